@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ackBy   string
+	ackNote string
+)
+
+var ackCmd = &cobra.Command{
+	Use:   "ack [failover-id]",
+	Short: "Acknowledge a failover on the running Solana validator HA manager",
+	Long: `Acknowledge a failover by its ID, as shown by the status command and in notifications, or list the failovers
+acknowledged so far when no ID is given. Later notifications for an acknowledged failover show who acknowledged it,
+no longer mention operators and acknowledge rather than trigger its PagerDuty incident. Acknowledgements do not
+survive a restart.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		url := loadedConfig.Prometheus.HealthCheckURL("/acknowledgements")
+
+		method := http.MethodGet
+		var body io.Reader
+		if len(args) == 1 {
+			by := ackBy
+			if by == "" {
+				by = currentUsername()
+			}
+			jsonData, err := json.Marshal(map[string]string{"id": args[0], "by": by, "note": ackNote})
+			if err != nil {
+				log.Fatal("failed to marshal acknowledgement", "error", err)
+			}
+			method = http.MethodPost
+			body = bytes.NewReader(jsonData)
+		}
+
+		req, err := http.NewRequest(method, url, body)
+		if err != nil {
+			log.Fatal("failed to create request", "error", err)
+		}
+
+		client := healthCheckClient(5 * time.Second)
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Fatal("failed to query HA manager - is it running?", "url", url, "error", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			log.Fatal("HA manager returned unexpected status", "url", url, "status", resp.StatusCode, "message", strings.TrimSpace(string(message)))
+		}
+
+		var acks []notify.Acknowledgement
+		if err := json.NewDecoder(resp.Body).Decode(&acks); err != nil {
+			log.Fatal("failed to decode HA manager acknowledgements", "error", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FAILOVER ID\tBY\tAT\tNOTE")
+		for _, ack := range acks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ack.ID, ack.By, ack.At.Format(time.RFC3339), ack.Note)
+		}
+		w.Flush()
+	},
+}
+
+// currentUsername returns the name of the user running the command, for acknowledgements made without --by
+func currentUsername() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	return "unknown"
+}
+
+func init() {
+	ackCmd.Flags().StringVar(&ackBy, "by", "", "Who is acknowledging the failover - defaults to the current user")
+	ackCmd.Flags().StringVar(&ackNote, "note", "", "Note added to the acknowledgement notification")
+}
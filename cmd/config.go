@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configValidateSkipIdentities bool
+	configValidateStrict         bool
+	configValidateSkipCommands   bool
+	configShowResolved           bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration utilities",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration file",
+	Long: `Load, default and validate the configuration file, resolve notification secrets and render the role commands
+and hooks against placeholder identities, printing the resolved secrets masked and the commands each failover would
+run. Every error found is listed and the command exits non-zero if there are any - use it to gate config changes in CI.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	// the config is checked here rather than loaded by the root command, which stops at the first error
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, errs := config.Check(cmd.Context(), configFile, config.CheckOptions{
+			SkipIdentities: configValidateSkipIdentities,
+			Strict:         configValidateStrict,
+			SkipCommands:   configValidateSkipCommands,
+		})
+		if len(errs) > 0 {
+			fmt.Fprintf(os.Stderr, "config %s is invalid - %d error(s):\n", configFile, len(errs))
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "  - %s\n", err)
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("config %s is valid\n", cfg.File)
+		for _, deprecation := range cfg.Deprecations() {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", deprecation)
+		}
+		for _, key := range cfg.UnknownKeys() {
+			fmt.Fprintf(os.Stderr, "warning: unknown key %s is ignored - check for typos\n", key)
+		}
+		for _, warning := range cfg.Warnings() {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+
+		secrets := cfg.Notifications.Secrets()
+		if len(secrets) > 0 {
+			fmt.Println()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SECRET\tVALUE")
+			for _, path := range slices.Sorted(maps.Keys(secrets)) {
+				fmt.Fprintf(w, "%s\t%s\n", path, config.MaskSecret(secrets[path]))
+			}
+			w.Flush()
+		}
+
+		fmt.Printf("\nbecoming %s runs:\n", constants.RoleNameActive)
+		for _, step := range cfg.Failover.RolePlan(constants.RoleNameActive, &cfg.Failover.Active) {
+			fmt.Printf("  %s\n", step)
+		}
+
+		fmt.Printf("\nbecoming %s runs:\n", constants.RoleNamePassive)
+		for _, step := range cfg.Failover.RolePlan(constants.RoleNamePassive, &cfg.Failover.Passive) {
+			fmt.Printf("  %s\n", step)
+		}
+	},
+}
+
+var configDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the configuration merged with its includes",
+	Long: `Print the configuration file merged with the files it includes as YAML, as the manager would load it - before
+environment variables are interpolated and defaults set, so secrets referenced as ${VAR} are not revealed.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	// the merged config is printed whether or not it validates
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		merged, err := config.Dump(configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load config %s: %s\n", configFile, err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(merged)
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the configuration",
+	Long: `Print the configuration file merged with its includes, as config dump does. With --resolved, print the effective
+configuration the manager runs with instead - after defaults, environment variable interpolation, secret resolution
+and template rendering - with secrets masked, so support can diff what each host actually runs.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	// the config is loaded here so an unresolved config can be shown whether or not it validates
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !configShowResolved {
+			configDumpCmd.Run(cmd, args)
+			return
+		}
+
+		cfg, err := config.NewFromConfigFile(cmd.Context(), configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load config %s: %s\n", configFile, err)
+			os.Exit(1)
+		}
+		effective, err := cfg.Effective()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to show config %s: %s\n", configFile, err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(effective)
+	},
+}
+
+func init() {
+	configValidateCmd.Flags().BoolVar(&configValidateSkipIdentities, "skip-identities", false, "Don't load the validator.identities keypair files, e.g. in CI where they are not present")
+	configValidateCmd.Flags().BoolVar(&configValidateSkipCommands, "skip-commands", false, "Don't check the role and hook commands exist, e.g. in CI where they are not installed")
+	configValidateCmd.Flags().BoolVar(&configValidateStrict, "strict", false, "Reject unknown config keys, as with strict: true in the config")
+
+	configCmd.AddCommand(configValidateCmd)
+	configShowCmd.Flags().BoolVar(&configShowResolved, "resolved", false, "Print the effective config after defaults, env resolution and template rendering, with secrets masked")
+
+	configCmd.AddCommand(configDumpCmd)
+	configCmd.AddCommand(configShowCmd)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configInitPreset string
+	configInitOutput string
+	configInitForce  bool
+)
+
+// peerNamePattern matches failover.peers names the wizard accepts
+var peerNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a starter configuration file",
+	Long: `Write a starter configuration file with sane defaults, peer placeholders and commented notification services.
+With --preset the preset is written as-is, otherwise a wizard asks for the validator name, identities and peers.
+The file is written to --output, or the --config path, and an existing file is only replaced with --force.
+
+Presets: ` + strings.Join(config.StarterPresetNames(), ", "),
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	// there is no config to load yet
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		var opts config.StarterOptions
+		var err error
+		if configInitPreset != "" {
+			opts, err = config.StarterPreset(configInitPreset)
+		} else {
+			opts, err = runConfigWizard(bufio.NewReader(os.Stdin), os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to generate config: %s\n", err)
+			os.Exit(1)
+		}
+
+		output := configInitOutput
+		if output == "" {
+			output = configFile
+		}
+		path, err := config.WriteStarter(output, opts, configInitForce)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write config: %s\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("wrote starter config to %s - review it, then check it with: solana-validator-ha config validate --config %s\n", path, path)
+	},
+}
+
+// runConfigWizard asks for the starter config settings, offering the chosen preset's as defaults
+func runConfigWizard(in *bufio.Reader, out io.Writer) (config.StarterOptions, error) {
+	presetNames := config.StarterPresetNames()
+	presetName, err := prompt(in, out, fmt.Sprintf("preset (%s)", strings.Join(presetNames, ", ")), presetNames[0], func(value string) error {
+		if !slices.Contains(presetNames, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(presetNames, ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		return config.StarterOptions{}, err
+	}
+	opts, err := config.StarterPreset(presetName)
+	if err != nil {
+		return config.StarterOptions{}, err
+	}
+
+	required := func(value string) error {
+		if value == "" {
+			return fmt.Errorf("must not be empty")
+		}
+		return nil
+	}
+	if opts.ValidatorName, err = prompt(in, out, "validator name", opts.ValidatorName, required); err != nil {
+		return config.StarterOptions{}, err
+	}
+	if opts.ActiveIdentityFile, err = prompt(in, out, "active identity keypair file (shared by all peers)", opts.ActiveIdentityFile, required); err != nil {
+		return config.StarterOptions{}, err
+	}
+	if opts.PassiveIdentityFile, err = prompt(in, out, "passive identity keypair file (unique to this validator)", opts.PassiveIdentityFile, required); err != nil {
+		return config.StarterOptions{}, err
+	}
+	if opts.SetRoleScript, err = prompt(in, out, "role command script", opts.SetRoleScript, required); err != nil {
+		return config.StarterOptions{}, err
+	}
+
+	fmt.Fprintln(out, "peers - every other validator in this HA set, leave the name empty when done (or to keep the placeholders)")
+	peers := []config.StarterPeer{}
+	for {
+		name, err := prompt(in, out, "  peer name", "", func(value string) error {
+			if value != "" && !peerNamePattern.MatchString(value) {
+				return fmt.Errorf("must be letters, digits, _, . or -")
+			}
+			return nil
+		})
+		if err != nil {
+			return config.StarterOptions{}, err
+		}
+		if name == "" {
+			break
+		}
+
+		ip, err := prompt(in, out, "  peer public IP", "", func(value string) error {
+			if net.ParseIP(value) == nil {
+				return fmt.Errorf("must be an IP address")
+			}
+			return nil
+		})
+		if err != nil {
+			return config.StarterOptions{}, err
+		}
+		peers = append(peers, config.StarterPeer{Name: name, IP: ip})
+	}
+	if len(peers) > 0 {
+		opts.Peers = peers
+	}
+
+	return opts, nil
+}
+
+// prompt asks for a value until validate accepts it, returning defaultValue when the answer is empty
+func prompt(in *bufio.Reader, out io.Writer, question string, defaultValue string, validate func(string) error) (string, error) {
+	for {
+		if defaultValue != "" {
+			fmt.Fprintf(out, "%s [%s]: ", question, defaultValue)
+		} else {
+			fmt.Fprintf(out, "%s: ", question)
+		}
+
+		line, err := in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("no answer to %q: %w", question, err)
+		}
+		value := strings.TrimSpace(line)
+		if value == "" {
+			value = defaultValue
+		}
+
+		if err := validate(value); err != nil {
+			fmt.Fprintf(out, "  %s\n", err)
+			continue
+		}
+		return value, nil
+	}
+}
+
+func init() {
+	configInitCmd.Flags().StringVar(&configInitPreset, "preset", "", "Write this preset without asking - one of "+strings.Join(config.StarterPresetNames(), ", "))
+	configInitCmd.Flags().StringVarP(&configInitOutput, "output", "o", "", "File to write the config to (default: the --config path)")
+	configInitCmd.Flags().BoolVar(&configInitForce, "force", false, "Replace the file if it exists")
+
+	configCmd.AddCommand(configInitCmd)
+}
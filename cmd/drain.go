@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/ha"
+	"github.com/spf13/cobra"
+)
+
+var drainCmd = &cobra.Command{
+	Use:   "drain [on|off]",
+	Short: "Show or toggle draining the running Solana validator HA manager for planned maintenance",
+	Long: `Drain this validator for planned OS or validator upgrades, or show the drain status when no argument is given.
+While draining an active validator hands the active role over to a peer once our next leader slot is at least
+failover.drain.leader_slot_gap slots away, or failover.drain.max_wait_duration has passed, and a drained validator
+never takes over. Notifications are in maintenance mode until the drain is turned off. Draining does not survive
+a restart.`,
+	Args:          cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:     []string{"on", "off"},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		url := loadedConfig.Prometheus.HealthCheckURL("/failover/drain")
+
+		method := http.MethodGet
+		if len(args) == 1 {
+			method = http.MethodPost
+			if args[0] == "off" {
+				method = http.MethodDelete
+			}
+		}
+
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			log.Fatal("failed to create request", "error", err)
+		}
+
+		client := healthCheckClient(5 * time.Second)
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Fatal("failed to query HA manager - is it running?", "url", url, "error", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			log.Fatal("HA manager returned unexpected status", "url", url, "status", resp.StatusCode, "message", strings.TrimSpace(string(message)))
+		}
+
+		var status ha.DrainStatus
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			log.Fatal("failed to decode HA manager drain status", "error", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "draining:\t%t\n", status.Draining)
+		if status.Draining {
+			fmt.Fprintf(w, "since:\t%s\n", status.Since.Format(time.RFC3339))
+			fmt.Fprintf(w, "progress:\t%s\n", status.Message)
+		}
+		w.Flush()
+	},
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/cache"
+	"github.com/sol-strategies/solana-validator-ha/internal/ha"
+	"github.com/spf13/cobra"
+)
+
+var (
+	roleChangeBy      string
+	roleChangeReason  string
+	roleChangeYes     bool
+	roleChangeTimeout time.Duration
+)
+
+var promoteCmd = newRoleChangeCmd("promote", "Make this passive validator active through the running Solana validator HA manager",
+	`Make this validator active through the running HA manager, running the failover.active hooks and command as a
+takeover would. Refused unless this validator is passive, in gossip and healthy and no peer is active.
+failover.arbitration, failover.tower_sync and failover.fencing still apply, while failover policies, the cooldown,
+the circuit breaker and the takeover delay don't. Lifts the hold on automatic takeovers left by demote or failover.`)
+
+var demoteCmd = newRoleChangeCmd("demote", "Make this validator passive through the running Solana validator HA manager",
+	`Make this validator passive through the running HA manager, running the failover.passive hooks and command as a
+failover would. Automatic takeovers by this validator are held back until a peer is active, or it is promoted, so
+it does not take the active role straight back.`)
+
+var failoverCmd = newRoleChangeCmd("failover", "Hand the active role over to a peer through the running Solana validator HA manager",
+	`Hand the active role over from this validator to a peer, which takes over once it sees no active validator.
+Refused unless this validator is active and a peer is in gossip to take over, then this validator is demoted as by
+the demote command.`)
+
+// newRoleChangeCmd returns a command requesting the action of the running HA manager
+func newRoleChangeCmd(action string, short string, long string) *cobra.Command {
+	return &cobra.Command{
+		Use:           action,
+		Short:         short,
+		Long:          long,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !roleChangeYes && !confirmRoleChange(action, bufio.NewReader(os.Stdin), os.Stdout) {
+				log.Fatal("role change not confirmed - nothing done")
+			}
+
+			by := roleChangeBy
+			if by == "" {
+				by = currentUsername()
+			}
+			jsonData, err := json.Marshal(ha.ManualFailoverRequest{Action: action, By: by, Reason: roleChangeReason})
+			if err != nil {
+				log.Fatal("failed to marshal role change", "error", err)
+			}
+
+			url := loadedConfig.Prometheus.HealthCheckURL("/failover/manual")
+			client := healthCheckClient(roleChangeTimeout)
+			resp, err := client.Post(url, "application/json", bytes.NewReader(jsonData))
+			if err != nil {
+				log.Fatal("failed to request role change of HA manager - is it running? The role change may still be running if it timed out",
+					"url", url, "error", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+				log.Fatal("HA manager returned unexpected status", "url", url, "status", resp.StatusCode, "message", strings.TrimSpace(string(message)))
+			}
+
+			var result ha.ManualFailoverResult
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				log.Fatal("failed to decode HA manager role change result", "error", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "action:\t%s\n", result.Action)
+			fmt.Fprintf(w, "role:\t%s\n", result.Role)
+			if result.FailoverID != "" {
+				fmt.Fprintf(w, "failover id:\t%s\n", result.FailoverID)
+			}
+			if result.Message != "" {
+				fmt.Fprintf(w, "message:\t%s\n", result.Message)
+			}
+			w.Flush()
+
+			switch {
+			case result.Refused:
+				log.Fatal("role change refused", "reason", result.Message)
+			case result.Error != "":
+				log.Fatal("role change failed", "error", result.Error)
+			}
+		},
+	}
+}
+
+// confirmRoleChange shows the running HA manager's current role and asks to go ahead with action
+func confirmRoleChange(action string, in *bufio.Reader, out io.Writer) bool {
+	current := "unknown role"
+	client := healthCheckClient(5 * time.Second)
+	if resp, err := client.Get(loadedConfig.Prometheus.HealthCheckURL("/status")); err == nil {
+		var state cache.State
+		if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&state) == nil {
+			current = fmt.Sprintf("%s, %s, active peer: %s", state.Role, state.Status, state.ActivePeerName)
+		}
+		resp.Body.Close()
+	}
+
+	answer, err := prompt(in, out, fmt.Sprintf("%s %s (%s)? [y/N]", action, loadedConfig.Validator.Name, current), "", func(string) error { return nil })
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes")
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{promoteCmd, demoteCmd, failoverCmd} {
+		cmd.Flags().StringVar(&roleChangeBy, "by", "", "Who is changing the role - defaults to the current user")
+		cmd.Flags().StringVar(&roleChangeReason, "reason", "", "Why the role is changed, sent in the manual_role_change notification")
+		cmd.Flags().BoolVarP(&roleChangeYes, "yes", "y", false, "Change the role without asking for confirmation")
+		cmd.Flags().DurationVar(&roleChangeTimeout, "timeout", 5*time.Minute, "How long to wait for the role change to finish")
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance [on|off]",
+	Short: "Show or toggle notification maintenance mode on the running Solana validator HA manager",
+	Long: `Turn notification maintenance mode on or off on the running HA manager, or show the current quiet status when no argument is given.
+While in maintenance mode non-critical notifications are suppressed or downgraded per notifications.quiet_hours.mode,
+and a summary is sent when it ends. Maintenance mode set here does not survive a restart - use notifications.quiet_hours.maintenance_file for that.`,
+	Args:          cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:     []string{"on", "off"},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		url := loadedConfig.Prometheus.HealthCheckURL("/notifications/maintenance")
+
+		method := http.MethodGet
+		if len(args) == 1 {
+			method = http.MethodPost
+			if args[0] == "off" {
+				method = http.MethodDelete
+			}
+		}
+
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			log.Fatal("failed to create request", "error", err)
+		}
+
+		client := healthCheckClient(5 * time.Second)
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Fatal("failed to query HA manager - is it running?", "url", url, "error", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			log.Fatal("HA manager returned unexpected status", "url", url, "status", resp.StatusCode)
+		}
+
+		var status notify.QuietStatus
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			log.Fatal("failed to decode HA manager quiet status", "error", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "maintenance:\t%t\n", status.Maintenance)
+		fmt.Fprintf(w, "quiet:\t%t\n", status.Quiet)
+		if status.Quiet {
+			fmt.Fprintf(w, "reason:\t%s\n", status.Reason)
+			fmt.Fprintf(w, "since:\t%s\n", status.Since.Format(time.RFC3339))
+			fmt.Fprintf(w, "mode:\t%s\n", status.Mode)
+			fmt.Fprintf(w, "held events:\t%d\n", status.HeldEvents)
+		}
+		w.Flush()
+	},
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyTestEvent    string
+	notifyTestSeverity string
+	notifyTestServices []string
+)
+
+var notifySeverities = []string{
+	string(notify.SeverityInfo),
+	string(notify.SeverityWarning),
+	string(notify.SeverityError),
+	string(notify.SeverityCritical),
+}
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Notification utilities",
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification to the configured notification services",
+	Long: `Send a synthetic event to all enabled notification services, or only those given with --service, and report
+whether each delivery succeeded. Event filters, quiet hours, dedup and digests are bypassed so every selected service
+is exercised; notifications.templates are still applied. Exits non-zero if any service fails.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loadedConfig.Notifications.HasAnyEnabled() {
+			log.Fatal("no notification services enabled - set notifications.enabled and enable at least one service")
+		}
+
+		eventNames := loadedConfig.Notifications.Events.Names()
+		if !slices.Contains(eventNames, notifyTestEvent) {
+			log.Fatal("unknown event", "event", notifyTestEvent, "valid", strings.Join(eventNames, ", "))
+		}
+
+		if notifyTestSeverity != "" && !slices.Contains(notifySeverities, notifyTestSeverity) {
+			log.Fatal("unknown severity", "severity", notifyTestSeverity, "valid", strings.Join(notifySeverities, ", "))
+		}
+
+		// the public IP is informational only - don't fail the test without it
+		publicIP, err := loadedConfig.Validator.PublicIP()
+		if err != nil {
+			log.Warn("failed to get public IP", "error", err)
+		}
+
+		manager := notify.NewManager(notify.ManagerOptions{
+			Config:        &loadedConfig.Notifications,
+			ValidatorName: loadedConfig.Validator.Name,
+			PublicIP:      publicIP,
+			Cluster:       loadedConfig.Cluster.Name,
+		})
+
+		results := manager.SendTest(notify.Event{
+			Type:          notify.EventType(notifyTestEvent),
+			Severity:      notify.Severity(notifyTestSeverity),
+			ValidatorName: loadedConfig.Validator.Name,
+			PublicIP:      publicIP,
+			Cluster:       loadedConfig.Cluster.Name,
+			ActivePubkey:  loadedConfig.Validator.Identities.ActiveKeyPair.PublicKey().String(),
+			PassivePubkey: loadedConfig.Validator.Identities.PassiveKeyPair.PublicKey().String(),
+			Message:       fmt.Sprintf("This is a test %s notification sent by solana-validator-ha notify test", notifyTestEvent),
+			Details:       map[string]string{"test": "true"},
+		}, notifyTestServices)
+		manager.Close()
+
+		if len(results) == 0 {
+			log.Fatal("no enabled notification services match", "services", strings.Join(notifyTestServices, ", "))
+		}
+
+		failed := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SERVICE\tRESULT\tERROR")
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+				fmt.Fprintf(w, "%s\tFAILED\t%s\n", result.Service, result.Err)
+				continue
+			}
+			fmt.Fprintf(w, "%s\tOK\t\n", result.Service)
+		}
+		w.Flush()
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	notifyTestCmd.Flags().StringVarP(&notifyTestEvent, "event", "e", string(notify.EventStartup), "Event type to send (as named in notifications.events)")
+	notifyTestCmd.Flags().StringVarP(&notifyTestSeverity, "severity", "s", "", "Severity to send the event with (info, warning, error, critical) - defaults to the event's configured severity")
+	notifyTestCmd.Flags().StringSliceVar(&notifyTestServices, "service", nil, "Only send to these services (discord, telegram, slack, pagerduty, tickets, plugin:<name>) - defaults to all enabled services")
+
+	notifyCmd.AddCommand(notifyTestCmd)
+}
@@ -31,7 +31,7 @@ It monitors peers and manages failover decisions to ensure continuous validator
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		// Load configuration
 		var err error
-		loadedConfig, err = config.NewFromConfigFile(configFile)
+		loadedConfig, err = config.NewFromConfigFile(cmd.Context(), configFile)
 		if err != nil {
 			log.Fatal("failed to load configuration", "error", err)
 		}
@@ -47,9 +47,18 @@ func Execute() error {
 
 func init() {
 	// Add global flags here
-	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "~/solana-validator-ha/config.yaml", "Path to configuration file (default: ~/solana-validator-ha/config.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "~/solana-validator-ha/config.yaml", "Path to configuration file, or a consul:// or etcd:// key URL (default: ~/solana-validator-ha/config.yaml)")
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "", "Log level (debug, info, warn, error, fatal) - overrides config.yaml log.level if specified")
 
 	// Add subcommands here
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(maintenanceCmd)
+	rootCmd.AddCommand(ackCmd)
+	rootCmd.AddCommand(promoteCmd)
+	rootCmd.AddCommand(demoteCmd)
+	rootCmd.AddCommand(failoverCmd)
+	rootCmd.AddCommand(drainCmd)
+	rootCmd.AddCommand(notifyCmd)
+	rootCmd.AddCommand(configCmd)
 }
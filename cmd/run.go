@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/charmbracelet/log"
-	"github.com/sol-strategies/solana-validator-ha/internal/ha"
+	"github.com/sol-strategies/solana-validator-ha/pkg/ha"
 	"github.com/spf13/cobra"
 )
 
@@ -14,10 +19,37 @@ var runCmd = &cobra.Command{
 	SilenceErrors: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Start the HA manager with the loaded config
-		manager := ha.NewManager(ha.NewManagerOptions{
-			Cfg: loadedConfig,
+		manager, err := ha.New(ha.Options{
+			Config: loadedConfig,
 		})
-		err := manager.Run()
+		if err != nil {
+			log.Fatal("failed to create manager", "error", err)
+		}
+
+		// stop gracefully on the first SIGINT or SIGTERM so the exit report and shutdown notification are sent -
+		// a second signal exits immediately
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			sig := <-signals
+			signal.Stop(signals)
+			log.Info("received signal - stopping", "signal", sig)
+			manager.Stop()
+		}()
+
+		// reload the config file on SIGHUP
+		reloads := make(chan os.Signal, 1)
+		signal.Notify(reloads, syscall.SIGHUP)
+		go func() {
+			for range reloads {
+				log.Info("received SIGHUP - reloading config", "file", loadedConfig.File)
+				if err := manager.Reload(); err != nil {
+					log.Error("failed to reload config - keeping the current config", "error", err)
+				}
+			}
+		}()
+
+		err = manager.Run(context.Background())
 		if err != nil {
 			log.Fatal("failed to run manager", "error", err)
 		}
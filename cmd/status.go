@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var statusJSON bool
+
+var statusCmd = &cobra.Command{
+	Use:           "status",
+	Short:         "Show the status of the running Solana validator HA manager",
+	Long:          `Query the running HA manager's health check server for its current state, including RPC endpoint statistics.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		url := loadedConfig.Prometheus.HealthCheckURL("/status")
+
+		client := healthCheckClient(5 * time.Second)
+		resp, err := client.Get(url)
+		if err != nil {
+			log.Fatal("failed to query HA manager status - is it running?", "url", url, "error", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			log.Fatal("HA manager returned unexpected status", "url", url, "status", resp.StatusCode)
+		}
+
+		var state cache.State
+		if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+			log.Fatal("failed to decode HA manager status", "error", err)
+		}
+
+		if statusJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(state); err != nil {
+				log.Fatal("failed to encode status", "error", err)
+			}
+			return
+		}
+
+		printStatus(state)
+	},
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Output status as JSON")
+}
+
+// printStatus prints the state in a human readable form
+func printStatus(state cache.State) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "validator:\t%s\n", state.ValidatorName)
+	fmt.Fprintf(w, "public ip:\t%s\n", state.PublicIP)
+	fmt.Fprintf(w, "role:\t%s\n", state.Role)
+	fmt.Fprintf(w, "status:\t%s\n", state.Status)
+	fmt.Fprintf(w, "client:\t%s\n", state.Client)
+	fmt.Fprintf(w, "failover status:\t%s\n", state.FailoverStatus)
+	fmt.Fprintf(w, "peers in gossip:\t%d\n", state.PeerCount)
+	fmt.Fprintf(w, "self in gossip:\t%t\n", state.SelfInGossip)
+	fmt.Fprintf(w, "active peer:\t%s\n", state.ActivePeerName)
+	fmt.Fprintf(w, "leaderless samples:\t%d\n", state.LeaderlessSamples)
+	fmt.Fprintf(w, "slots behind:\t%d\n", state.SlotsBehind)
+	fmt.Fprintf(w, "snapshot recovery running:\t%t\n", state.SnapshotRecoveryRunning)
+	fmt.Fprintf(w, "draining:\t%t\n", state.Draining)
+	if state.VoteCredits != nil {
+		fmt.Fprintf(w, "vote credits:\t%d (cluster average %.0f)\n", state.VoteCredits.Credits, state.VoteCredits.ClusterAverage)
+	}
+	if state.BlockProduction != nil {
+		fmt.Fprintf(w, "skip rate:\t%.1f%% (%d of %d leader slots produced)\n", state.BlockProduction.SkipRate()*100, state.BlockProduction.BlocksProduced, state.BlockProduction.LeaderSlots)
+	}
+	fmt.Fprintf(w, "last updated:\t%s\n", state.LastUpdated.Format(time.RFC3339))
+	w.Flush()
+
+	if len(state.RPCEndpoints) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RPC ENDPOINT\tREQUESTS\tERROR RATE\tTIMEOUTS\tAVG LATENCY\tDEMOTED")
+	for _, endpoint := range state.RPCEndpoints {
+		demoted := "no"
+		if endpoint.Demoted {
+			demoted = fmt.Sprintf("until %s", endpoint.DemotedUntil.Format(time.RFC3339))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%d\t%s\t%s\n",
+			endpoint.Endpoint,
+			endpoint.Requests,
+			endpoint.ErrorRate()*100,
+			endpoint.Timeouts,
+			endpoint.AverageLatency.Round(time.Millisecond),
+			demoted,
+		)
+	}
+	w.Flush()
+}
+
+// healthCheckClient returns a client for the local health check server, presenting this node's certificate when
+// prometheus.health_check_tls is enabled
+func healthCheckClient(timeout time.Duration) *http.Client {
+	client, err := loadedConfig.Prometheus.HealthCheckClient(timeout)
+	if err != nil {
+		log.Fatal("failed to create health check client", "error", err)
+	}
+	return client
+}
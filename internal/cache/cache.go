@@ -3,26 +3,53 @@ package cache
 import (
 	"sync"
 	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/client"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
 )
 
 // State represents the current state of the HA manager
 type State struct {
 	// Metadata
-	ValidatorName string
-	Hostname      string
-	PublicIP      string
-	Role          string // "active", "passive", "unknown"
-	Status        string // "healthy", "unhealthy", "unknown"
+	ValidatorName string `json:"validator_name"`
+	Hostname      string `json:"hostname,omitempty"`
+	PublicIP      string `json:"public_ip"`
+	Role          string `json:"role"`   // "active", "passive", "unknown"
+	Status        string `json:"status"` // "healthy", "unhealthy", "unknown"
+
+	// Validator client flavor and version
+	Client client.Info `json:"client"`
 
 	// Peer information
-	PeerCount    int
-	SelfInGossip bool
+	PeerCount         int    `json:"peer_count"`
+	SelfInGossip      bool   `json:"self_in_gossip"`
+	ActivePeerName    string `json:"active_peer_name,omitempty"` // name of the active peer seen in gossip, empty if none
+	LeaderlessSamples int    `json:"leaderless_samples"`         // consecutive gossip samples without an active peer
 
 	// Failover status
-	FailoverStatus string // "idle", "becoming_active", "becoming_passive"
+	FailoverStatus string `json:"failover_status"` // "idle", "becoming_active", "becoming_passive"
+	// FailoverID identifies the current or most recent role transition in its logs, events and incident report
+	FailoverID string `json:"failover_id,omitempty"`
+	// CircuitBreakerTripped is true while failover.circuit_breaker has frozen automatic takeovers
+	CircuitBreakerTripped bool `json:"circuit_breaker_tripped"`
+
+	// Catchup distance to the cluster and whether failover.snapshot_recovery.command is running
+	SlotsBehind             uint64 `json:"slots_behind"`
+	SnapshotRecoveryRunning bool   `json:"snapshot_recovery_running"`
+
+	// Draining is true while this node is drained for planned maintenance
+	Draining bool `json:"draining"`
+
+	// VoteCredits are the vote account's epoch credits and the cluster average, when validator.vote_credits is enabled
+	VoteCredits *rpc.VoteCredits `json:"vote_credits,omitempty"`
+	// BlockProduction is the active identity's block production this epoch, when validator.skip_rate is enabled
+	BlockProduction *rpc.BlockProduction `json:"block_production,omitempty"`
+
+	// RPC endpoint statistics for the cluster rpc urls
+	RPCEndpoints []rpc.EndpointStats `json:"rpc_endpoints"`
 
 	// Timestamps
-	LastUpdated time.Time
+	LastUpdated time.Time `json:"last_updated"`
 }
 
 // Cache provides thread-safe access to the HA manager state
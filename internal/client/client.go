@@ -0,0 +1,195 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Flavor is the validator client implementation running on this node
+type Flavor string
+
+const (
+	// FlavorUnknown is used when the client flavor could not be detected
+	FlavorUnknown Flavor = "unknown"
+	// FlavorAgave is the Anza agave-validator (or legacy solana-validator) client
+	FlavorAgave Flavor = "agave"
+	// FlavorJito is the jito-solana fork of agave with block engine support
+	FlavorJito Flavor = "jito-solana"
+	// FlavorFiredancer is the Jump firedancer/frankendancer client run with fdctl
+	FlavorFiredancer Flavor = "firedancer"
+)
+
+// jitoArgs are command line arguments only accepted by jito-solana
+var jitoArgs = []string{
+	"--block-engine-url",
+	"--tip-payment-program-pubkey",
+	"--tip-distribution-program-pubkey",
+	"--relayer-url",
+}
+
+// Info describes the detected validator client
+type Info struct {
+	Flavor  Flavor `json:"flavor"`
+	Version string `json:"version"`
+	// Source describes how the flavor was detected - "process", "version" or empty if undetected
+	Source string `json:"source,omitempty"`
+}
+
+// String returns a human readable flavor and version
+func (i Info) String() string {
+	if i.Version == "" {
+		return string(i.Flavor)
+	}
+	return string(i.Flavor) + " " + i.Version
+}
+
+// ToleratesMissingGetHealth returns true if the client's RPC may not implement getHealth,
+// in which case a responsive RPC is the best available health signal
+func (i Info) ToleratesMissingGetHealth() bool {
+	return i.Flavor == FlavorFiredancer
+}
+
+// DetectOptions contains options for detecting the validator client
+type DetectOptions struct {
+	// Version is the solana-core version reported by the local RPC getVersion call, empty if unavailable
+	Version string
+	// ProcRoot is the procfs mount to inspect running processes in - defaults to /proc
+	ProcRoot string
+}
+
+// Detect detects the validator client flavor from running processes, falling back to the RPC reported version
+func Detect(opts DetectOptions) Info {
+	info := Info{Flavor: FlavorUnknown, Version: opts.Version}
+
+	procRoot := opts.ProcRoot
+	if procRoot == "" {
+		procRoot = "/proc"
+	}
+
+	if flavor := detectFromProcesses(procRoot); flavor != FlavorUnknown {
+		info.Flavor = flavor
+		info.Source = "process"
+		return info
+	}
+
+	if flavor := detectFromVersion(opts.Version); flavor != FlavorUnknown {
+		info.Flavor = flavor
+		info.Source = "version"
+	}
+
+	return info
+}
+
+// detectFromProcesses inspects process command lines for a known validator binary
+func detectFromProcesses(procRoot string) Flavor {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return FlavorUnknown
+	}
+
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+		if flavor := flavorFromCmdline(strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")); flavor != FlavorUnknown {
+			return flavor
+		}
+	}
+
+	return FlavorUnknown
+}
+
+// IdentityPaths returns the --identity keypair paths running agave-validator and solana-validator processes were
+// started with - relative paths are resolved against the process working directory. procRoot defaults to /proc.
+func IdentityPaths(procRoot string) []string {
+	if procRoot == "" {
+		procRoot = "/proc"
+	}
+
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+
+		identityPath := identityFromCmdline(strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00"))
+		if identityPath == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(identityPath) {
+			cwd, err := os.Readlink(filepath.Join(procRoot, entry.Name(), "cwd"))
+			if err != nil {
+				continue
+			}
+			identityPath = filepath.Join(cwd, identityPath)
+		}
+		paths = append(paths, filepath.Clean(identityPath))
+	}
+
+	return paths
+}
+
+// identityFromCmdline returns the --identity (or -i) argument of an agave or solana validator process, empty if none
+func identityFromCmdline(args []string) string {
+	if flavor := flavorFromCmdline(args); flavor != FlavorAgave && flavor != FlavorJito {
+		return ""
+	}
+
+	for i, arg := range args[1:] {
+		if value, ok := strings.CutPrefix(arg, "--identity="); ok {
+			return value
+		}
+		if (arg == "--identity" || arg == "-i") && i+2 < len(args) {
+			return args[i+2]
+		}
+	}
+
+	return ""
+}
+
+// flavorFromCmdline returns the flavor of a validator process given its arguments
+func flavorFromCmdline(args []string) Flavor {
+	switch filepath.Base(args[0]) {
+	case "fdctl", "firedancer", "fddev":
+		return FlavorFiredancer
+	case "agave-validator", "solana-validator":
+		for _, arg := range args[1:] {
+			for _, jitoArg := range jitoArgs {
+				if arg == jitoArg || strings.HasPrefix(arg, jitoArg+"=") {
+					return FlavorJito
+				}
+			}
+		}
+		return FlavorAgave
+	default:
+		return FlavorUnknown
+	}
+}
+
+// detectFromVersion infers the flavor from the RPC reported version - firedancer reports a 0.x version,
+// jito-solana reports the agave version it is based on so cannot be told apart
+func detectFromVersion(version string) Flavor {
+	if version == "" {
+		return FlavorUnknown
+	}
+	if strings.HasPrefix(version, "0.") {
+		return FlavorFiredancer
+	}
+	return FlavorAgave
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestProcess(t *testing.T, procRoot, pid string, args ...string) {
+	dir := filepath.Join(procRoot, pid)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(strings.Join(args, "\x00")+"\x00"), 0644))
+}
+
+func TestDetect_FromProcesses(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected Flavor
+	}{
+		{name: "agave", args: []string{"/usr/local/bin/agave-validator", "--identity", "id.json"}, expected: FlavorAgave},
+		{name: "jito", args: []string{"agave-validator", "--block-engine-url", "https://mainnet.block-engine.jito.wtf"}, expected: FlavorJito},
+		{name: "jito with equals", args: []string{"solana-validator", "--relayer-url=http://127.0.0.1:11226"}, expected: FlavorJito},
+		{name: "firedancer", args: []string{"/opt/firedancer/fdctl", "run", "--config", "fd.toml"}, expected: FlavorFiredancer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procRoot := t.TempDir()
+			writeTestProcess(t, procRoot, "1", "/sbin/init")
+			writeTestProcess(t, procRoot, "4242", tt.args...)
+
+			info := Detect(DetectOptions{Version: "2.1.5", ProcRoot: procRoot})
+			assert.Equal(t, tt.expected, info.Flavor)
+			assert.Equal(t, "2.1.5", info.Version)
+			assert.Equal(t, "process", info.Source)
+		})
+	}
+}
+
+func TestDetect_FromVersion(t *testing.T) {
+	procRoot := t.TempDir()
+	writeTestProcess(t, procRoot, "1", "/sbin/init")
+
+	assert.Equal(t, FlavorFiredancer, Detect(DetectOptions{Version: "0.503.20214", ProcRoot: procRoot}).Flavor)
+	assert.Equal(t, FlavorAgave, Detect(DetectOptions{Version: "2.2.14", ProcRoot: procRoot}).Flavor)
+
+	info := Detect(DetectOptions{ProcRoot: filepath.Join(procRoot, "missing")})
+	assert.Equal(t, FlavorUnknown, info.Flavor)
+	assert.Empty(t, info.Source)
+}
+
+func TestIdentityPaths(t *testing.T) {
+	procRoot := t.TempDir()
+	writeTestProcess(t, procRoot, "1", "/sbin/init")
+	writeTestProcess(t, procRoot, "100", "/usr/local/bin/agave-validator", "--identity", "/home/sol/active.json", "--ledger", "/mnt/ledger")
+	writeTestProcess(t, procRoot, "101", "agave-validator", "--identity=/home/sol/../sol/passive.json")
+	writeTestProcess(t, procRoot, "102", "solana-validator", "-i", "keys/identity.json")
+	require.NoError(t, os.Symlink("/home/sol", filepath.Join(procRoot, "102", "cwd")))
+	writeTestProcess(t, procRoot, "103", "fdctl", "run", "--config", "fd.toml")
+	writeTestProcess(t, procRoot, "104", "agave-validator", "--identity")
+
+	assert.ElementsMatch(t, []string{
+		"/home/sol/active.json",
+		"/home/sol/passive.json",
+		"/home/sol/keys/identity.json",
+	}, IdentityPaths(procRoot))
+
+	assert.Empty(t, IdentityPaths(filepath.Join(procRoot, "missing")))
+}
@@ -0,0 +1,86 @@
+package client
+
+import "fmt"
+
+// Client is a validator client whose identity the failover sets
+type Client interface {
+	// Flavor returns the client flavor
+	Flavor() Flavor
+	// SetIdentityCommand returns the command and args switching the running validator to the identity in
+	// keypairFile - becoming active with the client's own checks that it is safe to vote, becoming passive without
+	SetIdentityCommand(keypairFile string, active bool) (string, []string)
+}
+
+// Options are the settings a client's commands are run with
+type Options struct {
+	// Command is the client's CLI - agave-validator or fdctl when empty
+	Command string
+	// LedgerDir is the agave ledger directory, where its admin RPC socket is
+	LedgerDir string
+	// ConfigFile is the fdctl config.toml the validator runs with
+	ConfigFile string
+}
+
+// Flavors returns the flavors a client can be configured as
+func Flavors() []Flavor {
+	return []Flavor{FlavorAgave, FlavorJito, FlavorFiredancer}
+}
+
+// New returns the client of flavor
+func New(flavor Flavor, opts Options) (Client, error) {
+	switch flavor {
+	case FlavorAgave, FlavorJito:
+		if opts.Command == "" {
+			opts.Command = "agave-validator"
+		}
+		return agaveClient{flavor: flavor, opts: opts}, nil
+	case FlavorFiredancer:
+		if opts.Command == "" {
+			opts.Command = "fdctl"
+		}
+		return firedancerClient{opts: opts}, nil
+	default:
+		return nil, fmt.Errorf("client must be one of %v - got: %q", Flavors(), flavor)
+	}
+}
+
+// agaveClient sets the identity of agave-validator and jito-solana over their admin RPC
+type agaveClient struct {
+	flavor Flavor
+	opts   Options
+}
+
+// Flavor returns agave or jito-solana
+func (c agaveClient) Flavor() Flavor {
+	return c.flavor
+}
+
+// SetIdentityCommand returns agave-validator set-identity - becoming active requires the tower, so a validator
+// without one never votes from scratch
+func (c agaveClient) SetIdentityCommand(keypairFile string, active bool) (string, []string) {
+	args := []string{"--ledger", c.opts.LedgerDir, "set-identity"}
+	if active {
+		args = append(args, "--require-tower")
+	}
+	return c.opts.Command, append(args, keypairFile)
+}
+
+// firedancerClient sets the identity of firedancer and frankendancer with fdctl
+type firedancerClient struct {
+	opts Options
+}
+
+// Flavor returns firedancer
+func (c firedancerClient) Flavor() Flavor {
+	return FlavorFiredancer
+}
+
+// SetIdentityCommand returns fdctl set-identity - fdctl refuses to switch while the validator is still catching
+// up, which becoming passive must never wait for so it is forced
+func (c firedancerClient) SetIdentityCommand(keypairFile string, active bool) (string, []string) {
+	args := []string{"set-identity", "--config", c.opts.ConfigFile}
+	if !active {
+		args = append(args, "--force")
+	}
+	return c.opts.Command, append(args, keypairFile)
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_SetIdentityCommand(t *testing.T) {
+	agave, err := New(FlavorJito, Options{LedgerDir: "/mnt/ledger"})
+	require.NoError(t, err)
+	assert.Equal(t, FlavorJito, agave.Flavor())
+	command, args := agave.SetIdentityCommand("/home/sol/active.json", true)
+	assert.Equal(t, "agave-validator", command)
+	assert.Equal(t, []string{"--ledger", "/mnt/ledger", "set-identity", "--require-tower", "/home/sol/active.json"}, args)
+	_, args = agave.SetIdentityCommand("/home/sol/passive.json", false)
+	assert.Equal(t, []string{"--ledger", "/mnt/ledger", "set-identity", "/home/sol/passive.json"}, args)
+
+	firedancer, err := New(FlavorFiredancer, Options{Command: "/opt/firedancer/fdctl", ConfigFile: "/home/sol/config.toml"})
+	require.NoError(t, err)
+	assert.Equal(t, FlavorFiredancer, firedancer.Flavor())
+	command, args = firedancer.SetIdentityCommand("/home/sol/active.json", true)
+	assert.Equal(t, "/opt/firedancer/fdctl", command)
+	assert.Equal(t, []string{"set-identity", "--config", "/home/sol/config.toml", "/home/sol/active.json"}, args)
+	_, args = firedancer.SetIdentityCommand("/home/sol/passive.json", false)
+	assert.Equal(t, []string{"set-identity", "--config", "/home/sol/config.toml", "--force", "/home/sol/passive.json"}, args)
+
+	_, err = New(FlavorUnknown, Options{})
+	assert.EqualError(t, err, `client must be one of [agave jito-solana firedancer] - got: "unknown"`)
+}
@@ -1,11 +1,20 @@
 package command
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"maps"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
@@ -16,6 +25,26 @@ var (
 	stdoutStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("28"))
 )
 
+// ErrCommandNotAllowed is returned, wrapped, when a command is refused for not being in its allowlist
+var ErrCommandNotAllowed = errors.New("command not allowed")
+
+// ErrTimeout is returned, wrapped, when a command is killed for running past its timeout
+var ErrTimeout = errors.New("command timed out")
+
+const (
+	// defaultStopSignal is sent to a command's process group to stop it when no StopSignal is given
+	defaultStopSignal = syscall.SIGTERM
+	// defaultStopGracePeriod is how long a command has to exit after its stop signal when no StopGracePeriod is given
+	defaultStopGracePeriod = 10 * time.Second
+)
+
+// shellPath is the shell commands are run through in Shell mode
+const shellPath = "/bin/sh"
+
+// outputWaitDelay is how long to wait for output once a command exits or is killed before giving up on it -
+// processes a command leaves running in the background can hold its output open
+const outputWaitDelay = time.Second
+
 // RunOptions are the options for running a command
 type RunOptions struct {
 	Name         string
@@ -26,141 +55,267 @@ type RunOptions struct {
 	StreamOutput bool
 	LoggerPrefix string
 	LoggerArgs   []any
+	// Resources the command touches - commands sharing a resource never run at the same time
+	Resources []string
+	// Timeout is the maximum time the command may run for, once its resources are held - zero means no timeout
+	Timeout time.Duration
+	// WorkingDir is the directory the command runs in - the current directory when empty
+	WorkingDir string
+	// Umask is the octal file mode creation mask the command runs with, e.g. 0027 - inherited when empty
+	Umask string
+	// MaxOutputBytes is how much of each of stdout and stderr is captured, the rest being discarded and marked
+	// as truncated - 1MiB when zero, unlimited when negative. Streamed output is still logged in full.
+	MaxOutputBytes int
+	// ClearEnv runs the command with only PassEnv and Env rather than the manager's whole environment, which
+	// may include secrets. Only applies to local commands.
+	ClearEnv bool
+	// PassEnv are the names of the manager's environment variables still passed to the command with ClearEnv, e.g. PATH
+	PassEnv []string
+	// LockFile, if set, is a file locked while the command runs so invocations in this or any other process
+	// never overlap, e.g. two health checks firing the same remediation
+	LockFile string
+	// LockMode is what to do when LockFile is locked by another invocation - LockModeWait when empty, or
+	// LockModeSkip to return an error wrapping ErrLocked without running the command
+	LockMode string
+	// AllowedCommands, if set, are the absolute paths of the only commands that may run - any other command,
+	// relative command or Shell mode command is refused with an error wrapping ErrCommandNotAllowed
+	AllowedCommands []string
+	// Shell runs Command and Args joined into a single line through /bin/sh -c, so pipes, redirection and
+	// expansions work - args are not quoted and are interpreted by the shell too
+	Shell bool
+	// StopSignal is sent to the command's process group when its context is done or it runs past its Timeout -
+	// SIGTERM when zero, so validators can exit cleanly
+	StopSignal syscall.Signal
+	// StopGracePeriod is how long the command has to exit after StopSignal before its process group is killed -
+	// 10s when zero
+	StopGracePeriod time.Duration
 }
 
-// Run runs a command with the given options.
-// Note: This function never times out - commands can take an indeterminate amount of time
-// (e.g., failover commands that may need to wait for services to start/stop).
-func Run(opts RunOptions) error {
-	logger := log.WithPrefix(fmt.Sprintf("[%s command %s]", opts.LoggerPrefix, opts.Name))
-	envString := ""
-	for key, value := range opts.Env {
-		envString += fmt.Sprintf("%s=%s ", key, value)
-	}
-	runMsg := fmt.Sprintf("%s %s %s", envString, opts.Command, strings.Join(opts.Args, " "))
-	runMsg = strings.TrimSpace(runMsg)
+// shellLine returns the line Shell mode passes to /bin/sh -c - the command and args joined by spaces
+func (opts RunOptions) shellLine() string {
+	return strings.TrimSpace(opts.Command + " " + strings.Join(opts.Args, " "))
+}
 
-	logger.Info(runMsg, "dry_run", opts.DryRun)
+// environ returns the command's environment - the manager's, or just its PassEnv variables with ClearEnv,
+// overridden by Env
+func (opts RunOptions) environ() []string {
+	var env []string
+	if opts.ClearEnv {
+		for _, name := range opts.PassEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+	} else {
+		env = os.Environ()
+	}
 
-	// if dry run, skip command execution
-	if opts.DryRun {
-		logger.Debug("command execution skipped - dry run")
-		return nil
+	keys := make([]string, 0, len(opts.Env))
+	for key := range opts.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", strings.TrimSpace(key), strings.TrimSpace(opts.Env[key])))
 	}
 
-	// execute command for realsies
-	cmd := exec.Command(opts.Command, opts.Args...)
+	return env
+}
 
-	// Set environment variables if provided
-	if len(opts.Env) > 0 {
-		cmd.Env = make([]string, 0, len(opts.Env))
-		for key, value := range opts.Env {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", strings.TrimSpace(key), strings.TrimSpace(value)))
+// Plan returns the command as a copy-pasteable shell line, changing to its working dir and setting its umask
+// and environment first - as logged for dry runs so operators can check exactly what would run
+func (opts RunOptions) Plan() string {
+	env := maps.Clone(opts.Env)
+	if opts.ClearEnv {
+		env = map[string]string{}
+		for _, name := range opts.PassEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				env[name] = value
+			}
 		}
+		maps.Copy(env, opts.Env)
 	}
 
-	if opts.StreamOutput {
-		return runWithStreaming(cmd, logger)
-	}
+	command, args := opts.commandAndArgs()
+	return shellPrelude(opts.WorkingDir, opts.Umask) + shellCommandString(command, args, env, opts.ClearEnv)
+}
 
-	return runWithoutStreaming(cmd, logger)
+// commandAndArgs returns the executable and args to run - the shell and its line in Shell mode
+func (opts RunOptions) commandAndArgs() (string, []string) {
+	if opts.Shell {
+		return shellPath, []string{"-c", opts.shellLine()}
+	}
+	return opts.Command, opts.Args
 }
 
-// runWithStreaming executes the command and streams stdout/stderr in real-time
-func runWithStreaming(cmd *exec.Cmd, logger *log.Logger) error {
-	// Capture stdout and stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		logger.Error("failed to create stdout pipe", "error", err)
-		return err
+// CheckAllowed returns an error wrapping ErrCommandNotAllowed if AllowedCommands is set and does not allow the command
+func (opts RunOptions) CheckAllowed() error {
+	if opts.AllowedCommands == nil {
+		return nil
+	}
+	if opts.Shell {
+		return fmt.Errorf("%w: shell mode can run anything so is refused with a command allowlist", ErrCommandNotAllowed)
+	}
+	if !filepath.IsAbs(opts.Command) {
+		return fmt.Errorf("%w: %s must be an absolute path with a command allowlist", ErrCommandNotAllowed, opts.Command)
 	}
+	for _, allowed := range opts.AllowedCommands {
+		if filepath.Clean(allowed) == filepath.Clean(opts.Command) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not in the command allowlist", ErrCommandNotAllowed, opts.Command)
+}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		logger.Error("failed to create stderr pipe", "error", err)
+// validate returns an error if the command is not allowed or its options are invalid
+func (opts RunOptions) validate() error {
+	if err := opts.CheckAllowed(); err != nil {
 		return err
 	}
+	if opts.Umask != "" {
+		if err := ValidateUmask(opts.Umask); err != nil {
+			return err
+		}
+	}
+	return ValidateLockMode(opts.LockMode)
+}
 
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		logger.Error("failed to start command", "error", err)
-		return err
+// ValidateUmask returns an error if umask is not an octal file mode creation mask
+func ValidateUmask(umask string) error {
+	mask, err := strconv.ParseUint(umask, 8, 32)
+	if err != nil || mask > 0o777 {
+		return fmt.Errorf("umask must be an octal mode between 0000 and 0777, got %q", umask)
 	}
+	return nil
+}
 
-	// Stream stdout
-	go func() {
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			logger.Info(styledStreamOutputString("stdout", scanner.Text()))
-		}
-	}()
+// RunResult is the outcome of a command run with RunWithResult
+type RunResult struct {
+	// Stdout and Stderr are the command's output
+	Stdout string
+	Stderr string
+	// ExitCode is the command's exit code, -1 if it did not start or was killed by a signal
+	ExitCode int
+	// Duration is how long the command ran for, excluding any wait for its resources
+	Duration time.Duration
+	// TimedOut is true if the command was killed for running past its timeout
+	TimedOut bool
+}
 
-	// Stream stderr
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			logger.Info(styledStreamOutputString("stderr", scanner.Text()))
-		}
-	}()
+// Run runs a command with the given options until it exits or ctx is done.
+// Note: Without a Timeout or a ctx deadline this function never times out - commands can take an indeterminate
+// amount of time (e.g., failover commands that may need to wait for services to start/stop). When either ends
+// the command, its whole process group is sent StopSignal, then killed if still running after StopGracePeriod,
+// and for a timeout an error wrapping ErrTimeout is returned.
+func Run(ctx context.Context, opts RunOptions) error {
+	_, err := RunWithResult(ctx, opts)
+	return err
+}
 
-	// Wait for command to complete
-	err = cmd.Wait()
+// LineHandlers are called with each line a command writes, without its trailing newline, as it is written.
+// Stdout and Stderr may be called concurrently with each other.
+type LineHandlers struct {
+	Stdout func(line string)
+	Stderr func(line string)
+}
+
+// RunWithResult runs a command like Run, also returning its captured output, exit code and duration.
+// The result is returned whether or not the command succeeded - a dry run returns a zero result.
+func RunWithResult(ctx context.Context, opts RunOptions) (RunResult, error) {
+	return run(ctx, opts, LineHandlers{})
+}
+
+// RunStreaming runs a command like RunWithResult, streaming its output and calling handlers with each line
+// as it is written, e.g. to follow the progress of a long-running command.
+func RunStreaming(ctx context.Context, opts RunOptions, handlers LineHandlers) (RunResult, error) {
+	opts.StreamOutput = true
+	return run(ctx, opts, handlers)
+}
+
+// RunJSON runs a command like RunWithResult and unmarshals its stdout into out, e.g. for CLIs run with
+// --output json. Errors include the raw output so unexpected output can be diagnosed. A dry run leaves out as is.
+func RunJSON(ctx context.Context, opts RunOptions, out any) error {
+	result, err := RunWithResult(ctx, opts)
 	if err != nil {
-		logger.Error("failed to run command", "error", err)
 		return err
 	}
+	if opts.DryRun {
+		return nil
+	}
 
-	logger.Debug("command completed successfully")
+	if err := json.Unmarshal([]byte(result.Stdout), out); err != nil {
+		return fmt.Errorf("failed to parse command %s output as json: %w - output: %q", opts.Name, err, abbreviate(result.Stdout, maxErrorOutputBytes))
+	}
 	return nil
 }
 
-// runWithoutStreaming executes the command and captures all output (original behavior)
-func runWithoutStreaming(cmd *exec.Cmd, logger *log.Logger) error {
-	// Capture stdout and stderr
-	stdout, err := cmd.StdoutPipe()
+// run runs a command, calling handlers with each line of its output when streamed
+func run(ctx context.Context, opts RunOptions, handlers LineHandlers) (RunResult, error) {
+	process, err := start(ctx, opts, handlers)
 	if err != nil {
-		logger.Error("failed to create stdout pipe", "error", err)
-		return err
+		return RunResult{ExitCode: -1}, err
 	}
+	return process.Wait()
+}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		logger.Error("failed to create stderr pipe", "error", err)
-		return err
+// waitForCommand waits for the command to exit and its output to be copied. Output still held open by processes
+// it left in the background is abandoned after outputWaitDelay rather than failing the command.
+func waitForCommand(cmd *exec.Cmd, logger *log.Logger) error {
+	err := cmd.Wait()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		logger.Debug("command output still held open by a background process - not waiting for it")
+		return nil
 	}
+	return err
+}
 
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		logger.Error("failed to start command", "error", err)
-		return err
-	}
+// lineWriter logs each line written to it as stream output, keeping what output can hold
+type lineWriter struct {
+	stream  string
+	logger  *log.Logger
+	onLine  func(line string)
+	output  *cappedBuffer
+	partial []byte
+}
 
-	// Read stdout and stderr
-	stdoutBytes, err := io.ReadAll(stdout)
-	if err != nil {
-		logger.Error("failed to read stdout", "error", err)
-		return err
+// Write logs the complete lines in p, holding back any partial line until it is completed or flushed
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	w.partial = append(w.partial, p...)
+	for {
+		line, rest, found := bytes.Cut(w.partial, []byte("\n"))
+		if !found {
+			break
+		}
+		w.writeLine(string(line))
+		w.partial = rest
+	}
+	// don't hold on to a line that never ends
+	if len(w.partial) > maxLineBytes {
+		w.flush()
 	}
+	return len(p), nil
+}
 
-	stderrBytes, err := io.ReadAll(stderr)
-	if err != nil {
-		logger.Error("failed to read stderr", "error", err)
-		return err
+// flush logs the final line if it had no trailing newline
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.writeLine(string(w.partial))
+		w.partial = nil
 	}
+}
 
-	// Wait for command to complete
-	err = cmd.Wait()
-	if err != nil {
-		logger.Error("failed to run command",
-			"error", err,
-			"stdout", string(stdoutBytes),
-			"stderr", string(stderrBytes),
-		)
-		return err
+// writeLine logs a complete line and passes it to onLine if set
+func (w *lineWriter) writeLine(line string) {
+	w.logger.Info(styledStreamOutputString(w.stream, line))
+	if w.onLine != nil {
+		w.onLine(line)
 	}
+}
 
-	logger.Debug("command completed successfully")
-	return nil
+// String returns the output kept
+func (w *lineWriter) String() string {
+	return w.output.String()
 }
 
 // styledStreamOutputString creates a styled string for stream output
@@ -1,9 +1,11 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 	"time"
 
@@ -25,7 +27,7 @@ func TestRun_Success(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -39,7 +41,7 @@ func TestRun_DryRun(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected dry run to succeed")
 }
 
@@ -57,7 +59,7 @@ func TestRun_Failure(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.Error(t, err, "expected command to fail")
 }
 
@@ -75,7 +77,7 @@ func TestRun_WithOutput(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -93,7 +95,7 @@ func TestRun_WithStderr(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -107,7 +109,7 @@ func TestRun_InvalidCommand(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.Error(t, err, "expected command to fail")
 }
 
@@ -126,7 +128,7 @@ func TestRun_CommandWithLongOutput(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -145,7 +147,7 @@ func TestRun_CommandWithSpecialCharacters(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -163,7 +165,7 @@ func TestRun_EmptyArgs(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -182,7 +184,7 @@ func TestRun_WithArgs(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -200,7 +202,7 @@ func TestRun_WithLoggerArgs(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed")
 }
 
@@ -223,7 +225,7 @@ func TestRun_CommandWithExitCodes(t *testing.T) {
 				},
 			}
 
-			err := Run(opts)
+			err := Run(context.Background(), opts)
 			if exitCode == 0 {
 				assert.NoError(t, err, "expected command to succeed with exit code 0")
 			} else {
@@ -248,7 +250,7 @@ func TestRun_CommandWithLargeOutput(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with large output")
 }
 
@@ -267,7 +269,7 @@ func TestRun_CommandWithUnicode(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with unicode")
 }
 
@@ -286,7 +288,7 @@ func TestRun_CommandWithNewlines(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with newlines")
 }
 
@@ -305,7 +307,7 @@ func TestRun_CommandWithMixedOutput(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with mixed output")
 }
 
@@ -324,7 +326,7 @@ func TestRun_CommandWithEnvironment(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with environment")
 }
 
@@ -343,7 +345,7 @@ func TestRun_CommandWithWorkingDirectory(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with working directory")
 }
 
@@ -357,7 +359,7 @@ func TestRun_InvalidExecutable(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.Error(t, err, "expected command to fail with invalid executable")
 }
 
@@ -371,7 +373,7 @@ func TestRun_CommandNotFound(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.Error(t, err, "expected command to fail when command not found")
 }
 
@@ -393,7 +395,7 @@ func TestRun_CommandWithTimeout(t *testing.T) {
 	// Run in a goroutine with a reasonable timeout for testing
 	done := make(chan error, 1)
 	go func() {
-		done <- Run(opts)
+		done <- Run(context.Background(), opts)
 	}()
 
 	select {
@@ -425,7 +427,7 @@ func TestRun_CommandWithComplexArgs(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with complex arguments")
 }
 
@@ -444,7 +446,7 @@ func TestRun_CommandWithEmptyStringArgs(t *testing.T) {
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command to succeed with empty string arguments")
 }
 
@@ -470,7 +472,7 @@ echo "This is stdout line 3"
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected streaming command to succeed")
 }
 
@@ -494,7 +496,7 @@ exit 1
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.Error(t, err, "expected streaming command to fail")
 }
 
@@ -511,7 +513,7 @@ func BenchmarkRun_Success(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		Run(opts)
+		Run(context.Background(), opts)
 	}
 }
 
@@ -524,11 +526,267 @@ func BenchmarkRun_DryRun(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		Run(opts)
+		Run(context.Background(), opts)
 	}
 }
 
 // Helper functions
+func TestRun_Timeout(t *testing.T) {
+	// the backgrounded sleep holds the output pipes open - it must be killed with the script
+	scriptPath := createTestScript(t, "sleep 30 &\nsleep 30", 0)
+
+	for _, streamOutput := range []bool{true, false} {
+		startedAt := time.Now()
+		err := Run(context.Background(), RunOptions{
+			Name:         "timeout",
+			Command:      scriptPath,
+			StreamOutput: streamOutput,
+			Timeout:      100 * time.Millisecond,
+		})
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.Contains(t, err.Error(), "command timed out after 100ms")
+		assert.Less(t, time.Since(startedAt), 2*time.Second)
+	}
+}
+
+func TestRun_ContextCancelled(t *testing.T) {
+	scriptPath := createTestScript(t, "sleep 30", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	startedAt := time.Now()
+	err := Run(ctx, RunOptions{Name: "cancelled", Command: scriptPath})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(startedAt), 2*time.Second)
+}
+
+func TestRun_StopSignal(t *testing.T) {
+	// the command is given the chance to clean up before exiting
+	scriptPath := createTestScript(t, "trap 'kill $!; echo cleaned up; exit 1' INT\nsleep 30 &\nwait", 0)
+
+	result, err := RunWithResult(context.Background(), RunOptions{
+		Name:       "stop signal",
+		Command:    scriptPath,
+		Timeout:    100 * time.Millisecond,
+		StopSignal: syscall.SIGINT,
+	})
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, "cleaned up\n", result.Stdout)
+}
+
+func TestRun_StopGracePeriod(t *testing.T) {
+	// commands ignoring the stop signal are killed once the grace period is up
+	scriptPath := createTestScript(t, "trap '' TERM\nsleep 30 &\nwait", 0)
+
+	startedAt := time.Now()
+	err := Run(context.Background(), RunOptions{
+		Name:            "grace period",
+		Command:         scriptPath,
+		Timeout:         100 * time.Millisecond,
+		StopGracePeriod: 200 * time.Millisecond,
+	})
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Greater(t, time.Since(startedAt), 300*time.Millisecond)
+	assert.Less(t, time.Since(startedAt), 2*time.Second)
+}
+
+func TestRun_TimeoutNotReached(t *testing.T) {
+	scriptPath := createTestScript(t, "echo done", 0)
+
+	err := Run(context.Background(), RunOptions{Name: "quick", Command: scriptPath, Timeout: 5 * time.Second})
+	assert.NoError(t, err)
+}
+
+func TestRun_BackgroundProcessHoldingOutput(t *testing.T) {
+	// a process left running in the background must not hold up or fail the command
+	scriptPath := createTestScript(t, "sleep 5 &\necho started", 0)
+
+	for _, streamOutput := range []bool{true, false} {
+		startedAt := time.Now()
+		result, err := RunWithResult(context.Background(), RunOptions{Name: "background", Command: scriptPath, StreamOutput: streamOutput})
+		assert.NoError(t, err)
+		assert.Equal(t, "started\n", result.Stdout)
+		assert.Less(t, time.Since(startedAt), 3*time.Second)
+	}
+}
+
+func TestRun_WorkingDirAndUmask(t *testing.T) {
+	scriptPath := createTestScript(t, "pwd\numask", 0)
+	workingDir := t.TempDir()
+
+	result, err := RunWithResult(context.Background(), RunOptions{Name: "dir", Command: scriptPath, WorkingDir: workingDir, Umask: "0027"})
+	require.NoError(t, err)
+	assert.Equal(t, workingDir+"\n0027\n", result.Stdout)
+
+	_, err = RunWithResult(context.Background(), RunOptions{Name: "dir", Command: scriptPath, Umask: "0999"})
+	assert.ErrorContains(t, err, "umask must be an octal mode")
+}
+
+func TestRunWithResult(t *testing.T) {
+	scriptPath := createTestScript(t, "echo out\necho err >&2\nexit 3", 0)
+
+	for _, streamOutput := range []bool{true, false} {
+		result, err := RunWithResult(context.Background(), RunOptions{Name: "result", Command: scriptPath, StreamOutput: streamOutput})
+		assert.Error(t, err)
+		assert.Equal(t, "out\n", result.Stdout)
+		assert.Equal(t, "err\n", result.Stderr)
+		assert.Equal(t, 3, result.ExitCode)
+		assert.Positive(t, result.Duration)
+		assert.False(t, result.TimedOut)
+	}
+
+	result, err := RunWithResult(context.Background(), RunOptions{Name: "result", Command: createTestScript(t, "sleep 30", 0), Timeout: 100 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, -1, result.ExitCode)
+
+	result, err = RunWithResult(context.Background(), RunOptions{Name: "result", Command: "nonexistent-command-that-should-fail"})
+	assert.Error(t, err)
+	assert.Equal(t, -1, result.ExitCode)
+
+	result, err = RunWithResult(context.Background(), RunOptions{Name: "result", Command: scriptPath, DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, RunResult{}, result)
+}
+
+func TestRunStreaming(t *testing.T) {
+	scriptPath := createTestScript(t, "echo 'slot 10 behind'\necho warn >&2\nprintf 'slot 0 behind'", 0)
+
+	var stdoutLines, stderrLines []string
+	result, err := RunStreaming(context.Background(), RunOptions{Name: "streaming", Command: scriptPath}, LineHandlers{
+		Stdout: func(line string) { stdoutLines = append(stdoutLines, line) },
+		Stderr: func(line string) { stderrLines = append(stderrLines, line) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"slot 10 behind", "slot 0 behind"}, stdoutLines)
+	assert.Equal(t, []string{"warn"}, stderrLines)
+	assert.Equal(t, "slot 10 behind\nslot 0 behind", result.Stdout)
+
+	// handlers are optional
+	_, err = RunStreaming(context.Background(), RunOptions{Name: "streaming", Command: scriptPath}, LineHandlers{})
+	assert.NoError(t, err)
+}
+
+func TestRunWithResult_MaxOutputBytes(t *testing.T) {
+	scriptPath := createTestScript(t, "printf 'first line\\nsecond line\\n'\nprintf 'err line\\n' >&2", 0)
+
+	for _, streamOutput := range []bool{true, false} {
+		result, err := RunWithResult(context.Background(), RunOptions{
+			Name:           "capped",
+			Command:        scriptPath,
+			StreamOutput:   streamOutput,
+			MaxOutputBytes: 10,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "first line\n... [truncated 13 bytes]", result.Stdout)
+		assert.Equal(t, "err line\n", result.Stderr)
+	}
+
+	result, err := RunWithResult(context.Background(), RunOptions{Name: "uncapped", Command: scriptPath, MaxOutputBytes: -1})
+	require.NoError(t, err)
+	assert.Equal(t, "first line\nsecond line\n", result.Stdout)
+}
+
+func TestRunJSON(t *testing.T) {
+	type version struct {
+		SolanaCore string `json:"solana-core"`
+		FeatureSet uint32 `json:"feature-set"`
+	}
+
+	var out version
+	err := RunJSON(context.Background(), RunOptions{
+		Name:    "version",
+		Command: createTestScript(t, `echo '{"solana-core":"2.2.1","feature-set":3294202862}'`, 0),
+	}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, version{SolanaCore: "2.2.1", FeatureSet: 3294202862}, out)
+
+	// the raw output is included when it can't be parsed
+	err = RunJSON(context.Background(), RunOptions{
+		Name:    "version",
+		Command: createTestScript(t, "echo 'Error: RPC request error'", 0),
+	}, &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse command version output as json")
+	assert.Contains(t, err.Error(), "Error: RPC request error")
+
+	// command failures are returned as is
+	err = RunJSON(context.Background(), RunOptions{Name: "version", Command: createTestScript(t, "exit 1", 0)}, &out)
+	assert.Error(t, err)
+
+	// dry runs leave out untouched
+	err = RunJSON(context.Background(), RunOptions{Name: "version", Command: "nonexistent-command", DryRun: true}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.2.1", out.SolanaCore)
+}
+
+func TestRun_AllowedCommands(t *testing.T) {
+	scriptPath := createTestScript(t, "echo allowed", 0)
+
+	err := Run(context.Background(), RunOptions{Name: "allowed", Command: scriptPath, AllowedCommands: []string{scriptPath}})
+	assert.NoError(t, err)
+
+	// refused even on dry runs so a bad config shows up before it matters
+	for _, opts := range []RunOptions{
+		{Name: "not listed", Command: createTestScript(t, "echo not allowed", 0), DryRun: true},
+		{Name: "relative", Command: "echo"},
+		{Name: "shell", Command: scriptPath, Shell: true},
+	} {
+		opts.AllowedCommands = []string{scriptPath}
+		result, err := RunWithResult(context.Background(), opts)
+		assert.ErrorIs(t, err, ErrCommandNotAllowed, opts.Name)
+		assert.Equal(t, -1, result.ExitCode)
+	}
+}
+
+func TestRun_ClearEnv(t *testing.T) {
+	t.Setenv("NOTIFIER_SECRET", "hunter2")
+	t.Setenv("KEEP_ME", "kept")
+	scriptPath := createTestScript(t, `echo "secret=$NOTIFIER_SECRET keep=$KEEP_ME mode=$MODE"`, 0)
+
+	// the manager's environment is inherited by default, with Env added
+	result, err := RunWithResult(context.Background(), RunOptions{Name: "inherit", Command: scriptPath, Env: map[string]string{"MODE": "active"}})
+	require.NoError(t, err)
+	assert.Equal(t, "secret=hunter2 keep=kept mode=active\n", result.Stdout)
+
+	// only passed variables and Env are kept when cleared
+	result, err = RunWithResult(context.Background(), RunOptions{
+		Name:     "clear",
+		Command:  scriptPath,
+		Env:      map[string]string{"MODE": "active", "KEEP_ME": "overridden"},
+		ClearEnv: true,
+		PassEnv:  []string{"KEEP_ME", "NOT_SET"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "secret= keep=overridden mode=active\n", result.Stdout)
+
+	result, err = RunWithResult(context.Background(), RunOptions{Name: "clear", Command: scriptPath, ClearEnv: true, PassEnv: []string{"KEEP_ME"}})
+	require.NoError(t, err)
+	assert.Equal(t, "secret= keep=kept mode=\n", result.Stdout)
+}
+
+func TestRunOptions_Plan(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin:/bin")
+
+	opts := RunOptions{
+		Command:    "/usr/local/bin/set-identity.sh",
+		Args:       []string{"--identity", "/home/sol/it's-active.json"},
+		Env:        map[string]string{"MODE": "active"},
+		WorkingDir: "/mnt/ledger",
+		Umask:      "0027",
+	}
+	assert.Equal(t, `cd '/mnt/ledger' && umask 0027 && env 'MODE=active' '/usr/local/bin/set-identity.sh' '--identity' '/home/sol/it'\''s-active.json'`, opts.Plan())
+
+	opts.ClearEnv = true
+	opts.PassEnv = []string{"PATH"}
+	assert.Equal(t, `cd '/mnt/ledger' && umask 0027 && env -i 'MODE=active' 'PATH=/usr/bin:/bin' '/usr/local/bin/set-identity.sh' '--identity' '/home/sol/it'\''s-active.json'`, opts.Plan())
+
+	opts = RunOptions{Command: "journalctl -u sol", Args: []string{"| tail"}, Shell: true}
+	assert.Equal(t, `'/bin/sh' '-c' 'journalctl -u sol | tail'`, opts.Plan())
+}
+
 func createTestScript(t testing.TB, content string, expectedExitCode int) string {
 	// Create a temporary script file
 	tmpDir := t.TempDir()
@@ -541,6 +799,26 @@ func createTestScript(t testing.TB, content string, expectedExitCode int) string
 	return scriptPath
 }
 
+func TestRun_Shell(t *testing.T) {
+	workingDir := t.TempDir()
+
+	result, err := RunWithResult(context.Background(), RunOptions{
+		Name:       "shell",
+		Command:    "echo",
+		Args:       []string{"$GREETING", "| tr a-z A-Z > out.txt && cat out.txt"},
+		Env:        map[string]string{"GREETING": "piped"},
+		WorkingDir: workingDir,
+		Umask:      "0077",
+		Shell:      true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "PIPED\n", result.Stdout)
+
+	info, err := os.Stat(filepath.Join(workingDir, "out.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
 func TestRun_WithEnvironmentVariables(t *testing.T) {
 	// Create a test script that outputs environment variables
 	scriptContent := `#!/bin/sh
@@ -567,7 +845,7 @@ echo "PATH: $PATH"
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command with env vars to succeed")
 }
 
@@ -597,7 +875,7 @@ echo "PATH: $PATH"
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected streaming command with env vars to succeed")
 }
 
@@ -622,6 +900,6 @@ echo "PATH: $PATH"
 		},
 	}
 
-	err := Run(opts)
+	err := Run(context.Background(), opts)
 	assert.NoError(t, err, "expected command with empty env vars to succeed")
 }
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// ErrLocked is returned, wrapped, when a command is skipped because another invocation holds its lock
+var ErrLocked = errors.New("command lock held by another invocation")
+
+const (
+	// LockModeWait waits for a held lock to be released before running the command
+	LockModeWait = "wait"
+	// LockModeSkip skips the command when its lock is held
+	LockModeSkip = "skip"
+)
+
+// lockPollInterval is how often a held lock is retried while waiting for it
+const lockPollInterval = 100 * time.Millisecond
+
+// ValidateLockMode returns an error if mode is not a lock mode - empty defaults to LockModeWait
+func ValidateLockMode(mode string) error {
+	switch mode {
+	case "", LockModeWait, LockModeSkip:
+		return nil
+	}
+	return fmt.Errorf("lock_mode must be one of %s or %s, got %q", LockModeWait, LockModeSkip, mode)
+}
+
+// acquireLock takes an exclusive flock on path, waiting for it or skipping as mode says - the returned release
+// unlocks it. Locks are released by the kernel if the process dies, so a crash never leaves one held.
+func acquireLock(ctx context.Context, path string, mode string, logger *log.Logger) (release func(), err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	waitingSince := time.Time{}
+	for {
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if mode == LockModeSkip {
+			file.Close()
+			logger.Warn("skipping command - another invocation holds its lock", "lock_file", path)
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+
+		if waitingSince.IsZero() {
+			waitingSince = time.Now()
+			logger.Info("waiting for another invocation to release the command lock", "lock_file", path)
+		}
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, fmt.Errorf("gave up waiting for lock %s: %w", path, context.Cause(ctx))
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	if !waitingSince.IsZero() {
+		logger.Info("acquired command lock", "lock_file", path, "waited", time.Since(waitingSince).Round(time.Millisecond))
+	}
+
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
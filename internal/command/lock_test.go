@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLockMode(t *testing.T) {
+	for _, mode := range []string{"", LockModeWait, LockModeSkip} {
+		assert.NoError(t, ValidateLockMode(mode), mode)
+	}
+	assert.ErrorContains(t, ValidateLockMode("queue"), "lock_mode must be one of wait or skip")
+}
+
+func TestRun_LockFile_Skip(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "remediate.lock")
+
+	holder, err := Start(context.Background(), RunOptions{Name: "holder", Command: "sleep", Args: []string{"5"}, LockFile: lockFile})
+	require.NoError(t, err)
+	t.Cleanup(func() { holder.Kill(); holder.Wait() })
+
+	err = Run(context.Background(), RunOptions{Name: "skipped", Command: "true", LockFile: lockFile, LockMode: LockModeSkip})
+	assert.ErrorIs(t, err, ErrLocked)
+}
+
+func TestRun_LockFile_Wait(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "remediate.lock")
+
+	holder, err := Start(context.Background(), RunOptions{Name: "holder", Command: "sleep", Args: []string{"0.3"}, LockFile: lockFile})
+	require.NoError(t, err)
+
+	startedAt := time.Now()
+	require.NoError(t, Run(context.Background(), RunOptions{Name: "waiter", Command: "true", LockFile: lockFile}))
+	assert.GreaterOrEqual(t, time.Since(startedAt), 200*time.Millisecond, "expected waiter to wait for the holder")
+
+	_, err = holder.Wait()
+	assert.NoError(t, err)
+}
+
+func TestAcquireLock_ContextDone(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "remediate.lock")
+
+	unlock, err := acquireLock(context.Background(), lockFile, LockModeWait, log.Default())
+	require.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = acquireLock(ctx, lockFile, LockModeWait, log.Default())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAcquireLock_Released(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "remediate.lock")
+
+	unlock, err := acquireLock(context.Background(), lockFile, LockModeSkip, log.Default())
+	require.NoError(t, err)
+	unlock()
+
+	unlock, err = acquireLock(context.Background(), lockFile, LockModeSkip, log.Default())
+	require.NoError(t, err)
+	unlock()
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// defaultMaxOutputBytes is how much of each output stream is kept when no MaxOutputBytes is given
+const defaultMaxOutputBytes = 1 << 20
+
+// maxLineBytes is the longest line logged as one - longer lines are logged in pieces
+const maxLineBytes = 64 << 10
+
+// maxErrorOutputBytes is how much of a command's output is included in errors about it
+const maxErrorOutputBytes = 512
+
+// cappedBuffer keeps the first limit bytes written to it, counting the rest so a command writing endless
+// output can't exhaust memory
+type cappedBuffer struct {
+	limit     int
+	buf       bytes.Buffer
+	truncated int
+}
+
+// newCappedBuffer returns a buffer keeping up to maxBytes - the default when zero, unlimited when negative
+func newCappedBuffer(maxBytes int) *cappedBuffer {
+	if maxBytes == 0 {
+		maxBytes = defaultMaxOutputBytes
+	}
+	return &cappedBuffer{limit: maxBytes}
+}
+
+// Write keeps what fits within the limit, discarding the rest - it never fails so the command is not disturbed
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.limit < 0 {
+		return b.buf.Write(p)
+	}
+
+	keep := min(len(p), max(b.limit-b.buf.Len(), 0))
+	b.buf.Write(p[:keep])
+	b.truncated += len(p) - keep
+	return len(p), nil
+}
+
+// String returns the output kept, marked if any was discarded
+func (b *cappedBuffer) String() string {
+	if b.truncated == 0 {
+		return b.buf.String()
+	}
+	return fmt.Sprintf("%s\n... [truncated %d bytes]", b.buf.String(), b.truncated)
+}
+
+// abbreviate returns s cut to maxBytes, marked if cut
+func abbreviate(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	return s[:maxBytes] + "..."
+}
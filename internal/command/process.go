@@ -0,0 +1,267 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// ErrKilled is returned, wrapped, when a started command is stopped with Process.Kill
+var ErrKilled = errors.New("command killed")
+
+// Process is a handle on a command started with Start
+type Process struct {
+	cmd    *exec.Cmd
+	logger *log.Logger
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	result RunResult
+	err    error
+	// mu guards killTimer and exited - the process group ID may be reused once the command has exited, so it
+	// must not be killed after
+	mu        sync.Mutex
+	killTimer *time.Timer
+	exited    bool
+}
+
+// Start starts a command with the given options without waiting for it to exit, for long-running processes
+// that are supervised while they run. The command is stopped like Run's when ctx is done, it runs past its
+// Timeout or Kill is called, and any resources it touches are held until it exits. A dry run returns a
+// process that has already exited.
+func Start(ctx context.Context, opts RunOptions) (*Process, error) {
+	return start(ctx, opts, LineHandlers{})
+}
+
+// PID returns the command's process ID, which is also its process group ID - zero for a dry run
+func (p *Process) PID() int {
+	if p.cmd == nil || p.cmd.Process == nil {
+		return 0
+	}
+	return p.cmd.Process.Pid
+}
+
+// Done returns a channel closed once the command has exited and its output has been copied
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait waits for the command to exit, returning its result like RunWithResult - it may be called any number of times
+func (p *Process) Wait() (RunResult, error) {
+	<-p.done
+	return p.result, p.err
+}
+
+// Signal sends sig to the command's process group - it has no effect once the command has exited
+func (p *Process) Signal(sig syscall.Signal) error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	if pid := p.PID(); pid != 0 {
+		return syscall.Kill(-pid, sig)
+	}
+	return nil
+}
+
+// Kill stops the command as if its context was done - sending StopSignal to its process group and killing it
+// if still running after StopGracePeriod. Wait returns an error wrapping ErrKilled.
+func (p *Process) Kill() {
+	if p.cancel != nil {
+		p.cancel(ErrKilled)
+	}
+}
+
+// start starts a command, calling handlers with each line of its output when streamed
+func start(ctx context.Context, opts RunOptions, handlers LineHandlers) (*Process, error) {
+	logger := log.WithPrefix(fmt.Sprintf("[%s command %s]", opts.LoggerPrefix, opts.Name))
+	envString := ""
+	for key, value := range opts.Env {
+		envString += fmt.Sprintf("%s=%s ", key, value)
+	}
+	command, args := opts.commandAndArgs()
+	runMsg := fmt.Sprintf("%s %s %s", envString, command, strings.Join(args, " "))
+	runMsg = strings.TrimSpace(runMsg)
+
+	logArgs := []any{"dry_run", opts.DryRun}
+	if opts.Shell {
+		logArgs = append(logArgs, "shell_line", opts.shellLine())
+	}
+	if opts.WorkingDir != "" {
+		logArgs = append(logArgs, "working_dir", opts.WorkingDir)
+	}
+	if opts.Umask != "" {
+		logArgs = append(logArgs, "umask", opts.Umask)
+	}
+	if opts.ClearEnv {
+		logArgs = append(logArgs, "clear_env", true, "pass_env", opts.PassEnv)
+	}
+	logger.Info(runMsg, logArgs...)
+
+	if err := opts.validate(); err != nil {
+		logger.Error("refusing to run command", "error", err)
+		if !opts.DryRun {
+			stats.record(opts.Name, 0, err)
+		}
+		return nil, err
+	}
+
+	p := &Process{logger: logger, done: make(chan struct{})}
+
+	// if dry run, skip command execution
+	if opts.DryRun {
+		logger.Info("command execution skipped - dry run", "plan", opts.Plan())
+		close(p.done)
+		return p, nil
+	}
+
+	unlock := func() {}
+	if opts.LockFile != "" {
+		var err error
+		if unlock, err = acquireLock(ctx, opts.LockFile, opts.LockMode, logger); err != nil {
+			stats.record(opts.Name, 0, err)
+			return nil, err
+		}
+	}
+
+	release := scheduler.Acquire(opts.Resources, logger)
+
+	ctx, p.cancel = context.WithCancelCause(ctx)
+	cancelTimeout := context.CancelFunc(func() {})
+	if opts.Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, opts.Timeout, fmt.Errorf("%w after %s", ErrTimeout, opts.Timeout))
+	}
+	// done once the command has exited
+	cleanup := func() {
+		cancelTimeout()
+		p.cancel(nil)
+		release()
+		unlock()
+	}
+
+	// execute command for realsies - the umask can only be set for a child process by a shell it is exec'd from
+	cmd := exec.Command(command, args...)
+	if opts.Umask != "" {
+		cmd = exec.Command(shellPath, append([]string{"-c", `umask ` + opts.Umask + ` && exec "$0" "$@"`, command}, args...)...)
+	}
+	cmd.Dir = opts.WorkingDir
+	p.cmd = cmd
+
+	// Run commands in their own process group so anything they start is stopped with them
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.WaitDelay = outputWaitDelay
+
+	// duplicate variables take the last value, so Env overrides those inherited
+	cmd.Env = opts.environ()
+
+	// capture output, logging it as it is written when streamed
+	stdout, stderr := newCappedBuffer(opts.MaxOutputBytes), newCappedBuffer(opts.MaxOutputBytes)
+	var stdoutLines, stderrLines *lineWriter
+	if opts.StreamOutput {
+		stdoutLines = &lineWriter{stream: "stdout", logger: logger, onLine: handlers.Stdout, output: stdout}
+		stderrLines = &lineWriter{stream: "stderr", logger: logger, onLine: handlers.Stderr, output: stderr}
+		cmd.Stdout = stdoutLines
+		cmd.Stderr = stderrLines
+	} else {
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+	}
+
+	startedAt := time.Now()
+	if err := cmd.Start(); err != nil {
+		logger.Error("failed to start command", "error", err)
+		cleanup()
+		stats.record(opts.Name, 0, err)
+		return nil, err
+	}
+
+	stopOnDone := context.AfterFunc(ctx, func() {
+		p.stop(cmp.Or(opts.StopSignal, defaultStopSignal), cmp.Or(opts.StopGracePeriod, defaultStopGracePeriod), context.Cause(ctx))
+	})
+
+	go func() {
+		defer close(p.done)
+
+		err := waitForCommand(cmd, logger)
+		stopOnDone()
+		p.exit()
+		if stdoutLines != nil {
+			stdoutLines.flush()
+			stderrLines.flush()
+		}
+
+		// report why the command was stopped rather than the signal that stopped it
+		if err != nil && ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+		cleanup()
+
+		p.result = RunResult{
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			ExitCode: -1,
+			Duration: time.Since(startedAt),
+			TimedOut: errors.Is(err, ErrTimeout),
+		}
+		if cmd.ProcessState != nil {
+			p.result.ExitCode = cmd.ProcessState.ExitCode()
+		}
+		p.err = err
+		stats.record(opts.Name, p.result.Duration, err)
+
+		switch {
+		case err != nil && opts.StreamOutput:
+			logger.Error("failed to run command", "error", err)
+		case err != nil:
+			logger.Error("failed to run command", "error", err, "stdout", p.result.Stdout, "stderr", p.result.Stderr)
+		default:
+			logger.Debug("command completed successfully")
+		}
+	}()
+
+	return p, nil
+}
+
+// stop sends stopSignal to the command's process group, killing whatever is left of it once gracePeriod is up
+func (p *Process) stop(stopSignal syscall.Signal, gracePeriod time.Duration, cause error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.exited {
+		return
+	}
+
+	pgid := p.cmd.Process.Pid
+	p.logger.Warn("stopping command", "signal", stopSignal, "grace_period", gracePeriod, "cause", cause)
+	if stopSignal != syscall.SIGKILL {
+		// kill whatever is left of the group, leader or not, once the grace period is up
+		p.killTimer = time.AfterFunc(gracePeriod, func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if !p.exited && syscall.Kill(-pgid, syscall.SIGKILL) == nil {
+				p.logger.Warn("command did not stop within grace period - killed", "grace_period", gracePeriod)
+			}
+		})
+	}
+	if err := syscall.Kill(-pgid, stopSignal); err != nil && !errors.Is(err, syscall.ESRCH) {
+		p.logger.Error("failed to stop command", "signal", stopSignal, "error", err)
+	}
+}
+
+// exit records that the command has exited, stopping the grace period kill if it was being stopped
+func (p *Process) exit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.exited = true
+	if p.killTimer != nil {
+		p.killTimer.Stop()
+	}
+}
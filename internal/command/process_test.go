@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart(t *testing.T) {
+	scriptPath := createTestScript(t, "echo started\nexit 2", 0)
+
+	process, err := Start(context.Background(), RunOptions{Name: "start", Command: scriptPath})
+	require.NoError(t, err)
+	assert.Positive(t, process.PID())
+
+	select {
+	case <-process.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected process to exit")
+	}
+
+	// Wait returns the same result however many times it is called
+	for range 2 {
+		result, err := process.Wait()
+		assert.Error(t, err)
+		assert.Equal(t, "started\n", result.Stdout)
+		assert.Equal(t, 2, result.ExitCode)
+	}
+}
+
+func TestStart_Signal(t *testing.T) {
+	scriptPath := createTestScript(t, "trap 'echo reloaded' HUP\nwhile true; do sleep 0.05; done", 0)
+
+	process, err := Start(context.Background(), RunOptions{Name: "signal", Command: scriptPath})
+	require.NoError(t, err)
+
+	// give the script time to set its trap
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, process.Signal(syscall.SIGHUP))
+	time.Sleep(200 * time.Millisecond)
+
+	process.Kill()
+	result, err := process.Wait()
+	assert.ErrorIs(t, err, ErrKilled)
+	assert.Equal(t, "reloaded\n", result.Stdout)
+	assert.False(t, result.TimedOut)
+
+	// signalling an exited process does nothing
+	assert.NoError(t, process.Signal(syscall.SIGHUP))
+}
+
+func TestStart_DryRun(t *testing.T) {
+	process, err := Start(context.Background(), RunOptions{Name: "dry run", Command: "nonexistent-command", DryRun: true})
+	require.NoError(t, err)
+	assert.Zero(t, process.PID())
+
+	result, err := process.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, RunResult{}, result)
+}
+
+func TestStart_InvalidCommand(t *testing.T) {
+	_, err := Start(context.Background(), RunOptions{Name: "invalid", Command: "nonexistent-command-that-should-fail"})
+	assert.Error(t, err)
+}
+
+func TestStart_KillStopsGracePeriodTimer(t *testing.T) {
+	scriptPath := createTestScript(t, "sleep 30", 0)
+
+	process, err := Start(context.Background(), RunOptions{Name: "kill", Command: scriptPath, StopGracePeriod: time.Hour})
+	require.NoError(t, err)
+
+	process.Kill()
+	_, err = process.Wait()
+	assert.ErrorIs(t, err, ErrKilled)
+
+	// the command stopped on the stop signal - its process group must not be killed once the grace period is up
+	process.mu.Lock()
+	defer process.mu.Unlock()
+	require.NotNil(t, process.killTimer)
+	assert.False(t, process.killTimer.Stop(), "grace period timer should be stopped once the command exits")
+}
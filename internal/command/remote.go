@@ -0,0 +1,311 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultSSHPort           = 22
+	defaultSSHConnectTimeout = 10 * time.Second
+)
+
+// RemoteOptions are the SSH options for running a command on a remote host
+type RemoteOptions struct {
+	// Host is the host name or IP address to run the command on
+	Host string
+	Port int
+	User string
+	// KeyFile is the path to the private key used to authenticate
+	KeyFile string
+	// KnownHostsFile is the path to a known_hosts file used to verify the remote host key
+	KnownHostsFile string
+	// InsecureIgnoreHostKey skips host key verification - only use for testing
+	InsecureIgnoreHostKey bool
+	// AllowedHosts is the allowlist of hosts commands may be run on
+	AllowedHosts []string
+	// ConnectTimeout is the maximum time to establish the SSH connection
+	ConnectTimeout time.Duration
+	// Timeout is the maximum time the command may run for - zero falls back to RunOptions.Timeout
+	Timeout time.Duration
+}
+
+// RunRemoteOptions are the options for running a command on a remote host over SSH
+type RunRemoteOptions struct {
+	RunOptions
+	Remote RemoteOptions
+}
+
+// RunRemote runs a command on a remote host over SSH with the given options until it exits or ctx is done.
+// The command and args are quoted so they reach the remote shell exactly as given - in Shell mode they are
+// passed as a single line to /bin/sh -c on the remote host instead.
+func RunRemote(ctx context.Context, opts RunRemoteOptions) error {
+	startedAt := time.Now()
+	err := runRemote(ctx, opts)
+	if !opts.DryRun {
+		stats.record(opts.Name, time.Since(startedAt), err)
+	}
+	return err
+}
+
+// runRemote runs a command on a remote host
+func runRemote(ctx context.Context, opts RunRemoteOptions) error {
+	logger := log.WithPrefix(fmt.Sprintf("[%s command %s]", opts.LoggerPrefix, opts.Name))
+	remoteCommand := opts.remoteCommand()
+
+	logger.Info(remoteCommand, "host", opts.Remote.Host, "dry_run", opts.DryRun)
+
+	if !slices.Contains(opts.Remote.AllowedHosts, opts.Remote.Host) {
+		return fmt.Errorf("host %s is not in the remote command allowlist", opts.Remote.Host)
+	}
+
+	if err := opts.CheckAllowed(); err != nil {
+		logger.Error("refusing to run remote command", "host", opts.Remote.Host, "error", err)
+		return err
+	}
+
+	if opts.Umask != "" {
+		if err := ValidateUmask(opts.Umask); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidateLockMode(opts.LockMode); err != nil {
+		return err
+	}
+
+	// if dry run, skip command execution
+	if opts.DryRun {
+		logger.Info("remote command execution skipped - dry run", "plan", opts.Plan())
+		return nil
+	}
+
+	// the lock is local - it serializes invocations from this host, not from others running against the remote
+	if opts.LockFile != "" {
+		unlock, err := acquireLock(ctx, opts.LockFile, opts.LockMode, logger)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	release := scheduler.Acquire(opts.Resources, logger)
+	defer release()
+
+	client, err := dialSSH(opts.Remote)
+	if err != nil {
+		logger.Error("failed to connect to remote host", "host", opts.Remote.Host, "error", err)
+		return err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		logger.Error("failed to create ssh session", "host", opts.Remote.Host, "error", err)
+		return err
+	}
+	defer session.Close()
+
+	// close the connection if ctx is done or the command runs past its timeout, which unblocks the wait below
+	timeout := opts.Remote.Timeout
+	if timeout == 0 {
+		timeout = opts.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("remote %w after %s", ErrTimeout, timeout))
+		defer cancel()
+	}
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	if opts.StreamOutput {
+		err = runRemoteWithStreaming(session, remoteCommand, logger)
+	} else {
+		err = runRemoteWithoutStreaming(session, remoteCommand, opts.MaxOutputBytes, logger)
+	}
+
+	if ctx.Err() != nil {
+		err = context.Cause(ctx)
+		logger.Error("failed to run remote command", "host", opts.Remote.Host, "error", err)
+		return err
+	}
+
+	if err != nil {
+		logger.Error("failed to run remote command", "host", opts.Remote.Host, "error", err)
+		return err
+	}
+
+	logger.Debug("remote command completed successfully", "host", opts.Remote.Host)
+	return nil
+}
+
+// remoteCommand returns the shell line run on the remote host
+func (opts RunRemoteOptions) remoteCommand() string {
+	command, args := opts.commandAndArgs()
+	return shellPrelude(opts.WorkingDir, opts.Umask) + shellCommandString(command, args, opts.Env, false)
+}
+
+// Plan returns the remote command as a copy-pasteable ssh command line, as logged for dry runs
+func (opts RunRemoteOptions) Plan() string {
+	port := opts.Remote.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	return fmt.Sprintf("ssh -p %d -i %s %s@%s %s",
+		port, shellQuote(opts.Remote.KeyFile), opts.Remote.User, opts.Remote.Host, shellQuote(opts.remoteCommand()))
+}
+
+// dialSSH connects to the remote host authenticating with the key file
+func dialSSH(opts RemoteOptions) (*ssh.Client, error) {
+	keyBytes, err := os.ReadFile(opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh key file: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ssh key file: %w", err)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !opts.InsecureIgnoreHostKey {
+		hostKeyCallback, err = knownhosts.New(opts.KnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known hosts file: %w", err)
+		}
+	}
+
+	port := opts.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+
+	connectTimeout := opts.ConnectTimeout
+	if connectTimeout == 0 {
+		connectTimeout = defaultSSHConnectTimeout
+	}
+
+	return ssh.Dial("tcp", net.JoinHostPort(opts.Host, strconv.Itoa(port)), &ssh.ClientConfig{
+		User:            opts.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         connectTimeout,
+	})
+}
+
+// runRemoteWithStreaming runs the command and streams stdout/stderr in real-time
+func runRemoteWithStreaming(session *ssh.Session, remoteCommand string, logger *log.Logger) error {
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	if err := session.Start(remoteCommand); err != nil {
+		return fmt.Errorf("failed to start remote command: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// Stream stdout
+	go func() {
+		defer wg.Done()
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			logger.Info(styledStreamOutputString("stdout", scanner.Text()))
+		}
+	}()
+
+	// Stream stderr
+	go func() {
+		defer wg.Done()
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.Info(styledStreamOutputString("stderr", scanner.Text()))
+		}
+	}()
+
+	wg.Wait()
+	return session.Wait()
+}
+
+// runRemoteWithoutStreaming runs the command and logs captured output on failure
+func runRemoteWithoutStreaming(session *ssh.Session, remoteCommand string, maxOutputBytes int, logger *log.Logger) error {
+	stdout, stderr := newCappedBuffer(maxOutputBytes), newCappedBuffer(maxOutputBytes)
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	err := session.Run(remoteCommand)
+	if err != nil {
+		logger.Error("remote command output",
+			"stdout", stdout.String(),
+			"stderr", stderr.String(),
+		)
+	}
+	return err
+}
+
+// shellCommandString builds a shell command line running the command with env, quoting every word - clearEnv
+// runs it with only env
+func shellCommandString(command string, args []string, env map[string]string, clearEnv bool) string {
+	words := []string{}
+
+	if len(env) > 0 || clearEnv {
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		words = append(words, "env")
+		if clearEnv {
+			words = append(words, "-i")
+		}
+		for _, key := range keys {
+			words = append(words, shellQuote(fmt.Sprintf("%s=%s", strings.TrimSpace(key), strings.TrimSpace(env[key]))))
+		}
+	}
+
+	words = append(words, shellQuote(command))
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+
+	return strings.Join(words, " ")
+}
+
+// shellPrelude returns the shell commands changing to workingDir and setting umask before the command, if set
+func shellPrelude(workingDir string, umask string) string {
+	prelude := ""
+	if workingDir != "" {
+		prelude += "cd " + shellQuote(workingDir) + " && "
+	}
+	if umask != "" {
+		prelude += "umask " + umask + " && "
+	}
+	return prelude
+}
+
+// shellQuote single-quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteFile is a file read from a remote host
+type RemoteFile struct {
+	Data []byte
+	// ModTime is the file's modification time on the remote host
+	ModTime time.Time
+	// SHA256 is the hex checksum of Data, as computed on the remote host
+	SHA256 string
+}
+
+// ReadRemoteFile reads path on the remote host over SSH until ctx is done or remote.Timeout passes, with its
+// modification time and a checksum computed on the remote host - an error is returned if what was received doesn't
+// match it, e.g. as the file was replaced while being read. Requires stat, sha256sum and cat on the remote host.
+func ReadRemoteFile(ctx context.Context, remote RemoteOptions, path string) (RemoteFile, error) {
+	if !slices.Contains(remote.AllowedHosts, remote.Host) {
+		return RemoteFile{}, fmt.Errorf("host %s is not in the remote command allowlist", remote.Host)
+	}
+
+	client, err := dialSSH(remote)
+	if err != nil {
+		return RemoteFile{}, fmt.Errorf("failed to connect to remote host %s: %w", remote.Host, err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return RemoteFile{}, fmt.Errorf("failed to create ssh session: %w", err)
+	}
+	defer session.Close()
+
+	// close the connection if ctx is done or the read runs past its timeout, which unblocks the run below
+	if remote.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, remote.Timeout, fmt.Errorf("remote %w after %s", ErrTimeout, remote.Timeout))
+		defer cancel()
+	}
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	// the modification time and checksum lines are followed by the file itself
+	quotedPath := shellQuote(path)
+	var stdout bytes.Buffer
+	stderr := newCappedBuffer(0)
+	session.Stdout = &stdout
+	session.Stderr = stderr
+	err = session.Run(fmt.Sprintf("stat -c %%Y -- %s && sha256sum < %s && cat -- %s", quotedPath, quotedPath, quotedPath))
+	if ctx.Err() != nil {
+		return RemoteFile{}, context.Cause(ctx)
+	}
+	if err != nil {
+		return RemoteFile{}, fmt.Errorf("failed to read %s on %s: %w: %s", path, remote.Host, err, abbreviate(strings.TrimSpace(stderr.String()), maxErrorOutputBytes))
+	}
+
+	return parseRemoteFile(&stdout)
+}
+
+// parseRemoteFile parses the output of reading a remote file - its modification time and checksum lines, then
+// the file - verifying the file matches the checksum
+func parseRemoteFile(output io.Reader) (RemoteFile, error) {
+	reader := bufio.NewReader(output)
+
+	modTimeLine, err := reader.ReadString('\n')
+	if err != nil {
+		return RemoteFile{}, fmt.Errorf("failed to read modification time: %w", err)
+	}
+	modTimeUnix, err := strconv.ParseInt(strings.TrimSpace(modTimeLine), 10, 64)
+	if err != nil {
+		return RemoteFile{}, fmt.Errorf("failed to parse modification time %q: %w", strings.TrimSpace(modTimeLine), err)
+	}
+
+	checksumLine, err := reader.ReadString('\n')
+	if err != nil {
+		return RemoteFile{}, fmt.Errorf("failed to read checksum: %w", err)
+	}
+	checksum, _, _ := strings.Cut(strings.TrimSpace(checksumLine), " ")
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return RemoteFile{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	if received := hex.EncodeToString(sum[:]); received != checksum {
+		return RemoteFile{}, fmt.Errorf("checksum mismatch - received %s, expected %s", received, checksum)
+	}
+
+	return RemoteFile{Data: data, ModTime: time.Unix(modTimeUnix, 0), SHA256: checksum}, nil
+}
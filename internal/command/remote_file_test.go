@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRemoteFile(t *testing.T) {
+	tower := []byte("tower\nbytes\x00\x01")
+	sum := sha256.Sum256(tower)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := newTestSSHServer(t)
+	server.respond = func(command string) ([]byte, uint32) {
+		switch {
+		case strings.Contains(command, "missing"):
+			return nil, 1
+		case strings.Contains(command, "changing"):
+			return []byte(fmt.Sprintf("1760000000\n%s  -\n%s", checksum, "changed")), 0
+		default:
+			return []byte(fmt.Sprintf("1760000000\n%s  -\n%s", checksum, tower)), 0
+		}
+	}
+
+	file, err := ReadRemoteFile(context.Background(), server.remoteOptions(), "/mnt/ledger/tower-1_9-x.bin")
+	require.NoError(t, err)
+	assert.Equal(t, tower, file.Data)
+	assert.Equal(t, checksum, file.SHA256)
+	assert.True(t, file.ModTime.Equal(time.Unix(1760000000, 0)))
+	assert.Equal(t, []string{"stat -c %Y -- '/mnt/ledger/tower-1_9-x.bin' && sha256sum < '/mnt/ledger/tower-1_9-x.bin' && cat -- '/mnt/ledger/tower-1_9-x.bin'"}, server.commands)
+
+	_, err = ReadRemoteFile(context.Background(), server.remoteOptions(), "/mnt/ledger/changing.bin")
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = ReadRemoteFile(context.Background(), server.remoteOptions(), "/mnt/ledger/missing.bin")
+	assert.ErrorContains(t, err, "failed to read /mnt/ledger/missing.bin on 127.0.0.1")
+
+	remote := server.remoteOptions()
+	remote.AllowedHosts = nil
+	_, err = ReadRemoteFile(context.Background(), remote, "/mnt/ledger/tower-1_9-x.bin")
+	assert.EqualError(t, err, "host 127.0.0.1 is not in the remote command allowlist")
+}
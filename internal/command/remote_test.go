@@ -0,0 +1,252 @@
+package command
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testSSHServer is a minimal SSH server that records exec'd commands - commands containing
+// "fail" exit 1 and commands containing "hang" never exit
+type testSSHServer struct {
+	addr     string
+	port     int
+	keyFile  string
+	mu       sync.Mutex
+	commands []string
+	// respond, if set, replaces the default output and exit status of commands
+	respond func(command string) (stdout []byte, exitStatus uint32)
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	clientPublicKey, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pemBlock, err := ssh.MarshalPrivateKey(clientKey, "")
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(pemBlock), 0o600))
+	authorizedKey, err := ssh.NewPublicKey(clientPublicKey)
+	require.NoError(t, err)
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unauthorized key")
+		},
+	}
+	serverConfig.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &testSSHServer{
+		addr:    "127.0.0.1",
+		port:    listener.Addr().(*net.TCPAddr).Port,
+		keyFile: keyFile,
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn, serverConfig)
+		}
+	}()
+
+	return server
+}
+
+func (s *testSSHServer) handle(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for request := range channelRequests {
+				if request.Type != "exec" {
+					request.Reply(false, nil)
+					continue
+				}
+				request.Reply(true, nil)
+
+				command := string(request.Payload[4:])
+				s.mu.Lock()
+				s.commands = append(s.commands, command)
+				s.mu.Unlock()
+
+				if strings.Contains(command, "hang") {
+					time.Sleep(5 * time.Second)
+				}
+
+				if s.respond != nil {
+					stdout, status := s.respond(command)
+					channel.Write(stdout)
+					exitStatus := make([]byte, 4)
+					binary.BigEndian.PutUint32(exitStatus, status)
+					channel.SendRequest("exit-status", false, exitStatus)
+					return
+				}
+
+				fmt.Fprintf(channel, "ran %s\n", command)
+				fmt.Fprintf(channel.Stderr(), "stderr line\n")
+
+				exitStatus := make([]byte, 4)
+				if strings.Contains(command, "fail") {
+					binary.BigEndian.PutUint32(exitStatus, 1)
+				}
+				channel.SendRequest("exit-status", false, exitStatus)
+				return
+			}
+		}()
+	}
+}
+
+func (s *testSSHServer) remoteOptions() RemoteOptions {
+	return RemoteOptions{
+		Host:                  s.addr,
+		Port:                  s.port,
+		User:                  "validator",
+		KeyFile:               s.keyFile,
+		InsecureIgnoreHostKey: true,
+		AllowedHosts:          []string{s.addr},
+	}
+}
+
+func TestRunRemote_Success(t *testing.T) {
+	server := newTestSSHServer(t)
+
+	for _, streamOutput := range []bool{true, false} {
+		err := RunRemote(context.Background(), RunRemoteOptions{
+			RunOptions: RunOptions{
+				Name:         "remote",
+				Command:      "fence.sh",
+				Args:         []string{"--reason", "it's down"},
+				Env:          map[string]string{"MODE": "stop"},
+				StreamOutput: streamOutput,
+			},
+			Remote: server.remoteOptions(),
+		})
+		require.NoError(t, err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.commands, 2)
+	assert.Equal(t, `env 'MODE=stop' 'fence.sh' '--reason' 'it'\''s down'`, server.commands[0])
+}
+
+func TestRunRemote_Shell(t *testing.T) {
+	server := newTestSSHServer(t)
+
+	err := RunRemote(context.Background(), RunRemoteOptions{
+		RunOptions: RunOptions{
+			Name:    "remote",
+			Command: "journalctl -u sol",
+			Args:    []string{"|", "tail -n 5"},
+			Shell:   true,
+		},
+		Remote: server.remoteOptions(),
+	})
+	require.NoError(t, err)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.commands, 1)
+	assert.Equal(t, `'/bin/sh' '-c' 'journalctl -u sol | tail -n 5'`, server.commands[0])
+}
+
+func TestRunRemote_Failure(t *testing.T) {
+	server := newTestSSHServer(t)
+
+	err := RunRemote(context.Background(), RunRemoteOptions{
+		RunOptions: RunOptions{Name: "remote", Command: "fail.sh"},
+		Remote:     server.remoteOptions(),
+	})
+	assert.Error(t, err)
+}
+
+func TestRunRemote_HostNotAllowed(t *testing.T) {
+	server := newTestSSHServer(t)
+
+	remote := server.remoteOptions()
+	remote.AllowedHosts = []string{"10.0.0.1"}
+
+	err := RunRemote(context.Background(), RunRemoteOptions{
+		RunOptions: RunOptions{Name: "remote", Command: "fence.sh", DryRun: true},
+		Remote:     remote,
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not in the remote command allowlist")
+}
+
+func TestRunRemote_Timeout(t *testing.T) {
+	server := newTestSSHServer(t)
+
+	remote := server.remoteOptions()
+	remote.Timeout = 100 * time.Millisecond
+
+	startedAt := time.Now()
+	err := RunRemote(context.Background(), RunRemoteOptions{
+		RunOptions: RunOptions{Name: "remote", Command: "hang.sh", StreamOutput: true},
+		Remote:     remote,
+	})
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(startedAt), 2*time.Second)
+}
+
+func TestRunRemote_DryRun(t *testing.T) {
+	remote := RemoteOptions{Host: "192.0.2.1", AllowedHosts: []string{"192.0.2.1"}}
+
+	err := RunRemote(context.Background(), RunRemoteOptions{
+		RunOptions: RunOptions{Name: "remote", Command: "fence.sh", DryRun: true},
+		Remote:     remote,
+	})
+	assert.NoError(t, err)
+}
+
+func TestShellPrelude(t *testing.T) {
+	assert.Equal(t, "", shellPrelude("", ""))
+	assert.Equal(t, "cd '/mnt/ledger' && umask 0027 && ", shellPrelude("/mnt/ledger", "0027"))
+}
+
+func TestRunRemoteOptions_Plan(t *testing.T) {
+	opts := RunRemoteOptions{
+		RunOptions: RunOptions{Command: "fence.sh", Args: []string{"--stop-voting"}},
+		Remote:     RemoteOptions{Host: "192.168.1.11", User: "sol", KeyFile: "/home/sol/.ssh/id_ed25519"},
+	}
+	assert.Equal(t, `ssh -p 22 -i '/home/sol/.ssh/id_ed25519' sol@192.168.1.11 ''\''fence.sh'\'' '\''--stop-voting'\'''`, opts.Plan())
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// scheduler serializes the commands run by this process
+var scheduler = NewScheduler()
+
+// Scheduler serializes commands that touch the same resource (e.g. the validator service or an identity file)
+// while letting commands on unrelated resources run in parallel
+type Scheduler struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// Acquire blocks until every resource is held and returns a func that releases them.
+// Resources are acquired in sorted order so commands sharing several resources can never deadlock.
+func (s *Scheduler) Acquire(resources []string, logger *log.Logger) (release func()) {
+	if len(resources) == 0 {
+		return func() {}
+	}
+
+	sorted := slices.Clone(resources)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	held := make([]*sync.Mutex, 0, len(sorted))
+	for _, resource := range sorted {
+		lock := s.lock(resource)
+		if !lock.TryLock() {
+			logger.Info("waiting for another command using resource to finish", "resource", resource)
+			lock.Lock()
+		}
+		held = append(held, lock)
+	}
+
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].Unlock()
+		}
+	}
+}
+
+// lock returns the lock for a resource, creating it on first use
+func (s *Scheduler) lock(resource string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[resource]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[resource] = lock
+	}
+	return lock
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// runConcurrently holds each resource set for a short time from its own goroutine, returning the peak
+// number of holders running at once
+func runConcurrently(scheduler *Scheduler, resourceSets ...[]string) int32 {
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	logger := log.New(io.Discard)
+
+	for _, resources := range resourceSets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := scheduler.Acquire(resources, logger)
+			defer release()
+
+			now := running.Add(1)
+			for {
+				current := peak.Load()
+				if now <= current || peak.CompareAndSwap(current, now) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+
+	wg.Wait()
+	return peak.Load()
+}
+
+func TestScheduler_SerializesSharedResource(t *testing.T) {
+	peak := runConcurrently(NewScheduler(),
+		[]string{"validator"},
+		[]string{"validator"},
+		[]string{"identity", "validator"},
+	)
+	assert.Equal(t, int32(1), peak)
+}
+
+func TestScheduler_ParallelUnrelatedResources(t *testing.T) {
+	peak := runConcurrently(NewScheduler(),
+		[]string{"validator"},
+		[]string{"firewall"},
+		nil,
+	)
+	assert.Equal(t, int32(3), peak)
+}
+
+func TestScheduler_OverlappingResourceSetsDoNotDeadlock(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runConcurrently(NewScheduler(),
+			[]string{"a", "b"},
+			[]string{"b", "a"},
+			[]string{"b", "a", "a"},
+		)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("overlapping resource sets deadlocked")
+	}
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DurationBuckets are the upper bounds, in seconds, command run durations are counted in
+var DurationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// Stats is a snapshot of the run statistics of a named command
+type Stats struct {
+	Name     string `json:"name"`
+	Runs     uint64 `json:"runs"`
+	Failures uint64 `json:"failures"`
+	// DurationSeconds is the total time spent running the command
+	DurationSeconds float64 `json:"duration_seconds"`
+	// DurationBucketCounts are the cumulative number of runs taking at most each of DurationBuckets
+	DurationBucketCounts []uint64 `json:"duration_bucket_counts"`
+}
+
+// statsRecorder records command run statistics keyed by command name
+type statsRecorder struct {
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// stats records the runs of all commands - dry runs are not recorded
+var stats = &statsRecorder{stats: map[string]*Stats{}}
+
+// record records a run of the named command
+func (r *statsRecorder) record(name string, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.stats[name]
+	if !ok {
+		s = &Stats{Name: name, DurationBucketCounts: make([]uint64, len(DurationBuckets))}
+		r.stats[name] = s
+	}
+
+	s.Runs++
+	if err != nil {
+		s.Failures++
+	}
+	s.DurationSeconds += duration.Seconds()
+	for i, bucket := range DurationBuckets {
+		if duration.Seconds() <= bucket {
+			s.DurationBucketCounts[i]++
+		}
+	}
+}
+
+// RunStats returns the run statistics of every command run so far, sorted by name
+func RunStats() []Stats {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	snapshot := make([]Stats, 0, len(stats.stats))
+	for _, s := range stats.stats {
+		s := *s
+		s.DurationBucketCounts = slices.Clone(s.DurationBucketCounts)
+		snapshot = append(snapshot, s)
+	}
+	slices.SortFunc(snapshot, func(a, b Stats) int { return strings.Compare(a.Name, b.Name) })
+	return snapshot
+}
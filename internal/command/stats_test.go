@@ -0,0 +1,46 @@
+package command
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsRecorder_Record(t *testing.T) {
+	recorder := &statsRecorder{stats: map[string]*Stats{}}
+
+	recorder.record("pre-hook notify", 300*time.Millisecond, nil)
+	recorder.record("pre-hook notify", 45*time.Second, errors.New("exit status 1"))
+
+	s := recorder.stats["pre-hook notify"]
+	require.NotNil(t, s)
+	assert.Equal(t, uint64(2), s.Runs)
+	assert.Equal(t, uint64(1), s.Failures)
+	assert.InDelta(t, 45.3, s.DurationSeconds, 0.001)
+
+	// bucket counts are cumulative
+	assert.Equal(t, uint64(0), s.DurationBucketCounts[0]) // 0.1s
+	assert.Equal(t, uint64(1), s.DurationBucketCounts[1]) // 0.5s
+	assert.Equal(t, uint64(1), s.DurationBucketCounts[6]) // 30s
+	assert.Equal(t, uint64(2), s.DurationBucketCounts[7]) // 60s
+}
+
+func TestRunStats(t *testing.T) {
+	name := "stats " + t.Name()
+	scriptPath := createTestScript(t, "exit 1", 0)
+
+	_ = Run(t.Context(), RunOptions{Name: name, Command: scriptPath})
+	_ = Run(t.Context(), RunOptions{Name: name, Command: scriptPath, DryRun: true})
+
+	for _, s := range RunStats() {
+		if s.Name == name {
+			assert.Equal(t, uint64(1), s.Runs, "dry runs are not recorded")
+			assert.Equal(t, uint64(1), s.Failures)
+			return
+		}
+	}
+	t.Fatalf("expected stats for %s", name)
+}
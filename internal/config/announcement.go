@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TakeoverAnnouncement represents the configuration for announcing an intent to take over to peers
+// before running the active command
+type TakeoverAnnouncement struct {
+	// Enabled broadcasts a signed intent to take over to all reachable peers and aborts on objection
+	Enabled bool `koanf:"enabled"`
+	// ObjectionWaitDuration is how long to wait for peers to respond with an objection
+	ObjectionWaitDuration time.Duration `koanf:"objection_wait_duration"`
+	// MaxMessageAgeDuration is the maximum age of a received intent before it is rejected as a replay
+	MaxMessageAgeDuration time.Duration `koanf:"max_message_age_duration"`
+}
+
+// SetDefaults sets default values for the takeover announcement configuration
+func (t *TakeoverAnnouncement) SetDefaults() {
+	if t.ObjectionWaitDuration == 0 {
+		t.ObjectionWaitDuration = 2 * time.Second
+	}
+	if t.MaxMessageAgeDuration == 0 {
+		t.MaxMessageAgeDuration = 30 * time.Second
+	}
+}
+
+// Validate validates the takeover announcement configuration
+func (t *TakeoverAnnouncement) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	if t.ObjectionWaitDuration <= 0 {
+		return fmt.Errorf("failover.takeover_announcement.objection_wait_duration must be greater than zero")
+	}
+
+	if t.MaxMessageAgeDuration <= 0 {
+		return fmt.Errorf("failover.takeover_announcement.max_message_age_duration must be greater than zero")
+	}
+
+	return nil
+}
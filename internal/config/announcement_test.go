@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeoverAnnouncement_SetDefaults(t *testing.T) {
+	announcement := &TakeoverAnnouncement{}
+	announcement.SetDefaults()
+
+	assert.Equal(t, 2*time.Second, announcement.ObjectionWaitDuration)
+	assert.Equal(t, 30*time.Second, announcement.MaxMessageAgeDuration)
+}
+
+func TestTakeoverAnnouncement_Validate(t *testing.T) {
+	// disabled is always valid
+	announcement := &TakeoverAnnouncement{}
+	assert.NoError(t, announcement.Validate())
+
+	// enabled with defaults is valid
+	announcement.Enabled = true
+	announcement.SetDefaults()
+	assert.NoError(t, announcement.Validate())
+
+	// negative objection wait
+	announcement.ObjectionWaitDuration = -time.Second
+	err := announcement.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "objection_wait_duration must be greater than zero")
+
+	// negative max message age
+	announcement.ObjectionWaitDuration = time.Second
+	announcement.MaxMessageAgeDuration = -time.Second
+	err = announcement.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "max_message_age_duration must be greater than zero")
+}
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// consulMinSessionTTL is the shortest TTL Consul accepts for a session
+const consulMinSessionTTL = 10 * time.Second
+
+// arbitrationRaft is the failover.arbitration.url scheme of the lock held by Raft among the peers themselves
+const arbitrationRaft = "raft"
+
+// Arbitration represents the configuration for a lock in etcd or Consul a validator must hold to be active, so
+// peers that can't see each other during a network partition can't both become active
+type Arbitration struct {
+	Enabled bool `koanf:"enabled"`
+	// URL is the lock key - etcd://<host:port>/<key> or consul://<host:port>/<key>, with a +https suffix for TLS,
+	// or raft://<bind host>:<port> for a lock held by Raft among failover.peers, each listening on that port
+	URL string `koanf:"url"`
+	// TTLDuration is how long the lock is held without being renewed - the etcd lease or Consul session TTL
+	TTLDuration time.Duration `koanf:"ttl_duration"`
+	// RenewIntervalDuration is how often the active validator renews the lock
+	RenewIntervalDuration time.Duration `koanf:"renew_interval_duration"`
+	// DataDir is where Raft keeps its log, term and snapshots across restarts
+	DataDir string `koanf:"data_dir"`
+}
+
+// SetDefaults sets default values for the arbitration configuration
+func (a *Arbitration) SetDefaults() {
+	if a.TTLDuration == 0 {
+		a.TTLDuration = 15 * time.Second
+	}
+	if a.RenewIntervalDuration == 0 {
+		a.RenewIntervalDuration = 5 * time.Second
+	}
+	if a.DataDir == "" {
+		a.DataDir = "/var/lib/solana-validator-ha/raft"
+	}
+}
+
+// Validate validates the arbitration configuration
+func (a *Arbitration) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+
+	// etcd leases are whole seconds, and Consul sessions have a minimum TTL
+	store, minTTL := arbitrationRaft, time.Second
+	if a.IsRaft() {
+		if _, _, err := a.RaftAddress(); err != nil {
+			return err
+		}
+		if a.DataDir == "" {
+			return fmt.Errorf("failover.arbitration.data_dir must be set for raft")
+		}
+	} else {
+		lock, err := a.NewLock()
+		if err != nil {
+			return err
+		}
+		store = lock.remote.store
+		if store == remoteStoreConsul {
+			minTTL = consulMinSessionTTL
+		}
+	}
+
+	if a.RenewIntervalDuration <= 0 {
+		return fmt.Errorf("failover.arbitration.renew_interval_duration must be greater than zero")
+	}
+	if a.TTLDuration < minTTL {
+		return fmt.Errorf("failover.arbitration.ttl_duration must be at least %s for %s", minTTL, store)
+	}
+	if a.RenewIntervalDuration >= a.TTLDuration {
+		return fmt.Errorf("failover.arbitration.renew_interval_duration %s must be shorter than ttl_duration %s",
+			a.RenewIntervalDuration, a.TTLDuration)
+	}
+
+	return nil
+}
+
+// ValidateRaftTransport validates that raft arbitration runs over prometheus.health_check_tls mutual TLS - its
+// transport is otherwise unauthenticated, so anyone reaching its port could take the token
+func (a *Arbitration) ValidateRaftTransport(healthCheckTLS HealthCheckTLS) error {
+	if !a.Enabled || !a.IsRaft() {
+		return nil
+	}
+	if !healthCheckTLS.Enabled {
+		return fmt.Errorf("failover.arbitration raft requires prometheus.health_check_tls to authenticate peers")
+	}
+	return nil
+}
+
+// IsRaft returns true if the lock is held by Raft among the peers rather than in etcd or Consul
+func (a *Arbitration) IsRaft() bool {
+	return strings.HasPrefix(a.URL, arbitrationRaft+"://")
+}
+
+// RaftAddress returns the host Raft listens on - all interfaces when empty - and the port every peer's Raft
+// listens on
+func (a *Arbitration) RaftAddress() (bindHost string, port int, err error) {
+	parsed, err := url.Parse(a.URL)
+	if err == nil && parsed.Scheme == arbitrationRaft && parsed.Port() != "" && (parsed.Path == "" || parsed.Path == "/") {
+		port, err = strconv.Atoi(parsed.Port())
+		if err == nil && port > 0 && port <= 65535 {
+			return parsed.Hostname(), port, nil
+		}
+	}
+	return "", 0, fmt.Errorf("failover.arbitration.url must be raft://<bind host>:<port> for raft - got: %s", a.URL)
+}
+
+// ArbitrationLock acquires and renews the failover.arbitration lock. The lock's value is the name of the validator
+// holding it, so a validator restarted within the TTL still holds the lock it took before.
+type ArbitrationLock struct {
+	mu          sync.Mutex
+	arbitration Arbitration
+	remote      *remoteSource
+	// leaseID is the etcd lease the lock lives as long as
+	leaseID int64
+	// sessionID is the Consul session the lock lives as long as
+	sessionID string
+}
+
+// NewLock returns a client for the arbitration lock
+func (a *Arbitration) NewLock() (*ArbitrationLock, error) {
+	if a.URL == "" {
+		return nil, fmt.Errorf("failover.arbitration.url must be set")
+	}
+	if a.IsRaft() {
+		return nil, fmt.Errorf("failover.arbitration.url %s is held by the peers' Raft, not a lock store", a.URL)
+	}
+	remote, err := parseRemoteSource(a.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failover.arbitration.url: %w", err)
+	}
+	remote.client.Timeout = remoteRequestTimeout
+	return &ArbitrationLock{arbitration: *a, remote: remote}, nil
+}
+
+// Acquire acquires the lock for holder, or renews it if holder already holds it, returning the name of the
+// validator holding it - the lock is held by another validator when that isn't holder
+func (l *ArbitrationLock) Acquire(ctx context.Context, holder string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.remote.store == remoteStoreConsul {
+		return l.acquireConsul(ctx, holder)
+	}
+	return l.acquireEtcd(ctx, holder)
+}
+
+// Release releases the lock if holder holds it, so a peer can take it without waiting for the TTL
+func (l *ArbitrationLock) Release(ctx context.Context, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.remote.store == remoteStoreConsul {
+		return l.releaseConsul(ctx)
+	}
+	return l.releaseEtcd(ctx, holder)
+}
+
+// acquireEtcd keeps the lock's etcd lease alive, putting the lock under a new lease if the key is free
+func (l *ArbitrationLock) acquireEtcd(ctx context.Context, holder string) (string, error) {
+	if l.leaseID != 0 {
+		var keepAlive struct {
+			Result struct {
+				TTL int64 `json:"TTL,string"`
+			} `json:"result"`
+		}
+		if err := l.remote.postEtcd(ctx, "/v3/lease/keepalive", map[string]string{"ID": strconv.FormatInt(l.leaseID, 10)}, &keepAlive); err != nil {
+			return "", fmt.Errorf("failed to renew etcd lease: %w", err)
+		}
+		if keepAlive.Result.TTL <= 0 {
+			// the lease lapsed, taking the key with it
+			l.leaseID = 0
+		}
+	}
+
+	if l.leaseID == 0 {
+		var lease struct {
+			ID int64 `json:"ID,string"`
+		}
+		ttl := strconv.FormatInt(int64(l.arbitration.TTLDuration/time.Second), 10)
+		if err := l.remote.postEtcd(ctx, "/v3/lease/grant", map[string]string{"TTL": ttl}, &lease); err != nil {
+			return "", fmt.Errorf("failed to grant etcd lease: %w", err)
+		}
+		if lease.ID == 0 {
+			return "", fmt.Errorf("failed to grant etcd lease: no lease ID returned")
+		}
+		l.leaseID = lease.ID
+	}
+
+	// put the key under our lease only if it doesn't exist, reading its holder otherwise
+	key := []byte(l.remote.key)
+	txn := map[string]any{
+		"compare": []map[string]any{{"key": key, "target": "CREATE", "result": "EQUAL", "create_revision": "0"}},
+		"success": []map[string]any{{"request_put": map[string]any{
+			"key":   key,
+			"value": []byte(holder),
+			"lease": strconv.FormatInt(l.leaseID, 10),
+		}}},
+		"failure": []map[string]any{{"request_range": map[string]any{"key": key}}},
+	}
+	var response struct {
+		Succeeded bool `json:"succeeded"`
+		Responses []struct {
+			ResponseRange struct {
+				KVs []etcdKeyValue `json:"kvs"`
+			} `json:"response_range"`
+		} `json:"responses"`
+	}
+	if err := l.remote.postEtcd(ctx, "/v3/kv/txn", txn, &response); err != nil {
+		return "", fmt.Errorf("failed to acquire etcd key %s: %w", l.remote.key, err)
+	}
+	if response.Succeeded {
+		return holder, nil
+	}
+	if len(response.Responses) == 0 || len(response.Responses[0].ResponseRange.KVs) == 0 {
+		// the key lapsed between the compare and the range, try again next time
+		return "", fmt.Errorf("etcd key %s changed while acquiring it", l.remote.key)
+	}
+	return string(response.Responses[0].ResponseRange.KVs[0].Value), nil
+}
+
+// releaseEtcd deletes the key if holder holds it and revokes the lease
+func (l *ArbitrationLock) releaseEtcd(ctx context.Context, holder string) error {
+	if l.leaseID == 0 {
+		return nil
+	}
+
+	key := []byte(l.remote.key)
+	txn := map[string]any{
+		"compare": []map[string]any{{"key": key, "target": "VALUE", "result": "EQUAL", "value": []byte(holder)}},
+		"success": []map[string]any{{"request_delete_range": map[string]any{"key": key}}},
+	}
+	if err := l.remote.postEtcd(ctx, "/v3/kv/txn", txn, &struct{}{}); err != nil {
+		return fmt.Errorf("failed to release etcd key %s: %w", l.remote.key, err)
+	}
+	if err := l.remote.postEtcd(ctx, "/v3/lease/revoke", map[string]string{"ID": strconv.FormatInt(l.leaseID, 10)}, &struct{}{}); err != nil {
+		return fmt.Errorf("failed to revoke etcd lease: %w", err)
+	}
+	l.leaseID = 0
+	return nil
+}
+
+// acquireConsul renews the lock's Consul session, creating a new one if it lapsed, and acquires the key with it
+func (l *ArbitrationLock) acquireConsul(ctx context.Context, holder string) (string, error) {
+	if l.sessionID != "" {
+		_, response, err := l.remote.consulRequest(ctx, http.MethodPut, "/v1/session/renew/"+l.sessionID, nil, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to renew consul session: %w", err)
+		}
+		if response.StatusCode == http.StatusNotFound {
+			// the session lapsed, deleting the key with it
+			l.sessionID = ""
+		}
+	}
+
+	if l.sessionID == "" {
+		session, err := json.Marshal(map[string]string{
+			"Name":      "solana-validator-ha " + holder,
+			"TTL":       l.arbitration.TTLDuration.String(),
+			"Behavior":  "delete",
+			"LockDelay": "0s",
+		})
+		if err != nil {
+			return "", err
+		}
+		body, _, err := l.remote.consulRequest(ctx, http.MethodPut, "/v1/session/create", nil, strings.NewReader(string(session)))
+		if err != nil {
+			return "", fmt.Errorf("failed to create consul session: %w", err)
+		}
+		var created struct {
+			ID string
+		}
+		if err := json.Unmarshal(body, &created); err != nil || created.ID == "" {
+			return "", fmt.Errorf("failed to create consul session: unexpected response %q", body)
+		}
+		l.sessionID = created.ID
+	}
+
+	body, _, err := l.remote.consulRequest(ctx, http.MethodPut, "/v1/kv/"+l.remote.key, url.Values{"acquire": {l.sessionID}}, strings.NewReader(holder))
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire consul key %s: %w", l.remote.key, err)
+	}
+	if strings.TrimSpace(string(body)) == "true" {
+		return holder, nil
+	}
+
+	value, response, err := l.remote.consulRequest(ctx, http.MethodGet, "/v1/kv/"+l.remote.key, url.Values{"raw": {""}}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to read consul key %s: %w", l.remote.key, err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		// the holder's session lapsed since acquiring, try again next time
+		return "", fmt.Errorf("consul key %s changed while acquiring it", l.remote.key)
+	}
+	return string(value), nil
+}
+
+// releaseConsul releases the key and destroys the session
+func (l *ArbitrationLock) releaseConsul(ctx context.Context) error {
+	if l.sessionID == "" {
+		return nil
+	}
+
+	if _, _, err := l.remote.consulRequest(ctx, http.MethodPut, "/v1/kv/"+l.remote.key, url.Values{"release": {l.sessionID}}, nil); err != nil {
+		return fmt.Errorf("failed to release consul key %s: %w", l.remote.key, err)
+	}
+	if _, _, err := l.remote.consulRequest(ctx, http.MethodPut, "/v1/session/destroy/"+l.sessionID, nil, nil); err != nil {
+		return fmt.Errorf("failed to destroy consul session: %w", err)
+	}
+	l.sessionID = ""
+	return nil
+}
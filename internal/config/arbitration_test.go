@@ -0,0 +1,308 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEtcdLock serves the etcd JSON API calls the arbitration lock makes - leases and single key transactions
+type fakeEtcdLock struct {
+	mu     sync.Mutex
+	value  []byte
+	lease  string
+	alive  map[string]bool
+	nextID int
+}
+
+func newFakeEtcdLock(t *testing.T) (*fakeEtcdLock, *httptest.Server) {
+	etcd := &fakeEtcdLock{alive: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/lease/grant", func(w http.ResponseWriter, r *http.Request) {
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+		etcd.nextID++
+		id := strconv.Itoa(etcd.nextID)
+		etcd.alive[id] = true
+		json.NewEncoder(w).Encode(map[string]string{"ID": id, "TTL": "15"})
+	})
+	mux.HandleFunc("POST /v3/lease/keepalive", func(w http.ResponseWriter, r *http.Request) {
+		var request struct{ ID string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+		ttl := "0"
+		if etcd.alive[request.ID] {
+			ttl = "15"
+		}
+		json.NewEncoder(w).Encode(map[string]any{"result": map[string]string{"ID": request.ID, "TTL": ttl}})
+	})
+	mux.HandleFunc("POST /v3/lease/revoke", func(w http.ResponseWriter, r *http.Request) {
+		var request struct{ ID string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+		delete(etcd.alive, request.ID)
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("POST /v3/kv/txn", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Compare []struct {
+				Target string
+				Value  []byte
+			}
+			Success []struct {
+				RequestPut *struct {
+					Value []byte
+					Lease string
+				} `json:"request_put"`
+				RequestDeleteRange *struct{} `json:"request_delete_range"`
+			}
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+
+		exists := etcd.value != nil && etcd.alive[etcd.lease]
+		compare := request.Compare[0]
+		succeeded := (compare.Target == "CREATE" && !exists) || (compare.Target == "VALUE" && exists && string(compare.Value) == string(etcd.value))
+		if !succeeded {
+			kvs := []map[string][]byte{}
+			if exists {
+				kvs = append(kvs, map[string][]byte{"value": etcd.value})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"responses": []any{map[string]any{"response_range": map[string]any{"kvs": kvs}}}})
+			return
+		}
+
+		if put := request.Success[0].RequestPut; put != nil {
+			etcd.value, etcd.lease = put.Value, put.Lease
+		} else if request.Success[0].RequestDeleteRange != nil {
+			etcd.value = nil
+		}
+		json.NewEncoder(w).Encode(map[string]any{"succeeded": true})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return etcd, server
+}
+
+// expire lapses every lease
+func (e *fakeEtcdLock) expire() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.alive = map[string]bool{}
+}
+
+// fakeConsulLock serves the Consul HTTP API calls the arbitration lock makes - sessions and acquiring a key
+type fakeConsulLock struct {
+	mu       sync.Mutex
+	value    string
+	holder   string
+	sessions map[string]bool
+	nextID   int
+}
+
+func newFakeConsulLock(t *testing.T) (*fakeConsulLock, *httptest.Server) {
+	consul := &fakeConsulLock{sessions: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/session/create", func(w http.ResponseWriter, r *http.Request) {
+		var request struct{ TTL, Behavior string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "delete", request.Behavior)
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		consul.nextID++
+		id := "session-" + strconv.Itoa(consul.nextID)
+		consul.sessions[id] = true
+		json.NewEncoder(w).Encode(map[string]string{"ID": id})
+	})
+	mux.HandleFunc("PUT /v1/session/renew/{id}", func(w http.ResponseWriter, r *http.Request) {
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		if !consul.sessions[r.PathValue("id")] {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("PUT /v1/session/destroy/{id}", func(w http.ResponseWriter, r *http.Request) {
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		delete(consul.sessions, r.PathValue("id"))
+		w.Write([]byte("true"))
+	})
+	mux.HandleFunc("/v1/kv/ha/active", func(w http.ResponseWriter, r *http.Request) {
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		held := consul.holder != "" && consul.sessions[consul.holder]
+		if consul.holder != "" && !held {
+			// the session lapsed, deleting the key
+			consul.value, consul.holder = "", ""
+		}
+
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodGet:
+			if consul.value == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(consul.value))
+		case query.Has("acquire"):
+			session := query.Get("acquire")
+			if held && consul.holder != session {
+				w.Write([]byte("false"))
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			consul.value, consul.holder = string(body), session
+			w.Write([]byte("true"))
+		case query.Has("release"):
+			if consul.holder == query.Get("release") {
+				consul.holder = ""
+			}
+			w.Write([]byte("true"))
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return consul, server
+}
+
+// expire lapses every session
+func (c *fakeConsulLock) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions = map[string]bool{}
+}
+
+func TestArbitration_Validate(t *testing.T) {
+	arbitration := &Arbitration{}
+	assert.NoError(t, arbitration.Validate())
+
+	arbitration = &Arbitration{Enabled: true, URL: "etcd://127.0.0.1:2379/solana-validator-ha/active"}
+	arbitration.SetDefaults()
+	assert.Equal(t, 15*time.Second, arbitration.TTLDuration)
+	assert.Equal(t, 5*time.Second, arbitration.RenewIntervalDuration)
+	assert.NoError(t, arbitration.Validate())
+
+	for _, url := range []string{"consul://127.0.0.1:8500/solana-validator-ha/active", "etcd+https://etcd.example.com/ha/active"} {
+		arbitration.URL = url
+		assert.NoError(t, arbitration.Validate(), url)
+	}
+
+	arbitration.URL = ""
+	assert.EqualError(t, arbitration.Validate(), "failover.arbitration.url must be set")
+	arbitration.URL = "zookeeper://127.0.0.1/active"
+	assert.ErrorContains(t, arbitration.Validate(), "failover.arbitration.url: remote config URL scheme must be one of")
+	arbitration.URL = "etcd://127.0.0.1:2379"
+	assert.ErrorContains(t, arbitration.Validate(), "must have a key path")
+
+	arbitration.URL = "etcd://127.0.0.1:2379/ha/active"
+	arbitration.RenewIntervalDuration = 15 * time.Second
+	assert.EqualError(t, arbitration.Validate(), "failover.arbitration.renew_interval_duration 15s must be shorter than ttl_duration 15s")
+	arbitration.RenewIntervalDuration = 100 * time.Millisecond
+	arbitration.TTLDuration = 500 * time.Millisecond
+	assert.EqualError(t, arbitration.Validate(), "failover.arbitration.ttl_duration must be at least 1s for etcd")
+
+	// consul sessions can't be shorter than 10s
+	arbitration.URL = "consul://127.0.0.1:8500/ha/active"
+	arbitration.TTLDuration = 5 * time.Second
+	assert.EqualError(t, arbitration.Validate(), "failover.arbitration.ttl_duration must be at least 10s for consul")
+
+	// raft among the peers listens on the port every peer uses
+	arbitration.URL = "raft://0.0.0.0:9092"
+	assert.NoError(t, arbitration.Validate())
+	bindHost, port, err := arbitration.RaftAddress()
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", bindHost)
+	assert.Equal(t, 9092, port)
+	arbitration.URL = "raft://:9092"
+	assert.NoError(t, arbitration.Validate())
+
+	for _, url := range []string{"raft://0.0.0.0", "raft://0.0.0.0:9092/active", "raft://0.0.0.0:70000"} {
+		arbitration.URL = url
+		assert.ErrorContains(t, arbitration.Validate(), "failover.arbitration.url must be raft://<bind host>:<port> for raft", url)
+	}
+
+	arbitration.URL = "raft://:9092"
+	arbitration.DataDir = ""
+	assert.EqualError(t, arbitration.Validate(), "failover.arbitration.data_dir must be set for raft")
+}
+
+func TestArbitration_ValidateRaftTransport(t *testing.T) {
+	arbitration := Arbitration{Enabled: true, URL: "raft://:9092"}
+	assert.EqualError(t, arbitration.ValidateRaftTransport(HealthCheckTLS{}), "failover.arbitration raft requires prometheus.health_check_tls to authenticate peers")
+	assert.NoError(t, arbitration.ValidateRaftTransport(HealthCheckTLS{Enabled: true}))
+
+	// etcd and consul authenticate with their own credentials
+	arbitration.URL = "etcd://127.0.0.1:2379/ha/active"
+	assert.NoError(t, arbitration.ValidateRaftTransport(HealthCheckTLS{}))
+}
+
+func TestArbitrationLock_Etcd(t *testing.T) {
+	etcd, server := newFakeEtcdLock(t)
+	testArbitrationLock(t, "etcd://"+strings.TrimPrefix(server.URL, "http://")+"/ha/active", etcd.expire)
+}
+
+func TestArbitrationLock_Consul(t *testing.T) {
+	consul, server := newFakeConsulLock(t)
+	testArbitrationLock(t, "consul://"+strings.TrimPrefix(server.URL, "http://")+"/ha/active", consul.expire)
+}
+
+// testArbitrationLock acquires, renews, loses and releases the lock at url between two validators
+func testArbitrationLock(t *testing.T, url string, expire func()) {
+	arbitration := Arbitration{Enabled: true, URL: url}
+	arbitration.SetDefaults()
+	self, err := arbitration.NewLock()
+	require.NoError(t, err)
+	other, err := arbitration.NewLock()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	holder, err := self.Acquire(ctx, "validator-1")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-1", holder)
+
+	// renewing keeps the lock, and the other validator can't take it
+	holder, err = self.Acquire(ctx, "validator-1")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-1", holder)
+	holder, err = other.Acquire(ctx, "validator-2")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-1", holder)
+
+	// once the lock lapses the other validator takes it
+	expire()
+	holder, err = other.Acquire(ctx, "validator-2")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-2", holder)
+	holder, err = self.Acquire(ctx, "validator-1")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-2", holder)
+
+	// releasing it hands it over without waiting for it to lapse
+	require.NoError(t, other.Release(ctx, "validator-2"))
+	holder, err = self.Acquire(ctx, "validator-1")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-1", holder)
+
+	// releasing a lock held by another validator leaves it held
+	require.NoError(t, other.Release(ctx, "validator-2"))
+	holder, err = other.Acquire(ctx, "validator-2")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-1", holder)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+)
+
+// Prefixes of secrets referenced in AWS - they are fetched with the AWS CLI, which finds credentials with the
+// default credential chain, e.g. the EC2 instance profile
+const (
+	awsSecretsManagerPrefix = "aws-sm:"
+	awsSSMPrefix            = "aws-ssm:"
+)
+
+// awsCLITimeout is how long the AWS CLI has to fetch a secret
+const awsCLITimeout = 30 * time.Second
+
+// awsCLI is the AWS CLI secrets are fetched with
+var awsCLI = "aws"
+
+// resolveSecretRef returns the secret value references, or value itself if it is not a reference. References are
+// aws-sm:<secret-id> for an AWS Secrets Manager secret and aws-ssm:<parameter-name> for an SSM Parameter Store
+// parameter, decrypted if a SecureString. Either may end with #<key> to take a key of a JSON object value. Fetching
+// stops when ctx is done.
+func resolveSecretRef(ctx context.Context, value string) (string, error) {
+	var args []string
+	var id string
+	switch {
+	case strings.HasPrefix(value, awsSecretsManagerPrefix):
+		id = strings.TrimPrefix(value, awsSecretsManagerPrefix)
+		args = []string{"secretsmanager", "get-secret-value", "--secret-id"}
+	case strings.HasPrefix(value, awsSSMPrefix):
+		id = strings.TrimPrefix(value, awsSSMPrefix)
+		args = []string{"ssm", "get-parameter", "--with-decryption", "--name"}
+	default:
+		return value, nil
+	}
+
+	id, key, _ := strings.Cut(id, "#")
+	if id == "" {
+		return "", fmt.Errorf("secret reference %s must name a secret", value)
+	}
+	args = append(args, id, "--output", "json")
+	args = append(args, awsRegionArgs(id)...)
+
+	var output struct {
+		SecretString string
+		Parameter    struct {
+			Value string
+		}
+	}
+	err := command.RunJSON(ctx, command.RunOptions{
+		Name:         "aws",
+		Command:      awsCLI,
+		Args:         args,
+		LoggerPrefix: "config",
+		Timeout:      awsCLITimeout,
+	}, &output)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch secret %s: %w", value, err)
+	}
+
+	secret := output.SecretString
+	if strings.HasPrefix(value, awsSSMPrefix) {
+		secret = output.Parameter.Value
+	}
+	if key == "" {
+		return secret, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret %s must be a JSON object to take key %s from it", id, key)
+	}
+	field, ok := fields[key].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s has no string key %s", id, key)
+	}
+	return field, nil
+}
+
+// awsRegionArgs returns the --region of an ARN so secrets in other regions can be fetched, none for names
+func awsRegionArgs(id string) []string {
+	// arn:partition:service:region:account-id:resource
+	parts := strings.Split(id, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[3] == "" {
+		return nil
+	}
+	return []string{"--region", parts[3]}
+}
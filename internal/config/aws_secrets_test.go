@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAWSCLI points awsCLI at a script answering like the AWS CLI, returning a file it appends each call's args to
+func fakeAWSCLI(t *testing.T) string {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := filepath.Join(dir, "aws")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo "$@" >> `+calls+`
+case "$1 $4" in
+  "secretsmanager missing") echo "An error occurred (ResourceNotFoundException)" >&2; exit 254 ;;
+  "secretsmanager discord") echo '{"SecretString": "https://discord.example/webhook"}' ;;
+  "secretsmanager"*) echo '{"SecretString": "{\"token\": \"json-token\", \"port\": 22}"}' ;;
+  "ssm"*) echo "{\"Parameter\": {\"Value\": \"${FAKE_AWS_SSM_VALUE:-/home/sol/active.json}\"}}" ;;
+esac
+`), 0o755))
+
+	previous := awsCLI
+	awsCLI = script
+	t.Cleanup(func() { awsCLI = previous })
+	return calls
+}
+
+func TestResolveSecretRef(t *testing.T) {
+	calls := fakeAWSCLI(t)
+
+	value, err := resolveSecretRef(context.Background(), "https://discord.example/plain")
+	require.NoError(t, err)
+	assert.Equal(t, "https://discord.example/plain", value)
+
+	value, err = resolveSecretRef(context.Background(), "aws-sm:discord")
+	require.NoError(t, err)
+	assert.Equal(t, "https://discord.example/webhook", value)
+
+	value, err = resolveSecretRef(context.Background(), "aws-sm:arn:aws:secretsmanager:eu-west-1:123456789012:secret:notifiers#token")
+	require.NoError(t, err)
+	assert.Equal(t, "json-token", value)
+
+	value, err = resolveSecretRef(context.Background(), "aws-ssm:/validators/active-keypair-path")
+	require.NoError(t, err)
+	assert.Equal(t, "/home/sol/active.json", value)
+
+	recorded, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"secretsmanager get-secret-value --secret-id discord --output json",
+		"secretsmanager get-secret-value --secret-id arn:aws:secretsmanager:eu-west-1:123456789012:secret:notifiers --output json --region eu-west-1",
+		"ssm get-parameter --with-decryption --name /validators/active-keypair-path --output json",
+	}, strings.Split(strings.TrimSpace(string(recorded)), "\n"))
+}
+
+func TestResolveSecretRef_Errors(t *testing.T) {
+	fakeAWSCLI(t)
+
+	_, err := resolveSecretRef(context.Background(), "aws-sm:missing")
+	assert.ErrorContains(t, err, "failed to fetch secret aws-sm:missing")
+
+	_, err = resolveSecretRef(context.Background(), "aws-sm:notifiers#port")
+	assert.EqualError(t, err, "secret notifiers has no string key port")
+
+	_, err = resolveSecretRef(context.Background(), "aws-sm:discord#token")
+	assert.EqualError(t, err, "secret discord must be a JSON object to take key token from it")
+
+	_, err = resolveSecretRef(context.Background(), "aws-ssm:")
+	assert.EqualError(t, err, "secret reference aws-ssm: must name a secret")
+}
+
+func TestNewFromConfigFile_AWSIdentityPath(t *testing.T) {
+	fakeAWSCLI(t)
+	configFile := createTempConfigFile(t)
+	content, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+
+	// the active keypair path is referenced in SSM and passed to the active command
+	cfg, err := New(NewConfigParams{})
+	require.NoError(t, err)
+	require.NoError(t, cfg.Load(configFile))
+	activeIdentityFile := cfg.Validator.Identities.ActiveKeyPairFile
+	t.Setenv("FAKE_AWS_SSM_VALUE", activeIdentityFile)
+	content = []byte(strings.NewReplacer(
+		`active: "`+activeIdentityFile+`"`, `active: "aws-ssm:/validators/active-keypair-path"`,
+		`command: "systemctl start solana"`, `command: "systemctl start solana --identity {{ .ActiveIdentityKeypairFile }}"`,
+	).Replace(string(content)))
+	require.NoError(t, os.WriteFile(configFile, content, 0o600))
+
+	cfg, err = NewFromConfigFile(context.Background(), configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "systemctl start solana --identity "+activeIdentityFile, cfg.Failover.Active.Command)
+	assert.Equal(t, activeIdentityFile, cfg.Validator.Identities.ActiveKeyPairPath())
+
+	// the reference is shown, not the path it resolved to
+	assert.Equal(t, "aws-ssm:/validators/active-keypair-path", cfg.Validator.Identities.ActiveKeyPairFile)
+	effective, err := cfg.Effective()
+	require.NoError(t, err)
+	assert.Contains(t, string(effective), "active: aws-ssm:/validators/active-keypair-path")
+}
+
+func TestNotificationConfig_ResolveSecrets_AWS(t *testing.T) {
+	fakeAWSCLI(t)
+
+	n := &NotificationConfig{
+		Enabled:  true,
+		Discord:  DiscordConfig{Enabled: true, WebhookURL: "aws-sm:discord"},
+		Telegram: TelegramConfig{Enabled: true, BotToken: "aws-sm:notifiers#token"},
+		// references of disabled services are left as they are
+		Slack: SlackConfig{WebhookURL: "aws-sm:missing"},
+	}
+	require.NoError(t, n.ResolveSecrets(context.Background()))
+
+	assert.Equal(t, "https://discord.example/webhook", n.Discord.WebhookURL)
+	assert.Equal(t, "json-token", n.Telegram.BotToken)
+	assert.Equal(t, "aws-sm:missing", n.Slack.WebhookURL)
+
+	n.PagerDuty = PagerDutyConfig{Enabled: true, RoutingKey: "aws-sm:missing"}
+	assert.ErrorContains(t, n.ResolveSecrets(context.Background()), "notifications.pagerduty.routing_key: failed to fetch secret aws-sm:missing")
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// CatchupGate represents the configuration for refusing to take over while the local validator trails the cluster -
+// promoting a node thousands of slots behind is worse than staying passive
+type CatchupGate struct {
+	Enabled bool `koanf:"enabled"`
+	// MaxSlotsBehind is the furthest the local validator's slot may trail the cluster's to take over
+	MaxSlotsBehind uint64 `koanf:"max_slots_behind"`
+	// MaxWaitDuration is how long a takeover waits for the local validator to catch up before giving up - zero
+	// gives up straight away
+	MaxWaitDuration time.Duration `koanf:"max_wait_duration"`
+}
+
+// SetDefaults sets default values for the catchup gate configuration
+func (c *CatchupGate) SetDefaults() {
+	if c.MaxSlotsBehind == 0 {
+		c.MaxSlotsBehind = 100
+	}
+}
+
+// Validate validates the catchup gate configuration
+func (c *CatchupGate) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MaxWaitDuration < 0 {
+		return fmt.Errorf("failover.catchup_gate.max_wait_duration must not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatchupGate_SetDefaults(t *testing.T) {
+	gate := &CatchupGate{}
+	gate.SetDefaults()
+
+	assert.Equal(t, uint64(100), gate.MaxSlotsBehind)
+	assert.Equal(t, time.Duration(0), gate.MaxWaitDuration)
+}
+
+func TestCatchupGate_Validate(t *testing.T) {
+	// disabled is always valid
+	gate := &CatchupGate{MaxWaitDuration: -time.Second}
+	assert.NoError(t, gate.Validate())
+
+	// enabled with defaults is valid
+	gate = &CatchupGate{Enabled: true}
+	gate.SetDefaults()
+	assert.NoError(t, gate.Validate())
+
+	// negative max wait
+	gate.MaxWaitDuration = -time.Second
+	err := gate.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.catchup_gate.max_wait_duration must not be negative")
+}
@@ -0,0 +1,79 @@
+package config
+
+import "context"
+
+// checkTemplateData is the placeholder data role commands are rendered with by Check, so a config can be checked
+// without its identity keypair files
+var checkTemplateData = RoleCommandTemplateData{
+	ActiveIdentityKeypairFile:  "<active-identity-keypair-file>",
+	ActiveIdentityPubkey:       "<active-identity-pubkey>",
+	PassiveIdentityKeypairFile: "<passive-identity-keypair-file>",
+	PassiveIdentityPubkey:      "<passive-identity-pubkey>",
+}
+
+// CheckOptions are the options for checking a config file
+type CheckOptions struct {
+	// SkipIdentities skips loading the validator.identities keypair files, e.g. in CI where they are not present
+	SkipIdentities bool
+	// Strict rejects unknown config keys as if the config set strict: true
+	Strict bool
+	// SkipCommands skips checking the configured commands exist, e.g. in CI where they are not installed
+	SkipCommands bool
+}
+
+// Check loads, defaults and validates a config file, resolves its notification secrets and renders its role
+// commands against placeholder identities. Unlike NewFromConfigFile it carries on past errors so every failing
+// section is reported, returning the config as far as it was checked with all the errors found.
+func Check(ctx context.Context, configFile string, opts CheckOptions) (*Config, []error) {
+	cfg, err := New(NewConfigParams{})
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	// nothing else can be checked without the file
+	if err := cfg.Load(configFile); err != nil {
+		return cfg, []error{err}
+	}
+
+	cfg.setDefaults()
+	cfg.Strict = cfg.Strict || opts.Strict
+
+	errs := []error{}
+	if !opts.SkipIdentities {
+		if err := cfg.Validator.Identities.Load(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, validate := range cfg.sectionValidators() {
+		if err := validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := cfg.Notifications.ResolveSecrets(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := cfg.Prometheus.Auth.ResolveSecrets(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := cfg.Prometheus.ResolveStaticLabelsEnv(); err != nil {
+		errs = append(errs, err)
+	}
+
+	templateData := checkTemplateData
+	templateData.SelfName = cfg.Validator.Name
+	templateData.Cluster = cfg.Cluster.Name
+	templateData.PeerIPs = cfg.Failover.Peers.GetIPs()
+	if err := cfg.Failover.RenderRoleCommands(templateData); err != nil {
+		errs = append(errs, err)
+	} else if !opts.SkipCommands && !cfg.Failover.SkipPreflight {
+		if err := cfg.Failover.PreflightCommands(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return cfg, errs
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	configFile := createTempConfigFile(t)
+	defer os.Remove(configFile)
+
+	cfg, errs := Check(context.Background(), configFile, CheckOptions{})
+	assert.Empty(t, errs)
+	require.NotNil(t, cfg)
+	assert.NotNil(t, cfg.Validator.Identities.ActiveKeyPair)
+}
+
+func TestCheck_ReportsAllErrors(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+validator:
+  name: "test-validator"
+  rpc_url: "not a url"
+  identities:
+    active: /missing/active.json
+    passive: /missing/passive.json
+cluster:
+  name: "testnet"
+failover:
+  active:
+    command: "set-identity {{ .ActiveIdentityPubkey }}"
+  passive:
+    command: ""
+  peers:
+    validator-1:
+      ip: "192.168.1.10"
+notifications:
+  enabled: true
+  telegram:
+    enabled: true
+    chat_id: "123"
+    bot_token_env: SOLANA_VALIDATOR_HA_TEST_UNSET_TOKEN
+`), 0o600))
+
+	_, errs := Check(context.Background(), configFile, CheckOptions{})
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	assert.GreaterOrEqual(t, len(errs), 4, messages)
+	assert.Contains(t, messages[0], "/missing/active.json")
+	assert.Contains(t, messages, "validator.rpc_url must be a valid URL: invalid URL not a url")
+	assert.Contains(t, messages, "notifications.telegram: environment variable SOLANA_VALIDATOR_HA_TEST_UNSET_TOKEN is not set")
+
+	// identity keypair files are not loaded when skipped
+	_, errs = Check(context.Background(), configFile, CheckOptions{SkipIdentities: true})
+	for _, err := range errs {
+		assert.NotContains(t, err.Error(), "/missing/active.json")
+	}
+}
+
+func TestCheck_RendersPlaceholderIdentities(t *testing.T) {
+	configFile := createTempConfigFile(t)
+	defer os.Remove(configFile)
+
+	content, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	content = []byte(strings.Replace(string(content), `command: "systemctl start solana"`,
+		`command: "set-identity"
+    args: ["{{ .ActiveIdentityPubkey }}", "{{ .SelfName }}"]`, 1))
+	require.NoError(t, os.WriteFile(configFile, content, 0o600))
+
+	cfg, errs := Check(context.Background(), configFile, CheckOptions{})
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"<active-identity-pubkey>", "test-validator"}, cfg.Failover.Active.Args)
+}
+
+func TestCheck_MissingFile(t *testing.T) {
+	_, errs := Check(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"), CheckOptions{})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "error loading config file")
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// CircuitBreaker represents the configuration for freezing automatic takeovers once too many happen within a
+// window - runaway flapping is worse than staying passive
+type CircuitBreaker struct {
+	// Enabled trips the circuit breaker once MaxFailovers takeovers happen within WindowDuration, freezing automatic
+	// takeovers until it is reset manually
+	Enabled bool `koanf:"enabled"`
+	// MaxFailovers is the most takeovers allowed within WindowDuration
+	MaxFailovers int `koanf:"max_failovers"`
+	// WindowDuration is the sliding window takeovers are counted in
+	WindowDuration time.Duration `koanf:"window_duration"`
+}
+
+// SetDefaults sets default values for the circuit breaker configuration
+func (c *CircuitBreaker) SetDefaults() {
+	if c.MaxFailovers == 0 {
+		c.MaxFailovers = 2
+	}
+	if c.WindowDuration == 0 {
+		c.WindowDuration = time.Hour
+	}
+}
+
+// Validate validates the circuit breaker configuration
+func (c *CircuitBreaker) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MaxFailovers <= 0 {
+		return fmt.Errorf("failover.circuit_breaker.max_failovers must be greater than zero")
+	}
+
+	if c.WindowDuration <= 0 {
+		return fmt.Errorf("failover.circuit_breaker.window_duration must be greater than zero")
+	}
+
+	return nil
+}
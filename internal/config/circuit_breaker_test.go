@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_SetDefaults(t *testing.T) {
+	breaker := &CircuitBreaker{}
+	breaker.SetDefaults()
+
+	assert.Equal(t, 2, breaker.MaxFailovers)
+	assert.Equal(t, time.Hour, breaker.WindowDuration)
+}
+
+func TestCircuitBreaker_Validate(t *testing.T) {
+	// disabled is always valid
+	breaker := &CircuitBreaker{MaxFailovers: -1}
+	assert.NoError(t, breaker.Validate())
+
+	// enabled with defaults is valid
+	breaker = &CircuitBreaker{Enabled: true}
+	breaker.SetDefaults()
+	assert.NoError(t, breaker.Validate())
+
+	// negative max failovers
+	breaker.MaxFailovers = -1
+	err := breaker.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.circuit_breaker.max_failovers must be greater than zero")
+
+	// negative window
+	breaker.MaxFailovers = 2
+	breaker.WindowDuration = -time.Hour
+	err = breaker.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.circuit_breaker.window_duration must be greater than zero")
+}
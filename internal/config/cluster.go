@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	solanagorpc "github.com/gagliardetto/solana-go/rpc"
 )
@@ -13,6 +14,12 @@ import (
 type Cluster struct {
 	Name    string   `koanf:"name"`
 	RPCURLs []string `koanf:"rpc_urls"`
+	// RPCDemotionFailureThreshold is the number of consecutive failures before an rpc url is demoted to last resort
+	RPCDemotionFailureThreshold int `koanf:"rpc_demotion_failure_threshold"`
+	// RPCDemotionDuration is how long a demoted rpc url stays demoted before being tried in normal order again
+	RPCDemotionDuration time.Duration `koanf:"rpc_demotion_duration"`
+	// DelinquencyConfirmation confirms the active peer is down with independent RPC endpoints
+	DelinquencyConfirmation DelinquencyConfirmation `koanf:"delinquency_confirmation"`
 }
 
 // Validate validates the cluster configuration
@@ -45,6 +52,21 @@ func (c *Cluster) Validate() error {
 		}
 	}
 
+	// cluster.rpc_demotion_failure_threshold must be positive
+	if c.RPCDemotionFailureThreshold < 0 {
+		return fmt.Errorf("cluster.rpc_demotion_failure_threshold must be positive")
+	}
+
+	// cluster.rpc_demotion_duration must be positive
+	if c.RPCDemotionDuration < 0 {
+		return fmt.Errorf("cluster.rpc_demotion_duration must be positive")
+	}
+
+	// cluster.delinquency_confirmation must be valid
+	if err := c.DelinquencyConfirmation.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -61,4 +83,15 @@ func (c *Cluster) SetDefaults() {
 			c.RPCURLs = []string{solanagorpc.DevNet.RPC}
 		}
 	}
+
+	// demote rpc urls after 5 consecutive failures for 5 minutes by default
+	if c.RPCDemotionFailureThreshold == 0 {
+		c.RPCDemotionFailureThreshold = 5
+	}
+
+	if c.RPCDemotionDuration == 0 {
+		c.RPCDemotionDuration = 5 * time.Minute
+	}
+
+	c.DelinquencyConfirmation.SetDefaults()
 }
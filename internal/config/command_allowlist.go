@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// CommandAllowlist represents the optional strict mode pinning the commands the manager may run
+type CommandAllowlist struct {
+	// Enabled refuses to run any role, hook or snapshot recovery command not in Paths
+	Enabled bool `koanf:"enabled"`
+	// Paths are the absolute paths of the commands allowed to run
+	Paths []string `koanf:"paths"`
+}
+
+// Validate validates the command allowlist configuration
+func (c *CommandAllowlist) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("failover.command_allowlist.paths must have at least one path when enabled")
+	}
+
+	for i, path := range c.Paths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("failover.command_allowlist.paths[%d] must be an absolute path, got %q", i, path)
+		}
+	}
+
+	return nil
+}
+
+// AllowedCommands returns the paths commands are checked against - nil when disabled, allowing any command
+func (c *CommandAllowlist) AllowedCommands() []string {
+	if !c.Enabled {
+		return nil
+	}
+	return c.Paths
+}
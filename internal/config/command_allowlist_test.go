@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandAllowlist_Validate(t *testing.T) {
+	// Test disabled
+	allowlist := &CommandAllowlist{}
+	assert.NoError(t, allowlist.Validate())
+	assert.Nil(t, allowlist.AllowedCommands())
+
+	// Test enabled without paths
+	allowlist.Enabled = true
+	assert.ErrorContains(t, allowlist.Validate(), "failover.command_allowlist.paths must have at least one path")
+
+	// Test relative path
+	allowlist.Paths = []string{"bin/set-identity.sh"}
+	assert.ErrorContains(t, allowlist.Validate(), "failover.command_allowlist.paths[0] must be an absolute path")
+
+	allowlist.Paths = []string{"/usr/local/bin/set-identity.sh"}
+	assert.NoError(t, allowlist.Validate())
+	assert.Equal(t, []string{"/usr/local/bin/set-identity.sh"}, allowlist.AllowedCommands())
+}
+
+func TestFailover_ValidateCommandAllowlist(t *testing.T) {
+	failover := &Failover{
+		CommandAllowlist: CommandAllowlist{Enabled: true, Paths: []string{"/usr/local/bin/set-identity.sh"}},
+		Active:           Role{Command: "/usr/local/bin/set-identity.sh"},
+		Passive:          Role{Command: "/usr/local/bin/set-identity.sh"},
+	}
+	assert.NoError(t, failover.validateCommandAllowlist())
+
+	// Test hook outside the allowlist
+	failover.Active.Hooks.Pre = []Hook{{Name: "notify", Command: "/usr/local/bin/notify.sh"}}
+	err := failover.validateCommandAllowlist()
+	assert.ErrorContains(t, err, "failover.active.hooks.pre[0].command")
+	assert.ErrorContains(t, err, "/usr/local/bin/notify.sh is not in the command allowlist")
+
+	// Test shell mode role command
+	failover.Active.Hooks.Pre = nil
+	failover.Passive.Shell = true
+	assert.ErrorContains(t, failover.validateCommandAllowlist(), "failover.passive.command")
+
+	// Test templated commands are left to be checked when run
+	failover.Passive = Role{Command: "{{ .Identities.Dir }}/set-identity.sh"}
+	assert.NoError(t, failover.validateCommandAllowlist())
+}
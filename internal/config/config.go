@@ -1,16 +1,17 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/knadh/koanf"
-	"github.com/knadh/koanf/parsers/yaml"
-	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/providers/confmap"
 )
 
 const (
@@ -19,6 +20,8 @@ const (
 
 // Config represents the complete configuration
 type Config struct {
+	// Version is the config format version the file is written in, see CurrentVersion
+	Version int `koanf:"version"`
 	// Log
 	Log Log `koanf:"log"`
 	// Validator is the local validator configuration
@@ -31,14 +34,23 @@ type Config struct {
 	Failover Failover `koanf:"failover"`
 	// Notifications is the notification configuration
 	Notifications NotificationConfig `koanf:"notifications"`
-	// File is the file that the config was loaded from
+	// Reload is the configuration for reloading the config file without restarting
+	Reload Reload `koanf:"reload"`
+	// Strict rejects config keys that are not recognised, e.g. misspelt ones, which are otherwise ignored with
+	// a warning
+	Strict bool `koanf:"strict"`
+	// File is the file, or the URL of the remote key, that the config was loaded from
 	File string `koanf:"-"`
 	// GetPublicIPFunc is a function that returns the public IP address of the current validator
 	// it defaults to using external services to get the public IP address, useful for testing to set to
 	// something else
 	GetPublicIPFunc func() (string, error)
 
-	logger *log.Logger
+	logger       *log.Logger
+	unknownKeys  []string
+	deprecations []string
+	// remoteVersion is the Consul index or etcd revision a remote key was loaded at, which Watch starts from
+	remoteVersion int64
 }
 
 // NewConfigParams represents parameters for creating a new Config
@@ -59,34 +71,54 @@ func New(params NewConfigParams) (config *Config, err error) {
 	return config, nil
 }
 
-// NewFromConfigFile creates a new Config from a config file path
-func NewFromConfigFile(configFile string) (*Config, error) {
+// NewFromConfigFile creates a new Config from a config file path, or the URL of a remote key holding it - secrets
+// referenced in AWS are fetched with ctx
+func NewFromConfigFile(ctx context.Context, configFile string) (*Config, error) {
 	// Create new config
 	cfg, err := New(NewConfigParams{})
 	if err != nil {
 		return nil, err
 	}
 
-	// Load from file
-	if err := cfg.LoadFromFile(configFile); err != nil {
+	// Load from file or remote key
+	if err := cfg.Load(configFile); err != nil {
 		return nil, err
 	}
 
 	// Initialize
-	if err := cfg.Initialize(); err != nil {
+	if err := cfg.Initialize(ctx); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// Load loads configuration from a file, or from a remote key if source is a consul:// or etcd:// URL, into the struct
+func (c *Config) Load(source string) error {
+	if isRemoteSource(source) {
+		return c.LoadFromRemote(source)
+	}
+	return c.LoadFromFile(source)
+}
+
 // LoadFromFile loads configuration from file into the struct
 func (c *Config) LoadFromFile(filePath string) error {
+	resolvedPath, err := resolveConfigPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	c.File = resolvedPath
+	return c.loadYAML()
+}
+
+// resolveConfigPath returns the absolute path of a config file, with ~ expanded and symlinks resolved
+func resolveConfigPath(filePath string) (string, error) {
 	// Expand ~ to home directory
 	if strings.HasPrefix(filePath, "~/") || filePath == "~" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return fmt.Errorf("error getting home directory: %w", err)
+			return "", fmt.Errorf("error getting home directory: %w", err)
 		}
 		if filePath == "~" {
 			filePath = homeDir
@@ -98,7 +130,7 @@ func (c *Config) LoadFromFile(filePath string) error {
 	// Resolve to absolute path
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
-		return fmt.Errorf("error resolving absolute path: %w", err)
+		return "", fmt.Errorf("error resolving absolute path: %w", err)
 	}
 
 	// Resolve symlinks to get the actual file path
@@ -109,29 +141,51 @@ func (c *Config) LoadFromFile(filePath string) error {
 		resolvedPath = absPath
 	}
 
-	k := koanf.New(".")
-	c.File = resolvedPath
+	return resolvedPath, nil
+}
 
-	// Load YAML config file
-	if err := k.Load(file.Provider(c.File), yaml.Parser()); err != nil {
+// loadYAML loads the YAML configuration at c.File, merged over the files it includes, into the struct
+func (c *Config) loadYAML() error {
+	// Load YAML config and its includes
+	raw, remoteVersion, err := loadMerged(c.File, nil)
+	if err != nil {
 		return fmt.Errorf("error loading config file: %w", err)
 	}
+	c.remoteVersion = remoteVersion
+
+	// Substitute ${VAR} environment variable references in string values
+	missingEnv := map[string]bool{}
+	interpolateEnvValues(raw, missingEnv)
+	if len(missingEnv) > 0 {
+		return fmt.Errorf("error loading config file: environment variables referenced but not set: %s",
+			strings.Join(slices.Sorted(maps.Keys(missingEnv)), ", "))
+	}
 
-	// Unmarshal into this config struct
-	if err := k.Unmarshal("", c); err != nil {
+	// Move deprecated keys to their current ones
+	if err := c.migrate(raw); err != nil {
+		return fmt.Errorf("error loading config file: %w", err)
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(confmap.Provider(raw, ""), nil); err != nil {
+		return fmt.Errorf("error loading config file: %w", err)
+	}
+
+	// Unmarshal into this config struct, noting keys that match no field
+	if err := c.unmarshal(k); err != nil {
 		return fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
 	return nil
 }
 
-// Initialize processes and validates the loaded configuration
-func (c *Config) Initialize() error {
+// Initialize processes and validates the loaded configuration, fetching secrets referenced in AWS with ctx
+func (c *Config) Initialize(ctx context.Context) error {
 	// Set defaults
 	c.setDefaults()
 
 	// load identity key pair files
-	if err := c.Validator.Identities.Load(); err != nil {
+	if err := c.Validator.Identities.Load(ctx); err != nil {
 		return err
 	}
 
@@ -141,76 +195,118 @@ func (c *Config) Initialize() error {
 	}
 
 	// resolve notification secrets from environment variables
-	if err := c.Notifications.ResolveSecrets(); err != nil {
+	if err := c.Notifications.ResolveSecrets(ctx); err != nil {
+		return err
+	}
+
+	// resolve the metrics server credentials from their files
+	if err := c.Prometheus.Auth.ResolveSecrets(ctx); err != nil {
+		return err
+	}
+
+	// resolve static metric labels from environment variables
+	if err := c.Prometheus.ResolveStaticLabelsEnv(); err != nil {
 		return err
 	}
 
 	// render failover commands, args and hooks
 	err := c.Failover.RenderRoleCommands(RoleCommandTemplateData{
-		ActiveIdentityKeypairFile:  c.Validator.Identities.ActiveKeyPairFile,
+		ActiveIdentityKeypairFile:  c.Validator.Identities.ActiveKeyPairPath(),
 		ActiveIdentityPubkey:       c.Validator.Identities.ActiveKeyPair.PublicKey().String(),
-		PassiveIdentityKeypairFile: c.Validator.Identities.PassiveKeyPairFile,
+		PassiveIdentityKeypairFile: c.Validator.Identities.PassiveKeyPairPath(),
 		PassiveIdentityPubkey:      c.Validator.Identities.PassiveKeyPair.PublicKey().String(),
 		SelfName:                   c.Validator.Name,
+		Cluster:                    c.Cluster.Name,
+		PeerIPs:                    c.Failover.Peers.GetIPs(),
 	})
 	if err != nil {
 		return err
 	}
 
+	// check the rendered commands can be run
+	if !c.Failover.SkipPreflight {
+		if err := c.Failover.PreflightCommands(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // validate validates the configuration
 func (c *Config) validate() error {
-	err := c.Log.Validate()
-	if err != nil {
-		return err
-	}
-
-	err = c.Validator.Validate()
-	if err != nil {
-		return err
+	for _, validate := range c.sectionValidators() {
+		if err := validate(); err != nil {
+			return err
+		}
 	}
 
-	err = c.Cluster.Validate()
-	if err != nil {
-		return err
+	// deprecated keys print warning
+	for _, deprecation := range c.deprecations {
+		c.logger.Warn(deprecation)
 	}
 
-	err = c.Prometheus.Validate()
-	if err != nil {
-		return err
+	// unknown keys if not strict print warning
+	for _, key := range c.unknownKeys {
+		c.logger.Warn("unknown config key ignored - set strict: true to reject unknown keys", "key", key)
 	}
 
-	err = c.Failover.Validate()
-	if err != nil {
-		return err
+	// valid but likely dangerous settings print warning
+	for _, warning := range c.Warnings() {
+		c.logger.Warn(warning)
 	}
 
-	err = c.Notifications.Validate()
-	if err != nil {
-		return err
-	}
-
-	// failover.dry_run if true print warning
-	if c.Failover.DryRun {
-		c.logger.Warn("failover.dry_run is true - failovers will dry-run commands only and be no-op")
-	}
+	return nil
+}
 
-	// failover.takeover_jitter_duration if below 1s print warning
-	if c.Failover.TakeoverJitterDuration > 0 && c.Failover.TakeoverJitterDuration < time.Second {
-		c.logger.Warn("failover.takeover_jitter_duration is below 1s - this may void the usefulness of jitter in preventing race conditions")
+// sectionValidators returns the validate func of each config section, in order
+func (c *Config) sectionValidators() []func() error {
+	return []func() error{
+		c.validateKeys,
+		c.Log.Validate,
+		c.Validator.Validate,
+		c.Cluster.Validate,
+		c.Prometheus.Validate,
+		c.Failover.Validate,
+		// failover.schedules may name this validator or any of failover.peers
+		func() error { return c.Failover.Schedules.Validate(c.Validator.Name, c.Failover.Peers) },
+		// failover.failback.primary may be this validator or any of failover.peers
+		func() error { return c.Failover.Failback.Validate(c.Validator.Name, c.Failover.Peers) },
+		// failover.arbitration raft peers authenticate each other with prometheus.health_check_tls
+		func() error { return c.Failover.Arbitration.ValidateRaftTransport(c.Prometheus.HealthCheckTLS) },
+		c.Notifications.Validate,
 	}
-
-	return nil
 }
 
 // setDefaults sets default values for configuration
 func (c *Config) setDefaults() {
+	if c.Version == 0 {
+		c.Version = CurrentVersion
+	}
 	c.Log.SetDefaults()
 	c.Validator.SetDefaults()
 	c.Cluster.SetDefaults()
 	c.Prometheus.SetDefaults()
 	c.Failover.SetDefaults()
 	c.Notifications.SetDefaults()
+	c.setClientRoleCommands()
+}
+
+// setClientRoleCommands sets the failover.active and failover.passive commands not configured to validator.client's
+// set-identity flow - an unknown client is left for validation to report
+func (c *Config) setClientRoleCommands() {
+	if c.Validator.Client == "" {
+		return
+	}
+	setIdentityClient, err := c.Validator.SetIdentityClient()
+	if err != nil {
+		return
+	}
+
+	if c.Failover.Active.Command == "" {
+		c.Failover.Active.Command, c.Failover.Active.Args = setIdentityClient.SetIdentityCommand("{{ .ActiveIdentityKeypairFile }}", true)
+	}
+	if c.Failover.Passive.Command == "" {
+		c.Failover.Passive.Command, c.Failover.Passive.Args = setIdentityClient.SetIdentityCommand("{{ .PassiveIdentityKeypairFile }}", false)
+	}
 }
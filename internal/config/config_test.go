@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
@@ -44,7 +45,7 @@ func TestNewFromConfigFile(t *testing.T) {
 	tempFile := createTempConfigFile(t)
 	defer os.Remove(tempFile)
 
-	cfg, err := NewFromConfigFile(tempFile)
+	cfg, err := NewFromConfigFile(context.Background(), tempFile)
 	require.NoError(t, err)
 	assert.NotNil(t, cfg)
 	assert.Equal(t, tempFile, cfg.File)
@@ -61,7 +62,7 @@ func TestInitialize(t *testing.T) {
 	err = cfg.LoadFromFile(tempFile)
 	require.NoError(t, err)
 
-	err = cfg.Initialize()
+	err = cfg.Initialize(context.Background())
 	require.NoError(t, err)
 }
 
@@ -74,6 +75,20 @@ func TestSetDefaults(t *testing.T) {
 	assert.Equal(t, 9090, cfg.Prometheus.Port)
 }
 
+func TestSetDefaults_ClientRoleCommands(t *testing.T) {
+	cfg := &Config{}
+	cfg.Validator.Client = "firedancer"
+	cfg.Validator.FdctlConfig = "/home/sol/config.toml"
+	cfg.Failover.Active.Command = "/usr/local/bin/become-active"
+	cfg.setDefaults()
+
+	// a configured role command is kept
+	assert.Equal(t, "/usr/local/bin/become-active", cfg.Failover.Active.Command)
+	assert.Empty(t, cfg.Failover.Active.Args)
+	assert.Equal(t, "fdctl", cfg.Failover.Passive.Command)
+	assert.Equal(t, []string{"set-identity", "--config", "/home/sol/config.toml", "--force", "{{ .PassiveIdentityKeypairFile }}"}, cfg.Failover.Passive.Args)
+}
+
 func TestValidate(t *testing.T) {
 	// Test with valid config (without identities to avoid loading files)
 	cfg := &Config{
@@ -161,8 +176,10 @@ failover:
   takeover_jitter_duration: "10s"
   active:
     command: "systemctl start solana"
+    shell: true
   passive:
     command: "systemctl stop solana"
+    shell: true
   peers:
     validator-1:
       ip: "192.168.1.10"
@@ -218,8 +235,10 @@ failover:
   takeover_jitter_duration: "10s"
   active:
     command: "systemctl start solana"
+    shell: true
   passive:
     command: "systemctl stop solana"
+    shell: true
   peers:
     validator-1:
       ip: "192.168.1.10"
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DelinquencyConfirmation represents the configuration for confirming the active peer is down with independent RPC
+// endpoints before counting a leaderless sample, so a single flaky RPC provider can't trigger a takeover
+type DelinquencyConfirmation struct {
+	// Enabled asks each of RPCURLs whether the active identity is in gossip and voting when cluster.rpc_urls says it
+	// isn't
+	Enabled bool `koanf:"enabled"`
+	// RPCURLs are the independent RPC endpoints asked - each is asked on its own, without falling back to the others
+	RPCURLs []string `koanf:"rpc_urls"`
+	// Quorum is how many of RPCURLs must agree the active identity is down - defaults to a majority
+	Quorum int `koanf:"quorum"`
+}
+
+// SetDefaults sets default values for the delinquency confirmation configuration
+func (d *DelinquencyConfirmation) SetDefaults() {
+	if d.Quorum == 0 {
+		d.Quorum = len(d.RPCURLs)/2 + 1
+	}
+}
+
+// Validate validates the delinquency confirmation configuration
+func (d *DelinquencyConfirmation) Validate() error {
+	if !d.Enabled {
+		return nil
+	}
+
+	if len(d.RPCURLs) == 0 {
+		return fmt.Errorf("cluster.delinquency_confirmation.rpc_urls must be a non-empty list of valid RPC URLs")
+	}
+
+	seen := make(map[string]bool)
+	for _, rpcURL := range d.RPCURLs {
+		parsedURL, err := url.Parse(rpcURL)
+		if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+			return fmt.Errorf("cluster.delinquency_confirmation.rpc_urls must be a list of valid RPC URLs: invalid URL %s", rpcURL)
+		}
+		if seen[rpcURL] {
+			return fmt.Errorf("cluster.delinquency_confirmation.rpc_urls must be unique: %s is listed more than once", rpcURL)
+		}
+		seen[rpcURL] = true
+	}
+
+	if d.Quorum < 1 || d.Quorum > len(d.RPCURLs) {
+		return fmt.Errorf("cluster.delinquency_confirmation.quorum must be between 1 and the number of rpc_urls (%d)", len(d.RPCURLs))
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelinquencyConfirmation_SetDefaults(t *testing.T) {
+	confirmation := &DelinquencyConfirmation{RPCURLs: []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"}}
+	confirmation.SetDefaults()
+	assert.Equal(t, 2, confirmation.Quorum)
+
+	confirmation = &DelinquencyConfirmation{RPCURLs: []string{"https://a.example.com", "https://b.example.com"}, Quorum: 1}
+	confirmation.SetDefaults()
+	assert.Equal(t, 1, confirmation.Quorum)
+}
+
+func TestDelinquencyConfirmation_Validate(t *testing.T) {
+	// disabled is always valid
+	confirmation := &DelinquencyConfirmation{Quorum: -1}
+	assert.NoError(t, confirmation.Validate())
+
+	tests := []struct {
+		name         string
+		confirmation DelinquencyConfirmation
+		err          string
+	}{
+		{"valid", DelinquencyConfirmation{RPCURLs: []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"}, Quorum: 2}, ""},
+		{"no rpc urls", DelinquencyConfirmation{Quorum: 1}, "cluster.delinquency_confirmation.rpc_urls must be a non-empty list"},
+		{"invalid rpc url", DelinquencyConfirmation{RPCURLs: []string{"not-a-url"}, Quorum: 1}, "invalid URL not-a-url"},
+		{"duplicate rpc url", DelinquencyConfirmation{RPCURLs: []string{"https://a.example.com", "https://a.example.com"}, Quorum: 1}, "must be unique"},
+		{"quorum too large", DelinquencyConfirmation{RPCURLs: []string{"https://a.example.com"}, Quorum: 2}, "cluster.delinquency_confirmation.quorum must be between 1 and the number of rpc_urls (1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.confirmation.Enabled = true
+			err := tt.confirmation.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Drain represents the configuration for draining this node for planned maintenance with the drain command - an
+// active node hands over to a peer away from its leader slots
+type Drain struct {
+	// LeaderSlotGap is how many slots away our next leader slot must be for an active node to hand over
+	LeaderSlotGap uint64 `koanf:"leader_slot_gap"`
+	// MaxWaitDuration is the longest an active node waits for LeaderSlotGap before handing over regardless
+	MaxWaitDuration time.Duration `koanf:"max_wait_duration"`
+}
+
+// SetDefaults sets default values for the drain configuration
+func (d *Drain) SetDefaults() {
+	if d.LeaderSlotGap == 0 {
+		d.LeaderSlotGap = 20
+	}
+	if d.MaxWaitDuration == 0 {
+		d.MaxWaitDuration = 5 * time.Minute
+	}
+}
+
+// Validate validates the drain configuration
+func (d *Drain) Validate() error {
+	if d.MaxWaitDuration < 0 {
+		return fmt.Errorf("failover.drain.max_wait_duration must not be negative")
+	}
+	return nil
+}
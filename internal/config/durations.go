@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	solanagorpc "github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// minMainnetLeaderlessWindow is the shortest leaderless window not warned about on mainnet-beta - gossip
+	// routinely misses a voting node for a few seconds, which would otherwise trigger needless failovers
+	minMainnetLeaderlessWindow = 10 * time.Second
+	// minPollInterval is the shortest poll interval not warned about - polling faster risks rpc rate limits
+	minPollInterval = time.Second
+	// maxPollInterval is the longest poll interval not warned about - polling slower delays failovers
+	maxPollInterval = time.Minute
+	// minSnapshotRecoveryCooldown is the shortest snapshot recovery cooldown not warned about
+	minSnapshotRecoveryCooldown = 10 * time.Minute
+)
+
+// leaderlessWindow returns how long the cluster must be leaderless before a takeover with the given samples threshold
+func (f *Failover) leaderlessWindow(samplesThreshold int) time.Duration {
+	return f.PollIntervalDuration * time.Duration(samplesThreshold)
+}
+
+// validateDurations validates the failover durations against each other - the takeover jitter must be shorter than
+// the leaderless window of the default threshold and of every policy overriding it
+func (f *Failover) validateDurations() error {
+	// failover.takeover_jitter_duration must not be negative
+	if f.TakeoverJitterDuration < 0 {
+		return fmt.Errorf("failover.takeover_jitter_duration must not be negative")
+	}
+
+	// failover.takeover_jitter_duration must be shorter than the leaderless window
+	window := f.leaderlessWindow(f.LeaderlessSamplesThreshold)
+	if f.TakeoverJitterDuration >= window {
+		return fmt.Errorf("failover.takeover_jitter_duration %s must be shorter than the leaderless window of %s (poll_interval_duration x leaderless_samples_threshold)",
+			f.TakeoverJitterDuration, window)
+	}
+
+	// failover.takeover_jitter_duration must be shorter than the leaderless window of each policy overriding it
+	for i, policy := range f.Policies {
+		if policy.LeaderlessSamplesThreshold <= 0 {
+			continue
+		}
+		window := f.leaderlessWindow(policy.LeaderlessSamplesThreshold)
+		if f.TakeoverJitterDuration >= window {
+			return fmt.Errorf("failover.policies[%d]: failover.takeover_jitter_duration %s must be shorter than the policy leaderless window of %s",
+				i, f.TakeoverJitterDuration, window)
+		}
+	}
+
+	return nil
+}
+
+// Warnings returns the valid but likely dangerous settings of a validated config, e.g. a leaderless window so
+// short on mainnet-beta that brief gossip gaps trigger failovers
+func (c *Config) Warnings() []string {
+	warnings := []string{}
+
+	// failover.dry_run if true
+	if c.Failover.DryRun {
+		warnings = append(warnings, "failover.dry_run is true - failovers will dry-run commands only and be no-op")
+	}
+
+	// failover.takeover_jitter_duration if below 1s
+	if c.Failover.TakeoverJitterDuration > 0 && c.Failover.TakeoverJitterDuration < time.Second {
+		warnings = append(warnings, "failover.takeover_jitter_duration is below 1s - this may void the usefulness of jitter in preventing race conditions")
+	}
+
+	// failover.poll_interval_duration if outside sane bounds
+	if c.Failover.PollIntervalDuration < minPollInterval {
+		warnings = append(warnings, fmt.Sprintf("failover.poll_interval_duration %s is below %s - polling this often may get rpc endpoints rate limited",
+			c.Failover.PollIntervalDuration, minPollInterval))
+	}
+	if c.Failover.PollIntervalDuration > maxPollInterval {
+		warnings = append(warnings, fmt.Sprintf("failover.poll_interval_duration %s is above %s - failovers will be slow to start",
+			c.Failover.PollIntervalDuration, maxPollInterval))
+	}
+
+	// leaderless windows if too short on mainnet-beta
+	if c.Cluster.Name == solanagorpc.MainNetBeta.Name {
+		if window := c.Failover.leaderlessWindow(c.Failover.LeaderlessSamplesThreshold); window < minMainnetLeaderlessWindow {
+			warnings = append(warnings, fmt.Sprintf("failover leaderless window of %s (poll_interval_duration x leaderless_samples_threshold) is below %s on %s - brief gossip gaps may trigger needless failovers",
+				window, minMainnetLeaderlessWindow, c.Cluster.Name))
+		}
+		for i, policy := range c.Failover.Policies {
+			if policy.LeaderlessSamplesThreshold <= 0 {
+				continue
+			}
+			if window := c.Failover.leaderlessWindow(policy.LeaderlessSamplesThreshold); window < minMainnetLeaderlessWindow {
+				warnings = append(warnings, fmt.Sprintf("failover.policies[%d] leaderless window of %s is below %s on %s - brief gossip gaps may trigger needless failovers",
+					i, window, minMainnetLeaderlessWindow, c.Cluster.Name))
+			}
+		}
+	}
+
+	// failover.snapshot_recovery.cooldown_duration if short enough to loop restarts
+	if c.Failover.SnapshotRecovery.Enabled && c.Failover.SnapshotRecovery.CooldownDuration < minSnapshotRecoveryCooldown {
+		warnings = append(warnings, fmt.Sprintf("failover.snapshot_recovery.cooldown_duration %s is below %s - a validator that keeps falling behind may be restarted in a loop",
+			c.Failover.SnapshotRecovery.CooldownDuration, minSnapshotRecoveryCooldown))
+	}
+
+	return warnings
+}
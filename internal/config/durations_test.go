@@ -0,0 +1,121 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover_ValidateDurations(t *testing.T) {
+	tests := map[string]struct {
+		modify   func(f *Failover)
+		expected string
+	}{
+		"valid": {
+			modify: func(f *Failover) {},
+		},
+		"negative jitter": {
+			modify:   func(f *Failover) { f.TakeoverJitterDuration = -time.Second },
+			expected: "failover.takeover_jitter_duration must not be negative",
+		},
+		"jitter equal to leaderless window": {
+			modify:   func(f *Failover) { f.TakeoverJitterDuration = 15 * time.Second },
+			expected: "failover.takeover_jitter_duration 15s must be shorter than the leaderless window of 15s",
+		},
+		"jitter longer than policy leaderless window": {
+			modify: func(f *Failover) {
+				f.TakeoverJitterDuration = 6 * time.Second
+				f.Policies = FailoverPolicies{{Name: "maintenance", LeaderlessSamplesThreshold: 6}, {Name: "weekend", LeaderlessSamplesThreshold: 1}}
+			},
+			expected: "failover.policies[1]: failover.takeover_jitter_duration 6s must be shorter than the policy leaderless window of 5s",
+		},
+		"policy without threshold override": {
+			modify: func(f *Failover) { f.Policies = FailoverPolicies{{Name: "weekend"}} },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := &Failover{}
+			f.SetDefaults()
+			f.TakeoverJitterDuration = 3 * time.Second
+			test.modify(f)
+			err := f.validateDurations()
+			if test.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestConfig_Warnings(t *testing.T) {
+	tests := map[string]struct {
+		modify   func(c *Config)
+		expected []string
+	}{
+		"defaults": {
+			modify: func(c *Config) {},
+		},
+		"dry run": {
+			modify:   func(c *Config) { c.Failover.DryRun = true },
+			expected: []string{"failover.dry_run is true"},
+		},
+		"short jitter": {
+			modify:   func(c *Config) { c.Failover.TakeoverJitterDuration = 500 * time.Millisecond },
+			expected: []string{"failover.takeover_jitter_duration is below 1s"},
+		},
+		"fast poll interval": {
+			modify: func(c *Config) {
+				c.Cluster.Name = "testnet"
+				c.Failover.PollIntervalDuration = 500 * time.Millisecond
+			},
+			expected: []string{"failover.poll_interval_duration 500ms is below 1s"},
+		},
+		"slow poll interval": {
+			modify:   func(c *Config) { c.Failover.PollIntervalDuration = 2 * time.Minute },
+			expected: []string{"failover.poll_interval_duration 2m0s is above 1m0s"},
+		},
+		"short leaderless window on mainnet": {
+			modify:   func(c *Config) { c.Failover.LeaderlessSamplesThreshold = 1 },
+			expected: []string{"failover leaderless window of 5s (poll_interval_duration x leaderless_samples_threshold) is below 10s on mainnet-beta"},
+		},
+		"short leaderless window on testnet": {
+			modify: func(c *Config) {
+				c.Cluster.Name = "testnet"
+				c.Failover.LeaderlessSamplesThreshold = 1
+			},
+		},
+		"short policy leaderless window on mainnet": {
+			modify: func(c *Config) {
+				c.Failover.TakeoverJitterDuration = time.Second
+				c.Failover.Policies = FailoverPolicies{{Name: "weekend", LeaderlessSamplesThreshold: 1}}
+			},
+			expected: []string{"failover.policies[0] leaderless window of 5s is below 10s on mainnet-beta"},
+		},
+		"short snapshot recovery cooldown": {
+			modify: func(c *Config) {
+				c.Failover.SnapshotRecovery.Enabled = true
+				c.Failover.SnapshotRecovery.CooldownDuration = time.Minute
+			},
+			expected: []string{"failover.snapshot_recovery.cooldown_duration 1m0s is below 10m0s"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Config{Cluster: Cluster{Name: "mainnet-beta"}}
+			c.Failover.SetDefaults()
+			test.modify(c)
+			warnings := c.Warnings()
+			require.Len(t, warnings, len(test.expected), "warnings: %v", warnings)
+			for i, expected := range test.expected {
+				assert.Contains(t, warnings[i], expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// ElectionModeJitter delays each takeover by a second per rank plus a random jitter
+	ElectionModeJitter = "jitter"
+	// ElectionModeRanked delays each takeover by rank_step_duration per candidate in gossip ranked ahead of us, with no
+	// jitter, so which node takes over is predictable
+	ElectionModeRanked = "ranked"
+
+	// PreemptionNever keeps the active role with whichever node has it
+	PreemptionNever = "never"
+	// PreemptionHigherPriority hands the active role over to a ready peer with a higher priority
+	PreemptionHigherPriority = "higher_priority"
+)
+
+// electionModes are the valid failover.election.mode values
+var electionModes = []string{ElectionModeJitter, ElectionModeRanked}
+
+// preemptionRules are the valid failover.election.preemption values
+var preemptionRules = []string{PreemptionNever, PreemptionHigherPriority}
+
+// Election represents the configuration for choosing which passive peer takes over and whether a higher priority
+// peer takes the active role back - ranks are by descending priority, then ascending IP
+type Election struct {
+	// Mode is jitter or ranked
+	Mode string `koanf:"mode"`
+	// RankStepDuration is how much longer each rank waits to take over than the one ahead of it in ranked mode - it
+	// must be long enough for the node ahead to show up active in gossip
+	RankStepDuration time.Duration `koanf:"rank_step_duration"`
+	// Preemption is never or higher_priority
+	Preemption string `koanf:"preemption"`
+	// PreemptionDelay is how long a higher priority peer must stay ready before it preempts the active node
+	PreemptionDelay time.Duration `koanf:"preemption_delay"`
+}
+
+// SetDefaults sets default values for the election configuration
+func (e *Election) SetDefaults() {
+	if e.Mode == "" {
+		e.Mode = ElectionModeJitter
+	}
+	if e.RankStepDuration == 0 {
+		e.RankStepDuration = 5 * time.Second
+	}
+	if e.Preemption == "" {
+		e.Preemption = PreemptionNever
+	}
+	if e.PreemptionDelay == 0 {
+		e.PreemptionDelay = 10 * time.Minute
+	}
+}
+
+// Validate validates the election configuration - unset values are left to their defaults
+func (e *Election) Validate() error {
+	if e.Mode != "" && !slices.Contains(electionModes, e.Mode) {
+		return fmt.Errorf("failover.election.mode must be one of %v", electionModes)
+	}
+
+	if e.Mode == ElectionModeRanked && e.RankStepDuration <= 0 {
+		return fmt.Errorf("failover.election.rank_step_duration must be greater than zero")
+	}
+
+	if e.Preemption != "" && !slices.Contains(preemptionRules, e.Preemption) {
+		return fmt.Errorf("failover.election.preemption must be one of %v", preemptionRules)
+	}
+
+	if e.Preemption == PreemptionHigherPriority && e.PreemptionDelay <= 0 {
+		return fmt.Errorf("failover.election.preemption_delay must be greater than zero")
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElection_SetDefaults(t *testing.T) {
+	election := &Election{}
+	election.SetDefaults()
+
+	assert.Equal(t, ElectionModeJitter, election.Mode)
+	assert.Equal(t, 5*time.Second, election.RankStepDuration)
+	assert.Equal(t, PreemptionNever, election.Preemption)
+	assert.Equal(t, 10*time.Minute, election.PreemptionDelay)
+}
+
+func TestElection_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Election)
+		err    string
+	}{
+		{"defaults", func(e *Election) {}, ""},
+		{"ranked with preemption", func(e *Election) { e.Mode = ElectionModeRanked; e.Preemption = PreemptionHigherPriority }, ""},
+		{"unknown mode", func(e *Election) { e.Mode = "random" }, "failover.election.mode must be one of [jitter ranked]"},
+		{"negative rank step", func(e *Election) { e.Mode = ElectionModeRanked; e.RankStepDuration = -time.Second }, "failover.election.rank_step_duration must be greater than zero"},
+		{"unknown preemption", func(e *Election) { e.Preemption = "always" }, "failover.election.preemption must be one of [never higher_priority]"},
+		{"negative preemption delay", func(e *Election) {
+			e.Preemption = PreemptionHigherPriority
+			e.PreemptionDelay = -time.Second
+		}, "failover.election.preemption_delay must be greater than zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			election := &Election{}
+			election.SetDefaults()
+			tt.modify(election)
+			err := election.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
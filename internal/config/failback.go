@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Failback represents the configuration for automatically handing the active role back to a designated primary
+// once it has been healthy for a while, e.g. after it recovered from the failure that failed it over
+type Failback struct {
+	Enabled bool `koanf:"enabled"`
+	// Primary is the name of the peer, in failover.peers or validator.name, the active role fails back to
+	Primary string `koanf:"primary"`
+	// StabilizationDuration is how long Primary must stay healthy and passive before the active role fails back
+	StabilizationDuration time.Duration `koanf:"stabilization_duration"`
+	// Windows are when failing back is safe - any time when empty
+	Windows []TimeWindow `koanf:"windows"`
+	// HoldDuration is how long other passive peers hold back their takeovers for Primary once it is ready to take
+	// the active role back
+	HoldDuration time.Duration `koanf:"hold_duration"`
+}
+
+// SetDefaults sets default values for the failback configuration
+func (f *Failback) SetDefaults() {
+	if f.StabilizationDuration == 0 {
+		f.StabilizationDuration = 10 * time.Minute
+	}
+	if f.HoldDuration == 0 {
+		f.HoldDuration = time.Minute
+	}
+	for i := range f.Windows {
+		f.Windows[i].SetDefaults()
+	}
+}
+
+// Validate validates the failback configuration - the primary must be selfName or in peers
+func (f *Failback) Validate(selfName string, peers Peers) error {
+	if !f.Enabled {
+		return nil
+	}
+
+	if f.Primary == "" {
+		return fmt.Errorf("failover.failback.primary must be defined when enabled")
+	}
+	if _, ok := peers[f.Primary]; !ok && f.Primary != selfName {
+		return fmt.Errorf("failover.failback.primary %s must be validator.name or in failover.peers", f.Primary)
+	}
+
+	if f.StabilizationDuration <= 0 {
+		return fmt.Errorf("failover.failback.stabilization_duration must be greater than zero")
+	}
+
+	if f.HoldDuration <= 0 {
+		return fmt.Errorf("failover.failback.hold_duration must be greater than zero")
+	}
+
+	for i := range f.Windows {
+		if err := f.Windows[i].Validate(); err != nil {
+			return fmt.Errorf("failover.failback.windows[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// InWindow returns true if failing back is safe at now - always when there are no windows
+func (f *Failback) InWindow(now time.Time) bool {
+	if len(f.Windows) == 0 {
+		return true
+	}
+	for i := range f.Windows {
+		if f.Windows[i].Contains(now) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailback_SetDefaults(t *testing.T) {
+	failback := &Failback{Windows: []TimeWindow{{Start: "02:00", End: "04:00"}}}
+	failback.SetDefaults()
+
+	assert.Equal(t, 10*time.Minute, failback.StabilizationDuration)
+	assert.Equal(t, time.Minute, failback.HoldDuration)
+	assert.Equal(t, "UTC", failback.Windows[0].Timezone)
+}
+
+func TestFailback_Validate(t *testing.T) {
+	peers := Peers{"primary": {Name: "primary", IP: "192.168.1.10"}}
+	valid := func() Failback {
+		failback := Failback{Enabled: true, Primary: "primary"}
+		failback.SetDefaults()
+		return failback
+	}
+
+	// disabled is always valid
+	assert.NoError(t, (&Failback{}).Validate("backup", peers))
+
+	failback := valid()
+	assert.NoError(t, failback.Validate("backup", peers))
+	assert.NoError(t, failback.Validate("primary", nil))
+
+	tests := []struct {
+		name   string
+		modify func(*Failback)
+		err    string
+	}{
+		{"missing primary", func(f *Failback) { f.Primary = "" }, "failover.failback.primary must be defined when enabled"},
+		{"unknown primary", func(f *Failback) { f.Primary = "nobody" }, "failover.failback.primary nobody must be validator.name or in failover.peers"},
+		{"negative stabilization", func(f *Failback) { f.StabilizationDuration = -time.Second }, "failover.failback.stabilization_duration must be greater than zero"},
+		{"negative hold", func(f *Failback) { f.HoldDuration = -time.Second }, "failover.failback.hold_duration must be greater than zero"},
+		{"bad window", func(f *Failback) { f.Windows = []TimeWindow{{Start: "25:00", End: "04:00", Timezone: "UTC"}} }, "failover.failback.windows[0]: start must be a HH:MM time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failback := valid()
+			tt.modify(&failback)
+			err := failback.Validate("backup", peers)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestFailback_InWindow(t *testing.T) {
+	at := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	failback := &Failback{}
+	assert.True(t, failback.InWindow(at))
+
+	failback.Windows = []TimeWindow{{Start: "02:00", End: "04:00", Timezone: "UTC"}}
+	assert.True(t, failback.InWindow(at))
+	assert.False(t, failback.InWindow(at.Add(2*time.Hour)))
+}
@@ -2,24 +2,55 @@ package config
 
 import (
 	"fmt"
-	"net"
+	"maps"
+	"strings"
 	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
 )
 
 // Failover represents failover decision parameters
 type Failover struct {
-	DryRun                     bool          `koanf:"dry_run"`
-	PollIntervalDuration       time.Duration `koanf:"poll_interval_duration"`
-	LeaderlessSamplesThreshold int           `koanf:"leaderless_samples_threshold"`
-	TakeoverJitterDuration     time.Duration `koanf:"takeover_jitter_duration"`
-	Active                     Role          `koanf:"active"`
-	Passive                    Role          `koanf:"passive"`
-	Peers                      Peers         `koanf:"peers"`
+	DryRun                     bool                 `koanf:"dry_run"`
+	PollIntervalDuration       time.Duration        `koanf:"poll_interval_duration"`
+	LeaderlessSamplesThreshold int                  `koanf:"leaderless_samples_threshold"`
+	TakeoverJitterDuration     time.Duration        `koanf:"takeover_jitter_duration"`
+	Election                   Election             `koanf:"election"`
+	TakeoverAnnouncement       TakeoverAnnouncement `koanf:"takeover_announcement"`
+	TakeoverQuorum             TakeoverQuorum       `koanf:"takeover_quorum"`
+	CircuitBreaker             CircuitBreaker       `koanf:"circuit_breaker"`
+	FlapDetection              FlapDetection        `koanf:"flap_detection"`
+	Policies                   FailoverPolicies     `koanf:"policies"`
+	Schedules                  FailoverSchedules    `koanf:"schedules"`
+	SnapshotRecovery           SnapshotRecovery     `koanf:"snapshot_recovery"`
+	IncidentReport             IncidentReport       `koanf:"incident_report"`
+	SSH                        SSH                  `koanf:"ssh"`
+	CommandAllowlist           CommandAllowlist     `koanf:"command_allowlist"`
+	Active                     Role                 `koanf:"active"`
+	Passive                    Role                 `koanf:"passive"`
+	Peers                      Peers                `koanf:"peers"`
+	PeerRegistry               PeerRegistry         `koanf:"peer_registry"`
+	Arbitration                Arbitration          `koanf:"arbitration"`
+	Fencing                    Fencing              `koanf:"fencing"`
+	TowerSync                  TowerSync            `koanf:"tower_sync"`
+	Drain                      Drain                `koanf:"drain"`
+	LeaderSlotGuard            LeaderSlotGuard      `koanf:"leader_slot_guard"`
+	CatchupGate                CatchupGate          `koanf:"catchup_gate"`
+	Failback                   Failback             `koanf:"failback"`
+	// CooldownDuration holds back a takeover within this long of the last role change unless confirmed manually,
+	// so marginally healthy nodes don't ping-pong the active role - zero disables the cooldown
+	CooldownDuration time.Duration `koanf:"cooldown_duration"`
+	// StateFile keeps the role, last role change, failover ID, drain, maintenance mode and circuit breaker across
+	// restarts - not written when empty
+	StateFile string `koanf:"state_file"`
+	// SkipPreflight skips checking the configured commands exist at startup, e.g. when they are installed later
+	SkipPreflight bool `koanf:"skip_preflight"`
 }
 
 func (f *Failover) Validate() error {
 	// failover.poll_interval must be greater than zero
-	if f.PollIntervalDuration == 0 {
+	if f.PollIntervalDuration <= 0 {
 		return fmt.Errorf("failover.poll_interval_duration must be greater than zero")
 	}
 
@@ -28,6 +59,71 @@ func (f *Failover) Validate() error {
 		return fmt.Errorf("failover.leaderless_samples_threshold must be positive and non-zero")
 	}
 
+	// failover.cooldown_duration must not be negative
+	if f.CooldownDuration < 0 {
+		return fmt.Errorf("failover.cooldown_duration must not be negative")
+	}
+
+	// failover.election must be valid
+	if err := f.Election.Validate(); err != nil {
+		return err
+	}
+
+	// failover.takeover_announcement must be valid
+	if err := f.TakeoverAnnouncement.Validate(); err != nil {
+		return err
+	}
+
+	// failover.takeover_quorum must be reachable with failover.peers and its witnesses
+	if err := f.TakeoverQuorum.Validate(f.Peers, f.PeerRegistry.Enabled); err != nil {
+		return err
+	}
+
+	// failover.circuit_breaker must be valid
+	if err := f.CircuitBreaker.Validate(); err != nil {
+		return err
+	}
+
+	// failover.flap_detection must be valid
+	if err := f.FlapDetection.Validate(); err != nil {
+		return err
+	}
+
+	// failover.policies must be valid
+	if err := f.Policies.Validate(); err != nil {
+		return err
+	}
+
+	// failover.drain must be valid
+	if err := f.Drain.Validate(); err != nil {
+		return err
+	}
+
+	// failover.leader_slot_guard must be valid
+	if err := f.LeaderSlotGuard.Validate(); err != nil {
+		return err
+	}
+
+	// failover.catchup_gate must be valid
+	if err := f.CatchupGate.Validate(); err != nil {
+		return err
+	}
+
+	// failover durations must make sense together
+	if err := f.validateDurations(); err != nil {
+		return err
+	}
+
+	// failover.snapshot_recovery must be valid
+	if err := f.SnapshotRecovery.Validate(); err != nil {
+		return err
+	}
+
+	// failover.incident_report must be valid
+	if err := f.IncidentReport.Validate(); err != nil {
+		return err
+	}
+
 	// failover.active.command must be defined
 	if f.Active.Command == "" {
 		return fmt.Errorf("failover.active.command must be defined")
@@ -78,26 +174,220 @@ func (f *Failover) Validate() error {
 		}
 	}
 
-	// failover.peers must be at least 1
-	if len(f.Peers) == 0 {
+	// failover.peers must be at least 1, unless peers are discovered in failover.peer_registry
+	if len(f.Peers) == 0 && !f.PeerRegistry.Enabled {
 		return fmt.Errorf("failover.peers - at least one peer must be defined")
 	}
 
+	// failover.peer_registry must be valid
+	if err := f.PeerRegistry.Validate(); err != nil {
+		return err
+	}
+
+	// failover.arbitration must be valid
+	if err := f.Arbitration.Validate(); err != nil {
+		return err
+	}
+
+	// a raft majority must survive any one peer failing, which takes three
+	if f.Arbitration.Enabled && f.Arbitration.IsRaft() && len(f.Peers) < 2 {
+		return fmt.Errorf("failover.arbitration raft needs at least 2 failover.peers so a majority survives a peer failing")
+	}
+
+	// failover.fencing must be valid and fence failover.peers
+	if err := f.Fencing.Validate(f.Peers); err != nil {
+		return err
+	}
+
+	// failover.tower_sync must be valid
+	if err := f.TowerSync.Validate(&f.SSH); err != nil {
+		return err
+	}
+
+	// hooks running on a remote host need valid failover.ssh config and an allowed host
+	if err := f.validateRemoteHooks(); err != nil {
+		return err
+	}
+
+	// role and hook command resources must be valid
+	if err := f.validateCommandResources(); err != nil {
+		return err
+	}
+
+	// hook when expressions must be valid
+	if err := f.validateHookConditions(); err != nil {
+		return err
+	}
+
+	// failover.command_allowlist must be valid and allow every configured command
+	if err := f.CommandAllowlist.Validate(); err != nil {
+		return err
+	}
+	if err := f.validateCommandAllowlist(); err != nil {
+		return err
+	}
+
 	// failover.peers must have unique valid IP addresses
 	ips := make(map[string]bool)
 	for name, peer := range f.Peers {
-		if net.ParseIP(peer.IP) == nil || net.ParseIP(peer.IP).To4() == nil {
+		ip, err := NormalizeIP(peer.IP)
+		if err != nil {
 			return fmt.Errorf("failover.peers - invalid IP address %s for peer %s", peer.IP, name)
 		}
-		if ips[peer.IP] {
+		if ips[ip] {
 			return fmt.Errorf("failover.peers - duplicate IP address %s found for peer %s", peer.IP, name)
 		}
-		ips[peer.IP] = true
+		ips[ip] = true
+		if peer.Priority < 0 {
+			return fmt.Errorf("failover.peers - priority for peer %s must not be negative", name)
+		}
+	}
+
+	return nil
+}
+
+// validateCommandResources validates the resources role and hook commands declare they touch
+func (f *Failover) validateCommandResources() error {
+	resourcesByPath := map[string][]string{
+		"failover.active.resources":  f.Active.Resources,
+		"failover.passive.resources": f.Passive.Resources,
+	}
+	for path, hooks := range map[string][]Hook{
+		"failover.active.hooks.pre":   f.Active.Hooks.Pre,
+		"failover.active.hooks.post":  f.Active.Hooks.Post,
+		"failover.passive.hooks.pre":  f.Passive.Hooks.Pre,
+		"failover.passive.hooks.post": f.Passive.Hooks.Post,
+	} {
+		for i, hook := range hooks {
+			resourcesByPath[fmt.Sprintf("%s[%d].resources", path, i)] = hook.Resources
+		}
+	}
+
+	for path, resources := range resourcesByPath {
+		for i, resource := range resources {
+			if resource == "" {
+				return fmt.Errorf("%s[%d] must not be empty", path, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateHookConditions validates the when expressions of hooks that declare one
+func (f *Failover) validateHookConditions() error {
+	for path, hooks := range map[string][]Hook{
+		"failover.active.hooks.pre":   f.Active.Hooks.Pre,
+		"failover.active.hooks.post":  f.Active.Hooks.Post,
+		"failover.passive.hooks.pre":  f.Passive.Hooks.Pre,
+		"failover.passive.hooks.post": f.Passive.Hooks.Post,
+	} {
+		for i, hook := range hooks {
+			if hook.When == "" {
+				continue
+			}
+			if err := validateWhen(hook.When); err != nil {
+				return fmt.Errorf("%s[%d].when: %w", path, i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRemoteHooks validates hooks that run on a remote host over SSH
+func (f *Failover) validateRemoteHooks() error {
+	hooksByPath := map[string][]Hook{
+		"failover.active.hooks.pre":   f.Active.Hooks.Pre,
+		"failover.active.hooks.post":  f.Active.Hooks.Post,
+		"failover.passive.hooks.pre":  f.Passive.Hooks.Pre,
+		"failover.passive.hooks.post": f.Passive.Hooks.Post,
+	}
+
+	for path, hooks := range hooksByPath {
+		for i, hook := range hooks {
+			if hook.Host == "" {
+				continue
+			}
+			if err := f.SSH.Validate(); err != nil {
+				return err
+			}
+			if !f.SSH.AllowsHost(hook.Host, f.Peers) {
+				return fmt.Errorf("%s[%d]: host %s must be a failover.peers name or IP, or in failover.ssh.allowed_hosts", path, i, hook.Host)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateCommandAllowlist refuses configured commands the allowlist would refuse to run - templated commands
+// are only known once rendered so are checked when run
+func (f *Failover) validateCommandAllowlist() error {
+	allowed := f.CommandAllowlist.AllowedCommands()
+	if allowed == nil {
+		return nil
+	}
+
+	commandsByPath := map[string]command.RunOptions{
+		"failover.active.command":  {Command: f.Active.Command, Shell: f.Active.Shell},
+		"failover.passive.command": {Command: f.Passive.Command, Shell: f.Passive.Shell},
+	}
+	if f.SnapshotRecovery.Enabled {
+		commandsByPath["failover.snapshot_recovery.command"] = command.RunOptions{Command: f.SnapshotRecovery.Command}
+	}
+	for path, hooks := range map[string][]Hook{
+		"failover.active.hooks.pre":   f.Active.Hooks.Pre,
+		"failover.active.hooks.post":  f.Active.Hooks.Post,
+		"failover.passive.hooks.pre":  f.Passive.Hooks.Pre,
+		"failover.passive.hooks.post": f.Passive.Hooks.Post,
+	} {
+		for i, hook := range hooks {
+			commandsByPath[fmt.Sprintf("%s[%d].command", path, i)] = command.RunOptions{Command: hook.Command, Shell: hook.Shell}
+		}
+	}
+
+	maps.Copy(commandsByPath, f.Fencing.commands())
+
+	for path, opts := range commandsByPath {
+		if strings.Contains(opts.Command, "{{") {
+			continue
+		}
+		opts.AllowedCommands = allowed
+		if err := opts.CheckAllowed(); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
 	}
 
 	return nil
 }
 
+// RolePlan returns the commands becoming roleName runs, in order, as numbered copy-pasteable command lines
+func (f *Failover) RolePlan(roleName string, role *Role) []string {
+	hookRunOptions := func(hookType string) HookRunOptions {
+		return HookRunOptions{
+			HookType: hookType,
+			SSH:      &f.SSH,
+			Peers:    f.Peers,
+		}
+	}
+
+	plan := []string{}
+	addStep := func(step string, line string) {
+		plan = append(plan, fmt.Sprintf("%d. %s: %s", len(plan)+1, step, line))
+	}
+
+	for _, hook := range role.Hooks.Pre {
+		addStep(fmt.Sprintf("pre-%s hook %s%s", roleName, hook.Name, hook.whenPlan()), hook.Plan(hookRunOptions(constants.HookTypePre)))
+	}
+	addStep(roleName+" command", role.Plan(RoleCommandRunOptions{}))
+	for _, hook := range role.Hooks.Post {
+		addStep(fmt.Sprintf("post-%s hook %s%s", roleName, hook.Name, hook.whenPlan()), hook.Plan(hookRunOptions(constants.HookTypePost)))
+	}
+
+	return plan
+}
+
 // RenderRoleCommands renders the failover commands for a given role if they have templated strings
 func (f *Failover) RenderRoleCommands(data RoleCommandTemplateData) (err error) {
 	err = f.Active.RenderCommands(data)
@@ -126,7 +416,51 @@ func (f *Failover) SetDefaults() {
 		f.TakeoverJitterDuration = 3 * time.Second
 	}
 
+	f.Election.SetDefaults()
+	f.TakeoverAnnouncement.SetDefaults()
+	f.TakeoverQuorum.SetDefaults()
+	f.CircuitBreaker.SetDefaults()
+	f.FlapDetection.SetDefaults()
+	f.Policies.SetDefaults()
+	f.Schedules.SetDefaults()
+	f.Failback.SetDefaults()
+	f.SnapshotRecovery.SetDefaults()
+	f.IncidentReport.SetDefaults()
+	f.SSH.SetDefaults()
+	f.PeerRegistry.SetDefaults()
+	f.Arbitration.SetDefaults()
+	f.Fencing.SetDefaults()
+	f.TowerSync.SetDefaults()
+	f.Drain.SetDefaults()
+	f.LeaderSlotGuard.SetDefaults()
+	f.CatchupGate.SetDefaults()
+
+	// Peer IPs are compared in their canonical form - invalid ones are left for Validate to report
+	for name, peer := range f.Peers {
+		if ip, err := NormalizeIP(peer.IP); err == nil {
+			peer.IP = ip
+			f.Peers[name] = peer
+		}
+	}
+
 	// Set role names
 	f.Active.Name = "active"
 	f.Passive.Name = "passive"
+
+	// Role commands change the validator identity
+	if len(f.Active.Resources) == 0 {
+		f.Active.Resources = []string{defaultCommandResource}
+	}
+	if len(f.Passive.Resources) == 0 {
+		f.Passive.Resources = []string{defaultCommandResource}
+	}
+}
+
+// LeaderlessSamplesThresholdAt returns the leaderless samples threshold in effect at the given time,
+// taking any matching failover policy override into account
+func (f *Failover) LeaderlessSamplesThresholdAt(now time.Time) int {
+	if policy := f.Policies.Active(now); policy != nil && policy.LeaderlessSamplesThreshold > 0 {
+		return policy.LeaderlessSamplesThreshold
+	}
+	return f.LeaderlessSamplesThreshold
 }
@@ -89,6 +89,52 @@ func TestFailover_Validate(t *testing.T) {
 	err = failover.Validate()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failover.peers - duplicate IP address")
+
+	// Test with negative priority
+	failover.Peers = Peers{
+		"validator-1": {IP: "192.168.1.10", Priority: -1},
+	}
+	err = failover.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.peers - priority for peer validator-1 must not be negative")
+
+	// Test with IPv6 addresses, which are duplicates in any form
+	failover.Peers = Peers{
+		"validator-1": {IP: "2001:db8::1"},
+		"validator-2": {IP: "[2001:db8::2]"},
+	}
+	assert.NoError(t, failover.Validate())
+	failover.Peers["validator-3"] = Peer{IP: "[2001:0db8::0001]:8001"}
+	err = failover.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.peers - duplicate IP address")
+
+	// Test with raft arbitration, which needs a majority of three
+	failover.Peers = Peers{
+		"validator-1": {IP: "192.168.1.10"},
+	}
+	failover.Arbitration = Arbitration{Enabled: true, URL: "raft://0.0.0.0:9092"}
+	failover.Arbitration.SetDefaults()
+	err = failover.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.arbitration raft needs at least 2 failover.peers")
+	failover.Peers["validator-2"] = Peer{IP: "192.168.1.11"}
+	assert.NoError(t, failover.Validate())
+}
+
+func TestFailover_SetDefaultsNormalizesPeerIPs(t *testing.T) {
+	failover := &Failover{
+		Peers: Peers{
+			"validator-1": {IP: "[2001:0db8::0001]:8001"},
+			"validator-2": {IP: "192.168.1.11"},
+			"validator-3": {IP: "invalid-ip"},
+		},
+	}
+	failover.SetDefaults()
+
+	assert.Equal(t, "2001:db8::1", failover.Peers["validator-1"].IP)
+	assert.Equal(t, "192.168.1.11", failover.Peers["validator-2"].IP)
+	assert.Equal(t, "invalid-ip", failover.Peers["validator-3"].IP)
 }
 
 func TestFailover_ValidateWithHooks(t *testing.T) {
@@ -139,3 +185,72 @@ func TestFailover_ValidateWithHooks(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failover.active.hooks.pre must have a command")
 }
+
+func TestFailover_CommandResources(t *testing.T) {
+	failover := &Failover{
+		PollIntervalDuration:       30 * time.Second,
+		LeaderlessSamplesThreshold: 10,
+		Active: Role{
+			Command: "systemctl start solana",
+			Hooks: Hooks{
+				Pre: []Hook{{Name: "firewall", Command: "ufw allow 8001", Resources: []string{"firewall"}}},
+			},
+		},
+		Passive: Role{
+			Command:   "systemctl stop solana",
+			Resources: []string{"validator", "identity"},
+		},
+		Peers: Peers{
+			"validator-1": {IP: "192.168.1.10"},
+		},
+	}
+	failover.SetDefaults()
+	assert.NoError(t, failover.Validate())
+
+	// role commands touch the validator unless configured otherwise
+	assert.Equal(t, []string{"validator"}, failover.Active.Resources)
+	assert.Equal(t, []string{"validator", "identity"}, failover.Passive.Resources)
+	assert.Equal(t, []string{"validator"}, failover.SnapshotRecovery.Resources)
+
+	// Test with an empty hook resource
+	failover.Active.Hooks.Pre[0].Resources = []string{""}
+	err := failover.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.active.hooks.pre[0].resources[0] must not be empty")
+}
+
+func TestFailover_ValidateHookConditions(t *testing.T) {
+	failover := &Failover{
+		Active: Role{Hooks: Hooks{Pre: []Hook{{Name: "mainnet", Command: "echo", When: "cluster == 'mainnet-beta'"}}}},
+	}
+	assert.NoError(t, failover.validateHookConditions())
+
+	failover.Passive.Hooks.Post = []Hook{{Name: "typo", Command: "echo", When: "previous_rol == 'active'"}}
+	err := failover.validateHookConditions()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.passive.hooks.post[0].when: unknown variable previous_rol")
+}
+
+func TestFailover_RolePlan(t *testing.T) {
+	failover := &Failover{
+		SSH: SSH{User: "sol", Port: 2222, KeyFile: "/home/sol/.ssh/id_ed25519"},
+		Peers: Peers{
+			"peer1": {IP: "192.168.1.101", Name: "peer1"},
+		},
+		Active: Role{
+			Command: "/usr/local/bin/set-identity.sh",
+			Args:    []string{"active"},
+			Env:     map[string]string{"MODE": "active"},
+			Hooks: Hooks{
+				Pre:  []Hook{{Name: "fence", Command: "fence.sh", Host: "peer1"}},
+				Post: []Hook{{Name: "notify", Command: "notify.sh", WorkingDir: "/tmp", When: "not dry_run"}},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		`1. pre-active hook fence: ssh -p 2222 -i '/home/sol/.ssh/id_ed25519' sol@192.168.1.101 ''\''fence.sh'\'''`,
+		"2. active command: env 'MODE=active' '/usr/local/bin/set-identity.sh' 'active'",
+		"3. post-active hook notify (when not dry_run): cd '/tmp' && 'notify.sh'",
+	}, failover.RolePlan("active", &failover.Active))
+}
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+)
+
+// fence agent types
+const (
+	FenceAgentTypeRedfish = "redfish"
+	FenceAgentTypeIPMI    = "ipmi"
+	FenceAgentTypeCommand = "command"
+)
+
+// fence agent actions
+const (
+	FenceActionOff   = "off"
+	FenceActionCycle = "cycle"
+)
+
+// redfishPowerOffPollInterval is how often a redfish fence agent checks the system has powered off
+const redfishPowerOffPollInterval = time.Second
+
+// Fencing represents the configuration for fencing the old active peer - powering it off or isolating it - before
+// we take over, so an active that is unresponsive but possibly still voting can't vote alongside us
+type Fencing struct {
+	Enabled bool `koanf:"enabled"`
+	// MustSucceed aborts the takeover unless the old active peer is fenced - defaults to true
+	MustSucceed *bool `koanf:"must_succeed"`
+	// Peers are the fence agents of each failover.peers peer, by name, tried in order until one fences it
+	Peers map[string][]FenceAgent `koanf:"peers"`
+}
+
+// FenceAgent represents one way of fencing a peer - its BMC over Redfish or IPMI, or a command, e.g. a cloud
+// provider CLI stopping its instance or shutting its switch port
+type FenceAgent struct {
+	Name string `koanf:"name"`
+	// Type is redfish, ipmi or command
+	Type string `koanf:"type"`
+	// Action is off (default) or cycle - redfish and ipmi only
+	Action string `koanf:"action"`
+	// URL is the peer's Redfish computer system, e.g. https://10.0.0.12/redfish/v1/Systems/1 - redfish only
+	URL string `koanf:"url"`
+	// TLS configures the TLS the Redfish BMC is reached with, e.g. its private CA - redfish only
+	TLS NotificationTLS `koanf:"tls"`
+	// Address is the peer's BMC address - ipmi only
+	Address string `koanf:"address"`
+	// Username authenticates with the BMC - redfish and ipmi only
+	Username string `koanf:"username"`
+	// Password authenticates with the Redfish BMC - redfish only
+	Password string `koanf:"password"`
+	// PasswordFile holds the IPMI password, read from the IPMI_PASSWORD environment variable when empty - ipmi only
+	PasswordFile string `koanf:"password_file"`
+	// Command and Args are what fences the peer - for ipmi, the ipmitool to run (default: ipmitool)
+	Command string   `koanf:"command"`
+	Args    []string `koanf:"args"`
+	// Shell runs the command and args as a single line through /bin/sh -c - command only
+	Shell bool `koanf:"shell"`
+	// TimeoutDuration is the most fencing the peer with this agent may take
+	TimeoutDuration time.Duration `koanf:"timeout_duration"`
+}
+
+// FenceOptions represents options for fencing a peer
+type FenceOptions struct {
+	DryRun       bool
+	LoggerPrefix string
+	LoggerArgs   []any
+	// AllowedCommands, if set, are the only commands ipmi and command agents may run - see failover.command_allowlist
+	AllowedCommands []string
+}
+
+// SetDefaults sets default values for the fencing configuration
+func (f *Fencing) SetDefaults() {
+	for name, agents := range f.Peers {
+		for i := range agents {
+			agents[i].setDefaults()
+		}
+		f.Peers[name] = agents
+	}
+}
+
+// IsMustSucceed returns whether the takeover is aborted unless the old active peer is fenced - defaults to true
+func (f *Fencing) IsMustSucceed() bool {
+	return f.MustSucceed == nil || *f.MustSucceed
+}
+
+// Validate validates the fencing configuration against the failover.peers it fences
+func (f *Fencing) Validate(peers Peers) error {
+	if !f.Enabled {
+		return nil
+	}
+
+	if len(f.Peers) == 0 {
+		return fmt.Errorf("failover.fencing.peers must have fence agents for at least one peer")
+	}
+
+	for name, agents := range f.Peers {
+		if _, ok := peers[name]; !ok {
+			return fmt.Errorf("failover.fencing.peers.%s must be a failover.peers name", name)
+		}
+		if len(agents) == 0 {
+			return fmt.Errorf("failover.fencing.peers.%s must have at least one fence agent", name)
+		}
+		for i, agent := range agents {
+			if err := agent.validate(); err != nil {
+				return fmt.Errorf("failover.fencing.peers.%s[%d]: %w", name, i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// commands returns the commands of the ipmi and command fence agents, by their config path
+func (f *Fencing) commands() map[string]command.RunOptions {
+	commandsByPath := map[string]command.RunOptions{}
+	if !f.Enabled {
+		return commandsByPath
+	}
+	for name, agents := range f.Peers {
+		for i, agent := range agents {
+			if agent.Type == FenceAgentTypeIPMI || agent.Type == FenceAgentTypeCommand {
+				commandsByPath[fmt.Sprintf("failover.fencing.peers.%s[%d].command", name, i)] = agent.runOptions(FenceOptions{})
+			}
+		}
+	}
+	return commandsByPath
+}
+
+// setDefaults sets default values for the fence agent
+func (a *FenceAgent) setDefaults() {
+	if a.Type != FenceAgentTypeCommand && a.Action == "" {
+		a.Action = FenceActionOff
+	}
+	if a.Type == FenceAgentTypeIPMI && a.Command == "" {
+		a.Command = "ipmitool"
+	}
+	if a.TimeoutDuration == 0 {
+		a.TimeoutDuration = 30 * time.Second
+	}
+}
+
+// validate validates the fence agent
+func (a *FenceAgent) validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("must have a name")
+	}
+
+	if a.TimeoutDuration <= 0 {
+		return fmt.Errorf("timeout_duration must be greater than zero")
+	}
+
+	switch a.Type {
+	case FenceAgentTypeRedfish:
+		parsed, err := url.Parse(a.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("url must be the http(s) URL of a Redfish computer system - got: %s", a.URL)
+		}
+		if err := a.TLS.Validate("tls"); err != nil {
+			return err
+		}
+	case FenceAgentTypeIPMI:
+		if a.Address == "" {
+			return fmt.Errorf("address must be set for ipmi")
+		}
+	case FenceAgentTypeCommand:
+		if a.Command == "" {
+			return fmt.Errorf("command must be set for command")
+		}
+		return nil
+	default:
+		return fmt.Errorf("type must be one of %s, %s or %s - got: %q", FenceAgentTypeRedfish, FenceAgentTypeIPMI, FenceAgentTypeCommand, a.Type)
+	}
+
+	if !slices.Contains([]string{FenceActionOff, FenceActionCycle}, a.Action) {
+		return fmt.Errorf("action must be %s or %s - got: %q", FenceActionOff, FenceActionCycle, a.Action)
+	}
+
+	return nil
+}
+
+// Fence fences the peer with the agent, returning once the peer is fenced or ctx is done - dry runs only log
+// what would run
+func (a *FenceAgent) Fence(ctx context.Context, opts FenceOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, a.TimeoutDuration)
+	defer cancel()
+
+	switch a.Type {
+	case FenceAgentTypeRedfish:
+		if opts.DryRun {
+			return nil
+		}
+		return a.fenceRedfish(ctx)
+	default:
+		return command.Run(ctx, a.runOptions(opts))
+	}
+}
+
+// runOptions returns the options an ipmi or command agent's command is run with
+func (a *FenceAgent) runOptions(opts FenceOptions) command.RunOptions {
+	runOptions := command.RunOptions{
+		Name:            fmt.Sprintf("fence-agent %s", a.Name),
+		Command:         a.Command,
+		Args:            a.Args,
+		DryRun:          opts.DryRun,
+		LoggerPrefix:    opts.LoggerPrefix,
+		LoggerArgs:      append([]any{"fence_agent", a.Name, "dry_run", opts.DryRun}, opts.LoggerArgs...),
+		StreamOutput:    true,
+		Shell:           a.Shell,
+		AllowedCommands: opts.AllowedCommands,
+	}
+
+	if a.Type == FenceAgentTypeIPMI {
+		// the password is kept off the command line, in a file or IPMI_PASSWORD
+		password := []string{"-E"}
+		if a.PasswordFile != "" {
+			password = []string{"-f", a.PasswordFile}
+		}
+		runOptions.Args = append([]string{"-I", "lanplus", "-H", a.Address, "-U", a.Username}, password...)
+		runOptions.Args = append(runOptions.Args, "chassis", "power", a.Action)
+		runOptions.Shell = false
+	}
+
+	return runOptions
+}
+
+// Plan returns the copy-pasteable command line, or Redfish request, the agent fences its peer with
+func (a *FenceAgent) Plan() string {
+	if a.Type == FenceAgentTypeRedfish {
+		return fmt.Sprintf("POST %s {\"ResetType\": %q}", a.redfishResetURL(), a.redfishResetType())
+	}
+	return a.runOptions(FenceOptions{}).Plan()
+}
+
+// redfishResetURL returns the URL of the computer system's reset action
+func (a *FenceAgent) redfishResetURL() string {
+	return strings.TrimSuffix(a.URL, "/") + "/Actions/ComputerSystem.Reset"
+}
+
+// redfishResetType returns the Redfish ResetType of the agent's action
+func (a *FenceAgent) redfishResetType() string {
+	if a.Action == FenceActionCycle {
+		return "ForceRestart"
+	}
+	return "ForceOff"
+}
+
+// fenceRedfish resets the computer system at the agent's URL, then for off waits until it reports being powered off
+func (a *FenceAgent) fenceRedfish(ctx context.Context) error {
+	tlsConfig, err := a.TLS.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+	body, err := json.Marshal(map[string]string{"ResetType": a.redfishResetType()})
+	if err != nil {
+		return err
+	}
+	if _, err := a.redfishRequest(ctx, client, http.MethodPost, a.redfishResetURL(), body); err != nil {
+		return fmt.Errorf("redfish reset failed: %w", err)
+	}
+
+	if a.Action != FenceActionOff {
+		return nil
+	}
+
+	// the reset is only accepted - the peer is fenced once it is off
+	for {
+		response, err := a.redfishRequest(ctx, client, http.MethodGet, a.URL, nil)
+		if err == nil {
+			var system struct{ PowerState string }
+			if err := json.Unmarshal(response, &system); err == nil && system.PowerState == "Off" {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("redfish system %s did not power off: %w", a.URL, context.Cause(ctx))
+		case <-time.After(redfishPowerOffPollInterval):
+		}
+	}
+}
+
+// redfishRequest makes a Redfish API request with the agent's credentials, returning the response body
+func (a *FenceAgent) redfishRequest(ctx context.Context, client *http.Client, method, requestURL string, body []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if a.Username != "" {
+		request.SetBasicAuth(a.Username, a.Password)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var responseBody bytes.Buffer
+	if _, err := responseBody.ReadFrom(response.Body); err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s returned %s: %s", method, requestURL, response.Status, strings.TrimSpace(responseBody.String()))
+	}
+	return responseBody.Bytes(), nil
+}
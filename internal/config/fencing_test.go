@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFencing_Validate(t *testing.T) {
+	peers := Peers{
+		"validator-1": {IP: "192.168.1.10"},
+		"validator-2": {IP: "192.168.1.11"},
+	}
+
+	fencing := &Fencing{}
+	assert.NoError(t, fencing.Validate(peers))
+	assert.True(t, fencing.IsMustSucceed())
+
+	fencing = &Fencing{
+		Enabled: true,
+		Peers: map[string][]FenceAgent{
+			"validator-1": {
+				{Name: "bmc", Type: FenceAgentTypeRedfish, URL: "https://10.0.0.10/redfish/v1/Systems/1"},
+				{Name: "bmc ipmi", Type: FenceAgentTypeIPMI, Address: "10.0.0.10", Username: "admin"},
+			},
+			"validator-2": {
+				{Name: "stop instance", Type: FenceAgentTypeCommand, Command: "aws", Args: []string{"ec2", "stop-instances", "--force"}},
+			},
+		},
+	}
+	fencing.SetDefaults()
+	assert.NoError(t, fencing.Validate(peers))
+	agent := fencing.Peers["validator-1"][1]
+	assert.Equal(t, FenceActionOff, agent.Action)
+	assert.Equal(t, "ipmitool", agent.Command)
+	assert.Equal(t, 30*time.Second, agent.TimeoutDuration)
+	assert.Empty(t, fencing.Peers["validator-2"][0].Action)
+
+	mustSucceed := false
+	fencing.MustSucceed = &mustSucceed
+	assert.False(t, fencing.IsMustSucceed())
+
+	fencing.Peers["validator-3"] = fencing.Peers["validator-2"]
+	assert.EqualError(t, fencing.Validate(peers), "failover.fencing.peers.validator-3 must be a failover.peers name")
+	delete(fencing.Peers, "validator-3")
+
+	for _, tc := range []struct {
+		agent FenceAgent
+		err   string
+	}{
+		{FenceAgent{Type: FenceAgentTypeCommand, Command: "true"}, "must have a name"},
+		{FenceAgent{Name: "pdu", Type: "pdu"}, `type must be one of redfish, ipmi or command - got: "pdu"`},
+		{FenceAgent{Name: "bmc", Type: FenceAgentTypeRedfish, URL: "10.0.0.10"}, "url must be the http(s) URL of a Redfish computer system"},
+		{FenceAgent{Name: "bmc", Type: FenceAgentTypeIPMI}, "address must be set for ipmi"},
+		{FenceAgent{Name: "bmc", Type: FenceAgentTypeIPMI, Address: "10.0.0.10", Action: "reboot"}, `action must be off or cycle - got: "reboot"`},
+		{FenceAgent{Name: "switch", Type: FenceAgentTypeCommand}, "command must be set for command"},
+	} {
+		fencing.Peers["validator-2"] = []FenceAgent{tc.agent}
+		fencing.SetDefaults()
+		assert.ErrorContains(t, fencing.Validate(peers), "failover.fencing.peers.validator-2[0]: "+tc.err)
+	}
+}
+
+func TestFenceAgent_Plan(t *testing.T) {
+	ipmi := FenceAgent{Name: "bmc", Type: FenceAgentTypeIPMI, Address: "10.0.0.10", Username: "admin"}
+	ipmi.setDefaults()
+	assert.Equal(t, "'ipmitool' '-I' 'lanplus' '-H' '10.0.0.10' '-U' 'admin' '-E' 'chassis' 'power' 'off'", ipmi.Plan())
+	ipmi.PasswordFile = "/etc/solana-validator-ha/ipmi-password"
+	ipmi.Action = FenceActionCycle
+	assert.Equal(t, "'ipmitool' '-I' 'lanplus' '-H' '10.0.0.10' '-U' 'admin' '-f' '/etc/solana-validator-ha/ipmi-password' 'chassis' 'power' 'cycle'", ipmi.Plan())
+
+	redfish := FenceAgent{Name: "bmc", Type: FenceAgentTypeRedfish, URL: "https://10.0.0.10/redfish/v1/Systems/1/", Action: FenceActionCycle}
+	assert.Equal(t, `POST https://10.0.0.10/redfish/v1/Systems/1/Actions/ComputerSystem.Reset {"ResetType": "ForceRestart"}`, redfish.Plan())
+}
+
+func TestFenceAgent_FenceRedfish(t *testing.T) {
+	var mu sync.Mutex
+	powerState, polls := "On", 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /redfish/v1/Systems/1/Actions/ComputerSystem.Reset", func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "admin", username)
+		assert.Equal(t, "secret", password)
+		var request struct{ ResetType string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "ForceOff", request.ResetType)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /redfish/v1/Systems/1", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		// the system takes a poll to power off
+		polls++
+		if polls > 1 {
+			powerState = "Off"
+		}
+		json.NewEncoder(w).Encode(map[string]string{"PowerState": powerState})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	agent := FenceAgent{Name: "bmc", Type: FenceAgentTypeRedfish, URL: server.URL + "/redfish/v1/Systems/1", Username: "admin", Password: "secret"}
+	agent.setDefaults()
+	require.NoError(t, agent.Fence(context.Background(), FenceOptions{}))
+	assert.Equal(t, 2, polls)
+
+	// a BMC refusing the reset fails the agent
+	agent.URL = server.URL + "/redfish/v1/Systems/2"
+	assert.ErrorContains(t, agent.Fence(context.Background(), FenceOptions{}), "redfish reset failed")
+
+	// dry runs don't reset the system
+	assert.NoError(t, agent.Fence(context.Background(), FenceOptions{DryRun: true}))
+}
+
+func TestFenceAgent_FenceCommand(t *testing.T) {
+	agent := FenceAgent{Name: "switch port", Type: FenceAgentTypeCommand, Command: "true"}
+	agent.setDefaults()
+	assert.NoError(t, agent.Fence(context.Background(), FenceOptions{}))
+
+	agent.Command = "false"
+	assert.Error(t, agent.Fence(context.Background(), FenceOptions{}))
+	assert.NoError(t, agent.Fence(context.Background(), FenceOptions{DryRun: true}))
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// FlapDetection represents the configuration for dampening failovers while this node keeps changing role - it
+// slows failovers down rather than freezing them like the circuit breaker
+type FlapDetection struct {
+	Enabled bool `koanf:"enabled"`
+	// MaxTransitions is how many changes between active and passive within WindowDuration count as flapping
+	MaxTransitions int `koanf:"max_transitions"`
+	// WindowDuration is the sliding window transitions are counted in
+	WindowDuration time.Duration `koanf:"window_duration"`
+	// DampeningFactor multiplies failover.leaderless_samples_threshold and failover.cooldown_duration while flapping
+	DampeningFactor int `koanf:"dampening_factor"`
+	// MinCooldownDuration is the cooldown held while flapping when failover.cooldown_duration is shorter
+	MinCooldownDuration time.Duration `koanf:"min_cooldown_duration"`
+}
+
+// SetDefaults sets default values for the flap detection configuration
+func (f *FlapDetection) SetDefaults() {
+	if f.MaxTransitions == 0 {
+		f.MaxTransitions = 4
+	}
+	if f.WindowDuration == 0 {
+		f.WindowDuration = time.Hour
+	}
+	if f.DampeningFactor == 0 {
+		f.DampeningFactor = 2
+	}
+	if f.MinCooldownDuration == 0 {
+		f.MinCooldownDuration = 5 * time.Minute
+	}
+}
+
+// Validate validates the flap detection configuration
+func (f *FlapDetection) Validate() error {
+	if !f.Enabled {
+		return nil
+	}
+
+	if f.MaxTransitions < 2 {
+		return fmt.Errorf("failover.flap_detection.max_transitions must be at least 2")
+	}
+
+	if f.WindowDuration <= 0 {
+		return fmt.Errorf("failover.flap_detection.window_duration must be greater than zero")
+	}
+
+	if f.DampeningFactor < 1 {
+		return fmt.Errorf("failover.flap_detection.dampening_factor must be at least 1")
+	}
+
+	if f.MinCooldownDuration < 0 {
+		return fmt.Errorf("failover.flap_detection.min_cooldown_duration must not be negative")
+	}
+
+	return nil
+}
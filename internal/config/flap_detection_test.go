@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlapDetection_SetDefaults(t *testing.T) {
+	flapDetection := &FlapDetection{}
+	flapDetection.SetDefaults()
+
+	assert.Equal(t, 4, flapDetection.MaxTransitions)
+	assert.Equal(t, time.Hour, flapDetection.WindowDuration)
+	assert.Equal(t, 2, flapDetection.DampeningFactor)
+	assert.Equal(t, 5*time.Minute, flapDetection.MinCooldownDuration)
+}
+
+func TestFlapDetection_Validate(t *testing.T) {
+	// disabled is always valid
+	assert.NoError(t, (&FlapDetection{MaxTransitions: -1}).Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*FlapDetection)
+		err    string
+	}{
+		{"valid", func(f *FlapDetection) {}, ""},
+		{"one transition", func(f *FlapDetection) { f.MaxTransitions = 1 }, "failover.flap_detection.max_transitions must be at least 2"},
+		{"negative window", func(f *FlapDetection) { f.WindowDuration = -time.Second }, "failover.flap_detection.window_duration must be greater than zero"},
+		{"negative factor", func(f *FlapDetection) { f.DampeningFactor = -1 }, "failover.flap_detection.dampening_factor must be at least 1"},
+		{"negative cooldown", func(f *FlapDetection) { f.MinCooldownDuration = -time.Second }, "failover.flap_detection.min_cooldown_duration must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flapDetection := &FlapDetection{Enabled: true}
+			flapDetection.SetDefaults()
+			tt.modify(flapDetection)
+			err := flapDetection.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
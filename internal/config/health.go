@@ -0,0 +1,72 @@
+package config
+
+import "fmt"
+
+// Health represents the configuration for declaring the local validator unhealthy or recovered
+type Health struct {
+	// UnhealthyThreshold is the number of consecutive failing polls before a check is declared failing
+	UnhealthyThreshold int `koanf:"unhealthy_threshold"`
+	// HealthyThreshold is the number of consecutive passing polls before a failing check is declared recovered
+	HealthyThreshold int `koanf:"healthy_threshold"`
+	// Checks override the thresholds per check
+	Checks HealthChecks `koanf:"checks"`
+}
+
+// HealthChecks are the per check threshold overrides
+type HealthChecks struct {
+	// RPC is the local rpc getHealth check
+	RPC HealthThresholds `koanf:"rpc"`
+	// Gossip is the check that this validator is visible in gossip
+	Gossip HealthThresholds `koanf:"gossip"`
+	// Peers is the check that each peer is visible in gossip, declaring it lost or discovered
+	Peers HealthThresholds `koanf:"peers"`
+}
+
+// HealthThresholds are the consecutive poll thresholds for a check - zero uses the validator.health threshold
+type HealthThresholds struct {
+	UnhealthyThreshold int `koanf:"unhealthy_threshold"`
+	HealthyThreshold   int `koanf:"healthy_threshold"`
+}
+
+// SetDefaults sets default values for the health configuration
+func (h *Health) SetDefaults() {
+	if h.UnhealthyThreshold == 0 {
+		h.UnhealthyThreshold = 1
+	}
+	if h.HealthyThreshold == 0 {
+		h.HealthyThreshold = 1
+	}
+
+	for _, check := range []*HealthThresholds{&h.Checks.RPC, &h.Checks.Gossip, &h.Checks.Peers} {
+		if check.UnhealthyThreshold == 0 {
+			check.UnhealthyThreshold = h.UnhealthyThreshold
+		}
+		if check.HealthyThreshold == 0 {
+			check.HealthyThreshold = h.HealthyThreshold
+		}
+	}
+}
+
+// Validate validates the health configuration
+func (h *Health) Validate() error {
+	thresholds := []struct {
+		key   string
+		value int
+	}{
+		{"unhealthy_threshold", h.UnhealthyThreshold},
+		{"healthy_threshold", h.HealthyThreshold},
+		{"checks.rpc.unhealthy_threshold", h.Checks.RPC.UnhealthyThreshold},
+		{"checks.rpc.healthy_threshold", h.Checks.RPC.HealthyThreshold},
+		{"checks.gossip.unhealthy_threshold", h.Checks.Gossip.UnhealthyThreshold},
+		{"checks.gossip.healthy_threshold", h.Checks.Gossip.HealthyThreshold},
+		{"checks.peers.unhealthy_threshold", h.Checks.Peers.UnhealthyThreshold},
+		{"checks.peers.healthy_threshold", h.Checks.Peers.HealthyThreshold},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value < 0 {
+			return fmt.Errorf("validator.health.%s must be positive", threshold.key)
+		}
+	}
+
+	return nil
+}
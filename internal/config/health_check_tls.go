@@ -0,0 +1,185 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// spiffeScheme is the URI scheme of SPIFFE IDs
+const spiffeScheme = "spiffe"
+
+// HealthCheckTLS represents the mutual TLS the health check server is served and reached with - it carries peer
+// traffic such as takeover announcements, status checks and raft, and the control API the commands use
+type HealthCheckTLS struct {
+	Enabled bool `koanf:"enabled"`
+	// CAFile is a PEM bundle of the CA certificates peer and client certificates must be signed by - system roots
+	// are not trusted
+	CAFile string `koanf:"ca_file"`
+	// CertFile and KeyFile are this node's PEM certificate and key, served to and presented to peers
+	CertFile string `koanf:"cert_file"`
+	KeyFile  string `koanf:"key_file"`
+	// TrustDomain verifies peers by the spiffe://<trust_domain>/... ID in their certificate's URI SANs instead of
+	// by the address they are reached at
+	TrustDomain string `koanf:"trust_domain"`
+	// AllowedIDs restricts peers to these SPIFFE IDs - any in TrustDomain when empty
+	AllowedIDs []string `koanf:"allowed_ids"`
+}
+
+// Validate validates the health check TLS configuration, loading the configured files
+func (h *HealthCheckTLS) Validate() error {
+	if !h.Enabled {
+		return nil
+	}
+
+	if h.CAFile == "" || h.CertFile == "" || h.KeyFile == "" {
+		return fmt.Errorf("prometheus.health_check_tls.ca_file, cert_file and key_file must be defined when enabled")
+	}
+
+	if strings.Contains(h.TrustDomain, "/") || strings.Contains(h.TrustDomain, ":") {
+		return fmt.Errorf("prometheus.health_check_tls.trust_domain must be a trust domain name, e.g. example.org - got: %s", h.TrustDomain)
+	}
+
+	for _, id := range h.AllowedIDs {
+		if h.TrustDomain == "" {
+			return fmt.Errorf("prometheus.health_check_tls.allowed_ids requires trust_domain")
+		}
+		if u, err := url.Parse(id); err != nil || u.Scheme != spiffeScheme || u.Host != h.TrustDomain || u.Path == "" {
+			return fmt.Errorf("prometheus.health_check_tls.allowed_ids %s must be a spiffe://%s/... ID", id, h.TrustDomain)
+		}
+	}
+
+	if _, err := h.ServerConfig(); err != nil {
+		return fmt.Errorf("prometheus.health_check_tls: %w", err)
+	}
+
+	return nil
+}
+
+// ServerConfig returns the TLS server config - nil when disabled. Client certificates are verified when given but
+// not required, so endpoints such as Slack callbacks can be served without one - see RequireClientCertificate.
+func (h *HealthCheckTLS) ServerConfig() (*tls.Config, error) {
+	if !h.Enabled {
+		return nil, nil
+	}
+
+	cert, pool, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    pool,
+	}
+	if h.TrustDomain != "" {
+		tlsConfig.VerifyPeerCertificate = func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+			if len(verifiedChains) == 0 {
+				return nil
+			}
+			return h.verifySPIFFEID(verifiedChains[0][0])
+		}
+	}
+	return tlsConfig, nil
+}
+
+// ClientConfig returns the TLS client config peers and the local health check server are reached with - nil when
+// disabled
+func (h *HealthCheckTLS) ClientConfig() (*tls.Config, error) {
+	if !h.Enabled {
+		return nil, nil
+	}
+
+	cert, pool, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+	}
+	if h.TrustDomain != "" {
+		// the SPIFFE ID identifies the peer instead of the address it is reached at, so the chain is verified here
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("peer presented no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, intermediate := range state.PeerCertificates[1:] {
+				intermediates.AddCert(intermediate)
+			}
+			leaf := state.PeerCertificates[0]
+			if _, err := leaf.Verify(x509.VerifyOptions{Roots: pool, Intermediates: intermediates}); err != nil {
+				return err
+			}
+			return h.verifySPIFFEID(leaf)
+		}
+	}
+	return tlsConfig, nil
+}
+
+// load loads this node's certificate and the CA pool
+func (h *HealthCheckTLS) load() (tls.Certificate, *x509.CertPool, error) {
+	cert, err := tls.LoadX509KeyPair(h.CertFile, h.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to load cert_file and key_file: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(h.CAFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to read ca_file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return tls.Certificate{}, nil, fmt.Errorf("ca_file %s contains no PEM certificates", h.CAFile)
+	}
+	return cert, pool, nil
+}
+
+// verifySPIFFEID returns an error unless cert carries a SPIFFE ID in TrustDomain, and in AllowedIDs when set
+func (h *HealthCheckTLS) verifySPIFFEID(cert *x509.Certificate) error {
+	for _, uri := range cert.URIs {
+		if uri.Scheme != spiffeScheme {
+			continue
+		}
+		if uri.Host != h.TrustDomain {
+			return fmt.Errorf("SPIFFE ID %s is not in trust domain %s", uri, h.TrustDomain)
+		}
+		if len(h.AllowedIDs) > 0 && !slices.Contains(h.AllowedIDs, uri.String()) {
+			return fmt.Errorf("SPIFFE ID %s is not allowed", uri)
+		}
+		return nil
+	}
+	return fmt.Errorf("certificate has no SPIFFE ID")
+}
+
+// RequireClientCertificate returns true unless the request presented a verified client certificate when health
+// check TLS is enabled
+func (h *HealthCheckTLS) RequireClientCertificate(r *http.Request) bool {
+	return h.Enabled && (r.TLS == nil || len(r.TLS.PeerCertificates) == 0)
+}
+
+// HealthCheckClient returns a client for the health check servers of this node and its peers, presenting this
+// node's certificate when prometheus.health_check_tls is enabled
+func (p *Prometheus) HealthCheckClient(timeout time.Duration) (*http.Client, error) {
+	tlsConfig, err := p.HealthCheckTLS.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("prometheus.health_check_tls: %w", err)
+	}
+	client := &http.Client{Timeout: timeout}
+	if tlsConfig != nil {
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+	return client, nil
+}
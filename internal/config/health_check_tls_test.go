@@ -0,0 +1,160 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestPeerCertificate writes a self-signed PEM certificate and key for 127.0.0.1 with SPIFFE ID id, usable
+// for both ends of mutual TLS, returning their paths
+func writeTestPeerCertificate(t *testing.T, dir string, id string) (certFile string, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	spiffeID, err := url.Parse(id)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "solana-validator-ha test peer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		URIs:                  []*url.URL{spiffeID},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "peer.pem")
+	keyFile = filepath.Join(dir, "peer-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestHealthCheckTLS_Validate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+	enabled := HealthCheckTLS{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile}
+
+	tests := []struct {
+		name        string
+		modify      func(h *HealthCheckTLS)
+		errContains string
+	}{
+		{name: "valid", modify: func(h *HealthCheckTLS) {}},
+		{name: "disabled without files", modify: func(h *HealthCheckTLS) { *h = HealthCheckTLS{} }},
+		{name: "missing ca file", modify: func(h *HealthCheckTLS) { h.CAFile = "" }, errContains: "ca_file, cert_file and key_file must be defined"},
+		{name: "unreadable ca file", modify: func(h *HealthCheckTLS) { h.CAFile = filepath.Join(dir, "missing.pem") }, errContains: "failed to read ca_file"},
+		{name: "mismatched key", modify: func(h *HealthCheckTLS) { h.KeyFile = certFile }, errContains: "failed to load cert_file and key_file"},
+		{name: "trust domain", modify: func(h *HealthCheckTLS) {
+			h.TrustDomain = "example.org"
+			h.AllowedIDs = []string{"spiffe://example.org/validator/a"}
+		}},
+		{name: "trust domain with scheme", modify: func(h *HealthCheckTLS) { h.TrustDomain = "spiffe://example.org" }, errContains: "trust_domain must be a trust domain name"},
+		{name: "allowed ids without trust domain", modify: func(h *HealthCheckTLS) {
+			h.AllowedIDs = []string{"spiffe://example.org/validator/a"}
+		}, errContains: "allowed_ids requires trust_domain"},
+		{name: "allowed id in another trust domain", modify: func(h *HealthCheckTLS) {
+			h.TrustDomain = "example.org"
+			h.AllowedIDs = []string{"spiffe://other.org/validator/a"}
+		}, errContains: "must be a spiffe://example.org/... ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := enabled
+			tt.modify(&h)
+			err := h.Validate()
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestPrometheus_HealthCheckClient(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestPeerCertificate(t, dir, "spiffe://example.org/validator/a")
+	h := HealthCheckTLS{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile}
+
+	serverConfig, err := h.ServerConfig()
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.RequireClientCertificate(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	server.TLS = serverConfig
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(h HealthCheckTLS) (int, error) {
+		p := Prometheus{HealthCheckTLS: h}
+		client, err := p.HealthCheckClient(5 * time.Second)
+		require.NoError(t, err)
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// verified by address
+	status, err := get(h)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	// verified by SPIFFE ID
+	spiffe := h
+	spiffe.TrustDomain = "example.org"
+	status, err = get(spiffe)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	spiffe.AllowedIDs = []string{"spiffe://example.org/validator/b"}
+	_, err = get(spiffe)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not allowed")
+
+	spiffe.TrustDomain, spiffe.AllowedIDs = "other.org", nil
+	_, err = get(spiffe)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not in trust domain other.org")
+
+	// without a client certificate
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	assert.Equal(t, "https://127.0.0.1:9091/status", (&Prometheus{HealthCheckPort: 9091, HealthCheckTLS: h}).HealthCheckURL("/status"))
+	assert.Equal(t, "http://192.0.2.1:9091/status", (&Prometheus{HealthCheckPort: 9091}).PeerHealthCheckURL("192.0.2.1", "/status"))
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_SetDefaults(t *testing.T) {
+	health := &Health{
+		UnhealthyThreshold: 3,
+		Checks: HealthChecks{
+			Gossip: HealthThresholds{HealthyThreshold: 5},
+		},
+	}
+	health.SetDefaults()
+
+	assert.Equal(t, 3, health.UnhealthyThreshold)
+	assert.Equal(t, 1, health.HealthyThreshold)
+	// checks inherit the global thresholds unless overridden
+	assert.Equal(t, HealthThresholds{UnhealthyThreshold: 3, HealthyThreshold: 1}, health.Checks.RPC)
+	assert.Equal(t, HealthThresholds{UnhealthyThreshold: 3, HealthyThreshold: 5}, health.Checks.Gossip)
+	assert.Equal(t, HealthThresholds{UnhealthyThreshold: 3, HealthyThreshold: 1}, health.Checks.Peers)
+}
+
+func TestHealth_Validate(t *testing.T) {
+	health := &Health{}
+	health.SetDefaults()
+	assert.NoError(t, health.Validate())
+
+	health.Checks.RPC.HealthyThreshold = -1
+	err := health.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "validator.health.checks.rpc.healthy_threshold must be positive")
+
+	health.Checks.RPC.HealthyThreshold = 1
+	health.Checks.Peers.UnhealthyThreshold = -1
+	err = health.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "validator.health.checks.peers.unhealthy_threshold must be positive")
+}
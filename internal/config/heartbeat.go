@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationHeartbeat sends a periodic all clear summary so silence can be told apart from a broken alerting path
+type NotificationHeartbeat struct {
+	Enabled bool `koanf:"enabled"`
+	// IntervalDuration is the time between heartbeats
+	IntervalDuration time.Duration `koanf:"interval_duration"`
+	// At is an optional HH:MM time heartbeats are aligned to - e.g. 09:00 with a 24h interval sends one every morning
+	At string `koanf:"at"`
+	// Timezone is an IANA timezone name At is evaluated in
+	Timezone string `koanf:"timezone"`
+}
+
+// SetDefaults sets default values for the heartbeat configuration
+func (h *NotificationHeartbeat) SetDefaults() {
+	if h.IntervalDuration == 0 {
+		h.IntervalDuration = 24 * time.Hour
+	}
+	if h.Timezone == "" {
+		h.Timezone = "UTC"
+	}
+}
+
+// Validate validates the heartbeat configuration
+func (h *NotificationHeartbeat) Validate() error {
+	if !h.Enabled {
+		return nil
+	}
+
+	if h.IntervalDuration <= 0 {
+		return fmt.Errorf("notifications.heartbeat.interval_duration must be greater than zero")
+	}
+
+	if h.At != "" {
+		if _, err := time.Parse(windowTimeLayout, h.At); err != nil {
+			return fmt.Errorf("notifications.heartbeat.at must be a HH:MM time, got %q", h.At)
+		}
+	}
+
+	if _, err := time.LoadLocation(h.Timezone); err != nil {
+		return fmt.Errorf("notifications.heartbeat.timezone: %w", err)
+	}
+
+	return nil
+}
+
+// Next returns the time of the next heartbeat after now - an interval from now, or the next time falling a whole
+// number of intervals from At today when set
+func (h *NotificationHeartbeat) Next(now time.Time) time.Time {
+	at, err := time.Parse(windowTimeLayout, h.At)
+	location, locationErr := time.LoadLocation(h.Timezone)
+	if h.At == "" || err != nil || locationErr != nil || h.IntervalDuration <= 0 {
+		return now.Add(h.IntervalDuration)
+	}
+
+	local := now.In(location)
+	anchor := time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, location)
+
+	// step back to the last heartbeat at or before now, then forward one interval
+	intervals := now.Sub(anchor) / h.IntervalDuration
+	next := anchor.Add(intervals * h.IntervalDuration)
+	for !next.After(now) {
+		next = next.Add(h.IntervalDuration)
+	}
+	for next.Add(-h.IntervalDuration).After(now) {
+		next = next.Add(-h.IntervalDuration)
+	}
+	return next
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationHeartbeat_SetDefaults(t *testing.T) {
+	heartbeat := &NotificationHeartbeat{}
+	heartbeat.SetDefaults()
+
+	assert.Equal(t, 24*time.Hour, heartbeat.IntervalDuration)
+	assert.Equal(t, "UTC", heartbeat.Timezone)
+}
+
+func TestNotificationHeartbeat_Validate(t *testing.T) {
+	// disabled is always valid
+	heartbeat := &NotificationHeartbeat{At: "not a time"}
+	assert.NoError(t, heartbeat.Validate())
+
+	heartbeat = &NotificationHeartbeat{Enabled: true, At: "09:00"}
+	heartbeat.SetDefaults()
+	assert.NoError(t, heartbeat.Validate())
+
+	// Test with invalid at
+	heartbeat.At = "9am"
+	err := heartbeat.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.heartbeat.at must be a HH:MM time")
+
+	// Test with invalid timezone
+	heartbeat.At = "09:00"
+	heartbeat.Timezone = "Mars/Olympus_Mons"
+	err = heartbeat.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.heartbeat.timezone")
+}
+
+func TestNotificationHeartbeat_Next(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	// an interval from now without at
+	heartbeat := &NotificationHeartbeat{IntervalDuration: 6 * time.Hour, Timezone: "UTC"}
+	assert.Equal(t, now.Add(6*time.Hour), heartbeat.Next(now))
+
+	// daily at 09:00 - already passed today so tomorrow
+	heartbeat = &NotificationHeartbeat{IntervalDuration: 24 * time.Hour, At: "09:00", Timezone: "UTC"}
+	assert.Equal(t, time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), heartbeat.Next(now))
+
+	// daily at 18:00 - later today
+	heartbeat.At = "18:00"
+	assert.Equal(t, time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC), heartbeat.Next(now))
+
+	// every 6h aligned to 09:00 - 09:00, 15:00, 21:00, 03:00
+	heartbeat = &NotificationHeartbeat{IntervalDuration: 6 * time.Hour, At: "09:00", Timezone: "UTC"}
+	assert.Equal(t, time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC), heartbeat.Next(now))
+	assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), heartbeat.Next(time.Date(2025, 1, 1, 4, 0, 0, 0, time.UTC)))
+
+	// at is evaluated in the timezone
+	heartbeat = &NotificationHeartbeat{IntervalDuration: 24 * time.Hour, At: "09:00", Timezone: "America/New_York"}
+	assert.Equal(t, time.Date(2025, 1, 1, 14, 0, 0, 0, time.UTC), heartbeat.Next(now).UTC())
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/iancoleman/strcase"
@@ -21,6 +24,33 @@ type Hook struct {
 	Command     string   `koanf:"command"`
 	Args        []string `koanf:"args"`
 	MustSucceed bool     `koanf:"must_succeed"`
+	// Host runs the hook on a remote host over SSH using failover.ssh - a failover.peers name or an allowed host
+	Host string `koanf:"host"`
+	// Resources the hook touches - commands sharing a resource never run at the same time
+	Resources []string `koanf:"resources"`
+	// WorkingDir is the directory the hook runs in, e.g. the ledger directory
+	WorkingDir string `koanf:"working_dir"`
+	// Umask is the octal file mode creation mask the hook runs with, e.g. "0027"
+	Umask string `koanf:"umask"`
+	// Shell runs the command and args as a single line through /bin/sh -c, for pipes and redirection
+	Shell bool `koanf:"shell"`
+	// ClearEnv runs the hook with only PassEnv instead of the manager's environment - local hooks only
+	ClearEnv bool `koanf:"clear_env"`
+	// PassEnv are the manager's environment variables still passed with ClearEnv, e.g. PATH
+	PassEnv []string `koanf:"pass_env"`
+	// LockFile is a file locked while the hook runs so overlapping invocations, from this or other processes, never run at once
+	LockFile string `koanf:"lock_file"`
+	// LockMode is what to do when LockFile is held - wait (default) or skip the hook
+	LockMode string `koanf:"lock_mode"`
+	// TimeoutDuration is the maximum time each run of the hook may take - no timeout when zero, or
+	// failover.ssh.timeout_duration for hooks with a host
+	TimeoutDuration time.Duration `koanf:"timeout_duration"`
+	// Retries is how many more times a failed hook is run before it is reported as failed
+	Retries int `koanf:"retries"`
+	// RetryDelayDuration is how long to wait before each retry
+	RetryDelayDuration time.Duration `koanf:"retry_delay_duration"`
+	// When is an expression the hook only runs if true, e.g. "not dry_run and previous_role == 'active'" - see evalWhen
+	When string `koanf:"when"`
 }
 
 // HookRunOptions represents options for running a hook
@@ -29,6 +59,11 @@ type HookRunOptions struct {
 	DryRun       bool
 	LoggerPrefix string
 	LoggerArgs   []any
+	// SSH and Peers are used to run hooks that declare a remote host
+	SSH   *SSH
+	Peers Peers
+	// AllowedCommands, if set, are the only commands hooks may run - see failover.command_allowlist
+	AllowedCommands []string
 }
 
 // HooksRunOptions represents options for running hooks
@@ -36,6 +71,28 @@ type HooksRunOptions struct {
 	DryRun       bool
 	LoggerPrefix string
 	LoggerArgs   []any
+	// SSH and Peers are used to run hooks that declare a remote host
+	SSH   *SSH
+	Peers Peers
+	// AllowedCommands, if set, are the only commands hooks may run - see failover.command_allowlist
+	AllowedCommands []string
+	// Conditions are what hook when expressions are evaluated against
+	Conditions HookConditions
+	// OnResult, if set, is called with the result of each hook run
+	OnResult func(result HookResult)
+}
+
+// HookResult is the result of running a hook
+type HookResult struct {
+	Type        string // "pre" or "post"
+	Name        string
+	Host        string
+	MustSucceed bool
+	// Skipped is true if the hook was not run as its when expression was false
+	Skipped   bool
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error
 }
 
 // Validate validates the hooks configuration
@@ -73,10 +130,117 @@ func (h *Hook) Validate(allowMustSucceed bool) error {
 		return fmt.Errorf("hook must_succeed not allowed for post hooks")
 	}
 
-	return nil
+	if h.Umask != "" {
+		if err := command.ValidateUmask(h.Umask); err != nil {
+			return err
+		}
+	}
+
+	if err := command.ValidateLockMode(h.LockMode); err != nil {
+		return err
+	}
+
+	if h.TimeoutDuration < 0 || h.Retries < 0 || h.RetryDelayDuration < 0 {
+		return fmt.Errorf("timeout_duration, retries and retry_delay_duration must not be negative")
+	}
+
+	if h.When != "" {
+		if err := validateWhen(h.When); err != nil {
+			return err
+		}
+	}
+
+	return validateResources(h.Resources)
 }
 
-func (h *Hook) Run(opts HookRunOptions) error {
+// ShouldRun returns false if the hook's when expression is false for conditions - hooks without one always run
+func (h *Hook) ShouldRun(conditions HookConditions, dryRun bool) (bool, error) {
+	if h.When == "" {
+		return true, nil
+	}
+	run, err := evalWhen(h.When, conditions.whenVariables(dryRun))
+	if err != nil {
+		return false, fmt.Errorf("hook %s when: %w", h.Name, err)
+	}
+	return run, nil
+}
+
+// whenPlan returns the hook's when expression as noted in failover plans, empty if it has none
+func (h *Hook) whenPlan() string {
+	if h.When == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (when %s)", h.When)
+}
+
+// Run runs the hook, locally or on its host, until it exits or ctx is done - a failed hook is run again up to
+// Retries times, RetryDelayDuration apart. Hooks refused by the command allowlist or skipped as their lock file
+// is held are not retried.
+func (h *Hook) Run(ctx context.Context, opts HookRunOptions) error {
+	err := h.run(ctx, opts)
+	for retry := 1; retry <= h.Retries && retryableHookError(err); retry++ {
+		log.Warn("hook failed - retrying", append([]any{
+			"hook_name", h.Name,
+			"retry", retry,
+			"retries", h.Retries,
+			"retry_delay", h.RetryDelayDuration,
+			"error", err,
+		}, opts.LoggerArgs...)...)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("hook %s retry cancelled: %w", h.Name, context.Cause(ctx))
+		case <-time.After(h.RetryDelayDuration):
+		}
+
+		err = h.run(ctx, opts)
+	}
+	return err
+}
+
+// retryableHookError returns true if err is a hook failure worth running the hook again for
+func retryableHookError(err error) bool {
+	return err != nil && !errors.Is(err, command.ErrCommandNotAllowed) && !errors.Is(err, command.ErrLocked)
+}
+
+// run runs the hook once, locally or on its host
+func (h *Hook) run(ctx context.Context, opts HookRunOptions) error {
+	runOptions := h.runOptions(opts)
+
+	// run on the remote host if declared
+	if h.Host != "" {
+		if opts.SSH == nil {
+			return fmt.Errorf("hook %s declares host %s but no ssh configuration was given", h.Name, h.Host)
+		}
+		return command.RunRemote(ctx, command.RunRemoteOptions{
+			RunOptions: runOptions,
+			Remote:     h.remoteOptions(opts),
+		})
+	}
+
+	return command.Run(ctx, runOptions)
+}
+
+// Plan returns the copy-pasteable command line the hook runs, over ssh for hooks with a host
+func (h *Hook) Plan(opts HookRunOptions) string {
+	runOptions := h.runOptions(opts)
+	if h.Host != "" && opts.SSH != nil {
+		return command.RunRemoteOptions{RunOptions: runOptions, Remote: h.remoteOptions(opts)}.Plan()
+	}
+	return runOptions.Plan()
+}
+
+// remoteOptions returns the ssh options the hook is run on its host with - its own timeout overrides failover.ssh's
+func (h *Hook) remoteOptions(opts HookRunOptions) command.RemoteOptions {
+	remote := opts.SSH.RemoteOptions(h.Host, opts.Peers)
+	if h.TimeoutDuration > 0 {
+		remote.Timeout = h.TimeoutDuration
+	}
+	return remote
+}
+
+// runOptions returns the options the hook's command is run with
+func (h *Hook) runOptions(opts HookRunOptions) command.RunOptions {
 	loggerArgs := []any{
 		"hook_name", strcase.ToSnake(h.Name),
 		"command", h.Command,
@@ -85,19 +249,29 @@ func (h *Hook) Run(opts HookRunOptions) error {
 	}
 	loggerArgs = append(loggerArgs, opts.LoggerArgs...)
 
-	return command.Run(command.RunOptions{
-		Name:         fmt.Sprintf("%s-hook %s", opts.HookType, h.Name),
-		Command:      h.Command,
-		Args:         h.Args,
-		DryRun:       opts.DryRun,
-		LoggerPrefix: opts.LoggerPrefix,
-		LoggerArgs:   loggerArgs,
-		StreamOutput: true,
-	})
+	return command.RunOptions{
+		Name:            fmt.Sprintf("%s-hook %s", opts.HookType, h.Name),
+		Command:         h.Command,
+		Args:            h.Args,
+		DryRun:          opts.DryRun,
+		LoggerPrefix:    opts.LoggerPrefix,
+		LoggerArgs:      loggerArgs,
+		StreamOutput:    true,
+		Resources:       h.Resources,
+		Timeout:         h.TimeoutDuration,
+		WorkingDir:      h.WorkingDir,
+		Umask:           h.Umask,
+		Shell:           h.Shell,
+		ClearEnv:        h.ClearEnv,
+		PassEnv:         h.PassEnv,
+		LockFile:        h.LockFile,
+		LockMode:        h.LockMode,
+		AllowedCommands: opts.AllowedCommands,
+	}
 }
 
-// RunPre runs the pre hooks
-func (h *Hooks) RunPre(opts HooksRunOptions) error {
+// RunPre runs the pre hooks, stopping at the first must_succeed hook to fail or when ctx is done
+func (h *Hooks) RunPre(ctx context.Context, opts HooksRunOptions) error {
 	loggerArgs := []any{
 		"hook_type", constants.HookTypePre,
 	}
@@ -105,12 +279,28 @@ func (h *Hooks) RunPre(opts HooksRunOptions) error {
 
 	// run pre hooks
 	for _, hook := range h.Pre {
-		err := hook.Run(HookRunOptions{
-			HookType:     constants.HookTypePre,
-			DryRun:       opts.DryRun,
-			LoggerPrefix: opts.LoggerPrefix,
-			LoggerArgs:   loggerArgs,
-		})
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("pre hooks cancelled before %s: %w", hook.Name, context.Cause(ctx))
+		}
+		startedAt := time.Now()
+		run, err := hook.ShouldRun(opts.Conditions, opts.DryRun)
+		if err == nil && !run {
+			log.Info("hook skipped - when is false", append(loggerArgs, "hook_name", hook.Name, "when", hook.When)...)
+			opts.report(constants.HookTypePre, hook, startedAt, nil, true)
+			continue
+		}
+		if err == nil {
+			err = hook.Run(ctx, HookRunOptions{
+				HookType:        constants.HookTypePre,
+				DryRun:          opts.DryRun,
+				LoggerPrefix:    opts.LoggerPrefix,
+				LoggerArgs:      loggerArgs,
+				SSH:             opts.SSH,
+				Peers:           opts.Peers,
+				AllowedCommands: opts.AllowedCommands,
+			})
+		}
+		opts.report(constants.HookTypePre, hook, startedAt, err, false)
 		if err != nil && hook.MustSucceed {
 			return err
 		}
@@ -122,8 +312,8 @@ func (h *Hooks) RunPre(opts HooksRunOptions) error {
 	return nil
 }
 
-// RunPost runs the post hooks
-func (h *Hooks) RunPost(opts HooksRunOptions) {
+// RunPost runs the post hooks, skipping those left when ctx is done
+func (h *Hooks) RunPost(ctx context.Context, opts HooksRunOptions) {
 	loggerArgs := []any{
 		"hook_type", constants.HookTypePost,
 	}
@@ -131,14 +321,48 @@ func (h *Hooks) RunPost(opts HooksRunOptions) {
 
 	// run post hooks - failures are logged but not returned
 	for _, hook := range h.Post {
-		err := hook.Run(HookRunOptions{
-			HookType:     constants.HookTypePost,
-			DryRun:       opts.DryRun,
-			LoggerPrefix: opts.LoggerPrefix,
-			LoggerArgs:   loggerArgs,
-		})
+		if ctx.Err() != nil {
+			log.Warn("post hooks cancelled", append(loggerArgs, "skipped_hook", hook.Name, "cause", context.Cause(ctx))...)
+			return
+		}
+		startedAt := time.Now()
+		run, err := hook.ShouldRun(opts.Conditions, opts.DryRun)
+		if err == nil && !run {
+			log.Info("hook skipped - when is false", append(loggerArgs, "hook_name", hook.Name, "when", hook.When)...)
+			opts.report(constants.HookTypePost, hook, startedAt, nil, true)
+			continue
+		}
+		if err == nil {
+			err = hook.Run(ctx, HookRunOptions{
+				HookType:        constants.HookTypePost,
+				DryRun:          opts.DryRun,
+				LoggerPrefix:    opts.LoggerPrefix,
+				LoggerArgs:      loggerArgs,
+				SSH:             opts.SSH,
+				Peers:           opts.Peers,
+				AllowedCommands: opts.AllowedCommands,
+			})
+		}
+		opts.report(constants.HookTypePost, hook, startedAt, err, false)
 		if err != nil {
 			log.Error("hook failed", loggerArgs...)
 		}
 	}
 }
+
+// report passes a hook's result to OnResult if set
+func (opts HooksRunOptions) report(hookType string, hook Hook, startedAt time.Time, err error, skipped bool) {
+	if opts.OnResult == nil {
+		return
+	}
+	opts.OnResult(HookResult{
+		Type:        hookType,
+		Name:        hook.Name,
+		Host:        hook.Host,
+		MustSucceed: hook.MustSucceed,
+		Skipped:     skipped,
+		StartedAt:   startedAt,
+		Duration:    time.Since(startedAt),
+		Err:         err,
+	})
+}
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHooks_Validate(t *testing.T) {
@@ -75,6 +83,43 @@ func TestHook_Validate(t *testing.T) {
 	// Test with must_succeed on pre hook (allowed)
 	err = hook.Validate(true) // allow must_succeed for pre hooks
 	assert.NoError(t, err)
+
+	// Test with valid and invalid umask
+	hook.Umask = "0027"
+	assert.NoError(t, hook.Validate(true))
+	hook.Umask = "0999"
+	err = hook.Validate(true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "umask must be an octal mode")
+
+	// Test with valid and invalid lock mode
+	hook.Umask = ""
+	hook.LockMode = "skip"
+	assert.NoError(t, hook.Validate(true))
+	hook.LockMode = "queue"
+	err = hook.Validate(true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "lock_mode must be one of wait or skip")
+
+	// Test with valid and negative timeout and retries
+	hook.LockMode = ""
+	hook.TimeoutDuration = time.Minute
+	hook.Retries = 2
+	hook.RetryDelayDuration = time.Second
+	assert.NoError(t, hook.Validate(true))
+	hook.Retries = -1
+	err = hook.Validate(true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout_duration, retries and retry_delay_duration must not be negative")
+
+	// Test with valid and invalid when expressions
+	hook.Retries = 0
+	hook.When = "not dry_run and cluster == 'mainnet-beta'"
+	assert.NoError(t, hook.Validate(true))
+	hook.When = "cluster == mainnet"
+	err = hook.Validate(true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown variable mainnet")
 }
 
 func TestHook_Run(t *testing.T) {
@@ -85,14 +130,66 @@ func TestHook_Run(t *testing.T) {
 	}
 
 	// Test dry run
-	err := hook.Run(HookRunOptions{DryRun: true})
+	err := hook.Run(context.Background(), HookRunOptions{DryRun: true})
 	assert.NoError(t, err)
 
 	// Test actual run (this will actually execute the command)
-	err = hook.Run(HookRunOptions{DryRun: false})
+	err = hook.Run(context.Background(), HookRunOptions{DryRun: false})
+	assert.NoError(t, err)
+
+	// Test shell run - pipes only work through the shell
+	hook = &Hook{Name: "shell-hook", Command: "echo hello", Args: []string{"|", "grep -q hello"}, Shell: true}
+	err = hook.Run(context.Background(), HookRunOptions{DryRun: false})
 	assert.NoError(t, err)
 }
 
+func TestHook_Run_Retries(t *testing.T) {
+	// fails until it has run three times
+	counter := filepath.Join(t.TempDir(), "runs")
+	hook := &Hook{
+		Name:               "flaky-hook",
+		Command:            fmt.Sprintf("echo run >> %s && test $(wc -l < %s) -ge 3", counter, counter),
+		Shell:              true,
+		RetryDelayDuration: time.Millisecond,
+	}
+
+	runs := func() int {
+		data, err := os.ReadFile(counter)
+		require.NoError(t, err)
+		return strings.Count(string(data), "run")
+	}
+
+	// not retried by default
+	assert.Error(t, hook.Run(context.Background(), HookRunOptions{}))
+	assert.Equal(t, 1, runs())
+
+	// retried until it succeeds
+	hook.Retries = 5
+	assert.NoError(t, hook.Run(context.Background(), HookRunOptions{}))
+	assert.Equal(t, 3, runs())
+
+	// never retried when refused by the allowlist
+	hook = &Hook{Name: "refused-hook", Command: "echo", Retries: 3}
+	err := hook.Run(context.Background(), HookRunOptions{AllowedCommands: []string{"/bin/true"}})
+	assert.ErrorIs(t, err, command.ErrCommandNotAllowed)
+
+	// retries stop when ctx is done
+	hook = &Hook{Name: "failing-hook", Command: "false", Shell: true, Retries: 3, RetryDelayDuration: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = hook.Run(ctx, HookRunOptions{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHook_Run_Timeout(t *testing.T) {
+	hook := &Hook{Name: "slow-hook", Command: "sleep", Args: []string{"10"}, TimeoutDuration: 100 * time.Millisecond}
+
+	startedAt := time.Now()
+	err := hook.Run(context.Background(), HookRunOptions{})
+	assert.ErrorIs(t, err, command.ErrTimeout)
+	assert.Less(t, time.Since(startedAt), 5*time.Second)
+}
+
 func TestHooks_RunPre(t *testing.T) {
 	hooks := &Hooks{
 		Pre: []Hook{
@@ -102,14 +199,57 @@ func TestHooks_RunPre(t *testing.T) {
 	}
 
 	// Test dry run
-	err := hooks.RunPre(HooksRunOptions{DryRun: true})
+	err := hooks.RunPre(context.Background(), HooksRunOptions{DryRun: true})
 	assert.NoError(t, err)
 
 	// Test actual run
-	err = hooks.RunPre(HooksRunOptions{DryRun: false})
+	err = hooks.RunPre(context.Background(), HooksRunOptions{DryRun: false})
 	assert.NoError(t, err)
 }
 
+func TestHooks_RunPre_When(t *testing.T) {
+	hooks := &Hooks{
+		Pre: []Hook{
+			{Name: "mainnet-only", Command: "echo", Args: []string{"mainnet"}, When: "cluster == 'mainnet-beta'"},
+			{Name: "was-active", Command: "false", Shell: true, MustSucceed: true, When: "previous_role == 'active'"},
+			{Name: "always", Command: "echo", Args: []string{"always"}},
+		},
+	}
+
+	results := map[string]HookResult{}
+	err := hooks.RunPre(context.Background(), HooksRunOptions{
+		Conditions: HookConditions{Cluster: "testnet", Role: "active", PreviousRole: "passive"},
+		OnResult:   func(result HookResult) { results[result.Name] = result },
+	})
+	require.NoError(t, err)
+	assert.True(t, results["mainnet-only"].Skipped)
+	assert.True(t, results["was-active"].Skipped)
+	assert.False(t, results["always"].Skipped)
+	assert.NoError(t, results["always"].Err)
+
+	// the failing must_succeed hook runs when its condition holds
+	err = hooks.RunPre(context.Background(), HooksRunOptions{
+		Conditions: HookConditions{Cluster: "testnet", Role: "active", PreviousRole: "active"},
+	})
+	assert.Error(t, err)
+}
+
+func TestHooks_RunPre_Cancelled(t *testing.T) {
+	hooks := &Hooks{
+		Pre: []Hook{
+			{Name: "pre-hook-1", Command: "echo", Args: []string{"pre1"}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran := false
+	err := hooks.RunPre(ctx, HooksRunOptions{OnResult: func(HookResult) { ran = true }})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, ran, "expected no hooks to run once cancelled")
+}
+
 func TestHooks_RunPost(t *testing.T) {
 	hooks := &Hooks{
 		Post: []Hook{
@@ -119,8 +259,8 @@ func TestHooks_RunPost(t *testing.T) {
 	}
 
 	// Test dry run
-	hooks.RunPost(HooksRunOptions{DryRun: true})
+	hooks.RunPost(context.Background(), HooksRunOptions{DryRun: true})
 
 	// Test actual run
-	hooks.RunPost(HooksRunOptions{DryRun: false})
+	hooks.RunPost(context.Background(), HooksRunOptions{DryRun: false})
 }
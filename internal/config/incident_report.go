@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// incidentReportFormats are the valid failover.incident_report.format values
+var incidentReportFormats = []string{"markdown", "html"}
+
+// IncidentReport represents the configuration for the report written after each takeover attempt
+type IncidentReport struct {
+	Enabled bool `koanf:"enabled"`
+	// Dir is the directory reports are written to
+	Dir string `koanf:"dir"`
+	// Format is markdown or html
+	Format string `koanf:"format"`
+	// URL is the base URL Dir is served from - the became_active notification links to the report there, or gives
+	// its path when empty
+	URL string `koanf:"url"`
+}
+
+// SetDefaults sets default values for the incident report configuration
+func (r *IncidentReport) SetDefaults() {
+	if r.Format == "" {
+		r.Format = "markdown"
+	}
+}
+
+// Validate validates the incident report configuration
+func (r *IncidentReport) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.Dir == "" {
+		return fmt.Errorf("failover.incident_report.dir must be defined when enabled")
+	}
+
+	if !slices.Contains(incidentReportFormats, r.Format) {
+		return fmt.Errorf("failover.incident_report.format must be one of %v", incidentReportFormats)
+	}
+
+	if r.URL != "" {
+		if u, err := url.Parse(r.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("failover.incident_report.url must be a valid URL")
+		}
+	}
+
+	return nil
+}
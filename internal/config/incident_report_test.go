@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncidentReport_SetDefaults(t *testing.T) {
+	incidentReport := &IncidentReport{}
+	incidentReport.SetDefaults()
+
+	assert.Equal(t, "markdown", incidentReport.Format)
+}
+
+func TestIncidentReport_Validate(t *testing.T) {
+	// disabled is always valid
+	incidentReport := &IncidentReport{}
+	assert.NoError(t, incidentReport.Validate())
+
+	// enabled requires a dir
+	incidentReport.Enabled = true
+	incidentReport.SetDefaults()
+	err := incidentReport.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.incident_report.dir must be defined when enabled")
+
+	incidentReport.Dir = "/var/lib/solana-validator-ha/incidents"
+	assert.NoError(t, incidentReport.Validate())
+
+	// Test with unknown format
+	incidentReport.Format = "pdf"
+	err = incidentReport.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.incident_report.format must be one of")
+
+	// Test with invalid url
+	incidentReport.Format = "html"
+	incidentReport.URL = "reports/incidents"
+	err = incidentReport.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.incident_report.url must be a valid URL")
+
+	incidentReport.URL = "https://reports.example.com/incidents"
+	assert.NoError(t, incidentReport.Validate())
+}
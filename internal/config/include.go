@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/providers/rawbytes"
+)
+
+// includeKey is the top-level key listing the files a config is merged over
+const includeKey = "include"
+
+// Dump returns the config at source merged with its includes as YAML - before environment variables are
+// interpolated and defaults set, so secrets referenced as ${VAR} are not revealed
+func Dump(source string) ([]byte, error) {
+	if !isRemoteSource(source) {
+		var err error
+		if source, err = resolveConfigPath(source); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, _, err := loadMerged(source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config file: %w", err)
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(confmap.Provider(raw, ""), nil); err != nil {
+		return nil, err
+	}
+	return k.Marshal(yaml.Parser())
+}
+
+// loadMerged returns the config at source merged over the configs it includes, in order - so source takes
+// precedence over its includes, and later includes over earlier ones. Maps are merged key by key and anything
+// else, lists included, is replaced. including is the chain of configs that included source. The version of a
+// remote key source is returned with it, zero for a file.
+func loadMerged(source string, including []string) (map[string]any, int64, error) {
+	provider, version, err := sourceProvider(source)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	own := koanf.New(".")
+	if err := own.Load(provider, yaml.Parser()); err != nil {
+		return nil, 0, err
+	}
+
+	includes, err := includePaths(own.Get(includeKey))
+	if err != nil {
+		return nil, 0, err
+	}
+	own.Delete(includeKey)
+
+	merged := koanf.New(".")
+	for _, include := range includes {
+		includeSource, err := resolveInclude(source, include)
+		if err != nil {
+			return nil, 0, fmt.Errorf("include %s: %w", include, err)
+		}
+		if includeSource == source || slices.Contains(including, includeSource) {
+			return nil, 0, fmt.Errorf("include %s: includes itself via %s", include, strings.Join(append(including, source), " -> "))
+		}
+
+		raw, _, err := loadMerged(includeSource, append(slices.Clone(including), source))
+		if err != nil {
+			return nil, 0, fmt.Errorf("include %s: %w", include, err)
+		}
+		if err := merged.Load(confmap.Provider(raw, ""), nil); err != nil {
+			return nil, 0, fmt.Errorf("include %s: %w", include, err)
+		}
+	}
+
+	if err := merged.Merge(own); err != nil {
+		return nil, 0, err
+	}
+	return merged.Raw(), version, nil
+}
+
+// sourceProvider returns a provider reading the config file or remote key at source, with the version of a
+// remote key
+func sourceProvider(source string) (koanf.Provider, int64, error) {
+	if !isRemoteSource(source) {
+		return file.Provider(source), 0, nil
+	}
+
+	value, version, err := getRemote(source)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rawbytes.Provider(value), version, nil
+}
+
+// includePaths returns the include list - a missing list is empty
+func includePaths(value any) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of config file paths", includeKey)
+	}
+	paths := make([]string, 0, len(list))
+	for _, item := range list {
+		path, ok := item.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("%s must be a list of config file paths, got %v", includeKey, item)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// resolveInclude returns the source of an include - relative paths are relative to the directory of the file
+// including them, or the working directory when included by a remote key
+func resolveInclude(including string, include string) (string, error) {
+	if isRemoteSource(include) {
+		return include, nil
+	}
+	if !isRemoteSource(including) && !filepath.IsAbs(include) && !strings.HasPrefix(include, "~") {
+		include = filepath.Join(filepath.Dir(including), include)
+	}
+	return resolveConfigPath(include)
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFiles writes each named config file to dir
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+func TestLoadFromFile_Includes(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"shared/base.yaml": `
+log:
+  level: info
+  format: json
+failover:
+  poll_interval_duration: 5s
+  peers:
+    validator-1:
+      ip: 192.168.1.10
+`,
+		"shared/alerts.yaml": `
+log:
+  level: warn
+`,
+		"host.yaml": `
+include:
+  - shared/base.yaml
+  - shared/alerts.yaml
+log:
+  format: text
+failover:
+  peers:
+    validator-2:
+      ip: 192.168.1.11
+`,
+	})
+
+	cfg := &Config{}
+	require.NoError(t, cfg.LoadFromFile(filepath.Join(dir, "host.yaml")))
+
+	// later includes override earlier ones, and the including file overrides its includes
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, "text", cfg.Log.Format)
+	assert.Equal(t, "5s", cfg.Failover.PollIntervalDuration.String())
+
+	// maps are merged key by key
+	require.Len(t, cfg.Failover.Peers, 2)
+	assert.Equal(t, "192.168.1.10", cfg.Failover.Peers["validator-1"].IP)
+	assert.Equal(t, "192.168.1.11", cfg.Failover.Peers["validator-2"].IP)
+}
+
+func TestLoadFromFile_NestedIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"a/base.yaml":  "log:\n  level: debug\n  format: json\n",
+		"a/fleet.yaml": "include: [base.yaml]\nlog:\n  format: logfmt\n",
+		"host.yaml":    "include: [a/fleet.yaml]\n",
+	})
+
+	cfg := &Config{}
+	require.NoError(t, cfg.LoadFromFile(filepath.Join(dir, "host.yaml")))
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, "logfmt", cfg.Log.Format)
+}
+
+func TestLoadFromFile_IncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"self.yaml":    "include: [self.yaml]\n",
+		"a.yaml":       "include: [b.yaml]\n",
+		"b.yaml":       "include: [a.yaml]\n",
+		"missing.yaml": "include: [nope.yaml]\n",
+		"scalar.yaml":  "include: base.yaml\n",
+	})
+
+	tests := map[string]string{
+		"self.yaml":    "includes itself",
+		"a.yaml":       "includes itself via " + filepath.Join(dir, "a.yaml") + " -> " + filepath.Join(dir, "b.yaml"),
+		"missing.yaml": "include nope.yaml",
+		"scalar.yaml":  "include must be a list of config file paths",
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := (&Config{}).LoadFromFile(filepath.Join(dir, name))
+			assert.ErrorContains(t, err, expected)
+		})
+	}
+}
+
+func TestDump(t *testing.T) {
+	t.Setenv("SOLANA_VALIDATOR_HA_TEST_DUMP_TOKEN", "secret")
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"base.yaml": "log:\n  level: info\nnotifications:\n  telegram:\n    bot_token: ${SOLANA_VALIDATOR_HA_TEST_DUMP_TOKEN}\n",
+		"host.yaml": "include: [base.yaml]\nlog:\n  level: debug\n",
+	})
+
+	dumped, err := Dump(filepath.Join(dir, "host.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "log:\n    level: debug\nnotifications:\n    telegram:\n        bot_token: ${SOLANA_VALIDATOR_HA_TEST_DUMP_TOKEN}\n", string(dumped))
+	assert.NotContains(t, string(dumped), "include")
+
+	_, err = Dump(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "error loading config file")
+}
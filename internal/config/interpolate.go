@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"regexp"
+)
+
+// envVarPattern matches ${VAR} environment variable references, and $${VAR} escapes of them
+var envVarPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolateEnv returns value with each ${VAR} replaced by the environment variable's value - $${VAR} is kept
+// as a literal ${VAR}, e.g. for shell hooks. Variables referenced but not set are added to missing.
+func interpolateEnv(value string, missing map[string]bool) string {
+	return envVarPattern.ReplaceAllStringFunc(value, func(reference string) string {
+		if reference[1] == '$' {
+			return reference[1:]
+		}
+
+		name := envVarPattern.FindStringSubmatch(reference)[1]
+		envValue, ok := os.LookupEnv(name)
+		if !ok {
+			missing[name] = true
+		}
+		return envValue
+	})
+}
+
+// interpolateEnvValues interpolates environment variables in every string value of a parsed config, in place -
+// map keys are left as they are
+func interpolateEnvValues(value any, missing map[string]bool) any {
+	switch v := value.(type) {
+	case string:
+		return interpolateEnv(v, missing)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = interpolateEnvValues(item, missing)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = interpolateEnvValues(item, missing)
+		}
+	}
+	return value
+}
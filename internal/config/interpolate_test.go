@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("SVHA_TEST_HOST", "validator-1")
+	t.Setenv("SVHA_TEST_EMPTY", "")
+
+	missing := map[string]bool{}
+	assert.Equal(t, "/home/sol/validator-1/ledger", interpolateEnv("/home/sol/${SVHA_TEST_HOST}/ledger", missing))
+	assert.Equal(t, "", interpolateEnv("${SVHA_TEST_EMPTY}", missing))
+	assert.Equal(t, "echo ${SVHA_TEST_HOST}", interpolateEnv("echo $${SVHA_TEST_HOST}", missing))
+	assert.Equal(t, "$HOME and $1", interpolateEnv("$HOME and $1", missing))
+	assert.Empty(t, missing)
+
+	assert.Equal(t, "-", interpolateEnv("${SVHA_TEST_UNSET}-", missing))
+	assert.Equal(t, map[string]bool{"SVHA_TEST_UNSET": true}, missing)
+}
+
+func TestInterpolateEnvValues(t *testing.T) {
+	t.Setenv("SVHA_TEST_CHANNEL", "#alerts")
+
+	missing := map[string]bool{}
+	value := interpolateEnvValues(map[string]any{
+		"${SVHA_TEST_CHANNEL}": "key",
+		"channel":              "${SVHA_TEST_CHANNEL}",
+		"args":                 []any{"--channel", "${SVHA_TEST_CHANNEL}", 3},
+		"nested":               map[string]any{"port": 22},
+	}, missing)
+
+	assert.Equal(t, map[string]any{
+		"${SVHA_TEST_CHANNEL}": "key",
+		"channel":              "#alerts",
+		"args":                 []any{"--channel", "#alerts", 3},
+		"nested":               map[string]any{"port": 22},
+	}, value)
+	assert.Empty(t, missing)
+}
+
+func TestLoadFromFile_InterpolatesEnv(t *testing.T) {
+	t.Setenv("SVHA_TEST_NAME", "validator-1")
+	t.Setenv("SVHA_TEST_PORT", "9191")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+validator:
+  name: "${SVHA_TEST_NAME}"
+prometheus:
+  port: ${SVHA_TEST_PORT}
+failover:
+  active:
+    command: "set-identity"
+    args: ["--name", "${SVHA_TEST_NAME}", "$${NOT_INTERPOLATED}"]
+`), 0o600))
+
+	cfg, err := New(NewConfigParams{})
+	require.NoError(t, err)
+	require.NoError(t, cfg.LoadFromFile(configFile))
+
+	assert.Equal(t, "validator-1", cfg.Validator.Name)
+	assert.Equal(t, 9191, cfg.Prometheus.Port)
+	assert.Equal(t, []string{"--name", "validator-1", "${NOT_INTERPOLATED}"}, cfg.Failover.Active.Args)
+}
+
+func TestLoadFromFile_UnsetEnv(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+validator:
+  name: "${SVHA_TEST_UNSET_B}"
+  rpc_url: "http://${SVHA_TEST_UNSET_A}:8899"
+`), 0o600))
+
+	cfg, err := New(NewConfigParams{})
+	require.NoError(t, err)
+	err = cfg.LoadFromFile(configFile)
+	assert.EqualError(t, err, "error loading config file: environment variables referenced but not set: SVHA_TEST_UNSET_A, SVHA_TEST_UNSET_B")
+}
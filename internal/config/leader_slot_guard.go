@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LeaderSlotGuard represents the configuration for holding back controlled identity switches - promote, demote,
+// failover and scheduled failovers - while our next leader slot is close, so the switch doesn't skip our blocks
+type LeaderSlotGuard struct {
+	// Enabled waits for a gap in our leader slots before a controlled identity switch
+	Enabled bool `koanf:"enabled"`
+	// Slots is how many slots away our next leader slot must be for the identity switch to run
+	Slots uint64 `koanf:"slots"`
+	// MaxWaitDuration is the longest an identity switch waits for Slots before running regardless
+	MaxWaitDuration time.Duration `koanf:"max_wait_duration"`
+}
+
+// SetDefaults sets default values for the leader slot guard configuration
+func (l *LeaderSlotGuard) SetDefaults() {
+	if l.Slots == 0 {
+		l.Slots = 20
+	}
+	if l.MaxWaitDuration == 0 {
+		l.MaxWaitDuration = 30 * time.Second
+	}
+}
+
+// Validate validates the leader slot guard configuration
+func (l *LeaderSlotGuard) Validate() error {
+	if !l.Enabled {
+		return nil
+	}
+
+	if l.MaxWaitDuration <= 0 {
+		return fmt.Errorf("failover.leader_slot_guard.max_wait_duration must be greater than zero")
+	}
+
+	return nil
+}
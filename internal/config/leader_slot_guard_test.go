@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderSlotGuard_SetDefaults(t *testing.T) {
+	guard := &LeaderSlotGuard{}
+	guard.SetDefaults()
+
+	assert.Equal(t, uint64(20), guard.Slots)
+	assert.Equal(t, 30*time.Second, guard.MaxWaitDuration)
+}
+
+func TestLeaderSlotGuard_Validate(t *testing.T) {
+	// disabled is always valid
+	guard := &LeaderSlotGuard{MaxWaitDuration: -time.Second}
+	assert.NoError(t, guard.Validate())
+
+	// enabled with defaults is valid
+	guard = &LeaderSlotGuard{Enabled: true}
+	guard.SetDefaults()
+	assert.NoError(t, guard.Validate())
+
+	// negative max wait
+	guard.MaxWaitDuration = -time.Second
+	err := guard.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.leader_slot_guard.max_wait_duration must be greater than zero")
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
@@ -22,6 +24,12 @@ type Log struct {
 	Level string `koanf:"level"`
 	// Format is the log format - one of "text" or "json" or "logfmt", defaults to txt
 	Format string `koanf:"format"`
+	// ExitReportFile is appended with a JSON line summarising each run of the manager when it stops - not written when empty
+	ExitReportFile string `koanf:"exit_report_file"`
+	// File is a rotated file logs are written to as well as stderr
+	File LogFile `koanf:"file"`
+	// Syslog is a local or remote syslog logs are sent to as well as stderr
+	Syslog LogSyslog `koanf:"syslog"`
 	// ParsedLevel is the parsed log level
 	ParsedLevel log.Level `koanf:"-"`
 	// ParsedFormat is the parsed log format
@@ -36,6 +44,8 @@ func (l *Log) SetDefaults() {
 	if l.Format == "" {
 		l.Format = "text"
 	}
+	l.File.SetDefaults()
+	l.Syslog.SetDefaults()
 }
 
 // Validate validates the log configuration
@@ -53,7 +63,11 @@ func (l *Log) Validate() (err error) {
 		return fmt.Errorf("log.format must be one of text, json, logfmt - got: %s", l.Format)
 	}
 
-	return nil
+	if err := l.File.Validate(); err != nil {
+		return err
+	}
+
+	return l.Syslog.Validate()
 }
 
 // SetLevelString sets the log level from a string
@@ -93,6 +107,28 @@ func (l *Log) ConfigureWithLevelString(logLevel string) {
 	// set formatter
 	log.SetFormatter(l.ParsedFormatter)
 
+	// write to log.file and log.syslog as well as stderr if set - carrying on without those that can't be opened
+	outputs := []io.Writer{os.Stderr}
+	if l.File.Path != "" {
+		file, err := newRotatingFile(l.File)
+		if err != nil {
+			log.Error("failed to open log.file - not logging to it", "path", l.File.Path, "error", err)
+		} else {
+			outputs = append(outputs, file)
+		}
+	}
+	if l.Syslog.Enabled {
+		syslogWriter, err := newSyslogWriter(l.Syslog, l.Format)
+		if err != nil {
+			log.Error("failed to connect to log.syslog - not logging to it", "address", l.Syslog.Address, "error", err)
+		} else {
+			outputs = append(outputs, syslogWriter)
+		}
+	}
+	if len(outputs) > 1 {
+		log.SetOutput(io.MultiWriter(outputs...))
+	}
+
 	// extend styles
 	styles := log.DefaultStyles()
 	styles.Timestamp = lipgloss.NewStyle().Faint(true)
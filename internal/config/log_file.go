@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logFileBackupTimeFormat is the time format rotated log files are suffixed with - it sorts oldest first
+const logFileBackupTimeFormat = "20060102T150405.000Z"
+
+// LogFile represents the configuration for writing logs to a rotated file as well as stderr
+type LogFile struct {
+	// Path is the file logs are written to - logs are only written to stderr when empty
+	Path string `koanf:"path"`
+	// MaxSizeMB is the size in megabytes the file is rotated at
+	MaxSizeMB int `koanf:"max_size_mb"`
+	// MaxAgeDuration is how long the file is written to before it is rotated - zero rotates on size only
+	MaxAgeDuration time.Duration `koanf:"max_age_duration"`
+	// MaxBackups is how many rotated files are kept, oldest removed first
+	MaxBackups int `koanf:"max_backups"`
+}
+
+// SetDefaults sets default values for the log file configuration
+func (f *LogFile) SetDefaults() {
+	if f.MaxSizeMB == 0 {
+		f.MaxSizeMB = 100
+	}
+	if f.MaxBackups == 0 {
+		f.MaxBackups = 7
+	}
+}
+
+// Validate validates the log file configuration
+func (f *LogFile) Validate() error {
+	if f.Path == "" {
+		return nil
+	}
+
+	if f.MaxSizeMB < 0 {
+		return fmt.Errorf("log.file.max_size_mb must not be negative")
+	}
+	if f.MaxAgeDuration < 0 {
+		return fmt.Errorf("log.file.max_age_duration must not be negative")
+	}
+	if f.MaxBackups < 0 {
+		return fmt.Errorf("log.file.max_backups must not be negative")
+	}
+
+	return nil
+}
+
+// rotatingFile is a log file rotated when it grows past maxSize bytes or has been written to for maxAge. Rotated
+// files are renamed with the time of their rotation, keeping the newest maxBackups.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	file       *os.File
+	size       int64
+	openedAt   time.Time
+	now        func() time.Time
+}
+
+// newRotatingFile opens the log file for appending, creating it and its directory if needed
+func newRotatingFile(cfg LogFile) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:       cfg.Path,
+		maxSize:    int64(cfg.MaxSizeMB) << 20,
+		maxAge:     cfg.MaxAgeDuration,
+		maxBackups: cfg.MaxBackups,
+		now:        time.Now,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes p to the file, rotating it first if p would take it past its size or it is too old. The logger
+// writes each entry in one call, so entries are never split across files.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tooBig := f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize
+	tooOld := f.maxAge > 0 && f.now().Sub(f.openedAt) >= f.maxAge
+	if tooBig || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// open opens the file for appending
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log file directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	return nil
+}
+
+// rotate renames the file with the current time, opens a new one and removes the oldest backups over maxBackups.
+// The file is renamed while still open, so it keeps being written to if rotating it fails.
+func (f *rotatingFile) rotate() error {
+	ext := filepath.Ext(f.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), f.now().UTC().Format(logFileBackupTimeFormat), ext)
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	previous := f.file
+	if err := f.open(); err != nil {
+		return err
+	}
+	previous.Close()
+
+	f.removeOldBackups()
+	return nil
+}
+
+// removeOldBackups removes the oldest rotated files beyond maxBackups - failures are left for the next rotation
+func (f *rotatingFile) removeOldBackups() {
+	ext := filepath.Ext(f.path)
+	backups, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-[0-9]*" + ext)
+	if err != nil || len(backups) <= f.maxBackups {
+		return
+	}
+
+	slices.Sort(backups)
+	for _, backup := range backups[:len(backups)-f.maxBackups] {
+		os.Remove(backup)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFile_SetDefaults(t *testing.T) {
+	logFile := &LogFile{}
+	logFile.SetDefaults()
+
+	assert.Equal(t, 100, logFile.MaxSizeMB)
+	assert.Equal(t, 7, logFile.MaxBackups)
+	assert.Zero(t, logFile.MaxAgeDuration)
+}
+
+func TestLogFile_Validate(t *testing.T) {
+	// no path is always valid
+	logFile := &LogFile{MaxSizeMB: -1}
+	assert.NoError(t, logFile.Validate())
+
+	logFile.Path = "/var/log/solana-validator-ha/ha.log"
+	err := logFile.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "log.file.max_size_mb must not be negative")
+
+	logFile.MaxSizeMB = 10
+	logFile.MaxAgeDuration = -time.Hour
+	err = logFile.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "log.file.max_age_duration must not be negative")
+
+	logFile.MaxAgeDuration = 24 * time.Hour
+	logFile.MaxBackups = -1
+	err = logFile.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "log.file.max_backups must not be negative")
+
+	logFile.MaxBackups = 3
+	assert.NoError(t, logFile.Validate())
+}
+
+func TestRotatingFile_RotatesOnSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "ha.log")
+	file, err := newRotatingFile(LogFile{Path: path, MaxBackups: 2})
+	require.NoError(t, err)
+	defer file.Close()
+	file.maxSize = 10
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	file.now = func() time.Time { return now }
+
+	// entries are never split across files
+	for _, entry := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		now = now.Add(time.Second)
+		_, err := file.Write([]byte(entry))
+		require.NoError(t, err)
+	}
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth\n", string(content))
+
+	// only the newest max_backups rotated files are kept
+	backups, err := filepath.Glob(filepath.Join(filepath.Dir(path), "ha-*.log"))
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "ha-20261016T120003.000Z.log"), backups[0])
+	content, err = os.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(content))
+}
+
+func TestRotatingFile_RotatesOnAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ha.log")
+	require.NoError(t, os.WriteFile(path, []byte("previous run\n"), 0o644))
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	file, err := newRotatingFile(LogFile{Path: path, MaxSizeMB: 100, MaxAgeDuration: time.Hour, MaxBackups: 7})
+	require.NoError(t, err)
+	defer file.Close()
+	file.now = func() time.Time { return now }
+	file.openedAt = now
+
+	// existing files are appended to until they are max_age_duration old
+	_, err = file.Write([]byte("started\n"))
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous run\nstarted\n", string(content))
+
+	now = now.Add(time.Hour)
+	_, err = file.Write([]byte("an hour later\n"))
+	require.NoError(t, err)
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "an hour later\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(filepath.Dir(path), "ha-20261016T130000.000Z.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "previous run\nstarted\n", string(content))
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log/syslog"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// syslogFacilities are the valid log.syslog.facility values
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":   syslog.LOG_KERN,
+	"user":   syslog.LOG_USER,
+	"daemon": syslog.LOG_DAEMON,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// syslogNetworks are the schemes a remote log.syslog.address may have
+var syslogNetworks = []string{"udp", "tcp"}
+
+// LogSyslog represents the configuration for sending logs to a syslog endpoint as well as stderr
+type LogSyslog struct {
+	Enabled bool `koanf:"enabled"`
+	// Address is the remote syslog endpoint as udp://<host:port> or tcp://<host:port> - the local syslog when empty
+	Address string `koanf:"address"`
+	// Tag is the syslog tag logs are sent with
+	Tag string `koanf:"tag"`
+	// Facility is the syslog facility logs are sent with
+	Facility string `koanf:"facility"`
+}
+
+// SetDefaults sets default values for the syslog configuration
+func (s *LogSyslog) SetDefaults() {
+	if s.Tag == "" {
+		s.Tag = "solana-validator-ha"
+	}
+	if s.Facility == "" {
+		s.Facility = "daemon"
+	}
+}
+
+// Validate validates the syslog configuration
+func (s *LogSyslog) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+
+	if _, ok := syslogFacilities[s.Facility]; !ok {
+		return fmt.Errorf("log.syslog.facility must be one of kern, user, daemon or local0-local7 - got: %s", s.Facility)
+	}
+
+	if s.Address != "" {
+		if _, _, err := s.network(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// network returns the network and host:port of the remote syslog address
+func (s *LogSyslog) network() (network, address string, err error) {
+	parsed, err := url.Parse(s.Address)
+	if err != nil || !slices.Contains(syslogNetworks, parsed.Scheme) || parsed.Host == "" {
+		return "", "", fmt.Errorf("log.syslog.address must be udp://<host:port> or tcp://<host:port> - got: %s", s.Address)
+	}
+	return parsed.Scheme, parsed.Host, nil
+}
+
+// syslogWriter sends each log entry to syslog with the severity of its level
+type syslogWriter struct {
+	writer *syslog.Writer
+	// levels are how each level appears in entries of the configured log.format
+	levels []syslogLevel
+}
+
+// syslogLevel is how a level appears in log entries, and the syslog severity it is sent with
+type syslogLevel struct {
+	marker []byte
+	send   func(*syslog.Writer, string) error
+}
+
+// newSyslogWriter connects to syslog, sending entries formatted as format
+func newSyslogWriter(cfg LogSyslog, format string) (*syslogWriter, error) {
+	var network, address string
+	if cfg.Address != "" {
+		var err error
+		if network, address, err = cfg.network(); err != nil {
+			return nil, err
+		}
+	}
+
+	writer, err := syslog.Dial(network, address, syslogFacilities[cfg.Facility]|syslog.LOG_INFO, cfg.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	return &syslogWriter{writer: writer, levels: syslogLevels(format)}, nil
+}
+
+// syslogLevels returns how levels appear in entries formatted as format
+func syslogLevels(format string) []syslogLevel {
+	severities := []struct {
+		text, name string
+		send       func(*syslog.Writer, string) error
+	}{
+		{"FATA", "fatal", (*syslog.Writer).Crit},
+		{"ERRO", "error", (*syslog.Writer).Err},
+		{"WARN", "warn", (*syslog.Writer).Warning},
+		{"INFO", "info", (*syslog.Writer).Info},
+		{"DEBU", "debug", (*syslog.Writer).Debug},
+	}
+
+	levels := []syslogLevel{}
+	for _, severity := range severities {
+		marker := severity.text + " "
+		switch format {
+		case "json":
+			marker = `"level":"` + severity.name + `"`
+		case "logfmt":
+			marker = "level=" + severity.name + " "
+		}
+		levels = append(levels, syslogLevel{marker: []byte(marker), send: severity.send})
+	}
+	return levels
+}
+
+// Write sends p as one syslog message - the logger writes each entry in one call. Entries whose level isn't found
+// are sent as info.
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSuffix(string(p), "\n")
+
+	// the level is written before the message, so the first one at the start of the entry is its level
+	head := p[:min(len(p), 64)]
+	send, first := (*syslog.Writer).Info, len(head)
+	for _, level := range w.levels {
+		if i := bytes.Index(head, level.marker); i >= 0 && i < first {
+			send, first = level.send, i
+		}
+	}
+
+	if err := send(w.writer, message); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
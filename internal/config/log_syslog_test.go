@@ -0,0 +1,85 @@
+package config
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogSyslog_SetDefaults(t *testing.T) {
+	logSyslog := &LogSyslog{}
+	logSyslog.SetDefaults()
+
+	assert.Equal(t, "solana-validator-ha", logSyslog.Tag)
+	assert.Equal(t, "daemon", logSyslog.Facility)
+}
+
+func TestLogSyslog_Validate(t *testing.T) {
+	// disabled is always valid
+	logSyslog := &LogSyslog{Facility: "nope"}
+	assert.NoError(t, logSyslog.Validate())
+
+	logSyslog.Enabled = true
+	err := logSyslog.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "log.syslog.facility must be one of")
+
+	// the local syslog when no address is given
+	logSyslog.Facility = "local3"
+	assert.NoError(t, logSyslog.Validate())
+
+	for _, address := range []string{"syslog.example.com:514", "http://syslog.example.com:514", "udp://"} {
+		logSyslog.Address = address
+		err = logSyslog.Validate()
+		assert.Error(t, err, address)
+		assert.Contains(t, err.Error(), "log.syslog.address must be udp://<host:port> or tcp://<host:port>")
+	}
+
+	logSyslog.Address = "tcp://syslog.example.com:514"
+	assert.NoError(t, logSyslog.Validate())
+}
+
+func TestSyslogWriter_Write(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	receive := func() string {
+		buf := make([]byte, 1024)
+		require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := listener.ReadFrom(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	tests := []struct {
+		format   string
+		entry    string
+		priority string
+	}{
+		{"text", "2026-10-16T12:00:00.000Z ERRO [validator-1 ha_manager]: failed to become active\n", "<27>"},
+		{"text", "2026-10-16T12:00:00.000Z WARN [validator-1 ha_manager]: ERRO in message\n", "<28>"},
+		{"json", `{"time":"2026-10-16T12:00:00.000Z","level":"debug","msg":"checking peers"}` + "\n", "<31>"},
+		{"logfmt", "time=2026-10-16T12:00:00.000Z level=fatal msg=\"failed to load configuration\"\n", "<26>"},
+		// entries without a level are sent as info
+		{"text", "no level here\n", "<30>"},
+	}
+
+	for _, test := range tests {
+		writer, err := newSyslogWriter(LogSyslog{Enabled: true, Address: "udp://" + listener.LocalAddr().String(), Tag: "ha", Facility: "daemon"}, test.format)
+		require.NoError(t, err)
+
+		n, err := writer.Write([]byte(test.entry))
+		require.NoError(t, err)
+		assert.Equal(t, len(test.entry), n)
+
+		message := receive()
+		assert.Regexp(t, `^`+test.priority, message, test.entry)
+		assert.Contains(t, message, " ha[")
+		assert.Contains(t, message, test.entry)
+		writer.writer.Close()
+	}
+}
@@ -1,18 +1,92 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
 )
 
+// notificationSeverities are the valid notification severity names
+var notificationSeverities = []string{"critical", "error", "warning", "info"}
+
+// notificationTemplateKeys are valid notifications.templates keys in addition to event names
+var notificationTemplateKeys = []string{"default", "digest"}
+
+// mentionHandlePatterns are the valid notifications.mentions handle formats by service
+var mentionHandlePatterns = map[string]*regexp.Regexp{
+	"discord":  regexp.MustCompile(`^[0-9]+$`),
+	"slack":    regexp.MustCompile(`^[UW][A-Z0-9]+$`),
+	"telegram": regexp.MustCompile(`^@?[A-Za-z0-9_]{5,32}$`),
+}
+
+// pagerDutyEventActions are valid notifications.pagerduty.event_actions values
+var pagerDutyEventActions = []string{"trigger", "resolve"}
+
+// slackActions are the valid notifications.slack.interactive.actions values
+var slackActions = []string{"acknowledge", "silence", "failover"}
+
+// quietHoursModes are the valid notifications.quiet_hours.mode values
+var quietHoursModes = []string{"suppress", "downgrade"}
+
+// notificationServices are the notification service names, as used by notifications.routing
+var notificationServices = []string{"discord", "telegram", "slack", "pagerduty", "tickets", "plugins"}
+
+// ticketProviders are the valid notifications.tickets.provider values
+var ticketProviders = []string{"jira", "servicenow"}
+
 // NotificationConfig represents the notifications configuration
 type NotificationConfig struct {
-	Enabled   bool                   `koanf:"enabled"`
-	Discord   DiscordConfig          `koanf:"discord"`
-	Telegram  TelegramConfig         `koanf:"telegram"`
-	Slack     SlackConfig            `koanf:"slack"`
-	PagerDuty PagerDutyConfig        `koanf:"pagerduty"`
-	Events    NotificationEvents     `koanf:"events"`
+	Enabled   bool                `koanf:"enabled"`
+	Discord   DiscordConfig       `koanf:"discord"`
+	Telegram  TelegramConfig      `koanf:"telegram"`
+	Slack     SlackConfig         `koanf:"slack"`
+	PagerDuty PagerDutyConfig     `koanf:"pagerduty"`
+	Tickets   TicketsConfig       `koanf:"tickets"`
+	Plugins   NotificationPlugins `koanf:"plugins"`
+	Events    NotificationEvents  `koanf:"events"`
+	Debug     NotificationDebug   `koanf:"debug"`
+	Digest    NotificationDigest  `koanf:"digest"`
+	// Heartbeat sends a periodic all clear summary of role, health and peers
+	Heartbeat NotificationHeartbeat `koanf:"heartbeat"`
+	// QuietHours suppresses or downgrades non-critical events during scheduled windows and maintenance mode
+	QuietHours NotificationQuietHours `koanf:"quiet_hours"`
+	// Templates maps event types, or "default" for all others, to custom title/description Go templates
+	Templates map[string]NotificationTemplate `koanf:"templates"`
+	// Links are added to Discord and Slack notifications, e.g. dashboards, runbooks and explorers
+	Links []NotificationLink `koanf:"links"`
+	// Mentions maps peer names, including this validator's name, to the operators mentioned in events involving them
+	Mentions map[string]NotificationMentions `koanf:"mentions"`
+	// SeverityOverrides maps event types to the severity they are sent with, overriding the defaults
+	SeverityOverrides map[string]string `koanf:"severity_overrides"`
+	// Routing maps severities to the services events of that severity are sent to - severities without
+	// a route are sent to every enabled service
+	Routing map[string][]string `koanf:"routing"`
+	// DedupWindowDuration suppresses identical events within this window - zero disables deduplication
+	DedupWindowDuration time.Duration `koanf:"dedup_window_duration"`
+	// SpoolDir is a directory where events that failed delivery are persisted and replayed from, after each successful
+	// delivery and every spool_replay_interval_duration
+	SpoolDir string `koanf:"spool_dir"`
+	// SpoolMaxAgeDuration is how long a spooled event is kept before being discarded
+	SpoolMaxAgeDuration time.Duration `koanf:"spool_max_age_duration"`
+	// SpoolMaxSizeBytes is the maximum total size of the spool directory - oldest events are discarded first
+	SpoolMaxSizeBytes int64 `koanf:"spool_max_size_bytes"`
+	// SpoolReplayIntervalDuration is how often spooled events are replayed, whether or not anything else is sent
+	SpoolReplayIntervalDuration time.Duration `koanf:"spool_replay_interval_duration"`
+	// HistorySize is the number of most recent events kept in memory and served on /events - kept even when
+	// notifications are disabled
+	HistorySize int `koanf:"history_size"`
+	// Workers is the number of events sent concurrently
+	Workers int `koanf:"workers"`
+	// QueueSize is the number of events waiting for a worker before new events are dropped
+	QueueSize int `koanf:"queue_size"`
+	// TimeoutDuration is the maximum time each service has to send an event
+	TimeoutDuration time.Duration `koanf:"timeout_duration"`
 }
 
 // NotificationEvents controls which events trigger notifications
@@ -30,6 +104,121 @@ type NotificationEvents struct {
 	GossipRecovered bool `koanf:"gossip_recovered"`
 	PeerDiscovered  bool `koanf:"peer_discovered"`
 	PeerLost        bool `koanf:"peer_lost"`
+	// RPCEndpointDemoted is sent when a cluster rpc url is demoted to last resort after consecutive failures
+	RPCEndpointDemoted bool `koanf:"rpc_endpoint_demoted"`
+	// SnapshotRecovery* are sent as a lagging passive node runs failover.snapshot_recovery.command
+	SnapshotRecoveryStarted   bool `koanf:"snapshot_recovery_started"`
+	SnapshotRecoveryCompleted bool `koanf:"snapshot_recovery_completed"`
+	SnapshotRecoveryFailed    bool `koanf:"snapshot_recovery_failed"`
+	// QuietPeriodEnded summarises what was held back once quiet hours or maintenance mode end
+	QuietPeriodEnded bool `koanf:"quiet_period_ended"`
+	// VoteAccountChanged is sent when validator.vote_account_watch sees an unexpected commission or authority change
+	VoteAccountChanged bool `koanf:"vote_account_changed"`
+	// ActiveIdentityOnPassive is sent when validator.identity_watchdog finds this passive node using the active identity
+	ActiveIdentityOnPassive bool `koanf:"active_identity_on_passive"`
+	// CircuitBreaker* are sent when failover.circuit_breaker freezes automatic takeovers and when it is reset
+	CircuitBreakerTripped bool `koanf:"circuit_breaker_tripped"`
+	CircuitBreakerReset   bool `koanf:"circuit_breaker_reset"`
+	// Heartbeat is the periodic all clear summary sent when notifications.heartbeat is enabled
+	Heartbeat bool `koanf:"heartbeat"`
+	// Acknowledged is sent when an operator acknowledges a failover with the acknowledgements API or ack command
+	Acknowledged bool `koanf:"acknowledged"`
+	// ManualRoleChange is sent when an operator promotes, demotes or fails over a node with the promote, demote or
+	// failover command
+	ManualRoleChange bool `koanf:"manual_role_change"`
+	// ScheduledFailover is sent when failover.schedules hands the active role over, or skips doing so
+	ScheduledFailover bool `koanf:"scheduled_failover"`
+	// VoteCreditsLagging is sent when validator.vote_credits finds the vote account earning too few credits
+	VoteCreditsLagging bool `koanf:"vote_credits_lagging"`
+	// SkipRateHigh is sent when validator.skip_rate finds the active identity skipping too many leader slots this epoch
+	SkipRateHigh bool `koanf:"skip_rate_high"`
+	// Failback is sent at each step of failover.failback handing the active role back to the primary
+	Failback bool `koanf:"failback"`
+	// FlappingDetected is sent when failover.flap_detection starts dampening failovers
+	FlappingDetected bool `koanf:"flapping_detected"`
+}
+
+// Names returns the event names as used in config keys
+func (e NotificationEvents) Names() []string {
+	eventsType := reflect.TypeOf(e)
+	names := make([]string, 0, eventsType.NumField())
+	for i := 0; i < eventsType.NumField(); i++ {
+		names = append(names, eventsType.Field(i).Tag.Get("koanf"))
+	}
+	return names
+}
+
+// NotificationDebug controls logging of notifier HTTP exchanges for troubleshooting
+type NotificationDebug struct {
+	// Enabled logs a sanitized summary of every notifier request/response at debug level
+	Enabled bool `koanf:"enabled"`
+	// CaptureBodies includes truncated, secret-redacted request and response bodies
+	CaptureBodies bool `koanf:"capture_bodies"`
+	// MaxBodyBytes is the maximum number of body bytes captured per request/response
+	MaxBodyBytes int `koanf:"max_body_bytes"`
+	// HistorySize is the number of most recent exchanges kept in memory
+	HistorySize int `koanf:"history_size"`
+}
+
+// NotificationDigest batches info and warning events into a periodic summary
+type NotificationDigest struct {
+	// Enabled batches info/warning events - error/critical events are always sent immediately
+	Enabled bool `koanf:"enabled"`
+	// IntervalDuration is how often batched events are sent as a single summary
+	IntervalDuration time.Duration `koanf:"interval_duration"`
+}
+
+// NotificationQuietHours holds back non-critical events while quiet - during a window or in maintenance mode
+type NotificationQuietHours struct {
+	// Mode is what happens to non-critical events while quiet - suppress drops them, downgrade sends them as info
+	Mode string `koanf:"mode"`
+	// Windows are recurring quiet hour windows
+	Windows []TimeWindow `koanf:"windows"`
+	// MaintenanceFile enables maintenance mode while the file exists, in addition to the API toggle
+	MaintenanceFile string `koanf:"maintenance_file"`
+}
+
+// NotificationTemplate holds Go templates for a notification's title and description - each is rendered
+// with the event (ValidatorName, Cluster, Details, etc.) and left as the default when empty
+type NotificationTemplate struct {
+	Title       string `koanf:"title"`
+	Description string `koanf:"description"`
+}
+
+// NotificationLink is a named link added to Discord and Slack notifications - URL is a Go template rendered
+// with the event (ValidatorName, ActivePubkey, Details, etc.)
+type NotificationLink struct {
+	Name string `koanf:"name"`
+	URL  string `koanf:"url"`
+	// Events limits the link to these event types - every event when empty
+	Events []string `koanf:"events"`
+}
+
+// NotificationMentions are the contact handles of a peer's operators on each service
+type NotificationMentions struct {
+	// Discord user IDs
+	Discord []string `koanf:"discord"`
+	// Slack member IDs
+	Slack []string `koanf:"slack"`
+	// Telegram usernames
+	Telegram []string `koanf:"telegram"`
+}
+
+// Validate validates the mention handles
+func (m NotificationMentions) Validate() error {
+	handlesByService := map[string][]string{
+		"discord":  m.Discord,
+		"slack":    m.Slack,
+		"telegram": m.Telegram,
+	}
+	for service, handles := range handlesByService {
+		for _, handle := range handles {
+			if !mentionHandlePatterns[service].MatchString(handle) {
+				return fmt.Errorf("%s: invalid handle %q", service, handle)
+			}
+		}
+	}
+	return nil
 }
 
 // DiscordConfig for Discord webhooks
@@ -37,34 +226,134 @@ type DiscordConfig struct {
 	Enabled       bool   `koanf:"enabled"`
 	WebhookURL    string `koanf:"webhook_url"`
 	WebhookURLEnv string `koanf:"webhook_url_env"`
-	Username      string `koanf:"username"`
-	AvatarURL     string `koanf:"avatar_url"`
+	// WebhookURLFile is a file holding the webhook URL, e.g. a systemd credential or mounted Kubernetes secret
+	WebhookURLFile string `koanf:"webhook_url_file"`
+	Username       string `koanf:"username"`
+	AvatarURL      string `koanf:"avatar_url"`
+	// Routes send matching events to other webhooks - the first matching route wins, else webhook_url is used
+	Routes []DiscordRoute `koanf:"routes"`
+	// Threads posts related events, such as becoming_active and became_active, into one thread - forum channels only
+	Threads bool `koanf:"threads"`
+	// TLS sets a private CA and client certificate for the webhook URLs
+	TLS NotificationTLS `koanf:"tls"`
+}
+
+// DiscordRoute sends events matching its event types and severities to its own webhook
+type DiscordRoute struct {
+	// Events are the event names routed - all events when empty
+	Events []string `koanf:"events"`
+	// Severities are the severities routed - all severities when empty
+	Severities     []string `koanf:"severities"`
+	WebhookURL     string   `koanf:"webhook_url"`
+	WebhookURLEnv  string   `koanf:"webhook_url_env"`
+	WebhookURLFile string   `koanf:"webhook_url_file"`
 }
 
 // TelegramConfig for Telegram Bot API
 type TelegramConfig struct {
-	Enabled     bool   `koanf:"enabled"`
-	BotToken    string `koanf:"bot_token"`
-	BotTokenEnv string `koanf:"bot_token_env"`
-	ChatID      string `koanf:"chat_id"`
-	ParseMode   string `koanf:"parse_mode"`
+	Enabled      bool   `koanf:"enabled"`
+	BotToken     string `koanf:"bot_token"`
+	BotTokenEnv  string `koanf:"bot_token_env"`
+	BotTokenFile string `koanf:"bot_token_file"`
+	ChatID       string `koanf:"chat_id"`
+	ParseMode    string `koanf:"parse_mode"`
+	// Commands lets allow-listed chats control the manager by messaging the bot
+	Commands TelegramCommands `koanf:"commands"`
+	// TLS sets a private CA and client certificate for the Telegram API, e.g. behind an intercepting proxy
+	TLS NotificationTLS `koanf:"tls"`
+}
+
+// TelegramCommands configures the bot commands accepted from allow-listed chats (/status, /maintenance, /failover)
+type TelegramCommands struct {
+	Enabled bool `koanf:"enabled"`
+	// AllowedChatIDs are the chats commands are accepted from - defaults to chat_id
+	AllowedChatIDs []string `koanf:"allowed_chat_ids"`
+	// RateLimitPerMinute is the maximum number of commands answered per chat per minute
+	RateLimitPerMinute int `koanf:"rate_limit_per_minute"`
+	// PollTimeoutDuration is how long each getUpdates long poll waits for new messages
+	PollTimeoutDuration time.Duration `koanf:"poll_timeout_duration"`
 }
 
 // SlackConfig for Slack webhooks
 type SlackConfig struct {
-	Enabled       bool   `koanf:"enabled"`
-	WebhookURL    string `koanf:"webhook_url"`
-	WebhookURLEnv string `koanf:"webhook_url_env"`
-	Channel       string `koanf:"channel"`
-	Username      string `koanf:"username"`
-	IconEmoji     string `koanf:"icon_emoji"`
+	Enabled        bool   `koanf:"enabled"`
+	WebhookURL     string `koanf:"webhook_url"`
+	WebhookURLEnv  string `koanf:"webhook_url_env"`
+	WebhookURLFile string `koanf:"webhook_url_file"`
+	Channel        string `koanf:"channel"`
+	Username       string `koanf:"username"`
+	IconEmoji      string `koanf:"icon_emoji"`
+	// Interactive adds action buttons to alerts, handled by a callback on the health check server
+	Interactive SlackInteractive `koanf:"interactive"`
+	// TLS sets a private CA and client certificate for the webhook URL
+	TLS NotificationTLS `koanf:"tls"`
+}
+
+// SlackInteractive configures the action buttons added to error and critical Slack alerts
+type SlackInteractive struct {
+	Enabled bool `koanf:"enabled"`
+	// SigningSecret verifies action callbacks come from the Slack app
+	SigningSecret     string `koanf:"signing_secret"`
+	SigningSecretEnv  string `koanf:"signing_secret_env"`
+	SigningSecretFile string `koanf:"signing_secret_file"`
+	// Actions are the buttons added to alerts - acknowledge, silence and failover
+	Actions []string `koanf:"actions"`
+	// SilenceDuration is how long the silence button holds back non-critical notifications
+	SilenceDuration time.Duration `koanf:"silence_duration"`
 }
 
 // PagerDutyConfig for PagerDuty Events API v2
 type PagerDutyConfig struct {
-	Enabled       bool   `koanf:"enabled"`
-	RoutingKey    string `koanf:"routing_key"`
-	RoutingKeyEnv string `koanf:"routing_key_env"`
+	Enabled        bool   `koanf:"enabled"`
+	RoutingKey     string `koanf:"routing_key"`
+	RoutingKeyEnv  string `koanf:"routing_key_env"`
+	RoutingKeyFile string `koanf:"routing_key_file"`
+	// ChangeEvents are sent as change events, adding context to the service timeline without opening incidents
+	ChangeEvents []string `koanf:"change_events"`
+	// EventActions maps event types to the alert event action they are sent with - trigger or resolve. Events
+	// not listed trigger; recovery events and became_passive resolve unless mapped to trigger.
+	EventActions map[string]string `koanf:"event_actions"`
+	// TLS sets a private CA and client certificate for the PagerDuty API, e.g. behind an intercepting proxy
+	TLS NotificationTLS `koanf:"tls"`
+}
+
+// TicketsConfig opens tickets in Jira or ServiceNow for critical events, resolving them on the recovery event
+type TicketsConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Provider is jira or servicenow
+	Provider string `koanf:"provider"`
+	// URL is the instance base URL, e.g. https://example.atlassian.net
+	URL      string `koanf:"url"`
+	Username string `koanf:"username"`
+	// Token is the Jira API token, or personal access token without a username, or the ServiceNow password
+	Token     string `koanf:"token"`
+	TokenEnv  string `koanf:"token_env"`
+	TokenFile string `koanf:"token_file"`
+	// Project is the Jira project key tickets are opened in
+	Project string `koanf:"project"`
+	// IssueType is the Jira issue type tickets are opened as
+	IssueType string `koanf:"issue_type"`
+	// Queue is the ServiceNow assignment group tickets are assigned to
+	Queue string `koanf:"queue"`
+	// Table is the ServiceNow table tickets are opened in
+	Table string `koanf:"table"`
+	// Events open tickets - critical severity events when empty
+	Events []string `koanf:"events"`
+	// Fields are extra fields set on opened tickets - Go templates rendered with the event
+	Fields map[string]string `koanf:"fields"`
+	// ResolveTransition is the Jira transition name or ServiceNow state applied when the recovery event arrives
+	ResolveTransition string `koanf:"resolve_transition"`
+	// ResolveFields are extra ServiceNow fields set when resolving, e.g. close_code
+	ResolveFields map[string]string `koanf:"resolve_fields"`
+	// TLS sets a private CA and client certificate for the instance URL
+	TLS NotificationTLS `koanf:"tls"`
+}
+
+// NotificationPlugins runs executables from a directory as notifiers, passing each event as JSON on stdin
+type NotificationPlugins struct {
+	Enabled bool `koanf:"enabled"`
+	// Dir is the directory plugins are discovered in - every executable file in it is a plugin
+	Dir string `koanf:"dir"`
 }
 
 // SetDefaults sets default values for notification configuration
@@ -83,11 +372,88 @@ func (n *NotificationConfig) SetDefaults() {
 	n.Events.GossipRecovered = true
 	n.Events.PeerDiscovered = true
 	n.Events.PeerLost = true
+	n.Events.RPCEndpointDemoted = true
+	n.Events.SnapshotRecoveryStarted = true
+	n.Events.SnapshotRecoveryCompleted = true
+	n.Events.SnapshotRecoveryFailed = true
+	n.Events.QuietPeriodEnded = true
+	n.Events.VoteAccountChanged = true
+	n.Events.ActiveIdentityOnPassive = true
+	n.Events.CircuitBreakerTripped = true
+	n.Events.CircuitBreakerReset = true
+	n.Events.Heartbeat = true
+	n.Events.Acknowledged = true
+	n.Events.ManualRoleChange = true
+	n.Events.ScheduledFailover = true
+	n.Events.VoteCreditsLagging = true
+	n.Events.SkipRateHigh = true
+	n.Events.Failback = true
+	n.Events.FlappingDetected = true
+
+	// Event history defaults
+	if n.HistorySize == 0 {
+		n.HistorySize = 100
+	}
+
+	// Delivery defaults
+	if n.Workers == 0 {
+		n.Workers = 4
+	}
+	if n.QueueSize == 0 {
+		n.QueueSize = 100
+	}
+	if n.TimeoutDuration == 0 {
+		n.TimeoutDuration = 10 * time.Second
+	}
+
+	// Spool defaults - only relevant when spool_dir is set
+	if n.SpoolMaxAgeDuration == 0 {
+		n.SpoolMaxAgeDuration = 24 * time.Hour
+	}
+	if n.SpoolMaxSizeBytes == 0 {
+		n.SpoolMaxSizeBytes = 10 * 1024 * 1024 // 10MiB
+	}
+	if n.SpoolReplayIntervalDuration == 0 {
+		n.SpoolReplayIntervalDuration = time.Minute
+	}
+
+	// Debug defaults
+	if n.Debug.MaxBodyBytes == 0 {
+		n.Debug.MaxBodyBytes = 1024
+	}
+	if n.Debug.HistorySize == 0 {
+		n.Debug.HistorySize = 20
+	}
+
+	// Digest defaults
+	if n.Digest.IntervalDuration == 0 {
+		n.Digest.IntervalDuration = 10 * time.Minute
+	}
+
+	// Heartbeat defaults
+	n.Heartbeat.SetDefaults()
+
+	// Quiet hours defaults
+	if n.QuietHours.Mode == "" {
+		n.QuietHours.Mode = "suppress"
+	}
+	for i := range n.QuietHours.Windows {
+		n.QuietHours.Windows[i].SetDefaults()
+	}
 
 	// Telegram defaults
 	if n.Telegram.ParseMode == "" {
 		n.Telegram.ParseMode = "HTML"
 	}
+	if len(n.Telegram.Commands.AllowedChatIDs) == 0 && n.Telegram.ChatID != "" {
+		n.Telegram.Commands.AllowedChatIDs = []string{n.Telegram.ChatID}
+	}
+	if n.Telegram.Commands.RateLimitPerMinute == 0 {
+		n.Telegram.Commands.RateLimitPerMinute = 6
+	}
+	if n.Telegram.Commands.PollTimeoutDuration == 0 {
+		n.Telegram.Commands.PollTimeoutDuration = 30 * time.Second
+	}
 
 	// Discord defaults
 	if n.Discord.Username == "" {
@@ -101,25 +467,218 @@ func (n *NotificationConfig) SetDefaults() {
 	if n.Slack.IconEmoji == "" {
 		n.Slack.IconEmoji = ":robot_face:"
 	}
+	if len(n.Slack.Interactive.Actions) == 0 {
+		n.Slack.Interactive.Actions = slices.Clone(slackActions)
+	}
+	if n.Slack.Interactive.SilenceDuration == 0 {
+		n.Slack.Interactive.SilenceDuration = time.Hour
+	}
+
+	// PagerDuty defaults
+	if len(n.PagerDuty.ChangeEvents) == 0 {
+		n.PagerDuty.ChangeEvents = []string{"startup", "shutdown", "became_passive"}
+	}
+	if n.PagerDuty.EventActions == nil {
+		n.PagerDuty.EventActions = make(map[string]string)
+	}
+	for _, eventName := range []string{"health_recovered", "gossip_recovered", "became_passive"} {
+		if _, ok := n.PagerDuty.EventActions[eventName]; !ok {
+			n.PagerDuty.EventActions[eventName] = "resolve"
+		}
+	}
+
+	// Tickets defaults
+	switch n.Tickets.Provider {
+	case "jira":
+		if n.Tickets.IssueType == "" {
+			n.Tickets.IssueType = "Task"
+		}
+		if n.Tickets.ResolveTransition == "" {
+			n.Tickets.ResolveTransition = "Done"
+		}
+	case "servicenow":
+		if n.Tickets.Table == "" {
+			n.Tickets.Table = "incident"
+		}
+		if n.Tickets.ResolveTransition == "" {
+			n.Tickets.ResolveTransition = "6" // Resolved
+		}
+		if len(n.Tickets.ResolveFields) == 0 {
+			n.Tickets.ResolveFields = map[string]string{"close_code": "Resolved by caller"}
+		}
+	}
 }
 
 // Validate validates the notification configuration
 func (n *NotificationConfig) Validate() error {
+	// Validate event history config - the history is kept whether or not notifications are enabled
+	if n.HistorySize < 0 {
+		return fmt.Errorf("notifications.history_size must be positive")
+	}
+
 	if !n.Enabled {
 		return nil
 	}
 
+	// Validate severity overrides
+	eventNames := n.Events.Names()
+	for eventName, severity := range n.SeverityOverrides {
+		if !slices.Contains(eventNames, eventName) {
+			return fmt.Errorf("notifications.severity_overrides: unknown event %s", eventName)
+		}
+		if !slices.Contains(notificationSeverities, severity) {
+			return fmt.Errorf("notifications.severity_overrides.%s must be one of %v", eventName, notificationSeverities)
+		}
+	}
+
+	// Validate delivery config
+	if n.Workers < 0 {
+		return fmt.Errorf("notifications.workers must be positive")
+	}
+	if n.QueueSize < 0 {
+		return fmt.Errorf("notifications.queue_size must be positive")
+	}
+	if n.TimeoutDuration < 0 {
+		return fmt.Errorf("notifications.timeout_duration must be positive")
+	}
+
+	// Validate severity routing
+	for severity, services := range n.Routing {
+		if !slices.Contains(notificationSeverities, severity) {
+			return fmt.Errorf("notifications.routing: unknown severity %s, must be one of %v", severity, notificationSeverities)
+		}
+		if len(services) == 0 {
+			return fmt.Errorf("notifications.routing.%s must list at least one service", severity)
+		}
+		for _, service := range services {
+			if !slices.Contains(notificationServices, service) {
+				return fmt.Errorf("notifications.routing.%s: unknown service %s, must be one of %v", severity, service, notificationServices)
+			}
+			if !n.serviceEnabled(service) {
+				return fmt.Errorf("notifications.routing.%s: %s is not enabled", severity, service)
+			}
+		}
+	}
+
+	// Validate dedup config
+	if n.DedupWindowDuration < 0 {
+		return fmt.Errorf("notifications.dedup_window_duration must be positive")
+	}
+
+	// Validate spool config
+	if n.SpoolDir != "" {
+		if n.SpoolMaxAgeDuration < 0 {
+			return fmt.Errorf("notifications.spool_max_age_duration must be positive")
+		}
+		if n.SpoolMaxSizeBytes < 0 {
+			return fmt.Errorf("notifications.spool_max_size_bytes must be positive")
+		}
+		if n.SpoolReplayIntervalDuration < 0 {
+			return fmt.Errorf("notifications.spool_replay_interval_duration must be positive")
+		}
+	}
+
+	// Validate debug config
+	if n.Debug.Enabled {
+		if n.Debug.MaxBodyBytes < 0 {
+			return fmt.Errorf("notifications.debug.max_body_bytes must be positive")
+		}
+		if n.Debug.HistorySize < 0 {
+			return fmt.Errorf("notifications.debug.history_size must be positive")
+		}
+	}
+
+	// Validate digest config
+	if n.Digest.Enabled && n.Digest.IntervalDuration <= 0 {
+		return fmt.Errorf("notifications.digest.interval_duration must be greater than zero")
+	}
+
+	// Validate heartbeat config
+	if err := n.Heartbeat.Validate(); err != nil {
+		return err
+	}
+
+	// Validate templates
+	for key, tmpl := range n.Templates {
+		if !slices.Contains(eventNames, key) && !slices.Contains(notificationTemplateKeys, key) {
+			return fmt.Errorf("notifications.templates: unknown event %s", key)
+		}
+		if _, err := template.New(key).Parse(tmpl.Title); err != nil {
+			return fmt.Errorf("notifications.templates.%s.title: %w", key, err)
+		}
+		if _, err := template.New(key).Parse(tmpl.Description); err != nil {
+			return fmt.Errorf("notifications.templates.%s.description: %w", key, err)
+		}
+	}
+
+	// Validate links
+	for i, link := range n.Links {
+		if link.Name == "" {
+			return fmt.Errorf("notifications.links[%d].name is required", i)
+		}
+		if link.URL == "" {
+			return fmt.Errorf("notifications.links[%d].url is required", i)
+		}
+		if _, err := template.New(link.Name).Parse(link.URL); err != nil {
+			return fmt.Errorf("notifications.links[%d].url: %w", i, err)
+		}
+		for _, event := range link.Events {
+			if !slices.Contains(eventNames, event) {
+				return fmt.Errorf("notifications.links[%d].events: unknown event %s", i, event)
+			}
+		}
+	}
+
+	// Validate mentions
+	for peerName, mentions := range n.Mentions {
+		if err := mentions.Validate(); err != nil {
+			return fmt.Errorf("notifications.mentions.%s.%w", peerName, err)
+		}
+	}
+
+	// Validate quiet hours config
+	if n.QuietHours.Mode != "" && !slices.Contains(quietHoursModes, n.QuietHours.Mode) {
+		return fmt.Errorf("notifications.quiet_hours.mode must be one of %v", quietHoursModes)
+	}
+	for i, window := range n.QuietHours.Windows {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("notifications.quiet_hours.windows[%d]: %w", i, err)
+		}
+	}
+
 	// Validate Discord config
 	if n.Discord.Enabled {
-		if n.Discord.WebhookURL == "" && n.Discord.WebhookURLEnv == "" {
-			return fmt.Errorf("notifications.discord: webhook_url or webhook_url_env is required when enabled")
+		if n.Discord.WebhookURL == "" && n.Discord.WebhookURLEnv == "" && n.Discord.WebhookURLFile == "" {
+			return fmt.Errorf("notifications.discord: webhook_url, webhook_url_env or webhook_url_file is required when enabled")
+		}
+		eventNames := n.Events.Names()
+		for i, route := range n.Discord.Routes {
+			if len(route.Events) == 0 && len(route.Severities) == 0 {
+				return fmt.Errorf("notifications.discord.routes[%d]: events or severities is required", i)
+			}
+			for _, eventName := range route.Events {
+				if !slices.Contains(eventNames, eventName) {
+					return fmt.Errorf("notifications.discord.routes[%d].events: unknown event %s", i, eventName)
+				}
+			}
+			for _, severity := range route.Severities {
+				if !slices.Contains(notificationSeverities, severity) {
+					return fmt.Errorf("notifications.discord.routes[%d].severities must be one of %v", i, notificationSeverities)
+				}
+			}
+			if route.WebhookURL == "" && route.WebhookURLEnv == "" && route.WebhookURLFile == "" {
+				return fmt.Errorf("notifications.discord.routes[%d]: webhook_url, webhook_url_env or webhook_url_file is required", i)
+			}
+		}
+		if err := n.Discord.TLS.Validate("notifications.discord.tls"); err != nil {
+			return err
 		}
 	}
 
 	// Validate Telegram config
 	if n.Telegram.Enabled {
-		if n.Telegram.BotToken == "" && n.Telegram.BotTokenEnv == "" {
-			return fmt.Errorf("notifications.telegram: bot_token or bot_token_env is required when enabled")
+		if n.Telegram.BotToken == "" && n.Telegram.BotTokenEnv == "" && n.Telegram.BotTokenFile == "" {
+			return fmt.Errorf("notifications.telegram: bot_token, bot_token_env or bot_token_file is required when enabled")
 		}
 		if n.Telegram.ChatID == "" {
 			return fmt.Errorf("notifications.telegram: chat_id is required when enabled")
@@ -127,19 +686,113 @@ func (n *NotificationConfig) Validate() error {
 		if n.Telegram.ParseMode != "HTML" && n.Telegram.ParseMode != "Markdown" && n.Telegram.ParseMode != "MarkdownV2" {
 			return fmt.Errorf("notifications.telegram: parse_mode must be HTML, Markdown, or MarkdownV2")
 		}
+		if n.Telegram.Commands.Enabled {
+			if len(n.Telegram.Commands.AllowedChatIDs) == 0 {
+				return fmt.Errorf("notifications.telegram.commands.allowed_chat_ids must not be empty when enabled")
+			}
+			if n.Telegram.Commands.RateLimitPerMinute < 0 {
+				return fmt.Errorf("notifications.telegram.commands.rate_limit_per_minute must be positive")
+			}
+			if n.Telegram.Commands.PollTimeoutDuration < 0 {
+				return fmt.Errorf("notifications.telegram.commands.poll_timeout_duration must be positive")
+			}
+		}
+		if err := n.Telegram.TLS.Validate("notifications.telegram.tls"); err != nil {
+			return err
+		}
 	}
 
 	// Validate Slack config
 	if n.Slack.Enabled {
-		if n.Slack.WebhookURL == "" && n.Slack.WebhookURLEnv == "" {
-			return fmt.Errorf("notifications.slack: webhook_url or webhook_url_env is required when enabled")
+		if n.Slack.WebhookURL == "" && n.Slack.WebhookURLEnv == "" && n.Slack.WebhookURLFile == "" {
+			return fmt.Errorf("notifications.slack: webhook_url, webhook_url_env or webhook_url_file is required when enabled")
+		}
+		if n.Slack.Interactive.Enabled {
+			if n.Slack.Interactive.SigningSecret == "" && n.Slack.Interactive.SigningSecretEnv == "" && n.Slack.Interactive.SigningSecretFile == "" {
+				return fmt.Errorf("notifications.slack.interactive: signing_secret, signing_secret_env or signing_secret_file is required when enabled")
+			}
+			for _, action := range n.Slack.Interactive.Actions {
+				if !slices.Contains(slackActions, action) {
+					return fmt.Errorf("notifications.slack.interactive.actions must be one of %v", slackActions)
+				}
+			}
+			if n.Slack.Interactive.SilenceDuration < 0 {
+				return fmt.Errorf("notifications.slack.interactive.silence_duration must be positive")
+			}
+		}
+		if err := n.Slack.TLS.Validate("notifications.slack.tls"); err != nil {
+			return err
 		}
 	}
 
 	// Validate PagerDuty config
 	if n.PagerDuty.Enabled {
-		if n.PagerDuty.RoutingKey == "" && n.PagerDuty.RoutingKeyEnv == "" {
-			return fmt.Errorf("notifications.pagerduty: routing_key or routing_key_env is required when enabled")
+		if n.PagerDuty.RoutingKey == "" && n.PagerDuty.RoutingKeyEnv == "" && n.PagerDuty.RoutingKeyFile == "" {
+			return fmt.Errorf("notifications.pagerduty: routing_key, routing_key_env or routing_key_file is required when enabled")
+		}
+		eventNames := n.Events.Names()
+		for _, eventName := range n.PagerDuty.ChangeEvents {
+			if !slices.Contains(eventNames, eventName) {
+				return fmt.Errorf("notifications.pagerduty.change_events: unknown event %s", eventName)
+			}
+		}
+		for eventName, action := range n.PagerDuty.EventActions {
+			if !slices.Contains(eventNames, eventName) {
+				return fmt.Errorf("notifications.pagerduty.event_actions: unknown event %s", eventName)
+			}
+			if !slices.Contains(pagerDutyEventActions, action) {
+				return fmt.Errorf("notifications.pagerduty.event_actions.%s must be one of %v", eventName, pagerDutyEventActions)
+			}
+		}
+		if err := n.PagerDuty.TLS.Validate("notifications.pagerduty.tls"); err != nil {
+			return err
+		}
+	}
+
+	// Validate tickets config
+	if n.Tickets.Enabled {
+		if !slices.Contains(ticketProviders, n.Tickets.Provider) {
+			return fmt.Errorf("notifications.tickets.provider must be one of %v", ticketProviders)
+		}
+		if n.Tickets.URL == "" {
+			return fmt.Errorf("notifications.tickets.url is required when enabled")
+		}
+		if n.Tickets.Token == "" && n.Tickets.TokenEnv == "" && n.Tickets.TokenFile == "" {
+			return fmt.Errorf("notifications.tickets: token, token_env or token_file is required when enabled")
+		}
+		if n.Tickets.Provider == "jira" && n.Tickets.Project == "" {
+			return fmt.Errorf("notifications.tickets.project is required for jira")
+		}
+		if n.Tickets.Provider == "servicenow" && n.Tickets.Username == "" {
+			return fmt.Errorf("notifications.tickets.username is required for servicenow")
+		}
+		eventNames := n.Events.Names()
+		for _, eventName := range n.Tickets.Events {
+			if !slices.Contains(eventNames, eventName) {
+				return fmt.Errorf("notifications.tickets.events: unknown event %s", eventName)
+			}
+		}
+		for field, value := range n.Tickets.Fields {
+			if _, err := template.New(field).Parse(value); err != nil {
+				return fmt.Errorf("notifications.tickets.fields.%s: %w", field, err)
+			}
+		}
+		if err := n.Tickets.TLS.Validate("notifications.tickets.tls"); err != nil {
+			return err
+		}
+	}
+
+	// Validate plugins config
+	if n.Plugins.Enabled {
+		if n.Plugins.Dir == "" {
+			return fmt.Errorf("notifications.plugins.dir is required when enabled")
+		}
+		info, err := os.Stat(n.Plugins.Dir)
+		if err != nil {
+			return fmt.Errorf("notifications.plugins.dir: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("notifications.plugins.dir must be a directory")
 		}
 	}
 
@@ -147,51 +800,124 @@ func (n *NotificationConfig) Validate() error {
 }
 
 // ResolveSecrets resolves environment variable references for secrets
-func (n *NotificationConfig) ResolveSecrets() error {
+func (n *NotificationConfig) ResolveSecrets(ctx context.Context) error {
 	if !n.Enabled {
 		return nil
 	}
 
-	// Resolve Discord webhook URL
-	if n.Discord.Enabled && n.Discord.WebhookURL == "" && n.Discord.WebhookURLEnv != "" {
-		value := os.Getenv(n.Discord.WebhookURLEnv)
-		if value == "" {
-			return fmt.Errorf("notifications.discord: environment variable %s is not set", n.Discord.WebhookURLEnv)
+	// Resolve secrets from environment variables or files
+	for _, secret := range n.secretFields() {
+		if err := secret.resolveSource(); err != nil {
+			return err
 		}
-		n.Discord.WebhookURL = value
 	}
 
-	// Resolve Telegram bot token
-	if n.Telegram.Enabled && n.Telegram.BotToken == "" && n.Telegram.BotTokenEnv != "" {
-		value := os.Getenv(n.Telegram.BotTokenEnv)
-		if value == "" {
-			return fmt.Errorf("notifications.telegram: environment variable %s is not set", n.Telegram.BotTokenEnv)
+	// Resolve secrets referenced in AWS Secrets Manager or SSM Parameter Store
+	for _, secret := range n.secretFields() {
+		if !secret.enabled {
+			continue
 		}
-		n.Telegram.BotToken = value
+		value, err := resolveSecretRef(ctx, *secret.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", secret.path, err)
+		}
+		*secret.value = value
 	}
 
-	// Resolve Slack webhook URL
-	if n.Slack.Enabled && n.Slack.WebhookURL == "" && n.Slack.WebhookURLEnv != "" {
-		value := os.Getenv(n.Slack.WebhookURLEnv)
-		if value == "" {
-			return fmt.Errorf("notifications.slack: environment variable %s is not set", n.Slack.WebhookURLEnv)
+	return nil
+}
+
+// Secrets returns the secrets of the enabled notification services that are set, keyed by their config path
+func (n *NotificationConfig) Secrets() map[string]string {
+	secrets := map[string]string{}
+	for _, secret := range n.secretFields() {
+		if n.Enabled && secret.enabled && *secret.value != "" {
+			secrets[secret.path] = *secret.value
 		}
-		n.Slack.WebhookURL = value
+	}
+	return secrets
+}
+
+// secretField is a notification secret config field
+type secretField struct {
+	path string
+	// enabled is true if the service the secret is for is enabled
+	enabled bool
+	value   *string
+	// env and file are the <field>_env and <field>_file settings the value is read from when not set directly
+	env  string
+	file string
+}
+
+// resolveSource sets the secret from its environment variable, or else its file, when enabled and not set directly
+func (s secretField) resolveSource() error {
+	if !s.enabled || *s.value != "" {
+		return nil
 	}
 
-	// Resolve PagerDuty routing key
-	if n.PagerDuty.Enabled && n.PagerDuty.RoutingKey == "" && n.PagerDuty.RoutingKeyEnv != "" {
-		value := os.Getenv(n.PagerDuty.RoutingKeyEnv)
+	dot := strings.LastIndex(s.path, ".")
+	section, field := s.path[:dot], s.path[dot+1:]
+	switch {
+	case s.env != "":
+		value := os.Getenv(s.env)
 		if value == "" {
-			return fmt.Errorf("notifications.pagerduty: environment variable %s is not set", n.PagerDuty.RoutingKeyEnv)
+			return fmt.Errorf("%s: environment variable %s is not set", section, s.env)
 		}
-		n.PagerDuty.RoutingKey = value
+		*s.value = value
+	case s.file != "":
+		content, err := os.ReadFile(s.file)
+		if err != nil {
+			return fmt.Errorf("%s: failed to read %s_file: %w", section, field, err)
+		}
+		// files written by editors and echo end with a newline
+		value := strings.TrimSpace(string(content))
+		if value == "" {
+			return fmt.Errorf("%s: %s_file %s is empty", section, field, s.file)
+		}
+		*s.value = value
 	}
-
 	return nil
 }
 
+// secretFields returns the notification secret config fields
+func (n *NotificationConfig) secretFields() []secretField {
+	fields := []secretField{
+		{"notifications.discord.webhook_url", n.Discord.Enabled, &n.Discord.WebhookURL, n.Discord.WebhookURLEnv, n.Discord.WebhookURLFile},
+	}
+	for i := range n.Discord.Routes {
+		route := &n.Discord.Routes[i]
+		fields = append(fields, secretField{fmt.Sprintf("notifications.discord.routes[%d].webhook_url", i), n.Discord.Enabled, &route.WebhookURL, route.WebhookURLEnv, route.WebhookURLFile})
+	}
+	return append(fields,
+		secretField{"notifications.telegram.bot_token", n.Telegram.Enabled, &n.Telegram.BotToken, n.Telegram.BotTokenEnv, n.Telegram.BotTokenFile},
+		secretField{"notifications.slack.webhook_url", n.Slack.Enabled, &n.Slack.WebhookURL, n.Slack.WebhookURLEnv, n.Slack.WebhookURLFile},
+		secretField{"notifications.slack.interactive.signing_secret", n.Slack.Enabled && n.Slack.Interactive.Enabled, &n.Slack.Interactive.SigningSecret, n.Slack.Interactive.SigningSecretEnv, n.Slack.Interactive.SigningSecretFile},
+		secretField{"notifications.pagerduty.routing_key", n.PagerDuty.Enabled, &n.PagerDuty.RoutingKey, n.PagerDuty.RoutingKeyEnv, n.PagerDuty.RoutingKeyFile},
+		secretField{"notifications.tickets.token", n.Tickets.Enabled, &n.Tickets.Token, n.Tickets.TokenEnv, n.Tickets.TokenFile},
+	)
+}
+
 // HasAnyEnabled returns true if any notification service is enabled
 func (n *NotificationConfig) HasAnyEnabled() bool {
-	return n.Enabled && (n.Discord.Enabled || n.Telegram.Enabled || n.Slack.Enabled || n.PagerDuty.Enabled)
+	return n.Enabled && (n.Discord.Enabled || n.Telegram.Enabled || n.Slack.Enabled || n.PagerDuty.Enabled || n.Tickets.Enabled || n.Plugins.Enabled)
+}
+
+// serviceEnabled returns true if the named notification service is enabled
+func (n *NotificationConfig) serviceEnabled(service string) bool {
+	switch service {
+	case "discord":
+		return n.Discord.Enabled
+	case "telegram":
+		return n.Telegram.Enabled
+	case "slack":
+		return n.Slack.Enabled
+	case "pagerduty":
+		return n.PagerDuty.Enabled
+	case "tickets":
+		return n.Tickets.Enabled
+	case "plugins":
+		return n.Plugins.Enabled
+	default:
+		return false
+	}
 }
@@ -0,0 +1,483 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationConfig_ValidateSeverityOverrides(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		SeverityOverrides: map[string]string{
+			"gossip_lost": "critical",
+			"peer_lost":   "info",
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+
+	// Test with unknown event
+	notifications.SeverityOverrides = map[string]string{"not_an_event": "info"}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.severity_overrides: unknown event not_an_event")
+
+	// Test with invalid severity
+	notifications.SeverityOverrides = map[string]string{"gossip_lost": "panic"}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.severity_overrides.gossip_lost must be one of")
+}
+
+func TestNotificationEvents_Names(t *testing.T) {
+	names := NotificationEvents{}.Names()
+	assert.Contains(t, names, "startup")
+	assert.Contains(t, names, "gossip_lost")
+	assert.Contains(t, names, "rpc_endpoint_demoted")
+}
+
+func TestNotificationConfig_ValidateQuietHours(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		QuietHours: NotificationQuietHours{
+			Windows: []TimeWindow{{Start: "22:00", End: "07:00"}},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+	assert.Equal(t, "suppress", notifications.QuietHours.Mode)
+	assert.Equal(t, "UTC", notifications.QuietHours.Windows[0].Timezone)
+
+	// Test with invalid mode
+	notifications.QuietHours.Mode = "mute"
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.quiet_hours.mode must be one of")
+
+	// Test with invalid window
+	notifications.QuietHours.Mode = "downgrade"
+	notifications.QuietHours.Windows[0].End = "7am"
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.quiet_hours.windows[0]: end must be a HH:MM time")
+}
+
+func TestNotificationConfig_ValidateTemplates(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Templates: map[string]NotificationTemplate{
+			"peer_lost": {Title: "{{ .ValidatorName }} lost {{ .Details.peer_name }}"},
+			"default":   {Description: "{{ .Message }}"},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+
+	// Test with unknown event
+	notifications.Templates = map[string]NotificationTemplate{"not_an_event": {Title: "x"}}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.templates: unknown event not_an_event")
+
+	// Test with invalid template
+	notifications.Templates = map[string]NotificationTemplate{"startup": {Description: "{{ .Message"}}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.templates.startup.description")
+}
+
+func TestNotificationConfig_ValidateLinks(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Links: []NotificationLink{
+			{Name: "Explorer", URL: "https://solscan.io/account/{{ .ActivePubkey }}"},
+			{Name: "Runbook", URL: "https://wiki.example.com/failover", Events: []string{"becoming_active"}},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+
+	tests := []struct {
+		link NotificationLink
+		err  string
+	}{
+		{NotificationLink{URL: "https://example.com"}, "notifications.links[0].name is required"},
+		{NotificationLink{Name: "Grafana"}, "notifications.links[0].url is required"},
+		{NotificationLink{Name: "Grafana", URL: "{{ .ValidatorName"}, "notifications.links[0].url"},
+		{NotificationLink{Name: "Grafana", URL: "https://example.com", Events: []string{"not_an_event"}}, "notifications.links[0].events: unknown event not_an_event"},
+	}
+	for _, tt := range tests {
+		notifications.Links = []NotificationLink{tt.link}
+		err := notifications.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), tt.err)
+	}
+}
+
+func TestNotificationConfig_ValidateMentions(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Mentions: map[string]NotificationMentions{
+			"validator-1": {
+				Discord:  []string{"123456789012345678"},
+				Slack:    []string{"U01ABCDEF"},
+				Telegram: []string{"@validator_ops", "backup_ops"},
+			},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+
+	// Test with a discord username instead of a user ID
+	notifications.Mentions["validator-1"] = NotificationMentions{Discord: []string{"@ops"}}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `notifications.mentions.validator-1.discord: invalid handle "@ops"`)
+
+	// Test with a slack channel ID
+	notifications.Mentions["validator-1"] = NotificationMentions{Slack: []string{"C01ABCDEF"}}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.mentions.validator-1.slack")
+}
+
+func TestNotificationConfig_ValidateTelegramCommands(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Telegram: TelegramConfig{
+			Enabled:  true,
+			BotToken: "123:abc",
+			ChatID:   "-1001234567890",
+			Commands: TelegramCommands{Enabled: true},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+	assert.Equal(t, []string{"-1001234567890"}, notifications.Telegram.Commands.AllowedChatIDs)
+	assert.Equal(t, 6, notifications.Telegram.Commands.RateLimitPerMinute)
+	assert.Equal(t, 30*time.Second, notifications.Telegram.Commands.PollTimeoutDuration)
+
+	// Test with a negative rate limit
+	notifications.Telegram.Commands.RateLimitPerMinute = -1
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.telegram.commands.rate_limit_per_minute must be positive")
+
+	// Test without allowed chats
+	notifications.Telegram.Commands.RateLimitPerMinute = 6
+	notifications.Telegram.Commands.AllowedChatIDs = nil
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.telegram.commands.allowed_chat_ids must not be empty when enabled")
+}
+
+func TestNotificationConfig_ValidateSlackInteractive(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Slack: SlackConfig{
+			Enabled:     true,
+			WebhookURL:  "https://hooks.slack.com/services/T000/B000/XXXX",
+			Interactive: SlackInteractive{Enabled: true, SigningSecretEnv: "TEST_SLACK_SIGNING_SECRET"},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+	assert.Equal(t, []string{"acknowledge", "silence", "failover"}, notifications.Slack.Interactive.Actions)
+	assert.Equal(t, time.Hour, notifications.Slack.Interactive.SilenceDuration)
+
+	// Test signing secret resolution
+	t.Setenv("TEST_SLACK_SIGNING_SECRET", "8f742231b10e8888abcd99yyyzzz85a5")
+	assert.NoError(t, notifications.ResolveSecrets(context.Background()))
+	assert.Equal(t, "8f742231b10e8888abcd99yyyzzz85a5", notifications.Slack.Interactive.SigningSecret)
+
+	// Test with an unknown action
+	notifications.Slack.Interactive.Actions = []string{"acknowledge", "reboot"}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.slack.interactive.actions must be one of")
+
+	// Test without a signing secret
+	notifications.Slack.Interactive.Actions = []string{"acknowledge"}
+	notifications.Slack.Interactive.SigningSecret = ""
+	notifications.Slack.Interactive.SigningSecretEnv = ""
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.slack.interactive: signing_secret, signing_secret_env or signing_secret_file is required when enabled")
+}
+
+func TestNotificationConfig_ValidateDiscordRoutes(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Discord: DiscordConfig{
+			Enabled:    true,
+			WebhookURL: "https://discord.com/api/webhooks/1/default",
+			Routes: []DiscordRoute{
+				{Events: []string{"becoming_active", "became_active"}, WebhookURL: "https://discord.com/api/webhooks/2/failover"},
+				{Severities: []string{"critical"}, WebhookURLEnv: "TEST_DISCORD_CRITICAL_WEBHOOK_URL"},
+			},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+
+	// Test route webhook URL resolution
+	t.Setenv("TEST_DISCORD_CRITICAL_WEBHOOK_URL", "https://discord.com/api/webhooks/3/critical")
+	assert.NoError(t, notifications.ResolveSecrets(context.Background()))
+	assert.Equal(t, "https://discord.com/api/webhooks/3/critical", notifications.Discord.Routes[1].WebhookURL)
+
+	// Test with an unknown event
+	notifications.Discord.Routes[0].Events = []string{"becoming_leader"}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.discord.routes[0].events: unknown event becoming_leader")
+
+	// Test with an unknown severity
+	notifications.Discord.Routes[0].Events = nil
+	notifications.Discord.Routes[0].Severities = []string{"fatal"}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.discord.routes[0].severities must be one of")
+
+	// Test with nothing to match
+	notifications.Discord.Routes[0].Severities = nil
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.discord.routes[0]: events or severities is required")
+}
+
+func TestNotificationConfig_ValidatePagerDutyChangeEvents(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled:   true,
+		PagerDuty: PagerDutyConfig{Enabled: true, RoutingKey: "routing-key"},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+	assert.Equal(t, []string{"startup", "shutdown", "became_passive"}, notifications.PagerDuty.ChangeEvents)
+
+	// Test with an unknown event
+	notifications.PagerDuty.ChangeEvents = []string{"startup", "restarted"}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.pagerduty.change_events: unknown event restarted")
+}
+
+func TestNotificationConfig_ValidatePagerDutyEventActions(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		PagerDuty: PagerDutyConfig{
+			Enabled:      true,
+			RoutingKey:   "routing-key",
+			EventActions: map[string]string{"became_passive": "trigger", "peer_discovered": "resolve"},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+
+	// configured actions are kept and the remaining defaults filled in
+	assert.Equal(t, map[string]string{
+		"health_recovered": "resolve",
+		"gossip_recovered": "resolve",
+		"became_passive":   "trigger",
+		"peer_discovered":  "resolve",
+	}, notifications.PagerDuty.EventActions)
+
+	notifications.PagerDuty.EventActions = map[string]string{"restarted": "resolve"}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.pagerduty.event_actions: unknown event restarted")
+
+	notifications.PagerDuty.EventActions = map[string]string{"peer_lost": "acknowledge"}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.pagerduty.event_actions.peer_lost must be one of")
+}
+
+func TestNotificationConfig_ValidateTickets(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Tickets: TicketsConfig{Enabled: true, Provider: "jira", URL: "https://example.atlassian.net", Token: "token", Project: "OPS"},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+	assert.Equal(t, "Task", notifications.Tickets.IssueType)
+	assert.Equal(t, "Done", notifications.Tickets.ResolveTransition)
+	assert.True(t, notifications.HasAnyEnabled())
+
+	tests := []struct {
+		name        string
+		modify      func(tickets *TicketsConfig)
+		errContains string
+	}{
+		{name: "unknown provider", modify: func(tickets *TicketsConfig) { tickets.Provider = "zendesk" }, errContains: "notifications.tickets.provider must be one of"},
+		{name: "missing url", modify: func(tickets *TicketsConfig) { tickets.URL = "" }, errContains: "notifications.tickets.url is required"},
+		{name: "missing token", modify: func(tickets *TicketsConfig) { tickets.Token = "" }, errContains: "token, token_env or token_file is required"},
+		{name: "jira without project", modify: func(tickets *TicketsConfig) { tickets.Project = "" }, errContains: "notifications.tickets.project is required for jira"},
+		{name: "servicenow without username", modify: func(tickets *TicketsConfig) { tickets.Provider = "servicenow" }, errContains: "notifications.tickets.username is required for servicenow"},
+		{name: "unknown event", modify: func(tickets *TicketsConfig) { tickets.Events = []string{"exploded"} }, errContains: "notifications.tickets.events: unknown event exploded"},
+		{name: "bad field template", modify: func(tickets *TicketsConfig) { tickets.Fields = map[string]string{"labels": "{{ .Nope"} }, errContains: "notifications.tickets.fields.labels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := *notifications
+			tt.modify(&invalid.Tickets)
+			err := invalid.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestNotificationConfig_TicketsServiceNowDefaults(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Tickets: TicketsConfig{Enabled: true, Provider: "servicenow", URL: "https://example.service-now.com", Username: "svc-ha", Token: "password"},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+	assert.Equal(t, "incident", notifications.Tickets.Table)
+	assert.Equal(t, "6", notifications.Tickets.ResolveTransition)
+	assert.Equal(t, map[string]string{"close_code": "Resolved by caller"}, notifications.Tickets.ResolveFields)
+}
+
+func TestNotificationConfig_ValidateHistorySize(t *testing.T) {
+	notifications := &NotificationConfig{}
+	notifications.SetDefaults()
+	assert.Equal(t, 100, notifications.HistorySize)
+	assert.NoError(t, notifications.Validate())
+
+	// validated even when notifications are disabled as the history is always kept
+	notifications.HistorySize = -1
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.history_size must be positive")
+}
+
+func TestNotificationConfig_ValidateDelivery(t *testing.T) {
+	notifications := &NotificationConfig{Enabled: true}
+	notifications.SetDefaults()
+	assert.Equal(t, 4, notifications.Workers)
+	assert.Equal(t, 100, notifications.QueueSize)
+	assert.Equal(t, 10*time.Second, notifications.TimeoutDuration)
+	assert.NoError(t, notifications.Validate())
+
+	notifications.Workers = -1
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.workers must be positive")
+
+	notifications.Workers = 4
+	notifications.TimeoutDuration = -time.Second
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.timeout_duration must be positive")
+}
+
+func TestNotificationConfig_ValidateRouting(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled:   true,
+		PagerDuty: PagerDutyConfig{Enabled: true, RoutingKey: "routing-key"},
+		Slack:     SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/services/x"},
+		Routing: map[string][]string{
+			"critical": {"pagerduty", "slack"},
+			"info":     {"slack"},
+		},
+	}
+	notifications.SetDefaults()
+	assert.NoError(t, notifications.Validate())
+
+	// Test with unknown severity
+	notifications.Routing = map[string][]string{"urgent": {"slack"}}
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.routing: unknown severity urgent")
+
+	// Test with no services
+	notifications.Routing = map[string][]string{"info": {}}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.routing.info must list at least one service")
+
+	// Test with unknown service
+	notifications.Routing = map[string][]string{"info": {"email"}}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.routing.info: unknown service email")
+
+	// Test with a service that is not enabled
+	notifications.Routing = map[string][]string{"critical": {"telegram"}}
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.routing.critical: telegram is not enabled")
+}
+
+func TestNotificationConfig_ValidatePlugins(t *testing.T) {
+	notifications := &NotificationConfig{
+		Enabled: true,
+		Plugins: NotificationPlugins{Enabled: true},
+	}
+	notifications.SetDefaults()
+	err := notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.plugins.dir is required when enabled")
+
+	notifications.Plugins.Dir = filepath.Join(t.TempDir(), "missing")
+	err = notifications.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.plugins.dir")
+
+	notifications.Plugins.Dir = t.TempDir()
+	notifications.Routing = map[string][]string{"critical": {"plugins"}}
+	assert.NoError(t, notifications.Validate())
+	assert.True(t, notifications.HasAnyEnabled())
+}
+
+func TestNotificationConfig_Secrets(t *testing.T) {
+	n := &NotificationConfig{
+		Enabled:   true,
+		Discord:   DiscordConfig{Enabled: true, WebhookURL: "https://discord.example/webhook", Routes: []DiscordRoute{{WebhookURL: "https://discord.example/route"}}},
+		Slack:     SlackConfig{Enabled: false, WebhookURL: "https://slack.example/webhook"},
+		PagerDuty: PagerDutyConfig{Enabled: true},
+	}
+
+	assert.Equal(t, map[string]string{
+		"notifications.discord.webhook_url":           "https://discord.example/webhook",
+		"notifications.discord.routes[0].webhook_url": "https://discord.example/route",
+	}, n.Secrets())
+
+	n.Enabled = false
+	assert.Empty(t, n.Secrets())
+}
+
+func TestNotificationConfig_ResolveSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"telegram":  "1234567890:telegram-bot-token\n",
+		"pagerduty": "routing-key",
+		"empty":     "\n",
+	})
+	t.Setenv("SOLANA_VALIDATOR_HA_TEST_ROUTING_KEY", "routing-key-from-env")
+
+	n := &NotificationConfig{
+		Enabled:  true,
+		Telegram: TelegramConfig{Enabled: true, BotTokenFile: filepath.Join(dir, "telegram")},
+		// environment variables are preferred to files
+		PagerDuty: PagerDutyConfig{Enabled: true, RoutingKeyEnv: "SOLANA_VALIDATOR_HA_TEST_ROUTING_KEY", RoutingKeyFile: filepath.Join(dir, "pagerduty")},
+		// secrets set directly are kept
+		Slack: SlackConfig{Enabled: true, WebhookURL: "https://slack.example/webhook", WebhookURLFile: filepath.Join(dir, "missing")},
+	}
+	assert.NoError(t, n.ResolveSecrets(context.Background()))
+	assert.Equal(t, "1234567890:telegram-bot-token", n.Telegram.BotToken)
+	assert.Equal(t, "routing-key-from-env", n.PagerDuty.RoutingKey)
+	assert.Equal(t, "https://slack.example/webhook", n.Slack.WebhookURL)
+
+	n = &NotificationConfig{Enabled: true, Discord: DiscordConfig{Enabled: true, WebhookURLFile: filepath.Join(dir, "missing")}}
+	assert.ErrorContains(t, n.ResolveSecrets(context.Background()), "notifications.discord: failed to read webhook_url_file")
+
+	n = &NotificationConfig{Enabled: true, Discord: DiscordConfig{Enabled: true, Routes: []DiscordRoute{{WebhookURLFile: filepath.Join(dir, "empty")}}}}
+	assert.EqualError(t, n.ResolveSecrets(context.Background()), "notifications.discord.routes[0]: webhook_url_file "+filepath.Join(dir, "empty")+" is empty")
+}
@@ -0,0 +1,318 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+)
+
+// Peer registries, named by the scheme of failover.peer_registry.url - etcd+https connects to etcd over TLS
+const (
+	peerRegistryEtcd   = "etcd"
+	peerRegistryS3     = "s3"
+	peerRegistryDNSSRV = "dns-srv"
+)
+
+// DNS lookups peers are discovered with from SRV records
+var (
+	lookupSRV  = net.DefaultResolver.LookupSRV
+	lookupHost = net.DefaultResolver.LookupHost
+)
+
+// PeerRegistry represents the configuration for discovering peers that register themselves, on top of
+// failover.peers, so adding a peer doesn't mean editing every other peer's config
+type PeerRegistry struct {
+	Enabled bool `koanf:"enabled"`
+	// URL is where peers register - etcd://<host:port>/<prefix>, s3://<bucket>/<prefix> or dns-srv://<name> for
+	// peers registered as SRV records by other means
+	URL string `koanf:"url"`
+	// TTLDuration is how long a registration lasts without being refreshed - the etcd lease TTL, or the age of the
+	// oldest S3 registration still discovered
+	TTLDuration time.Duration `koanf:"ttl_duration"`
+	// RefreshIntervalDuration is how often this validator refreshes its registration and looks for new peers
+	RefreshIntervalDuration time.Duration `koanf:"refresh_interval_duration"`
+}
+
+// SetDefaults sets default values for the peer registry configuration
+func (r *PeerRegistry) SetDefaults() {
+	if r.TTLDuration == 0 {
+		r.TTLDuration = time.Minute
+	}
+	if r.RefreshIntervalDuration == 0 {
+		r.RefreshIntervalDuration = 15 * time.Second
+	}
+}
+
+// Validate validates the peer registry configuration
+func (r *PeerRegistry) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if _, err := r.NewClient(); err != nil {
+		return err
+	}
+
+	if r.RefreshIntervalDuration <= 0 {
+		return fmt.Errorf("failover.peer_registry.refresh_interval_duration must be greater than zero")
+	}
+
+	// etcd leases are whole seconds, and registrations must be refreshed before they lapse
+	if r.TTLDuration < time.Second {
+		return fmt.Errorf("failover.peer_registry.ttl_duration must be at least 1s")
+	}
+	if r.RefreshIntervalDuration >= r.TTLDuration {
+		return fmt.Errorf("failover.peer_registry.refresh_interval_duration %s must be shorter than ttl_duration %s",
+			r.RefreshIntervalDuration, r.TTLDuration)
+	}
+
+	return nil
+}
+
+// peerRegistration is a peer as registered in etcd, or S3 object metadata
+type peerRegistration struct {
+	Name     string `json:"name"`
+	IP       string `json:"ip"`
+	Priority int    `json:"priority,string"`
+}
+
+// PeerRegistryClient registers this validator in failover.peer_registry and discovers the peers registered there
+type PeerRegistryClient struct {
+	registry PeerRegistry
+	store    string
+	// etcd is the etcd the registry is in, whose key is the prefix peers register under
+	etcd *remoteSource
+	// leaseID is the etcd lease this validator's registration lives as long as
+	leaseID int64
+	// bucket and prefix are the S3 objects peers register as
+	bucket string
+	prefix string
+	// srvName is the SRV record peers are registered as
+	srvName string
+}
+
+// NewClient returns a client for the peer registry
+func (r *PeerRegistry) NewClient() (*PeerRegistryClient, error) {
+	parsed, err := url.Parse(r.URL)
+	if err != nil || r.URL == "" {
+		return nil, fmt.Errorf("failover.peer_registry.url must be a valid URL")
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("failover.peer_registry.url %s must have a host", r.URL)
+	}
+
+	client := &PeerRegistryClient{registry: *r}
+	prefix := strings.TrimPrefix(parsed.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	switch parsed.Scheme {
+	case peerRegistryEtcd, peerRegistryEtcd + "+https":
+		if prefix == "" {
+			return nil, fmt.Errorf("failover.peer_registry.url %s must have a key prefix path", r.URL)
+		}
+		client.store = peerRegistryEtcd
+		client.etcd, err = parseRemoteSource(parsed.Scheme + "://" + parsed.Host + "/" + prefix)
+		if err != nil {
+			return nil, err
+		}
+	case peerRegistryS3:
+		client.store = peerRegistryS3
+		client.bucket = parsed.Host
+		client.prefix = prefix
+	case peerRegistryDNSSRV:
+		client.store = peerRegistryDNSSRV
+		client.srvName = parsed.Host
+	default:
+		return nil, fmt.Errorf("failover.peer_registry.url scheme must be one of etcd, etcd+https, s3 or dns-srv, got %s", parsed.Scheme)
+	}
+
+	return client, nil
+}
+
+// Register registers self in the registry, or refreshes its registration so it doesn't lapse. DNS SRV records
+// are registered by other means, so this does nothing for them.
+func (c *PeerRegistryClient) Register(ctx context.Context, self Peer) error {
+	switch c.store {
+	case peerRegistryEtcd:
+		return c.registerEtcd(ctx, self)
+	case peerRegistryS3:
+		return c.registerS3(ctx, self)
+	}
+	return nil
+}
+
+// Discover returns the peers registered in the registry, including this validator if it has registered
+func (c *PeerRegistryClient) Discover(ctx context.Context) (Peers, error) {
+	var registrations []peerRegistration
+	var err error
+	switch c.store {
+	case peerRegistryEtcd:
+		registrations, err = c.discoverEtcd(ctx)
+	case peerRegistryS3:
+		registrations, err = c.discoverS3(ctx)
+	case peerRegistryDNSSRV:
+		registrations, err = c.discoverDNSSRV(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	peers := Peers{}
+	for _, registration := range registrations {
+		ip, err := NormalizeIP(registration.IP)
+		if err != nil || registration.Name == "" || registration.Priority < 0 {
+			return nil, fmt.Errorf("invalid peer registration %+v", registration)
+		}
+		peers.Add(Peer{Name: registration.Name, IP: ip, Priority: registration.Priority})
+	}
+	return peers, nil
+}
+
+// registerEtcd keeps this validator's etcd lease alive, putting its registration under a new lease once the
+// previous one has lapsed
+func (c *PeerRegistryClient) registerEtcd(ctx context.Context, self Peer) error {
+	if c.leaseID != 0 {
+		var keepAlive struct {
+			Result struct {
+				TTL int64 `json:"TTL,string"`
+			} `json:"result"`
+		}
+		err := c.etcd.postEtcd(ctx, "/v3/lease/keepalive", map[string]string{"ID": strconv.FormatInt(c.leaseID, 10)}, &keepAlive)
+		if err == nil && keepAlive.Result.TTL > 0 {
+			return nil
+		}
+	}
+
+	var lease struct {
+		ID int64 `json:"ID,string"`
+	}
+	ttl := strconv.FormatInt(int64(c.registry.TTLDuration/time.Second), 10)
+	if err := c.etcd.postEtcd(ctx, "/v3/lease/grant", map[string]string{"TTL": ttl}, &lease); err != nil {
+		return fmt.Errorf("failed to grant etcd lease: %w", err)
+	}
+	if lease.ID == 0 {
+		return fmt.Errorf("failed to grant etcd lease: no lease ID returned")
+	}
+
+	value, err := json.Marshal(peerRegistration{Name: self.Name, IP: self.IP, Priority: self.Priority})
+	if err != nil {
+		return err
+	}
+	put := map[string]any{
+		"key":   []byte(c.etcd.key + self.Name),
+		"value": value,
+		"lease": strconv.FormatInt(lease.ID, 10),
+	}
+	if err := c.etcd.postEtcd(ctx, "/v3/kv/put", put, &struct{}{}); err != nil {
+		return fmt.Errorf("failed to put etcd key %s: %w", c.etcd.key+self.Name, err)
+	}
+
+	c.leaseID = lease.ID
+	return nil
+}
+
+// discoverEtcd returns the registrations under the etcd key prefix
+func (c *PeerRegistryClient) discoverEtcd(ctx context.Context) ([]peerRegistration, error) {
+	// the range end of a prefix is the prefix with its last byte incremented
+	rangeEnd := []byte(c.etcd.key)
+	rangeEnd[len(rangeEnd)-1]++
+
+	var response struct {
+		KVs []etcdKeyValue `json:"kvs"`
+	}
+	if err := c.etcd.postEtcd(ctx, "/v3/kv/range", map[string]any{"key": []byte(c.etcd.key), "range_end": rangeEnd}, &response); err != nil {
+		return nil, err
+	}
+
+	registrations := []peerRegistration{}
+	for _, kv := range response.KVs {
+		var registration peerRegistration
+		if err := json.Unmarshal(kv.Value, &registration); err != nil {
+			return nil, fmt.Errorf("invalid peer registration %q: %w", kv.Value, err)
+		}
+		registrations = append(registrations, registration)
+	}
+	return registrations, nil
+}
+
+// registerS3 writes this validator's registration as an empty S3 object carrying it in its metadata - writing it
+// again refreshes its last modified time
+func (c *PeerRegistryClient) registerS3(ctx context.Context, self Peer) error {
+	metadata, err := json.Marshal(peerRegistration{Name: self.Name, IP: self.IP, Priority: self.Priority})
+	if err != nil {
+		return err
+	}
+	return c.runAWS(ctx, &struct{}{}, "s3api", "put-object", "--bucket", c.bucket, "--key", c.prefix+self.Name,
+		"--metadata", string(metadata))
+}
+
+// discoverS3 returns the registrations under the S3 prefix modified within the TTL
+func (c *PeerRegistryClient) discoverS3(ctx context.Context) ([]peerRegistration, error) {
+	var objects struct {
+		Contents []struct {
+			Key          string
+			LastModified time.Time
+		}
+	}
+	if err := c.runAWS(ctx, &objects, "s3api", "list-objects-v2", "--bucket", c.bucket, "--prefix", c.prefix); err != nil {
+		return nil, err
+	}
+
+	registrations := []peerRegistration{}
+	for _, object := range objects.Contents {
+		if time.Since(object.LastModified) > c.registry.TTLDuration {
+			continue
+		}
+
+		var head struct {
+			Metadata peerRegistration
+		}
+		if err := c.runAWS(ctx, &head, "s3api", "head-object", "--bucket", c.bucket, "--key", object.Key); err != nil {
+			return nil, err
+		}
+		registrations = append(registrations, head.Metadata)
+	}
+	return registrations, nil
+}
+
+// runAWS runs the AWS CLI, unmarshalling its JSON output into out
+func (c *PeerRegistryClient) runAWS(ctx context.Context, out any, args ...string) error {
+	return command.RunJSON(ctx, command.RunOptions{
+		Name:         "aws",
+		Command:      awsCLI,
+		Args:         append(args, "--output", "json"),
+		LoggerPrefix: "peer_registry",
+		Timeout:      awsCLITimeout,
+	}, out)
+}
+
+// discoverDNSSRV returns a registration for each target of the SRV record, named by its host name
+func (c *PeerRegistryClient) discoverDNSSRV(ctx context.Context) ([]peerRegistration, error) {
+	_, records, err := lookupSRV(ctx, "", "", c.srvName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up SRV record %s: %w", c.srvName, err)
+	}
+
+	registrations := []peerRegistration{}
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		addresses, err := lookupHost(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up SRV target %s: %w", host, err)
+		}
+		if len(addresses) == 0 {
+			return nil, fmt.Errorf("SRV target %s has no addresses", host)
+		}
+		registrations = append(registrations, peerRegistration{Name: host, IP: addresses[0]})
+	}
+	return registrations, nil
+}
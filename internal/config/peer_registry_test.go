@@ -0,0 +1,235 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEtcdRegistry serves the etcd JSON API calls peers register with - leases, puts and prefix ranges
+type fakeEtcdRegistry struct {
+	mu     sync.Mutex
+	kvs    map[string][]byte
+	leases map[string]string
+	alive  map[string]bool
+	nextID int
+}
+
+func newFakeEtcdRegistry(t *testing.T) (*fakeEtcdRegistry, *httptest.Server) {
+	etcd := &fakeEtcdRegistry{kvs: map[string][]byte{}, leases: map[string]string{}, alive: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/lease/grant", func(w http.ResponseWriter, r *http.Request) {
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+		etcd.nextID++
+		id := strings.Repeat("7", etcd.nextID)
+		etcd.alive[id] = true
+		json.NewEncoder(w).Encode(map[string]string{"ID": id, "TTL": "60"})
+	})
+	mux.HandleFunc("POST /v3/lease/keepalive", func(w http.ResponseWriter, r *http.Request) {
+		var request struct{ ID string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+		ttl := "0"
+		if etcd.alive[request.ID] {
+			ttl = "60"
+		}
+		json.NewEncoder(w).Encode(map[string]any{"result": map[string]string{"ID": request.ID, "TTL": ttl}})
+	})
+	mux.HandleFunc("POST /v3/kv/put", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Key   []byte
+			Value []byte
+			Lease string
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+		etcd.kvs[string(request.Key)] = request.Value
+		etcd.leases[string(request.Key)] = request.Lease
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("POST /v3/kv/range", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Key      []byte `json:"key"`
+			RangeEnd []byte `json:"range_end"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		etcd.mu.Lock()
+		defer etcd.mu.Unlock()
+		kvs := []map[string][]byte{}
+		for key, value := range etcd.kvs {
+			if key >= string(request.Key) && key < string(request.RangeEnd) && etcd.alive[etcd.leases[key]] {
+				kvs = append(kvs, map[string][]byte{"key": []byte(key), "value": value})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"kvs": kvs})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return etcd, server
+}
+
+// expire lapses every lease
+func (e *fakeEtcdRegistry) expire() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.alive = map[string]bool{}
+}
+
+func TestPeerRegistry_Validate(t *testing.T) {
+	registry := &PeerRegistry{}
+	assert.NoError(t, registry.Validate())
+
+	registry = &PeerRegistry{Enabled: true, URL: "etcd://127.0.0.1:2379/solana-validator-ha/peers"}
+	registry.SetDefaults()
+	assert.Equal(t, time.Minute, registry.TTLDuration)
+	assert.Equal(t, 15*time.Second, registry.RefreshIntervalDuration)
+	assert.NoError(t, registry.Validate())
+
+	for _, url := range []string{"s3://my-bucket/peers", "s3://my-bucket", "dns-srv://_solana-ha._tcp.example.com", "etcd+https://etcd.example.com/peers/"} {
+		registry.URL = url
+		assert.NoError(t, registry.Validate(), url)
+	}
+
+	registry.URL = ""
+	assert.ErrorContains(t, registry.Validate(), "failover.peer_registry.url must be a valid URL")
+	registry.URL = "etcd://127.0.0.1:2379"
+	assert.ErrorContains(t, registry.Validate(), "must have a key prefix path")
+	registry.URL = "zookeeper://127.0.0.1/peers"
+	assert.ErrorContains(t, registry.Validate(), "scheme must be one of etcd, etcd+https, s3 or dns-srv, got zookeeper")
+	registry.URL = "s3:///peers"
+	assert.ErrorContains(t, registry.Validate(), "must have a host")
+
+	registry.URL = "s3://my-bucket/peers"
+	registry.RefreshIntervalDuration = time.Minute
+	assert.ErrorContains(t, registry.Validate(), "failover.peer_registry.refresh_interval_duration 1m0s must be shorter than ttl_duration 1m0s")
+	registry.TTLDuration = 500 * time.Millisecond
+	assert.ErrorContains(t, registry.Validate(), "failover.peer_registry.ttl_duration must be at least 1s")
+}
+
+func TestPeerRegistryClient_Etcd(t *testing.T) {
+	etcd, server := newFakeEtcdRegistry(t)
+	registry := PeerRegistry{Enabled: true, URL: "etcd://" + strings.TrimPrefix(server.URL, "http://") + "/ha/peers"}
+	registry.SetDefaults()
+
+	self, err := registry.NewClient()
+	require.NoError(t, err)
+	other, err := registry.NewClient()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, self.Register(ctx, Peer{Name: "validator-1", IP: "192.168.1.10", Priority: 10}))
+	require.NoError(t, other.Register(ctx, Peer{Name: "validator-2", IP: "2001:db8::2"}))
+	leaseID := self.leaseID
+
+	peers, err := self.Discover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Peers{
+		"validator-1": {Name: "validator-1", IP: "192.168.1.10", Priority: 10},
+		"validator-2": {Name: "validator-2", IP: "2001:db8::2"},
+	}, peers)
+
+	// a live lease is kept alive
+	require.NoError(t, self.Register(ctx, Peer{Name: "validator-1", IP: "192.168.1.10", Priority: 10}))
+	assert.Equal(t, leaseID, self.leaseID)
+
+	// a lapsed lease is replaced and the registration put again
+	etcd.expire()
+	peers, err = self.Discover(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+	require.NoError(t, self.Register(ctx, Peer{Name: "validator-1", IP: "192.168.1.10", Priority: 10}))
+	assert.NotEqual(t, leaseID, self.leaseID)
+	peers, err = self.Discover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Peers{"validator-1": {Name: "validator-1", IP: "192.168.1.10", Priority: 10}}, peers)
+}
+
+func TestPeerRegistryClient_S3(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := filepath.Join(dir, "aws")
+	recent := time.Now().UTC().Format(time.RFC3339)
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo "$@" >> `+calls+`
+case "$2 $6" in
+  "put-object"*) echo '{"ETag": "\"abc\""}' ;;
+  "list-objects-v2"*) echo '{"Contents": [{"Key": "peers/validator-2", "LastModified": "`+recent+`"}, {"Key": "peers/validator-3", "LastModified": "2020-01-01T00:00:00+00:00"}]}' ;;
+  "head-object peers/validator-2") echo '{"Metadata": {"name": "validator-2", "ip": "192.168.1.11", "priority": "5"}}' ;;
+esac
+`), 0o755))
+	previous := awsCLI
+	awsCLI = script
+	t.Cleanup(func() { awsCLI = previous })
+
+	registry := PeerRegistry{Enabled: true, URL: "s3://ha-bucket/peers"}
+	registry.SetDefaults()
+	client, err := registry.NewClient()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, client.Register(ctx, Peer{Name: "validator-1", IP: "192.168.1.10"}))
+
+	// stale registrations are not discovered
+	peers, err := client.Discover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Peers{"validator-2": {Name: "validator-2", IP: "192.168.1.11", Priority: 5}}, peers)
+
+	recorded, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`s3api put-object --bucket ha-bucket --key peers/validator-1 --metadata {"name":"validator-1","ip":"192.168.1.10","priority":"0"} --output json`,
+		"s3api list-objects-v2 --bucket ha-bucket --prefix peers/ --output json",
+		"s3api head-object --bucket ha-bucket --key peers/validator-2 --output json",
+	}, strings.Split(strings.TrimSpace(string(recorded)), "\n"))
+}
+
+func TestPeerRegistryClient_DNSSRV(t *testing.T) {
+	previousSRV, previousHost := lookupSRV, lookupHost
+	t.Cleanup(func() { lookupSRV, lookupHost = previousSRV, previousHost })
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		assert.Equal(t, "_solana-ha._tcp.example.com", name)
+		return name, []*net.SRV{{Target: "validator-1.example.com.", Port: 8001}, {Target: "validator-2.example.com.", Port: 8001}}, nil
+	}
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		if host == "validator-1.example.com" {
+			return []string{"192.168.1.10"}, nil
+		}
+		return []string{"2001:0db8::2"}, nil
+	}
+
+	registry := PeerRegistry{Enabled: true, URL: "dns-srv://_solana-ha._tcp.example.com"}
+	client, err := registry.NewClient()
+	require.NoError(t, err)
+
+	// SRV records are registered by other means
+	require.NoError(t, client.Register(context.Background(), Peer{Name: "validator-1", IP: "192.168.1.10"}))
+
+	peers, err := client.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Peers{
+		"validator-1.example.com": {Name: "validator-1.example.com", IP: "192.168.1.10"},
+		"validator-2.example.com": {Name: "validator-2.example.com", IP: "2001:db8::2"},
+	}, peers)
+
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+	_, err = client.Discover(context.Background())
+	assert.ErrorContains(t, err, "failed to look up SRV target validator-1.example.com: no such host")
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"cmp"
 	"fmt"
-	"sort"
+	"net"
+	"slices"
 	"strings"
 )
 
@@ -13,6 +15,8 @@ type Peers map[string]Peer
 type Peer struct {
 	IP   string `koanf:"ip"`
 	Name string `koanf:"-"`
+	// Priority ranks the peer in takeover races - the highest priority wins, ties are broken by IP
+	Priority int `koanf:"priority"`
 }
 
 // Add adds a peer to the peers map
@@ -39,27 +43,51 @@ func (p *Peers) String() string {
 	return fmt.Sprintf("[%s]", strings.Join(peerStrings, " "))
 }
 
-// GetIPs returns the IP addresses of the peers
+// GetIPs returns the IP addresses of the peers, sorted
 func (p *Peers) GetIPs() []string {
 	ips := []string{}
 	for _, peer := range *p {
 		ips = append(ips, peer.IP)
 	}
+	slices.Sort(ips)
 	return ips
 }
 
-// GetRankedIPs returns the IP addresses in ascending order
-// this is arbitrary but used to impose some portable guaranteed
-// rank among peers without sharing any other configuration
+// GetRankedIPs returns the IP addresses ranked from 1 by descending priority, then ascending IP among peers
+// with the same priority. With no priorities set this is arbitrary but imposes some portable guaranteed
+// rank among peers without sharing any other configuration.
 func (p *Peers) GetRankedIPs() (rankedIPs map[string]int) {
 	rankedIPs = make(map[string]int)
-	ips := p.GetIPs()
-	sort.Strings(ips)
+	peers := []Peer{}
+	for _, peer := range *p {
+		peers = append(peers, peer)
+	}
+	slices.SortFunc(peers, func(a, b Peer) int {
+		return cmp.Or(cmp.Compare(b.Priority, a.Priority), cmp.Compare(a.IP, b.IP))
+	})
 
-	// ips are sorted in ascending order now
-	for ipIndex, ip := range ips {
-		rankedIPs[ip] = ipIndex + 1
+	// peers are sorted best first now
+	for peerIndex, peer := range peers {
+		rankedIPs[peer.IP] = peerIndex + 1
 	}
 
 	return rankedIPs
 }
+
+// NormalizeIP returns the canonical form of an IPv4 or IPv6 address, which may be bracketed and may carry a port,
+// e.g. 192.168.1.10:8001, 2001:db8::1 or [2001:db8::1]:8001, so the same address always compares equal
+func NormalizeIP(address string) (string, error) {
+	host := strings.TrimSpace(address)
+	if splitHost, _, err := net.SplitHostPort(host); err == nil {
+		host = splitHost
+	}
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", fmt.Errorf("invalid IP address %s", address)
+	}
+	return ip.String(), nil
+}
@@ -61,3 +61,44 @@ func TestPeers_GetIPs(t *testing.T) {
 	ips = emptyPeers.GetIPs()
 	assert.Len(t, ips, 0)
 }
+
+func TestPeers_GetRankedIPs(t *testing.T) {
+	peers := &Peers{
+		"validator-1": {Name: "validator-1", IP: "192.168.1.12"},
+		"validator-2": {Name: "validator-2", IP: "192.168.1.10"},
+		"validator-3": {Name: "validator-3", IP: "192.168.1.11"},
+	}
+
+	// without priorities peers are ranked by IP
+	assert.Equal(t, map[string]int{"192.168.1.10": 1, "192.168.1.11": 2, "192.168.1.12": 3}, peers.GetRankedIPs())
+
+	// the highest priority wins regardless of IP, ties are broken by IP
+	(*peers)["validator-1"] = Peer{Name: "validator-1", IP: "192.168.1.12", Priority: 100}
+	(*peers)["validator-3"] = Peer{Name: "validator-3", IP: "192.168.1.11", Priority: 100}
+	assert.Equal(t, map[string]int{"192.168.1.11": 1, "192.168.1.12": 2, "192.168.1.10": 3}, peers.GetRankedIPs())
+
+	assert.Empty(t, (&Peers{}).GetRankedIPs())
+}
+
+func TestNormalizeIP(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.10":              "192.168.1.10",
+		" 192.168.1.10 ":            "192.168.1.10",
+		"192.168.1.10:8001":         "192.168.1.10",
+		"2001:db8::1":               "2001:db8::1",
+		"2001:0db8:0000::0001":      "2001:db8::1",
+		"[2001:db8::1]":             "2001:db8::1",
+		"[2001:db8::1]:8001":        "2001:db8::1",
+		"[2001:DB8:0:0:0:0:0:1]:80": "2001:db8::1",
+	}
+	for address, expected := range tests {
+		ip, err := NormalizeIP(address)
+		assert.NoError(t, err, address)
+		assert.Equal(t, expected, ip, address)
+	}
+
+	for _, address := range []string{"", "invalid-ip", "192.168.1", "[192.168.1.10", "host.example.com:8001"} {
+		_, err := NormalizeIP(address)
+		assert.Error(t, err, address)
+	}
+}
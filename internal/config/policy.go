@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// FailoverPolicies is an ordered list of policy windows - the first matching window applies
+type FailoverPolicies []FailoverPolicy
+
+// FailoverPolicy overrides failover behaviour during a time window
+type FailoverPolicy struct {
+	Name string `koanf:"name"`
+	// Days the window starts on (sun, mon, tue, wed, thu, fri, sat) - empty means every day
+	Days []string `koanf:"days"`
+	// Start and End are HH:MM times - a window where end is before start spans midnight
+	Start string `koanf:"start"`
+	End   string `koanf:"end"`
+	// Timezone is an IANA timezone name the window times are evaluated in
+	Timezone string `koanf:"timezone"`
+	// LeaderlessSamplesThreshold overrides failover.leaderless_samples_threshold when non-zero
+	LeaderlessSamplesThreshold int `koanf:"leaderless_samples_threshold"`
+	// AutoTakeover disables automatic takeover during the window when false, requiring manual intervention
+	AutoTakeover *bool `koanf:"auto_takeover"`
+}
+
+// SetDefaults sets default values for the failover policies
+func (p FailoverPolicies) SetDefaults() {
+	for i := range p {
+		if p[i].Timezone == "" {
+			p[i].Timezone = "UTC"
+		}
+	}
+}
+
+// Validate validates the failover policies
+func (p FailoverPolicies) Validate() error {
+	names := make(map[string]bool)
+	for i, policy := range p {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("failover.policies[%d]: %w", i, err)
+		}
+		if names[policy.Name] {
+			return fmt.Errorf("failover.policies[%d]: duplicate name %s", i, policy.Name)
+		}
+		names[policy.Name] = true
+	}
+	return nil
+}
+
+// Active returns the first policy whose window contains now, or nil if none match
+func (p FailoverPolicies) Active(now time.Time) *FailoverPolicy {
+	for i := range p {
+		if p[i].Contains(now) {
+			return &p[i]
+		}
+	}
+	return nil
+}
+
+// Validate validates the failover policy
+func (p *FailoverPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name must be defined")
+	}
+
+	window := p.window()
+	if err := window.Validate(); err != nil {
+		return err
+	}
+
+	if p.LeaderlessSamplesThreshold < 0 {
+		return fmt.Errorf("leaderless_samples_threshold must be positive")
+	}
+
+	return nil
+}
+
+// Contains returns true if now falls within the policy window
+func (p *FailoverPolicy) Contains(now time.Time) bool {
+	window := p.window()
+	return window.Contains(now)
+}
+
+// AutoTakeoverEnabled returns whether automatic takeover is allowed during the window - defaults to true
+func (p *FailoverPolicy) AutoTakeoverEnabled() bool {
+	return p.AutoTakeover == nil || *p.AutoTakeover
+}
+
+// window returns the policy's time window
+func (p *FailoverPolicy) window() TimeWindow {
+	return TimeWindow{
+		Days:     p.Days,
+		Start:    p.Start,
+		End:      p.End,
+		Timezone: p.Timezone,
+	}
+}
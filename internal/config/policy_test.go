@@ -0,0 +1,103 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverPolicy_Validate(t *testing.T) {
+	policy := FailoverPolicy{
+		Name:     "business-hours",
+		Days:     []string{"mon", "tue", "wed", "thu", "fri"},
+		Start:    "09:00",
+		End:      "17:00",
+		Timezone: "UTC",
+	}
+	assert.NoError(t, policy.Validate())
+
+	// Test with invalid day
+	policy.Days = []string{"funday"}
+	err := policy.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid day")
+
+	// Test with invalid start
+	policy.Days = nil
+	policy.Start = "9am"
+	err = policy.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "start must be a HH:MM time")
+
+	// Test with invalid timezone
+	policy.Start = "09:00"
+	policy.Timezone = "Mars/Olympus_Mons"
+	err = policy.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid timezone")
+
+	// Test duplicate names
+	policy.Timezone = "UTC"
+	policies := FailoverPolicies{policy, policy}
+	err = policies.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.policies[1]: duplicate name business-hours")
+}
+
+func TestFailoverPolicy_Contains(t *testing.T) {
+	businessHours := FailoverPolicy{
+		Name:     "business-hours",
+		Days:     []string{"mon", "tue", "wed", "thu", "fri"},
+		Start:    "09:00",
+		End:      "17:00",
+		Timezone: "UTC",
+	}
+
+	// 2024-01-01 is a Monday
+	assert.True(t, businessHours.Contains(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, businessHours.Contains(time.Date(2024, 1, 1, 16, 59, 0, 0, time.UTC)))
+	assert.False(t, businessHours.Contains(time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC)))
+	assert.False(t, businessHours.Contains(time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC)))
+
+	overnight := FailoverPolicy{
+		Name:     "overnight",
+		Days:     []string{"fri"},
+		Start:    "22:00",
+		End:      "06:00",
+		Timezone: "UTC",
+	}
+
+	// friday night and the following saturday morning are in the window
+	assert.True(t, overnight.Contains(time.Date(2024, 1, 5, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, overnight.Contains(time.Date(2024, 1, 6, 5, 59, 0, 0, time.UTC)))
+	// friday morning belongs to thursday's window
+	assert.False(t, overnight.Contains(time.Date(2024, 1, 5, 5, 0, 0, 0, time.UTC)))
+	assert.False(t, overnight.Contains(time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestFailover_LeaderlessSamplesThresholdAt(t *testing.T) {
+	autoTakeover := false
+	failover := &Failover{
+		LeaderlessSamplesThreshold: 3,
+		Policies: FailoverPolicies{
+			{Name: "business-hours", Start: "09:00", End: "17:00", LeaderlessSamplesThreshold: 10, AutoTakeover: &autoTakeover},
+			{Name: "evening", Start: "17:00", End: "22:00"},
+		},
+	}
+	failover.SetDefaults()
+
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 10, failover.LeaderlessSamplesThresholdAt(day))
+	assert.False(t, failover.Policies.Active(day).AutoTakeoverEnabled())
+
+	// policy without override falls back to the failover threshold
+	evening := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3, failover.LeaderlessSamplesThresholdAt(evening))
+	assert.True(t, failover.Policies.Active(evening).AutoTakeoverEnabled())
+
+	// no policy matches
+	night := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3, failover.LeaderlessSamplesThresholdAt(night))
+	assert.Nil(t, failover.Policies.Active(night))
+}
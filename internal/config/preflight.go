@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// preflightCommand is a configured command checked at startup
+type preflightCommand struct {
+	path       string
+	command    string
+	workingDir string
+}
+
+// PreflightCommands checks every local command the failover may run - role commands, hooks and
+// snapshot_recovery.command and fence agents - is on PATH or an executable file, so a typo or missing script fails at startup
+// rather than during a live failover. Run it once commands are rendered. Hooks with a host run remotely and shell
+// mode commands are interpreted by the shell, so neither is checked.
+func (f *Failover) PreflightCommands() error {
+	commands := []preflightCommand{}
+	addRole := func(path string, role *Role) {
+		addHooks := func(hookType string, hooks []Hook) {
+			for i, hook := range hooks {
+				if hook.Host != "" || hook.Shell {
+					continue
+				}
+				commands = append(commands, preflightCommand{fmt.Sprintf("%s.hooks.%s[%d].command", path, hookType, i), hook.Command, hook.WorkingDir})
+			}
+		}
+
+		addHooks("pre", role.Hooks.Pre)
+		if !role.Shell {
+			commands = append(commands, preflightCommand{path + ".command", role.Command, role.WorkingDir})
+		}
+		addHooks("post", role.Hooks.Post)
+	}
+	addRole("failover.active", &f.Active)
+	addRole("failover.passive", &f.Passive)
+	if f.SnapshotRecovery.Enabled {
+		commands = append(commands, preflightCommand{"failover.snapshot_recovery.command", f.SnapshotRecovery.Command, ""})
+	}
+	fenceCommands := f.Fencing.commands()
+	for _, path := range slices.Sorted(maps.Keys(fenceCommands)) {
+		if !fenceCommands[path].Shell {
+			commands = append(commands, preflightCommand{path, fenceCommands[path].Command, ""})
+		}
+	}
+
+	for _, c := range commands {
+		if err := lookCommand(c.command, c.workingDir); err != nil {
+			return fmt.Errorf("%s: %w - set failover.skip_preflight to skip this check", c.path, err)
+		}
+	}
+	return nil
+}
+
+// lookCommand returns an error unless command is found on PATH or, if it is a path, is an executable file -
+// relative to workingDir when set, as it is run
+func lookCommand(command string, workingDir string) error {
+	if !strings.Contains(command, "/") {
+		if _, err := exec.LookPath(command); err != nil {
+			return fmt.Errorf("%s not found on PATH", command)
+		}
+		return nil
+	}
+
+	path := command
+	if !filepath.IsAbs(path) && workingDir != "" {
+		path = filepath.Join(workingDir, path)
+	}
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("%s does not exist", path)
+	case err != nil:
+		return err
+	case info.IsDir():
+		return fmt.Errorf("%s is a directory", path)
+	case info.Mode().Perm()&0o111 == 0:
+		return fmt.Errorf("%s is not executable", path)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover_PreflightCommands(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "set-role.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "not-executable.sh"), []byte("#!/bin/sh\n"), 0o644))
+
+	failover := func() *Failover {
+		return &Failover{
+			Active: Role{
+				Command: script,
+				Hooks: Hooks{
+					Pre: []Hook{
+						{Name: "on-path", Command: "sh"},
+						// remote and shell hooks are not checked
+						{Name: "remote", Command: "/opt/fence.sh", Host: "validator-2"},
+						{Name: "shell", Command: "missing-command | tee /tmp/out", Shell: true},
+					},
+				},
+			},
+			// relative to the working dir
+			Passive: Role{Command: "./set-role.sh", WorkingDir: dir},
+		}
+	}
+	assert.NoError(t, failover().PreflightCommands())
+
+	tests := map[string]struct {
+		modify   func(f *Failover)
+		expected string
+	}{
+		"not on path": {
+			modify:   func(f *Failover) { f.Active.Command = "solana-validator-ha-test-missing" },
+			expected: "failover.active.command: solana-validator-ha-test-missing not found on PATH - set failover.skip_preflight to skip this check",
+		},
+		"missing file": {
+			modify: func(f *Failover) {
+				f.Passive.Hooks.Post = []Hook{{Name: "missing", Command: filepath.Join(dir, "missing.sh")}}
+			},
+			expected: "failover.passive.hooks.post[0].command: " + filepath.Join(dir, "missing.sh") + " does not exist",
+		},
+		"not executable": {
+			modify:   func(f *Failover) { f.Passive.Command = "./not-executable.sh" },
+			expected: "failover.passive.command: " + filepath.Join(dir, "not-executable.sh") + " is not executable",
+		},
+		"directory": {
+			modify:   func(f *Failover) { f.Active.Command = dir },
+			expected: "failover.active.command: " + dir + " is a directory",
+		},
+		"snapshot recovery": {
+			modify: func(f *Failover) {
+				f.SnapshotRecovery = SnapshotRecovery{Enabled: true, Command: "/opt/missing/fetch-snapshot.sh"}
+			},
+			expected: "failover.snapshot_recovery.command: /opt/missing/fetch-snapshot.sh does not exist",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := failover()
+			test.modify(f)
+			assert.ErrorContains(t, f.PreflightCommands(), test.expected)
+		})
+	}
+}
+
+func TestCheck_PreflightCommands(t *testing.T) {
+	configFile := createTempConfigFile(t)
+	defer os.Remove(configFile)
+
+	content, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	content = []byte(strings.Replace(string(content), `command: "systemctl stop solana"`, `command: "systemctl stop solana"
+    hooks:
+      pre:
+        - name: missing
+          command: /opt/solana-validator-ha-test/missing.sh`, 1))
+	require.NoError(t, os.WriteFile(configFile, content, 0o600))
+
+	_, errs := Check(context.Background(), configFile, CheckOptions{})
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "failover.passive.hooks.pre[0].command: /opt/solana-validator-ha-test/missing.sh does not exist")
+
+	_, errs = Check(context.Background(), configFile, CheckOptions{SkipCommands: true})
+	assert.Empty(t, errs)
+}
@@ -1,12 +1,111 @@
 package config
 
-import "fmt"
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPrometheusNamespace is the prefix of metric names when prometheus.namespace is not set
+const DefaultPrometheusNamespace = "solana_validator_ha"
+
+// prometheusNamePattern matches valid Prometheus metric namespaces and label names
+var prometheusNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
 // Prometheus represents Prometheus metrics configuration
 type Prometheus struct {
-	Port            int               `koanf:"port"`
-	HealthCheckPort int               `koanf:"health_check_port"`
-	StaticLabels    map[string]string `koanf:"static_labels"`
+	Port            int `koanf:"port"`
+	HealthCheckPort int `koanf:"health_check_port"`
+	// BindAddress is the address the metrics server listens on - all interfaces when empty
+	BindAddress string `koanf:"bind_address"`
+	// HealthCheckBindAddress is the address the health check server listens on - all interfaces when empty
+	HealthCheckBindAddress string `koanf:"health_check_bind_address"`
+	// Namespace prefixes metric names, e.g. <namespace>_peer_count
+	Namespace    string            `koanf:"namespace"`
+	StaticLabels map[string]string `koanf:"static_labels"`
+	// StaticLabelsEnv are static labels whose values are read from environment variables, keyed by label name
+	StaticLabelsEnv map[string]string `koanf:"static_labels_env"`
+	// TLS serves metrics over HTTPS
+	TLS PrometheusTLS `koanf:"tls"`
+	// HealthCheckTLS serves the health check server with mutual TLS, and reaches peers' with it
+	HealthCheckTLS HealthCheckTLS `koanf:"health_check_tls"`
+	// Auth requires scrapes to authenticate with basic auth or a bearer token
+	Auth PrometheusAuth `koanf:"auth"`
+	// Textfile periodically writes metrics for the node_exporter textfile collector
+	Textfile PrometheusTextfile `koanf:"textfile"`
+}
+
+// PrometheusTLS represents the certificate the metrics server serves HTTPS with
+type PrometheusTLS struct {
+	// CertFile and KeyFile are the PEM server certificate and key
+	CertFile string `koanf:"cert_file"`
+	KeyFile  string `koanf:"key_file"`
+}
+
+// PrometheusAuth represents the credentials scrapes of the metrics server must present - either is accepted when
+// both are set
+type PrometheusAuth struct {
+	Username     string `koanf:"username"`
+	Password     string `koanf:"password"`
+	PasswordFile string `koanf:"password_file"`
+	// BearerToken is accepted in an Authorization: Bearer header
+	BearerToken     string `koanf:"bearer_token"`
+	BearerTokenFile string `koanf:"bearer_token_file"`
+}
+
+// HealthCheckAddress returns the address the health check server listens on
+func (p *Prometheus) HealthCheckAddress() string {
+	return net.JoinHostPort(p.HealthCheckBindAddress, strconv.Itoa(p.HealthCheckPort))
+}
+
+// HealthCheckURL returns the URL of path on the local health check server - on localhost unless it listens on a
+// single other address
+func (p *Prometheus) HealthCheckURL(path string) string {
+	host := "127.0.0.1"
+	if ip := net.ParseIP(p.HealthCheckBindAddress); ip != nil && !ip.IsUnspecified() {
+		host = ip.String()
+	} else if p.HealthCheckBindAddress == "localhost" {
+		host = p.HealthCheckBindAddress
+	}
+	return p.healthCheckScheme() + net.JoinHostPort(host, strconv.Itoa(p.HealthCheckPort)) + path
+}
+
+// PeerHealthCheckURL returns the URL of path on the health check server of the peer at ip
+func (p *Prometheus) PeerHealthCheckURL(ip string, path string) string {
+	return p.healthCheckScheme() + net.JoinHostPort(ip, strconv.Itoa(p.HealthCheckPort)) + path
+}
+
+// healthCheckScheme returns the scheme and separator of health check server URLs
+func (p *Prometheus) healthCheckScheme() string {
+	if p.HealthCheckTLS.Enabled {
+		return "https://"
+	}
+	return "http://"
+}
+
+// PrometheusTextfile represents the configuration for writing metrics in node_exporter textfile collector format
+type PrometheusTextfile struct {
+	Enabled bool `koanf:"enabled"`
+	// Dir is the node_exporter --collector.textfile.directory
+	Dir string `koanf:"dir"`
+	// FileName is the name of the metrics file written to dir - must end in .prom
+	FileName string `koanf:"file_name"`
+	// IntervalDuration is how often the metrics file is rewritten
+	IntervalDuration time.Duration `koanf:"interval_duration"`
+}
+
+// Path returns the path of the metrics file
+func (t *PrometheusTextfile) Path() string {
+	return filepath.Join(t.Dir, t.FileName)
 }
 
 // Validate validates the Prometheus configuration
@@ -21,7 +120,47 @@ func (p *Prometheus) Validate() error {
 		return fmt.Errorf("prometheus.health_check_port must be positive and non-zero")
 	}
 
-	return nil
+	// bind addresses must be IPs or localhost
+	for key, address := range map[string]string{
+		"prometheus.bind_address":              p.BindAddress,
+		"prometheus.health_check_bind_address": p.HealthCheckBindAddress,
+	} {
+		if address != "" && address != "localhost" && net.ParseIP(address) == nil {
+			return fmt.Errorf("%s must be an IP address or localhost - got: %s", key, address)
+		}
+	}
+
+	// prometheus.namespace and static label names must be valid Prometheus names
+	if p.Namespace != "" && !prometheusNamePattern.MatchString(p.Namespace) {
+		return fmt.Errorf("prometheus.namespace must match %s - got: %s", prometheusNamePattern, p.Namespace)
+	}
+	for labelName := range p.StaticLabels {
+		if !prometheusNamePattern.MatchString(labelName) {
+			return fmt.Errorf("prometheus.static_labels name %s must match %s", labelName, prometheusNamePattern)
+		}
+	}
+	for labelName, env := range p.StaticLabelsEnv {
+		if !prometheusNamePattern.MatchString(labelName) {
+			return fmt.Errorf("prometheus.static_labels_env name %s must match %s", labelName, prometheusNamePattern)
+		}
+		if env == "" {
+			return fmt.Errorf("prometheus.static_labels_env.%s must name an environment variable", labelName)
+		}
+	}
+
+	if err := p.TLS.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.HealthCheckTLS.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.Auth.Validate(); err != nil {
+		return err
+	}
+
+	return p.Textfile.Validate()
 }
 
 // SetDefaults sets default values for the Prometheus configuration
@@ -35,4 +174,156 @@ func (p *Prometheus) SetDefaults() {
 	if p.HealthCheckPort == 0 {
 		p.HealthCheckPort = 9091
 	}
+
+	if p.Namespace == "" {
+		p.Namespace = DefaultPrometheusNamespace
+	}
+
+	p.Textfile.SetDefaults()
+}
+
+// SetDefaults sets default values for the textfile configuration
+func (t *PrometheusTextfile) SetDefaults() {
+	if t.FileName == "" {
+		t.FileName = "solana_validator_ha.prom"
+	}
+	if t.IntervalDuration == 0 {
+		t.IntervalDuration = 15 * time.Second
+	}
+}
+
+// Validate validates the textfile configuration
+func (t *PrometheusTextfile) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	if t.Dir == "" {
+		return fmt.Errorf("prometheus.textfile.dir is required when enabled")
+	}
+
+	// node_exporter only reads *.prom files directly in its textfile directory
+	if filepath.Base(t.FileName) != t.FileName || !strings.HasSuffix(t.FileName, ".prom") {
+		return fmt.Errorf("prometheus.textfile.file_name must be a file name ending in .prom")
+	}
+
+	if t.IntervalDuration < 0 {
+		return fmt.Errorf("prometheus.textfile.interval_duration must be positive")
+	}
+
+	return nil
+}
+
+// ResolveStaticLabelsEnv adds the prometheus.static_labels_env labels to the static labels, read from their
+// environment variables. HOSTNAME falls back to the host name, as services are often started without it.
+func (p *Prometheus) ResolveStaticLabelsEnv() error {
+	for labelName, env := range p.StaticLabelsEnv {
+		value := os.Getenv(env)
+		if value == "" && env == "HOSTNAME" {
+			value, _ = os.Hostname()
+		}
+		if value == "" {
+			return fmt.Errorf("prometheus.static_labels_env.%s: environment variable %s is not set", labelName, env)
+		}
+
+		if existing, ok := p.StaticLabels[labelName]; ok && existing != value {
+			return fmt.Errorf("prometheus.static_labels_env.%s is also set in prometheus.static_labels", labelName)
+		}
+		if p.StaticLabels == nil {
+			p.StaticLabels = map[string]string{}
+		}
+		p.StaticLabels[labelName] = value
+	}
+	return nil
+}
+
+// IsSet returns true if the metrics server serves HTTPS
+func (t PrometheusTLS) IsSet() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// Validate validates the TLS options, loading the configured files
+func (t PrometheusTLS) Validate() error {
+	if !t.IsSet() {
+		return nil
+	}
+
+	if t.CertFile == "" || t.KeyFile == "" {
+		return fmt.Errorf("prometheus.tls.cert_file and key_file must be set together")
+	}
+
+	if _, err := t.ServerConfig(); err != nil {
+		return fmt.Errorf("prometheus.tls: %w", err)
+	}
+
+	return nil
+}
+
+// ServerConfig returns the TLS server config - nil when TLS is not set
+func (t PrometheusTLS) ServerConfig() (*tls.Config, error) {
+	if !t.IsSet() {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cert_file and key_file: %w", err)
+	}
+
+	return &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}}, nil
+}
+
+// IsSet returns true if scrapes must authenticate
+func (a PrometheusAuth) IsSet() bool {
+	return a.Username != "" || a.Password != "" || a.PasswordFile != "" || a.BearerToken != "" || a.BearerTokenFile != ""
+}
+
+// Validate validates the auth options
+func (a PrometheusAuth) Validate() error {
+	hasPassword := a.Password != "" || a.PasswordFile != ""
+	if (a.Username == "") != !hasPassword {
+		return fmt.Errorf("prometheus.auth.username and password or password_file must be set together")
+	}
+	return nil
+}
+
+// ResolveSecrets reads the password and bearer token from their files, or AWS Secrets Manager or SSM Parameter
+// Store references
+func (a *PrometheusAuth) ResolveSecrets(ctx context.Context) error {
+	secrets := []secretField{
+		{"prometheus.auth.password", true, &a.Password, "", a.PasswordFile},
+		{"prometheus.auth.bearer_token", true, &a.BearerToken, "", a.BearerTokenFile},
+	}
+	for _, secret := range secrets {
+		if err := secret.resolveSource(); err != nil {
+			return err
+		}
+		value, err := resolveSecretRef(ctx, *secret.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", secret.path, err)
+		}
+		*secret.value = value
+	}
+	return nil
+}
+
+// Authorized returns true if the request presents the configured basic auth or bearer token, or no auth is set
+func (a PrometheusAuth) Authorized(r *http.Request) bool {
+	if !a.IsSet() {
+		return true
+	}
+
+	if username, password, ok := r.BasicAuth(); ok && a.Username != "" {
+		usernameOK := subtle.ConstantTimeCompare([]byte(username), []byte(a.Username)) == 1
+		passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(a.Password)) == 1
+		if usernameOK && passwordOK {
+			return true
+		}
+	}
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && a.BearerToken != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(a.BearerToken)) == 1
+	}
+
+	return false
 }
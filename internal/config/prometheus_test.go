@@ -1,9 +1,15 @@
 package config
 
 import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPrometheus_SetDefaults(t *testing.T) {
@@ -12,6 +18,7 @@ func TestPrometheus_SetDefaults(t *testing.T) {
 
 	assert.Equal(t, 9090, prometheus.Port)
 	assert.Equal(t, 9091, prometheus.HealthCheckPort)
+	assert.Equal(t, "solana_validator_ha", prometheus.Namespace)
 }
 
 func TestPrometheus_Validate(t *testing.T) {
@@ -50,4 +57,183 @@ func TestPrometheus_Validate(t *testing.T) {
 	prometheus.HealthCheckPort = 9091
 	err = prometheus.Validate()
 	assert.NoError(t, err)
+
+	// Test with bind addresses
+	prometheus.BindAddress = "127.0.0.1"
+	prometheus.HealthCheckBindAddress = "localhost"
+	assert.NoError(t, prometheus.Validate())
+
+	prometheus.HealthCheckBindAddress = "0.0.0.0:9091"
+	err = prometheus.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.health_check_bind_address must be an IP address or localhost")
+}
+
+func TestPrometheus_Validate_Names(t *testing.T) {
+	prometheus := &Prometheus{Port: 9090, HealthCheckPort: 9091, Namespace: "ha"}
+	assert.NoError(t, prometheus.Validate())
+
+	prometheus.Namespace = "solana-validator-ha"
+	err := prometheus.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.namespace must match")
+
+	prometheus.Namespace = "ha"
+	prometheus.StaticLabels = map[string]string{"data-center": "fra1"}
+	err = prometheus.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.static_labels name data-center must match")
+
+	prometheus.StaticLabels = nil
+	prometheus.StaticLabelsEnv = map[string]string{"region": ""}
+	err = prometheus.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.static_labels_env.region must name an environment variable")
+
+	prometheus.StaticLabelsEnv = map[string]string{"region": "AWS_REGION"}
+	assert.NoError(t, prometheus.Validate())
+}
+
+func TestPrometheus_ResolveStaticLabelsEnv(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-central-1")
+	t.Setenv("HOSTNAME", "")
+
+	prometheus := &Prometheus{StaticLabelsEnv: map[string]string{"region": "AWS_REGION", "host": "HOSTNAME"}}
+	require.NoError(t, prometheus.ResolveStaticLabelsEnv())
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu-central-1", "host": hostname}, prometheus.StaticLabels)
+
+	// resolving again is a no-op
+	require.NoError(t, prometheus.ResolveStaticLabelsEnv())
+
+	prometheus = &Prometheus{StaticLabels: map[string]string{"region": "us-east-1"}, StaticLabelsEnv: map[string]string{"region": "AWS_REGION"}}
+	err = prometheus.ResolveStaticLabelsEnv()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.static_labels_env.region is also set in prometheus.static_labels")
+
+	prometheus = &Prometheus{StaticLabelsEnv: map[string]string{"rack": "SOLANA_VALIDATOR_HA_TEST_UNSET"}}
+	err = prometheus.ResolveStaticLabelsEnv()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.static_labels_env.rack: environment variable SOLANA_VALIDATOR_HA_TEST_UNSET is not set")
+}
+
+func TestPrometheus_HealthCheckURL(t *testing.T) {
+	prometheus := &Prometheus{HealthCheckPort: 9091}
+	assert.Equal(t, "http://127.0.0.1:9091/status", prometheus.HealthCheckURL("/status"))
+
+	prometheus.HealthCheckBindAddress = "::"
+	assert.Equal(t, "http://127.0.0.1:9091/status", prometheus.HealthCheckURL("/status"))
+
+	prometheus.HealthCheckBindAddress = "10.0.0.5"
+	assert.Equal(t, "http://10.0.0.5:9091/status", prometheus.HealthCheckURL("/status"))
+
+	prometheus.HealthCheckBindAddress = "::1"
+	assert.Equal(t, "http://[::1]:9091/status", prometheus.HealthCheckURL("/status"))
+}
+
+func TestPrometheusTLS_Validate(t *testing.T) {
+	// no TLS is valid
+	assert.NoError(t, PrometheusTLS{}.Validate())
+
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+	err := PrometheusTLS{CertFile: certFile}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.tls.cert_file and key_file must be set together")
+
+	err = PrometheusTLS{CertFile: certFile, KeyFile: certFile}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.tls: failed to load cert_file and key_file")
+
+	tlsConfig := PrometheusTLS{CertFile: certFile, KeyFile: keyFile}
+	assert.NoError(t, tlsConfig.Validate())
+	serverConfig, err := tlsConfig.ServerConfig()
+	require.NoError(t, err)
+	assert.Len(t, serverConfig.Certificates, 1)
+}
+
+func TestPrometheusAuth_Validate(t *testing.T) {
+	assert.NoError(t, PrometheusAuth{}.Validate())
+	assert.NoError(t, PrometheusAuth{BearerToken: "token"}.Validate())
+	assert.NoError(t, PrometheusAuth{Username: "prometheus", PasswordFile: "/etc/solana-validator-ha/password"}.Validate())
+
+	for _, auth := range []PrometheusAuth{{Username: "prometheus"}, {Password: "password"}} {
+		err := auth.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "prometheus.auth.username and password or password_file must be set together")
+	}
+}
+
+func TestPrometheusAuth_ResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\n"), 0o600))
+
+	auth := &PrometheusAuth{Username: "prometheus", PasswordFile: passwordFile, BearerToken: "token"}
+	require.NoError(t, auth.ResolveSecrets(context.Background()))
+	assert.Equal(t, "hunter2", auth.Password)
+	assert.Equal(t, "token", auth.BearerToken)
+
+	auth = &PrometheusAuth{BearerTokenFile: filepath.Join(dir, "missing")}
+	err := auth.ResolveSecrets(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.auth: failed to read bearer_token_file")
+}
+
+func TestPrometheusAuth_Authorized(t *testing.T) {
+	// no auth lets every request through
+	assert.True(t, PrometheusAuth{}.Authorized(httptest.NewRequest("GET", "/metrics", nil)))
+
+	auth := PrometheusAuth{Username: "prometheus", Password: "hunter2", BearerToken: "token"}
+	assert.False(t, auth.Authorized(httptest.NewRequest("GET", "/metrics", nil)))
+
+	r := httptest.NewRequest("GET", "/metrics", nil)
+	r.SetBasicAuth("prometheus", "hunter2")
+	assert.True(t, auth.Authorized(r))
+
+	r.SetBasicAuth("prometheus", "wrong")
+	assert.False(t, auth.Authorized(r))
+
+	r = httptest.NewRequest("GET", "/metrics", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	assert.True(t, auth.Authorized(r))
+
+	r.Header.Set("Authorization", "Bearer wrong")
+	assert.False(t, auth.Authorized(r))
+
+	// a bearer token alone doesn't accept basic auth
+	r = httptest.NewRequest("GET", "/metrics", nil)
+	r.SetBasicAuth("", "")
+	assert.False(t, PrometheusAuth{BearerToken: "token"}.Authorized(r))
+}
+
+func TestPrometheusTextfile_Validate(t *testing.T) {
+	textfile := &PrometheusTextfile{
+		Enabled: true,
+		Dir:     "/var/lib/node_exporter/textfile_collector",
+	}
+	textfile.SetDefaults()
+	assert.NoError(t, textfile.Validate())
+	assert.Equal(t, "solana_validator_ha.prom", textfile.FileName)
+	assert.Equal(t, 15*time.Second, textfile.IntervalDuration)
+	assert.Equal(t, "/var/lib/node_exporter/textfile_collector/solana_validator_ha.prom", textfile.Path())
+
+	// Test without dir
+	textfile.Dir = ""
+	err := textfile.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prometheus.textfile.dir is required when enabled")
+
+	// Test with a file name node_exporter would ignore
+	textfile.Dir = "/var/lib/node_exporter/textfile_collector"
+	for _, fileName := range []string{"metrics.txt", "sub/metrics.prom"} {
+		textfile.FileName = fileName
+		err = textfile.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "prometheus.textfile.file_name must be a file name ending in .prom")
+	}
+
+	// Test disabled skips validation
+	textfile.Enabled = false
+	assert.NoError(t, textfile.Validate())
 }
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"context"
+
+	"github.com/knadh/koanf/providers/file"
+)
+
+// Reload is the configuration for reloading the config file without restarting
+type Reload struct {
+	// Watch reloads the config file whenever it is written, or the remote key whenever it changes, as well as on SIGHUP
+	Watch bool `koanf:"watch"`
+}
+
+// Watch calls onChange with nil each time the config changes, until ctx is done. A file watch ends on the first
+// error, e.g. the file being removed, which is passed to onChange. A remote key watch starts from the version the
+// config was loaded at, passes errors reaching its store to onChange and keeps retrying.
+func (c *Config) Watch(ctx context.Context, onChange func(err error)) error {
+	if isRemoteSource(c.File) {
+		remote, err := parseRemoteSource(c.File)
+		if err != nil {
+			return err
+		}
+		go remote.watch(ctx, c.remoteVersion, onChange)
+		return nil
+	}
+
+	return file.Provider(c.File).Watch(func(_ any, err error) {
+		if ctx.Err() != nil {
+			return
+		}
+		onChange(err)
+	})
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: info\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan error, 10)
+	cfg := &Config{File: path}
+	require.NoError(t, cfg.Watch(ctx, func(err error) { changes <- err }))
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: debug\n"), 0o600))
+	select {
+	case err := <-changes:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change to be watched")
+	}
+
+	// changes are no longer passed on once ctx is done
+	cancel()
+	for len(changes) > 0 {
+		<-changes
+	}
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: warn\n"), 0o600))
+	select {
+	case <-changes:
+		t.Fatal("expected no change once ctx is done")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestConfig_Watch_MissingFile(t *testing.T) {
+	cfg := &Config{File: filepath.Join(t.TempDir(), "missing.yaml")}
+	assert.Error(t, cfg.Watch(context.Background(), func(error) {}))
+}
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Remote stores config can be loaded from, named by the scheme of its key URL - a +https suffix connects over TLS
+const (
+	remoteStoreConsul = "consul"
+	remoteStoreEtcd   = "etcd"
+)
+
+const (
+	// remoteRequestTimeout is how long a remote store has to answer a request other than a watch
+	remoteRequestTimeout = 10 * time.Second
+	// consulWatchWait is how long a Consul blocking query waits for the key to change before asking again
+	consulWatchWait = 5 * time.Minute
+)
+
+// remoteWatchRetryInterval is how long a failed remote watch waits before watching again
+var remoteWatchRetryInterval = 5 * time.Second
+
+// remoteSource is a parsed remote config key URL, e.g. consul://127.0.0.1:8500/solana-validator-ha/validator-1
+type remoteSource struct {
+	store   string
+	baseURL string
+	key     string
+	client  *http.Client
+}
+
+// isRemoteSource returns true if source is the URL of a remote config key rather than a file path
+func isRemoteSource(source string) bool {
+	scheme, _, found := strings.Cut(source, "://")
+	if !found {
+		return false
+	}
+	store, _ := strings.CutSuffix(scheme, "+https")
+	return store == remoteStoreConsul || store == remoteStoreEtcd
+}
+
+// parseRemoteSource parses a remote config key URL
+func parseRemoteSource(source string) (*remoteSource, error) {
+	parsed, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote config URL: %w", err)
+	}
+
+	store, tls := strings.CutSuffix(parsed.Scheme, "+https")
+	if store != remoteStoreConsul && store != remoteStoreEtcd {
+		return nil, fmt.Errorf("remote config URL scheme must be one of consul, consul+https, etcd or etcd+https, got %s", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("remote config URL %s must have a host", source)
+	}
+	key := strings.TrimPrefix(parsed.Path, "/")
+	if key == "" {
+		return nil, fmt.Errorf("remote config URL %s must have a key path", source)
+	}
+
+	scheme := "http"
+	if tls {
+		scheme = "https"
+	}
+	return &remoteSource{
+		store:   store,
+		baseURL: scheme + "://" + parsed.Host,
+		key:     key,
+		client:  &http.Client{},
+	}, nil
+}
+
+// LoadFromRemote loads configuration from a key in Consul or etcd into the struct. Consul requests are made with
+// the CONSUL_HTTP_TOKEN ACL token and etcd requests as the ETCDCTL_USER user:password, when set.
+func (c *Config) LoadFromRemote(source string) error {
+	if _, err := parseRemoteSource(source); err != nil {
+		return err
+	}
+
+	c.File = source
+	return c.loadYAML()
+}
+
+// getRemote returns the value and version of the remote key at source
+func getRemote(source string) ([]byte, int64, error) {
+	remote, err := parseRemoteSource(source)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+	defer cancel()
+	return remote.get(ctx)
+}
+
+// watch calls onChange with nil each time the key changes after version until ctx is done, passing failures to
+// watch it to onChange and retrying after remoteWatchRetryInterval. A zero version watches from the key's current one.
+func (r *remoteSource) watch(ctx context.Context, version int64, onChange func(err error)) {
+	for ctx.Err() == nil {
+		var err error
+		switch r.store {
+		case remoteStoreConsul:
+			version, err = r.watchConsul(ctx, version, onChange)
+		case remoteStoreEtcd:
+			version, err = r.watchEtcd(ctx, version, onChange)
+		}
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		onChange(err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(remoteWatchRetryInterval):
+		}
+	}
+}
+
+// get returns the key's value and version
+func (r *remoteSource) get(ctx context.Context) (value []byte, version int64, err error) {
+	if r.store == remoteStoreConsul {
+		return r.getConsul(ctx, 0, 0)
+	}
+	return r.getEtcd(ctx)
+}
+
+// getConsul returns the key's value and modify index from Consul, blocking until its index is past index when set
+func (r *remoteSource) getConsul(ctx context.Context, index int64, wait time.Duration) (value []byte, version int64, err error) {
+	query := url.Values{"raw": {""}}
+	if index > 0 {
+		query.Set("index", strconv.FormatInt(index, 10))
+		query.Set("wait", wait.String())
+	}
+	body, response, err := r.consulRequest(ctx, http.MethodGet, "/v1/kv/"+r.key, query, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, 0, fmt.Errorf("consul key %s not found", r.key)
+	}
+
+	version, _ = strconv.ParseInt(response.Header.Get("X-Consul-Index"), 10, 64)
+	return body, version, nil
+}
+
+// consulRequest sends a request to Consul's HTTP API with the CONSUL_HTTP_TOKEN ACL token, when set
+func (r *remoteSource) consulRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) ([]byte, *http.Response, error) {
+	target := r.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token := os.Getenv("CONSUL_HTTP_TOKEN"); token != "" {
+		request.Header.Set("X-Consul-Token", token)
+	}
+	return r.do(request)
+}
+
+// watchConsul waits for the key to change with Consul blocking queries, returning its latest modify index
+func (r *remoteSource) watchConsul(ctx context.Context, index int64, onChange func(err error)) (int64, error) {
+	// the index the key was loaded at is unknown - start from its current one
+	if index == 0 {
+		ctx, cancel := context.WithTimeout(ctx, remoteRequestTimeout)
+		defer cancel()
+		_, latest, err := r.getConsul(ctx, 0, 0)
+		return latest, err
+	}
+
+	_, latest, err := r.getConsul(ctx, index, consulWatchWait)
+	if err != nil {
+		return index, err
+	}
+	// the index only moves on when the key changes, but may go backwards if consul is restored from a snapshot
+	if latest != index {
+		onChange(nil)
+	}
+	return latest, nil
+}
+
+// etcdKeyValue is a key value in etcd's JSON API, which encodes bytes as base64 and int64s as strings
+type etcdKeyValue struct {
+	Value       []byte `json:"value"`
+	ModRevision int64  `json:"mod_revision,string"`
+}
+
+// etcdHeader is the header of etcd's JSON API responses
+type etcdHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+// getEtcd returns the key's value and the store revision it was read at from etcd
+func (r *remoteSource) getEtcd(ctx context.Context) (value []byte, version int64, err error) {
+	kv, revision, err := r.rangeEtcd(ctx)
+	return kv.Value, revision, err
+}
+
+// rangeEtcd returns the key and the store revision it was read at from etcd
+func (r *remoteSource) rangeEtcd(ctx context.Context) (etcdKeyValue, int64, error) {
+	var response struct {
+		Header etcdHeader     `json:"header"`
+		KVs    []etcdKeyValue `json:"kvs"`
+	}
+	if err := r.postEtcd(ctx, "/v3/kv/range", map[string]any{"key": []byte(r.key)}, &response); err != nil {
+		return etcdKeyValue{}, 0, err
+	}
+	if len(response.KVs) == 0 {
+		return etcdKeyValue{}, 0, fmt.Errorf("etcd key %s not found", r.key)
+	}
+	return response.KVs[0], response.Header.Revision, nil
+}
+
+// resyncEtcd re-reads the key once the revisions after revision have been compacted away and can no longer be
+// watched, calling onChange if it changed in them and returning the revision to watch from
+func (r *remoteSource) resyncEtcd(ctx context.Context, revision int64, onChange func(err error)) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteRequestTimeout)
+	defer cancel()
+	kv, latest, err := r.rangeEtcd(ctx)
+	if err != nil {
+		return revision, err
+	}
+	if kv.ModRevision > revision {
+		onChange(nil)
+	}
+	return latest, nil
+}
+
+// watchEtcd streams changes to the key from etcd after revision, returning the latest revision seen when the
+// stream ends
+func (r *remoteSource) watchEtcd(ctx context.Context, revision int64, onChange func(err error)) (int64, error) {
+	// the revision the key was loaded at is unknown - start from the current one
+	if revision == 0 {
+		getCtx, cancel := context.WithTimeout(ctx, remoteRequestTimeout)
+		defer cancel()
+		_, latest, err := r.getEtcd(getCtx)
+		return latest, err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"create_request": map[string]any{"key": []byte(r.key), "start_revision": strconv.FormatInt(revision+1, 10)},
+	})
+	if err != nil {
+		return revision, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/v3/watch", bytes.NewReader(body))
+	if err != nil {
+		return revision, err
+	}
+	if err := r.authenticateEtcd(ctx, request); err != nil {
+		return revision, err
+	}
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return revision, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return revision, fmt.Errorf("etcd watch returned status %d", response.StatusCode)
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var message struct {
+			Result struct {
+				Events []struct {
+					KV etcdKeyValue `json:"kv"`
+				} `json:"events"`
+				Canceled        bool   `json:"canceled"`
+				CancelReason    string `json:"cancel_reason"`
+				CompactRevision int64  `json:"compact_revision,string"`
+			} `json:"result"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("etcd watch stream ended")
+			}
+			return revision, err
+		}
+		// watching from a compacted revision is never going to work - start again from the key as it is now
+		if message.Result.CompactRevision > 0 {
+			return r.resyncEtcd(ctx, revision, onChange)
+		}
+		if message.Result.Canceled {
+			return revision, fmt.Errorf("etcd watch canceled: %s", message.Result.CancelReason)
+		}
+
+		if len(message.Result.Events) == 0 {
+			continue
+		}
+		for _, event := range message.Result.Events {
+			revision = max(revision, event.KV.ModRevision)
+		}
+		onChange(nil)
+	}
+}
+
+// postEtcd posts a request to etcd's JSON API, decoding its response into out
+func (r *remoteSource) postEtcd(ctx context.Context, path string, in any, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if path != "/v3/auth/authenticate" {
+		if err := r.authenticateEtcd(ctx, request); err != nil {
+			return err
+		}
+	}
+
+	responseBody, _, err := r.do(request)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(responseBody, out)
+}
+
+// authenticateEtcd authorizes request as the ETCDCTL_USER user:password, if set
+func (r *remoteSource) authenticateEtcd(ctx context.Context, request *http.Request) error {
+	user := os.Getenv("ETCDCTL_USER")
+	if user == "" {
+		return nil
+	}
+
+	name, password, _ := strings.Cut(user, ":")
+	var response struct {
+		Token string `json:"token"`
+	}
+	if err := r.postEtcd(ctx, "/v3/auth/authenticate", map[string]string{"name": name, "password": password}, &response); err != nil {
+		return fmt.Errorf("failed to authenticate with etcd: %w", err)
+	}
+	request.Header.Set("Authorization", response.Token)
+	return nil
+}
+
+// do sends request, returning the response body - statuses other than OK and not found are errors
+func (r *remoteSource) do(request *http.Request) ([]byte, *http.Response, error) {
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		return nil, nil, fmt.Errorf("%s %s returned status %d: %s", r.store, request.URL.Path, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, response, nil
+}
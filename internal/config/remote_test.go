@@ -0,0 +1,331 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsul serves a single key from Consul's KV HTTP API, answering blocking queries once the key changes
+type fakeConsul struct {
+	mu      sync.Mutex
+	index   int
+	value   string
+	changed chan struct{}
+	token   string
+}
+
+func newFakeConsul(t *testing.T, key string, value string) *httptest.Server {
+	consul := &fakeConsul{index: 1, value: value, changed: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/kv/"+key, func(w http.ResponseWriter, r *http.Request) {
+		consul.mu.Lock()
+		consul.token = r.Header.Get("X-Consul-Token")
+		index, changed := consul.index, consul.changed
+		consul.mu.Unlock()
+
+		if r.URL.Query().Get("index") == fmt.Sprint(index) {
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		w.Header().Set("X-Consul-Index", fmt.Sprint(consul.index))
+		fmt.Fprint(w, consul.value)
+	})
+	mux.HandleFunc("/v1/kv/", http.NotFound)
+	mux.HandleFunc("PUT /test/set", func(w http.ResponseWriter, r *http.Request) {
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		consul.index++
+		consul.value = r.URL.Query().Get("value")
+		close(consul.changed)
+		consul.changed = make(chan struct{})
+	})
+	mux.HandleFunc("GET /test/token", func(w http.ResponseWriter, r *http.Request) {
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		fmt.Fprint(w, consul.token)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestIsRemoteSource(t *testing.T) {
+	assert.True(t, isRemoteSource("consul://127.0.0.1:8500/ha/validator-1"))
+	assert.True(t, isRemoteSource("consul+https://consul.example.com/ha/validator-1"))
+	assert.True(t, isRemoteSource("etcd://127.0.0.1:2379/ha/validator-1"))
+	assert.True(t, isRemoteSource("etcd+https://etcd.example.com/ha/validator-1"))
+	assert.False(t, isRemoteSource("/etc/solana-validator-ha/config.yaml"))
+	assert.False(t, isRemoteSource("config.yaml"))
+	assert.False(t, isRemoteSource("zookeeper://127.0.0.1/ha"))
+}
+
+func TestParseRemoteSource(t *testing.T) {
+	remote, err := parseRemoteSource("consul+https://consul.example.com:8501/ha/validator-1")
+	require.NoError(t, err)
+	assert.Equal(t, remoteStoreConsul, remote.store)
+	assert.Equal(t, "https://consul.example.com:8501", remote.baseURL)
+	assert.Equal(t, "ha/validator-1", remote.key)
+
+	_, err = parseRemoteSource("etcd://127.0.0.1:2379/")
+	assert.ErrorContains(t, err, "must have a key path")
+
+	_, err = parseRemoteSource("etcd:///ha/validator-1")
+	assert.ErrorContains(t, err, "must have a host")
+}
+
+func TestLoadFromRemote_Consul(t *testing.T) {
+	t.Setenv("CONSUL_HTTP_TOKEN", "secret-token")
+	server := newFakeConsul(t, "ha/validator-1", "log:\n  level: debug\n")
+	source := "consul://" + strings.TrimPrefix(server.URL, "http://") + "/ha/validator-1"
+
+	cfg := &Config{}
+	require.NoError(t, cfg.LoadFromRemote(source))
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, source, cfg.File)
+
+	response, err := http.Get(server.URL + "/test/token")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	token, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", string(token))
+
+	err = (&Config{}).LoadFromRemote("consul://" + strings.TrimPrefix(server.URL, "http://") + "/ha/missing")
+	assert.ErrorContains(t, err, "consul key ha/missing not found")
+}
+
+func TestConfig_Watch_Consul(t *testing.T) {
+	server := newFakeConsul(t, "ha/validator-1", "log:\n  level: debug\n")
+	cfg := &Config{File: "consul://" + strings.TrimPrefix(server.URL, "http://") + "/ha/validator-1"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan error, 10)
+	require.NoError(t, cfg.Watch(ctx, func(err error) { changes <- err }))
+
+	// wait for the watch to start blocking on the current index before changing the key
+	time.Sleep(100 * time.Millisecond)
+	request, err := http.NewRequest(http.MethodPut, server.URL+"/test/set?value=log:%0A%20%20level:%20warn%0A", nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+
+	select {
+	case err := <-changes:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change to be watched")
+	}
+
+	require.NoError(t, cfg.Load(cfg.File))
+	assert.Equal(t, "warn", cfg.Log.Level)
+}
+
+func TestConfig_Watch_Consul_FromLoadedIndex(t *testing.T) {
+	server := newFakeConsul(t, "ha/validator-1", "log:\n  level: debug\n")
+	cfg := &Config{}
+	require.NoError(t, cfg.LoadFromRemote("consul://"+strings.TrimPrefix(server.URL, "http://")+"/ha/validator-1"))
+
+	// a change between loading the config and watching it is not missed
+	request, err := http.NewRequest(http.MethodPut, server.URL+"/test/set?value=log:%0A%20%20level:%20warn%0A", nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan error, 10)
+	require.NoError(t, cfg.Watch(ctx, func(err error) { changes <- err }))
+
+	select {
+	case err := <-changes:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the change since loading to be watched")
+	}
+}
+
+func TestLoadFromRemote_Etcd(t *testing.T) {
+	t.Setenv("ETCDCTL_USER", "ha:password")
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/auth/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request["name"] != "ha" || request["password"] != "password" {
+			http.Error(w, `{"error":"authentication failed"}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"token":"etcd-token"}`)
+	})
+	mux.HandleFunc("POST /v3/kv/range", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "etcd-token" {
+			http.Error(w, `{"error":"invalid auth token"}`, http.StatusUnauthorized)
+			return
+		}
+		var request struct {
+			Key []byte `json:"key"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if string(request.Key) != "ha/validator-1" {
+			fmt.Fprint(w, `{"header":{"revision":"7"}}`)
+			return
+		}
+		value := base64.StdEncoding.EncodeToString([]byte("log:\n  level: debug\n"))
+		fmt.Fprintf(w, `{"header":{"revision":"7"},"kvs":[{"key":"aGEvdmFsaWRhdG9yLTE=","value":%q,"mod_revision":"5"}],"count":"1"}`, value)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	cfg := &Config{}
+	require.NoError(t, cfg.LoadFromRemote("etcd://"+host+"/ha/validator-1"))
+	assert.Equal(t, "debug", cfg.Log.Level)
+
+	err := (&Config{}).LoadFromRemote("etcd://" + host + "/ha/missing")
+	assert.ErrorContains(t, err, "etcd key ha/missing not found")
+
+	t.Setenv("ETCDCTL_USER", "ha:wrong")
+	err = (&Config{}).LoadFromRemote("etcd://" + host + "/ha/validator-1")
+	assert.ErrorContains(t, err, "failed to authenticate with etcd")
+}
+
+func TestConfig_Watch_Etcd(t *testing.T) {
+	originalRetryInterval := remoteWatchRetryInterval
+	remoteWatchRetryInterval = 10 * time.Millisecond
+	defer func() { remoteWatchRetryInterval = originalRetryInterval }()
+
+	startRevisions := make(chan string, 10)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/kv/range", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"header":{"revision":"7"},"kvs":[{"value":"","mod_revision":"5"}]}`)
+	})
+	mux.HandleFunc("POST /v3/watch", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			CreateRequest struct {
+				StartRevision string `json:"start_revision"`
+			} `json:"create_request"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		startRevisions <- request.CreateRequest.StartRevision
+
+		// the first stream sees a change and ends, the second is held open until the test ends
+		fmt.Fprintln(w, `{"result":{"header":{"revision":"7"},"created":true}}`)
+		if request.CreateRequest.StartRevision != "8" {
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprintln(w, `{"result":{"header":{"revision":"9"},"events":[{"kv":{"value":"","mod_revision":"9"}}]}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan error, 10)
+	cfg := &Config{File: "etcd://" + strings.TrimPrefix(server.URL, "http://") + "/ha/validator-1"}
+	require.NoError(t, cfg.Watch(ctx, func(err error) { changes <- err }))
+
+	select {
+	case err := <-changes:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change to be watched")
+	}
+
+	// the stream ending is passed on, and the watch resumes after the last revision seen
+	select {
+	case err := <-changes:
+		assert.ErrorContains(t, err, "etcd watch stream ended")
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stream ending to be passed on")
+	}
+	assert.Equal(t, "8", <-startRevisions)
+	select {
+	case revision := <-startRevisions:
+		assert.Equal(t, "10", revision)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the watch to resume")
+	}
+}
+
+func TestConfig_Watch_Etcd_Compacted(t *testing.T) {
+	var ranges atomic.Int32
+	startRevisions := make(chan string, 10)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/kv/range", func(w http.ResponseWriter, r *http.Request) {
+		// loaded at revision 7, then changed at revision 10 while the watch was behind
+		if ranges.Add(1) == 1 {
+			fmt.Fprint(w, `{"header":{"revision":"7"},"kvs":[{"value":"","mod_revision":"5"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"header":{"revision":"12"},"kvs":[{"value":"","mod_revision":"10"}]}`)
+	})
+	mux.HandleFunc("POST /v3/watch", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			CreateRequest struct {
+				StartRevision string `json:"start_revision"`
+			} `json:"create_request"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		startRevisions <- request.CreateRequest.StartRevision
+
+		if request.CreateRequest.StartRevision == "8" {
+			fmt.Fprintln(w, `{"result":{"header":{"revision":"12"},"created":true,"canceled":true,"compact_revision":"11",`+
+				`"cancel_reason":"mvcc: required revision has been compacted"}}`)
+			return
+		}
+		fmt.Fprintln(w, `{"result":{"header":{"revision":"12"},"created":true}}`)
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := &Config{}
+	require.NoError(t, cfg.LoadFromRemote("etcd://"+strings.TrimPrefix(server.URL, "http://")+"/ha/validator-1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan error, 10)
+	require.NoError(t, cfg.Watch(ctx, func(err error) { changes <- err }))
+
+	// the watch starts after the revision the config was loaded at, without reading the key again
+	assert.Equal(t, "8", <-startRevisions)
+
+	// and once compacted re-reads the key, seeing the change it missed, and watches on from there
+	select {
+	case err := <-changes:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the change missed to compaction to be seen")
+	}
+	select {
+	case revision := <-startRevisions:
+		assert.Equal(t, "13", revision)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the watch to resume")
+	}
+	assert.Equal(t, int32(2), ranges.Load())
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,16 @@
+package config
+
+import "fmt"
+
+// defaultCommandResource is the resource role and snapshot recovery commands touch unless configured otherwise
+const defaultCommandResource = "validator"
+
+// validateResources validates the resources a command declares it touches
+func validateResources(resources []string) error {
+	for i, resource := range resources {
+		if resource == "" {
+			return fmt.Errorf("resources[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -15,6 +18,17 @@ type RoleCommandTemplateData struct {
 	PassiveIdentityKeypairFile string
 	PassiveIdentityPubkey      string
 	SelfName                   string
+	// Cluster is cluster.name, e.g. mainnet-beta
+	Cluster string
+	// PublicIP is this validator's public IP - empty when the config is loaded, set before each failover
+	PublicIP string
+	// PeerIPs are the failover.peers IPs, excluding this validator, sorted
+	PeerIPs []string
+	// Epoch is the cluster's current epoch - zero when the config is loaded or it can't be fetched
+	Epoch uint64
+	// PreviousRole is the role this validator was last seen in before the transition - active, passive or unknown,
+	// and empty when the config is loaded
+	PreviousRole string
 }
 
 // Role represents configuration for active/passive role transitions
@@ -24,12 +38,48 @@ type Role struct {
 	Args    []string          `koanf:"args"`
 	Env     map[string]string `koanf:"env"`
 	Hooks   Hooks             `koanf:"hooks"`
+	// Resources the command touches - defaults to the validator, so role and snapshot recovery commands never interleave
+	Resources []string `koanf:"resources"`
+	// WorkingDir is the directory the command runs in, e.g. the ledger directory
+	WorkingDir string `koanf:"working_dir"`
+	// Umask is the octal file mode creation mask the command runs with, e.g. "0027"
+	Umask string `koanf:"umask"`
+	// Shell runs the command and args as a single line through /bin/sh -c, for pipes and redirection
+	Shell bool `koanf:"shell"`
+	// ClearEnv runs the command with only PassEnv and Env instead of the manager's environment
+	ClearEnv bool `koanf:"clear_env"`
+	// PassEnv are the manager's environment variables still passed with ClearEnv, e.g. PATH
+	PassEnv []string `koanf:"pass_env"`
+	// LockFile is a file locked while the command runs so it never overlaps another invocation, e.g. by an operator
+	LockFile string `koanf:"lock_file"`
+	// LockMode is what to do when LockFile is held - wait (default) or skip the command
+	LockMode string `koanf:"lock_mode"`
+	// templates are the command, args, env and hooks as configured, kept so they can be rendered again with the
+	// data only known at failover time
+	templates *roleCommands
+}
+
+// roleCommands are the command, args, env and hook commands and args of a role, rendered or not
+type roleCommands struct {
+	command string
+	args    []string
+	env     map[string]string
+	pre     []hookCommand
+	post    []hookCommand
+}
+
+// hookCommand is the command and args of a hook
+type hookCommand struct {
+	command string
+	args    []string
 }
 
 type RoleCommandRunOptions struct {
 	DryRun       bool
 	LoggerPrefix string
 	LoggerArgs   []any
+	// AllowedCommands, if set, are the only commands the role may run - see failover.command_allowlist
+	AllowedCommands []string
 }
 
 // Validate validates the role configuration
@@ -39,11 +89,37 @@ func (r *Role) Validate() error {
 		return fmt.Errorf("role.command must be defined")
 	}
 
+	if err := validateResources(r.Resources); err != nil {
+		return fmt.Errorf("role.%w", err)
+	}
+
+	if r.Umask != "" {
+		if err := command.ValidateUmask(r.Umask); err != nil {
+			return fmt.Errorf("role.%w", err)
+		}
+	}
+
+	if err := command.ValidateLockMode(r.LockMode); err != nil {
+		return fmt.Errorf("role.%w", err)
+	}
+
 	return r.Hooks.Validate()
 }
 
-// RenderCommands renders the role commands
+// RenderCommands renders the role commands - rendering again renders the templates as configured, not the
+// commands already rendered, and the commands are left as they were if rendering fails
 func (r *Role) RenderCommands(data RoleCommandTemplateData) (err error) {
+	rendered := r.commands()
+	if r.templates == nil {
+		r.templates = &rendered
+	}
+	r.setCommands(*r.templates)
+	defer func() {
+		if err != nil {
+			r.setCommands(rendered)
+		}
+	}()
+
 	// render role.command, role.args, and role.env
 	err = r.renderCommandAndArgs(data)
 	if err != nil {
@@ -69,6 +145,29 @@ func (r *Role) RenderCommands(data RoleCommandTemplateData) (err error) {
 	return nil
 }
 
+// commands returns a copy of the role's command, args, env and hook commands and args
+func (r *Role) commands() roleCommands {
+	commands := roleCommands{command: r.Command, args: slices.Clone(r.Args), env: maps.Clone(r.Env)}
+	for _, hook := range r.Hooks.Pre {
+		commands.pre = append(commands.pre, hookCommand{command: hook.Command, args: slices.Clone(hook.Args)})
+	}
+	for _, hook := range r.Hooks.Post {
+		commands.post = append(commands.post, hookCommand{command: hook.Command, args: slices.Clone(hook.Args)})
+	}
+	return commands
+}
+
+// setCommands sets the role's command, args, env and hook commands and args to copies of commands
+func (r *Role) setCommands(commands roleCommands) {
+	r.Command, r.Args, r.Env = commands.command, slices.Clone(commands.args), maps.Clone(commands.env)
+	for i, hook := range commands.pre {
+		r.Hooks.Pre[i].Command, r.Hooks.Pre[i].Args = hook.command, slices.Clone(hook.args)
+	}
+	for i, hook := range commands.post {
+		r.Hooks.Post[i].Command, r.Hooks.Post[i].Args = hook.command, slices.Clone(hook.args)
+	}
+}
+
 func (r *Role) renderCommandAndArgs(data RoleCommandTemplateData) (err error) {
 	// render command
 	r.Command, err = r.renderTemplateString(data, r.Command)
@@ -127,7 +226,22 @@ func (r *Role) renderTemplateString(data RoleCommandTemplateData, templateStr st
 	return buf.String(), nil
 }
 
-func (r *Role) RunCommand(opts RoleCommandRunOptions) error {
+// RunCommand runs the role command until it exits or ctx is done
+func (r *Role) RunCommand(ctx context.Context, opts RoleCommandRunOptions) error {
+	if err := command.Run(ctx, r.runOptions(opts)); err != nil {
+		return fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return nil
+}
+
+// Plan returns the copy-pasteable command line the role command runs
+func (r *Role) Plan(opts RoleCommandRunOptions) string {
+	return r.runOptions(opts).Plan()
+}
+
+// runOptions returns the options the role command is run with
+func (r *Role) runOptions(opts RoleCommandRunOptions) command.RunOptions {
 	loggerArgs := []any{
 		"command", r.Command,
 		"args", r.Args,
@@ -136,23 +250,23 @@ func (r *Role) RunCommand(opts RoleCommandRunOptions) error {
 	}
 	loggerArgs = append(loggerArgs, opts.LoggerArgs...)
 
-	if opts.DryRun {
-		return nil
+	return command.RunOptions{
+		Name:            r.Name,
+		Command:         r.Command,
+		Args:            r.Args,
+		Env:             r.Env,
+		DryRun:          opts.DryRun,
+		LoggerPrefix:    opts.LoggerPrefix,
+		LoggerArgs:      loggerArgs,
+		StreamOutput:    true,
+		Resources:       r.Resources,
+		WorkingDir:      r.WorkingDir,
+		Umask:           r.Umask,
+		Shell:           r.Shell,
+		ClearEnv:        r.ClearEnv,
+		PassEnv:         r.PassEnv,
+		LockFile:        r.LockFile,
+		LockMode:        r.LockMode,
+		AllowedCommands: opts.AllowedCommands,
 	}
-
-	err := command.Run(command.RunOptions{
-		Name:         r.Name,
-		Command:      r.Command,
-		Args:         r.Args,
-		Env:          r.Env,
-		DryRun:       opts.DryRun,
-		LoggerPrefix: opts.LoggerPrefix,
-		LoggerArgs:   loggerArgs,
-		StreamOutput: true,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to run command: %w", err)
-	}
-
-	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRole_Validate(t *testing.T) {
@@ -29,6 +30,13 @@ func TestRole_Validate(t *testing.T) {
 	err = role.Validate()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "role.command must be defined")
+
+	// Test with invalid umask
+	role.Command = "systemctl start solana"
+	role.Umask = "u=rwx"
+	err = role.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "role.umask must be an octal mode")
 }
 
 func TestRole_RenderCommands(t *testing.T) {
@@ -73,6 +81,43 @@ func TestRole_RenderCommands(t *testing.T) {
 	assert.Equal(t, "validator-1", role.Env["SOLANA_SELF"])
 }
 
+func TestRole_RenderCommandsAgain(t *testing.T) {
+	role := &Role{
+		Command: "notify",
+		Args:    []string{"--epoch", "{{.Epoch}}", "--peer", "{{index .PeerIPs 0}}"},
+		Env:     map[string]string{"PREVIOUS_ROLE": "{{.PreviousRole}}"},
+		Hooks: Hooks{
+			Pre: []Hook{{Name: "pre-hook", Command: "echo", Args: []string{"{{.Cluster}} {{.PublicIP}}"}}},
+		},
+	}
+
+	err := role.RenderCommands(RoleCommandTemplateData{Cluster: "mainnet-beta", PeerIPs: []string{"10.0.0.2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--epoch", "0", "--peer", "10.0.0.2"}, role.Args)
+	assert.Equal(t, "", role.Env["PREVIOUS_ROLE"])
+	assert.Equal(t, []string{"mainnet-beta "}, role.Hooks.Pre[0].Args)
+
+	// later renders use the original templates, not the previous rendering
+	err = role.RenderCommands(RoleCommandTemplateData{
+		Cluster:      "mainnet-beta",
+		PublicIP:     "10.0.0.1",
+		PeerIPs:      []string{"10.0.0.3"},
+		Epoch:        812,
+		PreviousRole: "passive",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--epoch", "812", "--peer", "10.0.0.3"}, role.Args)
+	assert.Equal(t, "passive", role.Env["PREVIOUS_ROLE"])
+	assert.Equal(t, []string{"mainnet-beta 10.0.0.1"}, role.Hooks.Pre[0].Args)
+
+	// a failed render leaves the previous rendering in place
+	err = role.RenderCommands(RoleCommandTemplateData{Epoch: 813})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"--epoch", "812", "--peer", "10.0.0.3"}, role.Args)
+	assert.Equal(t, "passive", role.Env["PREVIOUS_ROLE"])
+	assert.Equal(t, []string{"mainnet-beta 10.0.0.1"}, role.Hooks.Pre[0].Args)
+}
+
 func TestRole_RenderCommandsWithInvalidTemplate(t *testing.T) {
 	role := &Role{
 		Command: "systemctl {{.InvalidField}}",
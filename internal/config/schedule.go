@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduleCatchUp is the furthest back a missed scheduled failover is still run, e.g. after a slow HA check
+const maxScheduleCatchUp = time.Hour
+
+// FailoverSchedules are scheduled failovers that hand the active role to a peer, to regularly exercise the failover
+// path and give each node maintenance windows
+type FailoverSchedules []FailoverSchedule
+
+// FailoverSchedule hands the active role to a peer at the times of a cron expression, if that peer is healthy
+type FailoverSchedule struct {
+	Name string `koanf:"name"`
+	// Cron is a five field cron expression - minute, hour, day of month, month and day of week
+	Cron string `koanf:"cron"`
+	// Timezone is an IANA timezone name the cron expression is evaluated in
+	Timezone string `koanf:"timezone"`
+	// ActivePeer is the name of the peer, in failover.peers or validator.name, that should be active
+	ActivePeer string `koanf:"active_peer"`
+	// HoldDuration is how long other passive peers hold back their takeovers so ActivePeer takes over
+	HoldDuration time.Duration `koanf:"hold_duration"`
+}
+
+// SetDefaults sets default values for the failover schedules
+func (s FailoverSchedules) SetDefaults() {
+	for i := range s {
+		if s[i].Timezone == "" {
+			s[i].Timezone = "UTC"
+		}
+		if s[i].HoldDuration == 0 {
+			s[i].HoldDuration = 5 * time.Minute
+		}
+	}
+}
+
+// Validate validates the failover schedules - active peers must be selfName or in peers
+func (s FailoverSchedules) Validate(selfName string, peers Peers) error {
+	names := make(map[string]bool)
+	for i, schedule := range s {
+		if err := schedule.Validate(); err != nil {
+			return fmt.Errorf("failover.schedules[%d]: %w", i, err)
+		}
+		if names[schedule.Name] {
+			return fmt.Errorf("failover.schedules[%d]: duplicate name %s", i, schedule.Name)
+		}
+		names[schedule.Name] = true
+		if _, ok := peers[schedule.ActivePeer]; !ok && schedule.ActivePeer != selfName {
+			return fmt.Errorf("failover.schedules[%d]: active_peer %s must be validator.name or in failover.peers", i, schedule.ActivePeer)
+		}
+	}
+	return nil
+}
+
+// Validate validates the failover schedule
+func (s *FailoverSchedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name must be defined")
+	}
+	if _, err := parseCron(s.Cron); err != nil {
+		return fmt.Errorf("cron: %w", err)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+	if s.ActivePeer == "" {
+		return fmt.Errorf("active_peer must be defined")
+	}
+	if s.HoldDuration <= 0 {
+		return fmt.Errorf("hold_duration must be greater than zero")
+	}
+	return nil
+}
+
+// FiredBetween returns true if the schedule fired after from and at or before to - only the last hour of a longer
+// span is considered
+func (s *FailoverSchedule) FiredBetween(from time.Time, to time.Time) bool {
+	cron, err := parseCron(s.Cron)
+	if err != nil {
+		return false
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return false
+	}
+
+	if earliest := to.Add(-maxScheduleCatchUp); from.Before(earliest) {
+		from = earliest
+	}
+	for minute := from.Truncate(time.Minute).Add(time.Minute); !minute.After(to); minute = minute.Add(time.Minute) {
+		if cron.matches(minute.In(location)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cronSchedule is a parsed five field cron expression, each field the set of values it matches
+type cronSchedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek []int
+	// daysOfMonthAny and daysOfWeekAny are true for a * field - when both are restricted either may match
+	daysOfMonthAny, daysOfWeekAny bool
+}
+
+// cronMonths maps accepted month names to months
+var cronMonths = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// parseCron parses a five field cron expression of values, names, ranges, lists, steps and *
+func parseCron(expression string) (cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return cronSchedule{}, fmt.Errorf("must have 5 fields (minute hour day-of-month month day-of-week), got %q", expression)
+	}
+
+	weekdays := make(map[string]int, len(windowDays))
+	for name, weekday := range windowDays {
+		weekdays[name] = int(weekday)
+	}
+
+	var cron cronSchedule
+	var err error
+	if cron.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return cronSchedule{}, fmt.Errorf("minute: %w", err)
+	}
+	if cron.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return cronSchedule{}, fmt.Errorf("hour: %w", err)
+	}
+	if cron.daysOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return cronSchedule{}, fmt.Errorf("day of month: %w", err)
+	}
+	if cron.months, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return cronSchedule{}, fmt.Errorf("month: %w", err)
+	}
+	// 7 is also sunday
+	if cron.daysOfWeek, err = parseCronField(fields[4], 0, 7, weekdays); err != nil {
+		return cronSchedule{}, fmt.Errorf("day of week: %w", err)
+	}
+	if slices.Contains(cron.daysOfWeek, 7) {
+		cron.daysOfWeek = append(cron.daysOfWeek, 0)
+	}
+	cron.daysOfMonthAny = fields[2] == "*"
+	cron.daysOfWeekAny = fields[4] == "*"
+
+	return cron, nil
+}
+
+// parseCronField parses a comma separated list of values, names, ranges and steps between minValue and maxValue
+func parseCronField(field string, minValue int, maxValue int, names map[string]int) ([]int, error) {
+	parseValue := func(value string) (int, error) {
+		if named, ok := names[strings.ToLower(value)]; ok {
+			return named, nil
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil || number < minValue || number > maxValue {
+			return 0, fmt.Errorf("invalid value %q - must be %d-%d", value, minValue, maxValue)
+		}
+		return number, nil
+	}
+
+	var values []int
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		low, high := minValue, maxValue
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart); err != nil {
+				return nil, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseValue(highPart); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				high = maxValue
+			}
+			if high < low {
+				return nil, fmt.Errorf("invalid range %q", rangePart)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// matches returns true if the cron expression matches the minute of t
+func (c cronSchedule) matches(t time.Time) bool {
+	if !slices.Contains(c.minutes, t.Minute()) || !slices.Contains(c.hours, t.Hour()) || !slices.Contains(c.months, int(t.Month())) {
+		return false
+	}
+
+	dayOfMonth := slices.Contains(c.daysOfMonth, t.Day())
+	dayOfWeek := slices.Contains(c.daysOfWeek, int(t.Weekday()))
+	switch {
+	case c.daysOfMonthAny && c.daysOfWeekAny:
+		return true
+	case c.daysOfMonthAny:
+		return dayOfWeek
+	case c.daysOfWeekAny:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
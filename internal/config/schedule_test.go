@@ -0,0 +1,109 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailoverSchedules_Validate(t *testing.T) {
+	peers := Peers{"backup-1": {Name: "backup-1", IP: "192.168.1.11"}}
+	valid := func() FailoverSchedule {
+		return FailoverSchedule{Name: "weekly", Cron: "0 2 * * tue", Timezone: "UTC", ActivePeer: "backup-1", HoldDuration: 5 * time.Minute}
+	}
+
+	schedules := FailoverSchedules{valid()}
+	schedules[0].Timezone = ""
+	schedules[0].HoldDuration = 0
+	schedules.SetDefaults()
+	assert.Equal(t, "UTC", schedules[0].Timezone)
+	assert.Equal(t, 5*time.Minute, schedules[0].HoldDuration)
+	assert.NoError(t, schedules.Validate("primary", peers))
+
+	back := valid()
+	back.Name = "failback"
+	back.ActivePeer = "primary"
+	assert.NoError(t, FailoverSchedules{valid(), back}.Validate("primary", peers))
+
+	tests := []struct {
+		name   string
+		modify func(*FailoverSchedule)
+		err    string
+	}{
+		{"missing name", func(s *FailoverSchedule) { s.Name = "" }, "name must be defined"},
+		{"bad cron", func(s *FailoverSchedule) { s.Cron = "0 2 * *" }, "must have 5 fields"},
+		{"bad timezone", func(s *FailoverSchedule) { s.Timezone = "Mars/Olympus" }, "invalid timezone"},
+		{"missing active peer", func(s *FailoverSchedule) { s.ActivePeer = "" }, "active_peer must be defined"},
+		{"unknown active peer", func(s *FailoverSchedule) { s.ActivePeer = "nobody" }, "active_peer nobody must be validator.name or in failover.peers"},
+		{"negative hold duration", func(s *FailoverSchedule) { s.HoldDuration = -time.Second }, "hold_duration must be greater than zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := valid()
+			tt.modify(&schedule)
+			err := FailoverSchedules{schedule}.Validate("primary", peers)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	err := FailoverSchedules{valid(), valid()}.Validate("primary", peers)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate name weekly")
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{"* * * * *", ""},
+		{"*/15 0-6,22 1 jan-mar mon-fri", ""},
+		{"30 2 * * 7", ""},
+		{"60 * * * *", "minute: invalid value"},
+		{"* 24 * * *", "hour: invalid value"},
+		{"* * 0 * *", "day of month: invalid value"},
+		{"* * * foo *", "month: invalid value"},
+		{"* * * * 8", "day of week: invalid value"},
+		{"*/0 * * * *", "invalid step"},
+		{"5-1 * * * *", "invalid range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := parseCron(tt.expression)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestFailoverSchedule_FiredBetween(t *testing.T) {
+	// 2025-01-07 is a tuesday
+	tuesday := time.Date(2025, 1, 7, 2, 0, 0, 0, time.UTC)
+	schedule := FailoverSchedule{Cron: "0 2 * * tue", Timezone: "UTC"}
+
+	assert.True(t, schedule.FiredBetween(tuesday.Add(-5*time.Second), tuesday))
+	assert.True(t, schedule.FiredBetween(tuesday.Add(-5*time.Second), tuesday.Add(5*time.Second)))
+	assert.False(t, schedule.FiredBetween(tuesday, tuesday.Add(5*time.Second)), "from is exclusive")
+	assert.False(t, schedule.FiredBetween(tuesday.Add(-5*time.Second), tuesday.Add(-time.Second)))
+	assert.False(t, schedule.FiredBetween(tuesday.Add(24*time.Hour-5*time.Second), tuesday.Add(24*time.Hour)), "wednesday")
+	assert.True(t, schedule.FiredBetween(tuesday.Add(-30*time.Minute), tuesday.Add(30*time.Minute)), "missed by a slow check")
+	assert.False(t, schedule.FiredBetween(tuesday.Add(-time.Minute), tuesday.Add(2*time.Hour)), "missed by more than an hour")
+
+	// evaluated in the schedule's timezone
+	schedule.Timezone = "America/New_York"
+	assert.False(t, schedule.FiredBetween(tuesday.Add(-5*time.Second), tuesday))
+	assert.True(t, schedule.FiredBetween(tuesday.Add(5*time.Hour-5*time.Second), tuesday.Add(5*time.Hour)))
+
+	// either restricted day field matches
+	schedule = FailoverSchedule{Cron: "0 2 1 * sun", Timezone: "UTC"}
+	assert.True(t, schedule.FiredBetween(tuesday.Add(-6*24*time.Hour-time.Second), tuesday.Add(-6*24*time.Hour)), "1st of the month")
+	assert.True(t, schedule.FiredBetween(tuesday.Add(-2*24*time.Hour-time.Second), tuesday.Add(-2*24*time.Hour)), "sunday")
+	assert.False(t, schedule.FiredBetween(tuesday.Add(-time.Second), tuesday))
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/parsers/yaml"
+)
+
+// secretKeyPattern matches the keys of settings holding secrets - *_env settings only name the variables holding them
+var secretKeyPattern = regexp.MustCompile(`(?i)(^|_)(token|secret|password|webhook_url|routing_key|api_key)$`)
+
+// rpcURLKeyPattern matches the keys of RPC URL settings, whose paths and queries often carry API keys
+var rpcURLKeyPattern = regexp.MustCompile(`^rpc_urls?$`)
+
+// durationType is the type of duration settings, shown as Go duration strings
+var durationType = reflect.TypeFor[time.Duration]()
+
+// MaskSecret hides all but the last 4 characters of long secrets, and short secrets entirely
+func MaskSecret(secret string) string {
+	if len(secret) < 16 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// Effective returns the config as YAML as the manager runs with it - after defaults, environment variable
+// interpolation, secret resolution and template rendering - with secrets masked, e.g. to diff what two hosts run
+func (c *Config) Effective() ([]byte, error) {
+	values, ok := effectiveValue(reflect.ValueOf(c).Elem(), false).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert config to effective values")
+	}
+	return yaml.Parser().Marshal(values)
+}
+
+// effectiveValue returns v as plain values keyed by koanf tag, masking secret strings - settings without a koanf
+// tag, like parsed keypairs, are left out
+func effectiveValue(v reflect.Value, secret bool) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return effectiveValue(v.Elem(), secret)
+	case reflect.Struct:
+		values := map[string]any{}
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			fieldValue := effectiveValue(v.Field(i), secretKeyPattern.MatchString(name))
+			if rpcURLKeyPattern.MatchString(name) {
+				fieldValue = maskRPCURLs(fieldValue)
+			}
+			values[name] = fieldValue
+		}
+		return values
+	case reflect.Map:
+		values := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key := fmt.Sprint(iter.Key().Interface())
+			values[key] = effectiveValue(iter.Value(), secret || secretKeyPattern.MatchString(key))
+		}
+		return values
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []any{}
+		}
+		values := make([]any, v.Len())
+		for i := range v.Len() {
+			values[i] = effectiveValue(v.Index(i), secret)
+		}
+		return values
+	case reflect.String:
+		if secret && v.String() != "" {
+			return MaskSecret(v.String())
+		}
+		return v.String()
+	}
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// maskRPCURLs masks the path and query of RPC URLs, keeping their scheme and host
+func maskRPCURLs(value any) any {
+	switch value := value.(type) {
+	case string:
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Host == "" {
+			return value
+		}
+		masked := parsed.Scheme + "://" + parsed.Host
+		if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.User != nil {
+			masked += "/****"
+		}
+		return masked
+	case []any:
+		for i := range value {
+			value[i] = maskRPCURLs(value[i])
+		}
+	}
+	return value
+}
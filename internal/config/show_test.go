@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "****", MaskSecret("short"))
+	assert.Equal(t, "****cdef", MaskSecret("0123456789abcdef"))
+}
+
+func TestConfig_Effective(t *testing.T) {
+	configFile := createTempConfigFile(t)
+	defer os.Remove(configFile)
+
+	content, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	content = append(content, []byte(`
+notifications:
+  enabled: true
+  telegram:
+    enabled: true
+    bot_token_env: SOLANA_VALIDATOR_HA_TEST_EFFECTIVE_TOKEN
+    chat_id: "42"
+`)...)
+	require.NoError(t, os.WriteFile(configFile, content, 0o600))
+	t.Setenv("SOLANA_VALIDATOR_HA_TEST_EFFECTIVE_TOKEN", "1234567890:telegram-bot-token")
+
+	cfg, err := NewFromConfigFile(context.Background(), configFile)
+	require.NoError(t, err)
+	cfg.Cluster.RPCURLs = []string{"https://rpc.example.com/v1/api-key?token=abc", "https://api.testnet.solana.com"}
+	cfg.Failover.Active.Env = map[string]string{"API_TOKEN": "0123456789abcdef", "LEDGER": "/mnt/ledger"}
+
+	effective, err := cfg.Effective()
+	require.NoError(t, err)
+	values, err := yaml.Parser().Unmarshal(effective)
+	require.NoError(t, err)
+
+	// defaults are applied
+	log := values["log"].(map[string]any)
+	assert.Equal(t, "text", log["format"])
+	failover := values["failover"].(map[string]any)
+	assert.Equal(t, "30s", failover["poll_interval_duration"])
+
+	// secrets are masked, the variables naming them are not
+	telegram := values["notifications"].(map[string]any)["telegram"].(map[string]any)
+	assert.Equal(t, "****oken", telegram["bot_token"])
+	assert.Equal(t, "SOLANA_VALIDATOR_HA_TEST_EFFECTIVE_TOKEN", telegram["bot_token_env"])
+	env := failover["active"].(map[string]any)["env"].(map[string]any)
+	assert.Equal(t, "****cdef", env["API_TOKEN"])
+	assert.Equal(t, "/mnt/ledger", env["LEDGER"])
+
+	// rpc url paths and queries are masked
+	assert.Equal(t, []any{"https://rpc.example.com/****", "https://api.testnet.solana.com"}, values["cluster"].(map[string]any)["rpc_urls"])
+
+	// parsed keypairs and the loaded file are not shown
+	assert.NotContains(t, string(effective), "ActiveKeyPair")
+	assert.NotContains(t, string(effective), configFile)
+}
@@ -0,0 +1,36 @@
+package config
+
+import "fmt"
+
+// SkipRate represents the configuration for monitoring the active identity's block production skip rate this epoch
+// - skipped leader slots are often the first sign the active node needs failing over
+type SkipRate struct {
+	Enabled bool `koanf:"enabled"`
+	// ThresholdPercent is the skip rate, in percent, above which skip_rate_high is sent
+	ThresholdPercent float64 `koanf:"threshold_percent"`
+	// MinLeaderSlots is how many leader slots must have passed this epoch before the skip rate is compared
+	MinLeaderSlots uint64 `koanf:"min_leader_slots"`
+}
+
+// SetDefaults sets default values for the skip rate configuration
+func (s *SkipRate) SetDefaults() {
+	if s.ThresholdPercent == 0 {
+		s.ThresholdPercent = 10
+	}
+	if s.MinLeaderSlots == 0 {
+		s.MinLeaderSlots = 8
+	}
+}
+
+// Validate validates the skip rate configuration
+func (s *SkipRate) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+
+	if s.ThresholdPercent <= 0 || s.ThresholdPercent >= 100 {
+		return fmt.Errorf("validator.skip_rate.threshold_percent must be between 0 and 100")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipRate_SetDefaults(t *testing.T) {
+	skipRate := &SkipRate{}
+	skipRate.SetDefaults()
+
+	assert.Equal(t, float64(10), skipRate.ThresholdPercent)
+	assert.Equal(t, uint64(8), skipRate.MinLeaderSlots)
+}
+
+func TestSkipRate_Validate(t *testing.T) {
+	// disabled is always valid
+	skipRate := &SkipRate{ThresholdPercent: -1}
+	assert.NoError(t, skipRate.Validate())
+
+	// enabled with defaults is valid
+	skipRate = &SkipRate{Enabled: true}
+	skipRate.SetDefaults()
+	assert.NoError(t, skipRate.Validate())
+
+	// threshold out of range
+	for _, threshold := range []float64{-5, 100, 150} {
+		skipRate.ThresholdPercent = threshold
+		err := skipRate.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "validator.skip_rate.threshold_percent must be between 0 and 100")
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// SnapshotRecovery represents the configuration for recovering a lagging passive node by fetching a fresh snapshot
+type SnapshotRecovery struct {
+	// Enabled runs the command when a passive node stays too far behind the cluster
+	Enabled bool `koanf:"enabled"`
+	// MaxSlotsBehind is how many slots the local validator may trail the cluster before it is considered lagging
+	MaxSlotsBehind uint64 `koanf:"max_slots_behind"`
+	// SamplesThreshold is the number of consecutive lagging samples before the command is run
+	SamplesThreshold int `koanf:"samples_threshold"`
+	// CooldownDuration is the minimum time between command runs
+	CooldownDuration time.Duration     `koanf:"cooldown_duration"`
+	Command          string            `koanf:"command"`
+	Args             []string          `koanf:"args"`
+	Env              map[string]string `koanf:"env"`
+	// Resources the command touches - defaults to the validator, so it never interleaves with role commands
+	Resources []string `koanf:"resources"`
+	// ClearEnv runs the command with only PassEnv and Env instead of the manager's environment
+	ClearEnv bool `koanf:"clear_env"`
+	// PassEnv are the manager's environment variables still passed with ClearEnv, e.g. PATH
+	PassEnv []string `koanf:"pass_env"`
+}
+
+// SetDefaults sets default values for the snapshot recovery configuration
+func (s *SnapshotRecovery) SetDefaults() {
+	if s.MaxSlotsBehind == 0 {
+		s.MaxSlotsBehind = 5000
+	}
+	if s.SamplesThreshold == 0 {
+		s.SamplesThreshold = 12 // 12 x poll interval = (at least) 1 minute
+	}
+	if s.CooldownDuration == 0 {
+		s.CooldownDuration = time.Hour
+	}
+	if len(s.Resources) == 0 {
+		s.Resources = []string{defaultCommandResource}
+	}
+}
+
+// Validate validates the snapshot recovery configuration
+func (s *SnapshotRecovery) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+
+	if s.Command == "" {
+		return fmt.Errorf("failover.snapshot_recovery.command must be defined when enabled")
+	}
+
+	if s.SamplesThreshold <= 0 {
+		return fmt.Errorf("failover.snapshot_recovery.samples_threshold must be positive and non-zero")
+	}
+
+	if s.CooldownDuration < 0 {
+		return fmt.Errorf("failover.snapshot_recovery.cooldown_duration must be positive")
+	}
+
+	if err := validateResources(s.Resources); err != nil {
+		return fmt.Errorf("failover.snapshot_recovery.%w", err)
+	}
+
+	return nil
+}
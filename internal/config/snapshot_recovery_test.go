@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRecovery_SetDefaults(t *testing.T) {
+	snapshotRecovery := &SnapshotRecovery{}
+	snapshotRecovery.SetDefaults()
+
+	assert.Equal(t, uint64(5000), snapshotRecovery.MaxSlotsBehind)
+	assert.Equal(t, 12, snapshotRecovery.SamplesThreshold)
+	assert.Equal(t, time.Hour, snapshotRecovery.CooldownDuration)
+}
+
+func TestSnapshotRecovery_Validate(t *testing.T) {
+	// disabled is always valid
+	snapshotRecovery := &SnapshotRecovery{}
+	assert.NoError(t, snapshotRecovery.Validate())
+
+	// enabled requires a command
+	snapshotRecovery.Enabled = true
+	snapshotRecovery.SetDefaults()
+	err := snapshotRecovery.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.snapshot_recovery.command must be defined when enabled")
+
+	snapshotRecovery.Command = "fetch-snapshot.sh"
+	assert.NoError(t, snapshotRecovery.Validate())
+
+	// Test with negative samples threshold
+	snapshotRecovery.SamplesThreshold = -1
+	err = snapshotRecovery.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.snapshot_recovery.samples_threshold must be positive and non-zero")
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+)
+
+// SSH represents the configuration for running hooks on peer hosts over SSH
+type SSH struct {
+	User    string `koanf:"user"`
+	Port    int    `koanf:"port"`
+	KeyFile string `koanf:"key_file"`
+	// KnownHostsFile is used to verify remote host keys
+	KnownHostsFile string `koanf:"known_hosts_file"`
+	// InsecureIgnoreHostKey skips host key verification - only use for testing
+	InsecureIgnoreHostKey bool `koanf:"insecure_ignore_host_key"`
+	// AllowedHosts are hosts commands may run on in addition to failover.peers
+	AllowedHosts           []string      `koanf:"allowed_hosts"`
+	ConnectTimeoutDuration time.Duration `koanf:"connect_timeout_duration"`
+	// TimeoutDuration is the maximum time a remote command may run for
+	TimeoutDuration time.Duration `koanf:"timeout_duration"`
+}
+
+// SetDefaults sets default values for the SSH configuration
+func (s *SSH) SetDefaults() {
+	if s.Port == 0 {
+		s.Port = 22
+	}
+	if s.ConnectTimeoutDuration == 0 {
+		s.ConnectTimeoutDuration = 10 * time.Second
+	}
+	if s.TimeoutDuration == 0 {
+		s.TimeoutDuration = 5 * time.Minute
+	}
+}
+
+// Validate validates the SSH configuration - only required when a hook runs on a remote host
+func (s *SSH) Validate() error {
+	if s.User == "" {
+		return fmt.Errorf("failover.ssh.user must be defined when hooks run on a remote host")
+	}
+
+	if s.KeyFile == "" {
+		return fmt.Errorf("failover.ssh.key_file must be defined when hooks run on a remote host")
+	}
+
+	if s.KnownHostsFile == "" && !s.InsecureIgnoreHostKey {
+		return fmt.Errorf("failover.ssh.known_hosts_file must be defined when hooks run on a remote host")
+	}
+
+	if s.Port < 0 || s.ConnectTimeoutDuration < 0 || s.TimeoutDuration < 0 {
+		return fmt.Errorf("failover.ssh.port, connect_timeout_duration and timeout_duration must be positive")
+	}
+
+	return nil
+}
+
+// AllowsHost returns true if host is a peer name, peer IP or in failover.ssh.allowed_hosts
+func (s *SSH) AllowsHost(host string, peers Peers) bool {
+	return slices.Contains(s.allowedHosts(peers), s.resolveHost(host, peers))
+}
+
+// RemoteOptions returns the options for running a command on host, which may be a failover.peers name
+func (s *SSH) RemoteOptions(host string, peers Peers) command.RemoteOptions {
+	return command.RemoteOptions{
+		Host:                  s.resolveHost(host, peers),
+		Port:                  s.Port,
+		User:                  s.User,
+		KeyFile:               s.KeyFile,
+		KnownHostsFile:        s.KnownHostsFile,
+		InsecureIgnoreHostKey: s.InsecureIgnoreHostKey,
+		AllowedHosts:          s.allowedHosts(peers),
+		ConnectTimeout:        s.ConnectTimeoutDuration,
+		Timeout:               s.TimeoutDuration,
+	}
+}
+
+// resolveHost returns the peer IP if host is a peer name, else host
+func (s *SSH) resolveHost(host string, peers Peers) string {
+	if peer, ok := peers[host]; ok {
+		return peer.IP
+	}
+	return host
+}
+
+// allowedHosts returns the peer IPs and failover.ssh.allowed_hosts
+func (s *SSH) allowedHosts(peers Peers) []string {
+	return append(peers.GetIPs(), s.AllowedHosts...)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSH_SetDefaults(t *testing.T) {
+	ssh := &SSH{}
+	ssh.SetDefaults()
+
+	assert.Equal(t, 22, ssh.Port)
+	assert.Equal(t, 10*time.Second, ssh.ConnectTimeoutDuration)
+	assert.Equal(t, 5*time.Minute, ssh.TimeoutDuration)
+}
+
+func TestSSH_RemoteOptions(t *testing.T) {
+	ssh := &SSH{User: "sol", KeyFile: "/home/sol/.ssh/id_ed25519", AllowedHosts: []string{"fence.example.com"}}
+	ssh.SetDefaults()
+	peers := Peers{"backup": {Name: "backup", IP: "192.168.1.11"}}
+
+	// peer names resolve to their IP
+	remote := ssh.RemoteOptions("backup", peers)
+	assert.Equal(t, "192.168.1.11", remote.Host)
+	assert.Equal(t, "sol", remote.User)
+	assert.ElementsMatch(t, []string{"192.168.1.11", "fence.example.com"}, remote.AllowedHosts)
+
+	assert.True(t, ssh.AllowsHost("backup", peers))
+	assert.True(t, ssh.AllowsHost("192.168.1.11", peers))
+	assert.True(t, ssh.AllowsHost("fence.example.com", peers))
+	assert.False(t, ssh.AllowsHost("10.0.0.1", peers))
+}
+
+func TestFailover_ValidateRemoteHooks(t *testing.T) {
+	failover := &Failover{
+		Peers: Peers{"backup": {Name: "backup", IP: "192.168.1.11"}},
+		Active: Role{
+			Hooks: Hooks{Pre: []Hook{{Name: "fence", Command: "fence.sh", Host: "backup"}}},
+		},
+	}
+	failover.SetDefaults()
+
+	// Test without ssh config
+	err := failover.validateRemoteHooks()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.ssh.user must be defined")
+
+	// Test with ssh config
+	failover.SSH.User = "sol"
+	failover.SSH.KeyFile = "/home/sol/.ssh/id_ed25519"
+	failover.SSH.KnownHostsFile = "/home/sol/.ssh/known_hosts"
+	assert.NoError(t, failover.validateRemoteHooks())
+
+	// Test with host not allowed
+	failover.Active.Hooks.Pre[0].Host = "10.0.0.1"
+	err = failover.validateRemoteHooks()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failover.active.hooks.pre[0]: host 10.0.0.1 must be a failover.peers name or IP")
+}
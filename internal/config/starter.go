@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/template"
+)
+
+// StarterPeer is a peer declared in a starter config
+type StarterPeer struct {
+	Name string
+	IP   string
+	// Placeholder marks the peer as one to replace
+	Placeholder bool
+}
+
+// StarterOptions are the settings a starter config is generated with
+type StarterOptions struct {
+	// Client is the validator client the role commands manage - agave or firedancer
+	Client string
+	// ClusterName is the cluster.name
+	ClusterName string
+	// ValidatorName is the validator.name
+	ValidatorName string
+	// ActiveIdentityFile and PassiveIdentityFile are the validator.identities keypair paths
+	ActiveIdentityFile  string
+	PassiveIdentityFile string
+	// Peers are the failover.peers - placeholders to replace when generated from a preset
+	Peers []StarterPeer
+	// SetRoleScript is the path of the script the role commands run, see example-scripts/ha-set-role.sh
+	SetRoleScript string
+}
+
+// starterDefaults are the settings every starter preset shares
+var starterDefaults = StarterOptions{
+	ValidatorName:       "validator-1",
+	ActiveIdentityFile:  "/home/solana/active-identity.json",
+	PassiveIdentityFile: "/home/solana/passive-identity.json",
+	Peers: []StarterPeer{
+		// documentation (TEST-NET-1) addresses
+		{Name: "validator-2", IP: "192.0.2.2", Placeholder: true},
+		{Name: "validator-3", IP: "192.0.2.3", Placeholder: true},
+	},
+	SetRoleScript: "/home/solana/solana-validator-ha/scripts/ha-set-role.sh",
+}
+
+// starterPresets are the starter config presets by name
+var starterPresets = map[string]StarterOptions{
+	"agave-mainnet":      starterPreset("agave", "mainnet-beta"),
+	"agave-testnet":      starterPreset("agave", "testnet"),
+	"firedancer-mainnet": starterPreset("firedancer", "mainnet-beta"),
+	"firedancer-testnet": starterPreset("firedancer", "testnet"),
+}
+
+// starterPreset returns the starter defaults for client on cluster
+func starterPreset(client string, clusterName string) StarterOptions {
+	opts := starterDefaults
+	opts.Client = client
+	opts.ClusterName = clusterName
+	return opts
+}
+
+// StarterPresetNames returns the names of the starter config presets, sorted
+func StarterPresetNames() []string {
+	return slices.Sorted(maps.Keys(starterPresets))
+}
+
+// StarterPreset returns the options of the named starter config preset
+func StarterPreset(name string) (StarterOptions, error) {
+	opts, ok := starterPresets[name]
+	if !ok {
+		return StarterOptions{}, fmt.Errorf("unknown preset %s - must be one of %v", name, StarterPresetNames())
+	}
+	opts.Peers = slices.Clone(opts.Peers)
+	return opts, nil
+}
+
+// starterTemplate is the starter config - it uses [[ ]] delimiters so the role command templates are written as-is
+var starterTemplate = template.Must(template.New("starter").Delims("[[", "]]").Parse(`# solana-validator-ha config generated by solana-validator-ha config init
+# Review every setting before running it - see the README for all the options.
+# Check it with: solana-validator-ha config validate --strict
+
+# version - the config format version, older versions are migrated with a warning on load
+version: ` + strconv.Itoa(CurrentVersion) + `
+
+log:
+  level: info
+  format: text
+
+validator:
+  # name - vanity name of this validator, used in logs, metrics and notifications
+  name: [[ printf "%q" .ValidatorName ]]
+  rpc_url: http://localhost:8899
+  identities:
+    # active - the keypair shared by all peers, passive - the keypair unique to this validator
+    active: [[ printf "%q" .ActiveIdentityFile ]]
+    passive: [[ printf "%q" .PassiveIdentityFile ]]
+
+cluster:
+  name: [[ .ClusterName ]]
+  # rpc_urls - private RPC URLs avoid public endpoint rate limits, the cluster's public endpoint is used when empty
+  rpc_urls: []
+
+prometheus:
+  port: 9090
+  health_check_port: 9091
+
+failover:
+  # dry_run - log the commands each failover would run without running them, start with this until confident
+  dry_run: true
+  poll_interval_duration: 5s
+  leaderless_samples_threshold: 3
+  takeover_jitter_duration: 3s
+
+  # peers - every other validator in this HA set, by name, with its public IP as seen in gossip
+  peers:
+[[- range .Peers ]]
+    [[ .Name ]]:
+      ip: [[ .IP ]][[ if .Placeholder ]] # placeholder - replace with the peer's public IP[[ end ]]
+[[- end ]]
+
+  # active - sets the shared active identity on this validator when the cluster has no active peer
+  active:
+    command: [[ printf "%q" .SetRoleScript ]]
+    args: [
+      "--role", "active",
+      "--client", "[[ .Client ]]",
+      "--rpc-url", "http://127.0.0.1:8899",
+      "--identity-keyfile", "{{ .ActiveIdentityKeypairFile }}",
+      "--tower-file", "/mnt/ledger/tower-1_9-{{ .ActiveIdentityPubkey }}.bin",
+    ]
+
+  # passive - must make sure this validator is not voting, however it has to
+  passive:
+    command: [[ printf "%q" .SetRoleScript ]]
+    args: [
+      "--role", "passive",
+      "--client", "[[ .Client ]]",
+      "--rpc-url", "http://127.0.0.1:8899",
+      "--identity-keyfile", "{{ .PassiveIdentityKeypairFile }}",
+      "--tower-file", "/mnt/ledger/tower-1_9-{{ .ActiveIdentityPubkey }}.bin",
+    ]
+
+# notifications - uncomment and enable the services to alert. Secrets are read from the *_env environment variables.
+notifications:
+  enabled: false
+  # discord:
+  #   enabled: true
+  #   webhook_url_env: DISCORD_WEBHOOK_URL
+  # telegram:
+  #   enabled: true
+  #   bot_token_env: TELEGRAM_BOT_TOKEN
+  #   chat_id: "-1001234567890"
+  # slack:
+  #   enabled: true
+  #   webhook_url_env: SLACK_WEBHOOK_URL
+  # pagerduty:
+  #   enabled: true
+  #   routing_key_env: PAGERDUTY_ROUTING_KEY
+`))
+
+// RenderStarter returns a starter config file for opts, commented for new operators
+func RenderStarter(opts StarterOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := starterTemplate.Execute(&buf, opts); err != nil {
+		return nil, fmt.Errorf("failed to render starter config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteStarter writes a starter config for opts to path, returning the path written - an existing file is only
+// replaced when overwrite is set
+func WriteStarter(path string, opts StarterOptions, overwrite bool) (string, error) {
+	content, err := RenderStarter(opts)
+	if err != nil {
+		return "", err
+	}
+
+	resolvedPath, err := resolveConfigPath(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(resolvedPath); err == nil && !overwrite {
+		return "", fmt.Errorf("config file %s already exists", resolvedPath)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(resolvedPath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(resolvedPath, content, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+	return resolvedPath, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStarterPresets_Validate(t *testing.T) {
+	for _, name := range StarterPresetNames() {
+		t.Run(name, func(t *testing.T) {
+			opts, err := StarterPreset(name)
+			require.NoError(t, err)
+
+			path, err := WriteStarter(filepath.Join(t.TempDir(), "config.yaml"), opts, false)
+			require.NoError(t, err)
+
+			cfg, errs := Check(context.Background(), path, CheckOptions{SkipIdentities: true, SkipCommands: true, Strict: true})
+			assert.Empty(t, errs)
+			assert.Equal(t, opts.ClusterName, cfg.Cluster.Name)
+			assert.True(t, cfg.Failover.DryRun)
+			assert.Len(t, cfg.Failover.Peers, 2)
+			assert.Contains(t, cfg.Failover.Active.Args, opts.Client)
+		})
+	}
+}
+
+func TestStarterPreset_Unknown(t *testing.T) {
+	_, err := StarterPreset("solana-labs-mainnet")
+	assert.ErrorContains(t, err, "unknown preset solana-labs-mainnet")
+}
+
+func TestRenderStarter(t *testing.T) {
+	opts, err := StarterPreset("agave-mainnet")
+	require.NoError(t, err)
+	opts.ValidatorName = "primary"
+	opts.Peers = []StarterPeer{{Name: "backup", IP: "10.0.0.2"}}
+
+	content, err := RenderStarter(opts)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "name: \"primary\"")
+	assert.Contains(t, string(content), "    backup:\n      ip: 10.0.0.2\n")
+	assert.NotContains(t, string(content), "placeholder")
+	// role command templates are written for the manager to render
+	assert.Contains(t, string(content), "{{ .ActiveIdentityKeypairFile }}")
+
+	// placeholder peers are marked for replacing
+	opts.Peers = starterDefaults.Peers
+	content, err = RenderStarter(opts)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ip: 192.0.2.2 # placeholder - replace with the peer's public IP")
+}
+
+func TestWriteStarter_Overwrite(t *testing.T) {
+	opts, err := StarterPreset("agave-testnet")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml")
+
+	_, err = WriteStarter(path, opts, false)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = WriteStarter(path, opts, false)
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = WriteStarter(path, opts, true)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/knadh/koanf"
+	"github.com/mitchellh/mapstructure"
+)
+
+// UnknownKeys returns the config keys that match no setting, sorted - a misspelt key leaves its setting at
+// the default, so they are rejected in strict mode and warned about otherwise
+func (c *Config) UnknownKeys() []string {
+	return c.unknownKeys
+}
+
+// unmarshal unmarshals k into the config as koanf does by default, recording the keys that match no field
+func (c *Config) unmarshal(k *koanf.Koanf) error {
+	metadata := &mapstructure.Metadata{}
+	err := k.UnmarshalWithConf("", c, koanf.UnmarshalConf{
+		DecoderConfig: &mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToSliceHookFunc(","),
+				mapstructure.TextUnmarshallerHookFunc()),
+			Metadata:         metadata,
+			Result:           c,
+			WeaklyTypedInput: true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	c.unknownKeys = slices.Sorted(slices.Values(metadata.Unused))
+	return nil
+}
+
+// validateKeys rejects unknown keys in strict mode
+func (c *Config) validateKeys() error {
+	if !c.Strict || len(c.unknownKeys) == 0 {
+		return nil
+	}
+	return fmt.Errorf("strict mode rejects unknown config keys - check for typos: %s", strings.Join(c.unknownKeys, ", "))
+}
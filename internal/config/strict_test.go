@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_UnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{"config.yaml": "failover:\n  leaderless_treshold_duration: 10s\n  peers:\n    validator-1:\n      ip: 192.168.1.10\n      prot: 9\nlog:\n  level: debug\n"})
+
+	cfg := &Config{}
+	require.NoError(t, cfg.LoadFromFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, []string{"failover.leaderless_treshold_duration", "failover.peers[validator-1].prot"}, cfg.UnknownKeys())
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.NoError(t, cfg.validateKeys())
+
+	cfg.Strict = true
+	assert.EqualError(t, cfg.validateKeys(),
+		"strict mode rejects unknown config keys - check for typos: failover.leaderless_treshold_duration, failover.peers[validator-1].prot")
+}
+
+func TestNewFromConfigFile_Strict(t *testing.T) {
+	configFile := createTempConfigFile(t)
+	defer os.Remove(configFile)
+
+	// the test config sets the unknown failover.leaderless_threshold_duration, which is ignored unless strict
+	cfg, err := NewFromConfigFile(context.Background(), configFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"failover.leaderless_threshold_duration"}, cfg.UnknownKeys())
+
+	content, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configFile, append([]byte("strict: true\n"), content...), 0o600))
+	_, err = NewFromConfigFile(context.Background(), configFile)
+	assert.ErrorContains(t, err, "strict mode rejects unknown config keys - check for typos: failover.leaderless_threshold_duration")
+}
+
+func TestCheck_Strict(t *testing.T) {
+	configFile := createTempConfigFile(t)
+	defer os.Remove(configFile)
+
+	_, errs := Check(context.Background(), configFile, CheckOptions{})
+	assert.Empty(t, errs)
+
+	_, errs = Check(context.Background(), configFile, CheckOptions{Strict: true})
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "failover.leaderless_threshold_duration")
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TakeoverQuorum represents the configuration for requiring peers and witnesses to agree the active peer is down
+// before an automatic takeover, so a passive peer cut off from the network doesn't promote itself into a split brain
+type TakeoverQuorum struct {
+	Enabled bool `koanf:"enabled"`
+	// Quorum is how many of failover.peers and Witnesses must agree the active peer is down - we don't count
+	Quorum int `koanf:"quorum"`
+	// Witnesses are the health check server URLs of solana-validator-ha daemons run for this validator outside
+	// failover.peers, which vote from their own view of gossip
+	Witnesses []string `koanf:"witnesses"`
+	// VoteTimeoutDuration is how long to wait for votes - voters that don't answer in time don't agree
+	VoteTimeoutDuration time.Duration `koanf:"vote_timeout_duration"`
+}
+
+// SetDefaults sets default values for the takeover quorum configuration
+func (q *TakeoverQuorum) SetDefaults() {
+	if q.Quorum == 0 {
+		q.Quorum = 1
+	}
+	if q.VoteTimeoutDuration == 0 {
+		q.VoteTimeoutDuration = 5 * time.Second
+	}
+}
+
+// Validate validates the takeover quorum configuration - quorum must be reachable with peers and the witnesses,
+// unless more peers may be discovered in failover.peer_registry
+func (q *TakeoverQuorum) Validate(peers Peers, peerRegistryEnabled bool) error {
+	if !q.Enabled {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, witness := range q.Witnesses {
+		parsedURL, err := url.Parse(witness)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			return fmt.Errorf("failover.takeover_quorum.witnesses must be a list of health check server URLs: invalid URL %s", witness)
+		}
+		if seen[witness] {
+			return fmt.Errorf("failover.takeover_quorum.witnesses must be unique: %s is listed more than once", witness)
+		}
+		seen[witness] = true
+	}
+
+	voters := len(peers) + len(q.Witnesses)
+	if q.Quorum < 1 || (q.Quorum > voters && !peerRegistryEnabled) {
+		return fmt.Errorf("failover.takeover_quorum.quorum must be between 1 and the number of failover.peers and witnesses (%d)", voters)
+	}
+
+	if q.VoteTimeoutDuration <= 0 {
+		return fmt.Errorf("failover.takeover_quorum.vote_timeout_duration must be greater than zero")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeoverQuorum_SetDefaults(t *testing.T) {
+	quorum := &TakeoverQuorum{}
+	quorum.SetDefaults()
+
+	assert.Equal(t, 1, quorum.Quorum)
+	assert.Equal(t, 5*time.Second, quorum.VoteTimeoutDuration)
+}
+
+func TestTakeoverQuorum_Validate(t *testing.T) {
+	peers := Peers{"peer1": {Name: "peer1", IP: "192.168.1.101"}}
+
+	tests := []struct {
+		name     string
+		modify   func(*TakeoverQuorum)
+		registry bool
+		err      string
+	}{
+		{"disabled", func(q *TakeoverQuorum) { q.Enabled = false; q.Quorum = 5 }, false, ""},
+		{"peer and witness", func(q *TakeoverQuorum) { q.Quorum = 2 }, false, ""},
+		{"more than voters", func(q *TakeoverQuorum) { q.Quorum = 3 }, false, "failover.takeover_quorum.quorum must be between 1 and the number of failover.peers and witnesses (2)"},
+		{"more than voters with peer registry", func(q *TakeoverQuorum) { q.Quorum = 3 }, true, ""},
+		{"negative quorum", func(q *TakeoverQuorum) { q.Quorum = -1 }, false, "failover.takeover_quorum.quorum must be between 1"},
+		{"invalid witness", func(q *TakeoverQuorum) { q.Witnesses = []string{"witness:9091"} }, false, "invalid URL witness:9091"},
+		{"duplicate witness", func(q *TakeoverQuorum) {
+			q.Witnesses = append(q.Witnesses, q.Witnesses[0])
+		}, false, "failover.takeover_quorum.witnesses must be unique"},
+		{"negative vote timeout", func(q *TakeoverQuorum) { q.VoteTimeoutDuration = -time.Second }, false, "failover.takeover_quorum.vote_timeout_duration must be greater than zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quorum := &TakeoverQuorum{Enabled: true, Witnesses: []string{"https://witness.example.org:9091"}}
+			quorum.SetDefaults()
+			tt.modify(quorum)
+			err := quorum.Validate(peers, tt.registry)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
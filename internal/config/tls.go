@@ -0,0 +1,71 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// NotificationTLS configures the TLS a notifier posts events with, for HTTPS endpoints signed by a private CA or
+// requiring a client certificate
+type NotificationTLS struct {
+	// CAFile is a PEM bundle of CA certificates trusted in addition to the system roots
+	CAFile string `koanf:"ca_file"`
+	// CertFile and KeyFile are the PEM client certificate and key presented for mutual TLS
+	CertFile string `koanf:"cert_file"`
+	KeyFile  string `koanf:"key_file"`
+}
+
+// IsSet returns true if any TLS option is set
+func (t NotificationTLS) IsSet() bool {
+	return t.CAFile != "" || t.CertFile != "" || t.KeyFile != ""
+}
+
+// Validate validates the TLS options, loading the configured files - key is the config key, e.g. notifications.slack.tls
+func (t NotificationTLS) Validate(key string) error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("%s.cert_file and key_file must be set together", key)
+	}
+
+	if _, err := t.ClientConfig(); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	return nil
+}
+
+// ClientConfig returns the TLS client config - nil when no TLS option is set
+func (t NotificationTLS) ClientConfig() (*tls.Config, error) {
+	if !t.IsSet() {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if t.CAFile != "" {
+		caPEM, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_file: %w", err)
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("ca_file %s contains no PEM certificates", t.CAFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cert_file and key_file: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
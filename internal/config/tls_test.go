@@ -0,0 +1,93 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed PEM certificate and key, returning their paths
+func writeTestCertificate(t *testing.T, dir string) (certFile string, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "solana-validator-ha test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestNotificationTLS_ClientConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	tlsConfig, err := NotificationTLS{}.ClientConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "no options should use the default tls config")
+
+	tlsConfig, err = NotificationTLS{CAFile: certFile, CertFile: certFile, KeyFile: keyFile}.ClientConfig()
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+}
+
+func TestNotificationTLS_Validate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name        string
+		tls         NotificationTLS
+		errContains string
+	}{
+		{name: "unset", tls: NotificationTLS{}},
+		{name: "ca and client cert", tls: NotificationTLS{CAFile: certFile, CertFile: certFile, KeyFile: keyFile}},
+		{name: "cert without key", tls: NotificationTLS{CertFile: certFile}, errContains: "notifications.slack.tls.cert_file and key_file must be set together"},
+		{name: "missing ca file", tls: NotificationTLS{CAFile: filepath.Join(dir, "missing.pem")}, errContains: "notifications.slack.tls: failed to read ca_file"},
+		{name: "ca file without certificates", tls: NotificationTLS{CAFile: notPEM}, errContains: "contains no PEM certificates"},
+		{name: "mismatched key", tls: NotificationTLS{CertFile: certFile, KeyFile: certFile}, errContains: "failed to load cert_file and key_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tls.Validate("notifications.slack.tls")
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
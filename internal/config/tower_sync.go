@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TowerSync represents the configuration for copying the tower file from the old active peer before we take over,
+// so we never vote from a tower older than the votes it already cast - a stale tower risks duplicate votes
+type TowerSync struct {
+	Enabled bool `koanf:"enabled"`
+	// Path is the tower file, the same on every peer - a template of {{ .ActiveIdentityPubkey }}
+	Path string `koanf:"path"`
+	// MaxAgeDuration fails the copy if the old active peer's tower was last written longer ago than this - zero
+	// accepts a tower of any age
+	MaxAgeDuration time.Duration `koanf:"max_age_duration"`
+	// TimeoutDuration is the most each attempt at copying the tower may take
+	TimeoutDuration time.Duration `koanf:"timeout_duration"`
+	// Retries is how many more times a failed copy is attempted, e.g. as the tower was written while being read
+	Retries int `koanf:"retries"`
+	// MustSucceed aborts the takeover unless the tower is copied, or ours is already as new
+	MustSucceed bool `koanf:"must_succeed"`
+}
+
+// SetDefaults sets default values for the tower sync configuration
+func (t *TowerSync) SetDefaults() {
+	if t.TimeoutDuration == 0 {
+		t.TimeoutDuration = 30 * time.Second
+	}
+}
+
+// Validate validates the tower sync configuration - the tower is copied over failover.ssh, which must be valid
+func (t *TowerSync) Validate(ssh *SSH) error {
+	if !t.Enabled {
+		return nil
+	}
+
+	if t.Path == "" {
+		return fmt.Errorf("failover.tower_sync.path must be set")
+	}
+	path, err := t.TowerPath("ActiveIdentityPubkey")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("failover.tower_sync.path must be absolute - got: %s", t.Path)
+	}
+
+	if t.MaxAgeDuration < 0 || t.TimeoutDuration <= 0 || t.Retries < 0 {
+		return fmt.Errorf("failover.tower_sync.max_age_duration and retries must not be negative, and timeout_duration must be greater than zero")
+	}
+
+	if err := ssh.Validate(); err != nil {
+		return fmt.Errorf("failover.tower_sync copies the tower over failover.ssh: %w", err)
+	}
+
+	return nil
+}
+
+// TowerPath returns the tower file path for the active identity pubkey
+func (t *TowerSync) TowerPath(activeIdentityPubkey string) (string, error) {
+	tmpl, err := template.New("path").Option("missingkey=error").Parse(t.Path)
+	if err != nil {
+		return "", fmt.Errorf("failover.tower_sync.path: failed to parse template: %w", err)
+	}
+
+	var path strings.Builder
+	if err := tmpl.Execute(&path, struct{ ActiveIdentityPubkey string }{activeIdentityPubkey}); err != nil {
+		return "", fmt.Errorf("failover.tower_sync.path: failed to execute template: %w", err)
+	}
+	return path.String(), nil
+}
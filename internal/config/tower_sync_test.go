@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTowerSync_Validate(t *testing.T) {
+	ssh := &SSH{User: "sol", KeyFile: "/home/sol/.ssh/id_ed25519", KnownHostsFile: "/home/sol/.ssh/known_hosts"}
+	ssh.SetDefaults()
+
+	towerSync := &TowerSync{}
+	assert.NoError(t, towerSync.Validate(&SSH{}))
+
+	towerSync = &TowerSync{Enabled: true, Path: "/mnt/ledger/tower-1_9-{{ .ActiveIdentityPubkey }}.bin"}
+	towerSync.SetDefaults()
+	assert.Equal(t, 30*time.Second, towerSync.TimeoutDuration)
+	assert.NoError(t, towerSync.Validate(ssh))
+
+	path, err := towerSync.TowerPath("7Np41oeYqPefeNQEHSv1UDhYrehxin3NStELsSKCT4K2")
+	require.NoError(t, err)
+	assert.Equal(t, "/mnt/ledger/tower-1_9-7Np41oeYqPefeNQEHSv1UDhYrehxin3NStELsSKCT4K2.bin", path)
+
+	assert.ErrorContains(t, towerSync.Validate(&SSH{}), "failover.tower_sync copies the tower over failover.ssh: failover.ssh.user must be defined")
+
+	towerSync.Path = "tower-1_9-{{ .ActiveIdentityPubkey }}.bin"
+	assert.EqualError(t, towerSync.Validate(ssh), "failover.tower_sync.path must be absolute - got: tower-1_9-{{ .ActiveIdentityPubkey }}.bin")
+	towerSync.Path = "/mnt/ledger/tower-1_9-{{ .Pubkey }}.bin"
+	assert.ErrorContains(t, towerSync.Validate(ssh), "failover.tower_sync.path: failed to execute template")
+	towerSync.Path = ""
+	assert.EqualError(t, towerSync.Validate(ssh), "failover.tower_sync.path must be set")
+
+	towerSync.Path = "/mnt/ledger/tower.bin"
+	towerSync.Retries = -1
+	assert.ErrorContains(t, towerSync.Validate(ssh), "failover.tower_sync.max_age_duration and retries must not be negative")
+}
@@ -1,15 +1,16 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/charmbracelet/log"
 	solanago "github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-ha/internal/client"
 )
 
 var publicIPServices = []string{
@@ -25,25 +26,66 @@ type Validator struct {
 	RPCURL              string              `koanf:"rpc_url"`
 	PublicIPServiceURLs []string            `koanf:"public_ip_service_urls"`
 	Identities          ValidatorIdentities `koanf:"identities"`
+	VoteAccountWatch    VoteAccountWatch    `koanf:"vote_account_watch"`
+	VoteCredits         VoteCredits         `koanf:"vote_credits"`
+	SkipRate            SkipRate            `koanf:"skip_rate"`
+	IdentityWatchdog    IdentityWatchdog    `koanf:"identity_watchdog"`
+	Health              Health              `koanf:"health"`
+	// Priority ranks this validator among failover.peers in takeover races - the highest priority wins
+	Priority int `koanf:"priority"`
+	// Client is the validator client - agave, jito-solana or firedancer - detected when empty. Its set-identity
+	// flow is the failover.active and failover.passive command when they are not set.
+	Client string `koanf:"client"`
+	// ClientCommand is the client's CLI - agave-validator or fdctl when empty
+	ClientCommand string `koanf:"client_command"`
+	// LedgerDir is the ledger directory agave and jito-solana are set the identity of through
+	LedgerDir string `koanf:"ledger_dir"`
+	// FdctlConfig is the config.toml firedancer runs with
+	FdctlConfig string `koanf:"fdctl_config"`
 }
 
-// ValidatorIdentities represents the identities for the validator
+// IdentityWatchdog represents the configuration for checking a passive node is not using the active identity
+type IdentityWatchdog struct {
+	Enabled bool `koanf:"enabled"`
+	// AutoCorrect runs the failover.passive command when the running validator reports the active identity
+	AutoCorrect bool `koanf:"auto_correct"`
+}
+
+// ValidatorIdentities represents the identities for the validator - the key pair files are paths, or references to
+// them in AWS Secrets Manager or SSM Parameter Store, kept as configured so they are shown as such
 type ValidatorIdentities struct {
 	ActiveKeyPairFile  string               `koanf:"active"`
 	ActiveKeyPair      *solanago.PrivateKey `koanf:"-"`
 	PassiveKeyPairFile string               `koanf:"passive"`
 	PassiveKeyPair     *solanago.PrivateKey `koanf:"-"`
+
+	// key pair file paths resolved by Load
+	activeKeyPairPath  string
+	passiveKeyPairPath string
 }
 
-// Load loads the identities from the key pair files
-func (v *ValidatorIdentities) Load() error {
-	activeKeyPair, err := solanago.PrivateKeyFromSolanaKeygenFile(v.ActiveKeyPairFile)
+// Load loads the identities from the key pair files, resolving paths referenced in AWS Secrets Manager or SSM
+// Parameter Store first with ctx
+func (v *ValidatorIdentities) Load(ctx context.Context) error {
+	activeKeyPairPath, err := resolveSecretRef(ctx, v.ActiveKeyPairFile)
+	if err != nil {
+		return fmt.Errorf("failed to resolve validator.identities.active: %w", err)
+	}
+	v.activeKeyPairPath = activeKeyPairPath
+
+	passiveKeyPairPath, err := resolveSecretRef(ctx, v.PassiveKeyPairFile)
+	if err != nil {
+		return fmt.Errorf("failed to resolve validator.identities.passive: %w", err)
+	}
+	v.passiveKeyPairPath = passiveKeyPairPath
+
+	activeKeyPair, err := solanago.PrivateKeyFromSolanaKeygenFile(v.ActiveKeyPairPath())
 	if err != nil {
 		return fmt.Errorf("failed to load active identity file: %w", err)
 	}
 	v.ActiveKeyPair = &activeKeyPair
 
-	passiveKeyPair, err := solanago.PrivateKeyFromSolanaKeygenFile(v.PassiveKeyPairFile)
+	passiveKeyPair, err := solanago.PrivateKeyFromSolanaKeygenFile(v.PassiveKeyPairPath())
 	if err != nil {
 		return fmt.Errorf("failed to load passive identity file: %w", err)
 	}
@@ -52,6 +94,22 @@ func (v *ValidatorIdentities) Load() error {
 	return nil
 }
 
+// ActiveKeyPairPath returns the active key pair file path, resolved by Load if it is referenced in AWS
+func (v *ValidatorIdentities) ActiveKeyPairPath() string {
+	if v.activeKeyPairPath != "" {
+		return v.activeKeyPairPath
+	}
+	return v.ActiveKeyPairFile
+}
+
+// PassiveKeyPairPath returns the passive key pair file path, resolved by Load if it is referenced in AWS
+func (v *ValidatorIdentities) PassiveKeyPairPath() string {
+	if v.passiveKeyPairPath != "" {
+		return v.passiveKeyPairPath
+	}
+	return v.PassiveKeyPairFile
+}
+
 // Validate validates the validator identities, returns an error if the identities are the same
 func (v *ValidatorIdentities) Validate() (err error) {
 	if v.ActiveKeyPair.PublicKey().String() == v.PassiveKeyPair.PublicKey().String() {
@@ -67,6 +125,11 @@ func (v *Validator) Validate() error {
 		return fmt.Errorf("validator.name must be defined")
 	}
 
+	// validator.priority must not be negative
+	if v.Priority < 0 {
+		return fmt.Errorf("validator.priority must not be negative")
+	}
+
 	// validator.rpc_url must be a valid URL
 	if v.RPCURL == "" {
 		return fmt.Errorf("validator.rpc_url must be a valid URL")
@@ -91,6 +154,31 @@ func (v *Validator) Validate() error {
 		}
 	}
 
+	// validator.client must be a known client with its settings
+	if err := v.validateClient(); err != nil {
+		return err
+	}
+
+	// validator.vote_account_watch must be valid
+	if err := v.VoteAccountWatch.Validate(); err != nil {
+		return err
+	}
+
+	// validator.vote_credits must be valid
+	if err := v.VoteCredits.Validate(); err != nil {
+		return err
+	}
+
+	// validator.skip_rate must be valid
+	if err := v.SkipRate.Validate(); err != nil {
+		return err
+	}
+
+	// validator.health must be valid
+	if err := v.Health.Validate(); err != nil {
+		return err
+	}
+
 	// Only validate identities if they've been loaded
 	if v.Identities.ActiveKeyPair != nil && v.Identities.PassiveKeyPair != nil {
 		return v.Identities.Validate()
@@ -99,6 +187,38 @@ func (v *Validator) Validate() error {
 	return nil
 }
 
+// validateClient validates validator.client and the settings its commands need
+func (v *Validator) validateClient() error {
+	if v.Client == "" {
+		return nil
+	}
+
+	if _, err := v.SetIdentityClient(); err != nil {
+		return err
+	}
+	if client.Flavor(v.Client) == client.FlavorFiredancer && v.FdctlConfig == "" {
+		return fmt.Errorf("validator.fdctl_config must be set for validator.client %s", v.Client)
+	}
+	if client.Flavor(v.Client) != client.FlavorFiredancer && v.LedgerDir == "" {
+		return fmt.Errorf("validator.ledger_dir must be set for validator.client %s", v.Client)
+	}
+
+	return nil
+}
+
+// SetIdentityClient returns the validator.client whose set-identity flow sets the roles
+func (v *Validator) SetIdentityClient() (client.Client, error) {
+	setIdentityClient, err := client.New(client.Flavor(v.Client), client.Options{
+		Command:    v.ClientCommand,
+		LedgerDir:  v.LedgerDir,
+		ConfigFile: v.FdctlConfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("validator.%w", err)
+	}
+	return setIdentityClient, nil
+}
+
 // SetDefaults sets default values for the validator configuration
 func (v *Validator) SetDefaults() {
 	// Set default validator RPC URL
@@ -109,6 +229,11 @@ func (v *Validator) SetDefaults() {
 	if len(v.PublicIPServiceURLs) == 0 {
 		v.PublicIPServiceURLs = publicIPServices
 	}
+
+	v.VoteAccountWatch.SetDefaults()
+	v.VoteCredits.SetDefaults()
+	v.SkipRate.SetDefaults()
+	v.Health.SetDefaults()
 }
 
 // PublicIP returns the public IP address of the validator using the public IP service URLs
@@ -137,13 +262,13 @@ func (v *Validator) PublicIP() (string, error) {
 		sanitizedIP = strings.Trim(sanitizedIP, "\"")
 		sanitizedIP = strings.Trim(sanitizedIP, "'")
 
-		// validate the IP address is a valid IPv4 address
-		ip := net.ParseIP(sanitizedIP)
-		if ip == nil || ip.To4() == nil {
-			log.Warn("invalid IPv4 address returned from public IP service", "ip", sanitizedIP, "service_url", publicIPServiceURL)
+		// validate the IP address is a valid IPv4 or IPv6 address
+		ip, err := NormalizeIP(sanitizedIP)
+		if err != nil {
+			log.Warn("invalid IP address returned from public IP service", "ip", sanitizedIP, "service_url", publicIPServiceURL)
 			continue
 		}
-		return sanitizedIP, nil
+		return ip, nil
 	}
 	return "", fmt.Errorf("failed to get public IP from any public IP service URLs: %v", v.PublicIPServiceURLs)
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -31,8 +32,15 @@ func TestValidator_Validate(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "validator.name must be defined")
 
-	// Test with empty RPC URL
+	// Test with negative priority
 	validator.Name = "test-validator"
+	validator.Priority = -1
+	err = validator.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "validator.priority must not be negative")
+
+	// Test with empty RPC URL
+	validator.Priority = 0
 	validator.RPCURL = ""
 	err = validator.Validate()
 	assert.Error(t, err)
@@ -67,7 +75,7 @@ func TestValidatorIdentities_Load(t *testing.T) {
 		PassiveKeyPairFile: passiveIdentityFile,
 	}
 
-	err := identities.Load()
+	err := identities.Load(context.Background())
 	require.NoError(t, err)
 
 	assert.NotNil(t, identities.ActiveKeyPair)
@@ -92,7 +100,7 @@ func TestValidatorIdentities_Validate(t *testing.T) {
 		PassiveKeyPairFile: passiveIdentityFile,
 	}
 
-	err := identities.Load()
+	err := identities.Load(context.Background())
 	require.NoError(t, err)
 
 	// Test with different identities
@@ -105,3 +113,23 @@ func TestValidatorIdentities_Validate(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "validator.identities.active and validator.identities.passive must be different")
 }
+
+func TestValidator_ValidateClient(t *testing.T) {
+	validator := &Validator{Name: "test-validator", RPCURL: "http://localhost:8899", Client: "firedancer"}
+	err := validator.Validate()
+	assert.ErrorContains(t, err, "validator.fdctl_config must be set")
+
+	validator.FdctlConfig = "/home/sol/config.toml"
+	assert.NoError(t, validator.Validate())
+
+	validator.Client = "jito-solana"
+	err = validator.Validate()
+	assert.ErrorContains(t, err, "validator.ledger_dir must be set")
+
+	validator.LedgerDir = "/mnt/ledger"
+	assert.NoError(t, validator.Validate())
+
+	validator.Client = "solana-labs"
+	err = validator.Validate()
+	assert.ErrorContains(t, err, "validator.client must be one of")
+}
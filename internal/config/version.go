@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CurrentVersion is the config format version this release reads - configs without a version are version 1
+const CurrentVersion = 1
+
+// versionKey is the top level key configs declare the format version they are written in with
+const versionKey = "version"
+
+// configMigration moves keys renamed in a config format version - configs declaring an older version have them
+// moved to their new keys with a warning, newer ones have the old keys reported as unknown
+type configMigration struct {
+	// version is the config format version the keys were renamed in
+	version int
+	// renamed maps each deprecated key to its new key, as dotted paths
+	renamed map[string]string
+}
+
+// configMigrations are the key renames of each config format version, oldest first - bump CurrentVersion and add
+// one here when renaming or moving a setting, so existing configs keep loading on upgrade
+var configMigrations = []configMigration{}
+
+// Deprecations returns a warning for each deprecated key migrated when the config was loaded
+func (c *Config) Deprecations() []string {
+	return c.deprecations
+}
+
+// migrate moves the deprecated keys in raw to their current keys and sets its version to CurrentVersion
+func (c *Config) migrate(raw map[string]any) error {
+	deprecations, err := migrateRaw(raw, configMigrations, CurrentVersion)
+	if err != nil {
+		return err
+	}
+	c.deprecations = deprecations
+	return nil
+}
+
+// migrateRaw applies the migrations newer than the version raw declares, up to current, returning a warning for
+// each deprecated key moved
+func migrateRaw(raw map[string]any, migrations []configMigration, current int) ([]string, error) {
+	version := 1
+	if value, ok := raw[versionKey]; ok {
+		parsed, err := strconv.Atoi(fmt.Sprint(value))
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("version must be a positive integer - got: %v", value)
+		}
+		version = parsed
+	}
+	if version > current {
+		return nil, fmt.Errorf("config version %d is newer than the version %d this release reads - upgrade solana-validator-ha", version, current)
+	}
+
+	deprecations := []string{}
+	for _, migration := range migrations {
+		if migration.version <= version {
+			continue
+		}
+		for _, from := range slices.Sorted(maps.Keys(migration.renamed)) {
+			to := migration.renamed[from]
+			value, ok := getRawKey(raw, from)
+			if !ok {
+				continue
+			}
+			if _, ok := getRawKey(raw, to); ok {
+				return nil, fmt.Errorf("%s is deprecated and replaced by %s - set only %s", from, to, to)
+			}
+			deleteRawKey(raw, from)
+			setRawKey(raw, to, value)
+			deprecations = append(deprecations, fmt.Sprintf("%s is deprecated since config version %d - rename it to %s", from, migration.version, to))
+		}
+	}
+
+	raw[versionKey] = current
+	return deprecations, nil
+}
+
+// getRawKey returns the value at the dotted path key in raw
+func getRawKey(raw map[string]any, key string) (any, bool) {
+	parent, name, ok := rawParent(raw, key, false)
+	if !ok {
+		return nil, false
+	}
+	value, ok := parent[name]
+	return value, ok
+}
+
+// setRawKey sets the value at the dotted path key in raw, creating its parent maps as needed
+func setRawKey(raw map[string]any, key string, value any) {
+	if parent, name, ok := rawParent(raw, key, true); ok {
+		parent[name] = value
+	}
+}
+
+// deleteRawKey deletes the value at the dotted path key in raw, and the parent maps it leaves empty
+func deleteRawKey(raw map[string]any, key string) {
+	parent, name, ok := rawParent(raw, key, false)
+	if !ok {
+		return
+	}
+	delete(parent, name)
+	if len(parent) == 0 {
+		if dot := strings.LastIndex(key, "."); dot >= 0 {
+			deleteRawKey(raw, key[:dot])
+		}
+	}
+}
+
+// rawParent returns the map holding the dotted path key in raw and the key's last segment, creating missing maps
+// when create is set
+func rawParent(raw map[string]any, key string, create bool) (map[string]any, string, bool) {
+	segments := strings.Split(key, ".")
+	parent := raw
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := parent[segment].(map[string]any)
+		if !ok {
+			if !create || parent[segment] != nil {
+				return nil, "", false
+			}
+			child = map[string]any{}
+			parent[segment] = child
+		}
+		parent = child
+	}
+	return parent, segments[len(segments)-1], true
+}
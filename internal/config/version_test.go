@@ -0,0 +1,80 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Version(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"unversioned.yaml": "log:\n  level: debug\n",
+		"versioned.yaml":   "version: 1\nlog:\n  level: debug\n",
+		"newer.yaml":       "version: 99\n",
+		"invalid.yaml":     "version: latest\n",
+	})
+
+	// configs without a version are the first version
+	for _, file := range []string{"unversioned.yaml", "versioned.yaml"} {
+		cfg := &Config{}
+		require.NoError(t, cfg.LoadFromFile(filepath.Join(dir, file)))
+		assert.Equal(t, CurrentVersion, cfg.Version)
+		assert.Empty(t, cfg.Deprecations())
+		assert.Empty(t, cfg.UnknownKeys())
+	}
+
+	cfg := &Config{}
+	err := cfg.LoadFromFile(filepath.Join(dir, "newer.yaml"))
+	assert.ErrorContains(t, err, "config version 99 is newer than the version 1 this release reads - upgrade solana-validator-ha")
+
+	err = cfg.LoadFromFile(filepath.Join(dir, "invalid.yaml"))
+	assert.ErrorContains(t, err, "version must be a positive integer - got: latest")
+}
+
+func TestMigrateRaw(t *testing.T) {
+	migrations := []configMigration{
+		{version: 2, renamed: map[string]string{"failover.leaderless_threshold": "failover.leaderless_samples_threshold"}},
+		{version: 3, renamed: map[string]string{
+			"cluster.rpc_demotion_duration": "cluster.rpc_demotion.duration",
+			"prometheus.port":               "prometheus.metrics.port",
+		}},
+	}
+
+	raw := map[string]any{
+		"failover":   map[string]any{"leaderless_threshold": 5, "dry_run": true},
+		"cluster":    map[string]any{"rpc_demotion_duration": "5m"},
+		"prometheus": map[string]any{"port": 9090},
+	}
+	deprecations, err := migrateRaw(raw, migrations, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"failover.leaderless_threshold is deprecated since config version 2 - rename it to failover.leaderless_samples_threshold",
+		"cluster.rpc_demotion_duration is deprecated since config version 3 - rename it to cluster.rpc_demotion.duration",
+		"prometheus.port is deprecated since config version 3 - rename it to prometheus.metrics.port",
+	}, deprecations)
+	assert.Equal(t, map[string]any{
+		"version":    3,
+		"failover":   map[string]any{"leaderless_samples_threshold": 5, "dry_run": true},
+		"cluster":    map[string]any{"rpc_demotion": map[string]any{"duration": "5m"}},
+		"prometheus": map[string]any{"metrics": map[string]any{"port": 9090}},
+	}, raw)
+
+	// only migrations newer than the declared version apply - older keys in newer configs are left as unknown
+	raw = map[string]any{"version": 2, "failover": map[string]any{"leaderless_threshold": 5}, "prometheus": map[string]any{"port": 9090}}
+	deprecations, err = migrateRaw(raw, migrations, 3)
+	require.NoError(t, err)
+	assert.Len(t, deprecations, 1)
+	assert.Equal(t, map[string]any{
+		"version":    3,
+		"failover":   map[string]any{"leaderless_threshold": 5},
+		"prometheus": map[string]any{"metrics": map[string]any{"port": 9090}},
+	}, raw)
+
+	// setting both the deprecated and current key is ambiguous
+	raw = map[string]any{"failover": map[string]any{"leaderless_threshold": 5, "leaderless_samples_threshold": 3}}
+	_, err = migrateRaw(raw, migrations, 3)
+	assert.EqualError(t, err, "failover.leaderless_threshold is deprecated and replaced by failover.leaderless_samples_threshold - set only failover.leaderless_samples_threshold")
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// VoteAccountWatch represents the configuration for watching the vote account for unexpected commission and authority changes
+type VoteAccountWatch struct {
+	Enabled bool `koanf:"enabled"`
+	// VoteAccount is the vote account pubkey - discovered from the active identity when empty
+	VoteAccount string `koanf:"vote_account"`
+	// IntervalDuration is how often the vote account is checked
+	IntervalDuration time.Duration `koanf:"interval_duration"`
+	// Expected* pin the values the vote account must have - when unset, changes from the first observed values are reported
+	ExpectedCommission           *int   `koanf:"expected_commission"`
+	ExpectedAuthorizedWithdrawer string `koanf:"expected_authorized_withdrawer"`
+	ExpectedAuthorizedVoter      string `koanf:"expected_authorized_voter"`
+}
+
+// SetDefaults sets default values for the vote account watch configuration
+func (v *VoteAccountWatch) SetDefaults() {
+	if v.IntervalDuration == 0 {
+		v.IntervalDuration = 5 * time.Minute
+	}
+}
+
+// Validate validates the vote account watch configuration
+func (v *VoteAccountWatch) Validate() error {
+	if !v.Enabled {
+		return nil
+	}
+
+	if v.IntervalDuration < 0 {
+		return fmt.Errorf("validator.vote_account_watch.interval_duration must be positive")
+	}
+
+	pubkeys := map[string]string{
+		"vote_account":                   v.VoteAccount,
+		"expected_authorized_withdrawer": v.ExpectedAuthorizedWithdrawer,
+		"expected_authorized_voter":      v.ExpectedAuthorizedVoter,
+	}
+	for key, pubkey := range pubkeys {
+		if pubkey == "" {
+			continue
+		}
+		if _, err := solanago.PublicKeyFromBase58(pubkey); err != nil {
+			return fmt.Errorf("validator.vote_account_watch.%s must be a valid pubkey: %w", key, err)
+		}
+	}
+
+	if v.ExpectedCommission != nil && (*v.ExpectedCommission < 0 || *v.ExpectedCommission > 100) {
+		return fmt.Errorf("validator.vote_account_watch.expected_commission must be between 0 and 100")
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteAccountWatch_SetDefaults(t *testing.T) {
+	watch := &VoteAccountWatch{}
+	watch.SetDefaults()
+
+	assert.Equal(t, 5*time.Minute, watch.IntervalDuration)
+}
+
+func TestVoteAccountWatch_Validate(t *testing.T) {
+	// disabled is always valid
+	watch := &VoteAccountWatch{VoteAccount: "not-a-pubkey"}
+	assert.NoError(t, watch.Validate())
+
+	// Test with invalid vote account
+	watch.Enabled = true
+	err := watch.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "validator.vote_account_watch.vote_account must be a valid pubkey")
+
+	// Test with valid vote account
+	watch.VoteAccount = "Vote111111111111111111111111111111111111111"
+	assert.NoError(t, watch.Validate())
+
+	// Test with out of range commission
+	commission := 101
+	watch.ExpectedCommission = &commission
+	err = watch.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "validator.vote_account_watch.expected_commission must be between 0 and 100")
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// VoteCredits represents the configuration for comparing the vote account's epoch credits with the cluster average
+// every poll - a validator can earn too few credits while still voting, without ever going delinquent
+type VoteCredits struct {
+	Enabled bool `koanf:"enabled"`
+	// VoteAccount is the vote account pubkey - the vote account of the active identity when empty
+	VoteAccount string `koanf:"vote_account"`
+	// LagPercent is how far below the cluster average, in percent, the epoch credits must be to be lagging
+	LagPercent float64 `koanf:"lag_percent"`
+	// LaggingSamplesThreshold is the number of consecutive lagging polls before vote_credits_lagging is sent
+	LaggingSamplesThreshold int `koanf:"lagging_samples_threshold"`
+}
+
+// SetDefaults sets default values for the vote credits configuration
+func (v *VoteCredits) SetDefaults() {
+	if v.LagPercent == 0 {
+		v.LagPercent = 10
+	}
+	if v.LaggingSamplesThreshold == 0 {
+		v.LaggingSamplesThreshold = 3
+	}
+}
+
+// Validate validates the vote credits configuration
+func (v *VoteCredits) Validate() error {
+	if !v.Enabled {
+		return nil
+	}
+
+	if v.VoteAccount != "" {
+		if _, err := solanago.PublicKeyFromBase58(v.VoteAccount); err != nil {
+			return fmt.Errorf("validator.vote_credits.vote_account must be a valid pubkey: %w", err)
+		}
+	}
+
+	if v.LagPercent <= 0 || v.LagPercent >= 100 {
+		return fmt.Errorf("validator.vote_credits.lag_percent must be between 0 and 100")
+	}
+
+	if v.LaggingSamplesThreshold < 0 {
+		return fmt.Errorf("validator.vote_credits.lagging_samples_threshold must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteCredits_SetDefaults(t *testing.T) {
+	voteCredits := &VoteCredits{}
+	voteCredits.SetDefaults()
+
+	assert.Equal(t, float64(10), voteCredits.LagPercent)
+	assert.Equal(t, 3, voteCredits.LaggingSamplesThreshold)
+}
+
+func TestVoteCredits_Validate(t *testing.T) {
+	// disabled is always valid
+	voteCredits := &VoteCredits{LagPercent: 200}
+	assert.NoError(t, voteCredits.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*VoteCredits)
+		err    string
+	}{
+		{"defaults", func(v *VoteCredits) {}, ""},
+		{"vote account", func(v *VoteCredits) { v.VoteAccount = "Vote111111111111111111111111111111111111111" }, ""},
+		{"invalid vote account", func(v *VoteCredits) { v.VoteAccount = "not-a-pubkey" }, "validator.vote_credits.vote_account must be a valid pubkey"},
+		{"lag percent too large", func(v *VoteCredits) { v.LagPercent = 100 }, "validator.vote_credits.lag_percent must be between 0 and 100"},
+		{"negative lag percent", func(v *VoteCredits) { v.LagPercent = -1 }, "validator.vote_credits.lag_percent must be between 0 and 100"},
+		{"negative samples threshold", func(v *VoteCredits) { v.LaggingSamplesThreshold = -1 }, "validator.vote_credits.lagging_samples_threshold must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voteCredits := &VoteCredits{Enabled: true}
+			voteCredits.SetDefaults()
+			tt.modify(voteCredits)
+			err := voteCredits.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// HookConditions are the facts hook when expressions are evaluated against, alongside dry_run
+type HookConditions struct {
+	// Cluster is cluster.name, e.g. mainnet-beta
+	Cluster string
+	// Role is the role being transitioned to - active or passive
+	Role string
+	// PreviousRole is the role this node was last seen in before the transition - active, passive or unknown
+	PreviousRole string
+	// ValidatorName is validator.name
+	ValidatorName string
+}
+
+// whenVariables returns the variables hook when expressions may use
+func (c HookConditions) whenVariables(dryRun bool) map[string]any {
+	return map[string]any{
+		"dry_run":        dryRun,
+		"cluster":        c.Cluster,
+		"role":           c.Role,
+		"previous_role":  c.PreviousRole,
+		"validator_name": c.ValidatorName,
+	}
+}
+
+// validateWhen checks a when expression parses and uses known variables - both sides of every and/or are
+// evaluated, so evaluating against zero values finds every error
+func validateWhen(expression string) error {
+	_, err := evalWhen(expression, HookConditions{}.whenVariables(false))
+	return err
+}
+
+// whenToken is a token of a when expression - an identifier, keyword, quoted string or operator
+type whenToken struct {
+	text   string
+	quoted bool
+}
+
+// evalWhen evaluates a when expression against vars. Expressions compare variables and 'quoted' strings with ==
+// and !=, and combine them with and, or, not and parentheses, e.g. "not dry_run and cluster == 'mainnet-beta'".
+func evalWhen(expression string, vars map[string]any) (bool, error) {
+	tokens, err := tokenizeWhen(expression)
+	if err != nil {
+		return false, err
+	}
+	if len(tokens) == 0 {
+		return false, fmt.Errorf("when must not be empty")
+	}
+
+	p := &whenParser{tokens: tokens, vars: vars}
+	value, err := p.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, fmt.Errorf("unexpected %s in when expression", p.tokens[p.pos].text)
+	}
+
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("when must evaluate to true or false, not %q", value)
+	}
+	return result, nil
+}
+
+// tokenizeWhen splits a when expression into tokens
+func tokenizeWhen(expression string) ([]whenToken, error) {
+	tokens := []whenToken{}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, whenToken{text: string(r)})
+			i++
+		case r == '=' || r == '!':
+			if i+1 >= len(runes) || runes[i+1] != '=' {
+				return nil, fmt.Errorf("unexpected %c in when expression - use == or !=", r)
+			}
+			tokens = append(tokens, whenToken{text: string(runes[i : i+2])})
+			i += 2
+		case r == '\'' || r == '"':
+			end := strings.IndexRune(string(runes[i+1:]), r)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in when expression")
+			}
+			value := string(runes[i+1:])[:end]
+			tokens = append(tokens, whenToken{text: value, quoted: true})
+			i += 1 + len([]rune(value)) + 1
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, whenToken{text: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected %c in when expression", r)
+		}
+	}
+	return tokens, nil
+}
+
+// whenParser evaluates tokens as it parses them
+type whenParser struct {
+	tokens []whenToken
+	pos    int
+	vars   map[string]any
+}
+
+// next consumes the next token if it is the unquoted text, returning true if it did
+func (p *whenParser) next(text string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr parses a or b ...
+func (p *whenParser) parseOr() (any, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.next("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = whenLogic("or", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// parseAnd parses a and b ...
+func (p *whenParser) parseAnd() (any, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.next("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = whenLogic("and", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// parseNot parses not a
+func (p *whenParser) parseNot() (any, error) {
+	if !p.next("not") {
+		return p.parseComparison()
+	}
+	value, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("not needs true or false, not %q", value)
+	}
+	return !b, nil
+}
+
+// parseComparison parses a == b or a != b
+func (p *whenParser) parseComparison() (any, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!="} {
+		if !p.next(op) {
+			continue
+		}
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprintf("%T", left) != fmt.Sprintf("%T", right) {
+			return nil, fmt.Errorf("cannot compare %v %s %v", left, op, right)
+		}
+		return (left == right) == (op == "=="), nil
+	}
+	return left, nil
+}
+
+// parseOperand parses a variable, quoted string, true, false or parenthesised expression
+func (p *whenParser) parseOperand() (any, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("when expression ends unexpectedly")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+
+	if token.quoted {
+		return token.text, nil
+	}
+	switch token.text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "(":
+		value, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(")") {
+			return nil, fmt.Errorf("missing ) in when expression")
+		}
+		return value, nil
+	case ")", "==", "!=", "and", "or", "not":
+		return nil, fmt.Errorf("unexpected %s in when expression", token.text)
+	}
+
+	value, ok := p.vars[token.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %s in when expression - quote strings with '", token.text)
+	}
+	return value, nil
+}
+
+// whenLogic combines two values with and/or
+func whenLogic(op string, left any, right any) (any, error) {
+	l, lok := left.(bool)
+	r, rok := right.(bool)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s needs true or false on both sides, not %v and %v", op, left, right)
+	}
+	if op == "and" {
+		return l && r, nil
+	}
+	return l || r, nil
+}
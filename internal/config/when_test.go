@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalWhen(t *testing.T) {
+	vars := HookConditions{
+		Cluster:       "mainnet-beta",
+		Role:          "active",
+		PreviousRole:  "passive",
+		ValidatorName: "validator-1",
+	}.whenVariables(false)
+
+	tests := map[string]bool{
+		"true":                      true,
+		"not dry_run":               true,
+		"dry_run":                   false,
+		"cluster == 'mainnet-beta'": true,
+		`cluster == "testnet"`:      false,
+		"cluster != 'testnet'":      true,
+		"previous_role == 'active'": false,
+		"not dry_run and role == 'active' and previous_role == 'passive'":    true,
+		"dry_run or cluster == 'testnet'":                                    false,
+		"(dry_run or cluster == 'mainnet-beta') and not (role == 'passive')": true,
+		"not not dry_run": false,
+		"validator_name == 'validator-1' or validator_name == 'validator-2'": true,
+		"dry_run == false": true,
+	}
+	for expression, expected := range tests {
+		t.Run(expression, func(t *testing.T) {
+			result, err := evalWhen(expression, vars)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestValidateWhen(t *testing.T) {
+	tests := map[string]string{
+		"":                            "when must not be empty",
+		"cluster = 'mainnet-beta'":    "use == or !=",
+		"cluster == 'mainnet-beta":    "unterminated string",
+		"cluster == mainnet":          "unknown variable mainnet",
+		"dry_run and leader == 'x'":   "unknown variable leader",
+		"cluster":                     "when must evaluate to true or false",
+		"not cluster":                 "not needs true or false",
+		"cluster and dry_run":         "and needs true or false on both sides",
+		"dry_run == 'true'":           "cannot compare",
+		"(dry_run":                    "missing )",
+		"dry_run)":                    "unexpected )",
+		"dry_run and":                 "when expression ends unexpectedly",
+		"role == 'active' && dry_run": "unexpected &",
+	}
+	for expression, expected := range tests {
+		t.Run(expression, func(t *testing.T) {
+			err := validateWhen(expression)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), expected)
+		})
+	}
+
+	assert.NoError(t, validateWhen("not dry_run and previous_role == 'active'"))
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// windowTimeLayout is the layout for time window start and end times
+const windowTimeLayout = "15:04"
+
+// windowDays maps accepted day names to weekdays
+var windowDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// TimeWindow is a recurring daily time window
+type TimeWindow struct {
+	// Days the window starts on (sun, mon, tue, wed, thu, fri, sat) - empty means every day
+	Days []string `koanf:"days"`
+	// Start and End are HH:MM times - a window where end is before start spans midnight
+	Start string `koanf:"start"`
+	End   string `koanf:"end"`
+	// Timezone is an IANA timezone name the window times are evaluated in
+	Timezone string `koanf:"timezone"`
+}
+
+// SetDefaults sets default values for the time window
+func (w *TimeWindow) SetDefaults() {
+	if w.Timezone == "" {
+		w.Timezone = "UTC"
+	}
+}
+
+// Validate validates the time window
+func (w *TimeWindow) Validate() error {
+	for _, day := range w.Days {
+		if _, ok := windowDays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("invalid day %q - must be one of sun, mon, tue, wed, thu, fri, sat", day)
+		}
+	}
+
+	if _, err := time.Parse(windowTimeLayout, w.Start); err != nil {
+		return fmt.Errorf("start must be a HH:MM time, got %q", w.Start)
+	}
+
+	if _, err := time.Parse(windowTimeLayout, w.End); err != nil {
+		return fmt.Errorf("end must be a HH:MM time, got %q", w.End)
+	}
+
+	if w.Start == w.End {
+		return fmt.Errorf("start and end must differ")
+	}
+
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+	}
+
+	return nil
+}
+
+// Contains returns true if now falls within the window
+func (w *TimeWindow) Contains(now time.Time) bool {
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	start, startErr := time.Parse(windowTimeLayout, w.Start)
+	end, endErr := time.Parse(windowTimeLayout, w.End)
+	if startErr != nil || endErr != nil {
+		return false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	// window within a single day
+	if startMinute < endMinute {
+		return minute >= startMinute && minute < endMinute && w.onDay(local.Weekday())
+	}
+
+	// window spans midnight - the part after midnight belongs to the previous day's window
+	if minute >= startMinute {
+		return w.onDay(local.Weekday())
+	}
+	if minute < endMinute {
+		return w.onDay((local.Weekday() + 6) % 7)
+	}
+	return false
+}
+
+// onDay returns true if the window starts on the given weekday
+func (w *TimeWindow) onDay(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(w.Days, func(day string) bool {
+		return windowDays[strings.ToLower(day)] == weekday
+	})
+}
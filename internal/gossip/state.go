@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"net"
 	"slices"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/gagliardetto/solana-go"
 	solanagorpc "github.com/gagliardetto/solana-go/rpc"
 	"github.com/sol-strategies/solana-validator-ha/internal/config"
 	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
@@ -29,10 +30,12 @@ type State struct {
 	lastActivePeer         PeerState
 	activePeerLastSeenAt   time.Time
 	LeaderlessSamplesCount int
+	// confirmationRPCs are independent RPC clients, at least confirmationQuorum of which must agree the active
+	// identity is down before it is treated as down
+	confirmationRPCs   []*rpc.Client
+	confirmationQuorum int
 	// Callbacks for notification events
-	onPeerDiscovered func(name, ip, pubkey string)
-	onPeerLost       func(name, ip string)
-	onDelinquent     func(pubkey, gossipAddr string)
+	onDelinquent func(pubkey, gossipAddr string)
 }
 
 // PeerState represents the state of a peer as seen by the solana network
@@ -53,28 +56,30 @@ type PeerState struct {
 
 // Options are the options for peers state
 type Options struct {
-	ClusterRPC       *rpc.Client
-	ActivePubkey     string
-	SelfIP           string
-	ConfigPeers      config.Peers
-	LogPrefix        string
-	OnPeerDiscovered func(name, ip, pubkey string)
-	OnPeerLost       func(name, ip string)
-	OnDelinquent     func(pubkey, gossipAddr string)
+	ClusterRPC   *rpc.Client
+	ActivePubkey string
+	SelfIP       string
+	ConfigPeers  config.Peers
+	LogPrefix    string
+	OnDelinquent func(pubkey, gossipAddr string)
+	// ConfirmationRPCs, if any, must have ConfirmationQuorum agree the active identity is down before a leaderless
+	// sample is counted
+	ConfirmationRPCs   []*rpc.Client
+	ConfirmationQuorum int
 }
 
 // NewState creates a new gossip state
 func NewState(opts Options) *State {
 	return &State{
-		logger:           log.WithPrefix(fmt.Sprintf("[%s gossip_state]", opts.LogPrefix)),
-		clusterRPC:       opts.ClusterRPC,
-		activePubkey:     opts.ActivePubkey,
-		selfIP:           opts.SelfIP,
-		configPeers:      opts.ConfigPeers,
-		peerStatesByName: make(map[string]PeerState),
-		onPeerDiscovered: opts.OnPeerDiscovered,
-		onPeerLost:       opts.OnPeerLost,
-		onDelinquent:     opts.OnDelinquent,
+		logger:             log.WithPrefix(fmt.Sprintf("[%s gossip_state]", opts.LogPrefix)),
+		clusterRPC:         opts.ClusterRPC,
+		activePubkey:       opts.ActivePubkey,
+		selfIP:             opts.SelfIP,
+		configPeers:        opts.ConfigPeers,
+		peerStatesByName:   make(map[string]PeerState),
+		onDelinquent:       opts.OnDelinquent,
+		confirmationRPCs:   opts.ConfirmationRPCs,
+		confirmationQuorum: opts.ConfirmationQuorum,
 	}
 }
 
@@ -102,8 +107,16 @@ func (p *State) Refresh() {
 
 	// look through all the returned gossip nodes, looking for the ones that are in the config
 	isLeaderlessSample := true
+	activeInClusterNodes := false
 	for _, node := range clusterNodes {
-		nodeIP := strings.Split(*node.Gossip, ":")[0]
+		if node.Pubkey.String() == p.activePubkey {
+			activeInClusterNodes = true
+		}
+
+		nodeIP, err := config.NormalizeIP(*node.Gossip)
+		if err != nil {
+			continue
+		}
 
 		// if the peer is not the config, keep looking
 		if !p.hasConfigPeerWithIP(nodeIP) {
@@ -191,10 +204,6 @@ func (p *State) Refresh() {
 				"is_active", peerState.LastSeenActive,
 				"last_seen_at", peerState.LastSeenAtString(),
 			)
-			// Call peer discovered callback
-			if p.onPeerDiscovered != nil {
-				p.onPeerDiscovered(peerState.Name, peerState.IP, peerState.Pubkey)
-			}
 		}
 
 		// if all peers from configPeers are in the peerEntries, we can stop looking
@@ -221,10 +230,6 @@ func (p *State) Refresh() {
 		// warn if peer was in the old state but is now missing
 		if p.HasIP(ip) {
 			p.logger.Warn("peer lost", "name", name, "ip", ip)
-			// Call peer lost callback
-			if p.onPeerLost != nil {
-				p.onPeerLost(name, ip)
-			}
 			continue
 		}
 
@@ -238,6 +243,11 @@ func (p *State) Refresh() {
 		p.logger.Debug("peer still missing", "name", name, "ip", ip)
 	}
 
+	// an active identity missing from cluster nodes may just be a flaky cluster rpc - have it confirmed
+	if isLeaderlessSample && !activeInClusterNodes && !p.activeDownConfirmed("active identity not in cluster nodes") {
+		isLeaderlessSample = false
+	}
+
 	// update state
 	if isLeaderlessSample {
 		p.LeaderlessSamplesCount++
@@ -293,6 +303,11 @@ func (p *State) isNodeActiveAndVoting(node solanagorpc.GetClusterNodesResult) bo
 			return true
 		}
 
+		// a flaky cluster rpc may report it delinquent - have it confirmed
+		if !p.activeDownConfirmed("active identity delinquent") {
+			return true
+		}
+
 		// ohhh shit! we're delinquent - snitch on this guy!
 		p.logger.Error("‼️ node is delinquent - not voting",
 			"gossip_address", *node.Gossip,
@@ -323,8 +338,12 @@ func (p *State) isNodeActiveAndVoting(node solanagorpc.GetClusterNodesResult) bo
 		break
 	}
 
-	// if we didn't find our node - we're definitely inactive and not voting
+	// if we didn't find our node - we're definitely inactive and not voting, unless a flaky cluster rpc says so
 	if !found {
+		if !p.activeDownConfirmed("no current or delinquent vote account for active identity") {
+			return true
+		}
+
 		p.logger.Warn("no current or delinquent vote account found for node",
 			"gossip_address", *node.Gossip,
 			"pubkey", node.Pubkey.String(),
@@ -345,6 +364,56 @@ func (p *State) isNodeActiveAndVoting(node solanagorpc.GetClusterNodesResult) bo
 	return true
 }
 
+// activeDownConfirmed returns true if at least the confirmation quorum of the independent confirmation RPCs agree
+// the active identity is out of gossip or not voting - always true without confirmation RPCs. RPC errors count as
+// not agreeing, so unreachable confirmation RPCs hold back takeovers rather than trigger them
+func (p *State) activeDownConfirmed(reason string) bool {
+	if len(p.confirmationRPCs) == 0 {
+		return true
+	}
+
+	identity, err := solana.PublicKeyFromBase58(p.activePubkey)
+	if err != nil {
+		p.logger.Error("invalid active pubkey - unable to confirm it is down", "error", err)
+		return true
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	agreeing := 0
+	for _, client := range p.confirmationRPCs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			voting, err := client.IsNodeVoting(context.Background(), identity)
+			if err != nil {
+				p.logger.Warn("delinquency confirmation rpc failed - counting as not agreeing", "error", err)
+				return
+			}
+			if voting {
+				return
+			}
+			mu.Lock()
+			agreeing++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if agreeing < p.confirmationQuorum {
+		p.logger.Warn("cluster rpc reports active identity down but the delinquency confirmation quorum disagrees - treating it as up",
+			"reason", reason,
+			"agreeing", agreeing,
+			"quorum", p.confirmationQuorum,
+			"confirmation_rpcs", len(p.confirmationRPCs),
+		)
+		return false
+	}
+
+	p.logger.Warn("delinquency confirmation quorum agrees active identity is down", "reason", reason, "agreeing", agreeing, "quorum", p.confirmationQuorum)
+	return true
+}
+
 // isNodeGossipAlive returns true if the node's gossip address is alive
 // Note: We use Gossip port instead of TPU because TPU ports are often firewalled
 // and not reliable indicators of node liveness, while Gossip is more accessible
@@ -406,6 +475,11 @@ func (p *State) GetActivePeer() (state PeerState, err error) {
 	return PeerState{}, fmt.Errorf("no active peer found")
 }
 
+// LastActivePeer returns the peer last seen active, false if none has been seen since we started
+func (p *State) LastActivePeer() (PeerState, bool) {
+	return p.lastActivePeer, p.lastActivePeer.IP != ""
+}
+
 // HasPeers returns true if the IP has any peers in the gossip state
 // that is, any peers in that state that are not the passed IP address
 func (p *State) HasPeers(ip string) bool {
@@ -428,6 +502,11 @@ func (p *PeerState) LastSeenAtString() string {
 	return p.LastSeenAtUTC.Format(time.RFC3339)
 }
 
+// SetConfigPeers replaces the configured peers looked for in gossip, taking effect from the next Refresh
+func (p *State) SetConfigPeers(peers config.Peers) {
+	p.configPeers = peers
+}
+
 func (p *State) peerNameFromIP(ip string) (string, bool) {
 	for name, peer := range p.configPeers {
 		if peer.IP == ip {
@@ -1,6 +1,9 @@
 package gossip
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -163,6 +166,14 @@ func TestGetActivePeer(t *testing.T) {
 	assert.Equal(t, activePeer.IP, peerState.IP)
 	assert.Equal(t, activePeer.Pubkey, peerState.Pubkey)
 	assert.True(t, peerState.LastSeenActive)
+
+	// the last active peer is only known once one is seen by Refresh
+	_, ok := state.LastActivePeer()
+	assert.False(t, ok)
+	state.lastActivePeer = PeerState{Name: "peer1", IP: activePeer.IP}
+	lastActivePeer, ok := state.LastActivePeer()
+	assert.True(t, ok)
+	assert.Equal(t, "peer1", lastActivePeer.Name)
 }
 
 func TestHasPeers(t *testing.T) {
@@ -422,3 +433,75 @@ func TestState_ConcurrentAccess(t *testing.T) {
 	// If we get here without panicking, the methods are thread-safe
 	assert.True(t, true)
 }
+
+func TestSetConfigPeers(t *testing.T) {
+	state := NewState(Options{ConfigPeers: config.Peers{"peer1": {IP: "192.168.1.2"}}})
+
+	state.SetConfigPeers(config.Peers{"peer3": {IP: "192.168.1.4"}})
+
+	_, found := state.peerNameFromIP("192.168.1.2")
+	assert.False(t, found)
+	name, found := state.peerNameFromIP("192.168.1.4")
+	assert.True(t, found)
+	assert.Equal(t, "peer3", name)
+}
+
+// confirmationRPC returns a client for a mock rpc that sees activePubkey in gossip and voting if voting is true, and
+// fails every request if failing is true
+func confirmationRPC(t *testing.T, activePubkey string, voting bool, failing bool) *rpc.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var req struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		voteAccounts := map[string]any{"current": []any{}, "delinquent": []any{}}
+		if voting {
+			voteAccounts["current"] = []any{map[string]any{"votePubkey": "11111111111111111111111111111111", "nodePubkey": activePubkey}}
+		}
+		results := map[string]any{
+			"getClusterNodes": []any{map[string]any{"pubkey": activePubkey, "gossip": "192.0.2.1:8001"}},
+			"getVoteAccounts": voteAccounts,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": results[req.Method]}))
+	}))
+	t.Cleanup(server.Close)
+	return rpc.NewClient("test", server.URL)
+}
+
+func TestState_ActiveDownConfirmed(t *testing.T) {
+	activePubkey := "Vote111111111111111111111111111111111111111"
+
+	// without confirmation rpcs the cluster rpc is trusted
+	state := NewState(Options{ActivePubkey: activePubkey})
+	assert.True(t, state.activeDownConfirmed("test"))
+
+	tests := []struct {
+		name      string
+		voting    []bool
+		failing   []bool
+		quorum    int
+		confirmed bool
+	}{
+		{"all agree down", []bool{false, false, false}, []bool{false, false, false}, 2, true},
+		{"majority agree down", []bool{false, false, true}, []bool{false, false, false}, 2, true},
+		{"majority see it voting", []bool{false, true, true}, []bool{false, false, false}, 2, false},
+		{"failing rpcs don't agree", []bool{false, false, false}, []bool{false, true, true}, 2, false},
+		{"quorum of one", []bool{false, true, true}, []bool{false, false, false}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{ActivePubkey: activePubkey, ConfirmationQuorum: tt.quorum}
+			for i := range tt.voting {
+				opts.ConfirmationRPCs = append(opts.ConfirmationRPCs, confirmationRPC(t, activePubkey, tt.voting[i], tt.failing[i]))
+			}
+			assert.Equal(t, tt.confirmed, NewState(opts).activeDownConfirmed("test"))
+		})
+	}
+}
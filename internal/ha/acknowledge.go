@@ -0,0 +1,106 @@
+package ha
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// acknowledgementsPath is the health server path failovers are acknowledged on
+const acknowledgementsPath = "/acknowledgements"
+
+// acknowledgeRequest is the body POSTed to acknowledge a failover
+type acknowledgeRequest struct {
+	ID   string `json:"id"`
+	By   string `json:"by"`
+	Note string `json:"note"`
+}
+
+// handleAcknowledgements serves the acknowledged failovers, acknowledging one with POST - acknowledgements
+// are only accepted from localhost and only for failover IDs this manager knows of
+func (m *Manager) handleAcknowledgements(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if !isLoopbackRequest(r) {
+			http.Error(w, "failovers can only be acknowledged from localhost", http.StatusForbidden)
+			return
+		}
+
+		var req acknowledgeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid acknowledgement body", http.StatusBadRequest)
+			return
+		}
+		if req.ID == "" || req.By == "" {
+			http.Error(w, "id and by are required", http.StatusBadRequest)
+			return
+		}
+		if !m.knownFailoverID(req.ID) {
+			http.Error(w, fmt.Sprintf("unknown failover ID %s", req.ID), http.StatusNotFound)
+			return
+		}
+
+		m.acknowledge(req.ID, req.By, req.Note)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	acks := []notify.Acknowledgement{}
+	if m.notifyManager != nil {
+		acks = m.notifyManager.Acknowledgements()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(acks); err != nil {
+		m.logger.Error("failed to encode acknowledgements", "error", err)
+	}
+}
+
+// knownFailoverID returns true if id is the current failover or one in the event history
+func (m *Manager) knownFailoverID(id string) bool {
+	if m.cache.GetState().FailoverID == id {
+		return true
+	}
+	if m.notifyManager == nil {
+		return false
+	}
+	for _, event := range m.notifyManager.Events(time.Time{}) {
+		if event.Details["failover_id"] == id {
+			return true
+		}
+	}
+	return false
+}
+
+// acknowledge records the acknowledgement and tells everyone else who has taken ownership of the failover
+func (m *Manager) acknowledge(id string, by string, note string) {
+	if m.notifyManager == nil {
+		return
+	}
+
+	ack := m.notifyManager.Acknowledge(id, by, note)
+	state := m.cache.GetState()
+
+	message := fmt.Sprintf("Failover %s acknowledged by %s", id, by)
+	if note != "" {
+		message += ": " + note
+	}
+
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventAcknowledged,
+		Severity:      notify.SeverityInfo,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      state.PublicIP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message:       message,
+		Details: map[string]string{
+			"failover_id": ack.ID,
+			"note":        ack.Note,
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_HandleAcknowledgements(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Notifications.HistorySize = 10
+
+	manager := NewManager(NewManagerOptions{
+		Cfg:             cfg,
+		GetPublicIPFunc: mockPublicIPFunc,
+	})
+	require.NoError(t, manager.initialize())
+
+	failoverID := manager.beginFailover(constants.StatusBecomingActive)
+
+	acknowledge := func(remoteAddr string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, acknowledgementsPath, strings.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		manager.handleAcknowledgements(recorder, req)
+		return recorder
+	}
+
+	// only accepted from localhost, for known failovers, with who acknowledged
+	assert.Equal(t, http.StatusForbidden, acknowledge("192.0.2.1:1234", `{"id":"`+failoverID+`","by":"alice"}`).Code)
+	assert.Equal(t, http.StatusNotFound, acknowledge("127.0.0.1:1234", `{"id":"unknown","by":"alice"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, acknowledge("127.0.0.1:1234", `{"id":"`+failoverID+`"}`).Code)
+
+	recorder := acknowledge("127.0.0.1:1234", `{"id":"`+failoverID+`","by":"alice","note":"on it"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	acks := []notify.Acknowledgement{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &acks))
+	require.Len(t, acks, 1)
+	assert.Equal(t, failoverID, acks[0].ID)
+	assert.Equal(t, "alice", acks[0].By)
+	assert.Equal(t, "on it", acks[0].Note)
+
+	// anyone may list acknowledgements
+	recorder = httptest.NewRecorder()
+	manager.handleAcknowledgements(recorder, httptest.NewRequest(http.MethodGet, acknowledgementsPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), failoverID)
+}
@@ -0,0 +1,219 @@
+package ha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+)
+
+const takeoverIntentPath = "/peer/takeover-intent"
+
+// takeoverIntent is broadcast to peers before a node runs its active command
+type takeoverIntent struct {
+	Name      string `json:"name"`
+	IP        string `json:"ip"`
+	Timestamp int64  `json:"timestamp"`
+	// Signature is a base58 signature of the intent payload by the shared active identity,
+	// proving the sender is an HA peer holding the active keypair
+	Signature string `json:"signature"`
+}
+
+// takeoverIntentResponse is a peer's response to a takeover intent
+type takeoverIntentResponse struct {
+	Name      string `json:"name"`
+	Objection bool   `json:"objection"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// payload returns the bytes that are signed for the intent
+func (t *takeoverIntent) payload() []byte {
+	return []byte(fmt.Sprintf("takeover-intent|%s|%s|%d", t.Name, t.IP, t.Timestamp))
+}
+
+// announceTakeover broadcasts a signed intent to take over to all peers and waits for objections.
+// Peers that are unreachable or do not respond within the wait duration are treated as not objecting.
+// Returns true if any peer objected.
+func (m *Manager) announceTakeover() (objected bool) {
+	intent := takeoverIntent{
+		Name:      m.peerSelf.Name,
+		IP:        m.peerSelf.IP,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+	signature, err := m.cfg.Validator.Identities.ActiveKeyPair.Sign(intent.payload())
+	if err != nil {
+		m.logger.Error("failed to sign takeover intent - proceeding without announcement", "error", err)
+		return false
+	}
+	intent.Signature = signature.String()
+
+	body, err := json.Marshal(intent)
+	if err != nil {
+		m.logger.Error("failed to marshal takeover intent - proceeding without announcement", "error", err)
+		return false
+	}
+
+	m.announcingTakeover.Store(true)
+	defer m.announcingTakeover.Store(false)
+
+	waitDuration := m.cfg.Failover.TakeoverAnnouncement.ObjectionWaitDuration
+	ctx, cancel := context.WithTimeout(m.ctx, waitDuration)
+	defer cancel()
+
+	m.logger.Info("announcing intent to take over to peers", "objection_wait", waitDuration)
+
+	var wg sync.WaitGroup
+	peers := m.peers()
+	responses := make(chan takeoverIntentResponse, len(peers))
+	for name, peer := range peers {
+		if peer.IP == m.peerSelf.IP {
+			continue
+		}
+		wg.Add(1)
+		go func(name, ip string) {
+			defer wg.Done()
+			response, err := m.sendTakeoverIntent(ctx, ip, body)
+			if err != nil {
+				m.logger.Debug("peer did not respond to takeover intent", "peer_name", name, "peer_ip", ip, "error", err)
+				return
+			}
+			responses <- response
+		}(name, peer.IP)
+	}
+	wg.Wait()
+	close(responses)
+
+	for response := range responses {
+		if response.Objection {
+			m.logger.Warn("peer objected to takeover", "peer_name", response.Name, "reason", response.Reason)
+			objected = true
+		}
+	}
+
+	return objected
+}
+
+// sendTakeoverIntent posts the intent to a peer's health check server
+func (m *Manager) sendTakeoverIntent(ctx context.Context, ip string, body []byte) (response takeoverIntentResponse, err error) {
+	url := m.cfg.Prometheus.PeerHealthCheckURL(ip, takeoverIntentPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return response, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.peerHTTPClient().Do(req)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return response, fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	return response, err
+}
+
+// handleTakeoverIntent responds to a peer's intent to take over, objecting if we still see
+// an active peer, are active ourselves, or are taking over with a better rank
+func (m *Manager) handleTakeoverIntent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var intent takeoverIntent
+	if err := json.NewDecoder(r.Body).Decode(&intent); err != nil {
+		http.Error(w, "invalid takeover intent", http.StatusBadRequest)
+		return
+	}
+
+	if err := m.verifyTakeoverIntent(intent, r.RemoteAddr); err != nil {
+		m.logger.Warn("rejected takeover intent", "name", intent.Name, "ip", intent.IP, "error", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	response := takeoverIntentResponse{Name: m.cfg.Validator.Name}
+	response.Objection, response.Reason = m.takeoverObjection(intent)
+	m.logger.Info("received takeover intent from peer",
+		"peer_name", intent.Name,
+		"peer_ip", intent.IP,
+		"objection", response.Objection,
+		"reason", response.Reason,
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// verifyTakeoverIntent checks the intent comes from a configured peer sending it from its own IP at remoteAddr, is
+// recent and is signed by the active identity - so an intent captured in flight can't be replayed from elsewhere
+func (m *Manager) verifyTakeoverIntent(intent takeoverIntent, remoteAddr string) error {
+	peers := m.peers()
+	if !peers.HasIP(intent.IP) {
+		return fmt.Errorf("unknown peer ip %s", intent.IP)
+	}
+
+	senderIP, err := config.NormalizeIP(remoteAddr)
+	if err != nil {
+		return fmt.Errorf("unknown sender address %s", remoteAddr)
+	}
+	if senderIP != intent.IP {
+		return fmt.Errorf("intent for peer ip %s sent from %s", intent.IP, senderIP)
+	}
+
+	age := time.Since(time.Unix(intent.Timestamp, 0))
+	if age < -m.cfg.Failover.TakeoverAnnouncement.MaxMessageAgeDuration || age > m.cfg.Failover.TakeoverAnnouncement.MaxMessageAgeDuration {
+		return fmt.Errorf("intent timestamp outside allowed age of %s", m.cfg.Failover.TakeoverAnnouncement.MaxMessageAgeDuration)
+	}
+
+	signature, err := solanago.SignatureFromBase58(intent.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if !m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().Verify(intent.payload(), signature) {
+		return fmt.Errorf("signature does not match active identity")
+	}
+
+	return nil
+}
+
+// takeoverObjection decides whether to object to a peer taking over
+func (m *Manager) takeoverObjection(intent takeoverIntent) (objection bool, reason string) {
+	// an unranked peer can't be weighed against us
+	peers := m.peers()
+	rankedIPs := peers.GetRankedIPs()
+	intentRank, ranked := rankedIPs[intent.IP]
+	if !ranked {
+		return true, fmt.Sprintf("peer ip %s is not ranked", intent.IP)
+	}
+
+	state := m.cache.GetState()
+
+	if state.Role == constants.RoleNameActive {
+		return true, "objecting peer is active"
+	}
+
+	if state.ActivePeerName != "" && state.LeaderlessSamples == 0 {
+		return true, fmt.Sprintf("objecting peer still sees %s active and voting", state.ActivePeerName)
+	}
+
+	// both of us want to take over - the better ranked peer wins
+	if m.announcingTakeover.Load() {
+		if rankedIPs[m.peerSelf.IP] < intentRank {
+			return true, "objecting peer is also taking over and has a better rank"
+		}
+	}
+
+	return false, ""
+}
@@ -0,0 +1,132 @@
+package ha
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/cache"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAnnouncementManager(t *testing.T) *Manager {
+	cfg := createTestConfig()
+	cfg.Failover.TakeoverAnnouncement.Enabled = true
+	cfg.Failover.TakeoverAnnouncement.SetDefaults()
+
+	manager := NewManager(NewManagerOptions{
+		Cfg:             cfg,
+		GetPublicIPFunc: mockPublicIPFunc,
+	})
+	require.NoError(t, manager.initialize())
+	return manager
+}
+
+func signedTestIntent(t *testing.T, manager *Manager, ip string, timestamp time.Time) takeoverIntent {
+	intent := takeoverIntent{Name: "peer", IP: ip, Timestamp: timestamp.Unix()}
+	signature, err := manager.cfg.Validator.Identities.ActiveKeyPair.Sign(intent.payload())
+	require.NoError(t, err)
+	intent.Signature = signature.String()
+	return intent
+}
+
+// postTestIntent posts an intent as sent by the peer it names
+func postTestIntent(t *testing.T, manager *Manager, intent takeoverIntent) *httptest.ResponseRecorder {
+	return postTestIntentFrom(t, manager, intent, intent.IP+":51234")
+}
+
+func postTestIntentFrom(t *testing.T, manager *Manager, intent takeoverIntent, remoteAddr string) *httptest.ResponseRecorder {
+	body, err := json.Marshal(intent)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, takeoverIntentPath, bytes.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	manager.handleTakeoverIntent(recorder, req)
+	return recorder
+}
+
+func TestManager_VerifyTakeoverIntent(t *testing.T) {
+	manager := createTestAnnouncementManager(t)
+
+	// valid intent from known peer
+	assert.NoError(t, manager.verifyTakeoverIntent(signedTestIntent(t, manager, "192.168.1.101", time.Now()), "192.168.1.101:51234"))
+
+	// unknown peer
+	err := manager.verifyTakeoverIntent(signedTestIntent(t, manager, "10.0.0.1", time.Now()), "10.0.0.1:51234")
+	assert.ErrorContains(t, err, "unknown peer ip")
+
+	// a peer's intent replayed from another address
+	err = manager.verifyTakeoverIntent(signedTestIntent(t, manager, "192.168.1.101", time.Now()), "10.0.0.1:51234")
+	assert.ErrorContains(t, err, "sent from 10.0.0.1")
+
+	// stale intent
+	err = manager.verifyTakeoverIntent(signedTestIntent(t, manager, "192.168.1.101", time.Now().Add(-time.Hour)), "192.168.1.101:51234")
+	assert.ErrorContains(t, err, "outside allowed age")
+
+	// tampered intent
+	intent := signedTestIntent(t, manager, "192.168.1.101", time.Now())
+	intent.Name = "someone-else"
+	err = manager.verifyTakeoverIntent(intent, "192.168.1.101:51234")
+	assert.ErrorContains(t, err, "signature does not match")
+}
+
+func TestManager_HandleTakeoverIntent(t *testing.T) {
+	manager := createTestAnnouncementManager(t)
+
+	// no active peer seen - no objection
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNamePassive, LeaderlessSamples: 3})
+	recorder := postTestIntent(t, manager, signedTestIntent(t, manager, "192.168.1.101", time.Now()))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var response takeoverIntentResponse
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.False(t, response.Objection)
+
+	// active peer still seen voting - objection
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNamePassive, ActivePeerName: "peer2"})
+	recorder = postTestIntent(t, manager, signedTestIntent(t, manager, "192.168.1.101", time.Now()))
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.True(t, response.Objection)
+	assert.Contains(t, response.Reason, "peer2")
+
+	// we are active - objection
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNameActive})
+	recorder = postTestIntent(t, manager, signedTestIntent(t, manager, "192.168.1.101", time.Now()))
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.True(t, response.Objection)
+
+	// invalid signature - forbidden
+	intent := signedTestIntent(t, manager, "192.168.1.101", time.Now())
+	intent.Timestamp++
+	recorder = postTestIntent(t, manager, intent)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// replayed from another address - forbidden
+	recorder = postTestIntentFrom(t, manager, signedTestIntent(t, manager, "192.168.1.101", time.Now()), "10.0.0.1:51234")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestManager_TakeoverObjection_BothAnnouncing(t *testing.T) {
+	manager := createTestAnnouncementManager(t)
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNamePassive, LeaderlessSamples: 3})
+	manager.announcingTakeover.Store(true)
+
+	// self is 192.168.1.100 - ranked ahead of .101 so we object to it
+	objection, _ := manager.takeoverObjection(takeoverIntent{IP: "192.168.1.101"})
+	assert.True(t, objection)
+
+	// a better ranked peer is not objected to
+	manager.cfg.Failover.Peers.Add(config.Peer{Name: "better", IP: "192.168.1.1"})
+	objection, _ = manager.takeoverObjection(takeoverIntent{IP: "192.168.1.1"})
+	assert.False(t, objection)
+
+	// nor can an unranked peer win by ranking 0
+	objection, reason := manager.takeoverObjection(takeoverIntent{IP: "10.0.0.1"})
+	assert.True(t, objection)
+	assert.Contains(t, reason, "not ranked")
+}
@@ -0,0 +1,161 @@
+package ha
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// arbitrator is the failover.arbitration lock - in etcd or Consul, or a token held by Raft among the peers
+type arbitrator interface {
+	// Acquire acquires the lock for holder, or renews it if holder holds it, returning the holder of the lock
+	Acquire(ctx context.Context, holder string) (string, error)
+	// Release releases the lock if holder holds it
+	Release(ctx context.Context, holder string) error
+}
+
+// acquireArbitrationLock acquires the failover.arbitration lock before we become active, returning false if it
+// can't be acquired or a peer holds it - a peer we can't see may have taken over on the other side of a partition
+func (m *Manager) acquireArbitrationLock() bool {
+	if m.cfg.Failover.DryRun {
+		m.logger.Warn("dry run - not acquiring the failover.arbitration lock")
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, m.cfg.Failover.Arbitration.TTLDuration)
+	defer cancel()
+	holder, err := m.arbitrationLock.Acquire(ctx, m.peerSelf.Name)
+	if err != nil {
+		m.logger.Error("failed to acquire failover.arbitration lock - unable to become active in failover", "error", err)
+		return false
+	}
+	if holder != m.peerSelf.Name {
+		m.logger.Error("failover.arbitration lock is held by another peer - unable to become active in failover", "holder", holder)
+		return false
+	}
+
+	m.arbitrationHeld.Store(true)
+	m.takingOver.Store(true)
+	m.arbitrationLapsing.Store(false)
+	m.incident.step("decision", "acquired failover.arbitration lock")
+	return true
+}
+
+// releaseArbitrationLock releases the failover.arbitration lock once we are passive, so a peer can take over
+// without waiting for it to lapse
+func (m *Manager) releaseArbitrationLock(logger *log.Logger) {
+	if m.arbitrationLock == nil || !m.arbitrationHeld.Load() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, m.cfg.Failover.Arbitration.TTLDuration)
+	defer cancel()
+	if err := m.arbitrationLock.Release(ctx, m.peerSelf.Name); err != nil {
+		logger.Warn("failed to release failover.arbitration lock - it lapses after its ttl", "error", err)
+	}
+	m.arbitrationHeld.Store(false)
+	m.takingOver.Store(false)
+	m.arbitrationLapsing.Store(false)
+}
+
+// abandonArbitrationLock gives up the failover.arbitration lock after a takeover failed - it is released once local
+// rpc confirms we are passive. A failed active command or check doesn't prove the identity wasn't set, so otherwise
+// we may be voting and the lock is no longer renewed, leaving it to lapse after its ttl.
+func (m *Manager) abandonArbitrationLock(logger *log.Logger) {
+	if m.arbitrationLock == nil || !m.arbitrationHeld.Load() {
+		return
+	}
+	if m.isSelfPassive() {
+		m.releaseArbitrationLock(logger)
+		return
+	}
+
+	logger.Error("not confirmed passive after failing to become active - letting failover.arbitration lock lapse")
+	m.arbitrationLapsing.Store(true)
+}
+
+// arbitrationLoop renews the failover.arbitration lock every renew_interval_duration while we hold it or are
+// active, until the manager is stopped. Losing it - a peer holding it, or failing to renew it for its ttl - aborts a
+// takeover in progress and is sent on arbitrationLost for the HA loop to make us passive.
+func (m *Manager) arbitrationLoop() {
+	arbitration := m.cfg.Failover.Arbitration
+	ticker := time.NewTicker(arbitration.RenewIntervalDuration)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// the lock is only ours to renew while we hold it or are active, e.g. after a restart - takingOver is read
+		// first so a takeover finishing meanwhile is seen as active
+		takingOver := m.takingOver.Load()
+		held := m.arbitrationHeld.Load()
+		active := m.isSelfActive()
+		if !held && !active {
+			renewedAt = time.Now()
+			continue
+		}
+
+		// a takeover that didn't leave us active must not keep peers from taking over - only once local rpc
+		// confirms we are passive, not on an rpc error
+		if held && !active && !takingOver && m.isSelfPassive() {
+			m.logger.Error("holding failover.arbitration lock but not active after taking over - releasing it")
+			m.releaseArbitrationLock(m.logger)
+			renewedAt = time.Now()
+			continue
+		}
+
+		// a failed takeover we couldn't confirm left us passive isn't renewed, so the lock lapses after its ttl
+		if held && !active && m.arbitrationLapsing.Load() {
+			if time.Since(renewedAt) >= arbitration.TTLDuration {
+				m.logger.Warn("failover.arbitration lock lapsed after a failed takeover")
+				m.arbitrationHeld.Store(false)
+				m.arbitrationLapsing.Store(false)
+			}
+			continue
+		}
+		if m.cfg.Failover.DryRun {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(m.ctx, arbitration.RenewIntervalDuration)
+		holder, err := m.arbitrationLock.Acquire(ctx, m.peerSelf.Name)
+		cancel()
+
+		switch {
+		case err == nil && holder == m.peerSelf.Name:
+			m.arbitrationHeld.Store(true)
+			m.arbitrationLapsing.Store(false)
+			renewedAt = time.Now()
+			continue
+		case err == nil:
+			m.logger.Error("failover.arbitration lock is held by another peer - we must not be active", "holder", holder)
+		case time.Since(renewedAt) < arbitration.TTLDuration:
+			m.logger.Warn("failed to renew failover.arbitration lock - retrying", "error", err)
+			continue
+		default:
+			m.logger.Error("failed to renew failover.arbitration lock within its ttl - we must not be active", "error", err)
+		}
+
+		m.arbitrationHeld.Store(false)
+		m.abortTakeover(errArbitrationLost)
+		select {
+		case m.arbitrationLost <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// handleArbitrationLost makes us passive if we are active without the failover.arbitration lock
+func (m *Manager) handleArbitrationLost() {
+	if !m.isSelfActive() {
+		return
+	}
+	m.logger.Error("active without the failover.arbitration lock - becoming passive")
+	m.ensurePassive()
+}
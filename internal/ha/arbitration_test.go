@@ -0,0 +1,167 @@
+package ha
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_AcquireArbitrationLock(t *testing.T) {
+	// an etcd whose lock is held by holder
+	holder := "peer1"
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/lease/grant", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"ID": "7", "TTL": "15"})
+	})
+	mux.HandleFunc("POST /v3/lease/keepalive", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"result": map[string]string{"ID": "7", "TTL": "15"}})
+	})
+	mux.HandleFunc("POST /v3/kv/txn", func(w http.ResponseWriter, r *http.Request) {
+		if holder == "" {
+			json.NewEncoder(w).Encode(map[string]any{"succeeded": true})
+			return
+		}
+		kvs := []map[string][]byte{{"value": []byte(holder)}}
+		json.NewEncoder(w).Encode(map[string]any{"responses": []any{map[string]any{"response_range": map[string]any{"kvs": kvs}}}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := createTestConfig()
+	cfg.Failover.Arbitration = config.Arbitration{Enabled: true, URL: "etcd://" + strings.TrimPrefix(server.URL, "http://") + "/ha/active"}
+	cfg.Failover.Arbitration.SetDefaults()
+	cfg.Failover.DryRun = false
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	require.NotNil(t, manager.arbitrationLock)
+
+	// a peer holding the lock keeps us passive
+	assert.False(t, manager.acquireArbitrationLock())
+	assert.False(t, manager.arbitrationHeld.Load())
+
+	holder = ""
+	assert.True(t, manager.acquireArbitrationLock())
+	assert.True(t, manager.arbitrationHeld.Load())
+
+	// dry runs don't take the lock
+	manager.arbitrationHeld.Store(false)
+	holder = "peer1"
+	cfg.Failover.DryRun = true
+	assert.True(t, manager.acquireArbitrationLock())
+	assert.False(t, manager.arbitrationHeld.Load())
+}
+
+// fakeArbitrator is a failover.arbitration lock that is always free, counting acquisitions and releases
+type fakeArbitrator struct {
+	acquires atomic.Int32
+	releases atomic.Int32
+}
+
+func (f *fakeArbitrator) Acquire(ctx context.Context, holder string) (string, error) {
+	f.acquires.Add(1)
+	return holder, nil
+}
+
+func (f *fakeArbitrator) Release(ctx context.Context, holder string) error {
+	f.releases.Add(1)
+	return nil
+}
+
+// newPassiveIdentityRPC serves a local rpc reporting the passive identity
+func newPassiveIdentityRPC(t *testing.T, cfg *config.Config) *rpc.Client {
+	passivePubkey := cfg.Validator.Identities.PassiveKeyPair.PublicKey().String()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID any `json:"id"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": map[string]any{"identity": passivePubkey}})
+	}))
+	t.Cleanup(server.Close)
+	return rpc.NewClient("test", server.URL)
+}
+
+func TestManager_EnsureActive_ReleasesArbitrationLockOnFailure(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.DryRun = false
+	cfg.Failover.Active = config.Role{Command: "false"}
+	cfg.Failover.Arbitration = config.Arbitration{}
+	cfg.Failover.Arbitration.SetDefaults()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.localRPC = newPassiveIdentityRPC(t, cfg)
+	lock := &fakeArbitrator{}
+	manager.arbitrationLock = lock
+
+	require.True(t, manager.acquireArbitrationLock())
+	require.True(t, manager.takingOver.Load())
+	manager.ensureActive()
+
+	assert.Equal(t, int32(1), lock.releases.Load(), "a failed active command must release the lock once passive")
+	assert.False(t, manager.arbitrationHeld.Load())
+	assert.False(t, manager.takingOver.Load())
+}
+
+func TestManager_EnsureActive_LetsArbitrationLockLapseUnlessPassive(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.DryRun = false
+	cfg.Failover.Active = config.Role{Command: "false"}
+	cfg.Failover.Arbitration = config.Arbitration{RenewIntervalDuration: 10 * time.Millisecond, TTLDuration: 100 * time.Millisecond}
+	cfg.Failover.Arbitration.SetDefaults()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	// local rpc is unreachable, so we can't tell whether the identity was set
+	manager.localRPC = rpc.NewClient("test", "http://127.0.0.1:1")
+	lock := &fakeArbitrator{}
+	manager.arbitrationLock = lock
+
+	require.True(t, manager.acquireArbitrationLock())
+	manager.ensureActive()
+	assert.Zero(t, lock.releases.Load(), "the lock must not be released while we may be voting")
+	assert.True(t, manager.arbitrationHeld.Load())
+	assert.True(t, manager.arbitrationLapsing.Load())
+
+	// it is no longer renewed, so it lapses after its ttl
+	acquires := lock.acquires.Load()
+	go manager.arbitrationLoop()
+	defer manager.cancel()
+	assert.Eventually(t, func() bool { return !manager.arbitrationHeld.Load() }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, acquires, lock.acquires.Load())
+	assert.Zero(t, lock.releases.Load())
+}
+
+func TestManager_ArbitrationLoop_ReleasesLockWhenPassiveAfterTakeover(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.DryRun = false
+	cfg.Failover.Arbitration = config.Arbitration{RenewIntervalDuration: 10 * time.Millisecond}
+	cfg.Failover.Arbitration.SetDefaults()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.localRPC = newPassiveIdentityRPC(t, cfg)
+	lock := &fakeArbitrator{}
+	manager.arbitrationLock = lock
+
+	// mid takeover the lock is renewed though we are passive
+	require.True(t, manager.acquireArbitrationLock())
+	go manager.arbitrationLoop()
+	defer manager.cancel()
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, manager.arbitrationHeld.Load())
+	assert.Zero(t, lock.releases.Load())
+
+	// the takeover finished without leaving us active
+	manager.takingOver.Store(false)
+	assert.Eventually(t, func() bool { return !manager.arbitrationHeld.Load() }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), lock.releases.Load())
+}
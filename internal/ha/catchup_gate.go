@@ -0,0 +1,62 @@
+package ha
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// catchupGatePollInterval is how often the local and cluster slots are compared while waiting to catch up
+var catchupGatePollInterval = time.Second
+
+// waitForCatchup returns an error unless the local validator is within failover.catchup_gate.max_slots_behind slots
+// of the cluster, waiting up to failover.catchup_gate.max_wait_duration for it to catch up - the slots can't be
+// compared without both RPCs, so their failures count as not caught up
+func (m *Manager) waitForCatchup(logger *log.Logger) error {
+	gate := m.cfg.Failover.CatchupGate
+	if !gate.Enabled {
+		return nil
+	}
+
+	logger = logger.With("max_slots_behind", gate.MaxSlotsBehind)
+	deadline := time.Now().Add(gate.MaxWaitDuration)
+	for {
+		slotsBehind, err := m.getSlotsBehind()
+		switch {
+		case err != nil:
+			logger.Warn("failed to compare local and cluster slots - waiting to take over", "error", err)
+		case slotsBehind <= gate.MaxSlotsBehind:
+			logger.Info("local validator is caught up", "slots_behind", slotsBehind)
+			return nil
+		default:
+			logger.Warn("local validator is behind the cluster - waiting to take over", "slots_behind", slotsBehind)
+			err = fmt.Errorf("local validator is %d slots behind the cluster, more than failover.catchup_gate.max_slots_behind %d", slotsBehind, gate.MaxSlotsBehind)
+		}
+		if !time.Now().Before(deadline) {
+			return err
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return m.ctx.Err()
+		case <-time.After(catchupGatePollInterval):
+		}
+	}
+}
+
+// getSlotsBehind returns how many slots the local validator trails the cluster by
+func (m *Manager) getSlotsBehind() (uint64, error) {
+	clusterSlot, err := m.clusterRPC.GetSlot(m.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cluster slot: %w", err)
+	}
+	localSlot, err := m.localRPC.GetSlot(m.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get local slot: %w", err)
+	}
+	if clusterSlot > localSlot {
+		return clusterSlot - localSlot, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,74 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSlotServer returns a JSON-RPC server that answers getSlot with the slot func's result
+func mockSlotServer(t *testing.T, slot func() uint64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": slot()})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestManager_WaitForCatchup(t *testing.T) {
+	originalInterval := catchupGatePollInterval
+	catchupGatePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { catchupGatePollInterval = originalInterval })
+
+	var localSlot atomic.Uint64
+	clusterServer := mockSlotServer(t, func() uint64 { return 10_000 })
+	localServer := mockSlotServer(t, localSlot.Load)
+
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	manager.clusterRPC = rpc.NewClient("test", clusterServer.URL)
+	manager.localRPC = rpc.NewClient("test", localServer.URL)
+
+	// disabled never refuses
+	assert.NoError(t, manager.waitForCatchup(manager.logger))
+
+	manager.cfg.Failover.CatchupGate.Enabled = true
+	manager.cfg.Failover.CatchupGate.SetDefaults()
+
+	// caught up
+	localSlot.Store(9_950)
+	assert.NoError(t, manager.waitForCatchup(manager.logger))
+
+	// behind without waiting
+	localSlot.Store(0)
+	err := manager.waitForCatchup(manager.logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "10000 slots behind")
+
+	// catches up while waiting
+	manager.cfg.Failover.CatchupGate.MaxWaitDuration = 5 * time.Second
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		localSlot.Store(9_990)
+	}()
+	assert.NoError(t, manager.waitForCatchup(manager.logger))
+
+	// local rpc failing counts as not caught up
+	manager.cfg.Failover.CatchupGate.MaxWaitDuration = 0
+	manager.localRPC = rpc.NewClient("test", "http://127.0.0.1:1")
+	err = manager.waitForCatchup(manager.logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get local slot")
+}
@@ -0,0 +1,173 @@
+package ha
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// circuitBreakerPath is the health server path the circuit breaker status is served and reset on
+const circuitBreakerPath = "/failover/circuit-breaker"
+
+// circuitBreaker counts takeovers within failover.circuit_breaker.window_duration, tripping once there are
+// max_failovers of them - it stays tripped until reset manually
+type circuitBreaker struct {
+	mu        sync.Mutex
+	takeovers []time.Time
+	trippedAt time.Time
+}
+
+// circuitBreakerStatus is the circuit breaker status served on circuitBreakerPath
+type circuitBreakerStatus struct {
+	Enabled   bool        `json:"enabled"`
+	Tripped   bool        `json:"tripped"`
+	TrippedAt *time.Time  `json:"tripped_at,omitempty"`
+	Takeovers []time.Time `json:"takeovers"`
+}
+
+// record counts a takeover at now
+func (b *circuitBreaker) record(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.takeovers = append(b.takeovers, now)
+}
+
+// trip trips the breaker at now if maxTakeovers happened within window, returning true if it did and the
+// number of takeovers within window. A tripped breaker stays tripped.
+func (b *circuitBreaker) trip(now time.Time, maxTakeovers int, window time.Duration) (bool, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recent := b.takeovers[:0]
+	for _, takeover := range b.takeovers {
+		if now.Sub(takeover) < window {
+			recent = append(recent, takeover)
+		}
+	}
+	b.takeovers = recent
+
+	if b.trippedAt.IsZero() && len(b.takeovers) >= maxTakeovers {
+		b.trippedAt = now
+		return true, len(b.takeovers)
+	}
+	return false, len(b.takeovers)
+}
+
+// tripped returns when the breaker tripped, zero if it is not tripped
+func (b *circuitBreaker) tripped() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.trippedAt
+}
+
+// reset closes the breaker and forgets the takeovers counted so far, returning true if it was tripped
+func (b *circuitBreaker) reset() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasTripped := !b.trippedAt.IsZero()
+	b.trippedAt = time.Time{}
+	b.takeovers = nil
+	return wasTripped
+}
+
+// status returns the circuit breaker status
+func (b *circuitBreaker) status(enabled bool) circuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := circuitBreakerStatus{Enabled: enabled, Takeovers: append([]time.Time{}, b.takeovers...)}
+	if !b.trippedAt.IsZero() {
+		trippedAt := b.trippedAt
+		status.Tripped = true
+		status.TrippedAt = &trippedAt
+	}
+	return status
+}
+
+// circuitBreakerAllowsTakeover returns false if failover.circuit_breaker has frozen automatic takeovers, tripping it
+// and sending a critical notification when this takeover would be one too many within its window
+func (m *Manager) circuitBreakerAllowsTakeover(now time.Time) bool {
+	breaker := m.cfg.Failover.CircuitBreaker
+	if !breaker.Enabled {
+		return true
+	}
+
+	if trippedAt := m.circuitBreaker.tripped(); !trippedAt.IsZero() {
+		m.logger.Error("failover circuit breaker tripped - automatic takeovers frozen until reset", "tripped_at", trippedAt)
+		return false
+	}
+
+	justTripped, takeovers := m.circuitBreaker.trip(now, breaker.MaxFailovers, breaker.WindowDuration)
+	if !justTripped {
+		return true
+	}
+
+	m.logger.Error("failover circuit breaker tripped - automatic takeovers frozen until reset",
+		"takeovers", takeovers,
+		"window", breaker.WindowDuration,
+	)
+	if m.notifyManager != nil {
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventCircuitBreakerTripped,
+			Severity:      notify.SeverityCritical,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			Message: fmt.Sprintf("%d takeovers within %s - automatic takeovers are frozen until the circuit breaker is reset",
+				takeovers, breaker.WindowDuration),
+			Details: map[string]string{
+				"takeovers":     strconv.Itoa(takeovers),
+				"max_failovers": strconv.Itoa(breaker.MaxFailovers),
+				"window":        breaker.WindowDuration.String(),
+			},
+		})
+	}
+	return false
+}
+
+// resetCircuitBreaker resets a tripped circuit breaker so automatic takeovers resume, telling everyone who reset it
+func (m *Manager) resetCircuitBreaker(by string) string {
+	if !m.circuitBreaker.reset() {
+		return "Failover circuit breaker is not tripped"
+	}
+
+	m.logger.Warn("failover circuit breaker reset - automatic takeovers resumed", "by", by)
+	if m.notifyManager != nil {
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventCircuitBreakerReset,
+			Severity:      notify.SeverityInfo,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			Message:       fmt.Sprintf("Failover circuit breaker reset by %s - automatic takeovers resumed", by),
+			Details:       map[string]string{"reset_by": by},
+		})
+	}
+	return fmt.Sprintf("Failover circuit breaker reset - %s resumes automatic takeovers", m.cfg.Validator.Name)
+}
+
+// handleCircuitBreaker serves the circuit breaker status, resetting it with DELETE - resets are only accepted
+// from localhost
+func (m *Manager) handleCircuitBreaker(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		if !isLoopbackRequest(r) {
+			http.Error(w, "the circuit breaker can only be reset from localhost", http.StatusForbidden)
+			return
+		}
+		m.resetCircuitBreaker("api")
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(m.circuitBreaker.status(m.cfg.Failover.CircuitBreaker.Enabled)); err != nil {
+		m.logger.Error("failed to encode circuit breaker status", "error", err)
+	}
+}
@@ -0,0 +1,92 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_CircuitBreakerAllowsTakeover(t *testing.T) {
+	cfg := createTestConfig()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// disabled by default
+	for i := 0; i < 5; i++ {
+		manager.circuitBreaker.record(now)
+	}
+	assert.True(t, manager.circuitBreakerAllowsTakeover(now))
+	manager.circuitBreaker.reset()
+
+	cfg.Failover.CircuitBreaker.Enabled = true
+	cfg.Failover.CircuitBreaker.SetDefaults()
+
+	// two takeovers an hour are allowed
+	assert.True(t, manager.circuitBreakerAllowsTakeover(now))
+	manager.circuitBreaker.record(now)
+	assert.True(t, manager.circuitBreakerAllowsTakeover(now.Add(10*time.Minute)))
+	manager.circuitBreaker.record(now.Add(10 * time.Minute))
+
+	// takeovers drop out of the window
+	assert.True(t, manager.circuitBreakerAllowsTakeover(now.Add(time.Hour)))
+	manager.circuitBreaker.record(now.Add(time.Hour))
+
+	// a third within the hour trips the breaker
+	assert.False(t, manager.circuitBreakerAllowsTakeover(now.Add(time.Hour+time.Minute)))
+	assert.Equal(t, now.Add(time.Hour+time.Minute), manager.circuitBreaker.tripped())
+
+	// and it stays tripped until reset
+	assert.False(t, manager.circuitBreakerAllowsTakeover(now.Add(24*time.Hour)))
+	assert.Contains(t, manager.resetCircuitBreaker("test"), "Failover circuit breaker reset")
+	assert.True(t, manager.circuitBreaker.tripped().IsZero())
+	assert.True(t, manager.circuitBreakerAllowsTakeover(now.Add(24*time.Hour)))
+	assert.Equal(t, "Failover circuit breaker is not tripped", manager.resetCircuitBreaker("test"))
+}
+
+func TestManager_HandleCircuitBreaker(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.CircuitBreaker.Enabled = true
+	cfg.Failover.CircuitBreaker.SetDefaults()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+
+	now := time.Now()
+	manager.circuitBreaker.record(now)
+	manager.circuitBreaker.record(now)
+	require.False(t, manager.circuitBreakerAllowsTakeover(now))
+
+	serve := func(method string, remoteAddr string) (*httptest.ResponseRecorder, circuitBreakerStatus) {
+		req := httptest.NewRequest(method, circuitBreakerPath, nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		manager.handleCircuitBreaker(recorder, req)
+		status := circuitBreakerStatus{}
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		}
+		return recorder, status
+	}
+
+	// anyone may see the status
+	recorder, status := serve(http.MethodGet, "192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Tripped)
+	assert.Len(t, status.Takeovers, 2)
+
+	// only localhost may reset it
+	recorder, _ = serve(http.MethodDelete, "192.0.2.1:1234")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	recorder, _ = serve(http.MethodPost, "127.0.0.1:1234")
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder, status = serve(http.MethodDelete, "127.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.False(t, status.Tripped)
+	assert.Nil(t, status.TrippedAt)
+	assert.Empty(t, status.Takeovers)
+}
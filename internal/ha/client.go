@@ -0,0 +1,39 @@
+package ha
+
+import (
+	"strings"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/client"
+)
+
+// refreshClientInfo detects the validator client flavor and version, logging when it changes - validator.client,
+// when set, is the flavor
+func (m *Manager) refreshClientInfo() {
+	version := ""
+	versionResult, err := m.localRPC.GetVersion(m.ctx)
+	if err != nil {
+		m.logger.Debug("failed to get validator version", "error", err)
+	} else {
+		version = versionResult.SolanaCore
+	}
+
+	info := client.Detect(client.DetectOptions{Version: version})
+
+	// validator.client is what we set the identity of, whatever is detected - a mismatch is warned of once
+	if configured := client.Flavor(m.cfg.Validator.Client); configured != "" {
+		if info.Flavor != client.FlavorUnknown && info.Flavor != configured && m.clientInfo.Source != "config" {
+			m.logger.Warn("detected validator client differs from validator.client", "detected", info.Flavor, "configured", configured)
+		}
+		info.Flavor, info.Source = configured, "config"
+	}
+
+	if info != m.clientInfo {
+		m.logger.Info("detected validator client", "flavor", info.Flavor, "version", info.Version, "source", info.Source)
+	}
+	m.clientInfo = info
+}
+
+// isHealthMethodUnsupported returns true if a getHealth error means the client's RPC does not implement it
+func isHealthMethodUnsupported(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "method not found")
+}
@@ -0,0 +1,45 @@
+package ha
+
+import (
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+)
+
+// lastRoleChangeAt returns when this node last changed between active and passive - zero if it hasn't, as the role
+// it started in is not a change unless it differs from the role restored from failover.state_file. Unknown roles,
+// e.g. while the validator restarts, are skipped.
+func (m *Manager) lastRoleChangeAt() time.Time {
+	changedAt := m.restoredRoleChangedAt
+	lastRole := m.restoredRole
+	for _, change := range m.roleHistory {
+		if change.Role != constants.RoleNameActive && change.Role != constants.RoleNamePassive {
+			continue
+		}
+		if lastRole != "" && change.Role != lastRole {
+			changedAt = change.Since
+		}
+		lastRole = change.Role
+	}
+	return changedAt
+}
+
+// takeoverCooldown returns how long after the last role change a takeover is held back - failover.cooldown_duration,
+// extended by failover.flap_detection while flapping
+func (m *Manager) takeoverCooldown() time.Duration {
+	cooldown := m.cfg.Failover.CooldownDuration
+	if detection := m.cfg.Failover.FlapDetection; m.flapping {
+		cooldown = max(cooldown*time.Duration(detection.DampeningFactor), detection.MinCooldownDuration)
+	}
+	return cooldown
+}
+
+// takeoverCooldownRemaining returns how much longer the takeover cooldown holds back a takeover at now
+func (m *Manager) takeoverCooldownRemaining(now time.Time) time.Duration {
+	changedAt := m.lastRoleChangeAt()
+	cooldown := m.takeoverCooldown()
+	if cooldown <= 0 || changedAt.IsZero() {
+		return 0
+	}
+	return max(changedAt.Add(cooldown).Sub(now), 0)
+}
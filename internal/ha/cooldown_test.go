@@ -0,0 +1,50 @@
+package ha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_LastRoleChangeAt(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// the starting role is not a change
+	manager.recordRole("passive", startedAt)
+	assert.True(t, manager.lastRoleChangeAt().IsZero())
+
+	// nor is coming back to it after a restart
+	manager.recordRole("unknown", startedAt.Add(time.Minute))
+	manager.recordRole("passive", startedAt.Add(2*time.Minute))
+	assert.True(t, manager.lastRoleChangeAt().IsZero())
+
+	manager.recordRole("active", startedAt.Add(time.Hour))
+	assert.Equal(t, startedAt.Add(time.Hour), manager.lastRoleChangeAt())
+
+	manager.recordRole("unknown", startedAt.Add(2*time.Hour))
+	manager.recordRole("passive", startedAt.Add(3*time.Hour))
+	assert.Equal(t, startedAt.Add(3*time.Hour), manager.lastRoleChangeAt())
+}
+
+func TestManager_TakeoverCooldownRemaining(t *testing.T) {
+	cfg := createTestConfig()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager.recordRole("active", startedAt)
+	manager.recordRole("passive", startedAt.Add(time.Hour))
+
+	// disabled by default
+	assert.Zero(t, manager.takeoverCooldownRemaining(startedAt.Add(time.Hour+time.Minute)))
+
+	cfg.Failover.CooldownDuration = 10 * time.Minute
+	assert.Equal(t, 9*time.Minute, manager.takeoverCooldownRemaining(startedAt.Add(time.Hour+time.Minute)))
+	assert.Zero(t, manager.takeoverCooldownRemaining(startedAt.Add(time.Hour+10*time.Minute)))
+	assert.Zero(t, manager.takeoverCooldownRemaining(startedAt.Add(2*time.Hour)))
+
+	// no role change yet
+	manager.roleHistory = nil
+	manager.recordRole("passive", startedAt)
+	assert.Zero(t, manager.takeoverCooldownRemaining(startedAt.Add(time.Minute)))
+}
@@ -0,0 +1,146 @@
+package ha
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// drainPath is the health server path this node is drained on
+const drainPath = "/failover/drain"
+
+// DrainStatus is whether this node is draining for planned maintenance and how far the drain has got
+type DrainStatus struct {
+	Draining bool      `json:"draining"`
+	Since    time.Time `json:"since,omitzero"`
+	// Message is the progress of handing over the active role, e.g. what it is waiting for
+	Message string `json:"message,omitempty"`
+}
+
+// drain tracks draining this node, which hands the active role over to a peer away from our leader slots, never
+// takes over and keeps notifications in maintenance mode until the drain is turned off
+type drain struct {
+	mu     sync.Mutex
+	status DrainStatus
+	// setMaintenance is true if draining turned notification maintenance mode on, so turns it off again
+	setMaintenance bool
+}
+
+// get returns the drain status
+func (d *drain) get() DrainStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status
+}
+
+// draining returns true while the node is draining
+func (d *drain) draining() bool {
+	return d.get().Draining
+}
+
+// progress records how far the drain has got, if still draining
+func (d *drain) progress(format string, args ...any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.status.Draining {
+		d.status.Message = fmt.Sprintf(format, args...)
+	}
+}
+
+// setDraining starts or ends draining this node, returning the drain status
+func (m *Manager) setDraining(draining bool, by string) DrainStatus {
+	m.drain.mu.Lock()
+	defer m.drain.mu.Unlock()
+
+	if draining == m.drain.status.Draining {
+		return m.drain.status
+	}
+
+	if draining {
+		m.logger.Warn("draining - handing over the active role and not taking over until the drain is turned off", "by", by)
+		m.drain.status = DrainStatus{Draining: true, Since: time.Now().UTC(), Message: "draining"}
+		if m.notifyManager != nil && !m.notifyManager.QuietStatus().Maintenance {
+			m.notifyManager.SetMaintenance(true)
+			m.drain.setMaintenance = true
+		}
+		return m.drain.status
+	}
+
+	m.logger.Warn("drain turned off - taking part in failovers again", "by", by)
+	m.drain.status = DrainStatus{}
+	if m.drain.setMaintenance && m.notifyManager != nil {
+		m.notifyManager.SetMaintenance(false)
+	}
+	m.drain.setMaintenance = false
+	return m.drain.status
+}
+
+// continueDrain hands the active role over to a peer while draining, once a peer is in gossip to take over and our
+// next leader slot is at least failover.drain.leader_slot_gap slots away - or failover.drain.max_wait_duration has
+// passed since the drain started
+func (m *Manager) continueDrain() {
+	status := m.drain.get()
+	if !status.Draining {
+		return
+	}
+	if !m.isSelfActive() {
+		m.drain.progress("passive - not taking over until the drain is turned off")
+		return
+	}
+	if !m.gossipState.HasPeers(m.peerSelf.IP) {
+		m.logger.Warn("draining - waiting for a peer in gossip to hand the active role over to")
+		m.drain.progress("waiting for a peer in gossip to hand the active role over to")
+		return
+	}
+
+	drainCfg := m.cfg.Failover.Drain
+	waited := time.Since(status.Since)
+	gap, err := m.clusterRPC.GetLeaderSlotGap(m.ctx, m.cfg.Validator.Identities.ActiveKeyPair.PublicKey())
+	switch {
+	case waited >= drainCfg.MaxWaitDuration:
+		m.logger.Warn("draining - failover.drain.max_wait_duration passed, handing over regardless of leader slots", "waited", waited.Round(time.Second))
+	case err != nil:
+		m.logger.Warn("draining - failed to get leader schedule, waiting to hand over", "error", err)
+		m.drain.progress("waiting for the leader schedule: %s", err)
+		return
+	case gap.Slots < drainCfg.LeaderSlotGap:
+		m.logger.Info("draining - waiting for a gap in our leader slots to hand over", "next_leader_slot_in", gap.Slots, "leader_slot_gap", drainCfg.LeaderSlotGap)
+		m.drain.progress("waiting for a gap in our leader slots - next leader slot in %d slots", gap.Slots)
+		return
+	default:
+		m.logger.Warn("draining - handing over the active role", "next_leader_slot_in", gap.Slots, "scheduled", gap.Scheduled)
+	}
+
+	result := m.demote()
+	if result.Error != "" {
+		m.logger.Error("draining - failed to hand over the active role", "error", result.Error)
+		m.drain.progress("failed to hand over the active role, retrying: %s", result.Error)
+		return
+	}
+	m.drain.progress("handed over the active role - passive, not taking over until the drain is turned off")
+}
+
+// handleDrain serves the drain status, starting a drain with POST and ending it with DELETE - changes are only
+// accepted from localhost
+func (m *Manager) handleDrain(w http.ResponseWriter, r *http.Request) {
+	status := m.drain.get()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodDelete:
+		if !isLoopbackRequest(r) {
+			http.Error(w, "draining can only be changed from localhost", http.StatusForbidden)
+			return
+		}
+		status = m.setDraining(r.Method == http.MethodPost, "api")
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		m.logger.Error("failed to encode drain status", "error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_HandleDrain(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	manager.notifyManager = notify.NewManager(notify.ManagerOptions{Config: &config.NotificationConfig{Enabled: true}})
+
+	serve := func(method string, remoteAddr string) (*httptest.ResponseRecorder, DrainStatus) {
+		req := httptest.NewRequest(method, drainPath, nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		manager.handleDrain(recorder, req)
+		status := DrainStatus{}
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		}
+		return recorder, status
+	}
+
+	// anyone may see the status, only localhost may change it
+	recorder, status := serve(http.MethodGet, "192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.False(t, status.Draining)
+	recorder, _ = serve(http.MethodPost, "192.0.2.1:1234")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// draining puts notifications in maintenance mode
+	recorder, status = serve(http.MethodPost, "127.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, status.Draining)
+	assert.False(t, status.Since.IsZero())
+	assert.True(t, manager.drain.draining())
+	assert.True(t, manager.notifyManager.QuietStatus().Maintenance)
+
+	manager.drain.progress("waiting for a gap in our leader slots - next leader slot in %d slots", 4)
+	_, status = serve(http.MethodGet, "127.0.0.1:1234")
+	assert.Equal(t, "waiting for a gap in our leader slots - next leader slot in 4 slots", status.Message)
+
+	// and ending the drain takes them out of it
+	recorder, status = serve(http.MethodDelete, "127.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, DrainStatus{}, status)
+	assert.False(t, manager.notifyManager.QuietStatus().Maintenance)
+
+	// unless maintenance mode was already on
+	manager.notifyManager.SetMaintenance(true)
+	manager.setDraining(true, "test")
+	manager.setDraining(false, "test")
+	assert.True(t, manager.notifyManager.QuietStatus().Maintenance)
+}
@@ -0,0 +1,87 @@
+package ha
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// electionRank returns our rank among the election candidates - ourselves and the peers in gossip, ranked by
+// descending priority then ascending IP - and how many candidates there are. Peers missing from gossip are not
+// candidates, so a dual failure doesn't leave us waiting on a node that can't take over.
+func (m *Manager) electionRank() (rank int, candidates int) {
+	candidatePeers := config.Peers{}
+	for name, peer := range m.peers() {
+		if peer.IP != m.peerSelf.IP && m.gossipState.HasIP(peer.IP) {
+			candidatePeers[name] = peer
+		}
+	}
+	candidatePeers[m.peerSelf.Name] = *m.peerSelf
+
+	return candidatePeers.GetRankedIPs()[m.peerSelf.IP], len(candidatePeers)
+}
+
+// checkPreemption hands the active role over to the best ranked peer with a higher priority than ours once it has
+// been ready for failover.election.preemption_delay, if failover.election.preemption is higher_priority
+func (m *Manager) checkPreemption(now time.Time) {
+	election := m.cfg.Failover.Election
+	if election.Preemption != config.PreemptionHigherPriority || m.drain.draining() || !m.isSelfActive() {
+		m.resetPreemption()
+		return
+	}
+
+	peer, ok := m.preemptingPeer()
+	if !ok {
+		if m.preemptingPeerName != "" {
+			m.logger.Info("higher priority peer is no longer ready - not preempted", "peer", m.preemptingPeerName)
+		}
+		m.resetPreemption()
+		return
+	}
+
+	logger := m.logger.With("peer", peer.Name, "peer_priority", peer.Priority, "priority", m.peerSelf.Priority)
+	if peer.Name != m.preemptingPeerName {
+		m.preemptingPeerName = peer.Name
+		m.preemptionReadySince = now
+		logger.Info("higher priority peer is ready - handing the active role over once it has been ready for failover.election.preemption_delay",
+			"preemption_delay", election.PreemptionDelay)
+		return
+	}
+	if now.Sub(m.preemptionReadySince) < election.PreemptionDelay {
+		return
+	}
+
+	logger.Warn("preempted by a higher priority peer - handing the active role over")
+	if result := m.handOver(peer.Name, election.PreemptionDelay); result.Error != "" {
+		logger.Error("failed to hand the active role over to the higher priority peer", "error", result.Error)
+	}
+	m.resetPreemption()
+}
+
+// preemptingPeer returns the best ranked peer with a higher priority than ours that is ready to take over
+func (m *Manager) preemptingPeer() (config.Peer, bool) {
+	var higherPriorityPeers []config.Peer
+	for _, peer := range m.peers() {
+		if peer.Priority > m.peerSelf.Priority && peer.IP != m.peerSelf.IP {
+			higherPriorityPeers = append(higherPriorityPeers, peer)
+		}
+	}
+	slices.SortFunc(higherPriorityPeers, func(a, b config.Peer) int {
+		return cmp.Or(cmp.Compare(b.Priority, a.Priority), cmp.Compare(a.IP, b.IP))
+	})
+
+	for _, peer := range higherPriorityPeers {
+		if err := m.checkPeerReady(peer.Name); err == nil {
+			return peer, true
+		}
+	}
+	return config.Peer{}, false
+}
+
+// resetPreemption forgets the higher priority peer waiting to preempt us
+func (m *Manager) resetPreemption() {
+	m.preemptingPeerName = ""
+	m.preemptionReadySince = time.Time{}
+}
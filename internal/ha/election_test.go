@@ -0,0 +1,59 @@
+package ha
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_ElectionRank(t *testing.T) {
+	var ready atomic.Bool
+	manager := newReadyPeerTestManager(t, &ready)
+
+	// peers missing from gossip are not candidates, and ties are broken by IP
+	rank, candidates := manager.electionRank()
+	assert.Equal(t, 2, rank)
+	assert.Equal(t, 2, candidates)
+
+	// the highest priority ranks first
+	manager.peerSelf.Priority = 10
+	rank, _ = manager.electionRank()
+	assert.Equal(t, 1, rank)
+}
+
+func TestManager_CheckPreemption(t *testing.T) {
+	var ready atomic.Bool
+	ready.Store(true)
+	manager := newReadyPeerTestManager(t, &ready)
+	manager.cfg.Failover.Failback.Enabled = false
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// never preempted by default
+	manager.checkPreemption(now)
+	assert.Empty(t, manager.preemptingPeerName)
+
+	// nor by a peer without a higher priority
+	manager.cfg.Failover.Election = config.Election{Preemption: config.PreemptionHigherPriority}
+	manager.cfg.Failover.Election.SetDefaults()
+	manager.checkPreemption(now)
+	assert.Empty(t, manager.preemptingPeerName)
+
+	// a ready higher priority peer waits out preemption_delay
+	primary := manager.cfg.Failover.Peers["primary"]
+	primary.Priority = 10
+	manager.cfg.Failover.Peers["primary"] = primary
+	manager.checkPreemption(now)
+	assert.Equal(t, "primary", manager.preemptingPeerName)
+	assert.Equal(t, now, manager.preemptionReadySince)
+	manager.checkPreemption(now.Add(time.Minute))
+	assert.Equal(t, now, manager.preemptionReadySince)
+
+	// and starts over once it is no longer ready
+	ready.Store(false)
+	manager.checkPreemption(now.Add(2 * time.Minute))
+	assert.Empty(t, manager.preemptingPeerName)
+	assert.True(t, manager.preemptionReadySince.IsZero())
+}
@@ -0,0 +1,154 @@
+package ha
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// roleChange is a role this node was seen in during this run, from when it was first seen
+type roleChange struct {
+	Role  string    `json:"role"`
+	Since time.Time `json:"since"`
+}
+
+// exitReport summarises a run of the manager, from start to shutdown
+type exitReport struct {
+	ValidatorName string       `json:"validator_name"`
+	StartedAt     time.Time    `json:"started_at"`
+	StoppedAt     time.Time    `json:"stopped_at"`
+	Uptime        string       `json:"uptime"`
+	RoleHistory   []roleChange `json:"role_history"`
+	// EventsEmitted counts events by type, whether or not they were sent
+	EventsEmitted           map[notify.EventType]int `json:"events_emitted"`
+	NotificationsDelivered  int                      `json:"notifications_delivered"`
+	NotificationsFailed     int                      `json:"notifications_failed"`
+	NotificationSuccessRate *float64                 `json:"notification_success_rate,omitempty"`
+	Error                   string                   `json:"error,omitempty"`
+}
+
+// recordRole adds role to the role history if it differs from the last role seen
+func (m *Manager) recordRole(role string, now time.Time) {
+	if len(m.roleHistory) > 0 && m.roleHistory[len(m.roleHistory)-1].Role == role {
+		return
+	}
+	m.roleHistory = append(m.roleHistory, roleChange{Role: role, Since: now.UTC()})
+}
+
+// newExitReport builds the exit report for this run, runErr being the error the run stopped with if any
+func (m *Manager) newExitReport(stoppedAt time.Time, runErr error) exitReport {
+	report := exitReport{
+		ValidatorName: m.cfg.Validator.Name,
+		StartedAt:     m.startedAt.UTC(),
+		StoppedAt:     stoppedAt.UTC(),
+		Uptime:        stoppedAt.Sub(m.startedAt).Round(time.Second).String(),
+		RoleHistory:   m.roleHistory,
+		EventsEmitted: map[notify.EventType]int{},
+	}
+
+	if m.notifyManager != nil {
+		stats := m.notifyManager.Stats()
+		report.EventsEmitted = stats.Events
+		report.NotificationsDelivered = stats.Delivered
+		report.NotificationsFailed = stats.Failed
+		if rate, ok := stats.SuccessRate(); ok {
+			report.NotificationSuccessRate = &rate
+		}
+	}
+
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+
+	return report
+}
+
+// roleHistoryString returns the role history as e.g. "passive since 12:00:00Z, active since 14:05:10Z"
+func (r exitReport) roleHistoryString() string {
+	if len(r.RoleHistory) == 0 {
+		return "none"
+	}
+
+	changes := make([]string, 0, len(r.RoleHistory))
+	for _, change := range r.RoleHistory {
+		changes = append(changes, fmt.Sprintf("%s since %s", change.Role, change.Since.Format(time.RFC3339)))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// successRateString returns the notification success rate as a percentage, or n/a if nothing was sent
+func (r exitReport) successRateString() string {
+	if r.NotificationSuccessRate == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", *r.NotificationSuccessRate*100)
+}
+
+// emitExitReport logs the exit report, appends it to log.exit_report_file if set and sends it with the shutdown notification
+func (m *Manager) emitExitReport(runErr error) {
+	report := m.newExitReport(time.Now(), runErr)
+	eventsEmitted := notify.Stats{Events: report.EventsEmitted}.TotalEvents()
+
+	m.logger.Info("exit report",
+		"uptime", report.Uptime,
+		"role_history", report.roleHistoryString(),
+		"events_emitted", eventsEmitted,
+		"notifications_delivered", report.NotificationsDelivered,
+		"notifications_failed", report.NotificationsFailed,
+		"notification_success_rate", report.successRateString(),
+	)
+
+	if m.cfg.Log.ExitReportFile != "" {
+		if err := appendExitReport(m.cfg.Log.ExitReportFile, report); err != nil {
+			m.logger.Error("failed to write exit report", "file", m.cfg.Log.ExitReportFile, "error", err)
+		}
+	}
+
+	if m.notifyManager == nil {
+		return
+	}
+
+	details := map[string]string{
+		"uptime":                    report.Uptime,
+		"role_history":              report.roleHistoryString(),
+		"events_emitted":            fmt.Sprintf("%d", eventsEmitted),
+		"notification_success_rate": report.successRateString(),
+	}
+	if report.Error != "" {
+		details["error"] = report.Error
+	}
+
+	// sent synchronously so it goes out before the notification manager is closed
+	m.notifyManager.Notify(notify.Event{
+		Type:          notify.EventShutdown,
+		Severity:      notify.SeverityWarning,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message:       fmt.Sprintf("HA manager stopped after %s", report.Uptime),
+		Details:       details,
+	})
+}
+
+// appendExitReport appends the report as a JSON line to path
+func appendExitReport(path string, report exitReport) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal exit report: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
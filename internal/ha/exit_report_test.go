@@ -0,0 +1,47 @@
+package ha
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ExitReport(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager.startedAt = startedAt
+
+	// only role changes are recorded
+	manager.recordRole("passive", startedAt)
+	manager.recordRole("passive", startedAt.Add(time.Minute))
+	manager.recordRole("active", startedAt.Add(2*time.Hour))
+
+	report := manager.newExitReport(startedAt.Add(3*time.Hour+5*time.Second), nil)
+	assert.Equal(t, "3h0m5s", report.Uptime)
+	require.Len(t, report.RoleHistory, 2)
+	assert.Equal(t, "passive since 2025-01-01T12:00:00Z, active since 2025-01-01T14:00:00Z", report.roleHistoryString())
+	assert.Empty(t, report.EventsEmitted)
+	assert.Nil(t, report.NotificationSuccessRate)
+	assert.Equal(t, "n/a", report.successRateString())
+
+	// each report is appended as a JSON line
+	path := filepath.Join(t.TempDir(), "exit-reports.jsonl")
+	require.NoError(t, appendExitReport(path, report))
+	require.NoError(t, appendExitReport(path, report))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var decoded exitReport
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &decoded))
+	assert.Equal(t, report.ValidatorName, decoded.ValidatorName)
+	assert.Equal(t, report.RoleHistory, decoded.RoleHistory)
+}
@@ -0,0 +1,124 @@
+package ha
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// failback steps notified as the active peer hands the active role back to failover.failback.primary
+const (
+	failbackStepStabilizing      = "stabilizing"
+	failbackStepUnstable         = "unstable"
+	failbackStepWaitingForWindow = "waiting_for_window"
+	failbackStepHandingOver      = "handing_over"
+	failbackStepHandedOver       = "handed_over"
+	failbackStepFailed           = "failed"
+)
+
+// checkFailback hands the active role back to failover.failback.primary if we are active and it has been healthy,
+// passive and not draining for stabilization_duration, during one of its windows - each step is notified once
+func (m *Manager) checkFailback(now time.Time) {
+	failback := m.cfg.Failover.Failback
+	if !failback.Enabled || failback.Primary == m.cfg.Validator.Name || m.drain.draining() || !m.isSelfActive() {
+		m.resetFailback()
+		return
+	}
+
+	logger := m.logger.With("primary", failback.Primary)
+	if err := m.checkPeerReady(failback.Primary); err != nil {
+		if !m.failbackReadySince.IsZero() {
+			logger.Warn("failback primary is no longer ready - waiting for it to stabilize again", "error", err)
+			m.notifyFailback(failbackStepUnstable, err.Error(), notify.SeverityWarning)
+		}
+		m.resetFailback()
+		return
+	}
+
+	if m.failbackReadySince.IsZero() {
+		m.failbackReadySince = now
+		logger.Info("failback primary is ready - failing back once it is stable", "stabilization_duration", failback.StabilizationDuration)
+		m.notifyFailback(failbackStepStabilizing,
+			fmt.Sprintf("failing back once it has been healthy for %s", failback.StabilizationDuration), notify.SeverityInfo)
+		return
+	}
+	if now.Sub(m.failbackReadySince) < failback.StabilizationDuration {
+		return
+	}
+
+	if !failback.InWindow(now) {
+		if m.failbackStep != failbackStepWaitingForWindow {
+			logger.Info("failback primary is stable - waiting for a failover.failback window to fail back")
+			m.notifyFailback(failbackStepWaitingForWindow, "stable - waiting for a failback window", notify.SeverityInfo)
+		}
+		return
+	}
+
+	logger.Warn("failing back - handing the active role back to the primary")
+	m.notifyFailback(failbackStepHandingOver, "handing the active role back", notify.SeverityWarning)
+	result := m.handOver(failback.Primary, failback.HoldDuration)
+	if result.Error != "" {
+		logger.Error("failback failed", "error", result.Error)
+		m.notifyFailback(failbackStepFailed, result.Error, notify.SeverityError)
+	} else {
+		m.notifyFailback(failbackStepHandedOver, "handed the active role back", notify.SeverityInfo)
+	}
+	// a failed failback waits for the primary to stabilize again rather than retrying every HA check
+	m.resetFailback()
+}
+
+// resetFailback forgets how long failover.failback.primary has been ready for
+func (m *Manager) resetFailback() {
+	m.failbackReadySince = time.Time{}
+	m.failbackStep = ""
+}
+
+// failbackHoldsTakeover returns true while another passive peer holds back its takeover for
+// failover.failback.primary to take the active role, for up to hold_duration once the primary is found ready
+func (m *Manager) failbackHoldsTakeover(now time.Time) bool {
+	failback := m.cfg.Failover.Failback
+	if !failback.Enabled || failback.Primary == m.cfg.Validator.Name {
+		return false
+	}
+
+	if m.failbackHoldStartedAt.IsZero() {
+		if err := m.checkPeerReady(failback.Primary); err != nil {
+			return false
+		}
+		m.failbackHoldStartedAt = now
+	}
+	if now.Sub(m.failbackHoldStartedAt) >= failback.HoldDuration {
+		return false
+	}
+
+	m.logger.Error("holding back takeover for the failback primary to take over", "primary", failback.Primary,
+		"until", m.failbackHoldStartedAt.Add(failback.HoldDuration).UTC())
+	return true
+}
+
+// notifyFailback tells everyone how far handing the active role back to failover.failback.primary has got
+func (m *Manager) notifyFailback(step string, message string, severity notify.Severity) {
+	m.failbackStep = step
+	if m.notifyManager == nil {
+		return
+	}
+
+	primary := m.cfg.Failover.Failback.Primary
+	details := map[string]string{
+		"primary": primary,
+		"step":    step,
+	}
+	if step == failbackStepHandedOver || step == failbackStepFailed {
+		details["failover_id"] = m.failoverID
+	}
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventFailback,
+		Severity:      severity,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message:       fmt.Sprintf("Failback to %s %s: %s", primary, step, message),
+		Details:       details,
+	})
+}
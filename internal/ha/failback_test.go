@@ -0,0 +1,143 @@
+package ha
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/cache"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+	"github.com/sol-strategies/solana-validator-ha/internal/gossip"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReadyPeerTestManager returns a manager that is active, with peer primary - failover.failback.primary - in gossip on a mock server
+// serving the primary's status as healthy and passive while ready is true
+func newReadyPeerTestManager(t *testing.T, ready *atomic.Bool) *Manager {
+	cfg := createTestConfig()
+	activePubkey := cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
+	passivePubkey := cfg.Validator.Identities.PassiveKeyPair.PublicKey().String()
+
+	var gossipAddress string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			state := cache.State{Status: constants.StatusHealthy, Role: constants.RoleNamePassive}
+			if !ready.Load() {
+				state.Status = constants.StatusUnhealthy
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(state))
+			return
+		}
+
+		var req struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		results := map[string]any{
+			"getClusterNodes": []any{map[string]any{"pubkey": passivePubkey, "gossip": gossipAddress}},
+			"getIdentity":     map[string]any{"identity": activePubkey},
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": results[req.Method]}))
+	}))
+	t.Cleanup(server.Close)
+	gossipAddress = server.Listener.Addr().String()
+
+	_, port, err := net.SplitHostPort(gossipAddress)
+	require.NoError(t, err)
+	cfg.Prometheus.HealthCheckPort, err = strconv.Atoi(port)
+	require.NoError(t, err)
+	cfg.Failover.Peers["primary"] = config.Peer{Name: "primary", IP: "127.0.0.1"}
+	cfg.Failover.Failback = config.Failback{Enabled: true, Primary: "primary"}
+	cfg.Failover.Failback.SetDefaults()
+
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.localRPC = rpc.NewClient("test", server.URL)
+	manager.gossipState = gossip.NewState(gossip.Options{
+		ClusterRPC:   rpc.NewClient("test", server.URL),
+		ActivePubkey: activePubkey,
+		ConfigPeers:  cfg.Failover.Peers,
+	})
+	manager.gossipState.Refresh()
+	manager.observePeers(manager.gossipState.GetPeerStates())
+	return manager
+}
+
+// failbackSteps returns the failback steps sent on events so far
+func failbackSteps(events <-chan notify.Event) []string {
+	var steps []string
+	for {
+		select {
+		case event := <-events:
+			if event.Type == notify.EventFailback {
+				steps = append(steps, event.Details["step"])
+			}
+		case <-time.After(100 * time.Millisecond):
+			return steps
+		}
+	}
+}
+
+func TestManager_CheckFailback(t *testing.T) {
+	var ready atomic.Bool
+	ready.Store(true)
+	manager := newReadyPeerTestManager(t, &ready)
+	events, unsubscribe := manager.Subscribe(16)
+	defer unsubscribe()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// the primary being ready starts its stabilization
+	manager.checkFailback(now)
+	assert.Equal(t, now, manager.failbackReadySince)
+
+	// nothing happens until it has been stable for stabilization_duration, and then only during a window
+	manager.cfg.Failover.Failback.Windows = []config.TimeWindow{{Start: "02:00", End: "04:00", Timezone: "UTC"}}
+	manager.checkFailback(now.Add(time.Minute))
+	manager.checkFailback(now.Add(10 * time.Minute))
+	manager.checkFailback(now.Add(11 * time.Minute))
+	assert.Equal(t, failbackStepWaitingForWindow, manager.failbackStep)
+
+	// the primary becoming unready starts over
+	ready.Store(false)
+	manager.checkFailback(now.Add(12 * time.Minute))
+	assert.True(t, manager.failbackReadySince.IsZero())
+
+	assert.Equal(t, []string{failbackStepStabilizing, failbackStepWaitingForWindow, failbackStepUnstable}, failbackSteps(events))
+
+	// the primary never fails back to itself
+	manager.cfg.Failover.Failback.Primary = manager.cfg.Validator.Name
+	ready.Store(true)
+	manager.checkFailback(now)
+	assert.True(t, manager.failbackReadySince.IsZero())
+}
+
+func TestManager_FailbackHoldsTakeover(t *testing.T) {
+	var ready atomic.Bool
+	manager := newReadyPeerTestManager(t, &ready)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// no hold while the primary is not ready
+	assert.False(t, manager.failbackHoldsTakeover(now))
+
+	// held for hold_duration once it is
+	ready.Store(true)
+	assert.True(t, manager.failbackHoldsTakeover(now))
+	assert.True(t, manager.failbackHoldsTakeover(now.Add(30*time.Second)))
+	assert.False(t, manager.failbackHoldsTakeover(now.Add(time.Minute)))
+
+	// disabled never holds
+	manager.failbackHoldStartedAt = time.Time{}
+	manager.cfg.Failover.Failback.Enabled = false
+	assert.False(t, manager.failbackHoldsTakeover(now))
+}
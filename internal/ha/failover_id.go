@@ -0,0 +1,27 @@
+package ha
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// newFailoverID returns an ID for a role transition, prefixed with the time it started so IDs sort in order
+func newFailoverID(now time.Time) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// beginFailover starts a role transition, returning the ID that ties together its logs, hook runs, events and
+// incident report. The ID is kept in the cached state, and so in /status and metrics, until the next transition.
+func (m *Manager) beginFailover(failoverStatus string) string {
+	m.failoverID = newFailoverID(time.Now())
+
+	state := m.cache.GetState()
+	state.FailoverStatus = failoverStatus
+	state.FailoverID = m.failoverID
+	m.cache.UpdateState(state)
+
+	return m.failoverID
+}
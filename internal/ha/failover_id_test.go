@@ -0,0 +1,51 @@
+package ha
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFailoverID(t *testing.T) {
+	id := newFailoverID(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Regexp(t, regexp.MustCompile(`^20250102T030405Z-[0-9a-f]{8}$`), id)
+	assert.NotEqual(t, id, newFailoverID(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+}
+
+func TestManager_EnsureActive_FailoverID(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.DryRun = true
+
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	events, unsubscribe := manager.Subscribe(4)
+	defer unsubscribe()
+
+	manager.ensureActive()
+
+	failoverID := manager.cache.GetState().FailoverID
+	require.NotEmpty(t, failoverID)
+	assert.Equal(t, failoverID, manager.failoverID)
+
+	for {
+		select {
+		case event := <-events:
+			if event.Type != notify.EventBecomingActive {
+				continue
+			}
+			assert.Equal(t, failoverID, event.Details["failover_id"])
+		case <-time.After(time.Second):
+			t.Fatal("becoming_active not sent")
+		}
+		break
+	}
+
+	// the next transition gets a new ID
+	manager.ensurePassive()
+	assert.NotEqual(t, failoverID, manager.cache.GetState().FailoverID)
+}
@@ -0,0 +1,17 @@
+package ha
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// logFailoverPlan logs the failover plan for becoming roleName on dry runs, so operators can check exactly
+// what a real failover would run
+func (m *Manager) logFailoverPlan(logger *log.Logger, roleName string, role *config.Role) {
+	if !m.cfg.Failover.DryRun {
+		return
+	}
+	for _, step := range m.cfg.Failover.RolePlan(roleName, role) {
+		logger.Info("dry run failover plan", "step", step)
+	}
+}
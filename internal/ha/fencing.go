@@ -0,0 +1,66 @@
+package ha
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// fenceOldActivePeer fences the peer last seen active before we take over, returning false if the takeover must
+// not go ahead - it couldn't be fenced and failover.fencing.must_succeed is set
+func (m *Manager) fenceOldActivePeer() bool {
+	oldActive, ok := m.gossipState.LastActivePeer()
+	if ok && oldActive.IPEquals(m.peerSelf.IP) {
+		m.incident.step("decision", "we were the last active peer - nothing to fence")
+		return true
+	}
+
+	startedAt := time.Now()
+	var err error
+	if ok {
+		err = m.fencePeer(oldActive.Name)
+	} else {
+		err = fmt.Errorf("no active peer seen since startup - unknown which peer to fence")
+	}
+	m.incident.timed("fencing", fmt.Sprintf("fenced old active peer %s", oldActive.Name), startedAt, err)
+	if err == nil {
+		return true
+	}
+
+	if m.cfg.Failover.Fencing.IsMustSucceed() {
+		m.logger.Error("failed to fence old active peer - unable to become active in failover", "peer", oldActive.Name, "error", err)
+		return false
+	}
+	m.logger.Warn("failed to fence old active peer - taking over as failover.fencing.must_succeed is false", "peer", oldActive.Name, "error", err)
+	return true
+}
+
+// fencePeer fences the named peer with its failover.fencing agents, in order, until one succeeds
+func (m *Manager) fencePeer(name string) error {
+	agents := m.cfg.Failover.Fencing.Peers[name]
+	if len(agents) == 0 {
+		return fmt.Errorf("failover.fencing.peers has no fence agents for peer %s", name)
+	}
+
+	errs := []error{}
+	for _, agent := range agents {
+		logger := m.logger.With("peer", name, "fence_agent", agent.Name, "type", agent.Type, "dry_run", m.cfg.Failover.DryRun)
+		logger.Warn("fencing old active peer", "plan", agent.Plan())
+		err := agent.Fence(m.ctx, config.FenceOptions{
+			DryRun:          m.cfg.Failover.DryRun,
+			LoggerPrefix:    m.logPrefix,
+			LoggerArgs:      []any{"failover_stage", "fencing", "peer", name},
+			AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
+		})
+		if err == nil {
+			logger.Warn("fenced old active peer")
+			return nil
+		}
+		logger.Error("fence agent failed", "error", err)
+		errs = append(errs, fmt.Errorf("fence agent %s: %w", agent.Name, err))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,44 @@
+package ha
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_FencePeer(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.DryRun = false
+	cfg.Failover.Fencing = config.Fencing{
+		Enabled: true,
+		Peers: map[string][]config.FenceAgent{
+			"peer1": {
+				{Name: "broken bmc", Type: config.FenceAgentTypeCommand, Command: "false"},
+				{Name: "stop instance", Type: config.FenceAgentTypeCommand, Command: "true"},
+			},
+			"peer2": {
+				{Name: "broken bmc", Type: config.FenceAgentTypeCommand, Command: "false"},
+			},
+		},
+	}
+	cfg.Failover.Fencing.SetDefaults()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	// agents are tried in order until one fences the peer
+	assert.NoError(t, manager.fencePeer("peer1"))
+
+	err := manager.fencePeer("peer2")
+	assert.ErrorContains(t, err, "fence agent broken bmc")
+
+	err = manager.fencePeer("peer3")
+	assert.EqualError(t, err, "failover.fencing.peers has no fence agents for peer peer3")
+
+	// with no active peer seen there is no knowing which peer to fence
+	assert.False(t, manager.fenceOldActivePeer())
+	mustSucceed := false
+	cfg.Failover.Fencing.MustSucceed = &mustSucceed
+	assert.True(t, manager.fenceOldActivePeer())
+}
@@ -0,0 +1,74 @@
+package ha
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// roleTransitionsSince returns how many times this node changed between active and passive after since during this
+// run - unknown roles, e.g. while the validator restarts, are skipped
+func (m *Manager) roleTransitionsSince(since time.Time) int {
+	transitions := 0
+	lastRole := ""
+	for _, change := range m.roleHistory {
+		if change.Role != constants.RoleNameActive && change.Role != constants.RoleNamePassive {
+			continue
+		}
+		if lastRole != "" && change.Role != lastRole && change.Since.After(since) {
+			transitions++
+		}
+		lastRole = change.Role
+	}
+	return transitions
+}
+
+// checkFlapping dampens failovers while this node changed role failover.flap_detection.max_transitions times within
+// its window, sending a flapping_detected notification when it starts - dampening lifts once the transitions age out
+func (m *Manager) checkFlapping(now time.Time) {
+	detection := m.cfg.Failover.FlapDetection
+	if !detection.Enabled {
+		m.flapping = false
+		return
+	}
+
+	transitions := m.roleTransitionsSince(now.Add(-detection.WindowDuration))
+	flapping := transitions >= detection.MaxTransitions
+	if flapping == m.flapping {
+		return
+	}
+	m.flapping = flapping
+	if !flapping {
+		m.logger.Info("no longer flapping - failover dampening lifted")
+		return
+	}
+
+	leaderlessSamplesThreshold := m.leaderlessSamplesThreshold()
+	cooldown := m.takeoverCooldown()
+	m.logger.Warn("flapping detected - dampening failovers",
+		"transitions", transitions,
+		"window", detection.WindowDuration,
+		"leaderless_samples_threshold", leaderlessSamplesThreshold,
+		"cooldown", cooldown,
+	)
+	if m.notifyManager != nil {
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventFlappingDetected,
+			Severity:      notify.SeverityWarning,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			Message: fmt.Sprintf("%d role changes within %s - failovers dampened to %d leaderless samples and a %s cooldown until they age out",
+				transitions, detection.WindowDuration, leaderlessSamplesThreshold, cooldown),
+			Details: map[string]string{
+				"transitions":                  strconv.Itoa(transitions),
+				"window":                       detection.WindowDuration.String(),
+				"leaderless_samples_threshold": strconv.Itoa(leaderlessSamplesThreshold),
+				"cooldown":                     cooldown.String(),
+			},
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package ha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_RoleTransitionsSince(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	manager.recordRole("passive", startedAt)
+	manager.recordRole("active", startedAt.Add(time.Minute))
+	manager.recordRole("unknown", startedAt.Add(2*time.Minute))
+	manager.recordRole("active", startedAt.Add(3*time.Minute))
+	manager.recordRole("passive", startedAt.Add(4*time.Minute))
+
+	assert.Equal(t, 2, manager.roleTransitionsSince(startedAt))
+	assert.Equal(t, 1, manager.roleTransitionsSince(startedAt.Add(time.Minute)))
+	assert.Equal(t, 0, manager.roleTransitionsSince(startedAt.Add(4*time.Minute)))
+}
+
+func TestManager_CheckFlapping(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.CooldownDuration = time.Minute
+	cfg.Failover.FlapDetection.Enabled = true
+	cfg.Failover.FlapDetection.SetDefaults()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	events, unsubscribe := manager.Subscribe(16)
+	defer unsubscribe()
+
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	roles := []string{"passive", "active", "passive", "active"}
+	for i, role := range roles {
+		manager.recordRole(role, startedAt.Add(time.Duration(i)*time.Minute))
+	}
+
+	// three transitions are not flapping
+	manager.checkFlapping(startedAt.Add(5 * time.Minute))
+	assert.False(t, manager.flapping)
+	assert.Equal(t, 3, manager.leaderlessSamplesThreshold())
+	assert.Equal(t, time.Minute, manager.takeoverCooldown())
+
+	// the fourth is, dampening failovers
+	manager.recordRole("passive", startedAt.Add(5*time.Minute))
+	manager.checkFlapping(startedAt.Add(6 * time.Minute))
+	assert.True(t, manager.flapping)
+	assert.Equal(t, 6, manager.leaderlessSamplesThreshold())
+	assert.Equal(t, 5*time.Minute, manager.takeoverCooldown())
+
+	var flapping []notify.Event
+	for len(flapping) == 0 {
+		select {
+		case event := <-events:
+			if event.Type == notify.EventFlappingDetected {
+				flapping = append(flapping, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no flapping_detected event")
+		}
+	}
+	assert.Equal(t, "4", flapping[0].Details["transitions"])
+
+	// dampening lifts once the transitions age out of the window
+	manager.checkFlapping(startedAt.Add(time.Hour + 2*time.Minute))
+	assert.False(t, manager.flapping)
+	assert.Equal(t, 3, manager.leaderlessSamplesThreshold())
+}
@@ -0,0 +1,202 @@
+package ha
+
+import (
+	"github.com/sol-strategies/solana-validator-ha/internal/gossip"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// hysteresis debounces a check, declaring it failing or recovered only after enough consecutive polls agree
+type hysteresis struct {
+	unhealthyThreshold int
+	healthyThreshold   int
+	// healthy is the declared state, known once set by the first poll or an initial state
+	healthy bool
+	known   bool
+	// streak counts consecutive polls contrary to the declared state
+	streak int
+}
+
+// newHysteresis creates a hysteresis for a check whose state is declared by its first poll
+func newHysteresis(unhealthyThreshold, healthyThreshold int) *hysteresis {
+	return &hysteresis{unhealthyThreshold: unhealthyThreshold, healthyThreshold: healthyThreshold}
+}
+
+// newHealthyHysteresis creates a hysteresis for a check assumed healthy until enough failing polls
+func newHealthyHysteresis(unhealthyThreshold, healthyThreshold int) *hysteresis {
+	return &hysteresis{unhealthyThreshold: unhealthyThreshold, healthyThreshold: healthyThreshold, healthy: true, known: true}
+}
+
+// observe records a poll result, returning the declared state and whether this poll changed it
+func (h *hysteresis) observe(healthy bool) (declared bool, changed bool) {
+	if !h.known {
+		h.healthy, h.known = healthy, true
+		return h.healthy, false
+	}
+
+	if healthy == h.healthy {
+		h.streak = 0
+		return h.healthy, false
+	}
+
+	h.streak++
+	threshold := h.unhealthyThreshold
+	if healthy {
+		threshold = h.healthyThreshold
+	}
+	if h.streak < threshold {
+		return h.healthy, false
+	}
+
+	h.healthy, h.streak = healthy, 0
+	return h.healthy, true
+}
+
+// passing returns whether the check is declared passing - a check not yet polled is not
+func (h *hysteresis) passing() bool {
+	return h.known && h.healthy
+}
+
+// isSelfUnhealthy returns whether validator.health has declared the local rpc health check unhealthy, so takeovers
+// wait for the same debounced state that is reported and alerted on
+func (m *Manager) isSelfUnhealthy() bool {
+	return !m.healthCheck.passing()
+}
+
+// isSelfLostFromGossip returns whether validator.health has declared us lost from gossip
+func (m *Manager) isSelfLostFromGossip() bool {
+	return !m.gossipCheck.passing()
+}
+
+// isPeerInGossip returns whether validator.health has declared the named peer in gossip, so a peer is only handed the
+// active role once it has been seen for health.checks.peers.healthy_threshold polls
+func (m *Manager) isPeerInGossip(name string) bool {
+	peerCheck, ok := m.peerChecks[name]
+	return ok && peerCheck.passing()
+}
+
+// observeHealth records this poll's health check, notifying when validator.health declares it unhealthy or recovered,
+// and returns the declared health
+func (m *Manager) observeHealth(healthy bool, healthStatus string) bool {
+	declared, changed := m.healthCheck.observe(healthy)
+	if !changed || m.notifyManager == nil {
+		return declared
+	}
+
+	if !declared {
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventHealthUnhealthy,
+			Severity:      notify.SeverityError,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			Details: map[string]string{
+				"health_status": healthStatus,
+			},
+		})
+		return declared
+	}
+
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventHealthRecovered,
+		Severity:      notify.SeverityInfo,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+	})
+	return declared
+}
+
+// observeGossip records whether we were in gossip this poll, notifying when validator.health declares us lost from
+// or recovered in gossip, and returns the declared gossip presence
+func (m *Manager) observeGossip(inGossip bool) bool {
+	declared, changed := m.gossipCheck.observe(inGossip)
+	if !changed || m.notifyManager == nil {
+		return declared
+	}
+
+	if !declared {
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventGossipLost,
+			Severity:      notify.SeverityError,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			Message:       "Validator is no longer visible in gossip network",
+		})
+		return declared
+	}
+
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventGossipRecovered,
+		Severity:      notify.SeverityInfo,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message:       "Validator is now visible in gossip network",
+	})
+	return declared
+}
+
+// observePeers records whether each peer is in this poll's peerStates, notifying when validator.health declares a peer lost
+// from or discovered in gossip - the first poll finding a peer announces it too
+func (m *Manager) observePeers(peerStates map[string]gossip.PeerState) {
+	thresholds := m.cfg.Validator.Health.Checks.Peers
+	peers := m.peers()
+
+	// forget peers removed from failover.peers or the peer registry
+	for name := range m.peerChecks {
+		if _, ok := peers[name]; !ok {
+			delete(m.peerChecks, name)
+		}
+	}
+
+	for name, peer := range peers {
+		// our own gossip presence is the gossip check
+		if peer.IP == m.peerSelf.IP {
+			continue
+		}
+
+		peerCheck, known := m.peerChecks[name]
+		if !known {
+			peerCheck = newHysteresis(thresholds.UnhealthyThreshold, thresholds.HealthyThreshold)
+			m.peerChecks[name] = peerCheck
+		}
+
+		peerState, inGossip := peerStates[name]
+		declared, changed := peerCheck.observe(inGossip)
+		if !known && declared {
+			changed = true
+		}
+		if !changed || m.notifyManager == nil {
+			continue
+		}
+
+		if !declared {
+			m.notifyManager.NotifyAsync(notify.Event{
+				Type:          notify.EventPeerLost,
+				Severity:      notify.SeverityError,
+				ValidatorName: m.cfg.Validator.Name,
+				PublicIP:      m.peerSelf.IP,
+				Cluster:       m.cfg.Cluster.Name,
+				Details: map[string]string{
+					"peer_name": name,
+					"peer_ip":   peer.IP,
+				},
+			})
+			continue
+		}
+
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventPeerDiscovered,
+			Severity:      notify.SeverityInfo,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			Details: map[string]string{
+				"peer_name":   name,
+				"peer_ip":     peer.IP,
+				"peer_pubkey": peerState.Pubkey,
+			},
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package ha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/gossip"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHysteresis_Observe(t *testing.T) {
+	h := newHealthyHysteresis(3, 2)
+
+	// a single failing poll is a blip
+	declared, changed := h.observe(false)
+	assert.True(t, declared)
+	assert.False(t, changed)
+	declared, changed = h.observe(true)
+	assert.True(t, declared)
+	assert.False(t, changed)
+
+	// unhealthy only after 3 consecutive failing polls
+	h.observe(false)
+	h.observe(false)
+	declared, changed = h.observe(false)
+	assert.False(t, declared)
+	assert.True(t, changed)
+
+	declared, changed = h.observe(false)
+	assert.False(t, declared)
+	assert.False(t, changed)
+
+	// recovered only after 2 consecutive healthy polls
+	declared, changed = h.observe(true)
+	assert.False(t, declared)
+	assert.False(t, changed)
+	declared, changed = h.observe(true)
+	assert.True(t, declared)
+	assert.True(t, changed)
+}
+
+func TestHysteresis_FirstPollDeclaresState(t *testing.T) {
+	h := newHysteresis(1, 1)
+
+	declared, changed := h.observe(false)
+	assert.False(t, declared)
+	assert.False(t, changed, "first poll should declare the state without a change")
+
+	declared, changed = h.observe(true)
+	assert.True(t, declared)
+	assert.True(t, changed)
+}
+
+func TestHysteresis_ZeroThresholdsTriggerImmediately(t *testing.T) {
+	h := newHealthyHysteresis(0, 0)
+
+	declared, changed := h.observe(false)
+	assert.False(t, declared)
+	assert.True(t, changed)
+}
+
+func TestManager_ObservePeers(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Validator.Health.Checks.Peers = config.HealthThresholds{UnhealthyThreshold: 2, HealthyThreshold: 1}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	events, unsubscribe := manager.Subscribe(10)
+	defer unsubscribe()
+
+	// peer events only, skipping the startup event
+	nextEvent := func(timeout time.Duration) (notify.Event, bool) {
+		for {
+			select {
+			case event := <-events:
+				if event.Type == notify.EventPeerDiscovered || event.Type == notify.EventPeerLost {
+					return event, true
+				}
+			case <-time.After(timeout):
+				return notify.Event{}, false
+			}
+		}
+	}
+	requireEvent := func() notify.Event {
+		event, ok := nextEvent(time.Second)
+		require.True(t, ok, "no peer event emitted")
+		return event
+	}
+	assertNoEvent := func() {
+		event, ok := nextEvent(50 * time.Millisecond)
+		assert.False(t, ok, "unexpected %s event", event.Type)
+	}
+
+	peer1 := map[string]gossip.PeerState{"peer1": {Name: "peer1", IP: "192.168.1.101", Pubkey: "pubkey1"}}
+
+	// the first poll finding a peer announces it
+	manager.observePeers(peer1)
+	event := requireEvent()
+	assert.Equal(t, notify.EventPeerDiscovered, event.Type)
+	assert.Equal(t, "peer1", event.Details["peer_name"])
+	assert.Equal(t, "pubkey1", event.Details["peer_pubkey"])
+	assertNoEvent()
+
+	// a single poll without it is a blip
+	manager.observePeers(map[string]gossip.PeerState{})
+	manager.observePeers(peer1)
+	assertNoEvent()
+
+	// lost after 2 consecutive polls without it
+	manager.observePeers(map[string]gossip.PeerState{})
+	manager.observePeers(map[string]gossip.PeerState{})
+	event = requireEvent()
+	assert.Equal(t, notify.EventPeerLost, event.Type)
+	assert.Equal(t, "peer1", event.Details["peer_name"])
+
+	// and discovered again after 1 poll with it
+	manager.observePeers(peer1)
+	assert.Equal(t, notify.EventPeerDiscovered, requireEvent().Type)
+
+	// removed peers are forgotten
+	delete(manager.cfg.Failover.Peers, "peer2")
+	manager.observePeers(peer1)
+	assert.NotContains(t, manager.peerChecks, "peer2")
+}
+
+func TestManager_TakeoverGatesUseDeclaredHealth(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Validator.Health.Checks.RPC = config.HealthThresholds{UnhealthyThreshold: 2, HealthyThreshold: 2}
+	cfg.Validator.Health.Checks.Gossip = config.HealthThresholds{UnhealthyThreshold: 2, HealthyThreshold: 1}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+
+	// not yet polled - healthy until declared otherwise, but not known to be in gossip
+	assert.False(t, manager.isSelfUnhealthy())
+	assert.True(t, manager.isSelfLostFromGossip())
+
+	manager.observeGossip(true)
+	assert.False(t, manager.isSelfLostFromGossip())
+
+	// a single failing poll is a blip that doesn't stop a takeover
+	manager.observeHealth(false, "behind")
+	manager.observeGossip(false)
+	assert.False(t, manager.isSelfUnhealthy())
+	assert.False(t, manager.isSelfLostFromGossip())
+
+	manager.observeHealth(false, "behind")
+	manager.observeGossip(false)
+	assert.True(t, manager.isSelfUnhealthy())
+	assert.True(t, manager.isSelfLostFromGossip())
+
+	// and a single passing poll doesn't make us fit to take over again
+	manager.observeHealth(true, "ok")
+	assert.True(t, manager.isSelfUnhealthy())
+	manager.observeHealth(true, "ok")
+	assert.False(t, manager.isSelfUnhealthy())
+}
+
+func TestManager_IsPeerInGossip(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Validator.Health.Checks.Peers = config.HealthThresholds{UnhealthyThreshold: 2, HealthyThreshold: 2}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	peer1 := map[string]gossip.PeerState{"peer1": {Name: "peer1", IP: "192.168.1.101", Pubkey: "pubkey1"}}
+
+	// not a handover target until it has been polled
+	assert.False(t, manager.isPeerInGossip("peer1"))
+	manager.observePeers(peer1)
+	assert.True(t, manager.isPeerInGossip("peer1"))
+
+	// a single poll without it doesn't stop a handover
+	manager.observePeers(map[string]gossip.PeerState{})
+	assert.True(t, manager.isPeerInGossip("peer1"))
+	manager.observePeers(map[string]gossip.PeerState{})
+	assert.False(t, manager.isPeerInGossip("peer1"))
+
+	// and it is only handed the active role again once declared discovered
+	manager.observePeers(peer1)
+	assert.False(t, manager.isPeerInGossip("peer1"))
+	manager.observePeers(peer1)
+	assert.True(t, manager.isPeerInGossip("peer1"))
+	assert.False(t, manager.isPeerInGossip("peer2"))
+}
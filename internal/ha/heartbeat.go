@@ -0,0 +1,60 @@
+package ha
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// heartbeatLoop sends the heartbeat notification on schedule until the manager stops
+func (m *Manager) heartbeatLoop() {
+	heartbeat := m.cfg.Notifications.Heartbeat
+	m.logger.Info("sending heartbeat notifications", "interval", heartbeat.IntervalDuration, "at", heartbeat.At, "timezone", heartbeat.Timezone)
+
+	for {
+		next := heartbeat.Next(time.Now())
+		m.logger.Debug("next heartbeat scheduled", "at", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-m.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			m.sendHeartbeat()
+		}
+	}
+}
+
+// sendHeartbeat sends a summary of the current role, health and peers so operators can tell a quiet
+// cluster from a broken alerting path
+func (m *Manager) sendHeartbeat() {
+	state := m.cache.GetState()
+	uptime := time.Since(m.startedAt).Round(time.Second).String()
+
+	activePeer := state.ActivePeerName
+	if activePeer == "" {
+		activePeer = "none"
+	}
+
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventHeartbeat,
+		Severity:      notify.SeverityInfo,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      state.PublicIP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message: fmt.Sprintf("%s and %s, %d peers, active peer %s, up %s",
+			state.Role, state.Status, state.PeerCount, activePeer, uptime),
+		Details: map[string]string{
+			"role":            state.Role,
+			"status":          state.Status,
+			"failover_status": state.FailoverStatus,
+			"self_in_gossip":  strconv.FormatBool(state.SelfInGossip),
+			"peer_count":      strconv.Itoa(state.PeerCount),
+			"active_peer":     activePeer,
+			"uptime":          uptime,
+		},
+	})
+}
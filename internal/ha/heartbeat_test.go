@@ -0,0 +1,35 @@
+package ha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SendHeartbeat(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.startedAt = time.Now().Add(-time.Hour)
+
+	events, unsubscribe := manager.Subscribe(4)
+	defer unsubscribe()
+
+	manager.sendHeartbeat()
+
+	// the startup event may still be on its way
+	var event notify.Event
+	for event.Type != notify.EventHeartbeat {
+		select {
+		case event = <-events:
+		case <-time.After(time.Second):
+			t.Fatal("heartbeat not sent")
+		}
+	}
+	assert.Equal(t, notify.SeverityInfo, event.Severity)
+	assert.Equal(t, "none", event.Details["active_peer"])
+	assert.Equal(t, "1h0m0s", event.Details["uptime"])
+	assert.Contains(t, event.Message, "up 1h0m0s")
+}
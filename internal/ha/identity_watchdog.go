@@ -0,0 +1,100 @@
+package ha
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-ha/internal/client"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// checkActiveIdentityUsage makes sure this node is not using the active identity while another peer is active -
+// neither at runtime, as reported by local rpc getIdentity, nor in the validator process args, which would bring
+// it up active on its next restart. Each new finding is sent as a critical notification.
+func (m *Manager) checkActiveIdentityUsage() {
+	activePeer, err := m.gossipState.GetActivePeer()
+	if err != nil || activePeer.IPEquals(m.peerSelf.IP) {
+		m.lastActiveIdentityFindings = ""
+		return
+	}
+
+	activePubkey := m.cfg.Validator.Identities.ActiveKeyPair.PublicKey()
+	findings := []string{}
+
+	runningActive := false
+	identity, err := m.localRPC.GetIdentity(m.ctx)
+	if err != nil {
+		m.logger.Debug("identity watchdog failed to get local identity", "error", err)
+	} else if identity.Identity.Equals(activePubkey) {
+		runningActive = true
+		findings = append(findings, "local rpc getIdentity reports the active identity")
+	}
+
+	for _, path := range activeIdentityArgs(client.IdentityPaths(m.procRoot), m.cfg.Validator.Identities.ActiveKeyPairPath(), activePubkey) {
+		findings = append(findings, fmt.Sprintf("validator process was started with --identity %s", path))
+	}
+
+	if len(findings) == 0 {
+		m.lastActiveIdentityFindings = ""
+		return
+	}
+
+	summary := strings.Join(findings, "; ")
+	m.logger.Error("active identity in use on passive node - risk of duplicate voting",
+		"active_peer", activePeer.Name,
+		"active_peer_ip", activePeer.IP,
+		"findings", summary,
+	)
+
+	if summary != m.lastActiveIdentityFindings && m.notifyManager != nil {
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventActiveIdentityOnPassive,
+			Severity:      notify.SeverityCritical,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			ActivePubkey:  activePubkey.String(),
+			PassivePubkey: m.cfg.Validator.Identities.PassiveKeyPair.PublicKey().String(),
+			Message:       fmt.Sprintf("Active identity in use while %s is active: %s", activePeer.Name, summary),
+			Details: map[string]string{
+				"active_peer_name": activePeer.Name,
+				"active_peer_ip":   activePeer.IP,
+				"findings":         summary,
+				"auto_correct":     fmt.Sprintf("%t", m.cfg.Validator.IdentityWatchdog.AutoCorrect && runningActive),
+			},
+		})
+	}
+	m.lastActiveIdentityFindings = summary
+
+	// only a running active identity can be corrected - process args need fixing where the validator is started
+	if runningActive && m.cfg.Validator.IdentityWatchdog.AutoCorrect {
+		m.logger.Warn("identity watchdog auto-correcting - ensuring we are passive")
+		m.ensurePassive()
+	}
+}
+
+// activeIdentityArgs returns the identity paths that are the active identity - either the configured active
+// keypair file or a keypair file for the active pubkey
+func activeIdentityArgs(paths []string, activeKeyPairFile string, activePubkey solanago.PublicKey) []string {
+	activeFile := filepath.Clean(activeKeyPairFile)
+	if absFile, err := filepath.Abs(activeKeyPairFile); err == nil {
+		activeFile = absFile
+	}
+
+	active := []string{}
+	for _, path := range paths {
+		if path == activeFile {
+			active = append(active, path)
+			continue
+		}
+
+		keyPair, err := solanago.PrivateKeyFromSolanaKeygenFile(path)
+		if err == nil && keyPair.PublicKey().Equals(activePubkey) {
+			active = append(active, path)
+		}
+	}
+
+	return active
+}
@@ -0,0 +1,43 @@
+package ha
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestKeyPairFile writes a keypair file in the solana-keygen format
+func writeTestKeyPairFile(t *testing.T, path string, key solanago.PrivateKey) {
+	t.Helper()
+	keyBytes := make([]int, len(key))
+	for i, b := range key {
+		keyBytes[i] = int(b)
+	}
+	data, err := json.Marshal(keyBytes)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestActiveIdentityArgs(t *testing.T) {
+	dir := t.TempDir()
+	active := solanago.NewWallet().PrivateKey
+	passive := solanago.NewWallet().PrivateKey
+
+	activeFile := filepath.Join(dir, "active.json")
+	activeCopy := filepath.Join(dir, "active-copy.json")
+	passiveFile := filepath.Join(dir, "passive.json")
+	writeTestKeyPairFile(t, activeFile, active)
+	writeTestKeyPairFile(t, activeCopy, active)
+	writeTestKeyPairFile(t, passiveFile, passive)
+
+	paths := []string{passiveFile, activeFile, activeCopy, filepath.Join(dir, "missing.json")}
+	assert.Equal(t, []string{activeFile, activeCopy}, activeIdentityArgs(paths, activeFile, active.PublicKey()))
+
+	// a passive validator started with its own identity is fine
+	assert.Empty(t, activeIdentityArgs([]string{passiveFile}, activeFile, active.PublicKey()))
+}
@@ -0,0 +1,342 @@
+package ha
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// incidentEventsBuffer is how many events emitted during a takeover attempt are kept for its report
+const incidentEventsBuffer = 64
+
+// incident traces a takeover attempt for its report - a nil incident, when reports are disabled, records nothing
+type incident struct {
+	startedAt   time.Time
+	steps       []incidentStep
+	events      <-chan notify.Event
+	unsubscribe func()
+	// recorded are the events the takeover sent itself, recorded as they are sent since they are delivered to
+	// subscribers asynchronously
+	recorded []notify.Event
+}
+
+// incidentStep is a decision or action taken during a takeover attempt
+type incidentStep struct {
+	At       time.Time
+	Stage    string
+	Detail   string
+	Duration time.Duration
+	Err      string
+}
+
+// incidentReport is the data incident report templates are rendered with
+type incidentReport struct {
+	FailoverID string
+	Validator  string
+	Cluster    string
+	PublicIP   string
+	DryRun     bool
+	Outcome    string
+	Error      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Steps      []incidentStep
+	Events     []notify.Event
+}
+
+// beginIncident starts tracing a takeover attempt if failover.incident_report is enabled
+func (m *Manager) beginIncident() {
+	m.incident = nil
+	if m.cfg.Failover.IncidentReport.Enabled {
+		m.incident = &incident{startedAt: time.Now()}
+	}
+}
+
+// abandonIncident drops the takeover attempt being traced once its decision is abandoned, so a later takeover
+// doesn't report its steps
+func (m *Manager) abandonIncident() {
+	m.incident = nil
+}
+
+// step records a decision
+func (i *incident) step(stage string, format string, args ...any) {
+	if i == nil {
+		return
+	}
+	i.steps = append(i.steps, incidentStep{At: time.Now(), Stage: stage, Detail: fmt.Sprintf(format, args...)})
+}
+
+// timed records an action that started at startedAt and has just finished
+func (i *incident) timed(stage string, detail string, startedAt time.Time, err error) {
+	if i == nil {
+		return
+	}
+	step := incidentStep{At: startedAt, Stage: stage, Detail: detail, Duration: time.Since(startedAt)}
+	if err != nil {
+		step.Err = err.Error()
+	}
+	i.steps = append(i.steps, step)
+}
+
+// hookResults returns a config.HooksRunOptions.OnResult recording hook results under stage, e.g. pre-active
+func (i *incident) hookResults(stage string) func(config.HookResult) {
+	if i == nil {
+		return nil
+	}
+	return func(result config.HookResult) {
+		detail := result.Name
+		if result.Host != "" {
+			detail += " on " + result.Host
+		}
+		if result.MustSucceed {
+			detail += " (must succeed)"
+		}
+		if result.Skipped {
+			detail += " (skipped - when is false)"
+		}
+		step := incidentStep{At: result.StartedAt, Stage: stage + " hook", Detail: detail, Duration: result.Duration}
+		if result.Err != nil {
+			step.Err = result.Err.Error()
+		}
+		i.steps = append(i.steps, step)
+	}
+}
+
+// subscribe captures the events emitted from now on for the report
+func (i *incident) subscribe(subscribers *notify.Subscribers) {
+	if i == nil || subscribers == nil {
+		return
+	}
+	i.events, i.unsubscribe = subscribers.Subscribe(incidentEventsBuffer)
+}
+
+// recordIncidentEvent stamps an event the takeover is about to send and records it on the incident, returning the
+// event to send
+func (m *Manager) recordIncidentEvent(i *incident, event notify.Event) notify.Event {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if m.notifyManager == nil || i == nil {
+		return event
+	}
+	event.Severity = m.notifyManager.Severity(event)
+	i.recorded = append(i.recorded, event)
+	return event
+}
+
+// collectEvents stops capturing events, returning those recorded and captured in the order they were sent
+func (i *incident) collectEvents() []notify.Event {
+	events := slices.Clone(i.recorded)
+	if i.events != nil {
+		defer i.unsubscribe()
+	drain:
+		for {
+			select {
+			case event := <-i.events:
+				// skip the events we recorded that have been delivered already
+				if !slices.ContainsFunc(i.recorded, func(recorded notify.Event) bool {
+					return recorded.Type == event.Type && recorded.Timestamp.Equal(event.Timestamp)
+				}) {
+					events = append(events, event)
+				}
+			default:
+				break drain
+			}
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b notify.Event) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return events
+}
+
+// finishIncident writes the report for a takeover attempt that failed with err, or succeeded when err is nil,
+// returning where it can be found - its URL under failover.incident_report.url, else its path
+func (m *Manager) finishIncident(i *incident, err error) string {
+	if i == nil {
+		return ""
+	}
+
+	report := incidentReport{
+		FailoverID: m.failoverID,
+		Validator:  m.cfg.Validator.Name,
+		Cluster:    m.cfg.Cluster.Name,
+		PublicIP:   m.peerSelf.IP,
+		DryRun:     m.cfg.Failover.DryRun,
+		Outcome:    "succeeded",
+		StartedAt:  i.startedAt,
+		FinishedAt: time.Now(),
+		Steps:      i.steps,
+		Events:     i.collectEvents(),
+	}
+	if err != nil {
+		report.Outcome = "failed"
+		report.Error = err.Error()
+	}
+
+	cfg := m.cfg.Failover.IncidentReport
+	path, writeErr := writeIncidentReport(cfg.Dir, cfg.Format, report)
+	if writeErr != nil {
+		m.logger.Error("failed to write incident report", "dir", cfg.Dir, "error", writeErr)
+		return ""
+	}
+
+	location := path
+	if cfg.URL != "" {
+		location = strings.TrimSuffix(cfg.URL, "/") + "/" + url.PathEscape(filepath.Base(path))
+	}
+	m.logger.Info("incident report written", "outcome", report.Outcome, "report", location)
+	return location
+}
+
+// writeIncidentReport renders a report in format to a new file in dir, returning its path
+func writeIncidentReport(dir string, format string, report incidentReport) (string, error) {
+	var buf bytes.Buffer
+	var extension string
+	var err error
+
+	switch format {
+	case "html":
+		extension = ".html"
+		err = incidentReportHTMLTemplate.Execute(&buf, report)
+	default:
+		extension = ".md"
+		err = incidentReportMarkdownTemplate.Execute(&buf, report)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to render incident report: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create incident report dir: %w", err)
+	}
+
+	name := fmt.Sprintf("incident-%s-%s%s", report.Validator, report.StartedAt.UTC().Format("20060102T150405Z"), extension)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write incident report: %w", err)
+	}
+
+	return path, nil
+}
+
+// incidentReportFuncs are the functions available to incident report templates
+var incidentReportFuncs = map[string]any{
+	"time": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"offset": func(start time.Time, t time.Time) string {
+		return "+" + t.Sub(start).Round(time.Millisecond).String()
+	},
+	"duration": func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return d.Round(time.Millisecond).String()
+	},
+	"cell": func(s string) string {
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+	},
+}
+
+var incidentReportMarkdownTemplate = template.Must(template.New("incident_report.md").Funcs(incidentReportFuncs).Parse(
+	`# Takeover {{ .Outcome }}: {{ .Validator }}
+
+| | |
+|---|---|
+| Validator | {{ .Validator }} |
+| Cluster | {{ .Cluster }} |
+| Public IP | {{ .PublicIP }} |
+{{- if .FailoverID }}
+| Failover ID | {{ .FailoverID }} |
+{{- end }}
+| Outcome | {{ .Outcome }}{{ if .DryRun }} (dry run){{ end }} |
+{{- if .Error }}
+| Error | {{ cell .Error }} |
+{{- end }}
+| Started | {{ time .StartedAt }} |
+| Finished | {{ time .FinishedAt }} |
+| Duration | {{ duration (.FinishedAt.Sub .StartedAt) }} |
+
+## Timeline
+
+| Time | Offset | Stage | Detail | Duration | Error |
+|---|---|---|---|---|---|
+{{- range .Steps }}
+| {{ time .At }} | {{ offset $.StartedAt .At }} | {{ .Stage }} | {{ cell .Detail }} | {{ duration .Duration }} | {{ cell .Err }} |
+{{- end }}
+
+## Events
+{{ if .Events }}
+| Time | Event | Severity | Message |
+|---|---|---|---|
+{{- range .Events }}
+| {{ time .Timestamp }} | {{ .Type }} | {{ .Severity }} | {{ cell .Message }} |
+{{- end }}
+{{- else }}
+No events were emitted.
+{{- end }}
+`))
+
+var incidentReportHTMLTemplate = htmltemplate.Must(htmltemplate.New("incident_report.html").Funcs(incidentReportFuncs).Parse(
+	`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Takeover {{ .Outcome }}: {{ .Validator }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.error { color: #c00; }
+</style>
+</head>
+<body>
+<h1>Takeover {{ .Outcome }}: {{ .Validator }}</h1>
+<table>
+<tr><th>Validator</th><td>{{ .Validator }}</td></tr>
+<tr><th>Cluster</th><td>{{ .Cluster }}</td></tr>
+<tr><th>Public IP</th><td>{{ .PublicIP }}</td></tr>
+{{- if .FailoverID }}
+<tr><th>Failover ID</th><td>{{ .FailoverID }}</td></tr>
+{{- end }}
+<tr><th>Outcome</th><td>{{ .Outcome }}{{ if .DryRun }} (dry run){{ end }}</td></tr>
+{{- if .Error }}
+<tr><th>Error</th><td class="error">{{ .Error }}</td></tr>
+{{- end }}
+<tr><th>Started</th><td>{{ time .StartedAt }}</td></tr>
+<tr><th>Finished</th><td>{{ time .FinishedAt }}</td></tr>
+<tr><th>Duration</th><td>{{ duration (.FinishedAt.Sub .StartedAt) }}</td></tr>
+</table>
+<h2>Timeline</h2>
+<table>
+<tr><th>Time</th><th>Offset</th><th>Stage</th><th>Detail</th><th>Duration</th><th>Error</th></tr>
+{{- range .Steps }}
+<tr><td>{{ time .At }}</td><td>{{ offset $.StartedAt .At }}</td><td>{{ .Stage }}</td><td>{{ .Detail }}</td><td>{{ duration .Duration }}</td><td class="error">{{ .Err }}</td></tr>
+{{- end }}
+</table>
+<h2>Events</h2>
+{{- if .Events }}
+<table>
+<tr><th>Time</th><th>Event</th><th>Severity</th><th>Message</th></tr>
+{{- range .Events }}
+<tr><td>{{ time .Timestamp }}</td><td>{{ .Type }}</td><td>{{ .Severity }}</td><td>{{ .Message }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>No events were emitted.</p>
+{{- end }}
+</body>
+</html>
+`))
@@ -0,0 +1,156 @@
+package ha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_IncidentReport(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.IncidentReport = config.IncidentReport{Enabled: true, Dir: t.TempDir(), Format: "markdown"}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	manager.peerSelf = &config.Peer{Name: "test-validator", IP: "192.168.1.100"}
+
+	manager.beginIncident()
+	incident := manager.incident
+	require.NotNil(t, incident)
+	incident.step("decision", "no active peer found in the last %d samples - failover required", 3)
+	incident.subscribe(manager.subscribers)
+
+	manager.notifyManager = notify.NewManager(notify.ManagerOptions{
+		Config:      &config.NotificationConfig{},
+		Subscribers: manager.subscribers,
+	})
+	manager.notifyManager.Notify(notify.Event{Type: notify.EventBecomingActive, Message: "Failover triggered | validator becoming active"})
+
+	onResult := incident.hookResults("pre-active")
+	onResult(config.HookResult{Type: "pre", Name: "stop-peer", Host: "backup-1", MustSucceed: true, StartedAt: time.Now(), Duration: 1500 * time.Millisecond})
+	incident.timed("active command", "set-identity.sh", time.Now(), errors.New("exit status 1"))
+
+	path := manager.finishIncident(incident, errors.New("failed to run active command: exit status 1"))
+	require.NotEmpty(t, path)
+	assert.True(t, strings.HasPrefix(filepath.Base(path), "incident-test-validator-"))
+	assert.Equal(t, ".md", filepath.Ext(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	report := string(data)
+	assert.Contains(t, report, "# Takeover failed: test-validator")
+	assert.Contains(t, report, "| Error | failed to run active command: exit status 1 |")
+	assert.Contains(t, report, "| decision | no active peer found in the last 3 samples - failover required |")
+	assert.Contains(t, report, "| pre-active hook | stop-peer on backup-1 (must succeed) | 1.5s |")
+	assert.Contains(t, report, "| active command | set-identity.sh |")
+	assert.Contains(t, report, "| becoming_active | critical | Failover triggered \\| validator becoming active |")
+}
+
+func TestManager_IncidentReportLinksURL(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.IncidentReport = config.IncidentReport{Enabled: true, Dir: t.TempDir(), Format: "html", URL: "https://reports.example.com/incidents/"}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	manager.peerSelf = &config.Peer{Name: "test-validator", IP: "192.168.1.100"}
+
+	manager.beginIncident()
+	incident := manager.incident
+	incident.step("active", "confirmed active by <local rpc>")
+
+	link := manager.finishIncident(incident, nil)
+	assert.True(t, strings.HasPrefix(link, "https://reports.example.com/incidents/incident-test-validator-"))
+	assert.True(t, strings.HasSuffix(link, ".html"))
+
+	matches, err := filepath.Glob(filepath.Join(cfg.Failover.IncidentReport.Dir, "*.html"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	data, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<h1>Takeover succeeded: test-validator</h1>")
+	assert.Contains(t, string(data), "confirmed active by &lt;local rpc&gt;")
+	assert.Contains(t, string(data), "<p>No events were emitted.</p>")
+}
+
+func TestManager_IncidentReportDisabled(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+
+	manager.beginIncident()
+	assert.Nil(t, manager.incident)
+
+	// a nil incident records nothing
+	manager.incident.step("decision", "ignored")
+	assert.Nil(t, manager.incident.hookResults("pre-active"))
+	assert.Empty(t, manager.finishIncident(manager.incident, nil))
+}
+
+func TestManager_EnsureActive_IncidentReportEvents(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.IncidentReport = config.IncidentReport{Enabled: true, Dir: t.TempDir(), Format: "markdown"}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	// local rpc reports the active identity once the active command has run
+	activePubkey := cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID any `json:"id"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": map[string]any{"identity": activePubkey}})
+	}))
+	defer server.Close()
+	manager.localRPC = rpc.NewClient("test", server.URL)
+
+	manager.beginIncident()
+	manager.ensureActive()
+
+	matches, err := filepath.Glob(filepath.Join(cfg.Failover.IncidentReport.Dir, "*.md"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	data, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	report := string(data)
+
+	// the takeover's own events are in its report although they are delivered asynchronously
+	assert.Contains(t, report, "# Takeover succeeded: test-validator")
+	assert.Contains(t, report, "| becoming_active | critical | Failover triggered - validator becoming active |")
+	assert.Contains(t, report, "| became_active | info |")
+	assert.Equal(t, 1, strings.Count(report, "| becoming_active |"))
+}
+
+// heldArbitrator is a failover.arbitration lock held by another peer
+type heldArbitrator struct{}
+
+func (heldArbitrator) Acquire(ctx context.Context, holder string) (string, error) {
+	return "peer1", nil
+}
+
+func (heldArbitrator) Release(ctx context.Context, holder string) error {
+	return nil
+}
+
+func TestManager_AbandonedTakeoverDropsIncident(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.DryRun = false
+	cfg.Failover.IncidentReport = config.IncidentReport{Enabled: true, Dir: t.TempDir(), Format: "markdown"}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.localRPC = newPassiveIdentityRPC(t, cfg)
+	manager.arbitrationLock = heldArbitrator{}
+	manager.observeGossip(true)
+
+	result := manager.manualPromote()
+	assert.Equal(t, "failed to acquire the failover.arbitration lock", result.Error)
+	assert.Nil(t, manager.incident, "a later takeover must not report the abandoned one's steps")
+}
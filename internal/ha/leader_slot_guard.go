@@ -0,0 +1,46 @@
+package ha
+
+import (
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// leaderSlotGuardPollInterval is how often the leader schedule is checked while waiting for a gap in our leader slots
+var leaderSlotGuardPollInterval = time.Second
+
+// waitForLeaderSlotGap holds back a controlled identity switch while our next leader slot is fewer than
+// failover.leader_slot_guard.slots slots away, until failover.leader_slot_guard.max_wait_duration has passed - the
+// switch runs straight away if the leader schedule can't be fetched, so a cluster RPC outage doesn't block it
+func (m *Manager) waitForLeaderSlotGap(logger *log.Logger, transition string) {
+	guard := m.cfg.Failover.LeaderSlotGuard
+	if !guard.Enabled {
+		return
+	}
+
+	identity := m.cfg.Validator.Identities.ActiveKeyPair.PublicKey()
+	logger = logger.With("transition", transition, "leader_slot_guard_slots", guard.Slots)
+	deadline := time.Now().Add(guard.MaxWaitDuration)
+	for {
+		gap, err := m.clusterRPC.GetLeaderSlotGap(m.ctx, identity)
+		if err != nil {
+			logger.Warn("failed to get leader schedule - switching identity without waiting for a gap in our leader slots", "error", err)
+			return
+		}
+		if gap.Slots >= guard.Slots {
+			logger.Info("next leader slot is far enough away - switching identity", "next_leader_slot_in", gap.Slots, "scheduled", gap.Scheduled)
+			return
+		}
+		if !time.Now().Before(deadline) {
+			logger.Warn("failover.leader_slot_guard.max_wait_duration passed - switching identity regardless of leader slots", "next_leader_slot_in", gap.Slots)
+			return
+		}
+
+		logger.Info("next leader slot is too close - waiting to switch identity", "next_leader_slot_in", gap.Slots)
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(leaderSlotGuardPollInterval):
+		}
+	}
+}
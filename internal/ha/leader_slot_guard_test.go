@@ -0,0 +1,74 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLeaderScheduleServer serves getEpochInfo at the next of slotIndexes on each call, and a leader schedule with
+// the active identity leader for slots 40-43
+func mockLeaderScheduleServer(t *testing.T, manager *Manager, slotIndexes ...uint64) (*httptest.Server, *atomic.Int32) {
+	identity := manager.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
+	var epochInfoCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result any
+		switch req.Method {
+		case "getEpochInfo":
+			call := int(epochInfoCalls.Add(1)) - 1
+			result = map[string]any{
+				"absoluteSlot": 1000, "blockHeight": 1000, "epoch": 1, "transactionCount": 1,
+				"slotIndex": slotIndexes[min(call, len(slotIndexes)-1)], "slotsInEpoch": 432000,
+			}
+		case "getLeaderSchedule":
+			result = map[string][]uint64{identity: {40, 41, 42, 43}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result}))
+	}))
+	t.Cleanup(server.Close)
+	return server, &epochInfoCalls
+}
+
+func TestManager_WaitForLeaderSlotGap(t *testing.T) {
+	leaderSlotGuardPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { leaderSlotGuardPollInterval = time.Second })
+
+	cfg := createTestConfig()
+	cfg.Failover.LeaderSlotGuard.SetDefaults()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+
+	// disabled never fetches the leader schedule
+	server, calls := mockLeaderScheduleServer(t, manager, 30)
+	manager.clusterRPC = rpc.NewClient("test", server.URL)
+	manager.waitForLeaderSlotGap(manager.logger, "demote")
+	assert.Equal(t, int32(0), calls.Load())
+
+	// waits out our leader slots 40-43 until the next one is far enough away
+	cfg.Failover.LeaderSlotGuard.Enabled = true
+	server, calls = mockLeaderScheduleServer(t, manager, 30, 38, 44)
+	manager.clusterRPC = rpc.NewClient("test", server.URL)
+	manager.waitForLeaderSlotGap(manager.logger, "demote")
+	assert.Equal(t, int32(3), calls.Load())
+
+	// switches regardless once max_wait_duration has passed
+	cfg.Failover.LeaderSlotGuard.MaxWaitDuration = 50 * time.Millisecond
+	server, _ = mockLeaderScheduleServer(t, manager, 38)
+	manager.clusterRPC = rpc.NewClient("test", server.URL)
+	started := time.Now()
+	manager.waitForLeaderSlotGap(manager.logger, "promote")
+	assert.Less(t, time.Since(started), 5*time.Second)
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+}
@@ -2,15 +2,21 @@ package ha
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/log"
+	solanago "github.com/gagliardetto/solana-go"
 	solanagorpc "github.com/gagliardetto/solana-go/rpc"
 	"github.com/sol-strategies/solana-validator-ha/internal/cache"
+	"github.com/sol-strategies/solana-validator-ha/internal/client"
 	"github.com/sol-strategies/solana-validator-ha/internal/config"
 	"github.com/sol-strategies/solana-validator-ha/internal/constants"
 	"github.com/sol-strategies/solana-validator-ha/internal/gossip"
@@ -43,13 +49,99 @@ type Manager struct {
 	gossipState     *gossip.State
 	getPublicIPFunc func() (string, error)
 	localRPC        *rpc.Client
+	clusterRPC      *rpc.Client
+	clientInfo      client.Info
 	notifyManager   *notify.Manager
+	subscribers     *notify.Subscribers
 	peerCount       int
 	initialized     bool
 	logPrefix       string
-	// State tracking for notification deduplication
-	lastHealthy     bool
-	lastInGossip    bool
+	// Debounced health and gossip presence, notified when they change
+	healthCheck *hysteresis
+	gossipCheck *hysteresis
+	// peerChecks debounce each peer being lost from or discovered in gossip, keyed by peer name
+	peerChecks map[string]*hysteresis
+	// announcingTakeover is true while we wait for peer objections to our takeover
+	announcingTakeover atomic.Bool
+	// Snapshot recovery tracking for a lagging passive node
+	slotsBehind             uint64
+	snapshotLaggingSamples  int
+	lastSnapshotRecoveryAt  time.Time
+	snapshotRecoveryRunning atomic.Bool
+	// Vote account watching for unexpected commission and authority changes
+	voteAccount      solanago.PublicKey
+	voteAccountState *rpc.VoteAccountState
+	// Vote credits compared with the cluster average, debounced by validator.vote_credits.lagging_samples_threshold
+	voteCredits      *rpc.VoteCredits
+	voteCreditsCheck *hysteresis
+	// Block production this epoch, and whether its skip rate is within validator.skip_rate.threshold_percent
+	blockProduction *rpc.BlockProduction
+	skipRateCheck   *hysteresis
+	// Manual confirmation of a takeover held back by a failover policy with auto_takeover disabled
+	takeoverAwaitingConfirmation atomic.Bool
+	takeoverConfirmed            atomic.Bool
+	// flapping is true while failover.flap_detection dampens failovers
+	flapping bool
+	// circuitBreaker freezes automatic takeovers once failover.circuit_breaker.max_failovers happen within its window
+	circuitBreaker circuitBreaker
+	// Identity watchdog findings last notified, to only notify new findings
+	lastActiveIdentityFindings string
+	// procRoot is the procfs mount validator processes are inspected in - /proc when empty
+	procRoot string
+	// Run history for the exit report
+	startedAt   time.Time
+	roleHistory []roleChange
+	// Role and when it last changed restored from failover.state_file, and the state last written to it
+	restoredRole          string
+	restoredRoleChangedAt time.Time
+	savedState            []byte
+	// incident traces the current takeover attempt for failover.incident_report
+	incident *incident
+	// failoverID identifies the current or most recent role transition
+	failoverID string
+	// reloads are config reloads waiting to be applied between HA checks
+	reloads chan *config.Config
+	// Peers discovered in failover.peer_registry on top of the configured staticPeers, and discoveries waiting to
+	// be applied between HA checks. peersMu guards failover.peers and staticPeers, replaced by the HA loop while
+	// health check and control API handlers read them - through peers and configuredPeers.
+	peersMu                sync.RWMutex
+	peerRegistry           *config.PeerRegistryClient
+	staticPeers            config.Peers
+	registeredPeers        config.Peers
+	registeredPeersUpdates chan config.Peers
+	// arbitrationLock is the failover.arbitration lock we must hold to be active, and arbitrationLost is sent on
+	// when we lose it for the HA loop to make us passive
+	arbitrationLock arbitrator
+	arbitrationHeld atomic.Bool
+	arbitrationLost chan struct{}
+	// takingOver is true from acquiring the failover.arbitration lock until the takeover finishes, while the lock
+	// is renewed though we are not active yet, and arbitrationLapsing while a failed takeover leaves it to lapse
+	takingOver         atomic.Bool
+	arbitrationLapsing atomic.Bool
+	// transition is the role transition in progress, whose hooks and role command are cancelled if a takeover is
+	// aborted
+	transition transition
+	// manualFailovers are operator role changes waiting to be applied between HA checks, and takeoverHeld holds back
+	// automatic takeovers after an operator demoted us, until a peer is active
+	manualFailovers chan *manualFailover
+	takeoverHeld    bool
+	// drain hands the active role over and holds back takeovers for planned maintenance
+	drain drain
+	// failover.schedules last checked at, and the peer we hold back our takeovers for until handoverHoldUntil so
+	// it takes over - the scheduled peer, or the peer we handed the active role over to automatically
+	lastScheduleCheckAt time.Time
+	handoverHoldUntil   time.Time
+	handoverHoldPeer    string
+	// failover.failback primary ready since and the step last notified while we are active, and when another
+	// passive peer started holding back its takeover for the primary
+	failbackReadySince    time.Time
+	failbackStep          string
+	failbackHoldStartedAt time.Time
+	// the higher priority peer waiting for failover.election.preemption_delay to preempt us, and since when
+	preemptingPeerName   string
+	preemptionReadySince time.Time
+	// peerClient reaches peers' health check servers, with prometheus.health_check_tls when enabled
+	peerClient *http.Client
 }
 
 // NewManager creates a new HA manager from options
@@ -67,16 +159,27 @@ func NewManager(opts NewManagerOptions) *Manager {
 	})
 
 	manager := &Manager{
-		cfg:          opts.Cfg,
-		metrics:      metrics,
-		cache:        cache,
-		logger:       log.WithPrefix(fmt.Sprintf("[%s ha_manager]", opts.Cfg.Validator.Name)),
-		localRPC:     rpc.NewClient(opts.Cfg.Validator.Name, opts.Cfg.Validator.RPCURL),
-		ctx:          ctx,
-		cancel:       cancel,
-		peerCount:    len(opts.Cfg.Failover.Peers),
-		lastHealthy:  true,  // Assume healthy on start
-		lastInGossip: false, // Will be updated after first gossip refresh
+		cfg:         opts.Cfg,
+		metrics:     metrics,
+		cache:       cache,
+		logger:      log.WithPrefix(fmt.Sprintf("[%s ha_manager]", opts.Cfg.Validator.Name)),
+		localRPC:    rpc.NewClient(opts.Cfg.Validator.Name, opts.Cfg.Validator.RPCURL),
+		ctx:         ctx,
+		cancel:      cancel,
+		peerCount:   len(opts.Cfg.Failover.Peers),
+		subscribers: notify.NewSubscribers(),
+		reloads:     make(chan *config.Config, 1),
+		// discovered peers are applied between HA checks like reloads
+		registeredPeersUpdates: make(chan config.Peers, 1),
+		arbitrationLost:        make(chan struct{}, 1),
+		manualFailovers:        make(chan *manualFailover, 1),
+		// assume healthy on start, while gossip presence is declared by the first gossip refresh
+		healthCheck: newHealthyHysteresis(opts.Cfg.Validator.Health.Checks.RPC.UnhealthyThreshold, opts.Cfg.Validator.Health.Checks.RPC.HealthyThreshold),
+		gossipCheck: newHysteresis(opts.Cfg.Validator.Health.Checks.Gossip.UnhealthyThreshold, opts.Cfg.Validator.Health.Checks.Gossip.HealthyThreshold),
+		peerChecks:  make(map[string]*hysteresis),
+		// vote credits are assumed on par until enough lagging polls
+		voteCreditsCheck: newHealthyHysteresis(opts.Cfg.Validator.VoteCredits.LaggingSamplesThreshold, 1),
+		skipRateCheck:    newHealthyHysteresis(1, 1),
 	}
 
 	if opts.GetPublicIPFunc != nil {
@@ -86,8 +189,16 @@ func NewManager(opts NewManagerOptions) *Manager {
 	return manager
 }
 
+// Subscribe returns a channel receiving every event the manager emits, whether or not it is sent as a notification,
+// and a func to unsubscribe - see notify.Subscribers
+func (m *Manager) Subscribe(buffer int) (<-chan notify.Event, func()) {
+	return m.subscribers.Subscribe(buffer)
+}
+
 // Run starts the HA manager
 func (m *Manager) Run() error {
+	m.startedAt = time.Now()
+
 	// initialize
 	err := m.initialize()
 	if err != nil {
@@ -97,8 +208,73 @@ func (m *Manager) Run() error {
 	// start metrics server
 	go m.startMetricsServer()
 
+	// start watching the vote account if enabled
+	if m.cfg.Validator.VoteAccountWatch.Enabled {
+		go m.voteAccountWatchLoop()
+	}
+
+	// start reloading the config file when it is written if enabled
+	if m.cfg.Reload.Watch {
+		m.watchConfigFile()
+	}
+
+	// start registering in and discovering peers from failover.peer_registry if enabled
+	if m.cfg.Failover.PeerRegistry.Enabled {
+		go m.peerRegistryLoop()
+	}
+
+	// start renewing the failover.arbitration lock while active if enabled
+	if m.arbitrationLock != nil {
+		go m.arbitrationLoop()
+	}
+
+	// start sending heartbeat notifications if enabled
+	if m.cfg.Notifications.Enabled && m.cfg.Notifications.Heartbeat.Enabled {
+		go m.heartbeatLoop()
+	}
+
+	// start answering telegram bot commands if enabled
+	if m.cfg.Notifications.Enabled && m.cfg.Notifications.Telegram.Enabled && m.cfg.Notifications.Telegram.Commands.Enabled {
+		go m.telegramBotLoop()
+	}
+
+	// start writing metrics for the node_exporter textfile collector if enabled, waiting for it to remove the file on exit
+	var textfileExport sync.WaitGroup
+	if m.cfg.Prometheus.Textfile.Enabled {
+		textfileExport.Add(1)
+		go func() {
+			defer textfileExport.Done()
+			m.textfileExportLoop()
+		}()
+	}
+
 	// start monitoring loop
-	return m.haMonitorLoop()
+	err = m.haMonitorLoop()
+	textfileExport.Wait()
+	m.saveState()
+
+	// leave raft cleanly so the other peers elect a new leader straight away
+	if arbitrator, ok := m.arbitrationLock.(*raftArbitrator); ok {
+		if err := arbitrator.shutdown(); err != nil {
+			m.logger.Warn("failed to shut down raft", "error", err)
+		}
+	}
+
+	// summarise this run before notifications are closed
+	m.emitExitReport(err)
+
+	// flush any batched notifications before exiting
+	if m.notifyManager != nil {
+		m.notifyManager.Close()
+	}
+
+	return err
+}
+
+// Stop stops the manager, causing Run to return once the current HA check completes - in-flight hooks and
+// role commands are stopped rather than left running
+func (m *Manager) Stop() {
+	m.cancel()
 }
 
 // initialize initializes the manager
@@ -129,10 +305,37 @@ func (m *Manager) initialize() error {
 	// now we can set ourselves as a peer and continue
 	m.logger.Debug("adding us to config peers", "name", m.cfg.Validator.Name, "ip", publicIP)
 	m.peerSelf = &config.Peer{
-		Name: m.cfg.Validator.Name,
-		IP:   publicIP,
+		Name:     m.cfg.Validator.Name,
+		IP:       publicIP,
+		Priority: m.cfg.Validator.Priority,
+	}
+	m.setStaticPeers(m.cfg.Failover.Peers)
+
+	// add the peers registered in failover.peer_registry if enabled
+	if m.cfg.Failover.PeerRegistry.Enabled {
+		if err := m.startPeerRegistry(); err != nil {
+			return err
+		}
+	}
+
+	// reach peers with our prometheus.health_check_tls certificate, if enabled
+	if m.cfg.Prometheus.HealthCheckTLS.Enabled {
+		if m.peerClient, err = m.cfg.Prometheus.HealthCheckClient(peerRequestTimeout); err != nil {
+			return err
+		}
+	}
+
+	// connect to the failover.arbitration lock, or start raft among the peers to hold it, if enabled
+	if m.cfg.Failover.Arbitration.Enabled {
+		if m.cfg.Failover.Arbitration.IsRaft() {
+			m.arbitrationLock, err = m.startRaftArbitration()
+		} else {
+			m.arbitrationLock, err = m.cfg.Failover.Arbitration.NewLock()
+		}
+		if err != nil {
+			return err
+		}
 	}
-	m.cfg.Failover.Peers.Add(*m.peerSelf)
 
 	// initialize
 	m.logger.Info("initializing",
@@ -147,54 +350,59 @@ func (m *Manager) initialize() error {
 		"health_check_port", m.cfg.Prometheus.HealthCheckPort,
 	)
 
-	// initialize notification manager first (so gossip callbacks can use it)
-	if m.cfg.Notifications.HasAnyEnabled() {
-		m.notifyManager = notify.NewManager(notify.ManagerOptions{
-			Config:        &m.cfg.Notifications,
-			ValidatorName: m.cfg.Validator.Name,
-			PublicIP:      publicIP,
-			Cluster:       m.cfg.Cluster.Name,
+	// initialize notification manager first (so gossip callbacks can use it) - it is created even without any
+	// notification services so events still reach subscribers
+	notifications := m.cfg.Notifications
+	notifications.Enabled = m.cfg.Notifications.HasAnyEnabled()
+	m.notifyManager = notify.NewManager(notify.ManagerOptions{
+		Config:        &notifications,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      publicIP,
+		Cluster:       m.cfg.Cluster.Name,
+		Subscribers:   m.subscribers,
+	})
+
+	// create cluster rpc client, demoting persistently failing rpc urls to last resort
+	m.clusterRPC = rpc.NewClient(m.logPrefix, m.cfg.Cluster.RPCURLs...)
+	m.clusterRPC.SetDemotionPolicy(m.cfg.Cluster.RPCDemotionFailureThreshold, m.cfg.Cluster.RPCDemotionDuration)
+	if m.notifyManager != nil {
+		m.clusterRPC.OnEndpointDemoted(func(stats rpc.EndpointStats) {
+			m.notifyManager.NotifyAsync(notify.Event{
+				Type:          notify.EventRPCDemoted,
+				Severity:      notify.SeverityWarning,
+				ValidatorName: m.cfg.Validator.Name,
+				PublicIP:      publicIP,
+				Cluster:       m.cfg.Cluster.Name,
+				Message:       fmt.Sprintf("RPC endpoint %s demoted to last resort after %d consecutive failures", stats.Endpoint, stats.ConsecutiveFailures),
+				Details: map[string]string{
+					"endpoint":      stats.Endpoint,
+					"error_rate":    fmt.Sprintf("%.1f%%", stats.ErrorRate()*100),
+					"timeouts":      strconv.FormatUint(stats.Timeouts, 10),
+					"demoted_until": stats.DemotedUntil.UTC().Format(time.RFC3339),
+				},
+			})
 		})
 	}
 
 	// create gossip state with notification callbacks
 	m.logger.Debug("creating gossip state")
 	gossipOpts := gossip.Options{
-		ClusterRPC:   rpc.NewClient(m.logPrefix, m.cfg.Cluster.RPCURLs...),
+		ClusterRPC:   m.clusterRPC,
 		ActivePubkey: m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String(),
 		ConfigPeers:  m.cfg.Failover.Peers,
 		LogPrefix:    m.logPrefix,
 	}
 
+	// confirm the active peer is down with each independent rpc url on its own, without falling back to the others
+	if confirmation := m.cfg.Cluster.DelinquencyConfirmation; confirmation.Enabled {
+		for _, rpcURL := range confirmation.RPCURLs {
+			gossipOpts.ConfirmationRPCs = append(gossipOpts.ConfirmationRPCs, rpc.NewClient(m.logPrefix, rpcURL))
+		}
+		gossipOpts.ConfirmationQuorum = confirmation.Quorum
+	}
+
 	// Set up notification callbacks if notifications are enabled
 	if m.notifyManager != nil {
-		gossipOpts.OnPeerDiscovered = func(name, ip, pubkey string) {
-			m.notifyManager.NotifyAsync(notify.Event{
-				Type:          notify.EventPeerDiscovered,
-				Severity:      notify.SeverityInfo,
-				ValidatorName: m.cfg.Validator.Name,
-				PublicIP:      m.peerSelf.IP,
-				Cluster:       m.cfg.Cluster.Name,
-				Details: map[string]string{
-					"peer_name":   name,
-					"peer_ip":     ip,
-					"peer_pubkey": pubkey,
-				},
-			})
-		}
-		gossipOpts.OnPeerLost = func(name, ip string) {
-			m.notifyManager.NotifyAsync(notify.Event{
-				Type:          notify.EventPeerLost,
-				Severity:      notify.SeverityError,
-				ValidatorName: m.cfg.Validator.Name,
-				PublicIP:      m.peerSelf.IP,
-				Cluster:       m.cfg.Cluster.Name,
-				Details: map[string]string{
-					"peer_name": name,
-					"peer_ip":   ip,
-				},
-			})
-		}
 		gossipOpts.OnDelinquent = func(pubkey, gossipAddr string) {
 			m.notifyManager.NotifyAsync(notify.Event{
 				Type:          notify.EventDelinquent,
@@ -213,6 +421,12 @@ func (m *Manager) initialize() error {
 
 	m.gossipState = gossip.NewState(gossipOpts)
 
+	// restore the cooldown, drain, maintenance mode and held takeovers from before a restart
+	m.restoreState()
+
+	// detect the validator client flavor and version
+	m.refreshClientInfo()
+
 	// send startup notification
 	if m.notifyManager != nil {
 		m.notifyManager.NotifyAsync(notify.Event{
@@ -223,6 +437,7 @@ func (m *Manager) initialize() error {
 			Cluster:       m.cfg.Cluster.Name,
 			ActivePubkey:  m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String(),
 			PassivePubkey: m.cfg.Validator.Identities.PassiveKeyPair.PublicKey().String(),
+			Details:       m.startupDetails(),
 		})
 	}
 
@@ -258,21 +473,139 @@ func (m *Manager) startMetricsServer() {
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte("healthy"))
 		})
+		mux.HandleFunc("/status", m.handleStatus)
+		mux.HandleFunc("/events", m.handleEvents)
+		mux.HandleFunc("/notifications/exchanges", m.handleNotificationExchanges)
+		mux.HandleFunc("/notifications/maintenance", m.handleNotificationMaintenance)
+		mux.HandleFunc(acknowledgementsPath, m.handleAcknowledgements)
+		mux.HandleFunc(circuitBreakerPath, m.handleCircuitBreaker)
+		mux.HandleFunc(manualFailoverPath, m.handleManualFailover)
+		mux.HandleFunc(drainPath, m.handleDrain)
+		if m.cfg.Failover.TakeoverAnnouncement.Enabled {
+			mux.HandleFunc(takeoverIntentPath, m.handleTakeoverIntent)
+		}
+		// peers vote on each other's takeovers whether or not they require failover.takeover_quorum themselves
+		mux.HandleFunc(takeoverVotePath, m.handleTakeoverVote)
+		if m.cfg.Failover.Arbitration.Enabled && m.cfg.Failover.Arbitration.IsRaft() {
+			mux.HandleFunc(raftArbitrationPath, m.handleRaftCommand)
+		}
+		if m.slackActionsEnabled() {
+			mux.Handle(slackActionsPath, m.newSlackActionsHandler())
+		}
 
-		port := strconv.Itoa(m.cfg.Prometheus.HealthCheckPort)
+		tlsConfig, err := m.cfg.Prometheus.HealthCheckTLS.ServerConfig()
+		if err != nil {
+			m.logger.Error("health check server error", "error", err)
+			return
+		}
 		healthServer := &http.Server{
-			Addr:    ":" + port,
-			Handler: mux,
+			Addr:      m.cfg.Prometheus.HealthCheckAddress(),
+			Handler:   m.requireClientCertificate(mux),
+			TLSConfig: tlsConfig,
 		}
 
-		m.logger.Debug("starting health check server", "port", port)
+		m.logger.Debug("starting health check server", "address", healthServer.Addr, "mutual_tls", tlsConfig != nil)
 
-		if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if tlsConfig != nil {
+			err = healthServer.ListenAndServeTLS("", "")
+		} else {
+			err = healthServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			m.logger.Error("health check server error", "error", err)
 		}
 	}()
 }
 
+// handleStatus serves the current cached state as JSON
+func (m *Manager) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(m.cache.GetState()); err != nil {
+		m.logger.Error("failed to encode status", "error", err)
+	}
+}
+
+// handleEvents serves the recent events as JSON, oldest first - only those after the RFC3339 since query
+// parameter when given
+func (m *Manager) handleEvents(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, "since must be an RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events := []notify.Event{}
+	if m.notifyManager != nil {
+		events = m.notifyManager.Events(since)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		m.logger.Error("failed to encode events", "error", err)
+	}
+}
+
+// handleNotificationExchanges serves the most recent notifier HTTP exchanges as JSON - only to localhost as they
+// carry notifier URLs and bodies
+func (m *Manager) handleNotificationExchanges(w http.ResponseWriter, r *http.Request) {
+	if !isLoopbackRequest(r) {
+		http.Error(w, "notification exchanges can only be read from localhost", http.StatusForbidden)
+		return
+	}
+
+	exchanges := []notify.Exchange{}
+	if m.notifyManager != nil {
+		exchanges = m.notifyManager.RecentExchanges()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(exchanges); err != nil {
+		m.logger.Error("failed to encode notification exchanges", "error", err)
+	}
+}
+
+// handleNotificationMaintenance serves the notification quiet status, turning maintenance mode
+// on with POST and off with DELETE - changes are only accepted from localhost
+func (m *Manager) handleNotificationMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodDelete:
+		if !isLoopbackRequest(r) {
+			http.Error(w, "maintenance mode can only be changed from localhost", http.StatusForbidden)
+			return
+		}
+		if m.notifyManager != nil {
+			m.notifyManager.SetMaintenance(r.Method == http.MethodPost)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := notify.QuietStatus{}
+	if m.notifyManager != nil {
+		status = m.notifyManager.QuietStatus()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		m.logger.Error("failed to encode notification quiet status", "error", err)
+	}
+}
+
+// isLoopbackRequest returns true if the request came from localhost
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // haMonitorLoop runs the main ha monitoring loop
 func (m *Manager) haMonitorLoop() error {
 	m.logger.Info("monitoring HA state", "poll_interval", m.cfg.Failover.PollIntervalDuration)
@@ -295,6 +628,18 @@ func (m *Manager) haMonitorLoop() error {
 		case <-m.ctx.Done():
 			m.logger.Info("HA monitor loop done")
 			return nil
+		case cfg := <-m.reloads:
+			// applied between HA checks so a reload never interrupts a failover in progress
+			m.applyReload(cfg)
+		case registered := <-m.registeredPeersUpdates:
+			// applied between HA checks like reloads
+			m.addRegisteredPeers(registered)
+		case <-m.arbitrationLost:
+			// applied between HA checks like reloads
+			m.handleArbitrationLost()
+		case change := <-m.manualFailovers:
+			// applied between HA checks like reloads
+			m.applyManualFailover(change)
 		case <-ticker.C:
 			// Wait until the next aligned interval before running
 			// This ensures all nodes run at the same synchronized times
@@ -318,14 +663,17 @@ func (m *Manager) haMonitorLoop() error {
 			// Run at the aligned interval
 			m.ensureHAState()
 		}
+
+		// persist any change to the state kept across restarts
+		m.saveState()
 	}
 }
 
 // checkForActivePeer checks for an active peer in the gossip state
 func (m *Manager) checkForActivePeer() {
-	if m.gossipState.LeaderlessSamplesExceedsThreshold(m.cfg.Failover.LeaderlessSamplesThreshold) {
+	if m.gossipState.LeaderlessSamplesExceedsThreshold(m.leaderlessSamplesThreshold()) {
 		m.logger.Warn(fmt.Sprintf("leaderless samples exceeds threshold %d > %d",
-			m.gossipState.LeaderlessSamplesCount, m.cfg.Failover.LeaderlessSamplesThreshold))
+			m.gossipState.LeaderlessSamplesCount, m.leaderlessSamplesThreshold()))
 		return
 	}
 
@@ -343,6 +691,15 @@ func (m *Manager) checkForActivePeer() {
 	m.logger.Info(activePeerFoundMessage, "name", activePeerState.Name, "public_ip", activePeerState.IP, "pubkey", activePeerState.Pubkey)
 }
 
+// leaderlessSamplesThreshold returns the leaderless samples threshold in effect now
+func (m *Manager) leaderlessSamplesThreshold() int {
+	threshold := m.cfg.Failover.LeaderlessSamplesThresholdAt(time.Now())
+	if m.flapping {
+		threshold *= m.cfg.Failover.FlapDetection.DampeningFactor
+	}
+	return threshold
+}
+
 // ensureHAState implements basic HA logic
 func (m *Manager) ensureHAState() {
 	m.logger.Debug("ensuring HA")
@@ -350,33 +707,91 @@ func (m *Manager) ensureHAState() {
 	// refresh gossip state
 	m.gossipState.Refresh()
 
+	// trigger snapshot recovery if we are a standby that has fallen too far behind the cluster
+	m.checkSnapshotRecovery()
+
+	// compare our vote credits with the cluster average
+	m.checkVoteCredits()
+
+	// check our skip rate this epoch
+	m.checkSkipRate()
+
 	// refresh metrics
 	m.refreshMetrics()
 
+	// dampen failovers while we keep changing role
+	m.checkFlapping(time.Now())
+
+	// hand the active role over if we are draining
+	m.continueDrain()
+
+	// run any failover.schedules due
+	m.checkSchedules(time.Now())
+
+	// hand the active role back to failover.failback.primary once it is stable
+	m.checkFailback(time.Now())
+
+	// hand the active role over to a ready higher priority peer if failover.election preempts us
+	m.checkPreemption(time.Now())
+
 	// if there is an active peer found in the last failover.leaderless_samples_threshold - we are good
 	// having a lookback grace period is important to allow for RPC glitches and other issues
-	if !m.gossipState.LeaderlessSamplesExceedsThreshold(m.cfg.Failover.LeaderlessSamplesThreshold) {
+	if !m.gossipState.LeaderlessSamplesExceedsThreshold(m.leaderlessSamplesThreshold()) {
 		m.logger.Debug("active peer found - no failover required")
+		m.takeoverAwaitingConfirmation.Store(false)
+		m.takeoverConfirmed.Store(false)
+		m.failbackHoldStartedAt = time.Time{}
+
+		// a peer took over after an operator demoted us, so we take part in failovers again
+		if m.takeoverHeld && !m.isSelfActive() {
+			m.logger.Info("peer is active after manual demotion - automatic takeovers resumed")
+			m.takeoverHeld = false
+		}
+
+		// make sure we are not also using the active identity while another peer is active
+		if m.cfg.Validator.IdentityWatchdog.Enabled {
+			m.checkActiveIdentityUsage()
+		}
 		return
 	}
 
 	// we see no active peer in the last failover.leaderless_samples_threshold, so we need to failover
 	m.logger.Error(fmt.Sprintf("no active peer found in the last %d samples - failover required", m.gossipState.LeaderlessSamplesCount))
+	m.beginIncident()
+	defer m.abandonIncident()
+	m.incident.step("decision", "no active peer found in the last %d samples - failover required", m.gossipState.LeaderlessSamplesCount)
 
 	// if we don't see ourselves in gossip - bow out of the failover process and make sure we are passive - disconnection or starting up
-	if m.isSelfNotInGossip() {
+	if m.isSelfLostFromGossip() {
 		m.logger.Error("we do not appear in gossip - unable to become active in failover, ensuring we are passive")
 		m.ensurePassive()
 		// m.gossipState.Refresh() // refresh gossip state for clean next run
 		return
 	}
 	m.logger.Debug("we are in gossip", "pubkey", m.selfGossipPubkey(), "public_ip", m.peerSelf.IP)
+	m.incident.step("decision", "in gossip as %s", m.selfGossipPubkey())
 
-	// to participate in failover we must be healthy
+	// to participate in failover we must be healthy as declared by validator.health
 	if m.isSelfUnhealthy() {
 		m.logger.Error("we are not healthy - unable to become active in failover")
 		return
 	}
+	m.incident.step("decision", "local rpc reports healthy")
+
+	// a standby still recovering from a snapshot is not ready to vote
+	if m.snapshotRecoveryRunning.Load() {
+		m.logger.Error("snapshot recovery in progress - unable to become active in failover")
+		return
+	}
+
+	// promoting a node far behind the cluster is worse than staying passive
+	if err := m.waitForCatchup(m.logger); err != nil {
+		m.logger.Error("not caught up with the cluster - unable to become active in failover", "error", err)
+		return
+	}
+	if m.cfg.Failover.CatchupGate.Enabled {
+		m.incident.step("decision", "local validator is caught up with the cluster")
+	}
 
 	// one last check to ensure we are NOT already active
 	if m.isSelfActive() {
@@ -384,11 +799,68 @@ func (m *Manager) ensureHAState() {
 		return
 	}
 
+	// a draining node is about to be taken down for maintenance
+	if m.drain.draining() {
+		m.logger.Error("draining - not taking over until the drain is turned off")
+		return
+	}
+
+	// a scheduled failover or an automatic handover hands the active role to another peer
+	if time.Now().Before(m.handoverHoldUntil) {
+		m.logger.Error("holding back takeover for the active role to be handed over to another peer", "active_peer", m.handoverHoldPeer, "until", m.handoverHoldUntil.UTC())
+		return
+	}
+
+	// failover.failback prefers the primary taking over
+	if m.failbackHoldsTakeover(time.Now()) {
+		return
+	}
+
+	// an operator demoted us, so a peer takes over rather than us taking the active role straight back
+	if m.takeoverHeld {
+		m.logger.Error("automatic takeover held after manual demotion - promote to take over")
+		return
+	}
+
+	// runaway flapping is worse than staying passive
+	if !m.circuitBreakerAllowsTakeover(time.Now()) {
+		return
+	}
+
+	// a failover policy window may require manual intervention instead of automatic takeover
+	confirmed := false
+	if policy := m.cfg.Failover.Policies.Active(time.Now()); policy != nil && !policy.AutoTakeoverEnabled() {
+		if !m.takeoverConfirmed.CompareAndSwap(true, false) {
+			m.takeoverAwaitingConfirmation.Store(true)
+			m.logger.Error("automatic takeover disabled by failover policy - manual intervention required", "policy", policy.Name)
+			return
+		}
+		confirmed = true
+		m.logger.Warn("automatic takeover disabled by failover policy - proceeding with manually confirmed takeover", "policy", policy.Name)
+		m.incident.step("decision", "takeover manually confirmed - failover policy %s disables automatic takeover", policy.Name)
+	}
+
+	// failover.cooldown_duration holds back a takeover soon after our last role change so marginally healthy
+	// nodes don't ping-pong the active role - a manually confirmed takeover goes ahead regardless
+	if remaining := m.takeoverCooldownRemaining(time.Now()).Round(time.Second); remaining > 0 && !confirmed {
+		if !m.takeoverConfirmed.CompareAndSwap(true, false) {
+			m.takeoverAwaitingConfirmation.Store(true)
+			m.logger.Error("takeover held back by failover cooldown - manual intervention required to take over sooner",
+				"remaining", remaining, "last_role_change_at", m.lastRoleChangeAt())
+			return
+		}
+		m.logger.Warn("takeover held back by failover cooldown - proceeding with manually confirmed takeover", "remaining", remaining)
+		m.incident.step("decision", "takeover manually confirmed - %s of failover cooldown remaining", remaining)
+	}
+	m.takeoverAwaitingConfirmation.Store(false)
+
 	// at this point we know we are in gossip, healthy, and passive
 	// so we begin checks to make sure none of our peers have already taken over as active
 
 	// introduce a delay based on IP to safeguard against multiple nodes trying to become active at the same time
+	delayStartedAt := time.Now()
 	m.delayTakeover()
+	m.incident.timed("takeover delay", "waited for peers to take over first", delayStartedAt, nil)
 
 	// refresh the peers state to ensure no one else has taken over already if we know
 	// there are at least 2 possible peers other than ourselves - this will reset the leaderless samples count
@@ -396,7 +868,7 @@ func (m *Manager) ensureHAState() {
 	m.gossipState.Refresh()
 
 	// if someone has already taken over as active - say so and return
-	if m.gossipState.LeaderlessSamplesBelowThreshold(m.cfg.Failover.LeaderlessSamplesThreshold) {
+	if m.gossipState.LeaderlessSamplesBelowThreshold(m.leaderlessSamplesThreshold()) {
 		activePeerState, err := m.gossipState.GetActivePeer()
 		if err != nil {
 			m.logger.Warn("failed to get active peer from state, but we know someone else already assumed active role", "error", err)
@@ -409,8 +881,56 @@ func (m *Manager) ensureHAState() {
 		return
 	}
 
+	m.incident.step("decision", "no peer took over during the takeover delay")
+
+	// failover.takeover_quorum requires peers and witnesses to agree the active peer is down, so we don't take over
+	// when it is our own network that is down
+	if m.cfg.Failover.TakeoverQuorum.Enabled {
+		quorumStartedAt := time.Now()
+		if !m.takeoverQuorumReached() {
+			return
+		}
+		m.incident.timed("takeover quorum", "peers and witnesses agree the active peer is down", quorumStartedAt, nil)
+	}
+
+	// announce our intent to take over and back off if any peer objects
+	if m.cfg.Failover.TakeoverAnnouncement.Enabled {
+		announceStartedAt := time.Now()
+		if m.announceTakeover() {
+			m.logger.Warn("takeover aborted - a peer objected to our intent to take over")
+			return
+		}
+		m.incident.timed("takeover announcement", "no peer objected to our intent to take over", announceStartedAt, nil)
+	}
+
+	// failover.arbitration requires holding its lock to become active, so peers on either side of a network
+	// partition can't both take over
+	if m.arbitrationLock != nil && !m.acquireArbitrationLock() {
+		return
+	}
+
+	// failover.tower_sync copies the old active peer's tower while it may still be reachable, so we never vote from
+	// a tower older than the votes it cast
+	if m.cfg.Failover.TowerSync.Enabled && !m.syncTower() {
+		m.releaseArbitrationLock(m.logger)
+		return
+	}
+
+	// failover.fencing powers off or isolates the old active peer first, as it may be unresponsive but still voting
+	if m.cfg.Failover.Fencing.Enabled && !m.fenceOldActivePeer() {
+		m.releaseArbitrationLock(m.logger)
+		return
+	}
+
+	// the old active may have voted after its tower was copied, until it was fenced - copy its final tower again
+	if m.cfg.Failover.TowerSync.Enabled && m.cfg.Failover.Fencing.Enabled && !m.resyncTowerAfterFencing() {
+		m.releaseArbitrationLock(m.logger)
+		return
+	}
+
 	// now we know we are healthy, passive, and none of our peers have assumed active role
 	// we can take over as active - this should be idempotent in setting the active role
+	m.circuitBreaker.record(time.Now())
 	m.ensureActive()
 }
 
@@ -421,7 +941,16 @@ func (m *Manager) ensurePassive() {
 	var err error
 	passivePubkey := m.cfg.Validator.Identities.PassiveKeyPair.PublicKey().String()
 	activePubkey := m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
-	m.logger.Info("becoming passive", "pubkey", passivePubkey)
+
+	// tie this transition's logs, hooks and events together
+	failoverID := m.beginFailover(constants.StatusBecomingPassive)
+	ctx, done := m.beginTransition(false)
+	defer done()
+	logger := m.logger.With("failover_id", failoverID)
+	hookConditions := m.hookConditions(constants.RoleNamePassive)
+	m.renderRoleCommands(logger, &m.cfg.Failover.Passive, hookConditions.PreviousRole)
+	logger.Info("becoming passive", "pubkey", passivePubkey)
+	m.logFailoverPlan(logger, constants.RoleNamePassive, &m.cfg.Failover.Passive)
 
 	// Send becoming passive notification
 	if m.notifyManager != nil {
@@ -433,84 +962,95 @@ func (m *Manager) ensurePassive() {
 			Cluster:       m.cfg.Cluster.Name,
 			ActivePubkey:  activePubkey,
 			PassivePubkey: passivePubkey,
+			Details:       map[string]string{"failover_id": failoverID},
 		})
 	}
 
-	// Update failover status in cache
-	state := m.cache.GetState()
-	state.FailoverStatus = constants.StatusBecomingPassive
-	m.cache.UpdateState(state)
-
 	// run pre hooks
 	if len(m.cfg.Failover.Passive.Hooks.Pre) > 0 {
-		m.logger.Debug("running pre-passive hooks")
-		err = m.cfg.Failover.Passive.Hooks.RunPre(config.HooksRunOptions{
-			DryRun:       m.cfg.Failover.DryRun,
-			LoggerPrefix: m.logPrefix,
+		logger.Debug("running pre-passive hooks")
+		err = m.cfg.Failover.Passive.Hooks.RunPre(ctx, config.HooksRunOptions{
+			DryRun:          m.cfg.Failover.DryRun,
+			SSH:             &m.cfg.Failover.SSH,
+			Peers:           m.peers(),
+			AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
+			Conditions:      hookConditions,
+			LoggerPrefix:    m.logPrefix,
 			LoggerArgs: []any{
 				"failover_stage", "pre-passive",
+				"failover_id", failoverID,
 			},
 		})
 	}
 	if err != nil {
-		m.logger.Error("failed to run pre-passive hooks", "error", err)
+		logger.Error("failed to run pre-passive hooks", "error", err)
 		return
 	}
 
 	// run passive command
-	m.logger.Debug("running passive command")
-	err = m.cfg.Failover.Passive.RunCommand(config.RoleCommandRunOptions{
-		DryRun:       m.cfg.Failover.DryRun,
-		LoggerPrefix: m.logPrefix,
+	logger.Debug("running passive command")
+	err = m.cfg.Failover.Passive.RunCommand(ctx, config.RoleCommandRunOptions{
+		DryRun:          m.cfg.Failover.DryRun,
+		LoggerPrefix:    m.logPrefix,
+		AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
 		LoggerArgs: []any{
 			"failover_stage", constants.RoleNamePassive,
+			"failover_id", failoverID,
 			"passive_pubkey", passivePubkey,
 		},
 	})
 	if err != nil {
-		m.logger.Warn("failed to run passive command", "error", err)
+		logger.Warn("failed to run passive command", "error", err)
 		return
 	}
 
 	// run post hooks
 	if len(m.cfg.Failover.Passive.Hooks.Post) > 0 {
-		m.logger.Debug("running post-passive hooks")
-		m.cfg.Failover.Passive.Hooks.RunPost(config.HooksRunOptions{
-			DryRun:       m.cfg.Failover.DryRun,
-			LoggerPrefix: m.logPrefix,
+		logger.Debug("running post-passive hooks")
+		m.cfg.Failover.Passive.Hooks.RunPost(ctx, config.HooksRunOptions{
+			DryRun:          m.cfg.Failover.DryRun,
+			SSH:             &m.cfg.Failover.SSH,
+			Peers:           m.peers(),
+			AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
+			Conditions:      hookConditions,
+			LoggerPrefix:    m.logPrefix,
 			LoggerArgs: []any{
 				"failover_stage", "post-passive",
+				"failover_id", failoverID,
 			},
 		})
 	}
 
 	// check to ensure the call to the failover.passive.command was successful
 	if m.isNotSelfPassive() {
-		m.logger.Error("we are not passive as reported by local rpc - unable to become active in failover",
+		logger.Error("we are not passive as reported by local rpc - unable to become active in failover",
 			"passive_pubkey", passivePubkey,
 		)
 		return
 	}
 
-	m.logger.Debug("we are confirmed to be passive as reported by local rpc", "passive_pubkey", passivePubkey)
+	logger.Debug("we are confirmed to be passive as reported by local rpc", "passive_pubkey", passivePubkey)
+
+	// let a peer take the failover.arbitration lock now we no longer need it
+	m.releaseArbitrationLock(logger)
 
 	// refresh gossip state to warn if we are in gossip but not passive
 	m.gossipState.Refresh()
 
 	// if we are not in gossip, warn - we may be starting up or dropped from the network
 	if m.isSelfNotInGossip() {
-		m.logger.Warn("we are not in gossip after becoming passive", "passive_pubkey", passivePubkey)
+		logger.Warn("we are not in gossip after becoming passive", "passive_pubkey", passivePubkey)
 		return
 	}
 
 	// if we are in gossip but not passive, show error - failover.passive.command has likely fucked up
 	if m.isNotSelfPassive() {
-		m.logger.Error("we are in gossip but not passive - this should not happen check failover.passive.command logic", "passive_pubkey", passivePubkey)
+		logger.Error("we are in gossip but not passive - this should not happen check failover.passive.command logic", "passive_pubkey", passivePubkey)
 		return
 	}
 
 	// we are passive by local rpc and in gossip
-	m.logger.Info("we are confirmed to be passive", "passive_pubkey", passivePubkey)
+	logger.Info("we are confirmed to be passive", "passive_pubkey", passivePubkey)
 
 	// Send became passive notification
 	if m.notifyManager != nil {
@@ -522,6 +1062,7 @@ func (m *Manager) ensurePassive() {
 			Cluster:       m.cfg.Cluster.Name,
 			ActivePubkey:  activePubkey,
 			PassivePubkey: passivePubkey,
+			Details:       map[string]string{"failover_id": failoverID},
 		})
 	}
 }
@@ -533,11 +1074,32 @@ func (m *Manager) ensureActive() {
 	var err error
 	activePubkey := m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
 	passivePubkey := m.cfg.Validator.Identities.PassiveKeyPair.PublicKey().String()
-	m.logger.Info("becoming active", "pubkey", activePubkey)
+
+	// tie this transition's logs, hooks, events and incident report together
+	failoverID := m.beginFailover(constants.StatusBecomingActive)
+	ctx, done := m.beginTransition(true)
+	defer done()
+	logger := m.logger.With("failover_id", failoverID)
+	hookConditions := m.hookConditions(constants.RoleNameActive)
+	m.renderRoleCommands(logger, &m.cfg.Failover.Active, hookConditions.PreviousRole)
+	logger.Info("becoming active", "pubkey", activePubkey)
+	m.logFailoverPlan(logger, constants.RoleNameActive, &m.cfg.Failover.Active)
+
+	// the failover.arbitration lock is only renewed while we are active from here on
+	defer m.takingOver.Store(false)
+
+	// abort the takeover if a peer becomes active meanwhile
+	go m.watchForActivePeer(ctx)
+
+	// trace the takeover for its incident report, capturing the events emitted along the way
+	incident := m.incident
+	m.incident = nil
+	incident.subscribe(m.subscribers)
+	incident.step("active", "becoming active as %s", activePubkey)
 
 	// Send becoming active notification
 	if m.notifyManager != nil {
-		m.notifyManager.NotifyAsync(notify.Event{
+		m.notifyManager.NotifyAsync(m.recordIncidentEvent(incident, notify.Event{
 			Type:          notify.EventBecomingActive,
 			Severity:      notify.SeverityCritical,
 			ValidatorName: m.cfg.Validator.Name,
@@ -546,87 +1108,177 @@ func (m *Manager) ensureActive() {
 			ActivePubkey:  activePubkey,
 			PassivePubkey: passivePubkey,
 			Message:       "Failover triggered - validator becoming active",
-		})
+			Details:       map[string]string{"failover_id": failoverID},
+		}))
 	}
 
-	// Update failover status in cache
-	state := m.cache.GetState()
-	state.FailoverStatus = constants.StatusBecomingActive
-	m.cache.UpdateState(state)
-
 	// run pre hooks
 	if len(m.cfg.Failover.Active.Hooks.Pre) > 0 {
-		m.logger.Debug("running pre-active hooks")
-		err = m.cfg.Failover.Active.Hooks.RunPre(config.HooksRunOptions{
-			DryRun:       m.cfg.Failover.DryRun,
-			LoggerPrefix: m.logPrefix,
+		logger.Debug("running pre-active hooks")
+		err = m.cfg.Failover.Active.Hooks.RunPre(ctx, config.HooksRunOptions{
+			DryRun:          m.cfg.Failover.DryRun,
+			SSH:             &m.cfg.Failover.SSH,
+			Peers:           m.peers(),
+			AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
+			Conditions:      hookConditions,
+			LoggerPrefix:    m.logPrefix,
 			LoggerArgs: []any{
 				"failover_stage", "pre-active",
+				"failover_id", failoverID,
 			},
+			OnResult: incident.hookResults("pre-active"),
 		})
 	}
 	if err != nil {
-		m.logger.Error("failed to run pre-active hooks", "error", err)
+		logger.Error("failed to run pre-active hooks", "error", err)
+		m.finishIncident(incident, fmt.Errorf("failed to run pre-active hooks: %w", err))
+		m.abandonArbitrationLock(logger)
+		return
+	}
+
+	// hooks that may fail don't stop on an abort - never run the active command once aborted
+	if cause := context.Cause(ctx); cause != nil {
+		logger.Error("takeover aborted before running the active command", "reason", cause)
+		m.finishIncident(incident, fmt.Errorf("takeover aborted: %w", cause))
+		m.abandonArbitrationLock(logger)
 		return
 	}
 
 	// run active command
-	m.logger.Debug("running active command")
-	err = m.cfg.Failover.Active.RunCommand(config.RoleCommandRunOptions{
-		DryRun:       m.cfg.Failover.DryRun,
-		LoggerPrefix: m.logPrefix,
+	logger.Debug("running active command")
+	commandStartedAt := time.Now()
+	err = m.cfg.Failover.Active.RunCommand(ctx, config.RoleCommandRunOptions{
+		DryRun:          m.cfg.Failover.DryRun,
+		LoggerPrefix:    m.logPrefix,
+		AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
 		LoggerArgs: []any{
 			"failover_stage", constants.RoleNameActive,
+			"failover_id", failoverID,
 			"active_pubkey", activePubkey,
 		},
 	})
+	if cause := context.Cause(ctx); err != nil && cause != nil {
+		err = fmt.Errorf("takeover aborted: %w", cause)
+	}
+	incident.timed("active command", m.cfg.Failover.Active.Command, commandStartedAt, err)
 	if err != nil {
-		m.logger.Warn("failed to run active command", "error", err)
+		logger.Warn("failed to run active command", "error", err)
+		m.finishIncident(incident, fmt.Errorf("failed to run active command: %w", err))
+		m.abandonArbitrationLock(logger)
 		return
 	}
 
 	// run post hooks
 	if len(m.cfg.Failover.Active.Hooks.Post) > 0 {
-		m.logger.Debug("running post-active hooks")
-		m.cfg.Failover.Active.Hooks.RunPost(config.HooksRunOptions{
-			DryRun:       m.cfg.Failover.DryRun,
-			LoggerPrefix: m.logPrefix,
+		logger.Debug("running post-active hooks")
+		m.cfg.Failover.Active.Hooks.RunPost(ctx, config.HooksRunOptions{
+			DryRun:          m.cfg.Failover.DryRun,
+			SSH:             &m.cfg.Failover.SSH,
+			Peers:           m.peers(),
+			AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
+			Conditions:      hookConditions,
+			LoggerPrefix:    m.logPrefix,
 			LoggerArgs: []any{
 				"failover_stage", "post-active",
+				"failover_id", failoverID,
 			},
+			OnResult: incident.hookResults("post-active"),
 		})
 	}
 
 	// check to ensure the call to the failover.active.command was successful
 	if !m.isSelfActive() {
-		m.logger.Error("this node is not active as reported by local rpc - unable to become active in failover",
+		logger.Error("this node is not active as reported by local rpc - unable to become active in failover",
 			"active_pubkey", activePubkey,
 		)
+		m.finishIncident(incident, fmt.Errorf("not active as reported by local rpc after running the active command"))
+		m.abandonArbitrationLock(logger)
 		return
 	}
 
-	m.logger.Info("we are confirmed to be active", "active_pubkey", activePubkey)
-
-	// Send became active notification
+	logger.Info("we are confirmed to be active", "active_pubkey", activePubkey)
+	incident.step("active", "confirmed active by local rpc")
+
+	// Send became active notification, recorded in the incident report and linking it if written
+	becameActive := m.recordIncidentEvent(incident, notify.Event{
+		Type:          notify.EventBecameActive,
+		Severity:      notify.SeverityInfo,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		ActivePubkey:  activePubkey,
+		PassivePubkey: passivePubkey,
+		Details:       map[string]string{"failover_id": failoverID},
+	})
+	if report := m.finishIncident(incident, nil); report != "" {
+		becameActive.Details = map[string]string{"failover_id": failoverID, "incident_report": report}
+	}
 	if m.notifyManager != nil {
-		m.notifyManager.NotifyAsync(notify.Event{
-			Type:          notify.EventBecameActive,
-			Severity:      notify.SeverityInfo,
-			ValidatorName: m.cfg.Validator.Name,
-			PublicIP:      m.peerSelf.IP,
-			Cluster:       m.cfg.Cluster.Name,
-			ActivePubkey:  activePubkey,
-			PassivePubkey: passivePubkey,
-		})
+		m.notifyManager.NotifyAsync(becameActive)
+	}
+}
+
+// renderRoleCommands renders the role's commands and hooks again with the template data only known at failover
+// time - the commands are left as they were if that fails
+func (m *Manager) renderRoleCommands(logger *log.Logger, role *config.Role, previousRole string) {
+	data := config.RoleCommandTemplateData{
+		ActiveIdentityKeypairFile:  m.cfg.Validator.Identities.ActiveKeyPairPath(),
+		ActiveIdentityPubkey:       m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String(),
+		PassiveIdentityKeypairFile: m.cfg.Validator.Identities.PassiveKeyPairPath(),
+		PassiveIdentityPubkey:      m.cfg.Validator.Identities.PassiveKeyPair.PublicKey().String(),
+		SelfName:                   m.cfg.Validator.Name,
+		Cluster:                    m.cfg.Cluster.Name,
+		PreviousRole:               previousRole,
+	}
+
+	// failover.peers may list this validator too
+	data.PeerIPs = []string{}
+	peers := m.peers()
+	for _, ip := range peers.GetIPs() {
+		if m.peerSelf == nil || ip != m.peerSelf.IP {
+			data.PeerIPs = append(data.PeerIPs, ip)
+		}
+	}
+	if m.peerSelf != nil {
+		data.PublicIP = m.peerSelf.IP
+	}
+
+	if m.clusterRPC != nil {
+		if epochInfo, err := m.clusterRPC.GetEpochInfo(m.ctx); err != nil {
+			logger.Warn("failed to get epoch for command templates - rendering Epoch as 0", "error", err)
+		} else {
+			data.Epoch = epochInfo.Epoch
+		}
+	}
+
+	if err := role.RenderCommands(data); err != nil {
+		logger.Error("failed to render commands with failover time template data - running them as rendered at startup", "error", err)
 	}
 }
 
-// isSelfHealthy checks if the validator is healthy by calling the local RPC client
-func (m *Manager) isSelfHealthy() (isHealthy bool) {
+// hookConditions returns what hook when expressions are evaluated against for a transition to role
+func (m *Manager) hookConditions(role string) config.HookConditions {
+	return config.HookConditions{
+		Cluster:       m.cfg.Cluster.Name,
+		Role:          role,
+		PreviousRole:  m.cache.GetState().Role,
+		ValidatorName: m.cfg.Validator.Name,
+	}
+}
+
+// checkSelfHealth calls the local RPC client getHealth, returning whether it is healthy and the status reported
+func (m *Manager) checkSelfHealth() (isHealthy bool, healthStatus string) {
 	healthStatus, err := m.localRPC.GetHealth(m.ctx)
+	if err != nil && m.clientInfo.ToleratesMissingGetHealth() && isHealthMethodUnsupported(err) {
+		// some clients do not implement getHealth - a responsive RPC is the best signal available
+		m.logger.Debug("getHealth not supported by client, checking rpc responds", "client", m.clientInfo.Flavor)
+		if _, err = m.localRPC.GetSlot(m.ctx); err == nil {
+			healthStatus = solanagorpc.HealthOk
+		}
+	}
 	if err != nil {
 		m.logger.Error(err.Error())
-		return false
+		return false, err.Error()
 	}
 
 	isHealthy = healthStatus == solanagorpc.HealthOk
@@ -634,41 +1286,9 @@ func (m *Manager) isSelfHealthy() (isHealthy bool) {
 
 	if !isHealthy {
 		m.logger.Warn("this node is unhealthy", "status", healthStatus)
-
-		// Send health unhealthy notification (only if state changed)
-		if m.lastHealthy && m.notifyManager != nil {
-			m.notifyManager.NotifyAsync(notify.Event{
-				Type:          notify.EventHealthUnhealthy,
-				Severity:      notify.SeverityError,
-				ValidatorName: m.cfg.Validator.Name,
-				PublicIP:      m.peerSelf.IP,
-				Cluster:       m.cfg.Cluster.Name,
-				Details: map[string]string{
-					"health_status": string(healthStatus),
-				},
-			})
-		}
-		m.lastHealthy = false
-	} else if !m.lastHealthy {
-		// Health recovered
-		if m.notifyManager != nil {
-			m.notifyManager.NotifyAsync(notify.Event{
-				Type:          notify.EventHealthRecovered,
-				Severity:      notify.SeverityInfo,
-				ValidatorName: m.cfg.Validator.Name,
-				PublicIP:      m.peerSelf.IP,
-				Cluster:       m.cfg.Cluster.Name,
-			})
-		}
-		m.lastHealthy = true
 	}
 
-	return isHealthy
-}
-
-// isSelfUnhealthy checks if the validator is unhealthy by calling the local RPC client
-func (m *Manager) isSelfUnhealthy() (isUnhealthy bool) {
-	return !m.isSelfHealthy()
+	return isHealthy, healthStatus
 }
 
 // isSelfActive checks if the validator is active by checking the local RPC client getIdentity response to confirm it is the active identity
@@ -700,40 +1320,7 @@ func (m *Manager) isNotSelfPassive() (isNotPassive bool) {
 
 // isSelfInGossip checks if the validator is in the gossip state
 func (m *Manager) isSelfInGossip() (isInGossip bool) {
-	isInGossip = m.gossipState.HasIP(m.peerSelf.IP)
-
-	// Send gossip state notifications (only if state changed)
-	if !isInGossip && m.lastInGossip {
-		// Lost from gossip
-		if m.notifyManager != nil {
-			m.notifyManager.NotifyAsync(notify.Event{
-				Type:          notify.EventGossipLost,
-				Severity:      notify.SeverityError,
-				ValidatorName: m.cfg.Validator.Name,
-				PublicIP:      m.peerSelf.IP,
-				Cluster:       m.cfg.Cluster.Name,
-				Message:       "Validator is no longer visible in gossip network",
-			})
-		}
-		m.lastInGossip = false
-	} else if isInGossip && !m.lastInGossip && m.initialized {
-		// Recovered in gossip (only after initial startup)
-		if m.notifyManager != nil {
-			m.notifyManager.NotifyAsync(notify.Event{
-				Type:          notify.EventGossipRecovered,
-				Severity:      notify.SeverityInfo,
-				ValidatorName: m.cfg.Validator.Name,
-				PublicIP:      m.peerSelf.IP,
-				Cluster:       m.cfg.Cluster.Name,
-				Message:       "Validator is now visible in gossip network",
-			})
-		}
-		m.lastInGossip = true
-	} else if isInGossip {
-		m.lastInGossip = true
-	}
-
-	return isInGossip
+	return m.gossipState.HasIP(m.peerSelf.IP)
 }
 
 // isSelfNotInGossip checks if the validator is not in the gossip state
@@ -764,26 +1351,49 @@ func (m *Manager) refreshMetrics() {
 	} else {
 		role = constants.RoleNameUnknown
 	}
+	m.recordRole(role, time.Now())
 
-	if m.isSelfHealthy() {
+	// report health as declared by validator.health so single poll blips don't flap it
+	if m.observeHealth(m.checkSelfHealth()) {
 		status = constants.StatusHealthy
 	} else {
 		status = constants.StatusUnhealthy
 	}
 
+	// Re-detect the client as the validator may have been restarted or upgraded
+	m.refreshClientInfo()
+
 	// Get peer count and self in gossip status
 	peerCount := len(m.gossipState.GetPeerStates())
-	selfInGossip := m.gossipState.HasIP(m.peerSelf.IP)
+	selfInGossip := m.observeGossip(m.isSelfInGossip())
+	m.observePeers(m.gossipState.GetPeerStates())
+
+	// Get active peer name if there is one
+	activePeerName := ""
+	if activePeerState, err := m.gossipState.GetActivePeer(); err == nil {
+		activePeerName = activePeerState.Name
+	}
 
 	// Update cache with current state
 	state := cache.State{
-		ValidatorName:  m.cfg.Validator.Name,
-		PublicIP:       m.peerSelf.IP,
-		Role:           role,
-		Status:         status,
-		PeerCount:      peerCount,
-		SelfInGossip:   selfInGossip,
-		FailoverStatus: constants.StatusIdle,
+		ValidatorName:         m.cfg.Validator.Name,
+		PublicIP:              m.peerSelf.IP,
+		Role:                  role,
+		Status:                status,
+		PeerCount:             peerCount,
+		SelfInGossip:          selfInGossip,
+		ActivePeerName:        activePeerName,
+		LeaderlessSamples:     m.gossipState.LeaderlessSamplesCount,
+		FailoverStatus:        constants.StatusIdle,
+		CircuitBreakerTripped: !m.circuitBreaker.tripped().IsZero(),
+		RPCEndpoints:          m.clusterRPC.EndpointStats(),
+		Client:                m.clientInfo,
+
+		SlotsBehind:             m.slotsBehind,
+		SnapshotRecoveryRunning: m.snapshotRecoveryRunning.Load(),
+		Draining:                m.drain.draining(),
+		VoteCredits:             m.voteCredits,
+		BlockProduction:         m.blockProduction,
 	}
 
 	m.cache.UpdateState(state)
@@ -799,22 +1409,40 @@ func (m *Manager) refreshMetrics() {
 	)
 }
 
+// selfPeerRank returns our rank among peers - ordering of peers by priority, then IP, so that it is common across
+// all nodes running this function
+func (m *Manager) selfPeerRank() int {
+	peers := m.peers()
+	selfPeerRank := len(peers) + 1
+
+	// if find yourself in the ranked list, use that rank
+	if rank, ok := peers.GetRankedIPs()[m.peerSelf.IP]; ok {
+		selfPeerRank = rank
+	}
+
+	return selfPeerRank
+}
+
 // delayTakeover introduces a delay when there are multiple peers
-// to safeguard against multiple nodes trying to become active at the same time
+// to safeguard against multiple nodes trying to become active at the same time - a random one unless
+// failover.election is in ranked mode
 func (m *Manager) delayTakeover() {
 	if m.peerCount <= 1 {
 		return
 	}
 
-	// get the peer rank - artificial ordering of peers by IP so that it is common across all nodes
-	// running this function
-	selfPeerRank := len(m.cfg.Failover.Peers) + 1
-
-	// if find yourself in the ranked list, use that rank
-	if rank, ok := m.cfg.Failover.Peers.GetRankedIPs()[m.peerSelf.IP]; ok {
-		selfPeerRank = rank
+	// failover.election ranked mode waits rank_step_duration per candidate ranked ahead of us, without jitter
+	if m.cfg.Failover.Election.Mode == config.ElectionModeRanked {
+		rank, candidates := m.electionRank()
+		delay := time.Duration(rank-1) * m.cfg.Failover.Election.RankStepDuration
+		m.logger.Debug("delaying takeover for candidates ranked ahead of us", "delay", delay, "rank", rank, "candidates", candidates)
+		time.Sleep(delay)
+		m.logger.Debug("takeover delay complete", "rank", rank)
+		return
 	}
 
+	selfPeerRank := m.selfPeerRank()
+
 	// set delay seconds based on rank
 	delay := time.Duration(selfPeerRank) * time.Second
 
@@ -2,11 +2,15 @@ package ha
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	solanago "github.com/gagliardetto/solana-go"
 	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -959,3 +963,59 @@ func TestManager_EnsurePassive_WithDryRun(t *testing.T) {
 	state := manager.cache.GetState()
 	assert.Equal(t, "becoming_passive", state.FailoverStatus)
 }
+
+func TestManager_HandleEvents(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Notifications.HistorySize = 10
+
+	manager := NewManager(NewManagerOptions{
+		Cfg:             cfg,
+		GetPublicIPFunc: mockPublicIPFunc,
+	})
+	require.NoError(t, manager.initialize())
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	manager.notifyManager.Notify(notify.Event{Type: notify.EventPeerLost, Timestamp: start})
+	manager.notifyManager.Notify(notify.Event{Type: notify.EventPeerDiscovered, Timestamp: start.Add(time.Minute)})
+
+	getEvents := func(target string) (*httptest.ResponseRecorder, []notify.Event) {
+		recorder := httptest.NewRecorder()
+		manager.handleEvents(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		events := []notify.Event{}
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &events))
+		}
+		return recorder, events
+	}
+
+	recorder, events := getEvents("/events")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, events, 2)
+	assert.Equal(t, notify.EventPeerLost, events[0].Type)
+
+	_, events = getEvents("/events?since=" + start.Format(time.RFC3339))
+	require.Len(t, events, 1)
+	assert.Equal(t, notify.EventPeerDiscovered, events[0].Type)
+
+	recorder, _ = getEvents("/events?since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestManager_HandleNotificationExchanges(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	getExchanges := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/notifications/exchanges", nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		manager.handleNotificationExchanges(recorder, req)
+		return recorder
+	}
+
+	// exchanges carry notifier URLs and bodies, so are only served to localhost
+	assert.Equal(t, http.StatusForbidden, getExchanges("192.0.2.1:1234").Code)
+	recorder := getExchanges("127.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, "[]", recorder.Body.String())
+}
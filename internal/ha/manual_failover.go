@@ -0,0 +1,267 @@
+package ha
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// manualFailoverPath is the health server path operators promote, demote or fail over this node on
+const manualFailoverPath = "/failover/manual"
+
+// Manual role change actions
+const (
+	// actionPromote makes this passive node active, when no peer is active
+	actionPromote = "promote"
+	// actionDemote makes this node passive, holding back its automatic takeovers until a peer is active
+	actionDemote = "demote"
+	// actionFailover hands the active role over to a peer - this active node is demoted once a peer is in gossip
+	// to take over
+	actionFailover = "failover"
+)
+
+// ManualFailoverRequest is the body POSTed to change this node's role
+type ManualFailoverRequest struct {
+	Action string `json:"action"`
+	By     string `json:"by"`
+	Reason string `json:"reason"`
+}
+
+// ManualFailoverResult is the outcome of a manual role change
+type ManualFailoverResult struct {
+	Action     string `json:"action"`
+	Role       string `json:"role"`
+	FailoverID string `json:"failover_id,omitempty"`
+	Message    string `json:"message"`
+	// Refused is true if the role change was not attempted as it is unsafe or unnecessary
+	Refused bool `json:"refused"`
+	// Error is why the role change failed, if it did
+	Error string `json:"error,omitempty"`
+}
+
+// manualFailover is a manual role change waiting to be applied between HA checks, and where its result is sent
+type manualFailover struct {
+	ManualFailoverRequest
+	result chan ManualFailoverResult
+}
+
+// handleManualFailover applies a POSTed manual role change between HA checks, responding with its result once done.
+// Role changes are only accepted from localhost, one at a time.
+func (m *Manager) handleManualFailover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isLoopbackRequest(r) {
+		http.Error(w, "roles can only be changed from localhost", http.StatusForbidden)
+		return
+	}
+
+	var req ManualFailoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid role change body", http.StatusBadRequest)
+		return
+	}
+	switch req.Action {
+	case actionPromote, actionDemote, actionFailover:
+	default:
+		http.Error(w, fmt.Sprintf("action must be one of %s, %s or %s", actionPromote, actionDemote, actionFailover), http.StatusBadRequest)
+		return
+	}
+	if req.By == "" {
+		http.Error(w, "by is required", http.StatusBadRequest)
+		return
+	}
+
+	change := &manualFailover{ManualFailoverRequest: req, result: make(chan ManualFailoverResult, 1)}
+	select {
+	case m.manualFailovers <- change:
+	default:
+		http.Error(w, "another role change is waiting to be applied", http.StatusConflict)
+		return
+	}
+
+	var result ManualFailoverResult
+	select {
+	case result = <-change.result:
+	case <-r.Context().Done():
+		// the role change still runs, its outcome is in the logs and the manual_role_change event
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		m.logger.Error("failed to encode role change result", "error", err)
+	}
+}
+
+// applyManualFailover runs a manual role change through the same pipeline as an automatic one, telling everyone
+// who asked for it and how it went
+func (m *Manager) applyManualFailover(change *manualFailover) {
+	logger := m.logger.With("action", change.Action, "by", change.By, "reason", change.Reason)
+	logger.Warn("manual role change requested")
+
+	var result ManualFailoverResult
+	switch change.Action {
+	case actionPromote:
+		result = m.manualPromote()
+	case actionDemote:
+		result = m.manualDemote()
+	case actionFailover:
+		result = m.manualHandover()
+	}
+	// record the role we are now in straight away, rather than at the next HA check
+	if !result.Refused {
+		m.refreshMetrics()
+	}
+	result.Action = change.Action
+	result.Role = m.cache.GetState().Role
+
+	outcome, severity := "succeeded", notify.SeverityWarning
+	switch {
+	case result.Refused:
+		outcome = "refused"
+		logger.Warn("manual role change refused", "reason", result.Message)
+	case result.Error != "":
+		outcome, severity = "failed", notify.SeverityError
+		logger.Error("manual role change failed", "error", result.Error)
+	default:
+		logger.Warn("manual role change succeeded", "role", result.Role)
+	}
+
+	if m.notifyManager != nil {
+		details := map[string]string{
+			"action":       change.Action,
+			"requested_by": change.By,
+			"reason":       change.Reason,
+			"result":       outcome,
+		}
+		if result.FailoverID != "" {
+			details["failover_id"] = result.FailoverID
+		}
+		m.notifyManager.NotifyAsync(notify.Event{
+			Type:          notify.EventManualRoleChange,
+			Severity:      severity,
+			ValidatorName: m.cfg.Validator.Name,
+			PublicIP:      m.peerSelf.IP,
+			Cluster:       m.cfg.Cluster.Name,
+			Message:       fmt.Sprintf("Manual %s requested by %s %s: %s", change.Action, change.By, outcome, result.Message),
+			Details:       details,
+		})
+	}
+
+	change.result <- result
+}
+
+// manualPromote makes us active if we are passive, in gossip and healthy and no peer is active - the
+// failover.arbitration lock, tower sync and fencing still apply, while failover policies, the cooldown, the circuit
+// breaker and the takeover delay don't as an operator has decided to take over
+func (m *Manager) manualPromote() ManualFailoverResult {
+	m.gossipState.Refresh()
+
+	if m.isSelfActive() {
+		return ManualFailoverResult{Refused: true, Message: "already active"}
+	}
+	if activePeer, err := m.gossipState.GetActivePeer(); err == nil && !activePeer.IPEquals(m.peerSelf.IP) {
+		return ManualFailoverResult{Refused: true, Message: fmt.Sprintf("peer %s is active - demote it or fail it over first", activePeer.Name)}
+	}
+	if m.isSelfLostFromGossip() {
+		return ManualFailoverResult{Refused: true, Message: "not in gossip"}
+	}
+	if m.isSelfUnhealthy() {
+		return ManualFailoverResult{Refused: true, Message: "local rpc reports unhealthy"}
+	}
+	if m.snapshotRecoveryRunning.Load() {
+		return ManualFailoverResult{Refused: true, Message: "snapshot recovery in progress"}
+	}
+	if m.drain.draining() {
+		return ManualFailoverResult{Refused: true, Message: "draining - turn the drain off first"}
+	}
+	if err := m.waitForCatchup(m.logger); err != nil {
+		return ManualFailoverResult{Refused: true, Message: err.Error()}
+	}
+
+	// an operator taking over themselves lifts any hold from an earlier demotion
+	m.takeoverHeld = false
+	m.takeoverAwaitingConfirmation.Store(false)
+	m.takeoverConfirmed.Store(false)
+
+	m.beginIncident()
+	defer m.abandonIncident()
+	m.incident.step("decision", "manual promotion requested")
+	m.waitForLeaderSlotGap(m.logger, "promote")
+	if m.arbitrationLock != nil && !m.acquireArbitrationLock() {
+		return ManualFailoverResult{Error: "failed to acquire the failover.arbitration lock"}
+	}
+	if m.cfg.Failover.TowerSync.Enabled && !m.syncTower() {
+		m.releaseArbitrationLock(m.logger)
+		return ManualFailoverResult{Error: "failed to copy the tower from the old active peer"}
+	}
+	if m.cfg.Failover.Fencing.Enabled && !m.fenceOldActivePeer() {
+		m.releaseArbitrationLock(m.logger)
+		return ManualFailoverResult{Error: "failed to fence the old active peer"}
+	}
+	if m.cfg.Failover.TowerSync.Enabled && m.cfg.Failover.Fencing.Enabled && !m.resyncTowerAfterFencing() {
+		m.releaseArbitrationLock(m.logger)
+		return ManualFailoverResult{Error: "failed to copy the tower from the fenced old active peer"}
+	}
+
+	m.ensureActive()
+	if !m.isSelfActive() {
+		return ManualFailoverResult{FailoverID: m.failoverID, Error: "not active as reported by local rpc after becoming active"}
+	}
+	return ManualFailoverResult{FailoverID: m.failoverID, Message: "active"}
+}
+
+// manualDemote makes us passive, holding back our automatic takeovers until a peer is active so we don't take the
+// active role straight back
+func (m *Manager) manualDemote() ManualFailoverResult {
+	if m.isSelfPassive() {
+		m.takeoverHeld = true
+		return ManualFailoverResult{Refused: true, Message: "already passive - automatic takeovers held until a peer is active"}
+	}
+	return m.demote()
+}
+
+// manualHandover makes us passive for a peer to take over, refusing unless we are active and a peer is in gossip
+// to take over from us
+func (m *Manager) manualHandover() ManualFailoverResult {
+	m.gossipState.Refresh()
+
+	if !m.isSelfActive() {
+		return ManualFailoverResult{Refused: true, Message: "not active - only the active node can fail over"}
+	}
+	if !m.gossipState.HasPeers(m.peerSelf.IP) {
+		return ManualFailoverResult{Refused: true, Message: "no peer in gossip to take over"}
+	}
+	return m.demote()
+}
+
+// demote makes us passive, away from our leader slots with failover.leader_slot_guard, holding back our automatic
+// takeovers until a peer is active
+func (m *Manager) demote() ManualFailoverResult {
+	m.takeoverHeld = true
+	return m.becomePassive("passive - automatic takeovers held until a peer is active")
+}
+
+// handOver makes us passive for peer to take the active role over automatically, holding back our automatic
+// takeovers for hold - not until a peer is active as after a demotion, so we take over again if peer doesn't
+func (m *Manager) handOver(peer string, hold time.Duration) ManualFailoverResult {
+	m.handoverHoldUntil = time.Now().Add(hold)
+	m.handoverHoldPeer = peer
+	return m.becomePassive(fmt.Sprintf("passive - automatic takeovers held for %s to take over for %s", peer, hold))
+}
+
+// becomePassive makes us passive, away from our leader slots with failover.leader_slot_guard, returning message
+// once local rpc confirms it
+func (m *Manager) becomePassive(message string) ManualFailoverResult {
+	m.waitForLeaderSlotGap(m.logger, "demote")
+	m.ensurePassive()
+	if !m.isSelfPassive() {
+		return ManualFailoverResult{FailoverID: m.failoverID, Error: "not passive as reported by local rpc after becoming passive"}
+	}
+	return ManualFailoverResult{FailoverID: m.failoverID, Message: message}
+}
@@ -0,0 +1,62 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_HandleManualFailover(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+
+	serve := func(method string, remoteAddr string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, manualFailoverPath, strings.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		manager.handleManualFailover(recorder, req)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "127.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "192.0.2.1:1234", `{"action":"promote","by":"alice"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "127.0.0.1:1234", `{"action":"restart","by":"alice"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "127.0.0.1:1234", `{"action":"demote"}`).Code)
+
+	// the HA loop applies the change and sends its result back
+	go func() {
+		change := <-manager.manualFailovers
+		assert.Equal(t, "demote", change.Action)
+		assert.Equal(t, "alice", change.By)
+		assert.Equal(t, "maintenance", change.Reason)
+		change.result <- ManualFailoverResult{Action: change.Action, Role: "passive", FailoverID: "abc", Message: "passive"}
+	}()
+	recorder := serve(http.MethodPost, "127.0.0.1:1234", `{"action":"demote","by":"alice","reason":"maintenance"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var result ManualFailoverResult
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.Equal(t, ManualFailoverResult{Action: "demote", Role: "passive", FailoverID: "abc", Message: "passive"}, result)
+
+	// one role change at a time
+	manager.manualFailovers <- &manualFailover{}
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "127.0.0.1:1234", `{"action":"promote","by":"alice"}`).Code)
+}
+
+func TestManager_HandOver(t *testing.T) {
+	cfg := createTestConfig()
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.localRPC = newPassiveIdentityRPC(t, cfg)
+
+	// an automatic handover holds back our takeovers for a while, not until a peer is active as a demotion does
+	result := manager.handOver("peer1", 5*time.Minute)
+	assert.Empty(t, result.Error)
+	assert.False(t, manager.takeoverHeld)
+	assert.Equal(t, "peer1", manager.handoverHoldPeer)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), manager.handoverHoldUntil, time.Second)
+}
@@ -0,0 +1,164 @@
+package ha
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// startPeerRegistry registers us in failover.peer_registry and adds the peers registered there, failing when there
+// are no failover.peers to fall back on and the registry can't be read
+func (m *Manager) startPeerRegistry() error {
+	client, err := m.cfg.Failover.PeerRegistry.NewClient()
+	if err != nil {
+		return err
+	}
+	m.peerRegistry = client
+
+	registered, err := m.refreshPeerRegistry()
+	if err != nil {
+		if len(m.configuredPeers()) == 0 {
+			return fmt.Errorf("failed to discover peers in failover.peer_registry and no failover.peers are defined: %w", err)
+		}
+		m.logger.Error("failed to discover peers in failover.peer_registry - starting with failover.peers only", "error", err)
+		return nil
+	}
+
+	m.addRegisteredPeers(registered)
+	return nil
+}
+
+// refreshPeerRegistry refreshes our registration in failover.peer_registry and returns the other peers registered
+// there
+func (m *Manager) refreshPeerRegistry() (config.Peers, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, m.cfg.Failover.PeerRegistry.RefreshIntervalDuration)
+	defer cancel()
+
+	if err := m.peerRegistry.Register(ctx, *m.peerSelf); err != nil {
+		m.logger.Error("failed to register in failover.peer_registry", "error", err)
+	}
+
+	registered, err := m.peerRegistry.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// we are not our own peer
+	peers := config.Peers{}
+	for name, peer := range registered {
+		if name != m.peerSelf.Name && peer.IP != m.peerSelf.IP {
+			peers.Add(peer)
+		}
+	}
+	return peers, nil
+}
+
+// peerRegistryLoop refreshes our registration and looks for new peers every
+// failover.peer_registry.refresh_interval_duration until the manager is stopped
+func (m *Manager) peerRegistryLoop() {
+	ticker := time.NewTicker(m.cfg.Failover.PeerRegistry.RefreshIntervalDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			registered, err := m.refreshPeerRegistry()
+			if err != nil {
+				m.logger.Error("failed to discover peers in failover.peer_registry - keeping the current peers", "error", err)
+				continue
+			}
+
+			// applied between HA checks, like config reloads - a discovery not yet applied is replaced
+			select {
+			case <-m.registeredPeersUpdates:
+			default:
+			}
+			m.registeredPeersUpdates <- registered
+		}
+	}
+}
+
+// addRegisteredPeers adds peers discovered in failover.peer_registry to failover.peers. Peers whose registration
+// lapses are kept until restart, so a peer whose solana-validator-ha stops while its validator keeps voting is
+// never mistaken for a missing active peer.
+func (m *Manager) addRegisteredPeers(registered config.Peers) {
+	if m.registeredPeers == nil {
+		m.registeredPeers = config.Peers{}
+	}
+
+	changed := false
+	for name, peer := range registered {
+		if existing, ok := m.registeredPeers[name]; ok && existing == peer {
+			continue
+		}
+		// a peer registering again with a new IP replaces its old registration
+		for existingName, existing := range m.registeredPeers {
+			if existing.IP == peer.IP {
+				delete(m.registeredPeers, existingName)
+			}
+		}
+		m.registeredPeers[name] = peer
+		changed = true
+		m.logger.Info("peer discovered in failover.peer_registry", "name", name, "ip", peer.IP, "priority", peer.Priority)
+	}
+
+	if changed {
+		m.setPeers()
+	}
+}
+
+// setStaticPeers sets the configured peers to a copy of peers, keeping those discovered in failover.peer_registry
+func (m *Manager) setStaticPeers(peers config.Peers) {
+	staticPeers := maps.Clone(peers)
+	if staticPeers == nil {
+		staticPeers = config.Peers{}
+	}
+
+	m.peersMu.Lock()
+	m.staticPeers = staticPeers
+	m.peersMu.Unlock()
+	m.setPeers()
+}
+
+// setPeers sets failover.peers to the configured peers, those discovered in failover.peer_registry and ourselves -
+// configured peers win over registrations of the same name or IP
+func (m *Manager) setPeers() {
+	staticPeers := m.configuredPeers()
+	peers := config.Peers{}
+	for name, peer := range m.registeredPeers {
+		if _, ok := staticPeers[name]; !ok && !staticPeers.HasIP(peer.IP) {
+			peers.Add(peer)
+		}
+	}
+	maps.Copy(peers, staticPeers)
+
+	m.peerCount = len(peers)
+	peers.Add(*m.peerSelf)
+	m.peersMu.Lock()
+	m.cfg.Failover.Peers = peers
+	m.peersMu.Unlock()
+	if m.gossipState != nil {
+		m.gossipState.SetConfigPeers(peers)
+	}
+}
+
+// peers returns failover.peers, ourselves included - the map is replaced rather than modified, so callers may read
+// it without holding peersMu
+func (m *Manager) peers() config.Peers {
+	m.peersMu.RLock()
+	defer m.peersMu.RUnlock()
+	return m.cfg.Failover.Peers
+}
+
+// configuredPeers returns the configured failover.peers, without those discovered in failover.peer_registry or
+// ourselves - the map is replaced rather than modified, as with peers
+func (m *Manager) configuredPeers() config.Peers {
+	m.peersMu.RLock()
+	defer m.peersMu.RUnlock()
+	return m.staticPeers
+}
@@ -0,0 +1,37 @@
+package ha
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_AddRegisteredPeers(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	// configured peers win over registrations of the same name or IP
+	manager.addRegisteredPeers(config.Peers{
+		"peer1":  {Name: "peer1", IP: "192.168.1.201"},
+		"peer2b": {Name: "peer2b", IP: "192.168.1.102"},
+		"peer3":  {Name: "peer3", IP: "192.168.1.103", Priority: 10},
+	})
+	assert.ElementsMatch(t, []string{"peer1", "peer2", "peer3", "test-validator"}, peerNames(manager.cfg.Failover.Peers))
+	assert.Equal(t, "192.168.1.101", manager.cfg.Failover.Peers["peer1"].IP)
+	assert.Equal(t, 10, manager.cfg.Failover.Peers["peer3"].Priority)
+	assert.Equal(t, 3, manager.peerCount)
+	assert.Equal(t, 1, manager.cfg.Failover.Peers.GetRankedIPs()["192.168.1.103"])
+
+	// lapsed registrations are kept, and a peer registering with a new name replaces its old one
+	manager.addRegisteredPeers(config.Peers{"peer4": {Name: "peer4", IP: "192.168.1.103"}})
+	assert.ElementsMatch(t, []string{"peer1", "peer2", "peer4", "test-validator"}, peerNames(manager.cfg.Failover.Peers))
+
+	// registered peers survive reloads, and are no longer shadowed by configured peers removed
+	reloaded := createTestConfig()
+	reloaded.Failover.Peers = config.Peers{"peer1": {IP: "192.168.1.101", Name: "peer1"}}
+	manager.applyReload(reloaded)
+	assert.ElementsMatch(t, []string{"peer1", "peer2b", "peer4", "test-validator"}, peerNames(manager.cfg.Failover.Peers))
+	assert.Equal(t, 3, manager.peerCount)
+}
@@ -0,0 +1,70 @@
+package ha
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// peerRequestTimeout bounds requests to peers' health check servers that have no deadline of their own
+const peerRequestTimeout = 30 * time.Second
+
+// peerHTTPClient returns the client peers' health check servers are reached with, presenting our certificate when
+// prometheus.health_check_tls is enabled
+func (m *Manager) peerHTTPClient() *http.Client {
+	if m.peerClient == nil {
+		return http.DefaultClient
+	}
+	return m.peerClient
+}
+
+// requireClientCertificate refuses requests without a verified client certificate when
+// prometheus.health_check_tls is enabled - /health stays open for load balancers and Slack callbacks are signed
+func (m *Manager) requireClientCertificate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && r.URL.Path != slackActionsPath && m.cfg.Prometheus.HealthCheckTLS.RequireClientCertificate(r) {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// raftTLSStreamLayer carries raft between peers over prometheus.health_check_tls mutual TLS
+type raftTLSStreamLayer struct {
+	net.Listener
+	advertise    net.Addr
+	clientConfig *tls.Config
+}
+
+// newRaftTLSStreamLayer listens for raft peers on address, requiring their client certificates
+func (m *Manager) newRaftTLSStreamLayer(address string, advertise net.Addr) (*raftTLSStreamLayer, error) {
+	serverConfig, err := m.cfg.Prometheus.HealthCheckTLS.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientConfig, err := m.cfg.Prometheus.HealthCheckTLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+
+	listener, err := tls.Listen("tcp", address, serverConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &raftTLSStreamLayer{Listener: listener, advertise: advertise, clientConfig: clientConfig}, nil
+}
+
+// Addr returns the address peers reach us at
+func (l *raftTLSStreamLayer) Addr() net.Addr {
+	return l.advertise
+}
+
+// Dial connects to a raft peer
+func (l *raftTLSStreamLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", string(address), l.clientConfig)
+}
@@ -0,0 +1,36 @@
+package ha
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_RequireClientCertificate(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Prometheus.HealthCheckTLS.Enabled = true
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	handler := manager.requireClientCertificate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(path string, state *tls.ConnectionState) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.TLS = state
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	withCert := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}}
+	assert.Equal(t, http.StatusUnauthorized, serve("/status", nil))
+	assert.Equal(t, http.StatusUnauthorized, serve(takeoverIntentPath, &tls.ConnectionState{}))
+	assert.Equal(t, http.StatusOK, serve("/status", withCert))
+	assert.Equal(t, http.StatusOK, serve("/health", nil), "load balancer health checks stay open")
+	assert.Equal(t, http.StatusOK, serve(slackActionsPath, &tls.ConnectionState{}), "slack callbacks are signed instead")
+
+	cfg.Prometheus.HealthCheckTLS.Enabled = false
+	assert.Equal(t, http.StatusOK, serve("/status", nil))
+}
@@ -0,0 +1,436 @@
+package ha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+)
+
+const raftArbitrationPath = "/peer/arbitration"
+
+// Raft commands changing the active token
+const (
+	raftOpAcquire = "acquire"
+	raftOpRelease = "release"
+)
+
+const (
+	// raftTransportTimeout is how long a Raft RPC to a peer has to complete
+	raftTransportTimeout = 10 * time.Second
+	// raftSnapshotsRetained is how many Raft snapshots are kept in failover.arbitration.data_dir
+	raftSnapshotsRetained = 2
+)
+
+// raftToken is the active token Raft replicates - the IP of the peer holding it and when it lapses unless renewed
+type raftToken struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// raftCommand changes the active token. At is the leader's time when it applies the command, so every peer
+// applies it alike.
+type raftCommand struct {
+	Op     string        `json:"op"`
+	Holder string        `json:"holder"`
+	At     time.Time     `json:"at"`
+	TTL    time.Duration `json:"ttl"`
+}
+
+// raftTokenFSM is the state machine of the active token - it is acquired when free, lapsed or already held by
+// the same validator, and released only by its holder
+type raftTokenFSM struct {
+	mu    sync.Mutex
+	token raftToken
+}
+
+// Apply applies a committed command, returning the holder of the token after it
+func (f *raftTokenFSM) Apply(entry *raft.Log) any {
+	var command raftCommand
+	if err := json.Unmarshal(entry.Data, &command); err != nil {
+		return fmt.Errorf("invalid raft command: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch command.Op {
+	case raftOpAcquire:
+		if f.token.Holder == "" || f.token.Holder == command.Holder || !command.At.Before(f.token.ExpiresAt) {
+			f.token = raftToken{Holder: command.Holder, ExpiresAt: command.At.Add(command.TTL)}
+		}
+	case raftOpRelease:
+		if f.token.Holder == command.Holder {
+			f.token = raftToken{}
+		}
+	}
+	return f.token.Holder
+}
+
+// Snapshot returns the token to persist
+func (f *raftTokenFSM) Snapshot() (raft.FSMSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &raftTokenSnapshot{token: f.token}, nil
+}
+
+// Restore restores the token from a snapshot
+func (f *raftTokenFSM) Restore(snapshot io.ReadCloser) error {
+	defer snapshot.Close()
+	var token raftToken
+	if err := json.NewDecoder(snapshot).Decode(&token); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token = token
+	return nil
+}
+
+// raftTokenSnapshot is a snapshot of the active token
+type raftTokenSnapshot struct {
+	token raftToken
+}
+
+// Persist writes the token to sink
+func (s *raftTokenSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s.token); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release does nothing - the snapshot holds no resources
+func (s *raftTokenSnapshot) Release() {}
+
+// raftArbitrator holds the failover.arbitration lock as a token replicated by Raft among failover.peers, so
+// exactly one peer holds it without running etcd or Consul
+type raftArbitrator struct {
+	raft *raft.Raft
+	ttl  time.Duration
+	// forward sends a command to the leader when we aren't it
+	forward func(ctx context.Context, leader raft.ServerAddress, command raftCommand) (string, error)
+	// close closes the Raft stores once Raft is shut down
+	close func() error
+	// holderIP and holderName map validator names to the peer IPs the token is held by, as peers may name each
+	// other differently - names are used as is when nil
+	holderIP   func(name string) string
+	holderName func(ip string) string
+}
+
+// Acquire acquires the token for holder, or renews it if holder already holds it, returning its holder
+func (a *raftArbitrator) Acquire(ctx context.Context, holder string) (string, error) {
+	holder, err := a.apply(ctx, raftCommand{Op: raftOpAcquire, Holder: a.tokenHolder(holder), TTL: a.ttl})
+	if err != nil || a.holderName == nil {
+		return holder, err
+	}
+	return a.holderName(holder), nil
+}
+
+// Release releases the token if holder holds it
+func (a *raftArbitrator) Release(ctx context.Context, holder string) error {
+	_, err := a.apply(ctx, raftCommand{Op: raftOpRelease, Holder: a.tokenHolder(holder)})
+	return err
+}
+
+// tokenHolder returns the token holder of the named validator
+func (a *raftArbitrator) tokenHolder(name string) string {
+	if a.holderIP == nil {
+		return name
+	}
+	return a.holderIP(name)
+}
+
+// apply applies command, forwarding it to the leader when we aren't it
+func (a *raftArbitrator) apply(ctx context.Context, command raftCommand) (string, error) {
+	if a.raft.State() == raft.Leader {
+		return a.applyLocal(ctx, command)
+	}
+
+	leader, _ := a.raft.LeaderWithID()
+	if leader == "" {
+		return "", fmt.Errorf("no raft leader - a majority of failover.peers must be reachable")
+	}
+	return a.forward(ctx, leader, command)
+}
+
+// applyLocal applies command as the leader, returning the token's holder after it
+func (a *raftArbitrator) applyLocal(ctx context.Context, command raftCommand) (string, error) {
+	command.At = time.Now()
+	data, err := json.Marshal(command)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := a.ttl
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	future := a.raft.Apply(data, timeout)
+	if err := future.Error(); err != nil {
+		return "", fmt.Errorf("failed to apply raft %s: %w", command.Op, err)
+	}
+
+	switch response := future.Response().(type) {
+	case error:
+		return "", response
+	case string:
+		return response, nil
+	}
+	return "", fmt.Errorf("unexpected raft %s response %v", command.Op, future.Response())
+}
+
+// shutdown stops Raft and closes its stores
+func (a *raftArbitrator) shutdown() error {
+	if err := a.raft.Shutdown().Error(); err != nil {
+		return err
+	}
+	return a.close()
+}
+
+// startRaftArbitration starts Raft among failover.peers for the failover.arbitration lock, bootstrapping the
+// cluster from failover.peers on first start. Raft's log, term and snapshots are kept in
+// failover.arbitration.data_dir, so a restarted peer rejoins where it left off.
+func (m *Manager) startRaftArbitration() (*raftArbitrator, error) {
+	arbitration := m.cfg.Failover.Arbitration
+	bindHost, port, err := arbitration.RaftAddress()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(arbitration.DataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create failover.arbitration.data_dir: %w", err)
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:        "raft",
+		Level:       hclog.Warn,
+		Output:      m.logger.StandardLog(log.StandardLogOptions{ForceLevel: log.WarnLevel}).Writer(),
+		DisableTime: true,
+	})
+
+	store, err := raftboltdb.NewBoltStore(filepath.Join(arbitration.DataDir, "raft.db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raft store: %w", err)
+	}
+	snapshots, err := raft.NewFileSnapshotStoreWithLogger(arbitration.DataDir, raftSnapshotsRetained, logger)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to open raft snapshots: %w", err)
+	}
+	// raft only runs over prometheus.health_check_tls mutual TLS - a plain transport would let anyone reaching the
+	// port join, vote or take the token
+	if !m.cfg.Prometheus.HealthCheckTLS.Enabled {
+		store.Close()
+		return nil, fmt.Errorf("failover.arbitration raft requires prometheus.health_check_tls to authenticate peers")
+	}
+	advertise := &net.TCPAddr{IP: net.ParseIP(m.peerSelf.IP), Port: port}
+	stream, err := m.newRaftTLSStreamLayer(net.JoinHostPort(bindHost, strconv.Itoa(port)), advertise)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to listen for raft peers: %w", err)
+	}
+	transport := raft.NewNetworkTransportWithLogger(stream, 3, raftTransportTimeout, logger)
+
+	// members are identified by their IP rather than by name, as peers may name each other differently
+	conf := raft.DefaultConfig()
+	conf.LocalID = raft.ServerID(m.peerSelf.IP)
+	conf.Logger = logger
+
+	// every peer bootstraps the same configuration, so whichever starts first doesn't matter
+	hasState, err := raft.HasExistingState(store, store, snapshots)
+	if err != nil {
+		transport.Close()
+		store.Close()
+		return nil, fmt.Errorf("failed to read raft state: %w", err)
+	}
+	if !hasState {
+		servers := []raft.Server{{ID: conf.LocalID, Address: transport.LocalAddr()}}
+		for _, peer := range m.configuredPeers() {
+			servers = append(servers, raft.Server{
+				ID:      raft.ServerID(peer.IP),
+				Address: raft.ServerAddress(net.JoinHostPort(peer.IP, strconv.Itoa(port))),
+			})
+		}
+		if err := raft.BootstrapCluster(conf, store, store, snapshots, transport, raft.Configuration{Servers: servers}); err != nil {
+			transport.Close()
+			store.Close()
+			return nil, fmt.Errorf("failed to bootstrap raft: %w", err)
+		}
+	}
+
+	r, err := raft.NewRaft(conf, &raftTokenFSM{}, store, store, snapshots, transport)
+	if err != nil {
+		transport.Close()
+		store.Close()
+		return nil, fmt.Errorf("failed to start raft: %w", err)
+	}
+
+	return &raftArbitrator{
+		raft:       r,
+		ttl:        arbitration.TTLDuration,
+		forward:    m.forwardRaftCommand,
+		close:      store.Close,
+		holderIP:   m.raftHolderIP,
+		holderName: m.raftHolderName,
+	}, nil
+}
+
+// raftHolderIP returns the IP the named validator - us or one of failover.peers - holds the raft token by
+func (m *Manager) raftHolderIP(name string) string {
+	if name == m.peerSelf.Name {
+		return m.peerSelf.IP
+	}
+	if peer, ok := m.configuredPeers()[name]; ok {
+		return peer.IP
+	}
+	return name
+}
+
+// raftHolderName returns the name of the peer holding the raft token by ip - the ip itself when unknown
+func (m *Manager) raftHolderName(ip string) string {
+	if ip == m.peerSelf.IP {
+		return m.peerSelf.Name
+	}
+	for name, peer := range m.configuredPeers() {
+		if peer.IP == ip {
+			return name
+		}
+	}
+	return ip
+}
+
+// raftCommandRequest is a command forwarded to the Raft leader's health check server - signed by the shared active
+// identity like takeover intents, proving it comes from an HA peer
+type raftCommandRequest struct {
+	Command   raftCommand `json:"command"`
+	IP        string      `json:"ip"`
+	Timestamp int64       `json:"timestamp"`
+	Signature string      `json:"signature"`
+}
+
+// raftCommandResponse is the token's holder after a forwarded command
+type raftCommandResponse struct {
+	Holder string `json:"holder"`
+}
+
+// payload returns the bytes that are signed for the request
+func (r *raftCommandRequest) payload() []byte {
+	return []byte(fmt.Sprintf("arbitration|%s|%s|%s|%d", r.Command.Op, r.Command.Holder, r.IP, r.Timestamp))
+}
+
+// forwardRaftCommand sends command to the leader's health check server
+func (m *Manager) forwardRaftCommand(ctx context.Context, leader raft.ServerAddress, command raftCommand) (string, error) {
+	host, _, err := net.SplitHostPort(string(leader))
+	if err != nil {
+		return "", fmt.Errorf("invalid raft leader address %s: %w", leader, err)
+	}
+
+	request := raftCommandRequest{Command: command, IP: m.peerSelf.IP, Timestamp: time.Now().UTC().Unix()}
+	signature, err := m.cfg.Validator.Identities.ActiveKeyPair.Sign(request.payload())
+	if err != nil {
+		return "", fmt.Errorf("failed to sign raft %s: %w", command.Op, err)
+	}
+	request.Signature = signature.String()
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	url := m.cfg.Prometheus.PeerHealthCheckURL(host, raftArbitrationPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.peerHTTPClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to forward raft %s to leader %s: %w", command.Op, host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("raft leader %s returned status %d: %s", host, resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var response raftCommandResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	return response.Holder, nil
+}
+
+// handleRaftCommand applies a command a peer forwarded to us as the Raft leader
+func (m *Manager) handleRaftCommand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request raftCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid raft command", http.StatusBadRequest)
+		return
+	}
+	if err := m.verifyRaftCommand(request); err != nil {
+		m.logger.Warn("rejected raft command", "op", request.Command.Op, "holder", request.Command.Holder, "ip", request.IP, "error", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	arbitrator, ok := m.arbitrationLock.(*raftArbitrator)
+	if !ok || arbitrator.raft.State() != raft.Leader {
+		http.Error(w, "not the raft leader", http.StatusConflict)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), m.cfg.Failover.Arbitration.TTLDuration)
+	defer cancel()
+	request.Command.TTL = m.cfg.Failover.Arbitration.TTLDuration
+	holder, err := arbitrator.applyLocal(ctx, request.Command)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(raftCommandResponse{Holder: holder})
+}
+
+// verifyRaftCommand checks the command comes from the configured peer holding it by IP, is recent and is signed by
+// the active identity
+func (m *Manager) verifyRaftCommand(request raftCommandRequest) error {
+	staticPeers := m.configuredPeers()
+	if request.Command.Holder != request.IP || !staticPeers.HasIP(request.IP) {
+		return fmt.Errorf("holder %s is not the failover.peers peer at %s", request.Command.Holder, request.IP)
+	}
+
+	maxAge := m.cfg.Failover.Arbitration.TTLDuration
+	if age := time.Since(time.Unix(request.Timestamp, 0)); age < -maxAge || age > maxAge {
+		return fmt.Errorf("command timestamp outside allowed age of %s", maxAge)
+	}
+
+	signature, err := solanago.SignatureFromBase58(request.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if !m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().Verify(request.payload(), signature) {
+		return fmt.Errorf("signature does not match active identity")
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package ha
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaftTokenFSM(t *testing.T) {
+	fsm := &raftTokenFSM{}
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	apply := func(op, holder string, at time.Time) any {
+		data, err := json.Marshal(raftCommand{Op: op, Holder: holder, At: at, TTL: 15 * time.Second})
+		require.NoError(t, err)
+		return fsm.Apply(&raft.Log{Data: data})
+	}
+
+	assert.Equal(t, "validator-1", apply(raftOpAcquire, "validator-1", at))
+	// renewing extends the token, and it can't be taken until it lapses
+	assert.Equal(t, "validator-1", apply(raftOpAcquire, "validator-1", at.Add(10*time.Second)))
+	assert.Equal(t, "validator-1", apply(raftOpAcquire, "validator-2", at.Add(20*time.Second)))
+	assert.Equal(t, "validator-2", apply(raftOpAcquire, "validator-2", at.Add(25*time.Second)))
+
+	// only the holder releases it
+	assert.Equal(t, "validator-2", apply(raftOpRelease, "validator-1", at.Add(30*time.Second)))
+	assert.Equal(t, "", apply(raftOpRelease, "validator-2", at.Add(30*time.Second)))
+	assert.Equal(t, "validator-1", apply(raftOpAcquire, "validator-1", at.Add(31*time.Second)))
+
+	// the token survives a snapshot and restore
+	snapshot, err := fsm.Snapshot()
+	require.NoError(t, err)
+	sink := &fakeSnapshotSink{}
+	require.NoError(t, snapshot.Persist(sink))
+	restored := &raftTokenFSM{}
+	require.NoError(t, restored.Restore(sink))
+	assert.Equal(t, fsm.token.Holder, restored.token.Holder)
+	assert.True(t, fsm.token.ExpiresAt.Equal(restored.token.ExpiresAt))
+}
+
+// fakeSnapshotSink keeps a persisted snapshot in memory
+type fakeSnapshotSink struct {
+	data []byte
+	read int
+}
+
+func (s *fakeSnapshotSink) Write(p []byte) (int, error) {
+	s.data = append(s.data, p...)
+	return len(p), nil
+}
+
+func (s *fakeSnapshotSink) Read(p []byte) (int, error) {
+	if s.read >= len(s.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, s.data[s.read:])
+	s.read += n
+	return n, nil
+}
+
+func (s *fakeSnapshotSink) Close() error  { return nil }
+func (s *fakeSnapshotSink) ID() string    { return "test" }
+func (s *fakeSnapshotSink) Cancel() error { return nil }
+
+func TestRaftArbitrator_Cluster(t *testing.T) {
+	names := []string{"validator-1", "validator-2", "validator-3"}
+	transports := map[raft.ServerAddress]*raft.InmemTransport{}
+	configuration := raft.Configuration{}
+	for _, name := range names {
+		address, transport := raft.NewInmemTransport(raft.ServerAddress(name))
+		transports[address] = transport
+		configuration.Servers = append(configuration.Servers, raft.Server{ID: raft.ServerID(name), Address: address})
+	}
+	for _, transport := range transports {
+		for address, peer := range transports {
+			transport.Connect(address, peer)
+		}
+	}
+
+	// commands are forwarded to the leader directly rather than over its health check server
+	arbitrators := map[raft.ServerAddress]*raftArbitrator{}
+	forward := func(ctx context.Context, leader raft.ServerAddress, command raftCommand) (string, error) {
+		return arbitrators[leader].applyLocal(ctx, command)
+	}
+	for _, name := range names {
+		conf := raft.DefaultConfig()
+		conf.LocalID = raft.ServerID(name)
+		conf.Logger = hclog.NewNullLogger()
+		store := raft.NewInmemStore()
+		snapshots := raft.NewInmemSnapshotStore()
+		transport := transports[raft.ServerAddress(name)]
+		require.NoError(t, raft.BootstrapCluster(conf, store, store, snapshots, transport, configuration))
+		r, err := raft.NewRaft(conf, &raftTokenFSM{}, store, store, snapshots, transport)
+		require.NoError(t, err)
+		arbitrators[raft.ServerAddress(name)] = &raftArbitrator{raft: r, ttl: 15 * time.Second, forward: forward, close: func() error { return nil }}
+	}
+	defer func() {
+		for _, arbitrator := range arbitrators {
+			arbitrator.shutdown()
+		}
+	}()
+
+	// wait for a leader
+	require.Eventually(t, func() bool {
+		leader, _ := arbitrators["validator-1"].raft.LeaderWithID()
+		return leader != ""
+	}, 10*time.Second, 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// whichever peer is the leader, exactly one holds the token
+	holder, err := arbitrators["validator-1"].Acquire(ctx, "validator-1")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-1", holder)
+	holder, err = arbitrators["validator-2"].Acquire(ctx, "validator-2")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-1", holder)
+
+	require.NoError(t, arbitrators["validator-1"].Release(ctx, "validator-1"))
+	holder, err = arbitrators["validator-2"].Acquire(ctx, "validator-2")
+	require.NoError(t, err)
+	assert.Equal(t, "validator-2", holder)
+}
+
+func TestManager_VerifyRaftCommand(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.cfg.Failover.Arbitration.SetDefaults()
+
+	sign := func(request raftCommandRequest) raftCommandRequest {
+		signature, err := manager.cfg.Validator.Identities.ActiveKeyPair.Sign(request.payload())
+		require.NoError(t, err)
+		request.Signature = signature.String()
+		return request
+	}
+
+	request := sign(raftCommandRequest{
+		Command:   raftCommand{Op: raftOpAcquire, Holder: "192.168.1.101"},
+		IP:        "192.168.1.101",
+		Timestamp: time.Now().Unix(),
+	})
+	assert.NoError(t, manager.verifyRaftCommand(request))
+
+	// a peer can only act for itself
+	impersonating := request
+	impersonating.Command.Holder = "192.168.1.102"
+	assert.ErrorContains(t, manager.verifyRaftCommand(sign(impersonating)), "holder 192.168.1.102 is not the failover.peers peer at 192.168.1.101")
+
+	// an ip outside failover.peers can't hold the token
+	unknown := request
+	unknown.Command.Holder, unknown.IP = "10.0.0.1", "10.0.0.1"
+	assert.ErrorContains(t, manager.verifyRaftCommand(sign(unknown)), "holder 10.0.0.1 is not the failover.peers peer at 10.0.0.1")
+
+	stale := request
+	stale.Timestamp = time.Now().Add(-time.Hour).Unix()
+	assert.ErrorContains(t, manager.verifyRaftCommand(sign(stale)), "command timestamp outside allowed age of 15s")
+
+	tampered := request
+	tampered.Command.Op = raftOpRelease
+	assert.ErrorContains(t, manager.verifyRaftCommand(tampered), "signature does not match active identity")
+}
+
+func TestManager_RaftHolder(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	// the token is held by ip, so peers naming each other differently agree on its holder
+	assert.Equal(t, "192.168.1.100", manager.raftHolderIP("test-validator"))
+	assert.Equal(t, "192.168.1.101", manager.raftHolderIP("peer1"))
+	assert.Equal(t, "test-validator", manager.raftHolderName("192.168.1.100"))
+	assert.Equal(t, "peer2", manager.raftHolderName("192.168.1.102"))
+	assert.Equal(t, "10.0.0.1", manager.raftHolderName("10.0.0.1"))
+}
+
+func TestManager_StartRaftArbitration_RequiresHealthCheckTLS(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.cfg.Failover.Arbitration = config.Arbitration{Enabled: true, URL: "raft://127.0.0.1:19092", DataDir: t.TempDir()}
+	manager.cfg.Failover.Arbitration.SetDefaults()
+
+	_, err := manager.startRaftArbitration()
+	assert.EqualError(t, err, "failover.arbitration raft requires prometheus.health_check_tls to authenticate peers")
+}
@@ -0,0 +1,96 @@
+package ha
+
+import (
+	"fmt"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// Reload queues cfg to be applied between HA checks, so a reload never interrupts a failover in progress - see
+// applyReload for the settings it changes. A reload not yet applied is replaced.
+func (m *Manager) Reload(cfg *config.Config) {
+	select {
+	case <-m.reloads:
+		m.logger.Debug("replacing config reload not yet applied")
+	default:
+	}
+
+	select {
+	case m.reloads <- cfg:
+	default:
+		m.logger.Warn("config reload already queued - skipping")
+	}
+}
+
+// ReloadConfigFile reloads the config file or remote key the manager was started with, changing nothing if it fails to load
+// or validate
+func (m *Manager) ReloadConfigFile() error {
+	cfg, err := config.NewFromConfigFile(m.ctx, m.cfg.File)
+	if err != nil {
+		return fmt.Errorf("failed to reload config %s: %w", m.cfg.File, err)
+	}
+
+	m.Reload(cfg)
+	return nil
+}
+
+// watchConfigFile reloads the config file or remote key whenever it changes until the manager is stopped
+func (m *Manager) watchConfigFile() {
+	err := m.cfg.Watch(m.ctx, func(err error) {
+		if err != nil {
+			m.logger.Error("error watching config for changes", "file", m.cfg.File, "error", err)
+			return
+		}
+
+		m.logger.Info("config changed - reloading", "file", m.cfg.File)
+		if err := m.ReloadConfigFile(); err != nil {
+			m.logger.Error("failed to reload config - keeping the current config", "error", err)
+		}
+	})
+	if err != nil {
+		m.logger.Error("failed to watch config for changes", "file", m.cfg.File, "error", err)
+		return
+	}
+
+	m.logger.Info("watching config for changes", "file", m.cfg.File)
+}
+
+// applyReload applies the settings of cfg that can change without restarting - notification services, event
+// filters, severity overrides, routing, templates and links, failover.peers, and the health, leaderless samples,
+// takeover jitter and snapshot recovery thresholds. Other settings take effect on restart.
+func (m *Manager) applyReload(cfg *config.Config) {
+	// peers must not declare ourselves, as at startup
+	if cfg.Failover.Peers.HasIP(m.peerSelf.IP) {
+		m.logger.Error("failed to apply config reload - failover.peers must not reference ourselves", "ip", m.peerSelf.IP)
+		return
+	}
+
+	notifications := cfg.Notifications
+	notifications.Enabled = cfg.Notifications.HasAnyEnabled()
+	if err := m.notifyManager.Reload(&notifications); err != nil {
+		m.logger.Warn("notification settings not reloaded", "error", err)
+	}
+
+	// peers discovered in failover.peer_registry are kept
+	m.setStaticPeers(cfg.Failover.Peers)
+
+	health := cfg.Validator.Health.Checks
+	m.cfg.Validator.Health = cfg.Validator.Health
+	m.healthCheck.unhealthyThreshold, m.healthCheck.healthyThreshold = health.RPC.UnhealthyThreshold, health.RPC.HealthyThreshold
+	m.gossipCheck.unhealthyThreshold, m.gossipCheck.healthyThreshold = health.Gossip.UnhealthyThreshold, health.Gossip.HealthyThreshold
+	for _, peerCheck := range m.peerChecks {
+		peerCheck.unhealthyThreshold, peerCheck.healthyThreshold = health.Peers.UnhealthyThreshold, health.Peers.HealthyThreshold
+	}
+
+	m.cfg.Failover.LeaderlessSamplesThreshold = cfg.Failover.LeaderlessSamplesThreshold
+	m.cfg.Failover.TakeoverJitterDuration = cfg.Failover.TakeoverJitterDuration
+	m.cfg.Failover.SnapshotRecovery.MaxSlotsBehind = cfg.Failover.SnapshotRecovery.MaxSlotsBehind
+	m.cfg.Failover.SnapshotRecovery.SamplesThreshold = cfg.Failover.SnapshotRecovery.SamplesThreshold
+	m.cfg.Failover.SnapshotRecovery.CooldownDuration = cfg.Failover.SnapshotRecovery.CooldownDuration
+
+	m.logger.Info("config reloaded",
+		"peers", m.cfg.Failover.Peers.String(),
+		"leaderless_samples_threshold", m.cfg.Failover.LeaderlessSamplesThreshold,
+		"takeover_jitter_duration", m.cfg.Failover.TakeoverJitterDuration,
+	)
+}
@@ -0,0 +1,70 @@
+package ha
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Reload_ReplacesPending(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+
+	first, second := createTestConfig(), createTestConfig()
+	manager.Reload(first)
+	manager.Reload(second)
+
+	assert.Same(t, second, <-manager.reloads)
+	assert.Empty(t, manager.reloads)
+}
+
+func TestManager_ApplyReload(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	reloaded := createTestConfig()
+	reloaded.Failover.Peers = config.Peers{
+		"peer1": {IP: "192.168.1.101", Name: "peer1"},
+		"peer3": {IP: "192.168.1.103", Name: "peer3"},
+	}
+	reloaded.Failover.LeaderlessSamplesThreshold = 7
+	reloaded.Validator.Health.Checks.RPC = config.HealthThresholds{UnhealthyThreshold: 4, HealthyThreshold: 2}
+	reloaded.Failover.SnapshotRecovery.MaxSlotsBehind = 5000
+	// changes needing a restart are not applied
+	reloaded.Failover.PollIntervalDuration = 42
+
+	manager.applyReload(reloaded)
+
+	assert.ElementsMatch(t, []string{"peer1", "peer3", "test-validator"}, peerNames(manager.cfg.Failover.Peers))
+	assert.Equal(t, 2, manager.peerCount)
+	assert.Equal(t, 7, manager.leaderlessSamplesThreshold())
+	assert.Equal(t, 4, manager.healthCheck.unhealthyThreshold)
+	assert.Equal(t, 2, manager.healthCheck.healthyThreshold)
+	assert.Equal(t, uint64(5000), manager.cfg.Failover.SnapshotRecovery.MaxSlotsBehind)
+	assert.NotEqual(t, reloaded.Failover.PollIntervalDuration, manager.cfg.Failover.PollIntervalDuration)
+	// the reloaded config is left as loaded
+	assert.Len(t, reloaded.Failover.Peers, 2)
+}
+
+func TestManager_ApplyReload_RejectsSelfAsPeer(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	reloaded := createTestConfig()
+	reloaded.Failover.Peers["me"] = config.Peer{IP: "192.168.1.100", Name: "me"}
+	reloaded.Failover.LeaderlessSamplesThreshold = 7
+
+	manager.applyReload(reloaded)
+
+	assert.ElementsMatch(t, []string{"peer1", "peer2", "test-validator"}, peerNames(manager.cfg.Failover.Peers))
+	assert.Equal(t, 3, manager.cfg.Failover.LeaderlessSamplesThreshold)
+}
+
+func peerNames(peers config.Peers) []string {
+	names := make([]string, 0, len(peers))
+	for name := range peers {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,138 @@
+package ha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/cache"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// peerStatusTimeout is how long to wait for a peer's status before handing the active role over to it
+const peerStatusTimeout = 5 * time.Second
+
+// checkSchedules runs the failover.schedules that fired since the last HA check - none are run for the time before
+// the first check
+func (m *Manager) checkSchedules(now time.Time) {
+	if len(m.cfg.Failover.Schedules) == 0 {
+		return
+	}
+
+	lastCheckAt := m.lastScheduleCheckAt
+	m.lastScheduleCheckAt = now
+	if lastCheckAt.IsZero() {
+		return
+	}
+
+	for i := range m.cfg.Failover.Schedules {
+		if schedule := &m.cfg.Failover.Schedules[i]; schedule.FiredBetween(lastCheckAt, now) {
+			m.runSchedule(schedule, now)
+		}
+	}
+}
+
+// runSchedule hands the active role to the schedule's active peer if we are active and it is healthy, or holds back
+// our takeovers for its hold_duration if we are another passive peer, so the scheduled peer is the one to take over
+func (m *Manager) runSchedule(schedule *config.FailoverSchedule, now time.Time) {
+	logger := m.logger.With("schedule", schedule.Name, "active_peer", schedule.ActivePeer)
+
+	if schedule.ActivePeer == m.cfg.Validator.Name {
+		logger.Info("scheduled failover to us - taking over once the active peer hands over")
+		return
+	}
+
+	if !m.isSelfActive() {
+		m.handoverHoldUntil = now.Add(schedule.HoldDuration)
+		m.handoverHoldPeer = schedule.ActivePeer
+		logger.Info("scheduled failover to a peer - holding back our takeovers", "until", m.handoverHoldUntil.UTC())
+		return
+	}
+
+	if err := m.checkPeerReady(schedule.ActivePeer); err != nil {
+		logger.Warn("scheduled active peer is not ready - skipping scheduled failover", "error", err)
+		m.notifyScheduledFailover(schedule, "skipped", err.Error(), notify.SeverityWarning)
+		return
+	}
+
+	logger.Warn("scheduled failover - handing the active role over")
+	result := m.handOver(schedule.ActivePeer, schedule.HoldDuration)
+	if result.Error != "" {
+		logger.Error("scheduled failover failed", "error", result.Error)
+		m.notifyScheduledFailover(schedule, "failed", result.Error, notify.SeverityError)
+		return
+	}
+	m.notifyScheduledFailover(schedule, "handed_over", fmt.Sprintf("handed the active role over to %s", schedule.ActivePeer), notify.SeverityInfo)
+}
+
+// checkPeerReady returns an error unless the named peer is declared in gossip and its health check server reports it
+// healthy, passive and not draining - ready to take the active role over from us
+func (m *Manager) checkPeerReady(name string) error {
+	peer, ok := m.peers()[name]
+	if !ok {
+		return fmt.Errorf("peer %s is not in failover.peers", name)
+	}
+	if !m.isPeerInGossip(name) {
+		return fmt.Errorf("peer %s is not in gossip", name)
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, peerStatusTimeout)
+	defer cancel()
+
+	url := m.cfg.Prometheus.PeerHealthCheckURL(peer.IP, "/status")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create status request: %w", err)
+	}
+	resp, err := m.peerHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get peer %s status: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer %s status returned %d", name, resp.StatusCode)
+	}
+
+	var state cache.State
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return fmt.Errorf("failed to decode peer %s status: %w", name, err)
+	}
+	switch {
+	case state.Status != constants.StatusHealthy:
+		return fmt.Errorf("peer %s is %s", name, state.Status)
+	case state.Role != constants.RoleNamePassive:
+		return fmt.Errorf("peer %s is %s, not passive", name, state.Role)
+	case state.Draining:
+		return fmt.Errorf("peer %s is draining", name)
+	}
+	return nil
+}
+
+// notifyScheduledFailover tells everyone how a scheduled failover went
+func (m *Manager) notifyScheduledFailover(schedule *config.FailoverSchedule, result string, message string, severity notify.Severity) {
+	if m.notifyManager == nil {
+		return
+	}
+
+	details := map[string]string{
+		"schedule":    schedule.Name,
+		"active_peer": schedule.ActivePeer,
+		"result":      result,
+	}
+	if result != "skipped" {
+		details["failover_id"] = m.failoverID
+	}
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventScheduledFailover,
+		Severity:      severity,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message:       fmt.Sprintf("Scheduled failover %s to %s %s: %s", schedule.Name, schedule.ActivePeer, result, message),
+		Details:       details,
+	})
+}
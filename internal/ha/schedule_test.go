@@ -0,0 +1,37 @@
+package ha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_CheckSchedules(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.Schedules = config.FailoverSchedules{
+		{Name: "weekly", Cron: "0 2 * * tue", Timezone: "UTC", ActivePeer: "peer1", HoldDuration: 5 * time.Minute},
+		{Name: "failback", Cron: "0 2 * * wed", Timezone: "UTC", ActivePeer: "test-validator", HoldDuration: 5 * time.Minute},
+	}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+
+	// nothing runs on the first check
+	tuesday := time.Date(2025, 1, 7, 2, 0, 0, 0, time.UTC)
+	manager.checkSchedules(tuesday)
+	assert.True(t, manager.handoverHoldUntil.IsZero())
+
+	// passive peers hold back their takeovers when another peer is scheduled to be active
+	manager.lastScheduleCheckAt = tuesday.Add(-5 * time.Second)
+	manager.checkSchedules(tuesday)
+	assert.Equal(t, tuesday.Add(5*time.Minute), manager.handoverHoldUntil)
+	assert.Equal(t, "peer1", manager.handoverHoldPeer)
+
+	// and not when they are scheduled to be active themselves
+	manager.handoverHoldUntil = time.Time{}
+	wednesday := tuesday.Add(24 * time.Hour)
+	manager.lastScheduleCheckAt = wednesday.Add(-5 * time.Second)
+	manager.checkSchedules(wednesday)
+	assert.True(t, manager.handoverHoldUntil.IsZero())
+	assert.Equal(t, wednesday, manager.lastScheduleCheckAt)
+}
@@ -0,0 +1,73 @@
+package ha
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// checkSkipRate gets the active identity's block production this epoch, sending skip_rate_high from the active node
+// once its skip rate rises above validator.skip_rate.threshold_percent - once per epoch unless it recovers
+func (m *Manager) checkSkipRate() {
+	cfg := m.cfg.Validator.SkipRate
+	if !cfg.Enabled {
+		return
+	}
+
+	production, err := m.clusterRPC.GetBlockProduction(m.ctx, m.cfg.Validator.Identities.ActiveKeyPair.PublicKey())
+	if err != nil {
+		m.logger.Warn("failed to get block production", "error", err)
+		return
+	}
+
+	// a new epoch starts the skip rate afresh
+	if m.blockProduction != nil && m.blockProduction.FirstSlot != production.FirstSlot {
+		m.skipRateCheck = newHealthyHysteresis(1, 1)
+	}
+	m.blockProduction = &production
+
+	if production.LeaderSlots < cfg.MinLeaderSlots {
+		return
+	}
+
+	skipRatePercent := production.SkipRate() * 100
+	withinThreshold, changed := m.skipRateCheck.observe(skipRatePercent <= cfg.ThresholdPercent)
+	if !changed {
+		return
+	}
+
+	logger := m.logger.With(
+		"skip_rate_percent", strconv.FormatFloat(skipRatePercent, 'f', 1, 64),
+		"leader_slots", production.LeaderSlots,
+		"blocks_produced", production.BlocksProduced,
+	)
+	if withinThreshold {
+		logger.Info("skip rate back within validator.skip_rate.threshold_percent")
+		return
+	}
+
+	logger.Warn("skip rate above validator.skip_rate.threshold_percent", "threshold_percent", cfg.ThresholdPercent)
+
+	// the active identity's leader slots are only ours to report while we are active
+	if m.notifyManager == nil || !m.isSelfActive() {
+		return
+	}
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventSkipRateHigh,
+		Severity:      notify.SeverityWarning,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message: fmt.Sprintf("Skipped %d of %d leader slots this epoch (%.1f%%) - above the %.1f%% threshold",
+			production.LeaderSlots-production.BlocksProduced, production.LeaderSlots, skipRatePercent, cfg.ThresholdPercent),
+		Details: map[string]string{
+			"skip_rate_percent": strconv.FormatFloat(skipRatePercent, 'f', 1, 64),
+			"threshold_percent": strconv.FormatFloat(cfg.ThresholdPercent, 'f', 1, 64),
+			"leader_slots":      strconv.FormatUint(production.LeaderSlots, 10),
+			"blocks_produced":   strconv.FormatUint(production.BlocksProduced, 10),
+			"first_slot":        strconv.FormatUint(production.FirstSlot, 10),
+			"last_slot":         strconv.FormatUint(production.LastSlot, 10),
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_CheckSkipRate(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Validator.SkipRate.Enabled = true
+	cfg.Validator.SkipRate.ThresholdPercent = 10
+	cfg.Validator.SkipRate.MinLeaderSlots = 8
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+
+	// block production of the active identity from firstSlot
+	identity := cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
+	firstSlot, leaderSlots, blocksProduced := 1000, 4, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID any `json:"id"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := map[string]any{
+			"context": map[string]any{"slot": firstSlot + 100},
+			"value": map[string]any{
+				"byIdentity": map[string]any{identity: []int{leaderSlots, blocksProduced}},
+				"range":      map[string]any{"firstSlot": firstSlot, "lastSlot": firstSlot + 100},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result}))
+	}))
+	defer server.Close()
+	manager.clusterRPC = rpc.NewClient("test", server.URL)
+
+	// too few leader slots to compare
+	manager.checkSkipRate()
+	require.NotNil(t, manager.blockProduction)
+	assert.Equal(t, uint64(4), manager.blockProduction.LeaderSlots)
+	assert.True(t, manager.skipRateCheck.healthy)
+
+	// 25% skipped is above the threshold
+	leaderSlots, blocksProduced = 8, 6
+	manager.checkSkipRate()
+	assert.False(t, manager.skipRateCheck.healthy)
+
+	// a new epoch starts afresh
+	firstSlot, leaderSlots, blocksProduced = 2000, 4, 0
+	manager.checkSkipRate()
+	assert.True(t, manager.skipRateCheck.healthy)
+
+	// back within the threshold
+	firstSlot, leaderSlots, blocksProduced = 1000, 8, 6
+	manager.checkSkipRate()
+	leaderSlots, blocksProduced = 20, 19
+	manager.checkSkipRate()
+	assert.True(t, manager.skipRateCheck.healthy)
+}
@@ -0,0 +1,60 @@
+package ha
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// slackActionsPath is the health server path Slack posts alert button presses to
+const slackActionsPath = "/notifications/slack/actions"
+
+// slackActionsEnabled returns whether Slack alert buttons should be handled
+func (m *Manager) slackActionsEnabled() bool {
+	notifications := m.cfg.Notifications
+	return m.notifyManager != nil && notifications.Slack.Enabled && notifications.Slack.Interactive.Enabled
+}
+
+// newSlackActionsHandler creates the handler for Slack alert button presses
+func (m *Manager) newSlackActionsHandler() *notify.SlackActionsHandler {
+	interactive := m.cfg.Notifications.Slack.Interactive
+
+	handlers := map[string]notify.SlackActionFunc{
+		notify.SlackActionAcknowledge: m.slackAcknowledge,
+		notify.SlackActionSilence:     m.slackSilence,
+		notify.SlackActionFailover:    m.slackFailover,
+	}
+	actions := make(map[string]notify.SlackActionFunc, len(interactive.Actions))
+	for _, action := range interactive.Actions {
+		actions[action] = handlers[action]
+	}
+
+	return notify.NewSlackActionsHandler(notify.SlackActionsOptions{
+		SigningSecret: interactive.SigningSecret,
+		ValidatorName: m.cfg.Validator.Name,
+		Actions:       actions,
+		Logger:        log.WithPrefix(fmt.Sprintf("[%s slack_actions]", m.logPrefix)),
+	})
+}
+
+// slackAcknowledge records that an operator has seen an alert
+func (m *Manager) slackAcknowledge(user string) string {
+	m.logger.Info("alert acknowledged from slack", "user_id", user)
+	return fmt.Sprintf("Alert for %s acknowledged by <@%s>", m.cfg.Validator.Name, user)
+}
+
+// slackSilence holds back non-critical notifications for the configured silence duration
+func (m *Manager) slackSilence(user string) string {
+	duration := m.cfg.Notifications.Slack.Interactive.SilenceDuration
+	m.notifyManager.Silence(duration)
+	m.logger.Info("notifications silenced from slack", "user_id", user, "duration", duration)
+	return fmt.Sprintf("Notifications from %s silenced by <@%s> until %s", m.cfg.Validator.Name, user, time.Now().Add(duration).UTC().Format(time.RFC3339))
+}
+
+// slackFailover confirms a takeover held back by a failover policy with auto_takeover disabled or the failover cooldown
+func (m *Manager) slackFailover(user string) string {
+	m.logger.Info("takeover confirmation requested from slack", "user_id", user)
+	return m.confirmTakeover()
+}
@@ -0,0 +1,151 @@
+package ha
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// checkSnapshotRecovery samples how far the local validator trails the cluster and, when a passive node
+// stays more than failover.snapshot_recovery.max_slots_behind behind for samples_threshold consecutive
+// samples, runs failover.snapshot_recovery.command in the background so the standby can catch up again
+func (m *Manager) checkSnapshotRecovery() {
+	if !m.cfg.Failover.SnapshotRecovery.Enabled || m.snapshotRecoveryRunning.Load() {
+		return
+	}
+
+	// only standbys self-heal - an active node falling behind is a different problem entirely
+	if !m.isSelfPassive() {
+		m.snapshotLaggingSamples = 0
+		return
+	}
+
+	clusterSlot, err := m.clusterRPC.GetSlot(m.ctx)
+	if err != nil {
+		m.logger.Debug("failed to get cluster slot for snapshot recovery check", "error", err)
+		return
+	}
+
+	localSlot, err := m.localRPC.GetSlot(m.ctx)
+	if err != nil {
+		m.logger.Debug("failed to get local slot for snapshot recovery check", "error", err)
+		return
+	}
+
+	var slotsBehind uint64
+	if clusterSlot > localSlot {
+		slotsBehind = clusterSlot - localSlot
+	}
+
+	if !m.recordSlotsBehind(slotsBehind, time.Now()) {
+		return
+	}
+
+	m.snapshotRecoveryRunning.Store(true)
+	go m.runSnapshotRecovery(slotsBehind)
+}
+
+// recordSlotsBehind records a catchup distance sample and returns true if snapshot recovery should be triggered
+func (m *Manager) recordSlotsBehind(slotsBehind uint64, now time.Time) bool {
+	cfg := m.cfg.Failover.SnapshotRecovery
+	m.slotsBehind = slotsBehind
+
+	if slotsBehind <= cfg.MaxSlotsBehind {
+		m.snapshotLaggingSamples = 0
+		return false
+	}
+
+	m.snapshotLaggingSamples++
+	m.logger.Warn("we are lagging behind the cluster",
+		"slots_behind", slotsBehind,
+		"max_slots_behind", cfg.MaxSlotsBehind,
+		"lagging_samples", m.snapshotLaggingSamples,
+		"samples_threshold", cfg.SamplesThreshold,
+	)
+
+	if m.snapshotLaggingSamples < cfg.SamplesThreshold {
+		return false
+	}
+
+	if !m.lastSnapshotRecoveryAt.IsZero() && now.Sub(m.lastSnapshotRecoveryAt) < cfg.CooldownDuration {
+		m.logger.Debug("snapshot recovery in cooldown", "last_run", m.lastSnapshotRecoveryAt, "cooldown", cfg.CooldownDuration)
+		return false
+	}
+
+	m.snapshotLaggingSamples = 0
+	m.lastSnapshotRecoveryAt = now
+	return true
+}
+
+// runSnapshotRecovery runs failover.snapshot_recovery.command and notifies on its progress
+func (m *Manager) runSnapshotRecovery(slotsBehind uint64) {
+	defer m.snapshotRecoveryRunning.Store(false)
+
+	cfg := m.cfg.Failover.SnapshotRecovery
+	startedAt := time.Now()
+	m.logger.Warn("starting snapshot recovery", "slots_behind", slotsBehind, "command", cfg.Command)
+	m.notifySnapshotRecovery(notify.EventSnapshotRecoveryStarted, notify.SeverityWarning,
+		"Passive node is lagging behind the cluster - running snapshot recovery command",
+		map[string]string{
+			"slots_behind": strconv.FormatUint(slotsBehind, 10),
+			"command":      cfg.Command,
+		},
+	)
+
+	err := command.Run(m.ctx, command.RunOptions{
+		Name:            "snapshot-recovery",
+		Command:         cfg.Command,
+		Args:            cfg.Args,
+		Env:             cfg.Env,
+		DryRun:          m.cfg.Failover.DryRun,
+		StreamOutput:    true,
+		Resources:       cfg.Resources,
+		ClearEnv:        cfg.ClearEnv,
+		PassEnv:         cfg.PassEnv,
+		AllowedCommands: m.cfg.Failover.CommandAllowlist.AllowedCommands(),
+		LoggerPrefix:    m.logPrefix,
+		LoggerArgs: []any{
+			"slots_behind", slotsBehind,
+		},
+	})
+	duration := time.Since(startedAt).Round(time.Second)
+
+	if err != nil {
+		m.logger.Error("snapshot recovery command failed", "error", err, "duration", duration)
+		m.notifySnapshotRecovery(notify.EventSnapshotRecoveryFailed, notify.SeverityError,
+			"Snapshot recovery command failed - passive node may remain ineligible for takeover",
+			map[string]string{
+				"error":    err.Error(),
+				"duration": duration.String(),
+			},
+		)
+		return
+	}
+
+	m.logger.Info("snapshot recovery command completed", "duration", duration)
+	m.notifySnapshotRecovery(notify.EventSnapshotRecoveryCompleted, notify.SeverityInfo,
+		"Snapshot recovery command completed",
+		map[string]string{
+			"duration": duration.String(),
+		},
+	)
+}
+
+// notifySnapshotRecovery sends a snapshot recovery progress event
+func (m *Manager) notifySnapshotRecovery(eventType notify.EventType, severity notify.Severity, message string, details map[string]string) {
+	if m.notifyManager == nil {
+		return
+	}
+
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          eventType,
+		Severity:      severity,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message:       message,
+		Details:       details,
+	})
+}
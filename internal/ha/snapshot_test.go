@@ -0,0 +1,46 @@
+package ha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_RecordSlotsBehind(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.SnapshotRecovery = config.SnapshotRecovery{
+		Enabled:          true,
+		Command:          "echo 'fetch snapshot'",
+		MaxSlotsBehind:   100,
+		SamplesThreshold: 3,
+		CooldownDuration: time.Hour,
+	}
+
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	now := time.Now()
+
+	// within tolerance never triggers
+	assert.False(t, manager.recordSlotsBehind(100, now))
+	assert.Equal(t, 0, manager.snapshotLaggingSamples)
+
+	// lagging triggers only once the samples threshold is reached
+	assert.False(t, manager.recordSlotsBehind(500, now))
+	assert.False(t, manager.recordSlotsBehind(500, now))
+	assert.True(t, manager.recordSlotsBehind(500, now))
+	assert.Equal(t, uint64(500), manager.slotsBehind)
+
+	// catching up resets the sample count
+	assert.False(t, manager.recordSlotsBehind(500, now))
+	assert.False(t, manager.recordSlotsBehind(10, now))
+	assert.Equal(t, 0, manager.snapshotLaggingSamples)
+
+	// lagging again within the cooldown does not trigger
+	for i := 0; i < 3; i++ {
+		assert.False(t, manager.recordSlotsBehind(500, now.Add(time.Minute)))
+	}
+
+	// once the cooldown has passed it triggers again
+	assert.True(t, manager.recordSlotsBehind(500, now.Add(2*time.Hour)))
+}
@@ -0,0 +1,60 @@
+package ha
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// startupDetails returns the startup event details - a summary of the resolved config so the first message
+// after a deploy doubles as a sanity check of it
+func (m *Manager) startupDetails() map[string]string {
+	peers := m.peers()
+	rankedIPs := peers.GetRankedIPs()
+
+	return map[string]string{
+		"client":        m.clientInfo.String(),
+		"rpc_hosts":     strings.Join(rpcHosts(m.cfg.Cluster.RPCURLs), ", "),
+		"peers":         strings.Join(rankedPeers(peers, rankedIPs), ", "),
+		"self_rank":     strconv.Itoa(m.selfPeerRank()),
+		"poll_interval": m.cfg.Failover.PollIntervalDuration.String(),
+		"dry_run":       strconv.FormatBool(m.cfg.Failover.DryRun),
+	}
+}
+
+// rankedPeers returns the peers as "#<rank> <name> (<ip>)", best ranked first
+func rankedPeers(peers config.Peers, rankedIPs map[string]int) []string {
+	sorted := make([]config.Peer, 0, len(peers))
+	for name, peer := range peers {
+		peer.Name = name
+		sorted = append(sorted, peer)
+	}
+	slices.SortFunc(sorted, func(a, b config.Peer) int {
+		return cmp.Or(cmp.Compare(rankedIPs[a.IP], rankedIPs[b.IP]), cmp.Compare(a.Name, b.Name))
+	})
+
+	formatted := make([]string, 0, len(sorted))
+	for _, peer := range sorted {
+		formatted = append(formatted, fmt.Sprintf("#%d %s (%s)", rankedIPs[peer.IP], peer.Name, peer.IP))
+	}
+	return formatted
+}
+
+// rpcHosts returns the host of each rpc url - paths and queries are dropped as they often carry API keys
+func rpcHosts(rpcURLs []string) []string {
+	hosts := make([]string, 0, len(rpcURLs))
+	for _, rpcURL := range rpcURLs {
+		parsed, err := url.Parse(rpcURL)
+		if err != nil || parsed.Host == "" {
+			hosts = append(hosts, "invalid")
+			continue
+		}
+		hosts = append(hosts, parsed.Host)
+	}
+	return hosts
+}
@@ -0,0 +1,30 @@
+package ha
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_StartupDetails(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Cluster.RPCURLs = []string{"https://api.mainnet-beta.solana.com", "https://rpc.example.com/secret-key?api-key=x"}
+	cfg.Failover.DryRun = true
+	cfg.Failover.Peers["peer0"] = config.Peer{IP: "192.168.1.99", Name: "peer0"}
+
+	manager := NewManager(NewManagerOptions{
+		Cfg:             cfg,
+		GetPublicIPFunc: mockPublicIPFunc,
+	})
+	require.NoError(t, manager.initialize())
+
+	details := manager.startupDetails()
+	assert.Equal(t, "#1 test-validator (192.168.1.100), #2 peer1 (192.168.1.101), #3 peer2 (192.168.1.102), #4 peer0 (192.168.1.99)", details["peers"])
+	assert.Equal(t, "1", details["self_rank"])
+	assert.Equal(t, "api.mainnet-beta.solana.com, rpc.example.com", details["rpc_hosts"])
+	assert.Equal(t, cfg.Failover.PollIntervalDuration.String(), details["poll_interval"])
+	assert.Equal(t, "true", details["dry_run"])
+	assert.Contains(t, details, "client")
+}
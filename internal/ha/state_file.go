@@ -0,0 +1,158 @@
+package ha
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+)
+
+// persistedState is the state failover.state_file keeps across restarts, so a restarted daemon still knows it is
+// mid-cooldown, draining, in maintenance mode or has takeovers held back
+type persistedState struct {
+	// Role is the last active or passive role seen, and RoleChangedAt when it last changed between them
+	Role          string      `json:"role,omitempty"`
+	RoleChangedAt time.Time   `json:"role_changed_at,omitzero"`
+	FailoverID    string      `json:"failover_id,omitempty"`
+	TakeoverHeld  bool        `json:"takeover_held,omitempty"`
+	Drain         DrainStatus `json:"drain"`
+	// DrainSetMaintenance is true if draining turned notification maintenance mode on, so ending it turns it off
+	DrainSetMaintenance bool `json:"drain_set_maintenance,omitempty"`
+	// Maintenance is true while notification maintenance mode is turned on, not counting the maintenance file
+	Maintenance             bool        `json:"maintenance,omitempty"`
+	CircuitBreakerTakeovers []time.Time `json:"circuit_breaker_takeovers,omitempty"`
+	CircuitBreakerTrippedAt time.Time   `json:"circuit_breaker_tripped_at,omitzero"`
+}
+
+// currentState returns the state to persist to failover.state_file
+func (m *Manager) currentState() persistedState {
+	state := persistedState{
+		RoleChangedAt: m.lastRoleChangeAt(),
+		FailoverID:    m.failoverID,
+		TakeoverHeld:  m.takeoverHeld,
+	}
+	state.Role = m.lastKnownRole()
+
+	m.drain.mu.Lock()
+	state.Drain = m.drain.status
+	state.DrainSetMaintenance = m.drain.setMaintenance
+	m.drain.mu.Unlock()
+
+	if m.notifyManager != nil {
+		state.Maintenance = m.notifyManager.Maintenance()
+	}
+
+	breaker := m.circuitBreaker.status(m.cfg.Failover.CircuitBreaker.Enabled)
+	state.CircuitBreakerTakeovers = breaker.Takeovers
+	if breaker.TrippedAt != nil {
+		state.CircuitBreakerTrippedAt = *breaker.TrippedAt
+	}
+	return state
+}
+
+// lastKnownRole returns the last active or passive role seen, falling back to the role restored from
+// failover.state_file - empty if there is none
+func (m *Manager) lastKnownRole() string {
+	for i := len(m.roleHistory) - 1; i >= 0; i-- {
+		if role := m.roleHistory[i].Role; role == constants.RoleNameActive || role == constants.RoleNamePassive {
+			return role
+		}
+	}
+	return m.restoredRole
+}
+
+// saveState writes the current state to failover.state_file if it changed since it was last written
+func (m *Manager) saveState() {
+	path := m.cfg.Failover.StateFile
+	if path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(m.currentState(), "", "  ")
+	if err != nil {
+		m.logger.Error("failed to encode state", "error", err)
+		return
+	}
+	if bytes.Equal(data, m.savedState) {
+		return
+	}
+
+	if err := writeStateFile(path, data); err != nil {
+		m.logger.Error("failed to write state file", "path", path, "error", err)
+		return
+	}
+	m.savedState = data
+}
+
+// writeStateFile replaces the state file at path with data, through a temporary file so it is never left partly
+// written
+func writeStateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state file directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// restoreState restores the state written to failover.state_file before a restart - a missing file is a first
+// start, and an unreadable one is logged and ignored so it never stops the daemon starting
+func (m *Manager) restoreState() {
+	path := m.cfg.Failover.StateFile
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		m.logger.Debug("no state file to restore", "path", path)
+		return
+	}
+	if err != nil {
+		m.logger.Warn("failed to read state file - starting afresh", "path", path, "error", err)
+		return
+	}
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		m.logger.Warn("failed to decode state file - starting afresh", "path", path, "error", err)
+		return
+	}
+
+	m.restoredRole = state.Role
+	m.restoredRoleChangedAt = state.RoleChangedAt
+	m.failoverID = state.FailoverID
+	m.takeoverHeld = state.TakeoverHeld
+
+	m.drain.mu.Lock()
+	m.drain.status = state.Drain
+	m.drain.setMaintenance = state.DrainSetMaintenance
+	m.drain.mu.Unlock()
+
+	if state.Maintenance && m.notifyManager != nil {
+		m.notifyManager.SetMaintenance(true)
+	}
+
+	m.circuitBreaker.mu.Lock()
+	m.circuitBreaker.takeovers = state.CircuitBreakerTakeovers
+	m.circuitBreaker.trippedAt = state.CircuitBreakerTrippedAt
+	m.circuitBreaker.mu.Unlock()
+
+	m.savedState = data
+	m.logger.Info("restored state from before restart",
+		"path", path,
+		"role", state.Role,
+		"role_changed_at", state.RoleChangedAt,
+		"failover_id", state.FailoverID,
+		"takeover_held", state.TakeoverHeld,
+		"draining", state.Drain.Draining,
+		"maintenance", state.Maintenance,
+		"circuit_breaker_tripped", !state.CircuitBreakerTrippedAt.IsZero(),
+	)
+}
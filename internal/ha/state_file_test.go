@@ -0,0 +1,70 @@
+package ha
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SaveAndRestoreState(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.StateFile = filepath.Join(t.TempDir(), "state", "state.json")
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	before := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	before.recordRole("active", startedAt)
+	before.recordRole("passive", startedAt.Add(time.Hour))
+	before.failoverID = "abc"
+	before.takeoverHeld = true
+	before.setDraining(true, "test")
+	before.circuitBreaker.record(startedAt.Add(time.Hour))
+	before.saveState()
+	require.FileExists(t, cfg.Failover.StateFile)
+
+	// unchanged state is not written again
+	require.NoError(t, os.Remove(cfg.Failover.StateFile))
+	before.saveState()
+	assert.NoFileExists(t, cfg.Failover.StateFile)
+	before.takeoverHeld = false
+	before.saveState()
+	require.FileExists(t, cfg.Failover.StateFile)
+	before.takeoverHeld = true
+	before.saveState()
+
+	after := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	after.restoreState()
+	assert.Equal(t, "passive", after.lastKnownRole())
+	assert.Equal(t, startedAt.Add(time.Hour), after.lastRoleChangeAt())
+	assert.Equal(t, "abc", after.failoverID)
+	assert.True(t, after.takeoverHeld)
+	assert.True(t, after.drain.draining())
+	assert.Equal(t, []time.Time{startedAt.Add(time.Hour)}, after.circuitBreaker.status(true).Takeovers)
+
+	// still passive after the restart is not a change
+	after.recordRole("passive", startedAt.Add(2*time.Hour))
+	assert.Equal(t, startedAt.Add(time.Hour), after.lastRoleChangeAt())
+
+	// but coming back in another role is
+	after.recordRole("active", startedAt.Add(3*time.Hour))
+	assert.Equal(t, startedAt.Add(3*time.Hour), after.lastRoleChangeAt())
+}
+
+func TestManager_RestoreState_Unreadable(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.StateFile = filepath.Join(t.TempDir(), "state.json")
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+
+	// missing on first start
+	manager.restoreState()
+	assert.Empty(t, manager.lastKnownRole())
+
+	// corrupt files are ignored
+	require.NoError(t, os.WriteFile(cfg.Failover.StateFile, []byte("{"), 0o600))
+	manager.restoreState()
+	assert.Empty(t, manager.lastKnownRole())
+	assert.False(t, manager.takeoverHeld)
+}
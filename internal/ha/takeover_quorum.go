@@ -0,0 +1,202 @@
+package ha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+)
+
+// takeoverVotePath is the health server path peers and witnesses vote on our takeovers on
+const takeoverVotePath = "/peer/takeover-vote"
+
+// takeoverVoteMaxAge is the maximum age of a received vote request before it is rejected as a replay
+const takeoverVoteMaxAge = 30 * time.Second
+
+// takeoverVoteRequest asks a peer or witness whether it agrees the active peer is down
+type takeoverVoteRequest struct {
+	Name      string `json:"name"`
+	IP        string `json:"ip"`
+	Timestamp int64  `json:"timestamp"`
+	// Signature is a base58 signature of the request payload by the shared active identity, proving the sender is
+	// an HA peer holding the active keypair
+	Signature string `json:"signature"`
+}
+
+// takeoverVote is a peer's or witness's answer to a takeover vote request
+type takeoverVote struct {
+	Name   string `json:"name"`
+	Agree  bool   `json:"agree"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// payload returns the bytes that are signed for the request
+func (v *takeoverVoteRequest) payload() []byte {
+	return []byte(fmt.Sprintf("takeover-vote|%s|%s|%d", v.Name, v.IP, v.Timestamp))
+}
+
+// takeoverQuorumReached asks failover.peers and failover.takeover_quorum.witnesses whether they agree the active
+// peer is down, returning true if at least quorum of them do. Voters that are unreachable or don't answer within
+// vote_timeout_duration don't agree, so a passive peer cut off from the network never reaches quorum.
+func (m *Manager) takeoverQuorumReached() bool {
+	quorum := m.cfg.Failover.TakeoverQuorum
+	request := takeoverVoteRequest{Name: m.peerSelf.Name, IP: m.peerSelf.IP, Timestamp: time.Now().UTC().Unix()}
+	signature, err := m.cfg.Validator.Identities.ActiveKeyPair.Sign(request.payload())
+	if err != nil {
+		m.logger.Error("failed to sign takeover vote request - unable to reach takeover quorum", "error", err)
+		return false
+	}
+	request.Signature = signature.String()
+	body, err := json.Marshal(request)
+	if err != nil {
+		m.logger.Error("failed to marshal takeover vote request - unable to reach takeover quorum", "error", err)
+		return false
+	}
+
+	// voters by name, peers reached on their health check port and witnesses on their URL
+	voters := map[string]string{}
+	for name, peer := range m.peers() {
+		if peer.IP != m.peerSelf.IP {
+			voters[name] = m.cfg.Prometheus.PeerHealthCheckURL(peer.IP, takeoverVotePath)
+		}
+	}
+	for _, witness := range quorum.Witnesses {
+		voters[witness] = strings.TrimSuffix(witness, "/") + takeoverVotePath
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, quorum.VoteTimeoutDuration)
+	defer cancel()
+
+	m.logger.Info("asking peers and witnesses to agree the active peer is down", "voters", len(voters), "quorum", quorum.Quorum)
+
+	var wg sync.WaitGroup
+	votes := make(chan takeoverVote, len(voters))
+	for voter, url := range voters {
+		wg.Add(1)
+		go func(voter, url string) {
+			defer wg.Done()
+			vote, err := m.sendTakeoverVoteRequest(ctx, url, body)
+			if err != nil {
+				m.logger.Warn("voter did not answer takeover vote request", "voter", voter, "error", err)
+				return
+			}
+			m.logger.Info("takeover vote", "voter", voter, "name", vote.Name, "agree", vote.Agree, "reason", vote.Reason)
+			votes <- vote
+		}(voter, url)
+	}
+	wg.Wait()
+	close(votes)
+
+	agreed := 0
+	for vote := range votes {
+		if vote.Agree {
+			agreed++
+		}
+	}
+
+	if agreed < quorum.Quorum {
+		m.logger.Error("takeover quorum not reached - not taking over", "agreed", agreed, "quorum", quorum.Quorum, "voters", len(voters))
+		return false
+	}
+	m.logger.Info("takeover quorum reached", "agreed", agreed, "quorum", quorum.Quorum, "voters", len(voters))
+	return true
+}
+
+// sendTakeoverVoteRequest posts the vote request to a peer's or witness's health check server
+func (m *Manager) sendTakeoverVoteRequest(ctx context.Context, url string, body []byte) (vote takeoverVote, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return vote, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.peerHTTPClient().Do(req)
+	if err != nil {
+		return vote, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return vote, fmt.Errorf("voter returned status %d", resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&vote)
+	return vote, err
+}
+
+// handleTakeoverVote answers a peer asking whether we agree the active peer is down
+func (m *Manager) handleTakeoverVote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request takeoverVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid takeover vote request", http.StatusBadRequest)
+		return
+	}
+
+	if err := m.verifyTakeoverVoteRequest(request); err != nil {
+		m.logger.Warn("rejected takeover vote request", "name", request.Name, "ip", request.IP, "error", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	vote := takeoverVote{Name: m.cfg.Validator.Name}
+	vote.Agree, vote.Reason = m.takeoverVote()
+	m.logger.Info("voted on peer takeover",
+		"peer_name", request.Name,
+		"peer_ip", request.IP,
+		"agree", vote.Agree,
+		"reason", vote.Reason,
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vote)
+}
+
+// verifyTakeoverVoteRequest checks the request comes from a configured peer, is recent and is signed by the active
+// identity
+func (m *Manager) verifyTakeoverVoteRequest(request takeoverVoteRequest) error {
+	peers := m.peers()
+	if !peers.HasIP(request.IP) {
+		return fmt.Errorf("unknown peer ip %s", request.IP)
+	}
+
+	if age := time.Since(time.Unix(request.Timestamp, 0)); age < -takeoverVoteMaxAge || age > takeoverVoteMaxAge {
+		return fmt.Errorf("vote request timestamp outside allowed age of %s", takeoverVoteMaxAge)
+	}
+
+	signature, err := solanago.SignatureFromBase58(request.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if !m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().Verify(request.payload(), signature) {
+		return fmt.Errorf("signature does not match active identity")
+	}
+
+	return nil
+}
+
+// takeoverVote decides whether we agree the active peer is down - only once we have seen it missing ourselves
+func (m *Manager) takeoverVote() (agree bool, reason string) {
+	state := m.cache.GetState()
+
+	switch {
+	case state.Role == constants.RoleNameActive:
+		return false, "voter is active"
+	case state.LeaderlessSamples == 0 && state.ActivePeerName != "":
+		return false, fmt.Sprintf("voter still sees %s active and voting", state.ActivePeerName)
+	case state.LeaderlessSamples == 0:
+		return false, "voter has not seen the active peer missing"
+	}
+	return true, fmt.Sprintf("voter has seen no active peer for %d samples", state.LeaderlessSamples)
+}
@@ -0,0 +1,139 @@
+package ha
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/cache"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedTestVoteRequest(t *testing.T, manager *Manager, ip string, timestamp time.Time) takeoverVoteRequest {
+	request := takeoverVoteRequest{Name: "peer", IP: ip, Timestamp: timestamp.Unix()}
+	signature, err := manager.cfg.Validator.Identities.ActiveKeyPair.Sign(request.payload())
+	require.NoError(t, err)
+	request.Signature = signature.String()
+	return request
+}
+
+func postTestVoteRequest(t *testing.T, manager *Manager, request takeoverVoteRequest) (int, takeoverVote) {
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	manager.handleTakeoverVote(recorder, httptest.NewRequest(http.MethodPost, takeoverVotePath, bytes.NewReader(body)))
+
+	var vote takeoverVote
+	if recorder.Code == http.StatusOK {
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&vote))
+	}
+	return recorder.Code, vote
+}
+
+func TestManager_HandleTakeoverVote(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	// we have seen the active peer missing - agree
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNamePassive, LeaderlessSamples: 3})
+	code, vote := postTestVoteRequest(t, manager, signedTestVoteRequest(t, manager, "192.168.1.101", time.Now()))
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, vote.Agree)
+	assert.Equal(t, "test-validator", vote.Name)
+
+	// active peer still seen voting - disagree
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNamePassive, ActivePeerName: "peer2"})
+	_, vote = postTestVoteRequest(t, manager, signedTestVoteRequest(t, manager, "192.168.1.101", time.Now()))
+	assert.False(t, vote.Agree)
+	assert.Contains(t, vote.Reason, "peer2")
+
+	// we are active - disagree
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNameActive, LeaderlessSamples: 3})
+	_, vote = postTestVoteRequest(t, manager, signedTestVoteRequest(t, manager, "192.168.1.101", time.Now()))
+	assert.False(t, vote.Agree)
+
+	// unknown, stale and tampered requests - forbidden
+	code, _ = postTestVoteRequest(t, manager, signedTestVoteRequest(t, manager, "10.0.0.1", time.Now()))
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = postTestVoteRequest(t, manager, signedTestVoteRequest(t, manager, "192.168.1.101", time.Now().Add(-time.Hour)))
+	assert.Equal(t, http.StatusForbidden, code)
+	request := signedTestVoteRequest(t, manager, "192.168.1.101", time.Now())
+	request.Timestamp++
+	code, _ = postTestVoteRequest(t, manager, request)
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+// TestManager_HandleTakeoverVote_DuringReload serves vote requests while failover.peers is reloaded - run with -race
+func TestManager_HandleTakeoverVote_DuringReload(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.cache.UpdateState(cache.State{Role: constants.RoleNamePassive, LeaderlessSamples: 3})
+	request := signedTestVoteRequest(t, manager, "192.168.1.101", time.Now())
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				code, _ := postTestVoteRequest(t, manager, request)
+				assert.Equal(t, http.StatusOK, code)
+			}
+		}()
+	}
+
+	// peer1 is kept across reloads while other peers come and go
+	for i := range 50 {
+		reloaded := createTestConfig()
+		if i%2 == 0 {
+			reloaded.Failover.Peers = config.Peers{"peer1": {IP: "192.168.1.101", Name: "peer1"}}
+		}
+		manager.applyReload(reloaded)
+	}
+	wg.Wait()
+}
+
+func TestManager_TakeoverQuorumReached(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Failover.TakeoverQuorum.Enabled = true
+	cfg.Failover.TakeoverQuorum.VoteTimeoutDuration = time.Second
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	// peers are unreachable here - only the witnesses vote
+	delete(manager.cfg.Failover.Peers, "peer1")
+	delete(manager.cfg.Failover.Peers, "peer2")
+
+	witness := func(agree bool) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request takeoverVoteRequest
+			if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&request)) || manager.verifyTakeoverVoteRequest(request) != nil {
+				http.Error(w, "invalid takeover vote request", http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode(takeoverVote{Name: "witness", Agree: agree})
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+	agreeing, disagreeing := witness(true), witness(false)
+
+	manager.cfg.Failover.TakeoverQuorum.Witnesses = []string{agreeing, disagreeing}
+	manager.cfg.Failover.TakeoverQuorum.Quorum = 1
+	assert.True(t, manager.takeoverQuorumReached())
+
+	manager.cfg.Failover.TakeoverQuorum.Quorum = 2
+	assert.False(t, manager.takeoverQuorumReached())
+
+	// an unreachable witness doesn't agree
+	manager.cfg.Failover.TakeoverQuorum.Witnesses = []string{agreeing, "http://127.0.0.1:1"}
+	assert.False(t, manager.takeoverQuorumReached())
+	manager.cfg.Failover.TakeoverQuorum.Witnesses = []string{agreeing, witness(true) + "/"}
+	assert.True(t, manager.takeoverQuorumReached())
+}
@@ -0,0 +1,118 @@
+package ha
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// telegramBotLoop answers commands sent to the Telegram bot until the manager stops
+func (m *Manager) telegramBotLoop() {
+	telegram := m.cfg.Notifications.Telegram
+	logger := log.WithPrefix(fmt.Sprintf("[%s telegram_bot]", m.logPrefix))
+	bot := notify.NewTelegramBot(notify.TelegramBotOptions{
+		BotToken:           telegram.BotToken,
+		AllowedChatIDs:     telegram.Commands.AllowedChatIDs,
+		RateLimitPerMinute: telegram.Commands.RateLimitPerMinute,
+		PollTimeout:        telegram.Commands.PollTimeoutDuration,
+		Commands: map[string]notify.TelegramCommandFunc{
+			"status":      m.telegramStatus,
+			"maintenance": m.telegramMaintenance,
+			"failover":    m.telegramFailover,
+		},
+		Logger:    logger,
+		Transport: notify.TLSTransport("telegram", telegram.TLS, logger),
+	})
+	bot.Run(m.ctx)
+}
+
+// telegramStatus replies with a summary of the current state
+func (m *Manager) telegramStatus(args []string) string {
+	state := m.cache.GetState()
+
+	lines := []string{
+		fmt.Sprintf("%s (%s)", state.ValidatorName, state.PublicIP),
+		fmt.Sprintf("role: %s", state.Role),
+		fmt.Sprintf("status: %s", state.Status),
+		fmt.Sprintf("failover status: %s", state.FailoverStatus),
+		fmt.Sprintf("self in gossip: %t", state.SelfInGossip),
+		fmt.Sprintf("peers: %d", state.PeerCount),
+	}
+	if state.ActivePeerName != "" {
+		lines = append(lines, fmt.Sprintf("active peer: %s", state.ActivePeerName))
+	}
+	if state.LeaderlessSamples > 0 {
+		lines = append(lines, fmt.Sprintf("leaderless samples: %d", state.LeaderlessSamples))
+	}
+	if m.takeoverAwaitingConfirmation.Load() {
+		lines = append(lines, "takeover awaiting confirmation: /failover confirm")
+	}
+	if state.CircuitBreakerTripped {
+		lines = append(lines, "failover circuit breaker tripped: /failover reset")
+	}
+	if m.notifyManager != nil {
+		if quiet := m.notifyManager.QuietStatus(); quiet.Quiet {
+			lines = append(lines, fmt.Sprintf("notifications quiet: %s since %s", quiet.Reason, quiet.Since.Format(time.RFC3339)))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("updated: %s", state.LastUpdated.Format(time.RFC3339)))
+
+	return strings.Join(lines, "\n")
+}
+
+// telegramMaintenance turns notification maintenance mode on or off, or shows the quiet status
+func (m *Manager) telegramMaintenance(args []string) string {
+	if m.notifyManager == nil {
+		return "Notifications are disabled"
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "on":
+			m.notifyManager.SetMaintenance(true)
+		case "off":
+			m.notifyManager.SetMaintenance(false)
+		default:
+			return "Usage: /maintenance [on|off]"
+		}
+	}
+
+	quiet := m.notifyManager.QuietStatus()
+	if !quiet.Quiet {
+		return fmt.Sprintf("maintenance: %t\nnotifications: normal", quiet.Maintenance)
+	}
+	return fmt.Sprintf("maintenance: %t\nnotifications: %s (%s) since %s, %d held",
+		quiet.Maintenance, quiet.Mode, quiet.Reason, quiet.Since.Format(time.RFC3339), quiet.HeldEvents)
+}
+
+// telegramFailover confirms a takeover held back by a failover policy with auto_takeover disabled or the failover
+// cooldown, or resets a tripped failover circuit breaker
+func (m *Manager) telegramFailover(args []string) string {
+	if len(args) != 1 {
+		return "Usage: /failover confirm|reset"
+	}
+	switch args[0] {
+	case "confirm":
+		return m.confirmTakeover()
+	case "reset":
+		return m.resetCircuitBreaker("telegram")
+	default:
+		return "Usage: /failover confirm|reset"
+	}
+}
+
+// confirmTakeover lets the next HA check take over despite a failover policy disabling automatic takeover or
+// failover.cooldown_duration not having passed since the last role change.
+// Only a takeover currently awaiting confirmation can be confirmed, and the confirmation is used at most once.
+func (m *Manager) confirmTakeover() string {
+	if !m.takeoverAwaitingConfirmation.Load() {
+		return "No takeover is awaiting confirmation"
+	}
+
+	m.takeoverConfirmed.Store(true)
+	m.logger.Warn("takeover confirmed manually - taking over on the next check if still required")
+	return fmt.Sprintf("Takeover confirmed - %s will take over on the next check if still required", m.cfg.Validator.Name)
+}
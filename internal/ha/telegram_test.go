@@ -0,0 +1,28 @@
+package ha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_ConfirmTakeover(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+
+	// nothing to confirm
+	assert.Equal(t, "No takeover is awaiting confirmation", manager.telegramFailover([]string{"confirm"}))
+	assert.False(t, manager.takeoverConfirmed.Load())
+
+	// usage
+	assert.Equal(t, "Usage: /failover confirm|reset", manager.telegramFailover(nil))
+
+	// a takeover held back by policy can be confirmed
+	manager.takeoverAwaitingConfirmation.Store(true)
+	assert.Contains(t, manager.telegramFailover([]string{"confirm"}), "Takeover confirmed")
+	assert.True(t, manager.takeoverConfirmed.Load())
+}
+
+func TestManager_TelegramMaintenance(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	assert.Equal(t, "Notifications are disabled", manager.telegramMaintenance([]string{"on"}))
+}
@@ -0,0 +1,46 @@
+package ha
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// textfileExportLoop periodically writes metrics for the node_exporter textfile collector until the manager stops,
+// then removes the file so node_exporter stops exporting stale state
+func (m *Manager) textfileExportLoop() {
+	path := m.cfg.Prometheus.Textfile.Path()
+	interval := m.cfg.Prometheus.Textfile.IntervalDuration
+	m.logger.Info("writing metrics textfile", "path", path, "interval", interval)
+
+	m.writeMetricsTextfile(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			m.removeMetricsTextfile()
+			return
+		case <-ticker.C:
+			m.writeMetricsTextfile(path)
+		}
+	}
+}
+
+// writeMetricsTextfile writes the current metrics to path
+func (m *Manager) writeMetricsTextfile(path string) {
+	if err := m.metrics.WriteTextfile(path); err != nil {
+		m.logger.Error("failed to write metrics textfile", "path", path, "error", err)
+	}
+}
+
+// removeMetricsTextfile removes the metrics textfile
+func (m *Manager) removeMetricsTextfile() {
+	path := m.cfg.Prometheus.Textfile.Path()
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		m.logger.Warn("failed to remove metrics textfile", "path", path, "error", err)
+	}
+}
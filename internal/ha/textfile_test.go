@@ -0,0 +1,40 @@
+package ha
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_TextfileExportLoop(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Prometheus.Textfile.Enabled = true
+	cfg.Prometheus.Textfile.Dir = t.TempDir()
+	cfg.Prometheus.Textfile.FileName = "solana_validator_ha.prom"
+	cfg.Prometheus.Textfile.IntervalDuration = 10 * time.Millisecond
+
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	path := filepath.Join(cfg.Prometheus.Textfile.Dir, "solana_validator_ha.prom")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		manager.textfileExportLoop()
+	}()
+
+	// written immediately and kept up to date
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// removed once the manager stops
+	manager.cancel()
+	<-done
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
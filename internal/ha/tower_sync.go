@@ -0,0 +1,175 @@
+package ha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+)
+
+// towerSyncRetryDelay is how long to wait before copying the tower again after a failed attempt
+const towerSyncRetryDelay = time.Second
+
+// readRemoteTower reads the tower file from a peer - replaced in tests
+var readRemoteTower = command.ReadRemoteFile
+
+// syncTower copies the tower file from the peer last seen active before we take over, returning false if the
+// takeover must not go ahead - it couldn't be copied and failover.tower_sync.must_succeed is set
+func (m *Manager) syncTower() bool {
+	oldActive, ok := m.gossipState.LastActivePeer()
+	if ok && oldActive.IPEquals(m.peerSelf.IP) {
+		m.incident.step("decision", "we were the last active peer - our tower is the latest")
+		return true
+	}
+
+	startedAt := time.Now()
+	err := fmt.Errorf("no active peer seen since startup - unknown which peer to copy the tower from")
+	if ok {
+		err = m.copyTowerFrom(oldActive.Name, oldActive.IP)
+	}
+	m.incident.timed("tower sync", fmt.Sprintf("synced tower from old active peer %s", oldActive.Name), startedAt, err)
+	if err == nil {
+		return true
+	}
+
+	if m.cfg.Failover.TowerSync.MustSucceed {
+		m.logger.Error("failed to copy tower from old active peer - unable to become active in failover", "peer", oldActive.Name, "error", err)
+		return false
+	}
+	m.logger.Warn("failed to copy tower from old active peer - taking over with our own tower", "peer", oldActive.Name, "error", err)
+	return true
+}
+
+// resyncTowerAfterFencing copies the tower from the old active peer again once failover.fencing has fenced it, as
+// it may have voted after syncTower copied its tower - the fenced peer's tower is final. Returns false if the
+// takeover must not go ahead - it couldn't be copied and failover.tower_sync.must_succeed is set, so the tower we
+// have may be older than the votes the old active cast.
+func (m *Manager) resyncTowerAfterFencing() bool {
+	oldActive, ok := m.gossipState.LastActivePeer()
+	if !ok || oldActive.IPEquals(m.peerSelf.IP) {
+		return true
+	}
+
+	startedAt := time.Now()
+	err := m.copyTowerFrom(oldActive.Name, oldActive.IP)
+	m.incident.timed("tower sync", fmt.Sprintf("synced tower from fenced old active peer %s", oldActive.Name), startedAt, err)
+	if err == nil {
+		return true
+	}
+
+	if m.cfg.Failover.TowerSync.MustSucceed {
+		m.logger.Error("failed to copy tower from fenced old active peer - it may have voted since our copy, unable to become active in failover",
+			"peer", oldActive.Name, "error", err)
+		return false
+	}
+	m.logger.Warn("failed to copy tower from fenced old active peer - taking over with the tower copied before fencing", "peer", oldActive.Name, "error", err)
+	return true
+}
+
+// copyTowerFrom copies the tower file from the named peer at ip over failover.ssh, attempting it up to
+// failover.tower_sync.retries more times
+func (m *Manager) copyTowerFrom(name string, ip string) error {
+	towerSync := m.cfg.Failover.TowerSync
+	path, err := towerSync.TowerPath(m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String())
+	if err != nil {
+		return err
+	}
+	logger := m.logger.With("peer", name, "ip", ip, "path", path)
+
+	if m.cfg.Failover.DryRun {
+		logger.Warn("dry run - not copying tower from old active peer")
+		return nil
+	}
+
+	remote := m.cfg.Failover.SSH.RemoteOptions(ip, m.peers())
+	remote.Timeout = towerSync.TimeoutDuration
+	for attempt := 0; ; attempt++ {
+		var file command.RemoteFile
+		file, err = readRemoteTower(m.ctx, remote, path)
+		if err == nil {
+			var installed bool
+			installed, err = installTower(path, file, towerSync.MaxAgeDuration)
+			if err == nil && installed {
+				logger.Warn("copied tower from old active peer", "sha256", file.SHA256, "modified_at", file.ModTime.UTC())
+			}
+			if err == nil && !installed {
+				logger.Info("our tower is as new as the old active peer's - keeping it", "modified_at", file.ModTime.UTC())
+			}
+		}
+		if err == nil || attempt >= towerSync.Retries {
+			return err
+		}
+
+		logger.Warn("failed to copy tower from old active peer - retrying", "retry", attempt+1, "retries", towerSync.Retries, "error", err)
+		select {
+		case <-m.ctx.Done():
+			return fmt.Errorf("tower sync cancelled: %w", context.Cause(m.ctx))
+		case <-time.After(towerSyncRetryDelay):
+		}
+	}
+}
+
+// installTower writes file as our tower at path unless ours was written as recently, returning whether it was
+// written - a file last written longer ago than maxAge is refused as stale, unless maxAge is zero. The tower is
+// replaced atomically, keeping the mode and owner of ours or, without one, the owner of its directory - when
+// running as root.
+func installTower(path string, file command.RemoteFile, maxAge time.Duration) (bool, error) {
+	if maxAge > 0 && time.Since(file.ModTime) > maxAge {
+		return false, fmt.Errorf("tower last written at %s is older than failover.tower_sync.max_age_duration %s - stale",
+			file.ModTime.UTC().Format(time.RFC3339), maxAge)
+	}
+
+	mode := os.FileMode(0o644)
+	owner, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return false, fmt.Errorf("failed to stat tower directory: %w", err)
+	}
+	local, err := os.Stat(path)
+	switch {
+	case err == nil:
+		// remote modification times are whole seconds
+		if !local.ModTime().Truncate(time.Second).Before(file.ModTime) {
+			return false, nil
+		}
+		mode, owner = local.Mode().Perm(), local
+	case !errors.Is(err, os.ErrNotExist):
+		return false, fmt.Errorf("failed to stat tower: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tower-sync-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary tower: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(file.Data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	// only root can give the tower to the validator's user
+	if stat, ok := owner.Sys().(*syscall.Stat_t); ok && os.Geteuid() == 0 && err == nil {
+		err = os.Chown(tmp.Name(), int(stat.Uid), int(stat.Gid))
+	}
+	if err == nil {
+		err = os.Chtimes(tmp.Name(), file.ModTime, file.ModTime)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write tower: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,129 @@
+package ha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/gossip"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallTower(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tower-1_9-active.bin")
+	writtenAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	file := command.RemoteFile{Data: []byte("remote tower"), ModTime: writtenAt}
+
+	// without a tower of ours it is written
+	installed, err := installTower(path, file, 0)
+	require.NoError(t, err)
+	assert.True(t, installed)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "remote tower", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(writtenAt))
+
+	// ours as new is kept, while an older one is replaced keeping its mode
+	installed, err = installTower(path, command.RemoteFile{Data: []byte("same age"), ModTime: writtenAt}, 0)
+	require.NoError(t, err)
+	assert.False(t, installed)
+	require.NoError(t, os.Chmod(path, 0o600))
+	installed, err = installTower(path, command.RemoteFile{Data: []byte("newer tower"), ModTime: writtenAt.Add(time.Second)}, 0)
+	require.NoError(t, err)
+	assert.True(t, installed)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "newer tower", string(data))
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// a stale tower is refused
+	_, err = installTower(path, command.RemoteFile{Data: []byte("stale"), ModTime: time.Now().Add(-time.Hour)}, 10*time.Minute)
+	assert.ErrorContains(t, err, "is older than failover.tower_sync.max_age_duration 10m0s - stale")
+
+	// no temporary towers are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// newOldActiveTestManager returns a manager with peer old-active seen active in gossip on a mock server, syncing its
+// tower to a temporary directory
+func newOldActiveTestManager(t *testing.T) (*Manager, string) {
+	cfg := createTestConfig()
+	activePubkey := cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
+
+	var gossipAddress string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID any `json:"id"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := []any{map[string]any{"pubkey": activePubkey, "gossip": gossipAddress}}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result}))
+	}))
+	t.Cleanup(server.Close)
+	gossipAddress = server.Listener.Addr().String()
+
+	cfg.Failover.DryRun = false
+	cfg.Failover.Peers["old-active"] = config.Peer{Name: "old-active", IP: "127.0.0.1"}
+	cfg.Failover.TowerSync = config.TowerSync{Enabled: true, Path: filepath.Join(t.TempDir(), "tower-1_9-{{ .ActiveIdentityPubkey }}.bin")}
+	cfg.Failover.TowerSync.SetDefaults()
+
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	t.Cleanup(manager.cancel)
+	manager.gossipState = gossip.NewState(gossip.Options{
+		ClusterRPC:   rpc.NewClient("test", server.URL),
+		ActivePubkey: activePubkey,
+		ConfigPeers:  cfg.Failover.Peers,
+	})
+	manager.gossipState.Refresh()
+	_, ok := manager.gossipState.LastActivePeer()
+	require.True(t, ok)
+
+	path, err := cfg.Failover.TowerSync.TowerPath(activePubkey)
+	require.NoError(t, err)
+	return manager, path
+}
+
+func TestManager_ResyncTowerAfterFencing(t *testing.T) {
+	manager, path := newOldActiveTestManager(t)
+	originalReadRemoteTower := readRemoteTower
+	t.Cleanup(func() { readRemoteTower = originalReadRemoteTower })
+
+	// the old active votes between the copy before fencing and being fenced - its final tower replaces ours
+	writtenAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	remote := command.RemoteFile{Data: []byte("before fencing"), ModTime: writtenAt}
+	readRemoteTower = func(_ context.Context, options command.RemoteOptions, _ string) (command.RemoteFile, error) {
+		assert.Equal(t, "127.0.0.1", options.Host)
+		return remote, nil
+	}
+	require.True(t, manager.syncTower())
+	remote = command.RemoteFile{Data: []byte("after fencing"), ModTime: writtenAt.Add(time.Second)}
+	require.True(t, manager.resyncTowerAfterFencing())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "after fencing", string(data))
+
+	// failing to copy it again continues with the tower copied before fencing, unless it must succeed
+	readRemoteTower = func(context.Context, command.RemoteOptions, string) (command.RemoteFile, error) {
+		return command.RemoteFile{}, errors.New("connection refused")
+	}
+	assert.True(t, manager.resyncTowerAfterFencing())
+	manager.cfg.Failover.TowerSync.MustSucceed = true
+	assert.False(t, manager.resyncTowerAfterFencing())
+}
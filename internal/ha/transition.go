@@ -0,0 +1,79 @@
+package ha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/gossip"
+)
+
+// errArbitrationLost aborts a takeover that lost the failover.arbitration lock before it finished
+var errArbitrationLost = errors.New("lost the failover.arbitration lock")
+
+// transition is the role transition in progress - its hooks and role command run under a context derived from the
+// manager's, which a takeover that must not finish is cancelled with the reason for
+type transition struct {
+	mu       sync.Mutex
+	takeover bool
+	cancel   context.CancelCauseFunc
+}
+
+// beginTransition derives the context a role transition runs its hooks and role command under, returning it with
+// a func to call once the transition finishes - only a takeover can be aborted by abortTakeover
+func (m *Manager) beginTransition(takeover bool) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(m.ctx)
+
+	m.transition.mu.Lock()
+	m.transition.takeover, m.transition.cancel = takeover, cancel
+	m.transition.mu.Unlock()
+
+	return ctx, func() {
+		m.transition.mu.Lock()
+		m.transition.takeover, m.transition.cancel = false, nil
+		m.transition.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// abortTakeover cancels the takeover in progress, if any, with cause
+func (m *Manager) abortTakeover(cause error) {
+	m.transition.mu.Lock()
+	defer m.transition.mu.Unlock()
+
+	if m.transition.takeover && m.transition.cancel != nil {
+		m.logger.Error("aborting takeover", "reason", cause)
+		m.transition.cancel(cause)
+	}
+}
+
+// watchForActivePeer aborts the takeover running under ctx once a peer is seen active and voting in gossip, polling
+// every failover.poll_interval_duration on its own gossip state so the HA loop's leaderless samples are left alone
+func (m *Manager) watchForActivePeer(ctx context.Context) {
+	state := gossip.NewState(gossip.Options{
+		ClusterRPC:   m.clusterRPC,
+		ActivePubkey: m.cfg.Validator.Identities.ActiveKeyPair.PublicKey().String(),
+		SelfIP:       m.peerSelf.IP,
+		ConfigPeers:  m.peers(),
+		LogPrefix:    m.logPrefix,
+	})
+
+	ticker := time.NewTicker(m.cfg.Failover.PollIntervalDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		state.Refresh()
+		if activePeer, err := state.GetActivePeer(); err == nil && !activePeer.IPEquals(m.peerSelf.IP) {
+			m.abortTakeover(fmt.Errorf("peer %s seen active", activePeer.Name))
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ha
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_AbortTakeover(t *testing.T) {
+	manager := NewManager(NewManagerOptions{Cfg: createTestConfig(), GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+
+	// becoming passive is never aborted
+	ctx, done := manager.beginTransition(false)
+	manager.abortTakeover(errArbitrationLost)
+	assert.NoError(t, ctx.Err())
+	done()
+	assert.Error(t, ctx.Err())
+
+	// a takeover is, with the reason why
+	ctx, done = manager.beginTransition(true)
+	manager.abortTakeover(errArbitrationLost)
+	assert.ErrorIs(t, context.Cause(ctx), errArbitrationLost)
+	done()
+
+	// and nothing is left to abort once it finishes
+	manager.abortTakeover(errArbitrationLost)
+	assert.Nil(t, manager.transition.cancel)
+}
+
+func TestManager_EnsureActive_AbortedTakeoverSkipsActiveCommand(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "active")
+	cfg := createTestConfig()
+	cfg.Failover.DryRun = false
+	cfg.Failover.Active = config.Role{
+		Command: "touch",
+		Args:    []string{marker},
+		Hooks:   config.Hooks{Pre: []config.Hook{{Name: "slow", Command: "sleep", Args: []string{"5"}}}},
+	}
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+	require.NoError(t, manager.initialize())
+	manager.localRPC = newPassiveIdentityRPC(t, cfg)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		manager.abortTakeover(errArbitrationLost)
+	}()
+
+	startedAt := time.Now()
+	manager.ensureActive()
+	assert.Less(t, time.Since(startedAt), 3*time.Second, "the pre hook should be cancelled")
+	assert.NoFileExists(t, marker, "the active command must not run once the takeover is aborted")
+}
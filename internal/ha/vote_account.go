@@ -0,0 +1,159 @@
+package ha
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+)
+
+// voteAccountChange is an unexpected change to a watched vote account field
+type voteAccountChange struct {
+	Field    string
+	Expected string
+	Observed string
+}
+
+// voteAccountWatchLoop checks the vote account for unexpected changes every
+// validator.vote_account_watch.interval_duration until the manager is stopped
+func (m *Manager) voteAccountWatchLoop() {
+	interval := m.cfg.Validator.VoteAccountWatch.IntervalDuration
+	m.logger.Info("watching vote account for commission and authority changes", "interval", interval)
+
+	m.checkVoteAccount()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.checkVoteAccount()
+		}
+	}
+}
+
+// checkVoteAccount fetches the vote account and sends a critical event for each unexpected change
+func (m *Manager) checkVoteAccount() {
+	if m.voteAccount.IsZero() {
+		voteAccount, err := m.resolveVoteAccount()
+		if err != nil {
+			m.logger.Warn("failed to resolve vote account to watch", "error", err)
+			return
+		}
+		m.voteAccount = voteAccount
+		m.logger.Debug("resolved vote account to watch", "vote_account", voteAccount)
+	}
+
+	observed, err := m.clusterRPC.GetVoteAccountState(m.ctx, m.voteAccount)
+	if err != nil {
+		m.logger.Warn("failed to get vote account state", "vote_account", m.voteAccount, "error", err)
+		return
+	}
+
+	for _, change := range voteAccountChanges(m.voteAccountState, *observed, m.cfg.Validator.VoteAccountWatch) {
+		m.logger.Error("unexpected vote account change - possible key compromise or misconfiguration",
+			"vote_account", m.voteAccount,
+			"field", change.Field,
+			"expected", change.Expected,
+			"observed", change.Observed,
+		)
+
+		if m.notifyManager != nil {
+			m.notifyManager.NotifyAsync(notify.Event{
+				Type:          notify.EventVoteAccountChanged,
+				Severity:      notify.SeverityCritical,
+				ValidatorName: m.cfg.Validator.Name,
+				PublicIP:      m.peerSelf.IP,
+				Cluster:       m.cfg.Cluster.Name,
+				Message:       fmt.Sprintf("Vote account %s changed from %s to %s - possible key compromise or misconfiguration", change.Field, change.Expected, change.Observed),
+				Details: map[string]string{
+					"vote_account": m.voteAccount.String(),
+					"field":        change.Field,
+					"expected":     change.Expected,
+					"observed":     change.Observed,
+				},
+			})
+		}
+	}
+
+	m.voteAccountState = observed
+}
+
+// resolveVoteAccount returns the configured vote account, or the vote account whose node is the active identity
+func (m *Manager) resolveVoteAccount() (solanago.PublicKey, error) {
+	if m.cfg.Validator.VoteAccountWatch.VoteAccount != "" {
+		return solanago.PublicKeyFromBase58(m.cfg.Validator.VoteAccountWatch.VoteAccount)
+	}
+
+	voteAccounts, err := m.clusterRPC.GetVoteAccounts(m.ctx)
+	if err != nil {
+		return solanago.PublicKey{}, err
+	}
+
+	activePubkey := m.cfg.Validator.Identities.ActiveKeyPair.PublicKey()
+	for _, voteAccount := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if voteAccount.NodePubkey.Equals(activePubkey) {
+			return voteAccount.VotePubkey, nil
+		}
+	}
+
+	return solanago.PublicKey{}, fmt.Errorf("no vote account found for active identity %s", activePubkey)
+}
+
+// voteAccountChanges returns the watched fields of observed that differ from what is expected - the configured
+// expected value if set, else the previously observed value. A change is only reported once, when first observed.
+func voteAccountChanges(previous *rpc.VoteAccountState, observed rpc.VoteAccountState, cfg config.VoteAccountWatch) []voteAccountChange {
+	type watchedField struct {
+		name     string
+		pinned   string
+		previous string
+		observed string
+	}
+
+	fields := []watchedField{
+		{name: "commission", observed: strconv.Itoa(int(observed.Commission))},
+		{name: "authorized_withdrawer", pinned: cfg.ExpectedAuthorizedWithdrawer, observed: observed.AuthorizedWithdrawer},
+		{name: "authorized_voter", pinned: cfg.ExpectedAuthorizedVoter, observed: observed.AuthorizedVoter},
+	}
+	if cfg.ExpectedCommission != nil {
+		fields[0].pinned = strconv.Itoa(*cfg.ExpectedCommission)
+	}
+	if previous != nil {
+		fields[0].previous = strconv.Itoa(int(previous.Commission))
+		fields[1].previous = previous.AuthorizedWithdrawer
+		fields[2].previous = previous.AuthorizedVoter
+	}
+
+	changes := []voteAccountChange{}
+	for _, field := range fields {
+		expected := field.pinned
+		if expected == "" {
+			expected = field.previous
+		}
+
+		// nothing to compare against on the first observation of an unpinned field
+		if expected == "" || field.observed == expected {
+			continue
+		}
+
+		// already reported when first observed
+		if previous != nil && field.observed == field.previous {
+			continue
+		}
+
+		changes = append(changes, voteAccountChange{
+			Field:    field.name,
+			Expected: expected,
+			Observed: field.observed,
+		})
+	}
+
+	return changes
+}
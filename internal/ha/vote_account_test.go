@@ -0,0 +1,56 @@
+package ha
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteAccountChanges(t *testing.T) {
+	cfg := config.VoteAccountWatch{Enabled: true}
+	baseline := rpc.VoteAccountState{Commission: 5, AuthorizedWithdrawer: "withdrawer1", AuthorizedVoter: "voter1"}
+
+	// first observation without pinned values has nothing to compare against
+	assert.Empty(t, voteAccountChanges(nil, baseline, cfg))
+
+	// unchanged
+	assert.Empty(t, voteAccountChanges(&baseline, baseline, cfg))
+
+	// commission and withdrawer change
+	changed := baseline
+	changed.Commission = 100
+	changed.AuthorizedWithdrawer = "attacker"
+	changes := voteAccountChanges(&baseline, changed, cfg)
+	require.Len(t, changes, 2)
+	assert.Equal(t, voteAccountChange{Field: "commission", Expected: "5", Observed: "100"}, changes[0])
+	assert.Equal(t, voteAccountChange{Field: "authorized_withdrawer", Expected: "withdrawer1", Observed: "attacker"}, changes[1])
+
+	// the change is only reported once
+	assert.Empty(t, voteAccountChanges(&changed, changed, cfg))
+}
+
+func TestVoteAccountChanges_Pinned(t *testing.T) {
+	commission := 5
+	cfg := config.VoteAccountWatch{
+		Enabled:                 true,
+		ExpectedCommission:      &commission,
+		ExpectedAuthorizedVoter: "voter1",
+	}
+	observed := rpc.VoteAccountState{Commission: 10, AuthorizedWithdrawer: "withdrawer1", AuthorizedVoter: "voter1"}
+
+	// pinned values are checked on the first observation
+	changes := voteAccountChanges(nil, observed, cfg)
+	require.Len(t, changes, 1)
+	assert.Equal(t, voteAccountChange{Field: "commission", Expected: "5", Observed: "10"}, changes[0])
+
+	// and not reported again while unchanged
+	assert.Empty(t, voteAccountChanges(&observed, observed, cfg))
+
+	// reverting to the pinned value is not a change
+	reverted := observed
+	reverted.Commission = 5
+	assert.Empty(t, voteAccountChanges(&observed, reverted, cfg))
+}
@@ -0,0 +1,82 @@
+package ha
+
+import (
+	"fmt"
+	"strconv"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+)
+
+// minClusterAverageVoteCredits is the cluster average credits below which vote credits aren't compared, as the
+// ratio is noise at the start of an epoch
+const minClusterAverageVoteCredits = 1000
+
+// checkVoteCredits compares the vote account's credits this epoch with the cluster average, sending
+// vote_credits_lagging from the active node once validator.vote_credits declares it lagging
+func (m *Manager) checkVoteCredits() {
+	cfg := m.cfg.Validator.VoteCredits
+	if !cfg.Enabled {
+		return
+	}
+
+	var voteAccount solanago.PublicKey
+	if cfg.VoteAccount != "" {
+		voteAccount = solanago.MustPublicKeyFromBase58(cfg.VoteAccount)
+	}
+	credits, err := m.clusterRPC.GetVoteCredits(m.ctx, m.cfg.Validator.Identities.ActiveKeyPair.PublicKey(), voteAccount)
+	if err != nil {
+		m.logger.Warn("failed to get vote credits", "error", err)
+		return
+	}
+	m.voteCredits = &credits
+
+	if credits.ClusterAverage < minClusterAverageVoteCredits {
+		return
+	}
+
+	onPar, changed := m.voteCreditsCheck.observe(!voteCreditsLagging(credits, cfg.LagPercent))
+	if !changed {
+		return
+	}
+
+	logger := m.logger.With(
+		"vote_account", credits.VoteAccount,
+		"epoch", credits.Epoch,
+		"credits", credits.Credits,
+		"cluster_average", int(credits.ClusterAverage),
+	)
+	if onPar {
+		logger.Info("vote credits back within validator.vote_credits.lag_percent of the cluster average")
+		return
+	}
+
+	logger.Warn("vote credits lagging the cluster average", "lag_percent", cfg.LagPercent)
+
+	// the vote account is shared, so only the node voting with it reports it
+	if m.notifyManager == nil || !m.isSelfActive() {
+		return
+	}
+	percentOfAverage := strconv.FormatFloat(credits.Ratio()*100, 'f', 1, 64)
+	m.notifyManager.NotifyAsync(notify.Event{
+		Type:          notify.EventVoteCreditsLagging,
+		Severity:      notify.SeverityWarning,
+		ValidatorName: m.cfg.Validator.Name,
+		PublicIP:      m.peerSelf.IP,
+		Cluster:       m.cfg.Cluster.Name,
+		Message:       fmt.Sprintf("Vote credits %d are %s%% of the cluster average %.0f this epoch - degraded but not delinquent", credits.Credits, percentOfAverage, credits.ClusterAverage),
+		Details: map[string]string{
+			"vote_account":       credits.VoteAccount,
+			"epoch":              strconv.FormatUint(credits.Epoch, 10),
+			"credits":            strconv.FormatUint(credits.Credits, 10),
+			"cluster_average":    strconv.FormatFloat(credits.ClusterAverage, 'f', 0, 64),
+			"percent_of_average": percentOfAverage,
+		},
+	})
+}
+
+// voteCreditsLagging returns true if credits are more than lagPercent below the cluster average
+func voteCreditsLagging(credits rpc.VoteCredits, lagPercent float64) bool {
+	return credits.Ratio() < 1-lagPercent/100
+}
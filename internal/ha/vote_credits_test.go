@@ -0,0 +1,66 @@
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteCreditsLagging(t *testing.T) {
+	assert.False(t, voteCreditsLagging(rpc.VoteCredits{Credits: 950, ClusterAverage: 1000}, 10))
+	assert.False(t, voteCreditsLagging(rpc.VoteCredits{Credits: 900, ClusterAverage: 1000}, 10))
+	assert.True(t, voteCreditsLagging(rpc.VoteCredits{Credits: 899, ClusterAverage: 1000}, 10))
+	assert.False(t, voteCreditsLagging(rpc.VoteCredits{Credits: 1200, ClusterAverage: 1000}, 10))
+}
+
+func TestManager_CheckVoteCredits(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Validator.VoteCredits.Enabled = true
+	cfg.Validator.VoteCredits.LagPercent = 10
+	cfg.Validator.VoteCredits.LaggingSamplesThreshold = 2
+	manager := NewManager(NewManagerOptions{Cfg: cfg, GetPublicIPFunc: mockPublicIPFunc})
+
+	// our vote account earning ourCredits against one peer earning 10000 this epoch
+	identity := cfg.Validator.Identities.ActiveKeyPair.PublicKey().String()
+	ourCredits := 10000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID any `json:"id"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := map[string]any{
+			"current": []any{
+				map[string]any{"votePubkey": "Config1111111111111111111111111111111111111", "nodePubkey": identity, "activatedStake": 1, "epochCredits": [][]int64{{5, int64(ourCredits), 0}}},
+				map[string]any{"votePubkey": "Stake11111111111111111111111111111111111111", "nodePubkey": "Stake11111111111111111111111111111111111111", "activatedStake": 1, "epochCredits": [][]int64{{5, 10000, 0}}},
+			},
+			"delinquent": []any{},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result}))
+	}))
+	defer server.Close()
+	manager.clusterRPC = rpc.NewClient("test", server.URL)
+
+	manager.checkVoteCredits()
+	require.NotNil(t, manager.voteCredits)
+	assert.Equal(t, uint64(10000), manager.voteCredits.Credits)
+	assert.True(t, manager.voteCreditsCheck.healthy)
+
+	// lagging only after 2 consecutive lagging polls
+	ourCredits = 5000
+	manager.checkVoteCredits()
+	assert.True(t, manager.voteCreditsCheck.healthy)
+	manager.checkVoteCredits()
+	assert.False(t, manager.voteCreditsCheck.healthy)
+	assert.Equal(t, 7500.0, manager.voteCredits.ClusterAverage)
+
+	// and back on par after 1
+	ourCredits = 9900
+	manager.checkVoteCredits()
+	assert.True(t, manager.voteCreditsCheck.healthy)
+}
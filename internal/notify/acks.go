@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Acknowledgement records that an operator has taken ownership of a failover incident
+type Acknowledgement struct {
+	// ID is the failover ID acknowledged
+	ID   string    `json:"id"`
+	By   string    `json:"by"`
+	Note string    `json:"note,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// acknowledgements keeps the incidents acknowledged since startup, by failover ID
+type acknowledgements struct {
+	mu   sync.Mutex
+	byID map[string]Acknowledgement
+}
+
+// newAcknowledgements creates an empty acknowledgement store
+func newAcknowledgements() *acknowledgements {
+	return &acknowledgements{byID: make(map[string]Acknowledgement)}
+}
+
+// add records an acknowledgement, replacing any earlier one for the same ID
+func (a *acknowledgements) add(ack Acknowledgement) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.byID[ack.ID] = ack
+}
+
+// get returns the acknowledgement for id, if any
+func (a *acknowledgements) get(id string) (Acknowledgement, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ack, ok := a.byID[id]
+	return ack, ok
+}
+
+// list returns every acknowledgement, oldest first
+func (a *acknowledgements) list() []Acknowledgement {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	acks := slices.Collect(maps.Values(a.byID))
+	slices.SortFunc(acks, func(x, y Acknowledgement) int {
+		return x.At.Compare(y.At)
+	})
+	return acks
+}
+
+// annotate adds who acknowledged the event's failover, if anyone, to a copy of its details
+func (a *acknowledgements) annotate(event Event) Event {
+	id := event.Details["failover_id"]
+	if id == "" {
+		return event
+	}
+
+	ack, ok := a.get(id)
+	if !ok {
+		return event
+	}
+
+	details := maps.Clone(event.Details)
+	details["acknowledged_by"] = ack.By
+	details["acknowledged_at"] = ack.At.Format(time.RFC3339)
+	event.Details = details
+	return event
+}
+
+// isAcknowledged returns true if the event belongs to an acknowledged incident
+func isAcknowledged(event Event) bool {
+	return event.Details["acknowledged_by"] != ""
+}
+
+// Acknowledge records that by has taken ownership of the failover with the given ID. Later events for it carry
+// acknowledged_by and acknowledged_at details, no longer mention operators and acknowledge rather than trigger
+// their PagerDuty incident.
+func (m *Manager) Acknowledge(id string, by string, note string) Acknowledgement {
+	ack := Acknowledgement{ID: id, By: by, Note: note, At: time.Now().UTC()}
+	m.acks.add(ack)
+	m.logger.Info("failover acknowledged", "failover_id", id, "by", by, "note", note)
+	return ack
+}
+
+// Acknowledgements returns the failovers acknowledged since startup, oldest first
+func (m *Manager) Acknowledgements() []Acknowledgement {
+	return m.acks.list()
+}
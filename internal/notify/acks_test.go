@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Acknowledge(t *testing.T) {
+	manager := NewManager(ManagerOptions{
+		Config:        &config.NotificationConfig{Enabled: false, HistorySize: 10},
+		ValidatorName: "validator-1",
+	})
+
+	details := map[string]string{"failover_id": "20250101T000000Z-0a1b2c3d"}
+	manager.Notify(Event{Type: EventBecomingActive, Details: details})
+
+	ack := manager.Acknowledge("20250101T000000Z-0a1b2c3d", "alice", "looking into it")
+	assert.Equal(t, []Acknowledgement{ack}, manager.Acknowledgements())
+
+	manager.Notify(Event{Type: EventBecameActive, Details: details})
+	manager.Notify(Event{Type: EventBecameActive, Details: map[string]string{"failover_id": "other"}})
+
+	events := manager.Events(time.Time{})
+	require.Len(t, events, 3)
+
+	// events before the acknowledgement and for other failovers are not annotated
+	assert.NotContains(t, events[0].Details, "acknowledged_by")
+	assert.NotContains(t, events[2].Details, "acknowledged_by")
+
+	assert.Equal(t, "alice", events[1].Details["acknowledged_by"])
+	assert.Equal(t, ack.At.Format(time.RFC3339), events[1].Details["acknowledged_at"])
+
+	// the caller's details are not modified
+	assert.NotContains(t, details, "acknowledged_by")
+}
+
+func TestPagerDutyNotifier_Acknowledged(t *testing.T) {
+	transport := &recordingTransport{}
+	notifier := NewPagerDutyNotifier(PagerDutyOptions{
+		RoutingKey: "routing-key",
+		Logger:     log.New(io.Discard),
+		Transport:  transport,
+	})
+
+	events := []Event{
+		{Type: EventBecomingActive, ValidatorName: "validator-1", Details: map[string]string{"failover_id": "abc"}},
+		{Type: EventAcknowledged, ValidatorName: "validator-1", Details: map[string]string{"failover_id": "abc", "acknowledged_by": "alice"}},
+		{Type: EventBecameActive, ValidatorName: "validator-1", Details: map[string]string{"failover_id": "abc", "acknowledged_by": "alice"}},
+	}
+	for _, event := range events {
+		require.NoError(t, notifier.Send(context.Background(), event))
+	}
+
+	require.Len(t, transport.bodies, 3)
+	assert.Equal(t, "trigger", transport.bodies[0]["event_action"])
+	assert.Equal(t, "acknowledge", transport.bodies[1]["event_action"])
+	assert.Equal(t, "acknowledge", transport.bodies[2]["event_action"])
+
+	// every event for the failover updates the same incident
+	for _, body := range transport.bodies {
+		assert.Equal(t, "validator-1-failover-abc", body["dedup_key"])
+	}
+}
+
+func TestMentions_ForAcknowledgedEvent(t *testing.T) {
+	m := newMentions(map[string]config.NotificationMentions{
+		"validator-1": {Discord: []string{"111"}},
+	}, func(m config.NotificationMentions) []string { return m.Discord })
+
+	assert.Empty(t, m.forEvent(Event{
+		Type:          EventBecameActive,
+		ValidatorName: "validator-1",
+		Details:       map[string]string{"failover_id": "abc", "acknowledged_by": "alice"},
+	}))
+}
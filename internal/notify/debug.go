@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const redactedPlaceholder = "[REDACTED]"
+
+// Exchange is a sanitized summary of a single notifier HTTP request/response
+type Exchange struct {
+	Service      string        `json:"service"`
+	Method       string        `json:"method"`
+	URL          string        `json:"url"`
+	StatusCode   int           `json:"status_code"`
+	Latency      time.Duration `json:"latency"`
+	Error        string        `json:"error,omitempty"`
+	RequestBody  string        `json:"request_body,omitempty"`
+	ResponseBody string        `json:"response_body,omitempty"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// exchangeRecorder keeps the last N exchanges in memory
+type exchangeRecorder struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	size      int
+}
+
+func newExchangeRecorder(size int) *exchangeRecorder {
+	return &exchangeRecorder{
+		exchanges: make([]Exchange, 0, size),
+		size:      size,
+	}
+}
+
+// record adds an exchange, dropping the oldest when full
+func (r *exchangeRecorder) record(exchange Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.exchanges) >= r.size {
+		r.exchanges = r.exchanges[1:]
+	}
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+// list returns a copy of the recorded exchanges, oldest first
+func (r *exchangeRecorder) list() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exchanges := make([]Exchange, len(r.exchanges))
+	copy(exchanges, r.exchanges)
+	return exchanges
+}
+
+// redactor replaces known secrets in strings
+type redactor struct {
+	secrets []string
+}
+
+// newRedactor creates a redactor for the given secrets, ignoring empty values
+func newRedactor(secrets ...string) *redactor {
+	r := &redactor{}
+	for _, secret := range secrets {
+		if secret != "" {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+	return r
+}
+
+// webhookURLSecret returns the secret portion of a webhook URL - its path and query -
+// so that redacted URLs still show which host was called
+func webhookURLSecret(webhookURL string) string {
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil || parsedURL.Host == "" {
+		return webhookURL
+	}
+	return strings.TrimPrefix(webhookURL, parsedURL.Scheme+"://"+parsedURL.Host)
+}
+
+// redact replaces all secrets in s with a placeholder
+func (r *redactor) redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+	}
+	return s
+}
+
+// newDebugTransport wraps the default transport with exchange logging for the given service
+func newDebugTransport(service string, recorder *exchangeRecorder, redactor *redactor, captureBody bool, maxBodyBytes int, logger *log.Logger) *debugTransport {
+	return &debugTransport{
+		service:      service,
+		next:         http.DefaultTransport,
+		recorder:     recorder,
+		redactor:     redactor,
+		captureBody:  captureBody,
+		maxBodyBytes: maxBodyBytes,
+		logger:       logger,
+	}
+}
+
+// debugTransport is an http.RoundTripper that logs and records sanitized request/response summaries
+type debugTransport struct {
+	service      string
+	next         http.RoundTripper
+	recorder     *exchangeRecorder
+	redactor     *redactor
+	captureBody  bool
+	maxBodyBytes int
+	logger       *log.Logger
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := Exchange{
+		Service:   t.service,
+		Method:    req.Method,
+		URL:       t.redactor.redact(req.URL.String()),
+		Timestamp: time.Now().UTC(),
+	}
+
+	if t.captureBody && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.RequestBody = t.sanitizeBody(body)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	exchange.Latency = time.Since(start)
+
+	if err != nil {
+		exchange.Error = t.redactor.redact(err.Error())
+	} else {
+		exchange.StatusCode = resp.StatusCode
+		if t.captureBody && resp.Body != nil {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			if readErr == nil {
+				exchange.ResponseBody = t.sanitizeBody(body)
+			}
+		}
+	}
+
+	t.recorder.record(exchange)
+	t.logger.Debug("notifier http exchange",
+		"service", exchange.Service,
+		"method", exchange.Method,
+		"url", exchange.URL,
+		"status", exchange.StatusCode,
+		"latency", exchange.Latency,
+		"error", exchange.Error,
+		"request_body", exchange.RequestBody,
+		"response_body", exchange.ResponseBody,
+	)
+
+	return resp, err
+}
+
+// sanitizeBody truncates and redacts a body for logging
+func (t *debugTransport) sanitizeBody(body []byte) string {
+	s := t.redactor.redact(string(body))
+	if t.maxBodyBytes > 0 && len(s) > t.maxBodyBytes {
+		s = s[:t.maxBodyBytes] + "...(truncated)"
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookURLSecret(t *testing.T) {
+	assert.Equal(t, "/api/webhooks/123/abc", webhookURLSecret("https://discord.com/api/webhooks/123/abc"))
+	assert.Equal(t, "", webhookURLSecret(""))
+	assert.Equal(t, "not a url", webhookURLSecret("not a url"))
+}
+
+func TestDebugTransport_RecordsRedactedExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid token super-secret"}`))
+	}))
+	defer server.Close()
+
+	recorder := newExchangeRecorder(2)
+	transport := newDebugTransport("slack", recorder, newRedactor("/hooks/super-secret", "super-secret"), true, 20, log.WithPrefix("test"))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL+"/hooks/super-secret", "application/json", strings.NewReader(`{"text":"a long message body that will be truncated"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	exchanges := recorder.list()
+	require.Len(t, exchanges, 1)
+	assert.Equal(t, "slack", exchanges[0].Service)
+	assert.Equal(t, http.StatusBadRequest, exchanges[0].StatusCode)
+	assert.Equal(t, server.URL+redactedPlaceholder, exchanges[0].URL)
+	assert.NotContains(t, exchanges[0].ResponseBody, "super-secret")
+	assert.True(t, strings.HasSuffix(exchanges[0].RequestBody, "...(truncated)"))
+}
+
+func TestExchangeRecorder_KeepsLastN(t *testing.T) {
+	recorder := newExchangeRecorder(2)
+	recorder.record(Exchange{Service: "a"})
+	recorder.record(Exchange{Service: "b"})
+	recorder.record(Exchange{Service: "c"})
+
+	exchanges := recorder.list()
+	require.Len(t, exchanges, 2)
+	assert.Equal(t, "b", exchanges[0].Service)
+	assert.Equal(t, "c", exchanges[1].Service)
+}
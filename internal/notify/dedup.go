@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deduplicator suppresses identical events within a window and reports how many
+// were suppressed once the window closes
+type deduplicator struct {
+	window    time.Duration
+	mu        sync.Mutex
+	entries   map[string]*dedupEntry
+	onSummary func(event Event)
+}
+
+// dedupEntry tracks suppressed occurrences of an event within the current window
+type dedupEntry struct {
+	event      Event
+	suppressed int
+}
+
+// newDeduplicator creates a deduplicator that calls onSummary with the last suppressed
+// event when a window closes with suppressed occurrences
+func newDeduplicator(window time.Duration, onSummary func(event Event)) *deduplicator {
+	return &deduplicator{
+		window:    window,
+		entries:   make(map[string]*dedupEntry),
+		onSummary: onSummary,
+	}
+}
+
+// allow returns true if the event should be sent, false if it is a duplicate within the window
+func (d *deduplicator) allow(event Event) bool {
+	key := dedupKey(event)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok := d.entries[key]; ok {
+		entry.suppressed++
+		entry.event = event
+		return false
+	}
+
+	d.entries[key] = &dedupEntry{event: event}
+	time.AfterFunc(d.window, func() { d.closeWindow(key) })
+	return true
+}
+
+// closeWindow ends the window for key, emitting a summary if anything was suppressed
+func (d *deduplicator) closeWindow(key string) {
+	d.mu.Lock()
+	entry, ok := d.entries[key]
+	delete(d.entries, key)
+	d.mu.Unlock()
+
+	if !ok || entry.suppressed == 0 {
+		return
+	}
+
+	summary := entry.event
+	summary.Details = make(map[string]string, len(entry.event.Details)+1)
+	for k, v := range entry.event.Details {
+		summary.Details[k] = v
+	}
+	summary.Details["occurrences"] = fmt.Sprintf("occurred %d more times in the last %s", entry.suppressed, d.window)
+	d.onSummary(summary)
+}
+
+// dedupKey identifies identical events by type, validator, message and details
+func dedupKey(event Event) string {
+	detailKeys := make([]string, 0, len(event.Details))
+	for k := range event.Details {
+		detailKeys = append(detailKeys, k)
+	}
+	sort.Strings(detailKeys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%s", event.Type, event.ValidatorName, event.Message)
+	for _, k := range detailKeys {
+		fmt.Fprintf(&b, "|%s=%s", k, event.Details[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicator_SuppressesWithinWindow(t *testing.T) {
+	summaries := make(chan Event, 1)
+	dedup := newDeduplicator(50*time.Millisecond, func(event Event) {
+		summaries <- event
+	})
+
+	event := Event{Type: EventGossipLost, ValidatorName: "test", Message: "lost"}
+	assert.True(t, dedup.allow(event))
+	assert.False(t, dedup.allow(event))
+	assert.False(t, dedup.allow(event))
+
+	// different details are not duplicates
+	assert.True(t, dedup.allow(Event{Type: EventPeerLost, ValidatorName: "test", Details: map[string]string{"peer_name": "a"}}))
+	assert.True(t, dedup.allow(Event{Type: EventPeerLost, ValidatorName: "test", Details: map[string]string{"peer_name": "b"}}))
+
+	select {
+	case summary := <-summaries:
+		assert.Equal(t, EventGossipLost, summary.Type)
+		assert.Contains(t, summary.Details["occurrences"], "occurred 2 more times")
+	case <-time.After(time.Second):
+		t.Fatal("expected summary when window closed")
+	}
+
+	// window closed - event allowed again
+	require.Eventually(t, func() bool { return dedup.allow(event) }, time.Second, 10*time.Millisecond)
+}
+
+func TestDeduplicator_NoSummaryWithoutDuplicates(t *testing.T) {
+	called := make(chan struct{}, 1)
+	dedup := newDeduplicator(10*time.Millisecond, func(event Event) {
+		called <- struct{}{}
+	})
+
+	assert.True(t, dedup.allow(Event{Type: EventStartup}))
+
+	select {
+	case <-called:
+		t.Fatal("summary must not be sent when nothing was suppressed")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// digester batches low severity events and periodically flushes them as a single digest event
+type digester struct {
+	interval time.Duration
+	mu       sync.Mutex
+	events   []Event
+	onFlush  func(event Event)
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newDigester creates a digester that calls onFlush with a digest event every interval
+// if any events were batched
+func newDigester(interval time.Duration, onFlush func(event Event)) *digester {
+	d := &digester{
+		interval: interval,
+		onFlush:  onFlush,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// batches returns true if events of the given severity are batched rather than sent immediately
+func (d *digester) batches(severity Severity) bool {
+	return severity == SeverityInfo || severity == SeverityWarning || severity == ""
+}
+
+// add batches an event for the next digest
+func (d *digester) add(event Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, event)
+}
+
+// run flushes batched events every interval until closed
+func (d *digester) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			d.flush()
+			return
+		case <-ticker.C:
+			d.flush()
+		}
+	}
+}
+
+// close stops the digester, flushing any batched events before returning
+func (d *digester) close() {
+	d.stopOnce.Do(func() { close(d.stop) })
+	<-d.done
+}
+
+// flush sends batched events as a single digest event
+func (d *digester) flush() {
+	d.mu.Lock()
+	events := d.events
+	d.events = nil
+	d.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	d.onFlush(buildDigest(events, d.interval))
+}
+
+// buildDigest summarises events into a single digest event, taking identity fields from the latest event
+func buildDigest(events []Event, interval time.Duration) Event {
+	latest := events[len(events)-1]
+
+	severity := SeverityInfo
+	counts := make(map[EventType]int)
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		if event.Severity == SeverityWarning {
+			severity = SeverityWarning
+		}
+		counts[event.Type]++
+
+		line := fmt.Sprintf("%s [%s] %s", event.Timestamp.UTC().Format(time.TimeOnly), event.Severity, event.Type)
+		if event.Message != "" {
+			line += ": " + event.Message
+		}
+		lines = append(lines, line)
+	}
+
+	details := make(map[string]string, len(counts))
+	for eventType, count := range counts {
+		details[string(eventType)] = fmt.Sprintf("%d", count)
+	}
+
+	return Event{
+		Type:          EventDigest,
+		Severity:      severity,
+		Timestamp:     time.Now().UTC(),
+		ValidatorName: latest.ValidatorName,
+		PublicIP:      latest.PublicIP,
+		Cluster:       latest.Cluster,
+		ActivePubkey:  latest.ActivePubkey,
+		PassivePubkey: latest.PassivePubkey,
+		Message:       fmt.Sprintf("%d events in the last %s:\n%s", len(events), interval, strings.Join(lines, "\n")),
+		Details:       details,
+	}
+}
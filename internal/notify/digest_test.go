@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigester_Batches(t *testing.T) {
+	digest := newDigester(time.Hour, func(event Event) {})
+	defer digest.close()
+
+	assert.True(t, digest.batches(SeverityInfo))
+	assert.True(t, digest.batches(SeverityWarning))
+	assert.False(t, digest.batches(SeverityError))
+	assert.False(t, digest.batches(SeverityCritical))
+}
+
+func TestDigester_FlushesOnInterval(t *testing.T) {
+	digests := make(chan Event, 1)
+	digest := newDigester(20*time.Millisecond, func(event Event) {
+		digests <- event
+	})
+	defer digest.close()
+
+	digest.add(Event{Type: EventPeerDiscovered, Severity: SeverityInfo, ValidatorName: "test"})
+	digest.add(Event{Type: EventPeerDiscovered, Severity: SeverityInfo, ValidatorName: "test"})
+	digest.add(Event{Type: EventBecomingPassive, Severity: SeverityWarning, ValidatorName: "test", Message: "stepping down"})
+
+	select {
+	case event := <-digests:
+		assert.Equal(t, EventDigest, event.Type)
+		assert.Equal(t, SeverityWarning, event.Severity)
+		assert.Equal(t, "test", event.ValidatorName)
+		assert.Contains(t, event.Message, "3 events")
+		assert.Contains(t, event.Message, "becoming_passive: stepping down")
+		assert.Equal(t, "2", event.Details[string(EventPeerDiscovered)])
+		assert.Equal(t, "1", event.Details[string(EventBecomingPassive)])
+	case <-time.After(time.Second):
+		t.Fatal("expected digest to be flushed")
+	}
+}
+
+func TestDigester_CloseFlushes(t *testing.T) {
+	var flushed []Event
+	digest := newDigester(time.Hour, func(event Event) {
+		flushed = append(flushed, event)
+	})
+
+	digest.add(Event{Type: EventStartup, Severity: SeverityInfo})
+	digest.close()
+
+	require.Len(t, flushed, 1)
+	assert.Contains(t, flushed[0].Message, "1 events")
+
+	// closing again is a no-op
+	digest.close()
+	assert.Len(t, flushed, 1)
+}
@@ -5,7 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -19,12 +23,62 @@ const (
 	colorInfo     = 0x00FF00 // Green
 )
 
+// discordThreadMaxAge is how long a thread stays open for follow-up events after it was started
+const discordThreadMaxAge = 6 * time.Hour
+
+// discordThreadMaxNameLength is the longest thread name Discord accepts
+const discordThreadMaxNameLength = 100
+
+// discordThreadGroup describes which conversation an event belongs to - opening events start a thread,
+// closing events are the last posted to it
+type discordThreadGroup struct {
+	name   string
+	opens  bool
+	closes bool
+}
+
+// discordThreadGroups groups related events so they are posted to the same thread
+var discordThreadGroups = map[EventType]discordThreadGroup{
+	EventBecomingActive:            {name: "failover", opens: true},
+	EventBecameActive:              {name: "failover", closes: true},
+	EventBecomingPassive:           {name: "demotion", opens: true},
+	EventBecamePassive:             {name: "demotion", closes: true},
+	EventHealthUnhealthy:           {name: "health", opens: true},
+	EventHealthRecovered:           {name: "health", closes: true},
+	EventGossipLost:                {name: "gossip", opens: true},
+	EventGossipRecovered:           {name: "gossip", closes: true},
+	EventSnapshotRecoveryStarted:   {name: "snapshot_recovery", opens: true},
+	EventSnapshotRecoveryCompleted: {name: "snapshot_recovery", closes: true},
+	EventSnapshotRecoveryFailed:    {name: "snapshot_recovery", closes: true},
+}
+
+// DiscordRoute sends events matching its event types and severities to its own webhook -
+// empty event types or severities match all
+type DiscordRoute struct {
+	Events     []EventType
+	Severities []Severity
+	WebhookURL string
+}
+
+// matches returns whether the route applies to an event
+func (r DiscordRoute) matches(event Event) bool {
+	return (len(r.Events) == 0 || slices.Contains(r.Events, event.Type)) &&
+		(len(r.Severities) == 0 || slices.Contains(r.Severities, event.Severity))
+}
+
 // DiscordOptions contains options for creating a Discord notifier
 type DiscordOptions struct {
 	WebhookURL string
 	Username   string
 	AvatarURL  string
-	Logger     *log.Logger
+	// Mentions maps peer names to the user IDs mentioned in events involving them
+	Mentions map[string][]string
+	// Routes send matching events to other webhooks - the first matching route wins, else WebhookURL is used
+	Routes []DiscordRoute
+	// Threads posts related events into one thread - the webhook channel must be a forum channel
+	Threads   bool
+	Logger    *log.Logger
+	Transport http.RoundTripper
 }
 
 // DiscordNotifier sends notifications to Discord via webhooks
@@ -32,16 +86,43 @@ type DiscordNotifier struct {
 	webhookURL string
 	username   string
 	avatarURL  string
+	mentions   mentions
+	routes     []DiscordRoute
+	threads    bool
 	httpClient *http.Client
 	logger     *log.Logger
 	enabled    bool
+	// openThreads are the threads open for follow-up events, keyed by webhook URL and thread group
+	openThreadsMu sync.Mutex
+	openThreads   map[string]discordThread
+}
+
+// discordThread is a thread follow-up events are posted to
+type discordThread struct {
+	id        string
+	startedAt time.Time
 }
 
 // Discord webhook payload structures
 type discordPayload struct {
-	Username  string         `json:"username,omitempty"`
-	AvatarURL string         `json:"avatar_url,omitempty"`
-	Embeds    []discordEmbed `json:"embeds"`
+	Username        string                  `json:"username,omitempty"`
+	AvatarURL       string                  `json:"avatar_url,omitempty"`
+	Content         string                  `json:"content,omitempty"`
+	AllowedMentions *discordAllowedMentions `json:"allowed_mentions,omitempty"`
+	ThreadName      string                  `json:"thread_name,omitempty"`
+	Embeds          []discordEmbed          `json:"embeds"`
+}
+
+// discordMessage is the message returned by a webhook executed with wait=true
+type discordMessage struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// discordAllowedMentions limits who a message pings - mentions inside embeds never ping
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+	Users []string `json:"users,omitempty"`
 }
 
 type discordEmbed struct {
@@ -66,12 +147,16 @@ type discordFooter struct {
 // NewDiscordNotifier creates a new Discord notifier
 func NewDiscordNotifier(opts DiscordOptions) *DiscordNotifier {
 	return &DiscordNotifier{
-		webhookURL: opts.WebhookURL,
-		username:   opts.Username,
-		avatarURL:  opts.AvatarURL,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-		logger:     opts.Logger,
-		enabled:    opts.WebhookURL != "",
+		webhookURL:  opts.WebhookURL,
+		username:    opts.Username,
+		avatarURL:   opts.AvatarURL,
+		mentions:    opts.Mentions,
+		routes:      opts.Routes,
+		threads:     opts.Threads,
+		httpClient:  &http.Client{Timeout: 10 * time.Second, Transport: opts.Transport},
+		logger:      opts.Logger,
+		enabled:     opts.WebhookURL != "",
+		openThreads: make(map[string]discordThread),
 	}
 }
 
@@ -85,6 +170,21 @@ func (d *DiscordNotifier) IsEnabled() bool {
 	return d.enabled
 }
 
+// carryState takes over the threads previous has open - they are keyed by webhook URL, so threads of webhooks no
+// longer configured are never used
+func (d *DiscordNotifier) carryState(previous Notifier) {
+	old, ok := previous.(*DiscordNotifier)
+	if !ok {
+		return
+	}
+
+	old.openThreadsMu.Lock()
+	defer old.openThreadsMu.Unlock()
+	d.openThreadsMu.Lock()
+	defer d.openThreadsMu.Unlock()
+	maps.Copy(d.openThreads, old.openThreads)
+}
+
 // Send sends a notification to Discord
 func (d *DiscordNotifier) Send(ctx context.Context, event Event) error {
 	if !d.enabled {
@@ -93,7 +193,7 @@ func (d *DiscordNotifier) Send(ctx context.Context, event Event) error {
 
 	// Build embed
 	embed := discordEmbed{
-		Title:       d.getTitle(event),
+		Title:       eventTitle(event),
 		Description: d.getDescription(event),
 		Color:       d.getColor(event.Severity),
 		Timestamp:   event.Timestamp.Format(time.RFC3339),
@@ -109,61 +209,121 @@ func (d *DiscordNotifier) Send(ctx context.Context, event Event) error {
 		Embeds:    []discordEmbed{embed},
 	}
 
+	// Mention the operators of the peer involved, pinging only them
+	if userIDs := d.mentions.forEvent(event); len(userIDs) > 0 {
+		payload.Content = discordMentions(userIDs)
+		payload.AllowedMentions = &discordAllowedMentions{Parse: []string{}, Users: userIDs}
+	}
+
+	webhookURL := d.route(event)
+
+	// Post related events into one thread, starting it with the opening event
+	group, grouped := discordThreadGroups[event.Type]
+	grouped = grouped && d.threads
+	threadKey := webhookURL + " " + group.name
+	query := url.Values{}
+	if grouped {
+		if thread, ok := d.openThread(threadKey, time.Now()); ok {
+			query.Set("thread_id", thread.id)
+		} else if group.opens {
+			payload.ThreadName = discordThreadName(event)
+			query.Set("wait", "true")
+		}
+	}
+
+	message, err := d.post(ctx, webhookURL, query, payload)
+	if err != nil {
+		return err
+	}
+
+	if grouped {
+		d.openThreadsMu.Lock()
+		switch {
+		case group.closes:
+			delete(d.openThreads, threadKey)
+		case payload.ThreadName != "" && message.ChannelID != "":
+			// a thread started by a webhook message has the channel ID of the thread
+			d.openThreads[threadKey] = discordThread{id: message.ChannelID, startedAt: time.Now()}
+		}
+		d.openThreadsMu.Unlock()
+	}
+
+	return nil
+}
+
+// post executes the webhook with the given query, returning the created message when wait=true
+func (d *DiscordNotifier) post(ctx context.Context, webhookURL string, query url.Values, payload discordPayload) (discordMessage, error) {
+	var message discordMessage
+
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal discord payload: %w", err)
+		return message, fmt.Errorf("failed to marshal discord payload: %w", err)
+	}
+
+	requestURL, err := url.Parse(webhookURL)
+	if err != nil {
+		return message, fmt.Errorf("failed to parse discord webhook url: %w", redactURLError(err))
+	}
+	requestQuery := requestURL.Query()
+	for key, values := range query {
+		requestQuery[key] = values
 	}
+	requestURL.RawQuery = requestQuery.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL.String(), bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create discord request: %w", err)
+		return message, fmt.Errorf("failed to create discord request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := d.httpClient.Do(req)
+	resp, err := doRequest(d.httpClient, req, "discord webhook")
 	if err != nil {
-		return fmt.Errorf("failed to send discord notification: %w", err)
+		return message, fmt.Errorf("failed to send discord notification: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("discord webhook returned status %d", resp.StatusCode)
+	if query.Get("wait") == "true" {
+		if err := json.NewDecoder(resp.Body).Decode(&message); err != nil {
+			d.logger.Warn("failed to decode discord message - follow-up events will not be threaded", "error", err)
+		}
 	}
 
-	return nil
+	return message, nil
 }
 
-func (d *DiscordNotifier) getTitle(event Event) string {
-	switch event.Type {
-	case EventStartup:
-		return "Validator HA Started"
-	case EventShutdown:
-		return "Validator HA Stopped"
-	case EventBecomingActive:
-		return "FAILOVER: Becoming Active"
-	case EventBecameActive:
-		return "Became Active"
-	case EventBecomingPassive:
-		return "Becoming Passive"
-	case EventBecamePassive:
-		return "Became Passive"
-	case EventHealthUnhealthy:
-		return "Health Alert: Unhealthy"
-	case EventHealthRecovered:
-		return "Health Recovered"
-	case EventDelinquent:
-		return "CRITICAL: Validator Delinquent"
-	case EventGossipLost:
-		return "Lost from Gossip"
-	case EventGossipRecovered:
-		return "Gossip Recovered"
-	case EventPeerDiscovered:
-		return "Peer Discovered"
-	case EventPeerLost:
-		return "Peer Lost"
-	default:
-		return string(event.Type)
+// route returns the webhook URL an event is sent to
+func (d *DiscordNotifier) route(event Event) string {
+	for _, route := range d.routes {
+		if route.matches(event) {
+			return route.WebhookURL
+		}
+	}
+	return d.webhookURL
+}
+
+// openThread returns the thread open for key, forgetting it if it has been open too long
+func (d *DiscordNotifier) openThread(key string, now time.Time) (discordThread, bool) {
+	d.openThreadsMu.Lock()
+	defer d.openThreadsMu.Unlock()
+
+	thread, ok := d.openThreads[key]
+	if ok && now.Sub(thread.startedAt) > discordThreadMaxAge {
+		delete(d.openThreads, key)
+		return discordThread{}, false
+	}
+	return thread, ok
+}
+
+// discordThreadName returns the name of the thread an opening event starts
+func discordThreadName(event Event) string {
+	name := fmt.Sprintf("%s - %s", eventTitle(event), event.ValidatorName)
+	if !event.Timestamp.IsZero() {
+		name += " " + event.Timestamp.UTC().Format("2006-01-02 15:04")
+	}
+	if runes := []rune(name); len(runes) > discordThreadMaxNameLength {
+		name = string(runes[:discordThreadMaxNameLength])
 	}
+	return name
 }
 
 func (d *DiscordNotifier) getDescription(event Event) string {
@@ -239,6 +399,10 @@ func (d *DiscordNotifier) getFields(event Event) []discordField {
 		fields = append(fields, discordField{Name: k, Value: v, Inline: true})
 	}
 
+	if len(event.Links) > 0 {
+		fields = append(fields, discordField{Name: "Links", Value: discordLinks(event.Links)})
+	}
+
 	return fields
 }
 
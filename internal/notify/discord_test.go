@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// discordRequest is a webhook execution received by a fake Discord server
+type discordRequest struct {
+	path     string
+	threadID string
+	wait     bool
+	payload  discordPayload
+}
+
+// newTestDiscordServer records webhook executions, answering wait=true requests with a message in thread "thread-1"
+func newTestDiscordServer(t *testing.T) (*httptest.Server, func() []discordRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	requests := []discordRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := discordRequest{
+			path:     r.URL.Path,
+			threadID: r.URL.Query().Get("thread_id"),
+			wait:     r.URL.Query().Get("wait") == "true",
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request.payload))
+
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+
+		if request.wait {
+			json.NewEncoder(w).Encode(discordMessage{ID: "message-1", ChannelID: "thread-1"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []discordRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]discordRequest{}, requests...)
+	}
+}
+
+func TestDiscordNotifier_Routes(t *testing.T) {
+	server, requests := newTestDiscordServer(t)
+
+	notifier := NewDiscordNotifier(DiscordOptions{
+		WebhookURL: server.URL + "/default",
+		Routes: []DiscordRoute{
+			{Events: []EventType{EventPeerLost}, WebhookURL: server.URL + "/peers"},
+			{Severities: []Severity{SeverityCritical}, WebhookURL: server.URL + "/critical"},
+		},
+		Logger: log.New(io.Discard),
+	})
+
+	events := []Event{
+		{Type: EventPeerLost, Severity: SeverityCritical},
+		{Type: EventDelinquent, Severity: SeverityCritical},
+		{Type: EventStartup, Severity: SeverityInfo},
+	}
+	for _, event := range events {
+		require.NoError(t, notifier.Send(context.Background(), event))
+	}
+
+	received := requests()
+	require.Len(t, received, 3)
+	// the first matching route wins
+	assert.Equal(t, "/peers", received[0].path)
+	assert.Equal(t, "/critical", received[1].path)
+	assert.Equal(t, "/default", received[2].path)
+}
+
+func TestDiscordNotifier_Threads(t *testing.T) {
+	server, requests := newTestDiscordServer(t)
+
+	notifier := NewDiscordNotifier(DiscordOptions{
+		WebhookURL: server.URL,
+		Threads:    true,
+		Logger:     log.New(io.Discard),
+	})
+
+	events := []Event{
+		{Type: EventBecomingActive, ValidatorName: "validator-1", Timestamp: time.Now()},
+		{Type: EventPeerLost, ValidatorName: "validator-1"},
+		{Type: EventBecameActive, ValidatorName: "validator-1"},
+		{Type: EventBecameActive, ValidatorName: "validator-1"},
+	}
+	for _, event := range events {
+		require.NoError(t, notifier.Send(context.Background(), event))
+	}
+
+	received := requests()
+	require.Len(t, received, 4)
+
+	// the opening event starts a thread
+	assert.True(t, received[0].wait)
+	assert.Contains(t, received[0].payload.ThreadName, "validator-1")
+	assert.Empty(t, received[0].threadID)
+
+	// unrelated events are not threaded
+	assert.Empty(t, received[1].threadID)
+	assert.Empty(t, received[1].payload.ThreadName)
+
+	// the closing event is posted to the thread, which then closes
+	assert.Equal(t, "thread-1", received[2].threadID)
+	assert.Empty(t, received[3].threadID)
+	assert.Empty(t, received[3].payload.ThreadName)
+}
+
+func TestDiscordNotifier_ThreadMaxAge(t *testing.T) {
+	notifier := NewDiscordNotifier(DiscordOptions{WebhookURL: "https://discord.example/webhook", Threads: true})
+	notifier.openThreads["key"] = discordThread{id: "thread-1", startedAt: time.Now().Add(-discordThreadMaxAge - time.Minute)}
+
+	_, ok := notifier.openThread("key", time.Now())
+	assert.False(t, ok)
+	assert.Empty(t, notifier.openThreads)
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"sync"
+	"time"
+)
+
+// eventHistory keeps the last N events in memory
+type eventHistory struct {
+	mu     sync.Mutex
+	events []Event
+	size   int
+}
+
+// newEventHistory creates a history of size events, nil if size is not positive
+func newEventHistory(size int) *eventHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &eventHistory{
+		events: make([]Event, 0, size),
+		size:   size,
+	}
+}
+
+// record adds an event, dropping the oldest when full
+func (h *eventHistory) record(event Event) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.events) >= h.size {
+		h.events = h.events[1:]
+	}
+	h.events = append(h.events, event)
+}
+
+// since returns a copy of the events with a timestamp after since, oldest first
+func (h *eventHistory) since(since time.Time) []Event {
+	events := []Event{}
+	if h == nil {
+		return events
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, event := range h.events {
+		if event.Timestamp.After(since) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
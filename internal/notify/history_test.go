@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHistory(t *testing.T) {
+	history := newEventHistory(2)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	history.record(Event{Type: EventStartup, Timestamp: start})
+	history.record(Event{Type: EventPeerLost, Timestamp: start.Add(time.Minute)})
+	history.record(Event{Type: EventPeerDiscovered, Timestamp: start.Add(2 * time.Minute)})
+
+	// oldest dropped when full
+	events := history.since(time.Time{})
+	require.Len(t, events, 2)
+	assert.Equal(t, EventPeerLost, events[0].Type)
+	assert.Equal(t, EventPeerDiscovered, events[1].Type)
+
+	// since is exclusive
+	events = history.since(start.Add(time.Minute))
+	require.Len(t, events, 1)
+	assert.Equal(t, EventPeerDiscovered, events[0].Type)
+
+	// a zero size history keeps nothing
+	assert.Nil(t, newEventHistory(0))
+	assert.Empty(t, newEventHistory(0).since(time.Time{}))
+}
+
+func TestManager_EventsWhenDisabled(t *testing.T) {
+	manager := NewManager(ManagerOptions{
+		Config:        &config.NotificationConfig{Enabled: false, HistorySize: 10},
+		ValidatorName: "validator-1",
+	})
+	manager.Notify(Event{Type: EventDelinquent, Message: "delinquent"})
+
+	events := manager.Events(time.Time{})
+	require.Len(t, events, 1)
+	assert.Equal(t, EventDelinquent, events[0].Type)
+	assert.Equal(t, SeverityCritical, events[0].Severity)
+	assert.Empty(t, manager.Events(events[0].Timestamp))
+}
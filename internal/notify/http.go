@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+const (
+	// maxErrorBodyBytes is how much of an error response body is included in errors
+	maxErrorBodyBytes = 512
+	// maxRateLimitRetries is how many times a rate limited request is retried after its Retry-After delay
+	maxRateLimitRetries = 2
+)
+
+// statusError is returned when a service responds with a non-2xx status
+type statusError struct {
+	target     string
+	statusCode int
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	msg := fmt.Sprintf("%s returned status %d", e.target, e.statusCode)
+	if e.body != "" {
+		msg += ": " + e.body
+	}
+	if e.retryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.retryAfter)
+	}
+	return msg
+}
+
+// doRequest sends req to target, e.g. "discord webhook", and returns a *statusError carrying the truncated response
+// body for any non-2xx response. Rate limited (429) requests are retried after their Retry-After delay when it fits
+// within the request's context deadline. The caller closes the body of a successful response.
+func doRequest(client *http.Client, req *http.Request, target string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		statusErr, hasRetryAfter := newStatusError(resp, target)
+		if resp.StatusCode != http.StatusTooManyRequests || !hasRetryAfter || attempt >= maxRateLimitRetries {
+			return nil, statusErr
+		}
+
+		ctx := req.Context()
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < statusErr.retryAfter {
+			return nil, statusErr
+		}
+
+		// the body was consumed by the first attempt - retry with a fresh copy
+		retry := req.Clone(ctx)
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return nil, statusErr
+			}
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, statusErr
+			}
+		}
+		req = retry
+
+		timer := time.NewTimer(statusErr.retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, statusErr
+		case <-timer.C:
+		}
+	}
+}
+
+// newStatusError reads and closes a non-2xx response, returning its error and whether it said when to retry
+func newStatusError(resp *http.Response, target string) (*statusError, bool) {
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4*maxErrorBodyBytes))
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), body, time.Now())
+
+	trimmed := strings.TrimSpace(string(body))
+	if len(trimmed) > maxErrorBodyBytes {
+		trimmed = trimmed[:maxErrorBodyBytes] + "..."
+	}
+
+	return &statusError{
+		target:     target,
+		statusCode: resp.StatusCode,
+		body:       trimmed,
+		retryAfter: retryAfter,
+	}, hasRetryAfter
+}
+
+// parseRetryAfter returns the retry delay from a Retry-After header, in seconds or as an HTTP date, falling back to
+// the retry_after field Discord and Telegram include in their rate limit response bodies
+func parseRetryAfter(header string, body []byte, now time.Time) (time.Duration, bool) {
+	if header != "" {
+		if seconds, err := strconv.ParseFloat(header, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		if at, err := http.ParseTime(header); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	var rateLimited struct {
+		// Discord
+		RetryAfter *float64 `json:"retry_after"`
+		// Telegram
+		Parameters struct {
+			RetryAfter *float64 `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(body, &rateLimited); err != nil {
+		return 0, false
+	}
+	for _, seconds := range []*float64{rateLimited.RetryAfter, rateLimited.Parameters.RetryAfter} {
+		if seconds != nil && *seconds >= 0 {
+			return time.Duration(*seconds * float64(time.Second)), true
+		}
+	}
+
+	return 0, false
+}
+
+// TLSTransport returns a transport using a notifier's TLS options, or nil for the default transport when none are
+// set. Options that fail to load, already reported by config validation, are logged and the default transport used.
+func TLSTransport(service string, tlsConfig config.NotificationTLS, logger *log.Logger) http.RoundTripper {
+	clientConfig, err := tlsConfig.ClientConfig()
+	if err != nil {
+		logger.Error("failed to load notifier tls config - using default tls", "service", service, "error", err)
+		return nil
+	}
+	if clientConfig == nil {
+		return nil
+	}
+	return newTLSTransport(clientConfig)
+}
+
+// newTLSTransport returns a copy of the default transport using clientConfig
+func newTLSTransport(clientConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = clientConfig
+	return transport
+}
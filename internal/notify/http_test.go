@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoRequest_RetriesAfterRateLimit(t *testing.T) {
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(`{"content":"hello"}`))
+	require.NoError(t, err)
+
+	resp, err := doRequest(server.Client(), req, "discord webhook")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{`{"content":"hello"}`, `{"content":"hello"}`}, bodies, "retry should resend the body")
+}
+
+func TestDoRequest_RetryAfterPastDeadline(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 30","parameters":{"retry_after":30}}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = doRequest(server.Client(), req, "telegram API")
+	require.Error(t, err)
+	assert.Equal(t, 1, calls, "should not wait past the request deadline")
+	assert.Contains(t, err.Error(), "telegram API returned status 429: ")
+	assert.Contains(t, err.Error(), "Too Many Requests")
+	assert.Contains(t, err.Error(), "(retry after 30s)")
+}
+
+func TestDoRequest_TruncatesErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(strings.Repeat("x", 2*maxErrorBodyBytes)))
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = doRequest(server.Client(), req, "slack webhook")
+	require.Error(t, err)
+	assert.Equal(t, "slack webhook returned status 400: "+strings.Repeat("x", maxErrorBodyBytes)+"...", err.Error())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		header   string
+		body     string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "seconds header", header: "5", expected: 5 * time.Second, ok: true},
+		{name: "http date header", header: now.Add(10 * time.Second).Format(http.TimeFormat), expected: 10 * time.Second, ok: true},
+		{name: "discord body", body: `{"message":"You are being rate limited.","retry_after":1.5,"global":false}`, expected: 1500 * time.Millisecond, ok: true},
+		{name: "telegram body", body: `{"ok":false,"parameters":{"retry_after":3}}`, expected: 3 * time.Second, ok: true},
+		{name: "missing", body: "rate limited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryAfter, ok := parseRetryAfter(tt.header, []byte(tt.body), now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, retryAfter)
+		})
+	}
+}
+
+func TestTLSTransport_TrustsCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	assert.Nil(t, TLSTransport("slack", config.NotificationTLS{}, log.New(io.Discard)))
+
+	// the server's certificate is not trusted by default
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	_, err = doRequest(&http.Client{}, req, "slack webhook")
+	require.Error(t, err)
+
+	client := &http.Client{Transport: TLSTransport("slack", config.NotificationTLS{CAFile: caFile}, log.New(io.Discard))}
+	req, err = http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := doRequest(client, req, "slack webhook")
+	require.NoError(t, err)
+	resp.Body.Close()
+}
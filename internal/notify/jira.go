@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// jiraProvider opens tickets as Jira issues with the REST API v2
+type jiraProvider struct {
+	client            *ticketClient
+	project           string
+	issueType         string
+	resolveTransition string
+}
+
+type jiraIssue struct {
+	Key string `json:"key"`
+}
+
+type jiraTransitions struct {
+	Transitions []jiraTransition `json:"transitions"`
+}
+
+type jiraTransition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// open creates an issue, returning its key
+func (j *jiraProvider) open(ctx context.Context, summary string, description string, fields map[string]any) (string, error) {
+	issueFields := map[string]any{}
+	for name, value := range fields {
+		issueFields[name] = value
+	}
+	issueFields["project"] = map[string]string{"key": j.project}
+	issueFields["issuetype"] = map[string]string{"name": j.issueType}
+	issueFields["summary"] = summary
+	issueFields["description"] = description
+
+	var issue jiraIssue
+	if err := j.client.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": issueFields}, &issue); err != nil {
+		return "", err
+	}
+	if issue.Key == "" {
+		return "", fmt.Errorf("jira API returned no issue key")
+	}
+
+	return issue.Key, nil
+}
+
+// comment adds a comment to an issue
+func (j *jiraProvider) comment(ctx context.Context, id string, text string) error {
+	return j.client.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(id)+"/comment", map[string]string{"body": text}, nil)
+}
+
+// resolve comments on an issue and applies the resolve transition
+func (j *jiraProvider) resolve(ctx context.Context, id string, text string) error {
+	if err := j.comment(ctx, id, text); err != nil {
+		return err
+	}
+
+	path := "/rest/api/2/issue/" + url.PathEscape(id) + "/transitions"
+	var transitions jiraTransitions
+	if err := j.client.do(ctx, http.MethodGet, path, nil, &transitions); err != nil {
+		return err
+	}
+
+	for _, transition := range transitions.Transitions {
+		if strings.EqualFold(transition.Name, j.resolveTransition) {
+			return j.client.do(ctx, http.MethodPost, path, map[string]any{"transition": map[string]string{"id": transition.ID}}, nil)
+		}
+	}
+
+	return fmt.Errorf("jira transition %s is not available for %s", j.resolveTransition, id)
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// Link is a named URL sent with a notification
+type Link struct {
+	Name string
+	URL  string
+}
+
+// linkTemplate is a parsed notifications.links entry
+type linkTemplate struct {
+	name   string
+	url    *template.Template
+	events []EventType
+}
+
+// eventLinks renders the configured links for events
+type eventLinks struct {
+	templates []linkTemplate
+}
+
+// newEventLinks parses the configured link URL templates
+func newEventLinks(cfg []config.NotificationLink) (*eventLinks, error) {
+	l := &eventLinks{templates: make([]linkTemplate, 0, len(cfg))}
+
+	for i, linkCfg := range cfg {
+		// missing details render empty so links to them are dropped rather than sent broken
+		tmpl, err := template.New(linkCfg.Name).Option("missingkey=zero").Parse(linkCfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notifications.links[%d].url: %w", i, err)
+		}
+		l.templates = append(l.templates, linkTemplate{name: linkCfg.Name, url: tmpl, events: eventTypes(linkCfg.Events)})
+	}
+
+	return l, nil
+}
+
+// render sets the event's links, skipping links limited to other event types and links whose URL
+// renders empty. Links that fail to execute are skipped and their errors returned.
+func (l *eventLinks) render(event Event) (Event, error) {
+	links := []Link{}
+	var errs []error
+
+	for _, link := range l.templates {
+		if len(link.events) > 0 && !slices.Contains(link.events, event.Type) {
+			continue
+		}
+
+		url, err := executeTemplate(link.url, event)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if url = strings.TrimSpace(url); url == "" {
+			continue
+		}
+
+		links = append(links, Link{Name: link.name, URL: url})
+	}
+
+	event.Links = links
+	return event, errors.Join(errs...)
+}
+
+// discordLinks formats links as markdown for a Discord embed field
+func discordLinks(links []Link) string {
+	formatted := make([]string, 0, len(links))
+	for _, link := range links {
+		formatted = append(formatted, fmt.Sprintf("[%s](%s)", link.Name, link.URL))
+	}
+	return strings.Join(formatted, " | ")
+}
+
+// slackLinks formats links as mrkdwn for a Slack attachment field
+func slackLinks(links []Link) string {
+	formatted := make([]string, 0, len(links))
+	for _, link := range links {
+		formatted = append(formatted, fmt.Sprintf("<%s|%s>", link.URL, link.Name))
+	}
+	return strings.Join(formatted, " | ")
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLinks_Render(t *testing.T) {
+	links, err := newEventLinks([]config.NotificationLink{
+		{Name: "Explorer", URL: "https://solscan.io/account/{{ .ActivePubkey }}"},
+		{Name: "Runbook", URL: "https://wiki.example.com/failover", Events: []string{"becoming_active"}},
+		{Name: "Peer", URL: "{{ with .Details.peer_name }}https://grafana.example.com/d/ha?var-peer={{ . }}{{ end }}"},
+	})
+	require.NoError(t, err)
+
+	event, err := links.render(Event{Type: EventBecomingActive, ActivePubkey: "ActivePubkey111"})
+	require.NoError(t, err)
+	assert.Equal(t, []Link{
+		{Name: "Explorer", URL: "https://solscan.io/account/ActivePubkey111"},
+		{Name: "Runbook", URL: "https://wiki.example.com/failover"},
+	}, event.Links)
+
+	// links limited to other events are skipped and links rendering empty are dropped
+	event, err = links.render(Event{Type: EventPeerLost, ActivePubkey: "ActivePubkey111", Details: map[string]string{"peer_name": "validator-2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Link{
+		{Name: "Explorer", URL: "https://solscan.io/account/ActivePubkey111"},
+		{Name: "Peer", URL: "https://grafana.example.com/d/ha?var-peer=validator-2"},
+	}, event.Links)
+}
+
+func TestLinkFormatting(t *testing.T) {
+	links := []Link{{Name: "Grafana", URL: "https://grafana.example.com"}, {Name: "Runbook", URL: "https://wiki.example.com"}}
+	assert.Equal(t, "[Grafana](https://grafana.example.com) | [Runbook](https://wiki.example.com)", discordLinks(links))
+	assert.Equal(t, "<https://grafana.example.com|Grafana> | <https://wiki.example.com|Runbook>", slackLinks(links))
+}
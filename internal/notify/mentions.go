@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// mentions maps peer names to the handles of their operators on a single service
+type mentions map[string][]string
+
+// newMentions selects a single service's handles from notifications.mentions
+func newMentions(cfg map[string]config.NotificationMentions, handles func(config.NotificationMentions) []string) mentions {
+	m := make(mentions, len(cfg))
+	for peerName, peerMentions := range cfg {
+		if peerHandles := handles(peerMentions); len(peerHandles) > 0 {
+			m[peerName] = peerHandles
+		}
+	}
+	return m
+}
+
+// forEvent returns the handles to mention for an event - the operators of the peer it involves,
+// or of the validator itself when it involves no peer. Nobody is mentioned once the incident is acknowledged.
+func (m mentions) forEvent(event Event) []string {
+	if isAcknowledged(event) {
+		return nil
+	}
+	if peerName, ok := event.Details["peer_name"]; ok {
+		return m[peerName]
+	}
+	return m[event.ValidatorName]
+}
+
+// discordMentions formats discord user IDs as mentions
+func discordMentions(userIDs []string) string {
+	formatted := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		formatted = append(formatted, fmt.Sprintf("<@%s>", userID))
+	}
+	return strings.Join(formatted, " ")
+}
+
+// slackMentions formats slack member IDs as mentions
+func slackMentions(memberIDs []string) string {
+	formatted := make([]string, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		formatted = append(formatted, fmt.Sprintf("<@%s>", memberID))
+	}
+	return strings.Join(formatted, " ")
+}
+
+// telegramMentions formats telegram usernames as mentions, escaping underscores for markdown parse modes
+func telegramMentions(usernames []string, parseMode string) string {
+	formatted := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		mention := "@" + strings.TrimPrefix(username, "@")
+		if parseMode != "HTML" {
+			mention = strings.ReplaceAll(mention, "_", `\_`)
+		}
+		formatted = append(formatted, mention)
+	}
+	return strings.Join(formatted, " ")
+}
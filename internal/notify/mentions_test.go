@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMentions_ForEvent(t *testing.T) {
+	m := newMentions(map[string]config.NotificationMentions{
+		"validator-1": {Discord: []string{"111"}},
+		"validator-2": {Discord: []string{"222", "333"}},
+		"validator-3": {Slack: []string{"U01ABCDEF"}},
+	}, func(m config.NotificationMentions) []string { return m.Discord })
+
+	// events involving a peer mention its operators
+	assert.Equal(t, []string{"222", "333"}, m.forEvent(Event{
+		Type:          EventPeerLost,
+		ValidatorName: "validator-1",
+		Details:       map[string]string{"peer_name": "validator-2"},
+	}))
+
+	// other events mention the validator's own operators
+	assert.Equal(t, []string{"111"}, m.forEvent(Event{Type: EventBecomingActive, ValidatorName: "validator-1"}))
+
+	// peers without handles for the service are not mentioned
+	assert.Empty(t, m.forEvent(Event{Type: EventStartup, ValidatorName: "validator-3"}))
+}
+
+func TestTelegramMentions(t *testing.T) {
+	assert.Equal(t, "@validator_ops @backup", telegramMentions([]string{"validator_ops", "@backup"}, "HTML"))
+	assert.Equal(t, `@validator\_ops`, telegramMentions([]string{"@validator_ops"}, "Markdown"))
+}
+
+func TestDiscordNotifier_Mentions(t *testing.T) {
+	var payload discordPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload = discordPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewDiscordNotifier(DiscordOptions{
+		WebhookURL: server.URL,
+		Mentions:   map[string][]string{"validator-2": {"222"}},
+	})
+
+	err := notifier.Send(context.Background(), Event{
+		Type:          EventPeerLost,
+		ValidatorName: "validator-1",
+		Details:       map[string]string{"peer_name": "validator-2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "<@222>", payload.Content)
+	require.NotNil(t, payload.AllowedMentions)
+	assert.Equal(t, []string{"222"}, payload.AllowedMentions.Users)
+
+	// no mentions for events not involving a mapped peer
+	err = notifier.Send(context.Background(), Event{Type: EventStartup, ValidatorName: "validator-1"})
+	require.NoError(t, err)
+	assert.Empty(t, payload.Content)
+	assert.Nil(t, payload.AllowedMentions)
+}
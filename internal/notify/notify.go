@@ -3,6 +3,10 @@ package notify
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -26,6 +30,32 @@ const (
 	EventGossipRecovered EventType = "gossip_recovered"
 	EventPeerDiscovered  EventType = "peer_discovered"
 	EventPeerLost        EventType = "peer_lost"
+	EventDigest          EventType = "digest"
+	EventRPCDemoted      EventType = "rpc_endpoint_demoted"
+
+	EventSnapshotRecoveryStarted   EventType = "snapshot_recovery_started"
+	EventSnapshotRecoveryCompleted EventType = "snapshot_recovery_completed"
+	EventSnapshotRecoveryFailed    EventType = "snapshot_recovery_failed"
+
+	EventQuietPeriodEnded EventType = "quiet_period_ended"
+
+	EventVoteAccountChanged EventType = "vote_account_changed"
+
+	EventActiveIdentityOnPassive EventType = "active_identity_on_passive"
+
+	EventCircuitBreakerTripped EventType = "circuit_breaker_tripped"
+	EventCircuitBreakerReset   EventType = "circuit_breaker_reset"
+
+	EventHeartbeat EventType = "heartbeat"
+
+	EventAcknowledged EventType = "acknowledged"
+
+	EventManualRoleChange   EventType = "manual_role_change"
+	EventScheduledFailover  EventType = "scheduled_failover"
+	EventVoteCreditsLagging EventType = "vote_credits_lagging"
+	EventSkipRateHigh       EventType = "skip_rate_high"
+	EventFailback           EventType = "failback"
+	EventFlappingDetected   EventType = "flapping_detected"
 )
 
 // Severity levels for notifications
@@ -48,8 +78,12 @@ type Event struct {
 	Cluster       string
 	ActivePubkey  string
 	PassivePubkey string
-	Message       string
-	Details       map[string]string
+	// Title overrides the default title for the event type - set from notifications.templates
+	Title   string
+	Message string
+	Details map[string]string
+	// Links are added to Discord and Slack notifications - set from notifications.links
+	Links []Link
 }
 
 // Notifier interface for all notification services
@@ -62,12 +96,52 @@ type Notifier interface {
 	IsEnabled() bool
 }
 
+// statefulNotifier is implemented by notifiers tracking what they opened, such as tickets and threads - a reload
+// carries it over to the notifier replacing them, so follow-up events still find it
+type statefulNotifier interface {
+	// carryState takes over the state of previous, the notifier of the same name being replaced
+	carryState(previous Notifier)
+}
+
+// incidentResolver is implemented by notifiers resolving an incident they opened when a recovery event arrives -
+// those events are never batched into the digest, or the incident would never be resolved
+type incidentResolver interface {
+	// Resolves returns true if events of eventType resolve an incident
+	Resolves(eventType EventType) bool
+}
+
 // Manager coordinates all notification services
 type Manager struct {
+	// mu guards the settings replaced by Reload - notifiers, eventFilter, severityOverrides, routes, templates and links
+	mu          sync.RWMutex
 	notifiers   []Notifier
 	logger      *log.Logger
 	enabled     bool
 	eventFilter config.NotificationEvents
+	spool       *Spool
+	replaying   atomic.Bool
+	replayer    *spoolReplayer
+	exchanges   *exchangeRecorder
+	dedup       *deduplicator
+	digest      *digester
+	quiet       *quietPeriod
+	templates   *messageTemplates
+	links       *eventLinks
+	// severityOverrides replaces the severity events are sent with, by event type
+	severityOverrides map[EventType]Severity
+	// routes limits the notifiers events are sent to, by severity - unrouted severities go to every notifier
+	routes map[Severity][]string
+	stats  *statsCounter
+	// subscribers receive every event, whether or not notifications are enabled for it
+	subscribers *Subscribers
+	// history keeps the most recent events, whether or not notifications are enabled for them
+	history *eventHistory
+	// pool handles events sent with NotifyAsync
+	pool *workerPool
+	// timeout is the maximum time each notifier has to send an event
+	timeout time.Duration
+	// acks are the failovers acknowledged by operators
+	acks *acknowledgements
 }
 
 // ManagerOptions contains options for creating a new Manager
@@ -76,6 +150,8 @@ type ManagerOptions struct {
 	ValidatorName string
 	PublicIP      string
 	Cluster       string
+	// Subscribers, if set, receive every event - see Subscribers
+	Subscribers *Subscribers
 }
 
 // NewManager creates a notification manager from config
@@ -84,74 +160,372 @@ func NewManager(opts ManagerOptions) *Manager {
 
 	if !opts.Config.Enabled {
 		logger.Debug("notifications disabled")
-		return &Manager{
-			enabled: false,
-			logger:  logger,
+		manager := &Manager{
+			enabled:     false,
+			logger:      logger,
+			subscribers: opts.Subscribers,
+			history:     newEventHistory(opts.Config.HistorySize),
+			acks:        newAcknowledgements(),
 		}
+		manager.pool = newWorkerPool(opts.Config.Workers, opts.Config.QueueSize, manager.Notify)
+		return manager
+	}
+
+	// Record notifier HTTP exchanges when debugging
+	var exchanges *exchangeRecorder
+	if opts.Config.Debug.Enabled {
+		exchanges = newExchangeRecorder(opts.Config.Debug.HistorySize)
+		logger.Warn("notification debug logging enabled", "capture_bodies", opts.Config.Debug.CaptureBodies)
 	}
 
-	notifiers := make([]Notifier, 0)
+	manager := &Manager{
+		logger:      logger,
+		enabled:     true,
+		exchanges:   exchanges,
+		stats:       newStatsCounter(),
+		subscribers: opts.Subscribers,
+		history:     newEventHistory(opts.Config.HistorySize),
+		timeout:     opts.Config.TimeoutDuration,
+		acks:        newAcknowledgements(),
+	}
+	manager.pool = newWorkerPool(opts.Config.Workers, opts.Config.QueueSize, manager.Notify)
+	manager.configure(opts.Config)
+
+	logger.Info("notification manager initialized", "services", len(manager.notifiers))
+
+	// Batch info/warning events into a periodic digest if configured
+	if opts.Config.Digest.Enabled {
+		manager.digest = newDigester(opts.Config.Digest.IntervalDuration, manager.deliver)
+		logger.Debug("notification digest enabled", "interval", opts.Config.Digest.IntervalDuration)
+	}
+
+	// Hold back non-critical events during quiet hours and maintenance mode, summarising them when it ends
+	manager.quiet = newQuietPeriod(opts.Config.QuietHours, func(summary Event) {
+		summary.ValidatorName = opts.ValidatorName
+		summary.PublicIP = opts.PublicIP
+		summary.Cluster = opts.Cluster
+		manager.Notify(summary)
+	})
+	if len(opts.Config.QuietHours.Windows) > 0 || opts.Config.QuietHours.MaintenanceFile != "" {
+		logger.Debug("notification quiet hours enabled",
+			"mode", opts.Config.QuietHours.Mode,
+			"windows", len(opts.Config.QuietHours.Windows),
+			"maintenance_file", opts.Config.QuietHours.MaintenanceFile,
+		)
+	}
+
+	// Suppress identical events within the dedup window if configured
+	if opts.Config.DedupWindowDuration > 0 {
+		manager.dedup = newDeduplicator(opts.Config.DedupWindowDuration, manager.dispatch)
+		logger.Debug("notification deduplication enabled", "window", opts.Config.DedupWindowDuration)
+	}
+
+	// Create spool for events that fail delivery if configured
+	if opts.Config.SpoolDir != "" {
+		spool, err := NewSpool(SpoolOptions{
+			Dir:          opts.Config.SpoolDir,
+			MaxAge:       opts.Config.SpoolMaxAgeDuration,
+			MaxSizeBytes: opts.Config.SpoolMaxSizeBytes,
+			Logger:       logger,
+		})
+		if err != nil {
+			logger.Error("failed to create notification spool - failed notifications will not be replayed", "error", err)
+		} else {
+			manager.spool = spool
+			if opts.Config.SpoolReplayIntervalDuration > 0 {
+				manager.replayer = newSpoolReplayer(opts.Config.SpoolReplayIntervalDuration, manager.replaySpool)
+			}
+			logger.Debug("notification spool enabled",
+				"dir", opts.Config.SpoolDir,
+				"spooled", spool.Len(),
+				"replay_interval", opts.Config.SpoolReplayIntervalDuration,
+			)
+		}
+	}
+
+	return manager
+}
+
+// Reload replaces the notifiers, event filter, severity overrides, routing, templates and links with those in cfg
+// without restarting - queued and batched events, the spool, history, acknowledgements, and open tickets and
+// Discord threads are kept. The other
+// notification settings, and enabling notifications if they were disabled at startup, need a restart.
+func (m *Manager) Reload(cfg *config.NotificationConfig) error {
+	if !m.enabled {
+		if cfg.Enabled {
+			return fmt.Errorf("notifications were disabled at startup - restart to enable them")
+		}
+		return nil
+	}
+
+	m.configure(cfg)
+	m.logger.Info("notification settings reloaded", "services", len(m.targets(nil)))
+	return nil
+}
+
+// configure sets the reloadable settings from cfg
+func (m *Manager) configure(cfg *config.NotificationConfig) {
+	notifiers, pluginNames := newNotifiers(cfg, m.exchanges, m.logger)
+
+	severityOverrides := make(map[EventType]Severity, len(cfg.SeverityOverrides))
+	routes := make(map[Severity][]string, len(cfg.Routing))
+	var templates *messageTemplates
+	var links *eventLinks
+
+	// Override event severities if configured
+	for eventType, severity := range cfg.SeverityOverrides {
+		severityOverrides[EventType(eventType)] = Severity(severity)
+		m.logger.Debug("notification severity overridden", "event", eventType, "severity", severity)
+	}
+
+	// Route events to notifiers by severity if configured
+	for severity, services := range cfg.Routing {
+		routes[Severity(severity)] = pluginRoutes(services, pluginNames)
+		m.logger.Debug("notification severity routed", "severity", severity, "services", services)
+	}
+
+	// Render custom titles and descriptions if configured
+	if len(cfg.Templates) > 0 {
+		parsed, err := newMessageTemplates(cfg.Templates)
+		if err != nil {
+			m.logger.Error("failed to parse notification templates - default messages will be sent", "error", err)
+		} else {
+			templates = parsed
+			m.logger.Debug("notification templates enabled", "templates", len(cfg.Templates))
+		}
+	}
+
+	// Add links to dashboards, runbooks and explorers if configured
+	if len(cfg.Links) > 0 {
+		parsed, err := newEventLinks(cfg.Links)
+		if err != nil {
+			m.logger.Error("failed to parse notification links - notifications will be sent without links", "error", err)
+		} else {
+			links = parsed
+			m.logger.Debug("notification links enabled", "links", len(cfg.Links))
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	carryNotifierState(m.notifiers, notifiers)
+	m.notifiers = notifiers
+	m.eventFilter = cfg.Events
+	m.severityOverrides = severityOverrides
+	m.routes = routes
+	m.templates = templates
+	m.links = links
+}
+
+// carryNotifierState carries the state of each previous notifier over to the notifier of the same name replacing it
+func carryNotifierState(previous []Notifier, notifiers []Notifier) {
+	for _, notifier := range notifiers {
+		stateful, ok := notifier.(statefulNotifier)
+		if !ok {
+			continue
+		}
+		for _, old := range previous {
+			if old.Name() == notifier.Name() {
+				stateful.carryState(old)
+				break
+			}
+		}
+	}
+}
+
+// newNotifiers creates the notifiers enabled in cfg, recording their HTTP exchanges in exchanges if set, and
+// returns them with the names of the plugins among them
+func newNotifiers(cfg *config.NotificationConfig, exchanges *exchangeRecorder, logger *log.Logger) (notifiers []Notifier, pluginNames []string) {
+	notifiers = make([]Notifier, 0)
+
+	// Wrap notifier HTTP transports with exchange logging when debugging
+	transport := func(service string, tlsConfig config.NotificationTLS) http.RoundTripper {
+		return TLSTransport(service, tlsConfig, logger)
+	}
+	if exchanges != nil {
+		secretValues := []string{
+			webhookURLSecret(cfg.Discord.WebhookURL),
+			webhookURLSecret(cfg.Slack.WebhookURL),
+			cfg.Telegram.BotToken,
+			cfg.PagerDuty.RoutingKey,
+			cfg.Tickets.Token,
+		}
+		for _, route := range cfg.Discord.Routes {
+			secretValues = append(secretValues, webhookURLSecret(route.WebhookURL))
+		}
+		secrets := newRedactor(secretValues...)
+		transport = func(service string, tlsConfig config.NotificationTLS) http.RoundTripper {
+			debug := newDebugTransport(service, exchanges, secrets, cfg.Debug.CaptureBodies, cfg.Debug.MaxBodyBytes, logger)
+			if next := TLSTransport(service, tlsConfig, logger); next != nil {
+				debug.next = next
+			}
+			return debug
+		}
+	}
 
 	// Create Discord notifier if enabled
-	if opts.Config.Discord.Enabled {
+	if cfg.Discord.Enabled {
 		notifiers = append(notifiers, NewDiscordNotifier(DiscordOptions{
-			WebhookURL: opts.Config.Discord.WebhookURL,
-			Username:   opts.Config.Discord.Username,
-			AvatarURL:  opts.Config.Discord.AvatarURL,
+			WebhookURL: cfg.Discord.WebhookURL,
+			Username:   cfg.Discord.Username,
+			AvatarURL:  cfg.Discord.AvatarURL,
+			Mentions:   newMentions(cfg.Mentions, func(m config.NotificationMentions) []string { return m.Discord }),
+			Routes:     discordRoutes(cfg.Discord.Routes),
+			Threads:    cfg.Discord.Threads,
 			Logger:     logger,
+			Transport:  transport("discord", cfg.Discord.TLS),
 		}))
 		logger.Debug("discord notifications enabled")
 	}
 
 	// Create Telegram notifier if enabled
-	if opts.Config.Telegram.Enabled {
+	if cfg.Telegram.Enabled {
 		notifiers = append(notifiers, NewTelegramNotifier(TelegramOptions{
-			BotToken:  opts.Config.Telegram.BotToken,
-			ChatID:    opts.Config.Telegram.ChatID,
-			ParseMode: opts.Config.Telegram.ParseMode,
+			BotToken:  cfg.Telegram.BotToken,
+			ChatID:    cfg.Telegram.ChatID,
+			ParseMode: cfg.Telegram.ParseMode,
+			Mentions:  newMentions(cfg.Mentions, func(m config.NotificationMentions) []string { return m.Telegram }),
 			Logger:    logger,
+			Transport: transport("telegram", cfg.Telegram.TLS),
 		}))
 		logger.Debug("telegram notifications enabled")
 	}
 
 	// Create Slack notifier if enabled
-	if opts.Config.Slack.Enabled {
+	if cfg.Slack.Enabled {
 		notifiers = append(notifiers, NewSlackNotifier(SlackOptions{
-			WebhookURL: opts.Config.Slack.WebhookURL,
-			Channel:    opts.Config.Slack.Channel,
-			Username:   opts.Config.Slack.Username,
-			IconEmoji:  opts.Config.Slack.IconEmoji,
-			Logger:     logger,
+			WebhookURL:      cfg.Slack.WebhookURL,
+			Channel:         cfg.Slack.Channel,
+			Username:        cfg.Slack.Username,
+			IconEmoji:       cfg.Slack.IconEmoji,
+			Mentions:        newMentions(cfg.Mentions, func(m config.NotificationMentions) []string { return m.Slack }),
+			Actions:         slackButtonActions(cfg.Slack.Interactive),
+			SilenceDuration: cfg.Slack.Interactive.SilenceDuration,
+			Logger:          logger,
+			Transport:       transport("slack", cfg.Slack.TLS),
 		}))
 		logger.Debug("slack notifications enabled")
 	}
 
 	// Create PagerDuty notifier if enabled
-	if opts.Config.PagerDuty.Enabled {
+	if cfg.PagerDuty.Enabled {
 		notifiers = append(notifiers, NewPagerDutyNotifier(PagerDutyOptions{
-			RoutingKey: opts.Config.PagerDuty.RoutingKey,
-			Logger:     logger,
+			RoutingKey:    cfg.PagerDuty.RoutingKey,
+			ChangeEvents:  eventTypes(cfg.PagerDuty.ChangeEvents),
+			ResolveEvents: pagerDutyResolveEvents(cfg.PagerDuty.EventActions),
+			Logger:        logger,
+			Transport:     transport("pagerduty", cfg.PagerDuty.TLS),
 		}))
 		logger.Debug("pagerduty notifications enabled")
 	}
 
-	logger.Info("notification manager initialized", "services", len(notifiers))
+	// Create tickets notifier if enabled
+	if cfg.Tickets.Enabled {
+		notifiers = append(notifiers, NewTicketsNotifier(TicketsOptions{
+			Provider:          cfg.Tickets.Provider,
+			URL:               cfg.Tickets.URL,
+			Username:          cfg.Tickets.Username,
+			Token:             cfg.Tickets.Token,
+			Project:           cfg.Tickets.Project,
+			IssueType:         cfg.Tickets.IssueType,
+			Queue:             cfg.Tickets.Queue,
+			Table:             cfg.Tickets.Table,
+			Events:            eventTypes(cfg.Tickets.Events),
+			Fields:            cfg.Tickets.Fields,
+			ResolveTransition: cfg.Tickets.ResolveTransition,
+			ResolveFields:     cfg.Tickets.ResolveFields,
+			Logger:            logger,
+			Transport:         transport("tickets", cfg.Tickets.TLS),
+		}))
+		logger.Debug("ticket notifications enabled", "provider", cfg.Tickets.Provider)
+	}
 
-	return &Manager{
-		notifiers:   notifiers,
-		logger:      logger,
-		enabled:     true,
-		eventFilter: opts.Config.Events,
+	// Create a notifier for each plugin discovered if enabled
+	pluginNames = []string{}
+	if cfg.Plugins.Enabled {
+		paths, err := DiscoverPlugins(cfg.Plugins.Dir)
+		if err != nil {
+			logger.Error("failed to discover notification plugins", "dir", cfg.Plugins.Dir, "error", err)
+		}
+		for _, path := range paths {
+			plugin := NewPluginNotifier(PluginOptions{Path: path, Logger: logger})
+			notifiers = append(notifiers, plugin)
+			pluginNames = append(pluginNames, plugin.Name())
+		}
+		logger.Debug("notification plugins enabled", "dir", cfg.Plugins.Dir, "plugins", pluginNames)
+	}
+
+	return notifiers, pluginNames
+}
+
+// eventTypes converts configured event names to event types
+func eventTypes(names []string) []EventType {
+	types := make([]EventType, 0, len(names))
+	for _, name := range names {
+		types = append(types, EventType(name))
+	}
+	return types
+}
+
+// discordRoutes converts configured Discord routes to notifier routes
+func discordRoutes(cfg []config.DiscordRoute) []DiscordRoute {
+	routes := make([]DiscordRoute, 0, len(cfg))
+	for _, route := range cfg {
+		discordRoute := DiscordRoute{WebhookURL: route.WebhookURL, Events: eventTypes(route.Events)}
+		for _, severity := range route.Severities {
+			discordRoute.Severities = append(discordRoute.Severities, Severity(severity))
+		}
+		routes = append(routes, discordRoute)
+	}
+	return routes
+}
+
+// pagerDutyResolveEvents returns the event types mapped to resolve in notifications.pagerduty.event_actions
+func pagerDutyResolveEvents(eventActions map[string]string) []EventType {
+	resolve := []EventType{}
+	for eventName, action := range eventActions {
+		if action == "resolve" {
+			resolve = append(resolve, EventType(eventName))
+		}
 	}
+	return resolve
+}
+
+// slackButtonActions returns the action buttons added to Slack alerts, none unless interactivity is enabled
+func slackButtonActions(cfg config.SlackInteractive) []string {
+	if !cfg.Enabled {
+		return nil
+	}
+	return cfg.Actions
 }
 
 // IsEnabled returns whether the notification manager is enabled
 func (m *Manager) IsEnabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.enabled && len(m.notifiers) > 0
 }
 
+// RecentExchanges returns the most recent notifier HTTP exchanges, oldest first.
+// Only populated when notifications.debug.enabled is true.
+func (m *Manager) RecentExchanges() []Exchange {
+	if m.exchanges == nil {
+		return []Exchange{}
+	}
+	return m.exchanges.list()
+}
+
+// Events returns the events emitted after since, oldest first - at most notifications.history_size of them.
+// Events are kept whether or not notifications are enabled for them.
+func (m *Manager) Events(since time.Time) []Event {
+	return m.history.since(since)
+}
+
 // isEventEnabled checks if a specific event type is enabled
 func (m *Manager) isEventEnabled(eventType EventType) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	switch eventType {
 	case EventStartup:
 		return m.eventFilter.Startup
@@ -179,6 +553,40 @@ func (m *Manager) isEventEnabled(eventType EventType) bool {
 		return m.eventFilter.PeerDiscovered
 	case EventPeerLost:
 		return m.eventFilter.PeerLost
+	case EventRPCDemoted:
+		return m.eventFilter.RPCEndpointDemoted
+	case EventSnapshotRecoveryStarted:
+		return m.eventFilter.SnapshotRecoveryStarted
+	case EventSnapshotRecoveryCompleted:
+		return m.eventFilter.SnapshotRecoveryCompleted
+	case EventSnapshotRecoveryFailed:
+		return m.eventFilter.SnapshotRecoveryFailed
+	case EventQuietPeriodEnded:
+		return m.eventFilter.QuietPeriodEnded
+	case EventVoteAccountChanged:
+		return m.eventFilter.VoteAccountChanged
+	case EventActiveIdentityOnPassive:
+		return m.eventFilter.ActiveIdentityOnPassive
+	case EventCircuitBreakerTripped:
+		return m.eventFilter.CircuitBreakerTripped
+	case EventCircuitBreakerReset:
+		return m.eventFilter.CircuitBreakerReset
+	case EventHeartbeat:
+		return m.eventFilter.Heartbeat
+	case EventAcknowledged:
+		return m.eventFilter.Acknowledged
+	case EventManualRoleChange:
+		return m.eventFilter.ManualRoleChange
+	case EventScheduledFailover:
+		return m.eventFilter.ScheduledFailover
+	case EventVoteCreditsLagging:
+		return m.eventFilter.VoteCreditsLagging
+	case EventSkipRateHigh:
+		return m.eventFilter.SkipRateHigh
+	case EventFailback:
+		return m.eventFilter.Failback
+	case EventFlappingDetected:
+		return m.eventFilter.FlappingDetected
 	default:
 		return true
 	}
@@ -186,65 +594,327 @@ func (m *Manager) isEventEnabled(eventType EventType) bool {
 
 // Notify sends an event to all enabled notifiers synchronously
 func (m *Manager) Notify(event Event) {
+	// Set timestamp if not set
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	// Apply configured severity so colors, digest batching and notifier severities honor it
+	event.Severity = m.Severity(event)
+
+	// Note who acknowledged the incident the event belongs to, if anyone
+	event = m.acks.annotate(event)
+
+	m.subscribers.publish(event)
+	m.history.record(event)
+
 	if !m.enabled {
 		return
 	}
 
+	m.stats.event(event.Type)
+
 	if !m.isEventEnabled(event.Type) {
 		m.logger.Debug("event type disabled, skipping notification", "event", event.Type)
 		return
 	}
 
-	// Set timestamp if not set
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now().UTC()
+	// Suppress identical events within the dedup window
+	if m.dedup != nil && !m.dedup.allow(event) {
+		m.logger.Debug("duplicate event within dedup window, skipping notification", "event", event.Type)
+		return
+	}
+
+	// Hold back non-critical events during quiet hours and maintenance mode
+	if m.quiet != nil {
+		var send bool
+		if event, send = m.quiet.apply(event, time.Now()); !send {
+			m.logger.Debug("quiet period, skipping notification", "event", event.Type)
+			return
+		}
+	}
+
+	m.dispatch(event)
+}
+
+// dispatch batches low severity events into the digest when enabled, delivering everything else immediately -
+// events resolving an incident included
+func (m *Manager) dispatch(event Event) {
+	if m.digest != nil && m.digest.batches(event.Severity) && !m.resolvesIncident(event.Type) {
+		m.logger.Debug("event batched for digest", "event", event.Type)
+		m.digest.add(event)
+		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	m.deliver(event)
+}
+
+// resolvesIncident returns true if any notifier resolves an incident on events of eventType
+func (m *Manager) resolvesIncident(eventType EventType) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
 	for _, notifier := range m.notifiers {
-		if !notifier.IsEnabled() {
-			continue
+		if resolver, ok := notifier.(incidentResolver); ok && notifier.IsEnabled() && resolver.Resolves(eventType) {
+			return true
 		}
+	}
+	return false
+}
+
+// SetMaintenance turns maintenance mode on or off - non-critical events are held back while it is on
+func (m *Manager) SetMaintenance(enabled bool) {
+	if m.quiet == nil {
+		return
+	}
+	m.logger.Info("notification maintenance mode changed", "enabled", enabled)
+	m.quiet.setMaintenance(enabled)
+}
+
+// Maintenance returns true while maintenance mode is turned on - the maintenance file is not counted
+func (m *Manager) Maintenance() bool {
+	return m.quiet != nil && m.quiet.maintenance.Load()
+}
+
+// Silence holds back non-critical events for the given duration
+func (m *Manager) Silence(duration time.Duration) {
+	if m.quiet == nil {
+		return
+	}
+	until := time.Now().Add(duration)
+	m.logger.Info("notifications silenced", "until", until.Format(time.RFC3339))
+	m.quiet.silence(until)
+}
 
-		if err := notifier.Send(ctx, event); err != nil {
+// Stats returns the events emitted and notifications delivered so far
+func (m *Manager) Stats() Stats {
+	if m.stats == nil {
+		return Stats{Events: map[EventType]int{}}
+	}
+	return m.stats.snapshot()
+}
+
+// QuietStatus returns whether non-critical notifications are currently being held back and why
+func (m *Manager) QuietStatus() QuietStatus {
+	if m.quiet == nil {
+		return QuietStatus{}
+	}
+	return m.quiet.status()
+}
+
+// Close sends any queued events, flushes any batched digest events and stops background work, including spool replays
+func (m *Manager) Close() {
+	m.pool.close()
+	if m.quiet != nil {
+		m.quiet.close()
+	}
+	if m.digest != nil {
+		m.digest.close()
+	}
+	if m.replayer != nil {
+		m.replayer.close()
+	}
+}
+
+// deliver sends an event to the enabled notifiers routed for its severity, spooling failed deliveries if configured
+func (m *Manager) deliver(event Event) {
+	m.mu.RLock()
+	templates, links, routes := m.templates, m.links, m.routes
+	m.mu.RUnlock()
+
+	// Render custom titles and descriptions so spooled events are replayed as they were first sent
+	if templates != nil {
+		rendered, err := templates.render(event)
+		if err != nil {
+			m.logger.Error("failed to render notification template - sending default message", "event", event.Type, "error", err)
+		}
+		event = rendered
+	}
+
+	if links != nil {
+		linked, err := links.render(event)
+		if err != nil {
+			m.logger.Error("failed to render notification links - sending without the failed links", "event", event.Type, "error", err)
+		}
+		event = linked
+	}
+
+	// Send only to the notifiers routed for the event's severity, as it stands after overrides and quiet hours
+	routed := routes[event.Severity]
+	failed := m.send(event, routed)
+
+	if m.spool == nil {
+		return
+	}
+
+	// at least one notifier is reachable - try to deliver anything spooled previously
+	if len(failed) < len(m.targets(routed)) {
+		m.replaySpool()
+	}
+
+	// persist failed deliveries so they can be replayed once connectivity returns
+	if len(failed) > 0 {
+		if err := m.spool.Write(event, failed); err != nil {
+			m.logger.Error("failed to spool notification", "event", event.Type, "error", err)
+		}
+	}
+}
+
+// send sends an event to the enabled notifiers, limited to notifierNames when non-empty,
+// and returns the names of the notifiers that failed. Notifiers are sent to in parallel, each
+// with its own timeout, so one slow service doesn't delay the others.
+func (m *Manager) send(event Event, notifierNames []string) (failed []string) {
+	results := m.sendEach(event, notifierNames)
+
+	for _, result := range results {
+		m.stats.delivery(result.Err == nil)
+		if result.Err != nil {
 			m.logger.Error("notification failed",
-				"service", notifier.Name(),
+				"service", result.Service,
 				"event", event.Type,
-				"error", err,
+				"error", result.Err,
 			)
+			failed = append(failed, result.Service)
 		} else {
 			m.logger.Debug("notification sent",
-				"service", notifier.Name(),
+				"service", result.Service,
 				"event", event.Type,
 			)
 		}
 	}
+
+	return failed
 }
 
-// NotifyAsync sends notification in background goroutine (non-blocking)
-func (m *Manager) NotifyAsync(event Event) {
-	if !m.enabled {
-		return
+// sendEach sends an event to the enabled notifiers in parallel, limited to notifierNames when non-empty,
+// and returns the outcome for each in notifier order
+func (m *Manager) sendEach(event Event, notifierNames []string) []TestResult {
+	timeout := m.timeout
+	if timeout <= 0 {
+		timeout = defaultNotifierTimeout
 	}
 
-	if !m.isEventEnabled(event.Type) {
-		m.logger.Debug("event type disabled, skipping notification", "event", event.Type)
+	notifiers := m.targets(notifierNames)
+	results := make([]TestResult, len(notifiers))
+	var wg sync.WaitGroup
+	for i, notifier := range notifiers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			results[i] = TestResult{Service: notifier.Name(), Err: notifier.Send(ctx, event)}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// TestResult is the outcome of sending a test event to a single notifier
+type TestResult struct {
+	Service string
+	Err     error
+}
+
+// SendTest sends an event straight to the enabled notifiers, limited to services when non-empty, and returns
+// the outcome for each. An event without a severity is sent with its configured severity. Event filtering, dedup, quiet hours, digests and the spool are bypassed so every
+// selected notifier is exercised; templates are still rendered.
+func (m *Manager) SendTest(event Event, services []string) []TestResult {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if event.Severity == "" {
+		event.Severity = m.Severity(event)
+	}
+
+	m.mu.RLock()
+	templates := m.templates
+	m.mu.RUnlock()
+
+	if templates != nil {
+		rendered, err := templates.render(event)
+		if err != nil {
+			m.logger.Error("failed to render notification template - sending default message", "event", event.Type, "error", err)
+		}
+		event = rendered
+	}
+
+	return m.sendEach(event, services)
+}
+
+// replaySpool redelivers spooled events, skipping if a replay is already in progress
+func (m *Manager) replaySpool() {
+	if !m.replaying.CompareAndSwap(false, true) {
 		return
 	}
+	defer m.replaying.Store(false)
 
-	go m.Notify(event)
+	m.spool.Replay(m.send)
+}
+
+// targets returns the enabled notifiers, limited to notifierNames when non-empty
+func (m *Manager) targets(notifierNames []string) []Notifier {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notifiers := []Notifier{}
+	for _, notifier := range m.notifiers {
+		if !notifier.IsEnabled() {
+			continue
+		}
+
+		if len(notifierNames) > 0 && !slices.Contains(notifierNames, notifier.Name()) {
+			continue
+		}
+
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers
+}
+
+// NotifyAsync queues a notification for the worker pool (non-blocking). The event is dropped, and an
+// error logged, if notifications.queue_size events are already waiting.
+func (m *Manager) NotifyAsync(event Event) {
+	if m.subscribers == nil && m.history == nil {
+		if !m.enabled {
+			return
+		}
+
+		if !m.isEventEnabled(event.Type) {
+			m.logger.Debug("event type disabled, skipping notification", "event", event.Type)
+			return
+		}
+	}
+
+	if !m.pool.submit(event) {
+		m.logger.Error("notification queue full or closed - event dropped", "event", event.Type, "message", event.Message)
+	}
+}
+
+// Severity returns the severity an event is sent with - the configured override for its type if any,
+// else its own severity, falling back to the default severity for its type
+func (m *Manager) Severity(event Event) Severity {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if severity, ok := m.severityOverrides[event.Type]; ok {
+		return severity
+	}
+	if event.Severity == "" {
+		return GetDefaultSeverity(event.Type)
+	}
+	return event.Severity
 }
 
 // Helper function to get default severity for an event type
 func GetDefaultSeverity(eventType EventType) Severity {
 	switch eventType {
-	case EventBecomingActive, EventDelinquent:
+	case EventBecomingActive, EventDelinquent, EventVoteAccountChanged, EventActiveIdentityOnPassive, EventCircuitBreakerTripped:
 		return SeverityCritical
-	case EventHealthUnhealthy, EventGossipLost, EventPeerLost:
+	case EventHealthUnhealthy, EventGossipLost, EventPeerLost, EventSnapshotRecoveryFailed:
 		return SeverityError
-	case EventBecomingPassive, EventShutdown:
+	case EventBecomingPassive, EventShutdown, EventRPCDemoted, EventSnapshotRecoveryStarted:
 		return SeverityWarning
 	default:
 		return SeverityInfo
@@ -0,0 +1,214 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Severity(t *testing.T) {
+	manager := &Manager{
+		severityOverrides: map[EventType]Severity{
+			EventGossipLost: SeverityCritical,
+		},
+	}
+
+	// override wins over the event's own severity
+	assert.Equal(t, SeverityCritical, manager.Severity(Event{Type: EventGossipLost, Severity: SeverityError}))
+
+	// event severity is kept without an override
+	assert.Equal(t, SeverityWarning, manager.Severity(Event{Type: EventPeerLost, Severity: SeverityWarning}))
+
+	// default severity when the event has none
+	assert.Equal(t, SeverityCritical, manager.Severity(Event{Type: EventBecomingActive}))
+}
+
+// fakeNotifier records sent events and fails when err is set
+type fakeNotifier struct {
+	name   string
+	err    error
+	events []Event
+}
+
+func (f *fakeNotifier) Name() string    { return f.name }
+func (f *fakeNotifier) IsEnabled() bool { return true }
+func (f *fakeNotifier) Send(ctx context.Context, event Event) error {
+	f.events = append(f.events, event)
+	return f.err
+}
+
+func TestManager_SendTest(t *testing.T) {
+	discord := &fakeNotifier{name: "discord"}
+	slack := &fakeNotifier{name: "slack", err: errors.New("webhook returned status 404")}
+	manager := &Manager{
+		notifiers: []Notifier{discord, slack},
+		logger:    log.New(io.Discard),
+	}
+
+	results := manager.SendTest(Event{Type: EventBecomingActive, Message: "test"}, nil)
+	require.Len(t, results, 2)
+	assert.Equal(t, "discord", results[0].Service)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "slack", results[1].Service)
+	assert.Error(t, results[1].Err)
+
+	require.Len(t, discord.events, 1)
+	assert.Equal(t, SeverityCritical, discord.events[0].Severity)
+	assert.False(t, discord.events[0].Timestamp.IsZero())
+
+	// limited to the given services
+	results = manager.SendTest(Event{Type: EventStartup}, []string{"slack"})
+	require.Len(t, results, 1)
+	assert.Equal(t, "slack", results[0].Service)
+	assert.Len(t, discord.events, 1)
+}
+
+func TestManager_Stats(t *testing.T) {
+	discord := &fakeNotifier{name: "discord"}
+	slack := &fakeNotifier{name: "slack", err: errors.New("webhook returned status 404")}
+	manager := &Manager{
+		notifiers:   []Notifier{discord, slack},
+		logger:      log.New(io.Discard),
+		enabled:     true,
+		eventFilter: config.NotificationEvents{Startup: true},
+		stats:       newStatsCounter(),
+	}
+
+	_, ok := manager.Stats().SuccessRate()
+	assert.False(t, ok)
+
+	manager.Notify(Event{Type: EventStartup})
+	manager.Notify(Event{Type: EventStartup})
+	// disabled events are counted as emitted but not sent
+	manager.Notify(Event{Type: EventPeerLost})
+
+	stats := manager.Stats()
+	assert.Equal(t, map[EventType]int{EventStartup: 2, EventPeerLost: 1}, stats.Events)
+	assert.Equal(t, 3, stats.TotalEvents())
+	assert.Equal(t, 2, stats.Delivered)
+	assert.Equal(t, 2, stats.Failed)
+	rate, ok := stats.SuccessRate()
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, rate)
+}
+
+// slowNotifier blocks until its send is cancelled
+type slowNotifier struct{}
+
+func (s *slowNotifier) Name() string    { return "slow" }
+func (s *slowNotifier) IsEnabled() bool { return true }
+func (s *slowNotifier) Send(ctx context.Context, event Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestManager_SendTimesOutEachNotifier(t *testing.T) {
+	discord := &fakeNotifier{name: "discord"}
+	manager := &Manager{
+		notifiers: []Notifier{&slowNotifier{}, discord},
+		logger:    log.New(io.Discard),
+		stats:     newStatsCounter(),
+		timeout:   50 * time.Millisecond,
+	}
+
+	started := time.Now()
+	failed := manager.send(Event{Type: EventStartup}, nil)
+
+	// the slow notifier times out on its own without holding up the others
+	assert.Equal(t, []string{"slow"}, failed)
+	assert.Len(t, discord.events, 1)
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestManager_NotifyAsync(t *testing.T) {
+	discord := &fakeNotifier{name: "discord"}
+	manager := &Manager{
+		notifiers:   []Notifier{discord},
+		logger:      log.New(io.Discard),
+		enabled:     true,
+		eventFilter: config.NotificationEvents{Startup: true},
+		stats:       newStatsCounter(),
+	}
+	manager.pool = newWorkerPool(1, 1, manager.Notify)
+
+	manager.NotifyAsync(Event{Type: EventStartup})
+	manager.Close()
+	assert.Len(t, discord.events, 1)
+
+	// events are dropped once closed
+	manager.NotifyAsync(Event{Type: EventStartup})
+	assert.Len(t, discord.events, 1)
+}
+
+func TestManager_NotifyRoutesBySeverity(t *testing.T) {
+	pagerduty := &fakeNotifier{name: "pagerduty"}
+	slack := &fakeNotifier{name: "slack"}
+	manager := &Manager{
+		notifiers:   []Notifier{pagerduty, slack},
+		logger:      log.New(io.Discard),
+		enabled:     true,
+		eventFilter: config.NotificationEvents{Startup: true, Delinquent: true, PeerLost: true},
+		stats:       newStatsCounter(),
+		routes: map[Severity][]string{
+			SeverityCritical: {"pagerduty"},
+			SeverityInfo:     {"slack"},
+		},
+	}
+
+	manager.Notify(Event{Type: EventDelinquent})
+	manager.Notify(Event{Type: EventStartup})
+	// unrouted severities go to every notifier
+	manager.Notify(Event{Type: EventPeerLost})
+
+	require.Len(t, pagerduty.events, 2)
+	assert.Equal(t, EventDelinquent, pagerduty.events[0].Type)
+	assert.Equal(t, EventPeerLost, pagerduty.events[1].Type)
+	require.Len(t, slack.events, 2)
+	assert.Equal(t, EventStartup, slack.events[0].Type)
+	assert.Equal(t, EventPeerLost, slack.events[1].Type)
+}
+
+func TestManager_Reload(t *testing.T) {
+	manager := NewManager(ManagerOptions{
+		Config: &config.NotificationConfig{
+			Enabled: true,
+			Discord: config.DiscordConfig{Enabled: true, WebhookURL: "https://discord.example/webhook"},
+			Events:  config.NotificationEvents{Startup: true},
+		},
+		ValidatorName: "test-validator",
+	})
+	defer manager.Close()
+	history := manager.history
+
+	err := manager.Reload(&config.NotificationConfig{
+		Enabled:           true,
+		Slack:             config.SlackConfig{Enabled: true, WebhookURL: "https://slack.example/webhook"},
+		Events:            config.NotificationEvents{PeerLost: true},
+		SeverityOverrides: map[string]string{string(EventPeerLost): string(SeverityCritical)},
+	})
+	require.NoError(t, err)
+
+	targets := manager.targets(nil)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "slack", targets[0].Name())
+	assert.False(t, manager.isEventEnabled(EventStartup))
+	assert.True(t, manager.isEventEnabled(EventPeerLost))
+	assert.Equal(t, SeverityCritical, manager.Severity(Event{Type: EventPeerLost}))
+	// state kept across reloads
+	assert.Same(t, history, manager.history)
+}
+
+func TestManager_Reload_DisabledAtStartup(t *testing.T) {
+	manager := NewManager(ManagerOptions{Config: &config.NotificationConfig{}})
+	defer manager.Close()
+
+	assert.NoError(t, manager.Reload(&config.NotificationConfig{}))
+	assert.ErrorContains(t, manager.Reload(&config.NotificationConfig{Enabled: true}), "restart to enable them")
+}
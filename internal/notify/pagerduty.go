@@ -6,25 +6,36 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/log"
 )
 
-const pagerDutyEventsAPI = "https://events.pagerduty.com/v2/enqueue"
+const (
+	pagerDutyEventsAPI       = "https://events.pagerduty.com/v2/enqueue"
+	pagerDutyChangeEventsAPI = "https://events.pagerduty.com/v2/change/enqueue"
+)
 
 // PagerDutyOptions contains options for creating a PagerDuty notifier
 type PagerDutyOptions struct {
 	RoutingKey string
-	Logger     *log.Logger
+	// ChangeEvents are sent as change events, adding context to the service timeline without opening incidents
+	ChangeEvents []EventType
+	// ResolveEvents resolve the incident for their dedup key rather than triggering it
+	ResolveEvents []EventType
+	Logger        *log.Logger
+	Transport     http.RoundTripper
 }
 
 // PagerDutyNotifier sends notifications to PagerDuty via Events API v2
 type PagerDutyNotifier struct {
-	routingKey string
-	httpClient *http.Client
-	logger     *log.Logger
-	enabled    bool
+	routingKey    string
+	changeEvents  []EventType
+	resolveEvents []EventType
+	httpClient    *http.Client
+	logger        *log.Logger
+	enabled       bool
 }
 
 // PagerDuty Events API v2 payload structures
@@ -35,6 +46,19 @@ type pagerDutyPayload struct {
 	Payload     pagerDutyEvent `json:"payload"`
 }
 
+// PagerDuty change event payload structures
+type pagerDutyChangePayload struct {
+	RoutingKey string               `json:"routing_key"`
+	Payload    pagerDutyChangeEvent `json:"payload"`
+}
+
+type pagerDutyChangeEvent struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
 type pagerDutyEvent struct {
 	Summary       string            `json:"summary"`
 	Severity      string            `json:"severity"`
@@ -49,10 +73,12 @@ type pagerDutyEvent struct {
 // NewPagerDutyNotifier creates a new PagerDuty notifier
 func NewPagerDutyNotifier(opts PagerDutyOptions) *PagerDutyNotifier {
 	return &PagerDutyNotifier{
-		routingKey: opts.RoutingKey,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-		logger:     opts.Logger,
-		enabled:    opts.RoutingKey != "",
+		routingKey:    opts.RoutingKey,
+		changeEvents:  opts.ChangeEvents,
+		resolveEvents: opts.ResolveEvents,
+		httpClient:    &http.Client{Timeout: 10 * time.Second, Transport: opts.Transport},
+		logger:        opts.Logger,
+		enabled:       opts.RoutingKey != "",
 	}
 }
 
@@ -66,19 +92,58 @@ func (p *PagerDutyNotifier) IsEnabled() bool {
 	return p.enabled
 }
 
+// Resolves returns true if events of eventType resolve their incident, per notifications.pagerduty.event_actions
+func (p *PagerDutyNotifier) Resolves(eventType EventType) bool {
+	return slices.Contains(p.resolveEvents, eventType)
+}
+
 // Send sends a notification to PagerDuty
 func (p *PagerDutyNotifier) Send(ctx context.Context, event Event) error {
 	if !p.enabled {
 		return nil
 	}
 
-	// Determine event action based on event type
+	// Lifecycle events add timeline context as change events rather than opening incidents
+	if slices.Contains(p.changeEvents, event.Type) {
+		return p.post(ctx, pagerDutyChangeEventsAPI, pagerDutyChangePayload{
+			RoutingKey: p.routingKey,
+			Payload: pagerDutyChangeEvent{
+				Summary:       p.getSummary(event),
+				Source:        event.ValidatorName,
+				Timestamp:     event.Timestamp.Format(time.RFC3339),
+				CustomDetails: p.getCustomDetails(event),
+			},
+		})
+	}
+
+	// Determine event action based on notifications.pagerduty.event_actions
 	eventAction := "trigger"
-	if event.Type == EventHealthRecovered || event.Type == EventGossipRecovered || event.Type == EventBecamePassive {
+	if slices.Contains(p.resolveEvents, event.Type) {
 		eventAction = "resolve"
+	} else if isAcknowledged(event) {
+		// acknowledged incidents stop escalating rather than being triggered again
+		eventAction = "acknowledge"
 	}
 
-	// Build custom details
+	return p.post(ctx, pagerDutyEventsAPI, pagerDutyPayload{
+		RoutingKey:  p.routingKey,
+		EventAction: eventAction,
+		DedupKey:    p.getDedupKey(event),
+		Payload: pagerDutyEvent{
+			Summary:       p.getSummary(event),
+			Severity:      p.getSeverity(event.Severity),
+			Source:        event.ValidatorName,
+			Timestamp:     event.Timestamp.Format(time.RFC3339),
+			Component:     "solana-validator-ha",
+			Group:         event.Cluster,
+			Class:         string(event.Type),
+			CustomDetails: p.getCustomDetails(event),
+		},
+	})
+}
+
+// getCustomDetails returns the event details sent with both alert and change events
+func (p *PagerDutyNotifier) getCustomDetails(event Event) map[string]string {
 	customDetails := map[string]string{
 		"validator_name": event.ValidatorName,
 		"cluster":        event.Cluster,
@@ -93,52 +158,46 @@ func (p *PagerDutyNotifier) Send(ctx context.Context, event Event) error {
 		customDetails["passive_pubkey"] = event.PassivePubkey
 	}
 
+	// Keep the description when a templated title is used as the summary
+	if event.Title != "" && event.Message != "" {
+		customDetails["description"] = event.Message
+	}
+
 	// Add any additional details
 	for k, v := range event.Details {
 		customDetails[k] = v
 	}
 
-	payload := pagerDutyPayload{
-		RoutingKey:  p.routingKey,
-		EventAction: eventAction,
-		DedupKey:    p.getDedupKey(event),
-		Payload: pagerDutyEvent{
-			Summary:       p.getSummary(event),
-			Severity:      p.getSeverity(event.Severity),
-			Source:        event.ValidatorName,
-			Timestamp:     event.Timestamp.Format(time.RFC3339),
-			Component:     "solana-validator-ha",
-			Group:         event.Cluster,
-			Class:         string(event.Type),
-			CustomDetails: customDetails,
-		},
-	}
+	return customDetails
+}
 
+// post sends a payload to a PagerDuty Events API v2 endpoint
+func (p *PagerDutyNotifier) post(ctx context.Context, apiURL string, payload any) error {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal pagerduty payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pagerDutyEventsAPI, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create pagerduty request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.httpClient.Do(req)
+	resp, err := doRequest(p.httpClient, req, "pagerduty API")
 	if err != nil {
 		return fmt.Errorf("failed to send pagerduty notification: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("pagerduty API returned status %d", resp.StatusCode)
-	}
-
 	return nil
 }
 
 func (p *PagerDutyNotifier) getSummary(event Event) string {
+	if event.Title != "" {
+		return event.Title
+	}
+
 	if event.Message != "" {
 		return event.Message
 	}
@@ -172,6 +231,42 @@ func (p *PagerDutyNotifier) getSummary(event Event) string {
 	case EventPeerLost:
 		peerName := event.Details["peer_name"]
 		return fmt.Sprintf("[%s] Peer lost: %s", event.ValidatorName, peerName)
+	case EventDigest:
+		return fmt.Sprintf("[%s] Notification digest", event.ValidatorName)
+	case EventRPCDemoted:
+		return fmt.Sprintf("[%s] RPC endpoint demoted: %s", event.ValidatorName, event.Details["endpoint"])
+	case EventSnapshotRecoveryStarted:
+		return fmt.Sprintf("[%s] Snapshot recovery started", event.ValidatorName)
+	case EventSnapshotRecoveryCompleted:
+		return fmt.Sprintf("[%s] Snapshot recovery completed", event.ValidatorName)
+	case EventSnapshotRecoveryFailed:
+		return fmt.Sprintf("[%s] Snapshot recovery failed", event.ValidatorName)
+	case EventQuietPeriodEnded:
+		return fmt.Sprintf("[%s] Quiet period ended", event.ValidatorName)
+	case EventVoteAccountChanged:
+		return fmt.Sprintf("[%s] CRITICAL: Vote account %s changed", event.ValidatorName, event.Details["field"])
+	case EventActiveIdentityOnPassive:
+		return fmt.Sprintf("[%s] CRITICAL: Active identity in use on passive node", event.ValidatorName)
+	case EventCircuitBreakerTripped:
+		return fmt.Sprintf("[%s] CRITICAL: Failover circuit breaker tripped - automatic takeovers frozen", event.ValidatorName)
+	case EventCircuitBreakerReset:
+		return fmt.Sprintf("[%s] Failover circuit breaker reset", event.ValidatorName)
+	case EventHeartbeat:
+		return fmt.Sprintf("[%s] Heartbeat: %s", event.ValidatorName, event.Message)
+	case EventAcknowledged:
+		return fmt.Sprintf("[%s] Failover %s acknowledged by %s", event.ValidatorName, event.Details["failover_id"], event.Details["acknowledged_by"])
+	case EventManualRoleChange:
+		return fmt.Sprintf("[%s] Manual %s requested by %s %s", event.ValidatorName, event.Details["action"], event.Details["requested_by"], event.Details["result"])
+	case EventScheduledFailover:
+		return fmt.Sprintf("[%s] Scheduled failover %s to %s %s", event.ValidatorName, event.Details["schedule"], event.Details["active_peer"], event.Details["result"])
+	case EventVoteCreditsLagging:
+		return fmt.Sprintf("[%s] Vote credits %s%% of the cluster average", event.ValidatorName, event.Details["percent_of_average"])
+	case EventSkipRateHigh:
+		return fmt.Sprintf("[%s] Skip rate %s%% this epoch", event.ValidatorName, event.Details["skip_rate_percent"])
+	case EventFailback:
+		return fmt.Sprintf("[%s] Failback to %s %s", event.ValidatorName, event.Details["primary"], event.Details["step"])
+	case EventFlappingDetected:
+		return fmt.Sprintf("[%s] Flapping - %s role changes within %s", event.ValidatorName, event.Details["transitions"], event.Details["window"])
 	default:
 		return fmt.Sprintf("[%s] Event: %s", event.ValidatorName, event.Type)
 	}
@@ -193,6 +288,14 @@ func (p *PagerDutyNotifier) getSeverity(severity Severity) string {
 // getDedupKey returns a deduplication key for the event
 // Events with the same dedup key will be grouped together
 func (p *PagerDutyNotifier) getDedupKey(event Event) string {
+	// Role transitions and acknowledgements for the same failover share its incident
+	if failoverID := event.Details["failover_id"]; failoverID != "" {
+		switch event.Type {
+		case EventBecomingActive, EventBecameActive, EventBecomingPassive, EventBecamePassive, EventAcknowledged, EventManualRoleChange, EventScheduledFailover, EventFailback:
+			return fmt.Sprintf("%s-failover-%s", event.ValidatorName, failoverID)
+		}
+	}
+
 	// Group related events together
 	switch event.Type {
 	case EventHealthUnhealthy, EventHealthRecovered:
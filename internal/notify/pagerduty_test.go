@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTransport records request URLs and bodies, answering every request with 202 Accepted
+type recordingTransport struct {
+	urls   []string
+	bodies []map[string]any
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := map[string]any{}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	r.urls = append(r.urls, req.URL.String())
+	r.bodies = append(r.bodies, body)
+	return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(`{"status":"success"}`)), Request: req}, nil
+}
+
+func TestPagerDutyNotifier_ChangeEvents(t *testing.T) {
+	transport := &recordingTransport{}
+	notifier := NewPagerDutyNotifier(PagerDutyOptions{
+		RoutingKey:   "routing-key",
+		ChangeEvents: []EventType{EventStartup, EventBecamePassive},
+		Logger:       log.New(io.Discard),
+		Transport:    transport,
+	})
+
+	events := []Event{
+		{Type: EventStartup, Severity: SeverityInfo, ValidatorName: "validator-1", Timestamp: time.Now()},
+		{Type: EventBecamePassive, Severity: SeverityInfo, ValidatorName: "validator-1", Timestamp: time.Now()},
+		{Type: EventDelinquent, Severity: SeverityCritical, ValidatorName: "validator-1", Timestamp: time.Now()},
+	}
+	for _, event := range events {
+		require.NoError(t, notifier.Send(context.Background(), event))
+	}
+
+	require.Len(t, transport.urls, 3)
+	assert.Equal(t, pagerDutyChangeEventsAPI, transport.urls[0])
+	assert.Equal(t, pagerDutyChangeEventsAPI, transport.urls[1])
+	assert.Equal(t, pagerDutyEventsAPI, transport.urls[2])
+
+	// change events carry no event action or severity
+	assert.NotContains(t, transport.bodies[0], "event_action")
+	changePayload := transport.bodies[0]["payload"].(map[string]any)
+	assert.Equal(t, "[validator-1] Validator HA manager started", changePayload["summary"])
+	assert.Equal(t, "validator-1", changePayload["source"])
+	assert.NotContains(t, changePayload, "severity")
+
+	assert.Equal(t, "trigger", transport.bodies[2]["event_action"])
+}
+
+func TestPagerDutyNotifier_ResolveEvents(t *testing.T) {
+	transport := &recordingTransport{}
+	notifier := NewPagerDutyNotifier(PagerDutyOptions{
+		RoutingKey:    "routing-key",
+		ResolveEvents: []EventType{EventHealthRecovered},
+		Logger:        log.New(io.Discard),
+		Transport:     transport,
+	})
+
+	events := []Event{
+		{Type: EventHealthUnhealthy, ValidatorName: "validator-1"},
+		{Type: EventHealthRecovered, ValidatorName: "validator-1"},
+		// became_passive only resolves when mapped to resolve
+		{Type: EventBecamePassive, ValidatorName: "validator-1"},
+	}
+	for _, event := range events {
+		require.NoError(t, notifier.Send(context.Background(), event))
+	}
+
+	require.Len(t, transport.bodies, 3)
+	assert.Equal(t, "trigger", transport.bodies[0]["event_action"])
+	assert.Equal(t, "resolve", transport.bodies[1]["event_action"])
+	assert.Equal(t, "trigger", transport.bodies[2]["event_action"])
+}
@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// pluginProtocolVersion is sent with every event so plugins can detect changes to the document they are given
+	pluginProtocolVersion = 1
+	// pluginNamePrefix prefixes plugin notifier names so they can't clash with the built-in services
+	pluginNamePrefix = "plugin:"
+	// pluginsRoute routes to every plugin in notifications.routing
+	pluginsRoute = "plugins"
+	// maxPluginOutputBytes is the maximum number of bytes of plugin stderr included in errors
+	maxPluginOutputBytes = 512
+	// pluginWaitDelay is how long to wait for output from processes a killed plugin leaves behind
+	pluginWaitDelay = time.Second
+)
+
+// pluginEvent is the JSON document a plugin receives on stdin
+type pluginEvent struct {
+	Version       int               `json:"version"`
+	Type          EventType         `json:"type"`
+	Severity      Severity          `json:"severity"`
+	Timestamp     time.Time         `json:"timestamp"`
+	ValidatorName string            `json:"validator_name"`
+	PublicIP      string            `json:"public_ip"`
+	Cluster       string            `json:"cluster"`
+	ActivePubkey  string            `json:"active_pubkey"`
+	PassivePubkey string            `json:"passive_pubkey"`
+	Title         string            `json:"title"`
+	Message       string            `json:"message"`
+	Details       map[string]string `json:"details"`
+}
+
+// PluginOptions contains options for creating a plugin notifier
+type PluginOptions struct {
+	// Path is the plugin executable
+	Path   string
+	Logger *log.Logger
+}
+
+// PluginNotifier sends events to an external executable. The plugin is run once per event with the event as JSON
+// on stdin and must exit zero once it has delivered it - anything it writes to stderr is included in the error when
+// it does not.
+type PluginNotifier struct {
+	name   string
+	path   string
+	logger *log.Logger
+}
+
+// NewPluginNotifier creates a new plugin notifier named plugin:<file name without extension>
+func NewPluginNotifier(opts PluginOptions) *PluginNotifier {
+	base := filepath.Base(opts.Path)
+	return &PluginNotifier{
+		name:   pluginNamePrefix + strings.TrimSuffix(base, filepath.Ext(base)),
+		path:   opts.Path,
+		logger: opts.Logger,
+	}
+}
+
+// DiscoverPlugins returns the executable files in dir, sorted by name. Hidden files and directories are skipped.
+func DiscoverPlugins(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins dir: %w", err)
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// Name returns the notifier name
+func (p *PluginNotifier) Name() string {
+	return p.name
+}
+
+// IsEnabled returns whether the notifier is enabled
+func (p *PluginNotifier) IsEnabled() bool {
+	return p.path != ""
+}
+
+// Send runs the plugin with the event on stdin, killing it if ctx is done first
+func (p *PluginNotifier) Send(ctx context.Context, event Event) error {
+	jsonData, err := json.Marshal(pluginEvent{
+		Version:       pluginProtocolVersion,
+		Type:          event.Type,
+		Severity:      event.Severity,
+		Timestamp:     event.Timestamp,
+		ValidatorName: event.ValidatorName,
+		PublicIP:      event.PublicIP,
+		Cluster:       event.Cluster,
+		ActivePubkey:  event.ActivePubkey,
+		PassivePubkey: event.PassivePubkey,
+		Title:         eventTitle(event),
+		Message:       event.Message,
+		Details:       event.Details,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin event: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(jsonData)
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = pluginWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		output := strings.TrimSpace(stderr.String())
+		if len(output) > maxPluginOutputBytes {
+			output = output[:maxPluginOutputBytes] + "..."
+		}
+		if output == "" {
+			return fmt.Errorf("plugin %s failed: %w", p.path, err)
+		}
+		return fmt.Errorf("plugin %s failed: %w: %s", p.path, err, output)
+	}
+
+	p.logger.Debug("plugin notification sent", "plugin", p.name, "event", event.Type)
+	return nil
+}
+
+// pluginRoutes expands the plugins route in services to the given plugin notifier names. The route itself is
+// kept so a severity routed only to plugins is sent nowhere, rather than everywhere, when none are found.
+func pluginRoutes(services []string, pluginNames []string) []string {
+	routed := []string{}
+	for _, service := range services {
+		routed = append(routed, service)
+		if service == pluginsRoute {
+			routed = append(routed, pluginNames...)
+		}
+	}
+	return routed
+}
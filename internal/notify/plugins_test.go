@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestPlugin writes an executable shell script plugin to dir
+func writeTestPlugin(t *testing.T, dir string, name string, script string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPlugin(t, dir, "zulip.sh", "exit 0\n")
+	writeTestPlugin(t, dir, "matrix", "exit 0\n")
+	writeTestPlugin(t, dir, ".hidden", "exit 0\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lib"), 0o755))
+
+	paths, err := DiscoverPlugins(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "matrix"), filepath.Join(dir, "zulip.sh")}, paths)
+
+	_, err = DiscoverPlugins(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestPluginNotifier_Send(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "event.json")
+	path := writeTestPlugin(t, dir, "matrix.sh", "cat > "+output+"\n")
+
+	plugin := NewPluginNotifier(PluginOptions{Path: path, Logger: log.New(io.Discard)})
+	assert.Equal(t, "plugin:matrix", plugin.Name())
+	assert.True(t, plugin.IsEnabled())
+
+	timestamp := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, plugin.Send(context.Background(), Event{
+		Type:          EventBecameActive,
+		Severity:      SeverityInfo,
+		Timestamp:     timestamp,
+		ValidatorName: "validator-1",
+		Message:       "now active",
+		Details:       map[string]string{"peer": "validator-2"},
+	}))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	var received pluginEvent
+	require.NoError(t, json.Unmarshal(data, &received))
+	assert.Equal(t, pluginProtocolVersion, received.Version)
+	assert.Equal(t, EventBecameActive, received.Type)
+	assert.Equal(t, SeverityInfo, received.Severity)
+	assert.Equal(t, timestamp, received.Timestamp)
+	assert.Equal(t, "validator-1", received.ValidatorName)
+	assert.Equal(t, eventTitles[EventBecameActive], received.Title)
+	assert.Equal(t, "now active", received.Message)
+	assert.Equal(t, map[string]string{"peer": "validator-2"}, received.Details)
+}
+
+func TestPluginNotifier_SendErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	failing := NewPluginNotifier(PluginOptions{
+		Path:   writeTestPlugin(t, dir, "failing", "echo 'matrix homeserver unreachable' >&2\nexit 3\n"),
+		Logger: log.New(io.Discard),
+	})
+	err := failing.Send(context.Background(), Event{Type: EventStartup})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 3")
+	assert.Contains(t, err.Error(), "matrix homeserver unreachable")
+
+	slow := NewPluginNotifier(PluginOptions{
+		Path:   writeTestPlugin(t, dir, "slow", "sleep 5\n"),
+		Logger: log.New(io.Discard),
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = slow.Send(ctx, Event{Type: EventStartup})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewManager_Plugins(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPlugin(t, dir, "matrix", "exit 0\n")
+
+	manager := NewManager(ManagerOptions{
+		Config: &config.NotificationConfig{
+			Enabled: true,
+			Plugins: config.NotificationPlugins{Enabled: true, Dir: dir},
+			Routing: map[string][]string{"critical": {"plugins"}},
+		},
+		ValidatorName: "validator-1",
+	})
+	defer manager.Close()
+
+	require.Len(t, manager.notifiers, 1)
+	assert.Equal(t, "plugin:matrix", manager.notifiers[0].Name())
+	assert.Equal(t, []string{"plugins", "plugin:matrix"}, manager.routes[SeverityCritical])
+}
+
+func TestPluginRoutes(t *testing.T) {
+	assert.Equal(t, []string{"slack", "plugins", "plugin:a", "plugin:b"}, pluginRoutes([]string{"slack", "plugins"}, []string{"plugin:a", "plugin:b"}))
+
+	// without any plugins the route still matches no notifiers
+	assert.Equal(t, []string{"plugins"}, pluginRoutes([]string{"plugins"}, nil))
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultWorkers         = 4
+	defaultQueueSize       = 100
+	defaultNotifierTimeout = 10 * time.Second
+)
+
+// workerPool handles queued events on a fixed number of workers so a burst of events, or slow notifiers,
+// can't spawn an unbounded number of goroutines
+type workerPool struct {
+	// mu guards closing queue against concurrent submits
+	mu     sync.RWMutex
+	queue  chan Event
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// newWorkerPool starts workers handling events from a queue of queueSize, falling back to the defaults when not positive
+func newWorkerPool(workers int, queueSize int, handle func(Event)) *workerPool {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	p := &workerPool{queue: make(chan Event, queueSize)}
+	p.wg.Add(workers)
+	for range workers {
+		go func() {
+			defer p.wg.Done()
+			for event := range p.queue {
+				handle(event)
+			}
+		}()
+	}
+	return p
+}
+
+// submit queues an event, returning false if the queue is full or the pool is closed
+func (p *workerPool) submit(event Event) bool {
+	if p == nil {
+		return false
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+
+	select {
+	case p.queue <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops accepting events and waits for those already queued to be handled
+func (p *workerPool) close() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	handled := []EventType{}
+	pool := newWorkerPool(1, 1, func(event Event) {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, event.Type)
+	})
+
+	// the worker takes the first event, the second waits in the queue and the third is dropped
+	assert.True(t, pool.submit(Event{Type: EventStartup}))
+	assert.Eventually(t, func() bool { return len(pool.queue) == 0 }, time.Second, time.Millisecond)
+	assert.True(t, pool.submit(Event{Type: EventPeerLost}))
+	assert.False(t, pool.submit(Event{Type: EventPeerDiscovered}))
+
+	// close waits for queued events to be handled
+	close(release)
+	pool.close()
+	assert.Equal(t, []EventType{EventStartup, EventPeerLost}, handled)
+	assert.False(t, pool.submit(Event{Type: EventShutdown}))
+
+	// a nil pool accepts nothing
+	var nilPool *workerPool
+	assert.False(t, nilPool.submit(Event{Type: EventStartup}))
+	nilPool.close()
+}
@@ -0,0 +1,255 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+const (
+	// QuietReasonMaintenance is the quiet reason while maintenance mode is on
+	QuietReasonMaintenance = "maintenance"
+	// QuietReasonQuietHours is the quiet reason during a quiet hours window
+	QuietReasonQuietHours = "quiet_hours"
+	// QuietReasonSilenced is the quiet reason while notifications are silenced for a fixed time
+	QuietReasonSilenced = "silenced"
+
+	// quietModeDowngrade sends non-critical events as info instead of dropping them
+	quietModeDowngrade = "downgrade"
+
+	// quietCheckInterval is how often the quiet period is checked for ending
+	quietCheckInterval = time.Minute
+)
+
+// QuietStatus describes whether non-critical notifications are currently being held back
+type QuietStatus struct {
+	Quiet       bool      `json:"quiet"`
+	Reason      string    `json:"reason,omitempty"`
+	Maintenance bool      `json:"maintenance"`
+	Mode        string    `json:"mode"`
+	Since       time.Time `json:"since,omitempty"`
+	HeldEvents  int       `json:"held_events"`
+	// SilencedUntil is when silenced notifications resume - zero when not silenced
+	SilencedUntil time.Time `json:"silenced_until,omitempty"`
+}
+
+// quietPeriod holds back non-critical events during quiet hour windows and maintenance mode,
+// sending a summary of what was held back once the quiet period ends
+type quietPeriod struct {
+	mode            string
+	windows         []config.TimeWindow
+	maintenanceFile string
+	maintenance     atomic.Bool
+	// silencedUntil is the unix nano time silenced notifications resume at - zero when not silenced
+	silencedUntil atomic.Int64
+	onEnd         func(event Event)
+
+	mu     sync.Mutex
+	reason string
+	since  time.Time
+	held   []Event
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newQuietPeriod creates a quiet period that calls onEnd with a summary event when a quiet period ends
+func newQuietPeriod(cfg config.NotificationQuietHours, onEnd func(event Event)) *quietPeriod {
+	q := &quietPeriod{
+		mode:            cfg.Mode,
+		windows:         cfg.Windows,
+		maintenanceFile: cfg.MaintenanceFile,
+		onEnd:           onEnd,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// run checks for the quiet period ending every quietCheckInterval until closed
+func (q *quietPeriod) run() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(quietCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+			q.check(time.Now())
+		}
+	}
+}
+
+// close stops the quiet period checks
+func (q *quietPeriod) close() {
+	q.stopOnce.Do(func() { close(q.stop) })
+	<-q.done
+}
+
+// setMaintenance turns maintenance mode on or off, sending the summary straight away when it ends
+func (q *quietPeriod) setMaintenance(enabled bool) {
+	q.maintenance.Store(enabled)
+	q.check(time.Now())
+}
+
+// silence holds back non-critical events until the given time
+func (q *quietPeriod) silence(until time.Time) {
+	q.silencedUntil.Store(until.UnixNano())
+	q.check(time.Now())
+}
+
+// silencedUntilTime returns when silenced notifications resume, or the zero time if not silenced
+func (q *quietPeriod) silencedUntilTime() time.Time {
+	if until := q.silencedUntil.Load(); until != 0 {
+		return time.Unix(0, until)
+	}
+	return time.Time{}
+}
+
+// reasonAt returns why notifications are quiet at now, or an empty string if they are not
+func (q *quietPeriod) reasonAt(now time.Time) string {
+	if q.maintenance.Load() {
+		return QuietReasonMaintenance
+	}
+
+	if q.maintenanceFile != "" {
+		if _, err := os.Stat(q.maintenanceFile); err == nil {
+			return QuietReasonMaintenance
+		}
+	}
+
+	if now.Before(q.silencedUntilTime()) {
+		return QuietReasonSilenced
+	}
+
+	for i := range q.windows {
+		if q.windows[i].Contains(now) {
+			return QuietReasonQuietHours
+		}
+	}
+
+	return ""
+}
+
+// apply holds back a non-critical event while quiet, returning the event to send and whether to send it
+func (q *quietPeriod) apply(event Event, now time.Time) (Event, bool) {
+	q.check(now)
+
+	if event.Severity == SeverityCritical {
+		return event, true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.reason == "" {
+		return event, true
+	}
+
+	q.held = append(q.held, event)
+
+	if q.mode == quietModeDowngrade {
+		event.Severity = SeverityInfo
+		return event, true
+	}
+
+	return event, false
+}
+
+// check tracks quiet period transitions, calling onEnd with a summary when a quiet period ends
+func (q *quietPeriod) check(now time.Time) {
+	reason := q.reasonAt(now)
+
+	q.mu.Lock()
+	previousReason := q.reason
+	since := q.since
+	held := q.held
+
+	if reason != "" && previousReason == "" {
+		q.since = now
+	}
+	q.reason = reason
+	if reason == "" {
+		q.held = nil
+	}
+	q.mu.Unlock()
+
+	if previousReason != "" && reason == "" {
+		q.onEnd(buildQuietSummary(previousReason, q.mode, since, now, held))
+	}
+}
+
+// status returns the current quiet status
+func (q *quietPeriod) status() QuietStatus {
+	q.check(time.Now())
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := QuietStatus{
+		Quiet:       q.reason != "",
+		Reason:      q.reason,
+		Maintenance: q.reason == QuietReasonMaintenance,
+		Mode:        q.mode,
+		Since:       q.since,
+		HeldEvents:  len(q.held),
+	}
+	if q.reason == QuietReasonSilenced {
+		status.SilencedUntil = q.silencedUntilTime()
+	}
+
+	return status
+}
+
+// buildQuietSummary summarises the events held back during a quiet period
+func buildQuietSummary(reason, mode string, since, until time.Time, held []Event) Event {
+	action := "suppressed"
+	if mode == quietModeDowngrade {
+		action = "downgraded"
+	}
+
+	counts := make(map[EventType]int)
+	for _, event := range held {
+		counts[event.Type]++
+	}
+
+	eventTypes := make([]string, 0, len(counts))
+	details := map[string]string{
+		"reason":   reason,
+		"duration": until.Sub(since).Round(time.Second).String(),
+	}
+	for eventType, count := range counts {
+		eventTypes = append(eventTypes, string(eventType))
+		details[string(eventType)] = fmt.Sprintf("%d", count)
+	}
+	sort.Strings(eventTypes)
+
+	lines := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		lines = append(lines, fmt.Sprintf("%s: %d", eventType, counts[EventType(eventType)]))
+	}
+
+	message := fmt.Sprintf("%s ended after %s - %d notifications %s", strings.ReplaceAll(reason, "_", " "), details["duration"], len(held), action)
+	if len(lines) > 0 {
+		message += ":\n" + strings.Join(lines, "\n")
+	}
+
+	return Event{
+		Type:      EventQuietPeriodEnded,
+		Severity:  SeverityInfo,
+		Timestamp: until.UTC(),
+		Message:   message,
+		Details:   details,
+	}
+}
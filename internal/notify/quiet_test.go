@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietPeriod_SuppressesDuringWindow(t *testing.T) {
+	var summaries []Event
+	quiet := newQuietPeriod(config.NotificationQuietHours{
+		Mode:    "suppress",
+		Windows: []config.TimeWindow{{Start: "22:00", End: "07:00", Timezone: "UTC"}},
+	}, func(event Event) {
+		summaries = append(summaries, event)
+	})
+	defer quiet.close()
+
+	night := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+
+	// non-critical events are held back
+	_, send := quiet.apply(Event{Type: EventPeerLost, Severity: SeverityError}, night)
+	assert.False(t, send)
+	_, send = quiet.apply(Event{Type: EventPeerLost, Severity: SeverityError}, night)
+	assert.False(t, send)
+
+	// critical events always go through
+	_, send = quiet.apply(Event{Type: EventBecomingActive, Severity: SeverityCritical}, night)
+	assert.True(t, send)
+	assert.Empty(t, summaries)
+
+	// the window ending sends a summary of what was held back
+	morning := time.Date(2024, 1, 2, 7, 30, 0, 0, time.UTC)
+	_, send = quiet.apply(Event{Type: EventPeerDiscovered, Severity: SeverityInfo}, morning)
+	assert.True(t, send)
+
+	require.Len(t, summaries, 1)
+	assert.Equal(t, EventQuietPeriodEnded, summaries[0].Type)
+	assert.Equal(t, "quiet_hours", summaries[0].Details["reason"])
+	assert.Equal(t, "2", summaries[0].Details[string(EventPeerLost)])
+	assert.Contains(t, summaries[0].Message, "2 notifications suppressed")
+}
+
+func TestQuietPeriod_DowngradesDuringMaintenance(t *testing.T) {
+	var summaries []Event
+	quiet := newQuietPeriod(config.NotificationQuietHours{Mode: "downgrade"}, func(event Event) {
+		summaries = append(summaries, event)
+	})
+	defer quiet.close()
+
+	quiet.setMaintenance(true)
+	assert.True(t, quiet.status().Maintenance)
+
+	event, send := quiet.apply(Event{Type: EventHealthUnhealthy, Severity: SeverityError}, time.Now())
+	assert.True(t, send)
+	assert.Equal(t, SeverityInfo, event.Severity)
+	assert.Equal(t, 1, quiet.status().HeldEvents)
+
+	quiet.setMaintenance(false)
+	assert.False(t, quiet.status().Quiet)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "maintenance", summaries[0].Details["reason"])
+	assert.Contains(t, summaries[0].Message, "1 notifications downgraded")
+}
+
+func TestQuietPeriod_MaintenanceFile(t *testing.T) {
+	maintenanceFile := filepath.Join(t.TempDir(), "maintenance")
+	quiet := newQuietPeriod(config.NotificationQuietHours{Mode: "suppress", MaintenanceFile: maintenanceFile}, func(event Event) {})
+	defer quiet.close()
+
+	assert.False(t, quiet.status().Quiet)
+
+	require.NoError(t, os.WriteFile(maintenanceFile, nil, 0o644))
+	status := quiet.status()
+	assert.True(t, status.Quiet)
+	assert.Equal(t, QuietReasonMaintenance, status.Reason)
+
+	require.NoError(t, os.Remove(maintenanceFile))
+	assert.False(t, quiet.status().Quiet)
+}
+
+func TestQuietPeriod_Silence(t *testing.T) {
+	var summaries []Event
+	quiet := newQuietPeriod(config.NotificationQuietHours{Mode: "suppress"}, func(event Event) {
+		summaries = append(summaries, event)
+	})
+	defer quiet.close()
+
+	until := time.Now().Add(time.Hour)
+	quiet.silence(until)
+
+	status := quiet.status()
+	assert.True(t, status.Quiet)
+	assert.Equal(t, QuietReasonSilenced, status.Reason)
+	assert.False(t, status.Maintenance)
+	assert.WithinDuration(t, until, status.SilencedUntil, time.Millisecond)
+
+	_, send := quiet.apply(Event{Type: EventPeerLost, Severity: SeverityError}, time.Now())
+	assert.False(t, send)
+
+	// silence ends once its time has passed
+	quiet.check(until.Add(time.Second))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "silenced", summaries[0].Details["reason"])
+	assert.Contains(t, summaries[0].Message, "silenced ended after")
+}
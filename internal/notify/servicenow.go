@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// serviceNowProvider opens tickets as ServiceNow records with the Table API
+type serviceNowProvider struct {
+	client        *ticketClient
+	queue         string
+	table         string
+	resolveState  string
+	resolveFields map[string]string
+}
+
+type serviceNowResponse struct {
+	Result serviceNowRecord `json:"result"`
+}
+
+type serviceNowRecord struct {
+	SysID string `json:"sys_id"`
+}
+
+// open creates a record, returning its sys_id
+func (s *serviceNowProvider) open(ctx context.Context, summary string, description string, fields map[string]any) (string, error) {
+	record := map[string]any{}
+	for name, value := range fields {
+		record[name] = value
+	}
+	record["short_description"] = summary
+	record["description"] = description
+	if s.queue != "" {
+		record["assignment_group"] = s.queue
+	}
+
+	var response serviceNowResponse
+	if err := s.client.do(ctx, http.MethodPost, s.tablePath(""), record, &response); err != nil {
+		return "", err
+	}
+	if response.Result.SysID == "" {
+		return "", fmt.Errorf("servicenow API returned no sys_id")
+	}
+
+	return response.Result.SysID, nil
+}
+
+// comment adds a work note to a record
+func (s *serviceNowProvider) comment(ctx context.Context, id string, text string) error {
+	return s.client.do(ctx, http.MethodPatch, s.tablePath(id), map[string]string{"work_notes": text}, nil)
+}
+
+// resolve sets a record's state to the resolve state with the text as its close notes
+func (s *serviceNowProvider) resolve(ctx context.Context, id string, text string) error {
+	record := map[string]string{}
+	for name, value := range s.resolveFields {
+		record[name] = value
+	}
+	record["state"] = s.resolveState
+	record["close_notes"] = text
+
+	return s.client.do(ctx, http.MethodPatch, s.tablePath(id), record, nil)
+}
+
+// tablePath returns the Table API path for the table, or a record in it
+func (s *serviceNowProvider) tablePath(id string) string {
+	path := "/api/now/table/" + url.PathEscape(s.table)
+	if id != "" {
+		path += "/" + url.PathEscape(id)
+	}
+	return path
+}
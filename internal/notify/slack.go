@@ -17,7 +17,14 @@ type SlackOptions struct {
 	Channel    string
 	Username   string
 	IconEmoji  string
-	Logger     *log.Logger
+	// Mentions maps peer names to the member IDs mentioned in events involving them
+	Mentions map[string][]string
+	// Actions are the buttons added to error and critical alerts - see SlackAction*
+	Actions []string
+	// SilenceDuration is how long the silence button holds back non-critical notifications, shown on its label
+	SilenceDuration time.Duration
+	Logger          *log.Logger
+	Transport       http.RoundTripper
 }
 
 // SlackNotifier sends notifications to Slack via webhooks
@@ -26,6 +33,9 @@ type SlackNotifier struct {
 	channel    string
 	username   string
 	iconEmoji  string
+	mentions   mentions
+	actions    []string
+	silenceFor time.Duration
 	httpClient *http.Client
 	logger     *log.Logger
 	enabled    bool
@@ -33,19 +43,49 @@ type SlackNotifier struct {
 
 // Slack webhook payload structures
 type slackPayload struct {
-	Channel     string        `json:"channel,omitempty"`
-	Username    string        `json:"username,omitempty"`
-	IconEmoji   string        `json:"icon_emoji,omitempty"`
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	Text        string            `json:"text,omitempty"`
+	Blocks      []slackBlock      `json:"blocks,omitempty"`
 	Attachments []slackAttachment `json:"attachments"`
 }
 
+// Slack Block Kit structures for action buttons
+type slackBlock struct {
+	Type     string        `json:"type"`
+	Text     *slackText    `json:"text,omitempty"`
+	Elements []slackButton `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackButton struct {
+	Type     string        `json:"type"`
+	Text     slackText     `json:"text"`
+	ActionID string        `json:"action_id"`
+	Value    string        `json:"value"`
+	Style    string        `json:"style,omitempty"`
+	Confirm  *slackConfirm `json:"confirm,omitempty"`
+}
+
+type slackConfirm struct {
+	Title   slackText `json:"title"`
+	Text    slackText `json:"text"`
+	Confirm slackText `json:"confirm"`
+	Deny    slackText `json:"deny"`
+}
+
 type slackAttachment struct {
-	Color      string       `json:"color"`
-	Title      string       `json:"title"`
-	Text       string       `json:"text"`
-	Fields     []slackField `json:"fields,omitempty"`
-	Footer     string       `json:"footer"`
-	Timestamp  int64        `json:"ts"`
+	Color     string       `json:"color"`
+	Title     string       `json:"title"`
+	Text      string       `json:"text"`
+	Fields    []slackField `json:"fields,omitempty"`
+	Footer    string       `json:"footer"`
+	Timestamp int64        `json:"ts"`
 }
 
 type slackField struct {
@@ -61,7 +101,10 @@ func NewSlackNotifier(opts SlackOptions) *SlackNotifier {
 		channel:    opts.Channel,
 		username:   opts.Username,
 		iconEmoji:  opts.IconEmoji,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		mentions:   opts.Mentions,
+		actions:    opts.Actions,
+		silenceFor: opts.SilenceDuration,
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: opts.Transport},
 		logger:     opts.Logger,
 		enabled:    opts.WebhookURL != "",
 	}
@@ -99,6 +142,22 @@ func (s *SlackNotifier) Send(ctx context.Context, event Event) error {
 		Attachments: []slackAttachment{attachment},
 	}
 
+	// Mention the operators of the peer involved - mentions only notify from the top level text
+	if memberIDs := s.mentions.forEvent(event); len(memberIDs) > 0 {
+		payload.Text = slackMentions(memberIDs)
+	}
+
+	// Add action buttons to alerts - blocks replace the top level text so mentions move into a section
+	if buttons := s.getButtons(event); len(buttons) > 0 {
+		if payload.Text != "" {
+			payload.Blocks = append(payload.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: payload.Text}})
+		}
+		payload.Blocks = append(payload.Blocks, slackBlock{Type: "actions", Elements: buttons})
+		if payload.Text == "" {
+			payload.Text = eventTitle(event)
+		}
+	}
+
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal slack payload: %w", err)
@@ -110,17 +169,53 @@ func (s *SlackNotifier) Send(ctx context.Context, event Event) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := doRequest(s.httpClient, req, "slack webhook")
 	if err != nil {
 		return fmt.Errorf("failed to send slack notification: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	return nil
+}
+
+// getButtons returns the action buttons for error and critical events - their value is the validator the
+// event is from, so a callback can tell which manager should act on it
+func (s *SlackNotifier) getButtons(event Event) []slackButton {
+	if event.Severity != SeverityError && event.Severity != SeverityCritical {
+		return nil
 	}
 
-	return nil
+	buttons := make([]slackButton, 0, len(s.actions))
+	for _, action := range s.actions {
+		button := slackButton{
+			Type:     "button",
+			ActionID: action,
+			Value:    event.ValidatorName,
+		}
+
+		switch action {
+		case SlackActionAcknowledge:
+			button.Text = slackText{Type: "plain_text", Text: "Acknowledge"}
+			button.Style = "primary"
+		case SlackActionSilence:
+			button.Text = slackText{Type: "plain_text", Text: fmt.Sprintf("Silence %s", formatSilenceDuration(s.silenceFor))}
+		case SlackActionFailover:
+			button.Text = slackText{Type: "plain_text", Text: "Trigger failover"}
+			button.Style = "danger"
+			button.Confirm = &slackConfirm{
+				Title:   slackText{Type: "plain_text", Text: "Trigger failover?"},
+				Text:    slackText{Type: "plain_text", Text: fmt.Sprintf("%s will take over if it is still awaiting takeover confirmation.", event.ValidatorName)},
+				Confirm: slackText{Type: "plain_text", Text: "Trigger failover"},
+				Deny:    slackText{Type: "plain_text", Text: "Cancel"},
+			}
+		default:
+			continue
+		}
+
+		buttons = append(buttons, button)
+	}
+
+	return buttons
 }
 
 func (s *SlackNotifier) getTitle(event Event) string {
@@ -136,39 +231,7 @@ func (s *SlackNotifier) getTitle(event Event) string {
 		emoji = ":information_source:"
 	}
 
-	var title string
-	switch event.Type {
-	case EventStartup:
-		title = "Validator HA Started"
-	case EventShutdown:
-		title = "Validator HA Stopped"
-	case EventBecomingActive:
-		title = "FAILOVER: Becoming Active"
-	case EventBecameActive:
-		title = "Became Active"
-	case EventBecomingPassive:
-		title = "Becoming Passive"
-	case EventBecamePassive:
-		title = "Became Passive"
-	case EventHealthUnhealthy:
-		title = "Health Alert: Unhealthy"
-	case EventHealthRecovered:
-		title = "Health Recovered"
-	case EventDelinquent:
-		title = "CRITICAL: Validator Delinquent"
-	case EventGossipLost:
-		title = "Lost from Gossip"
-	case EventGossipRecovered:
-		title = "Gossip Recovered"
-	case EventPeerDiscovered:
-		title = "Peer Discovered"
-	case EventPeerLost:
-		title = "Peer Lost"
-	default:
-		title = string(event.Type)
-	}
-
-	return fmt.Sprintf("%s %s", emoji, title)
+	return fmt.Sprintf("%s %s", emoji, eventTitle(event))
 }
 
 func (s *SlackNotifier) getDescription(event Event) string {
@@ -244,5 +307,9 @@ func (s *SlackNotifier) getFields(event Event) []slackField {
 		fields = append(fields, slackField{Title: k, Value: v, Short: true})
 	}
 
+	if len(event.Links) > 0 {
+		fields = append(fields, slackField{Title: "Links", Value: slackLinks(event.Links)})
+	}
+
 	return fields
 }
@@ -0,0 +1,232 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Slack action button IDs
+const (
+	SlackActionAcknowledge = "acknowledge"
+	SlackActionSilence     = "silence"
+	SlackActionFailover    = "failover"
+)
+
+const (
+	// slackSignatureMaxAge is how old a signed callback may be before it is rejected as a possible replay
+	slackSignatureMaxAge = 5 * time.Minute
+	// slackResponseURLPrefix is the only host action replies are posted to
+	slackResponseURLPrefix = "https://hooks.slack.com/"
+	// slackMaxCallbackBytes is the maximum callback body size read
+	slackMaxCallbackBytes = 1 << 20
+)
+
+// SlackActionFunc handles an action button press, returning the reply posted to the channel -
+// user is the Slack member ID of who pressed it
+type SlackActionFunc func(user string) string
+
+// SlackActionsOptions contains options for creating a Slack action callback handler
+type SlackActionsOptions struct {
+	SigningSecret string
+	// ValidatorName is this validator - presses on alerts from other validators are refused
+	ValidatorName string
+	// Actions maps action button IDs to their handlers
+	Actions   map[string]SlackActionFunc
+	Logger    *log.Logger
+	Transport http.RoundTripper
+}
+
+// SlackActionsHandler handles Slack interactivity callbacks for alert action buttons
+type SlackActionsHandler struct {
+	signingSecret     string
+	validatorName     string
+	actions           map[string]SlackActionFunc
+	responseURLPrefix string
+	httpClient        *http.Client
+	logger            *log.Logger
+}
+
+// slackActionCallback is the block_actions interaction payload
+type slackActionCallback struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// slackActionResponse is posted to the callback response_url
+type slackActionResponse struct {
+	ResponseType    string `json:"response_type"`
+	ReplaceOriginal bool   `json:"replace_original"`
+	Text            string `json:"text"`
+}
+
+// NewSlackActionsHandler creates a new Slack action callback handler
+func NewSlackActionsHandler(opts SlackActionsOptions) *SlackActionsHandler {
+	return &SlackActionsHandler{
+		signingSecret:     opts.SigningSecret,
+		validatorName:     opts.ValidatorName,
+		actions:           opts.Actions,
+		responseURLPrefix: slackResponseURLPrefix,
+		httpClient:        &http.Client{Timeout: 10 * time.Second, Transport: opts.Transport},
+		logger:            opts.Logger,
+	}
+}
+
+// ServeHTTP verifies the callback signature, runs the pressed action and posts its reply to the channel
+func (h *SlackActionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxCallbackBytes))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.verify(r.Header, body, time.Now()); err != nil {
+		h.logger.Warn("rejected slack action callback", "remote_addr", r.RemoteAddr, "error", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	var callback slackActionCallback
+	if err := json.Unmarshal([]byte(form.Get("payload")), &callback); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// Slack only needs an acknowledgement here - replies go to the response_url
+	w.WriteHeader(http.StatusOK)
+
+	if callback.Type != "block_actions" || len(callback.Actions) == 0 {
+		return
+	}
+
+	action := callback.Actions[0]
+	reply := h.run(action.ActionID, action.Value, callback.User.ID)
+	h.logger.Info("slack action handled",
+		"action", action.ActionID,
+		"validator", action.Value,
+		"user_id", callback.User.ID,
+		"username", callback.User.Username,
+	)
+
+	go h.respond(callback.ResponseURL, reply)
+}
+
+// run runs an action pressed on an alert from validatorName, returning the reply
+func (h *SlackActionsHandler) run(actionID, validatorName, user string) string {
+	if validatorName != h.validatorName {
+		return fmt.Sprintf("This alert is from %s - only its own HA manager can act on it, this is %s", validatorName, h.validatorName)
+	}
+
+	action, ok := h.actions[actionID]
+	if !ok {
+		return fmt.Sprintf("Action %s is not enabled on %s", actionID, h.validatorName)
+	}
+
+	return action(user)
+}
+
+// verify checks the callback was signed with the signing secret recently
+func (h *SlackActionsHandler) verify(header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp %q", timestamp)
+	}
+
+	if age := now.Sub(time.Unix(signedAt, 0)); age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return fmt.Errorf("request timestamp is %s old", age.Round(time.Second))
+	}
+
+	expected := slackSignature(h.signingSecret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
+
+// slackSignature computes the v0 request signature for a callback body
+func slackSignature(signingSecret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// respond posts an action reply to the channel the button was pressed in
+func (h *SlackActionsHandler) respond(responseURL, text string) {
+	if !strings.HasPrefix(responseURL, h.responseURLPrefix) {
+		h.logger.Warn("not replying to slack action - unexpected response_url", "response_url", responseURL)
+		return
+	}
+
+	jsonData, err := json.Marshal(slackActionResponse{
+		ResponseType:    "in_channel",
+		ReplaceOriginal: false,
+		Text:            text,
+	})
+	if err != nil {
+		h.logger.Error("failed to marshal slack action reply", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		h.logger.Error("failed to create slack action reply request", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(h.httpClient, req, "slack action reply")
+	if err != nil {
+		h.logger.Error("failed to send slack action reply", "error", redactURLError(err))
+		return
+	}
+	resp.Body.Close()
+}
+
+// formatSilenceDuration formats a silence duration for a button label, e.g. 1h or 30m
+func formatSilenceDuration(d time.Duration) string {
+	formatted := d.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+	return formatted
+}
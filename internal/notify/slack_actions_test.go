@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSlackSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signedSlackRequest builds a button press callback for actionID signed with secret at signedAt
+func signedSlackRequest(secret string, signedAt time.Time, actionID, value, responseURL string) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": "U01ABCDEF", "username": "operator"},
+		"response_url": responseURL,
+		"actions":      []map[string]string{{"action_id": actionID, "value": value}},
+	})
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications/slack/actions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", slackSignature(secret, timestamp, []byte(body)))
+	return req
+}
+
+func TestSlackSignature(t *testing.T) {
+	// example from the Slack request signing documentation
+	body := "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	assert.Equal(t, "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503", slackSignature(testSlackSigningSecret, "1531420618", []byte(body)))
+}
+
+func TestSlackActionsHandler_Verify(t *testing.T) {
+	handler := NewSlackActionsHandler(SlackActionsOptions{SigningSecret: testSlackSigningSecret, Logger: log.New(io.Discard)})
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		wantErr string
+	}{
+		{name: "valid", req: signedSlackRequest(testSlackSigningSecret, now, "acknowledge", "validator-1", "")},
+		{name: "wrong secret", req: signedSlackRequest("other-secret", now, "acknowledge", "validator-1", ""), wantErr: "signature mismatch"},
+		{name: "stale", req: signedSlackRequest(testSlackSigningSecret, now.Add(-10*time.Minute), "acknowledge", "validator-1", ""), wantErr: "old"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := io.ReadAll(tt.req.Body)
+			require.NoError(t, err)
+
+			err = handler.verify(tt.req.Header, body, now)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	// a tampered body no longer matches its signature
+	req := signedSlackRequest(testSlackSigningSecret, now, "acknowledge", "validator-1", "")
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Error(t, handler.verify(req.Header, append(body, 'x'), now))
+}
+
+func TestSlackActionsHandler_ServeHTTP(t *testing.T) {
+	replies := make(chan slackActionResponse, 1)
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reply slackActionResponse
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reply))
+		replies <- reply
+	}))
+	defer responseServer.Close()
+
+	pressedBy := ""
+	handler := NewSlackActionsHandler(SlackActionsOptions{
+		SigningSecret: testSlackSigningSecret,
+		ValidatorName: "validator-1",
+		Actions: map[string]SlackActionFunc{
+			SlackActionAcknowledge: func(user string) string {
+				pressedBy = user
+				return "acknowledged"
+			},
+		},
+		Logger: log.New(io.Discard),
+	})
+	handler.responseURLPrefix = responseServer.URL
+
+	// unsigned requests are rejected without running the action
+	req := signedSlackRequest("other-secret", time.Now(), SlackActionAcknowledge, "validator-1", responseServer.URL)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, pressedBy)
+
+	req = signedSlackRequest(testSlackSigningSecret, time.Now(), SlackActionAcknowledge, "validator-1", responseServer.URL)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "U01ABCDEF", pressedBy)
+
+	select {
+	case reply := <-replies:
+		assert.Equal(t, "acknowledged", reply.Text)
+		assert.Equal(t, "in_channel", reply.ResponseType)
+		assert.False(t, reply.ReplaceOriginal)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reply posted to response_url")
+	}
+}
+
+func TestSlackActionsHandler_Run(t *testing.T) {
+	handler := NewSlackActionsHandler(SlackActionsOptions{
+		ValidatorName: "validator-1",
+		Actions: map[string]SlackActionFunc{
+			SlackActionSilence: func(user string) string { return "silenced" },
+		},
+		Logger: log.New(io.Discard),
+	})
+
+	assert.Equal(t, "silenced", handler.run(SlackActionSilence, "validator-1", "U01ABCDEF"))
+	assert.Contains(t, handler.run(SlackActionSilence, "validator-2", "U01ABCDEF"), "only its own HA manager")
+	assert.Contains(t, handler.run(SlackActionFailover, "validator-1", "U01ABCDEF"), "not enabled")
+}
+
+func TestSlackNotifier_Buttons(t *testing.T) {
+	var payload slackPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload = slackPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(SlackOptions{
+		WebhookURL:      server.URL,
+		Actions:         []string{SlackActionAcknowledge, SlackActionSilence, SlackActionFailover},
+		SilenceDuration: time.Hour,
+	})
+
+	err := notifier.Send(context.Background(), Event{Type: EventDelinquent, Severity: SeverityCritical, ValidatorName: "validator-1"})
+	require.NoError(t, err)
+	require.Len(t, payload.Blocks, 1)
+	buttons := payload.Blocks[0].Elements
+	require.Len(t, buttons, 3)
+	assert.Equal(t, "Acknowledge", buttons[0].Text.Text)
+	assert.Equal(t, "Silence 1h", buttons[1].Text.Text)
+	assert.Equal(t, "Trigger failover", buttons[2].Text.Text)
+	assert.NotNil(t, buttons[2].Confirm)
+	for _, button := range buttons {
+		assert.Equal(t, "validator-1", button.Value)
+	}
+	assert.NotEmpty(t, payload.Text)
+
+	// informational events get no buttons
+	err = notifier.Send(context.Background(), Event{Type: EventStartup, Severity: SeverityInfo, ValidatorName: "validator-1"})
+	require.NoError(t, err)
+	assert.Empty(t, payload.Blocks)
+}
+
+func TestFormatSilenceDuration(t *testing.T) {
+	assert.Equal(t, "1h", formatSilenceDuration(time.Hour))
+	assert.Equal(t, "30m", formatSilenceDuration(30*time.Minute))
+	assert.Equal(t, "1h30m", formatSilenceDuration(90*time.Minute))
+	assert.Equal(t, "45s", formatSilenceDuration(45*time.Second))
+}
@@ -0,0 +1,296 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const spoolFileExtension = ".json"
+
+// SpoolOptions contains options for creating a spool
+type SpoolOptions struct {
+	Dir          string
+	MaxAge       time.Duration
+	MaxSizeBytes int64
+	Logger       *log.Logger
+}
+
+// Spool persists events that failed delivery to disk so they can be replayed
+// once the notifiers are reachable again
+type Spool struct {
+	dir          string
+	maxAge       time.Duration
+	maxSizeBytes int64
+	logger       *log.Logger
+	mu           sync.Mutex
+}
+
+// spoolEntry is the on-disk representation of a spooled event
+type spoolEntry struct {
+	SpooledAt time.Time `json:"spooled_at"`
+	Notifiers []string  `json:"notifiers"`
+	Event     Event     `json:"event"`
+}
+
+// spoolFile is a spooled entry as found on disk
+type spoolFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// NewSpool creates a new spool, creating the spool directory if it does not exist
+func NewSpool(opts SpoolOptions) (*Spool, error) {
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory %s: %w", opts.Dir, err)
+	}
+
+	return &Spool{
+		dir:          opts.Dir,
+		maxAge:       opts.MaxAge,
+		maxSizeBytes: opts.MaxSizeBytes,
+		logger:       opts.Logger,
+	}, nil
+}
+
+// Write persists an event that failed delivery to the given notifiers
+func (s *Spool) Write(event Event, notifierNames []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := spoolEntry{
+		SpooledAt: time.Now().UTC(),
+		Notifiers: notifierNames,
+		Event:     event,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spool entry: %w", err)
+	}
+
+	// name files so that lexical order is chronological order
+	fileName := fmt.Sprintf("%d-%s%s", entry.SpooledAt.UnixNano(), event.Type, spoolFileExtension)
+	tmpPath := filepath.Join(s.dir, "."+fileName)
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write spool entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, fileName)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write spool entry: %w", err)
+	}
+
+	s.logger.Warn("notification spooled for later delivery", "event", event.Type, "services", notifierNames)
+
+	s.prune()
+	return nil
+}
+
+// Replay attempts to redeliver all spooled events using the supplied send function.
+// send is called with the spooled event and the notifier names it failed on, and must return
+// the notifier names that still failed. Entries are removed once all their notifiers succeed.
+// The spool is not locked while send runs, so events can still be spooled during a slow replay.
+func (s *Spool) Replay(send func(event Event, notifierNames []string) (failed []string)) {
+	s.mu.Lock()
+	s.prune()
+	files, err := s.files()
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("failed to list spool directory", "error", err)
+		return
+	}
+
+	for _, f := range files {
+		entry, ok := s.read(f)
+		if !ok {
+			continue
+		}
+
+		failed := send(entry.Event, entry.Notifiers)
+		if len(failed) == 0 {
+			s.logger.Info("spooled notification delivered", "event", entry.Event.Type, "spooled_at", entry.SpooledAt.Format(time.RFC3339))
+			s.remove(f)
+			continue
+		}
+
+		// still failing - keep the entry for the notifiers that did not succeed and stop replaying
+		// to preserve ordering, we'll try again on the next replay
+		if len(failed) != len(entry.Notifiers) {
+			entry.Notifiers = failed
+			s.rewrite(f, entry)
+		}
+		return
+	}
+}
+
+// read reads a spooled entry, discarding it if corrupt - ok is false if there is nothing to replay
+func (s *Spool) read(f spoolFile) (entry spoolEntry, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		// pruned since it was listed
+		if !os.IsNotExist(err) {
+			s.logger.Error("failed to read spool entry", "file", f.path, "error", err)
+		}
+		return entry, false
+	}
+
+	if err := json.Unmarshal(data, &entry); err != nil {
+		s.logger.Error("discarding corrupt spool entry", "file", f.path, "error", err)
+		os.Remove(f.path)
+		return entry, false
+	}
+
+	return entry, true
+}
+
+// remove removes a delivered entry
+func (s *Spool) remove(f spoolFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	os.Remove(f.path)
+}
+
+// rewrite replaces an entry with one for the notifiers that still failed, unless it was pruned during the replay
+func (s *Spool) rewrite(f spoolFile, entry spoolEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(f.path); err != nil {
+		return
+	}
+
+	if data, err := json.Marshal(entry); err == nil && os.WriteFile(f.path, data, 0o600) == nil {
+		// keep the original age so max age is still honoured
+		os.Chtimes(f.path, f.modTime, f.modTime)
+	}
+}
+
+// Len returns the number of spooled events
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.files()
+	if err != nil {
+		return 0
+	}
+	return len(files)
+}
+
+// prune discards entries older than the max age and the oldest entries when the spool exceeds its max size
+// caller must hold the lock
+func (s *Spool) prune() {
+	files, err := s.files()
+	if err != nil {
+		s.logger.Error("failed to list spool directory", "error", err)
+		return
+	}
+
+	var totalSize int64
+	kept := make([]spoolFile, 0, len(files))
+	for _, f := range files {
+		if s.maxAge > 0 && time.Since(f.modTime) > s.maxAge {
+			s.logger.Warn("discarding spooled notification older than max age", "file", filepath.Base(f.path), "max_age", s.maxAge)
+			os.Remove(f.path)
+			continue
+		}
+		totalSize += f.size
+		kept = append(kept, f)
+	}
+
+	// oldest first
+	for i := 0; s.maxSizeBytes > 0 && totalSize > s.maxSizeBytes && i < len(kept); i++ {
+		s.logger.Warn("discarding spooled notification - spool exceeds max size", "file", filepath.Base(kept[i].path), "max_size_bytes", s.maxSizeBytes)
+		os.Remove(kept[i].path)
+		totalSize -= kept[i].size
+	}
+}
+
+// files returns the spool entries on disk ordered oldest first
+func (s *Spool) files() ([]spoolFile, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]spoolFile, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, spoolFileExtension) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, spoolFile{
+			path:    filepath.Join(s.dir, name),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+
+	return files, nil
+}
+
+// spoolReplayer periodically replays the spool, so spooled events are delivered once the notifiers are reachable
+// again even if nothing else is sent
+type spoolReplayer struct {
+	interval time.Duration
+	replay   func()
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newSpoolReplayer creates a spoolReplayer that calls replay every interval
+func newSpoolReplayer(interval time.Duration, replay func()) *spoolReplayer {
+	r := &spoolReplayer{
+		interval: interval,
+		replay:   replay,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// run replays the spool every interval until closed
+func (r *spoolReplayer) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.replay()
+		}
+	}
+}
+
+// close stops the replayer, waiting for a replay in progress to finish
+func (r *spoolReplayer) close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+}
@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestSpool(t *testing.T, maxAge time.Duration, maxSizeBytes int64) *Spool {
+	spool, err := NewSpool(SpoolOptions{
+		Dir:          filepath.Join(t.TempDir(), "spool"),
+		MaxAge:       maxAge,
+		MaxSizeBytes: maxSizeBytes,
+		Logger:       log.WithPrefix("test"),
+	})
+	require.NoError(t, err)
+	return spool
+}
+
+func TestSpool_WriteAndReplay(t *testing.T) {
+	spool := createTestSpool(t, time.Hour, 0)
+
+	require.NoError(t, spool.Write(Event{Type: EventGossipLost, ValidatorName: "test"}, []string{"slack"}))
+	require.NoError(t, spool.Write(Event{Type: EventPeerLost, ValidatorName: "test"}, []string{"slack", "discord"}))
+	assert.Equal(t, 2, spool.Len())
+
+	// replay in order, all succeed
+	replayed := []EventType{}
+	spool.Replay(func(event Event, notifierNames []string) []string {
+		replayed = append(replayed, event.Type)
+		return nil
+	})
+
+	assert.Equal(t, []EventType{EventGossipLost, EventPeerLost}, replayed)
+	assert.Equal(t, 0, spool.Len())
+}
+
+func TestSpool_ReplayStopsOnFailure(t *testing.T) {
+	spool := createTestSpool(t, time.Hour, 0)
+
+	require.NoError(t, spool.Write(Event{Type: EventGossipLost}, []string{"slack", "discord"}))
+	require.NoError(t, spool.Write(Event{Type: EventPeerLost}, []string{"slack"}))
+
+	// first entry only partially delivered - replay must stop and keep remaining notifiers
+	calls := 0
+	spool.Replay(func(event Event, notifierNames []string) []string {
+		calls++
+		return []string{"discord"}
+	})
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 2, spool.Len())
+
+	// next replay should only target the notifier that failed
+	var gotNotifiers []string
+	spool.Replay(func(event Event, notifierNames []string) []string {
+		if gotNotifiers == nil {
+			gotNotifiers = notifierNames
+		}
+		return nil
+	})
+	assert.Equal(t, []string{"discord"}, gotNotifiers)
+	assert.Equal(t, 0, spool.Len())
+}
+
+func TestSpool_ReplaySendsUnlocked(t *testing.T) {
+	spool := createTestSpool(t, time.Hour, 0)
+
+	require.NoError(t, spool.Write(Event{Type: EventGossipLost}, []string{"slack"}))
+
+	// events failing while a replay is sending must still be spooled
+	replayed := make(chan struct{})
+	go func() {
+		defer close(replayed)
+		spool.Replay(func(event Event, notifierNames []string) []string {
+			assert.NoError(t, spool.Write(Event{Type: EventPeerLost}, []string{"slack"}))
+			return nil
+		})
+	}()
+
+	select {
+	case <-replayed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay held the spool lock while sending")
+	}
+	assert.Equal(t, 1, spool.Len())
+}
+
+func TestSpoolReplayer(t *testing.T) {
+	var replays atomic.Int32
+	replayer := newSpoolReplayer(10*time.Millisecond, func() { replays.Add(1) })
+
+	// replays without anything else being sent
+	assert.Eventually(t, func() bool { return replays.Load() >= 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// and stops once closed
+	replayer.close()
+	stoppedAt := replays.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stoppedAt, replays.Load())
+}
+
+func TestSpool_PruneMaxAge(t *testing.T) {
+	spool := createTestSpool(t, time.Minute, 0)
+
+	require.NoError(t, spool.Write(Event{Type: EventGossipLost}, []string{"slack"}))
+	files, err := spool.files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// age the entry past max age
+	old := time.Now().Add(-2 * time.Minute)
+	require.NoError(t, os.Chtimes(files[0].path, old, old))
+
+	spool.Replay(func(event Event, notifierNames []string) []string {
+		t.Fatal("expired entry must not be replayed")
+		return nil
+	})
+	assert.Equal(t, 0, spool.Len())
+}
+
+func TestSpool_PruneMaxSize(t *testing.T) {
+	// small enough that only one entry fits
+	spool := createTestSpool(t, time.Hour, 300)
+
+	require.NoError(t, spool.Write(Event{Type: EventGossipLost}, []string{"slack"}))
+	require.NoError(t, spool.Write(Event{Type: EventPeerLost}, []string{"slack"}))
+	assert.Equal(t, 1, spool.Len())
+
+	// the newest entry is kept
+	spool.Replay(func(event Event, notifierNames []string) []string {
+		assert.Equal(t, EventPeerLost, event.Type)
+		return nil
+	})
+}
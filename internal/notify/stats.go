@@ -0,0 +1,70 @@
+package notify
+
+import "sync"
+
+// Stats counts the events emitted and notifications delivered over the life of a manager
+type Stats struct {
+	// Events is the number of events emitted by type, whether or not they were sent
+	Events map[EventType]int `json:"events"`
+	// Delivered and Failed count sends to individual services, including spool replays
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+}
+
+// TotalEvents returns the number of events emitted of all types
+func (s Stats) TotalEvents() int {
+	total := 0
+	for _, count := range s.Events {
+		total += count
+	}
+	return total
+}
+
+// SuccessRate returns the fraction of sends that were delivered, false if nothing was sent
+func (s Stats) SuccessRate() (float64, bool) {
+	sent := s.Delivered + s.Failed
+	if sent == 0 {
+		return 0, false
+	}
+	return float64(s.Delivered) / float64(sent), true
+}
+
+// statsCounter counts events and deliveries safely across notifying goroutines
+type statsCounter struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+func newStatsCounter() *statsCounter {
+	return &statsCounter{stats: Stats{Events: make(map[EventType]int)}}
+}
+
+// event counts an emitted event
+func (c *statsCounter) event(eventType EventType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Events[eventType]++
+}
+
+// delivery counts a send to a service
+func (c *statsCounter) delivery(ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ok {
+		c.stats.Delivered++
+	} else {
+		c.stats.Failed++
+	}
+}
+
+// snapshot returns a copy of the counts
+func (c *statsCounter) snapshot() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events := make(map[EventType]int, len(c.stats.Events))
+	for eventType, count := range c.stats.Events {
+		events[eventType] = count
+	}
+	return Stats{Events: events, Delivered: c.stats.Delivered, Failed: c.stats.Failed}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import "sync"
+
+// Subscribers fans events out to in-process subscribers, such as a program embedding the HA manager
+type Subscribers struct {
+	mu   sync.Mutex
+	next int
+	subs map[int]chan Event
+}
+
+// NewSubscribers creates an empty set of subscribers
+func NewSubscribers() *Subscribers {
+	return &Subscribers{subs: make(map[int]chan Event)}
+}
+
+// Subscribe returns a channel receiving every event published from now on, and a func to unsubscribe which closes
+// it. Events are dropped for a subscriber whose buffer is full rather than blocking the HA manager.
+func (s *Subscribers) Subscribe(buffer int) (<-chan Event, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.next
+	s.next++
+	events := make(chan Event, buffer)
+	s.subs[id] = events
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subs, id)
+			close(events)
+		})
+	}
+
+	return events, unsubscribe
+}
+
+// publish sends an event to every subscriber without blocking
+func (s *Subscribers) publish(event Event) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, events := range s.subs {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribers(t *testing.T) {
+	subscribers := NewSubscribers()
+	first, unsubscribeFirst := subscribers.Subscribe(1)
+	second, unsubscribeSecond := subscribers.Subscribe(1)
+	defer unsubscribeSecond()
+
+	subscribers.publish(Event{Type: EventStartup})
+	assert.Equal(t, EventStartup, (<-first).Type)
+	assert.Equal(t, EventStartup, (<-second).Type)
+
+	// a full subscriber drops events rather than blocking
+	subscribers.publish(Event{Type: EventPeerLost})
+	subscribers.publish(Event{Type: EventPeerDiscovered})
+	assert.Equal(t, EventPeerLost, (<-second).Type)
+	assert.Len(t, second, 0)
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	<-first
+	_, open := <-first
+	assert.False(t, open, "unsubscribe should close the channel")
+}
+
+func TestManager_NotifyPublishesWhenDisabled(t *testing.T) {
+	subscribers := NewSubscribers()
+	events, unsubscribe := subscribers.Subscribe(1)
+	defer unsubscribe()
+
+	manager := NewManager(ManagerOptions{
+		Config:        &config.NotificationConfig{Enabled: false},
+		ValidatorName: "validator-1",
+		Subscribers:   subscribers,
+	})
+	manager.Notify(Event{Type: EventDelinquent})
+
+	require.Len(t, events, 1)
+	event := <-events
+	assert.Equal(t, EventDelinquent, event.Type)
+	assert.Equal(t, SeverityCritical, event.Severity)
+	assert.False(t, event.Timestamp.IsZero())
+}
@@ -18,7 +18,10 @@ type TelegramOptions struct {
 	BotToken  string
 	ChatID    string
 	ParseMode string
+	// Mentions maps peer names to the usernames mentioned in events involving them
+	Mentions  map[string][]string
 	Logger    *log.Logger
+	Transport http.RoundTripper
 }
 
 // TelegramNotifier sends notifications to Telegram via Bot API
@@ -26,6 +29,7 @@ type TelegramNotifier struct {
 	botToken   string
 	chatID     string
 	parseMode  string
+	mentions   mentions
 	httpClient *http.Client
 	logger     *log.Logger
 	enabled    bool
@@ -44,7 +48,8 @@ func NewTelegramNotifier(opts TelegramOptions) *TelegramNotifier {
 		botToken:   opts.BotToken,
 		chatID:     opts.ChatID,
 		parseMode:  opts.ParseMode,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		mentions:   opts.Mentions,
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: opts.Transport},
 		logger:     opts.Logger,
 		enabled:    opts.BotToken != "" && opts.ChatID != "",
 	}
@@ -68,6 +73,11 @@ func (t *TelegramNotifier) Send(ctx context.Context, event Event) error {
 
 	message := t.formatMessage(event)
 
+	// Mention the operators of the peer involved
+	if usernames := t.mentions.forEvent(event); len(usernames) > 0 {
+		message += "\n\n" + telegramMentions(usernames, t.parseMode)
+	}
+
 	payload := telegramPayload{
 		ChatID:    t.chatID,
 		Text:      message,
@@ -86,16 +96,12 @@ func (t *TelegramNotifier) Send(ctx context.Context, event Event) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := t.httpClient.Do(req)
+	resp, err := doRequest(t.httpClient, req, "telegram API")
 	if err != nil {
-		return fmt.Errorf("failed to send telegram notification: %w", err)
+		return fmt.Errorf("failed to send telegram notification: %w", redactURLError(err))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("telegram API returned status %d", resp.StatusCode)
-	}
-
 	return nil
 }
 
@@ -112,7 +118,7 @@ func (t *TelegramNotifier) formatMessage(event Event) string {
 		emoji = "\u2139\uFE0F" // Info
 	}
 
-	title := t.getTitle(event)
+	title := eventTitle(event)
 	description := t.getDescription(event)
 
 	if t.parseMode == "HTML" {
@@ -139,39 +145,6 @@ func (t *TelegramNotifier) formatMessage(event Event) string {
 	)
 }
 
-func (t *TelegramNotifier) getTitle(event Event) string {
-	switch event.Type {
-	case EventStartup:
-		return "Validator HA Started"
-	case EventShutdown:
-		return "Validator HA Stopped"
-	case EventBecomingActive:
-		return "FAILOVER: Becoming Active"
-	case EventBecameActive:
-		return "Became Active"
-	case EventBecomingPassive:
-		return "Becoming Passive"
-	case EventBecamePassive:
-		return "Became Passive"
-	case EventHealthUnhealthy:
-		return "Health Alert: Unhealthy"
-	case EventHealthRecovered:
-		return "Health Recovered"
-	case EventDelinquent:
-		return "CRITICAL: Validator Delinquent"
-	case EventGossipLost:
-		return "Lost from Gossip"
-	case EventGossipRecovered:
-		return "Gossip Recovered"
-	case EventPeerDiscovered:
-		return "Peer Discovered"
-	case EventPeerLost:
-		return "Peer Lost"
-	default:
-		return string(event.Type)
-	}
-}
-
 func (t *TelegramNotifier) getDescription(event Event) string {
 	if event.Message != "" {
 		return event.Message
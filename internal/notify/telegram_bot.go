@@ -0,0 +1,292 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// TelegramCommandFunc handles a bot command, returning the reply - args are the words following the command
+type TelegramCommandFunc func(args []string) string
+
+// TelegramBotOptions contains options for creating a Telegram bot
+type TelegramBotOptions struct {
+	BotToken string
+	// AllowedChatIDs are the chats commands are accepted from - messages from any other chat are ignored
+	AllowedChatIDs []string
+	// RateLimitPerMinute is the maximum number of commands answered per chat per minute
+	RateLimitPerMinute int
+	// PollTimeout is how long each getUpdates long poll waits for new messages
+	PollTimeout time.Duration
+	// Commands maps command names, without the leading slash, to their handlers
+	Commands  map[string]TelegramCommandFunc
+	Logger    *log.Logger
+	Transport http.RoundTripper
+}
+
+// TelegramBot answers commands sent to the Telegram bot from allow-listed chats
+type TelegramBot struct {
+	botToken       string
+	apiBase        string
+	allowedChatIDs []string
+	pollTimeout    time.Duration
+	commands       map[string]TelegramCommandFunc
+	limiter        *chatRateLimiter
+	httpClient     *http.Client
+	logger         *log.Logger
+}
+
+// Telegram getUpdates response
+type telegramUpdatesResponse struct {
+	OK          bool             `json:"ok"`
+	Description string           `json:"description"`
+	Result      []telegramUpdate `json:"result"`
+}
+
+type telegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *telegramMessage `json:"message"`
+}
+
+type telegramMessage struct {
+	Chat telegramChat  `json:"chat"`
+	From *telegramUser `json:"from"`
+	Text string        `json:"text"`
+}
+
+type telegramChat struct {
+	ID int64 `json:"id"`
+}
+
+type telegramUser struct {
+	Username string `json:"username"`
+}
+
+// NewTelegramBot creates a new Telegram bot
+func NewTelegramBot(opts TelegramBotOptions) *TelegramBot {
+	return &TelegramBot{
+		botToken:       opts.BotToken,
+		apiBase:        telegramAPIBase,
+		allowedChatIDs: opts.AllowedChatIDs,
+		pollTimeout:    opts.PollTimeout,
+		commands:       opts.Commands,
+		limiter:        newChatRateLimiter(opts.RateLimitPerMinute, time.Minute),
+		// allow for the long poll on top of the usual request timeout
+		httpClient: &http.Client{Timeout: opts.PollTimeout + 10*time.Second, Transport: opts.Transport},
+		logger:     opts.Logger,
+	}
+}
+
+// Run polls for commands until ctx is done. Commands sent while the bot was not running are discarded
+// so a stale command is never acted on.
+func (b *TelegramBot) Run(ctx context.Context) {
+	b.logger.Info("telegram bot commands enabled", "allowed_chat_ids", b.allowedChatIDs)
+
+	offset, err := b.skipPendingUpdates(ctx)
+	if err != nil {
+		b.logger.Warn("failed to discard pending telegram updates", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		updates, err := b.getUpdates(ctx, offset, b.pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.logger.Warn("failed to get telegram updates", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			b.handleUpdate(ctx, update)
+		}
+	}
+}
+
+// skipPendingUpdates acknowledges updates sent before the bot started and returns the offset to poll from
+func (b *TelegramBot) skipPendingUpdates(ctx context.Context) (int64, error) {
+	updates, err := b.getUpdates(ctx, -1, 0)
+	if err != nil {
+		return 0, err
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	return updates[len(updates)-1].UpdateID + 1, nil
+}
+
+// handleUpdate runs the command in an update and replies with its result if the chat is allowed and within its rate limit
+func (b *TelegramBot) handleUpdate(ctx context.Context, update telegramUpdate) {
+	if update.Message == nil || !strings.HasPrefix(update.Message.Text, "/") {
+		return
+	}
+
+	chatID := strconv.FormatInt(update.Message.Chat.ID, 10)
+	username := ""
+	if update.Message.From != nil {
+		username = update.Message.From.Username
+	}
+
+	if !slices.Contains(b.allowedChatIDs, chatID) {
+		b.logger.Warn("ignoring telegram command from chat not in allowed_chat_ids", "chat_id", chatID, "username", username)
+		return
+	}
+
+	if !b.limiter.allow(chatID, time.Now()) {
+		b.logger.Warn("ignoring telegram command - rate limit exceeded", "chat_id", chatID, "username", username)
+		return
+	}
+
+	name, args := parseTelegramCommand(update.Message.Text)
+	b.logger.Info("telegram command received", "command", name, "args", args, "chat_id", chatID, "username", username)
+
+	reply := b.run(name, args)
+	if err := b.sendMessage(ctx, chatID, reply); err != nil {
+		b.logger.Error("failed to reply to telegram command", "command", name, "error", err)
+	}
+}
+
+// run runs the named command, returning its reply
+func (b *TelegramBot) run(name string, args []string) string {
+	command, ok := b.commands[name]
+	if !ok {
+		names := make([]string, 0, len(b.commands))
+		for commandName := range b.commands {
+			names = append(names, "/"+commandName)
+		}
+		slices.Sort(names)
+		return fmt.Sprintf("Unknown command /%s - available commands: %s", name, strings.Join(names, ", "))
+	}
+	return command(args)
+}
+
+// parseTelegramCommand splits a message into its command name and args, dropping any @botname suffix
+func parseTelegramCommand(text string) (name string, args []string) {
+	words := strings.Fields(text)
+	name = strings.TrimPrefix(words[0], "/")
+	name, _, _ = strings.Cut(name, "@")
+	return strings.ToLower(name), words[1:]
+}
+
+// getUpdates long polls for updates from offset
+func (b *TelegramBot) getUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]telegramUpdate, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	query.Set("timeout", strconv.Itoa(int(timeout.Seconds())))
+	query.Set("allowed_updates", `["message"]`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.methodURL("getUpdates")+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telegram request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telegram updates: %w", redactURLError(err))
+	}
+	defer resp.Body.Close()
+
+	var updates telegramUpdatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&updates); err != nil {
+		return nil, fmt.Errorf("failed to decode telegram updates (status %d): %w", resp.StatusCode, err)
+	}
+	if !updates.OK {
+		return nil, fmt.Errorf("telegram API returned status %d: %s", resp.StatusCode, updates.Description)
+	}
+
+	return updates.Result, nil
+}
+
+// sendMessage sends a plain text message to a chat
+func (b *TelegramBot) sendMessage(ctx context.Context, chatID string, text string) error {
+	jsonData, err := json.Marshal(telegramPayload{ChatID: chatID, Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal telegram payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.methodURL("sendMessage"), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(b.httpClient, req, "telegram API")
+	if err != nil {
+		return fmt.Errorf("failed to send telegram message: %w", redactURLError(err))
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// methodURL returns the bot API URL for a method
+func (b *TelegramBot) methodURL(method string) string {
+	return fmt.Sprintf("%s/bot%s/%s", b.apiBase, b.botToken, method)
+}
+
+// redactURLError drops the request URL from an HTTP client error as it carries the bot token
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// chatRateLimiter limits how many commands are answered per chat within a sliding window
+type chatRateLimiter struct {
+	limit  int
+	window time.Duration
+	mu     sync.Mutex
+	seen   map[string][]time.Time
+}
+
+// newChatRateLimiter creates a rate limiter allowing limit commands per chat per window
+func newChatRateLimiter(limit int, window time.Duration) *chatRateLimiter {
+	return &chatRateLimiter{
+		limit:  limit,
+		window: window,
+		seen:   make(map[string][]time.Time),
+	}
+}
+
+// allow records a command from chatID at now, returning false if the chat is over its limit
+func (l *chatRateLimiter) allow(chatID string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recent := slices.DeleteFunc(l.seen[chatID], func(at time.Time) bool {
+		return now.Sub(at) >= l.window
+	})
+
+	if len(recent) >= l.limit {
+		l.seen[chatID] = recent
+		return false
+	}
+
+	l.seen[chatID] = append(recent, now)
+	return true
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTelegramBot creates a bot against a fake bot API recording sent messages
+func newTestTelegramBot(t *testing.T, rateLimit int) (*TelegramBot, func() []telegramPayload) {
+	t.Helper()
+
+	var mu sync.Mutex
+	sent := []telegramPayload{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasPrefix(r.URL.Path, "/bot123:abc/"))
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			var payload telegramPayload
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			mu.Lock()
+			sent = append(sent, payload)
+			mu.Unlock()
+			io.WriteString(w, `{"ok":true}`)
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			io.WriteString(w, `{"ok":true,"result":[{"update_id":41,"message":{"chat":{"id":100},"text":"/status"}}]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	bot := NewTelegramBot(TelegramBotOptions{
+		BotToken:           "123:abc",
+		AllowedChatIDs:     []string{"100"},
+		RateLimitPerMinute: rateLimit,
+		Commands: map[string]TelegramCommandFunc{
+			"status": func(args []string) string { return "all good" },
+			"echo":   func(args []string) string { return strings.Join(args, " ") },
+		},
+		Logger: log.New(io.Discard),
+	})
+	bot.apiBase = server.URL
+
+	return bot, func() []telegramPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]telegramPayload{}, sent...)
+	}
+}
+
+func commandUpdate(chatID int64, text string) telegramUpdate {
+	return telegramUpdate{Message: &telegramMessage{Chat: telegramChat{ID: chatID}, Text: text}}
+}
+
+func TestTelegramBot_HandleUpdate(t *testing.T) {
+	bot, sent := newTestTelegramBot(t, 10)
+	ctx := context.Background()
+
+	bot.handleUpdate(ctx, commandUpdate(100, "/status"))
+	bot.handleUpdate(ctx, commandUpdate(100, "/echo@ha_bot hello there"))
+	bot.handleUpdate(ctx, commandUpdate(100, "/reboot"))
+
+	// chats not allowed and plain messages are ignored
+	bot.handleUpdate(ctx, commandUpdate(200, "/status"))
+	bot.handleUpdate(ctx, commandUpdate(100, "status please"))
+
+	messages := sent()
+	require.Len(t, messages, 3)
+	assert.Equal(t, telegramPayload{ChatID: "100", Text: "all good"}, messages[0])
+	assert.Equal(t, "hello there", messages[1].Text)
+	assert.Equal(t, "Unknown command /reboot - available commands: /echo, /status", messages[2].Text)
+}
+
+func TestTelegramBot_RateLimit(t *testing.T) {
+	bot, sent := newTestTelegramBot(t, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		bot.handleUpdate(ctx, commandUpdate(100, "/status"))
+	}
+	assert.Len(t, sent(), 2)
+}
+
+func TestTelegramBot_SkipPendingUpdates(t *testing.T) {
+	bot, sent := newTestTelegramBot(t, 10)
+
+	offset, err := bot.skipPendingUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Empty(t, sent())
+}
+
+func TestChatRateLimiter(t *testing.T) {
+	limiter := newChatRateLimiter(2, time.Minute)
+	now := time.Now()
+
+	assert.True(t, limiter.allow("100", now))
+	assert.True(t, limiter.allow("100", now.Add(time.Second)))
+	assert.False(t, limiter.allow("100", now.Add(2*time.Second)))
+
+	// limits are per chat
+	assert.True(t, limiter.allow("200", now.Add(2*time.Second)))
+
+	// allowed again once the oldest command leaves the window
+	assert.True(t, limiter.allow("100", now.Add(time.Minute)))
+	assert.False(t, limiter.allow("100", now.Add(time.Minute+500*time.Millisecond)))
+}
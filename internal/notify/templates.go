@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+)
+
+// defaultTemplateKey is the notifications.templates key applied to event types without their own template
+const defaultTemplateKey = "default"
+
+// eventTitles are the default notification titles by event type
+var eventTitles = map[EventType]string{
+	EventStartup:                   "Validator HA Started",
+	EventShutdown:                  "Validator HA Stopped",
+	EventBecomingActive:            "FAILOVER: Becoming Active",
+	EventBecameActive:              "Became Active",
+	EventBecomingPassive:           "Becoming Passive",
+	EventBecamePassive:             "Became Passive",
+	EventHealthUnhealthy:           "Health Alert: Unhealthy",
+	EventHealthRecovered:           "Health Recovered",
+	EventDelinquent:                "CRITICAL: Validator Delinquent",
+	EventGossipLost:                "Lost from Gossip",
+	EventGossipRecovered:           "Gossip Recovered",
+	EventPeerDiscovered:            "Peer Discovered",
+	EventPeerLost:                  "Peer Lost",
+	EventDigest:                    "Notification Digest",
+	EventRPCDemoted:                "RPC Endpoint Demoted",
+	EventSnapshotRecoveryStarted:   "Snapshot Recovery Started",
+	EventSnapshotRecoveryCompleted: "Snapshot Recovery Completed",
+	EventSnapshotRecoveryFailed:    "Snapshot Recovery Failed",
+	EventQuietPeriodEnded:          "Quiet Period Ended",
+	EventVoteAccountChanged:        "CRITICAL: Vote Account Changed",
+	EventActiveIdentityOnPassive:   "CRITICAL: Active Identity on Passive Node",
+	EventCircuitBreakerTripped:     "CRITICAL: Failover Circuit Breaker Tripped",
+	EventCircuitBreakerReset:       "Failover Circuit Breaker Reset",
+	EventHeartbeat:                 "Heartbeat",
+	EventAcknowledged:              "Acknowledged",
+	EventManualRoleChange:          "Manual Role Change",
+	EventScheduledFailover:         "Scheduled Failover",
+	EventVoteCreditsLagging:        "Vote Credits Lagging",
+	EventSkipRateHigh:              "Skip Rate High",
+	EventFailback:                  "Failback",
+	EventFlappingDetected:          "Flapping Detected",
+}
+
+// eventTitle returns the title an event is sent with - its rendered template title if any, else the default for its type
+func eventTitle(event Event) string {
+	if event.Title != "" {
+		return event.Title
+	}
+	if title, ok := eventTitles[event.Type]; ok {
+		return title
+	}
+	return string(event.Type)
+}
+
+// messageTemplate is a parsed notifications.templates entry
+type messageTemplate struct {
+	title       *template.Template
+	description *template.Template
+}
+
+// messageTemplates renders user supplied titles and descriptions for events
+type messageTemplates struct {
+	byKey map[string]messageTemplate
+}
+
+// newMessageTemplates parses the configured templates by event type
+func newMessageTemplates(cfg map[string]config.NotificationTemplate) (*messageTemplates, error) {
+	t := &messageTemplates{byKey: make(map[string]messageTemplate, len(cfg))}
+
+	for key, templateCfg := range cfg {
+		var parsed messageTemplate
+		var err error
+
+		if templateCfg.Title != "" {
+			if parsed.title, err = template.New(key + ".title").Parse(templateCfg.Title); err != nil {
+				return nil, fmt.Errorf("failed to parse notifications.templates.%s.title: %w", key, err)
+			}
+		}
+
+		if templateCfg.Description != "" {
+			if parsed.description, err = template.New(key + ".description").Parse(templateCfg.Description); err != nil {
+				return nil, fmt.Errorf("failed to parse notifications.templates.%s.description: %w", key, err)
+			}
+		}
+
+		t.byKey[key] = parsed
+	}
+
+	return t, nil
+}
+
+// render sets the event's title and message from its event type's template, falling back to the default template.
+// Templates have access to all event fields, e.g. {{ .ValidatorName }} and {{ .Details.peer_name }}.
+// A template that fails to execute leaves the event unchanged.
+func (t *messageTemplates) render(event Event) (Event, error) {
+	tmpl, ok := t.byKey[string(event.Type)]
+	if !ok {
+		if tmpl, ok = t.byKey[defaultTemplateKey]; !ok {
+			return event, nil
+		}
+	}
+
+	rendered := event
+
+	if tmpl.title != nil {
+		title, err := executeTemplate(tmpl.title, event)
+		if err != nil {
+			return event, err
+		}
+		rendered.Title = title
+	}
+
+	if tmpl.description != nil {
+		description, err := executeTemplate(tmpl.description, event)
+		if err != nil {
+			return event, err
+		}
+		rendered.Message = description
+	}
+
+	return rendered, nil
+}
+
+// executeTemplate executes a template with the event as data
+func executeTemplate(tmpl *template.Template, event Event) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("failed to execute notification template %s: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageTemplates_Render(t *testing.T) {
+	templates, err := newMessageTemplates(map[string]config.NotificationTemplate{
+		"peer_lost": {
+			Title:       "{{ .ValidatorName }} lost {{ .Details.peer_name }}",
+			Description: "[{{ .Severity }}] {{ .Cluster }}: {{ .Message }}",
+		},
+		"default": {
+			Title: "{{ .Type }} on {{ .ValidatorName }}",
+		},
+	})
+	require.NoError(t, err)
+
+	event := Event{
+		Type:          EventPeerLost,
+		Severity:      SeverityError,
+		ValidatorName: "primary",
+		Cluster:       "mainnet-beta",
+		Message:       "peer gone",
+		Details:       map[string]string{"peer_name": "backup"},
+	}
+
+	rendered, err := templates.render(event)
+	require.NoError(t, err)
+	assert.Equal(t, "primary lost backup", rendered.Title)
+	assert.Equal(t, "[error] mainnet-beta: peer gone", rendered.Message)
+	assert.Equal(t, "primary lost backup", eventTitle(rendered))
+
+	// event types without their own template use the default template, keeping their description
+	rendered, err = templates.render(Event{Type: EventStartup, ValidatorName: "primary"})
+	require.NoError(t, err)
+	assert.Equal(t, "startup on primary", rendered.Title)
+	assert.Empty(t, rendered.Message)
+}
+
+func TestMessageTemplates_ParseError(t *testing.T) {
+	_, err := newMessageTemplates(map[string]config.NotificationTemplate{
+		"startup": {Title: "{{ .ValidatorName"},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.templates.startup.title")
+}
+
+func TestEventTitle_Defaults(t *testing.T) {
+	assert.Equal(t, "FAILOVER: Becoming Active", eventTitle(Event{Type: EventBecomingActive}))
+	assert.Equal(t, "custom_event", eventTitle(Event{Type: EventType("custom_event")}))
+}
@@ -0,0 +1,340 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// ticketRecoveries maps recovery events to the event whose ticket they resolve
+var ticketRecoveries = map[EventType]EventType{
+	EventHealthRecovered:           EventHealthUnhealthy,
+	EventGossipRecovered:           EventGossipLost,
+	EventPeerDiscovered:            EventPeerLost,
+	EventSnapshotRecoveryCompleted: EventSnapshotRecoveryFailed,
+	EventCircuitBreakerReset:       EventCircuitBreakerTripped,
+}
+
+// ticketProvider opens, updates and resolves tickets in a ticketing system
+type ticketProvider interface {
+	// open opens a ticket, returning its ID
+	open(ctx context.Context, summary string, description string, fields map[string]any) (string, error)
+	// comment adds a comment to a ticket
+	comment(ctx context.Context, id string, text string) error
+	// resolve resolves a ticket with a closing comment
+	resolve(ctx context.Context, id string, text string) error
+}
+
+// TicketsOptions contains options for creating a tickets notifier
+type TicketsOptions struct {
+	// Provider is jira or servicenow
+	Provider string
+	URL      string
+	Username string
+	Token    string
+	// Project and IssueType are the Jira project key and issue type tickets are opened as
+	Project   string
+	IssueType string
+	// Queue and Table are the ServiceNow assignment group and table tickets are opened in
+	Queue string
+	Table string
+	// Events open tickets - critical severity events when empty
+	Events []EventType
+	// Fields are extra fields set on opened tickets - Go templates rendered with the event
+	Fields map[string]string
+	// ResolveTransition is the Jira transition name or ServiceNow state applied on recovery
+	ResolveTransition string
+	// ResolveFields are extra ServiceNow fields set when resolving
+	ResolveFields map[string]string
+	Logger        *log.Logger
+	Transport     http.RoundTripper
+}
+
+// TicketsNotifier opens a ticket for critical events, commenting on it while it is open and resolving it when the
+// recovery event arrives. Open tickets are tracked in memory and kept across reloads, so only a ticket open when the
+// manager restarts is left for an operator to resolve.
+type TicketsNotifier struct {
+	provider ticketProvider
+	// instance is the provider and URL tickets are opened in
+	instance string
+	events   []EventType
+	fields   map[string]*template.Template
+	logger   *log.Logger
+	enabled  bool
+	// openTickets are the IDs of open tickets by ticketKey
+	openTickets   map[string]string
+	openTicketsMu sync.Mutex
+}
+
+// NewTicketsNotifier creates a new tickets notifier
+func NewTicketsNotifier(opts TicketsOptions) *TicketsNotifier {
+	client := &ticketClient{
+		baseURL:    strings.TrimSuffix(opts.URL, "/"),
+		username:   opts.Username,
+		token:      opts.Token,
+		target:     opts.Provider + " API",
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: opts.Transport},
+	}
+
+	var provider ticketProvider
+	switch opts.Provider {
+	case "jira":
+		provider = &jiraProvider{client: client, project: opts.Project, issueType: opts.IssueType, resolveTransition: opts.ResolveTransition}
+	case "servicenow":
+		provider = &serviceNowProvider{client: client, queue: opts.Queue, table: opts.Table, resolveState: opts.ResolveTransition, resolveFields: opts.ResolveFields}
+	}
+
+	fields := make(map[string]*template.Template, len(opts.Fields))
+	for name, value := range opts.Fields {
+		tmpl, err := template.New("notifications.tickets.fields." + name).Parse(value)
+		if err != nil {
+			opts.Logger.Error("failed to parse ticket field template - field not set", "field", name, "error", err)
+			continue
+		}
+		fields[name] = tmpl
+	}
+
+	return &TicketsNotifier{
+		provider:    provider,
+		instance:    opts.Provider + " " + strings.TrimSuffix(opts.URL, "/"),
+		events:      opts.Events,
+		fields:      fields,
+		logger:      opts.Logger,
+		enabled:     provider != nil && opts.URL != "" && opts.Token != "",
+		openTickets: make(map[string]string),
+	}
+}
+
+// Name returns the notifier name
+func (t *TicketsNotifier) Name() string {
+	return "tickets"
+}
+
+// IsEnabled returns whether the notifier is enabled
+func (t *TicketsNotifier) IsEnabled() bool {
+	return t.enabled
+}
+
+// Resolves returns true if events of eventType resolve an open ticket
+func (t *TicketsNotifier) Resolves(eventType EventType) bool {
+	_, ok := ticketRecoveries[eventType]
+	return ok
+}
+
+// carryState takes over the tickets previous has open, unless they are in another ticketing system
+func (t *TicketsNotifier) carryState(previous Notifier) {
+	old, ok := previous.(*TicketsNotifier)
+	if !ok || old.instance != t.instance {
+		return
+	}
+
+	old.openTicketsMu.Lock()
+	defer old.openTicketsMu.Unlock()
+	t.openTicketsMu.Lock()
+	defer t.openTicketsMu.Unlock()
+	maps.Copy(t.openTickets, old.openTickets)
+}
+
+// Send opens or comments on the ticket for a ticketed event, or resolves the open ticket for a recovery event
+func (t *TicketsNotifier) Send(ctx context.Context, event Event) error {
+	if !t.enabled {
+		return nil
+	}
+
+	if problem, ok := ticketRecoveries[event.Type]; ok {
+		return t.resolve(ctx, ticketKey(event, problem), event)
+	}
+
+	if !t.opens(event) {
+		return nil
+	}
+
+	key := ticketKey(event, event.Type)
+	if id, ok := t.openTicket(key); ok {
+		if err := t.provider.comment(ctx, id, ticketText(event)); err != nil {
+			return fmt.Errorf("failed to comment on ticket %s: %w", id, err)
+		}
+		return nil
+	}
+
+	id, err := t.provider.open(ctx, ticketSummary(event), ticketText(event), t.renderFields(event))
+	if err != nil {
+		return fmt.Errorf("failed to open ticket: %w", err)
+	}
+	t.logger.Info("ticket opened", "ticket", id, "event", event.Type)
+
+	t.openTicketsMu.Lock()
+	t.openTickets[key] = id
+	t.openTicketsMu.Unlock()
+	return nil
+}
+
+// resolve resolves the open ticket for key, if any
+func (t *TicketsNotifier) resolve(ctx context.Context, key string, event Event) error {
+	id, ok := t.openTicket(key)
+	if !ok {
+		return nil
+	}
+
+	if err := t.provider.resolve(ctx, id, ticketText(event)); err != nil {
+		return fmt.Errorf("failed to resolve ticket %s: %w", id, err)
+	}
+	t.logger.Info("ticket resolved", "ticket", id, "event", event.Type)
+
+	t.openTicketsMu.Lock()
+	delete(t.openTickets, key)
+	t.openTicketsMu.Unlock()
+	return nil
+}
+
+// opens returns whether an event opens a ticket
+func (t *TicketsNotifier) opens(event Event) bool {
+	if len(t.events) == 0 {
+		return event.Severity == SeverityCritical
+	}
+	return slices.Contains(t.events, event.Type)
+}
+
+// openTicket returns the ID of the open ticket for key
+func (t *TicketsNotifier) openTicket(key string) (string, bool) {
+	t.openTicketsMu.Lock()
+	defer t.openTicketsMu.Unlock()
+	id, ok := t.openTickets[key]
+	return id, ok
+}
+
+// renderFields renders the configured fields for an event - values rendering to a JSON object or array, such as
+// {"name": "High"} for a Jira priority, are sent as JSON
+func (t *TicketsNotifier) renderFields(event Event) map[string]any {
+	fields := make(map[string]any, len(t.fields))
+	for name, tmpl := range t.fields {
+		value, err := executeTemplate(tmpl, event)
+		if err != nil {
+			t.logger.Error("failed to render ticket field - field not set", "field", name, "error", err)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(value)
+		if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+			fields[name] = json.RawMessage(trimmed)
+			continue
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// ticketKey identifies the ticket for an event of the given type - per peer for peer events
+func ticketKey(event Event, eventType EventType) string {
+	key := fmt.Sprintf("%s-%s", event.ValidatorName, eventType)
+	if peerName := event.Details["peer_name"]; peerName != "" {
+		key += "-" + peerName
+	}
+	return key
+}
+
+// ticketSummary returns the summary a ticket is opened with
+func ticketSummary(event Event) string {
+	return fmt.Sprintf("%s - %s", eventTitle(event), event.ValidatorName)
+}
+
+// ticketText returns the plain text description or comment for an event
+func ticketText(event Event) string {
+	var text strings.Builder
+
+	text.WriteString(eventTitle(event))
+	if event.Message != "" {
+		text.WriteString("\n\n" + event.Message)
+	}
+	text.WriteString("\n")
+
+	lines := [][2]string{
+		{"Validator", event.ValidatorName},
+		{"Cluster", event.Cluster},
+		{"IP", event.PublicIP},
+		{"Active Pubkey", event.ActivePubkey},
+		{"Passive Pubkey", event.PassivePubkey},
+		{"Severity", string(event.Severity)},
+		{"Time", event.Timestamp.UTC().Format(time.RFC3339)},
+	}
+
+	detailKeys := make([]string, 0, len(event.Details))
+	for key := range event.Details {
+		detailKeys = append(detailKeys, key)
+	}
+	sort.Strings(detailKeys)
+	for _, key := range detailKeys {
+		lines = append(lines, [2]string{key, event.Details[key]})
+	}
+
+	for _, line := range lines {
+		if line[1] != "" {
+			fmt.Fprintf(&text, "\n%s: %s", line[0], line[1])
+		}
+	}
+
+	return text.String()
+}
+
+// ticketClient sends authenticated JSON requests to a ticketing system API
+type ticketClient struct {
+	baseURL    string
+	username   string
+	token      string
+	target     string
+	httpClient *http.Client
+}
+
+// do sends a JSON request to path, decoding the response into out when set
+func (c *ticketClient) do(ctx context.Context, method string, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s request: %w", c.target, err)
+		}
+		reader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", c.target, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// a token without a username is a bearer personal access token
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := doRequest(c.httpClient, req, c.target)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", c.target, err)
+		}
+	}
+
+	return nil
+}
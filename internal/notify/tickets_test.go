@@ -0,0 +1,223 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ticketRequest is a request received by a test ticketing server
+type ticketRequest struct {
+	method string
+	path   string
+	body   map[string]any
+}
+
+// newTestTicketServer starts a server answering requests with the response for their method and path
+func newTestTicketServer(t *testing.T, responses map[string]string) (*httptest.Server, func() []ticketRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	requests := []ticketRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]any{}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			require.NoError(t, json.Unmarshal(data, &body))
+		}
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "svc-ha", username)
+		assert.Equal(t, "secret", password)
+
+		mu.Lock()
+		requests = append(requests, ticketRequest{method: r.Method, path: r.URL.Path, body: body})
+		mu.Unlock()
+
+		response, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			response = "{}"
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []ticketRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]ticketRequest{}, requests...)
+	}
+}
+
+func TestTicketsNotifier_Jira(t *testing.T) {
+	server, requests := newTestTicketServer(t, map[string]string{
+		"POST /rest/api/2/issue":                  `{"key":"OPS-7"}`,
+		"GET /rest/api/2/issue/OPS-7/transitions": `{"transitions":[{"id":"11","name":"In Progress"},{"id":"31","name":"Done"}]}`,
+	})
+
+	notifier := NewTicketsNotifier(TicketsOptions{
+		Provider:          "jira",
+		URL:               server.URL + "/",
+		Username:          "svc-ha",
+		Token:             "secret",
+		Project:           "OPS",
+		IssueType:         "Task",
+		Events:            []EventType{EventHealthUnhealthy},
+		Fields:            map[string]string{"labels": `["{{ .ValidatorName }}"]`, "environment": "{{ .Cluster }}"},
+		ResolveTransition: "done",
+		Logger:            log.New(io.Discard),
+	})
+
+	event := Event{Type: EventHealthUnhealthy, Severity: SeverityError, ValidatorName: "validator-1", Cluster: "mainnet-beta", Timestamp: time.Now()}
+	require.NoError(t, notifier.Send(context.Background(), event))
+	// a repeat while the ticket is open is commented on
+	require.NoError(t, notifier.Send(context.Background(), event))
+	// events not configured are ignored
+	require.NoError(t, notifier.Send(context.Background(), Event{Type: EventDelinquent, Severity: SeverityCritical, ValidatorName: "validator-1"}))
+	require.NoError(t, notifier.Send(context.Background(), Event{Type: EventHealthRecovered, Severity: SeverityInfo, ValidatorName: "validator-1"}))
+	// a recovery without an open ticket is ignored
+	require.NoError(t, notifier.Send(context.Background(), Event{Type: EventHealthRecovered, Severity: SeverityInfo, ValidatorName: "validator-1"}))
+
+	received := requests()
+	require.Len(t, received, 5)
+
+	assert.Equal(t, "/rest/api/2/issue", received[0].path)
+	fields := received[0].body["fields"].(map[string]any)
+	assert.Equal(t, map[string]any{"key": "OPS"}, fields["project"])
+	assert.Equal(t, map[string]any{"name": "Task"}, fields["issuetype"])
+	assert.Equal(t, "Health Alert: Unhealthy - validator-1", fields["summary"])
+	assert.Contains(t, fields["description"], "Validator: validator-1")
+	assert.Equal(t, []any{"validator-1"}, fields["labels"])
+	assert.Equal(t, "mainnet-beta", fields["environment"])
+
+	assert.Equal(t, "/rest/api/2/issue/OPS-7/comment", received[1].path)
+	assert.Equal(t, "/rest/api/2/issue/OPS-7/comment", received[2].path)
+	assert.Contains(t, received[2].body["body"], "Health Recovered")
+	assert.Equal(t, http.MethodGet, received[3].method)
+	assert.Equal(t, "/rest/api/2/issue/OPS-7/transitions", received[4].path)
+	assert.Equal(t, map[string]any{"id": "31"}, received[4].body["transition"])
+}
+
+func TestTicketsNotifier_ServiceNow(t *testing.T) {
+	server, requests := newTestTicketServer(t, map[string]string{
+		"POST /api/now/table/incident": `{"result":{"sys_id":"abc123","number":"INC0010001"}}`,
+	})
+
+	notifier := NewTicketsNotifier(TicketsOptions{
+		Provider:          "servicenow",
+		URL:               server.URL,
+		Username:          "svc-ha",
+		Token:             "secret",
+		Queue:             "Validator Ops",
+		Table:             "incident",
+		ResolveTransition: "6",
+		ResolveFields:     map[string]string{"close_code": "Resolved by caller"},
+		Logger:            log.New(io.Discard),
+	})
+
+	peerLost := Event{Type: EventPeerLost, Severity: SeverityCritical, ValidatorName: "validator-1", Details: map[string]string{"peer_name": "backup-1"}}
+	require.NoError(t, notifier.Send(context.Background(), peerLost))
+	// a different peer's recovery does not resolve the ticket
+	require.NoError(t, notifier.Send(context.Background(), Event{Type: EventPeerDiscovered, Severity: SeverityInfo, ValidatorName: "validator-1", Details: map[string]string{"peer_name": "backup-2"}}))
+	require.NoError(t, notifier.Send(context.Background(), Event{Type: EventPeerDiscovered, Severity: SeverityInfo, ValidatorName: "validator-1", Details: map[string]string{"peer_name": "backup-1"}}))
+
+	received := requests()
+	require.Len(t, received, 2)
+
+	assert.Equal(t, "/api/now/table/incident", received[0].path)
+	assert.Equal(t, "Validator Ops", received[0].body["assignment_group"])
+	assert.Equal(t, "Peer Lost - validator-1", received[0].body["short_description"])
+
+	assert.Equal(t, http.MethodPatch, received[1].method)
+	assert.Equal(t, "/api/now/table/incident/abc123", received[1].path)
+	assert.Equal(t, "6", received[1].body["state"])
+	assert.Equal(t, "Resolved by caller", received[1].body["close_code"])
+	assert.Contains(t, received[1].body["close_notes"], "peer_name: backup-1")
+}
+
+func TestManager_Digest_DeliversTicketRecoveries(t *testing.T) {
+	server, requests := newTestTicketServer(t, map[string]string{
+		"POST /rest/api/2/issue": `{"key":"OPS-7"}`,
+	})
+	manager := NewManager(ManagerOptions{
+		Config: &config.NotificationConfig{
+			Enabled: true,
+			Digest:  config.NotificationDigest{Enabled: true, IntervalDuration: time.Hour},
+			Tickets: config.TicketsConfig{
+				Enabled:   true,
+				Provider:  "jira",
+				URL:       server.URL,
+				Username:  "svc-ha",
+				Token:     "secret",
+				Project:   "OPS",
+				IssueType: "Task",
+				Events:    []string{string(EventHealthUnhealthy)},
+			},
+			Events: config.NotificationEvents{HealthUnhealthy: true, HealthRecovered: true, PeerLost: true},
+		},
+		ValidatorName: "validator-1",
+	})
+	defer manager.Close()
+
+	manager.Notify(Event{Type: EventHealthUnhealthy, Severity: SeverityCritical, ValidatorName: "validator-1", Message: "unhealthy"})
+	// info events are batched for the digest, unless they resolve a ticket
+	manager.Notify(Event{Type: EventPeerLost, Severity: SeverityInfo, Message: "peer lost"})
+	manager.Notify(Event{Type: EventHealthRecovered, Severity: SeverityInfo, ValidatorName: "validator-1", Message: "recovered"})
+
+	received := requests()
+	require.Len(t, received, 3)
+	assert.Equal(t, "/rest/api/2/issue", received[0].path)
+	assert.Contains(t, received[1].body["body"], "Health Recovered")
+	assert.Equal(t, "/rest/api/2/issue/OPS-7/transitions", received[2].path)
+}
+
+func TestManager_Reload_KeepsOpenTickets(t *testing.T) {
+	server, requests := newTestTicketServer(t, map[string]string{
+		"POST /rest/api/2/issue": `{"key":"OPS-7"}`,
+	})
+	cfg := &config.NotificationConfig{
+		Enabled: true,
+		Tickets: config.TicketsConfig{
+			Enabled:   true,
+			Provider:  "jira",
+			URL:       server.URL,
+			Username:  "svc-ha",
+			Token:     "secret",
+			Project:   "OPS",
+			IssueType: "Task",
+			Events:    []string{string(EventHealthUnhealthy)},
+		},
+		Events: config.NotificationEvents{HealthUnhealthy: true, HealthRecovered: true},
+	}
+	manager := NewManager(ManagerOptions{Config: cfg, ValidatorName: "validator-1"})
+	defer manager.Close()
+
+	manager.Notify(Event{Type: EventHealthUnhealthy, Severity: SeverityCritical, ValidatorName: "validator-1", Message: "unhealthy"})
+	require.NoError(t, manager.Reload(cfg))
+	// the ticket opened before the reload is still resolved
+	manager.Notify(Event{Type: EventHealthRecovered, Severity: SeverityInfo, ValidatorName: "validator-1", Message: "recovered"})
+
+	received := requests()
+	require.Len(t, received, 3)
+	assert.Equal(t, "/rest/api/2/issue/OPS-7/comment", received[1].path)
+	assert.Equal(t, "/rest/api/2/issue/OPS-7/transitions", received[2].path)
+
+	// tickets in another ticketing system are not carried over
+	manager.Notify(Event{Type: EventHealthUnhealthy, Severity: SeverityCritical, ValidatorName: "validator-1", Message: "unhealthy"})
+	key := ticketKey(Event{Type: EventHealthUnhealthy, ValidatorName: "validator-1"}, EventHealthUnhealthy)
+	_, ok := manager.targets(nil)[0].(*TicketsNotifier).openTicket(key)
+	require.True(t, ok)
+	other := *cfg
+	other.Tickets.URL = server.URL + "/other"
+	require.NoError(t, manager.Reload(&other))
+	_, ok = manager.targets(nil)[0].(*TicketsNotifier).openTicket(key)
+	assert.False(t, ok)
+}
@@ -0,0 +1,63 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+)
+
+const commandLabelName = "command"
+
+// commandCollector exports the run statistics internal/command records for each command when gathered
+type commandCollector struct {
+	metrics  *Metrics
+	runStats func() []command.Stats
+	duration *prometheus.Desc
+	failures *prometheus.Desc
+}
+
+// newCommandCollector creates a collector for the command run statistics
+func newCommandCollector(m *Metrics) *commandCollector {
+	labelNames := append([]string{commandLabelName}, m.commonLabelNames...)
+	return &commandCollector{
+		metrics:  m,
+		runStats: command.RunStats,
+		duration: prometheus.NewDesc(
+			m.metricName("command_duration_seconds"),
+			"Duration of role, hook and snapshot recovery command runs in seconds since startup",
+			labelNames, nil,
+		),
+		failures: prometheus.NewDesc(
+			m.metricName("command_failures_total"),
+			"Number of role, hook and snapshot recovery command runs that failed since startup",
+			labelNames, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *commandCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.duration
+	ch <- c.failures
+}
+
+// Collect implements prometheus.Collector
+func (c *commandCollector) Collect(ch chan<- prometheus.Metric) {
+	state := c.metrics.cache.GetState()
+	commonLabels := c.metrics.getCommonLabels(&state)
+
+	for _, stats := range c.runStats() {
+		labelValues := []string{stats.Name}
+		for _, labelName := range c.metrics.commonLabelNames {
+			labelValues = append(labelValues, commonLabels[labelName])
+		}
+
+		buckets := make(map[float64]uint64, len(command.DurationBuckets))
+		for i, bucket := range command.DurationBuckets {
+			buckets[bucket] = stats.DurationBucketCounts[i]
+		}
+
+		ch <- prometheus.MustNewConstHistogram(c.duration, stats.Runs, stats.DurationSeconds, buckets, labelValues...)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue, float64(stats.Failures), labelValues...)
+	}
+}
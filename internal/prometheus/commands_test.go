@@ -0,0 +1,54 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/command"
+)
+
+func TestCommandCollector(t *testing.T) {
+	metrics := New(Options{
+		Config: createTestConfig(),
+		Logger: createTestLogger(),
+		Cache:  createTestCache(),
+	})
+
+	collector := newCommandCollector(metrics)
+	collector.runStats = func() []command.Stats {
+		bucketCounts := make([]uint64, len(command.DurationBuckets))
+		for i := 3; i < len(bucketCounts); i++ {
+			bucketCounts[i] = 2
+		}
+		return []command.Stats{
+			{Name: "pre-hook notify", Runs: 2, Failures: 1, DurationSeconds: 3.5, DurationBucketCounts: bucketCounts},
+		}
+	}
+
+	registry := metrics.GetRegistry()
+	registry.Unregister(newCommandCollector(metrics))
+	require.NoError(t, registry.Register(collector))
+
+	metricFamilies, err := registry.Gather()
+	require.NoError(t, err)
+
+	found := 0
+	for _, metricFamily := range metricFamilies {
+		switch *metricFamily.Name {
+		case "solana_validator_ha_command_duration_seconds":
+			require.Len(t, metricFamily.Metric, 1)
+			histogram := metricFamily.Metric[0].Histogram
+			assert.Equal(t, uint64(2), *histogram.SampleCount)
+			assert.Equal(t, 3.5, *histogram.SampleSum)
+			assert.Contains(t, metricFamily.Metric[0].String(), "pre-hook notify")
+			found++
+		case "solana_validator_ha_command_failures_total":
+			require.Len(t, metricFamily.Metric, 1)
+			assert.Equal(t, float64(1), *metricFamily.Metric[0].Counter.Value)
+			found++
+		}
+	}
+	assert.Equal(t, 2, found)
+}
@@ -1,8 +1,9 @@
 package prometheus
 
 import (
-	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/charmbracelet/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,17 +11,21 @@ import (
 
 	"github.com/sol-strategies/solana-validator-ha/internal/cache"
 	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
 )
 
 const (
-	metricsNamespacePrefix   = "solana_validator_ha_"
 	validatorNameLabelName   = "validator_name"
 	publicIPLabelName        = "public_ip"
 	validatorRoleLabelName   = "validator_role"
 	validatorStatusLabelName = "validator_status"
 	failoverStatusLabelName  = "status"
+	failoverIDLabelName      = "failover_id"
 	peerCountLabelName       = "peer_count"
 	selfInGossipLabelName    = "self_in_gossip"
+	rpcEndpointLabelName     = "rpc_endpoint"
+	clientFlavorLabelName    = "client_flavor"
+	clientVersionLabelName   = "client_version"
 )
 
 var (
@@ -43,7 +48,21 @@ type Metrics struct {
 	metadata       *prometheus.GaugeVec
 	peerCount      *prometheus.GaugeVec
 	selfInGossip   *prometheus.GaugeVec
+	draining       *prometheus.GaugeVec
+	voteCredits    *prometheus.GaugeVec
+	skipRate       *prometheus.GaugeVec
 	failoverStatus *prometheus.GaugeVec
+	failoverInfo   *prometheus.GaugeVec
+	clientInfo     *prometheus.GaugeVec
+
+	// RPC endpoint metrics - the totals are counters advanced by how far each endpoint's totals moved since they were
+	// last exported, kept in rpcEndpointExported
+	rpcEndpointRequests       *prometheus.CounterVec
+	rpcEndpointErrors         *prometheus.CounterVec
+	rpcEndpointTimeouts       *prometheus.CounterVec
+	rpcEndpointLatencySeconds *prometheus.GaugeVec
+	rpcEndpointDemoted        *prometheus.GaugeVec
+	rpcEndpointExported       map[string]rpc.EndpointStats
 }
 
 // Options for creating a new Metrics instance
@@ -75,6 +94,15 @@ func New(opts Options) *Metrics {
 	return m
 }
 
+// metricName returns the name of a metric prefixed with prometheus.namespace
+func (m *Metrics) metricName(name string) string {
+	namespace := m.config.Prometheus.Namespace
+	if namespace == "" {
+		namespace = config.DefaultPrometheusNamespace
+	}
+	return namespace + "_" + name
+}
+
 // initMetrics initializes all Prometheus metrics
 func (m *Metrics) initMetrics() {
 	// Metadata metric - always 1 with metadata labels
@@ -85,7 +113,7 @@ func (m *Metrics) initMetrics() {
 	metadataLabelNames = append(metadataLabelNames, m.commonLabelNames...)
 	m.metadata = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: metricsNamespacePrefix + "metadata",
+			Name: m.metricName("metadata"),
 			Help: "Metadata about the validator HA manager, always 1 with metadata labels",
 		},
 		metadataLabelNames,
@@ -94,7 +122,7 @@ func (m *Metrics) initMetrics() {
 	// Peer count metric
 	m.peerCount = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: metricsNamespacePrefix + "peer_count",
+			Name: m.metricName("peer_count"),
 			Help: "Number of peers seen in gossip this node is aware of, excluding self",
 		},
 		m.commonLabelNames,
@@ -103,12 +131,39 @@ func (m *Metrics) initMetrics() {
 	// Self in gossip metric
 	m.selfInGossip = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: metricsNamespacePrefix + "self_in_gossip",
+			Name: m.metricName("self_in_gossip"),
 			Help: "Whether this node sees itself in gossip (1 = yes, 0 = no)",
 		},
 		m.commonLabelNames,
 	)
 
+	// Draining metric
+	m.draining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: m.metricName("draining"),
+			Help: "Whether this node is drained for planned maintenance (1 = yes, 0 = no)",
+		},
+		m.commonLabelNames,
+	)
+
+	// Vote credits metric
+	m.voteCredits = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: m.metricName("vote_credits_ratio"),
+			Help: "The vote account's credits this epoch as a fraction of the cluster average (1 = on par)",
+		},
+		m.commonLabelNames,
+	)
+
+	// Skip rate metric
+	m.skipRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: m.metricName("skip_rate"),
+			Help: "The fraction of the active identity's leader slots skipped this epoch (0 = none, 1 = all)",
+		},
+		m.commonLabelNames,
+	)
+
 	// Failover status metric
 	failoverLabelNames := []string{
 		failoverStatusLabelName,
@@ -116,40 +171,145 @@ func (m *Metrics) initMetrics() {
 	failoverLabelNames = append(failoverLabelNames, m.commonLabelNames...)
 	m.failoverStatus = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: metricsNamespacePrefix + "failover_status",
+			Name: m.metricName("failover_status"),
 			Help: "Current failover status of the node",
 		},
 		failoverLabelNames,
 	)
 
+	// Failover info metric - always 1 with the ID of the current or most recent role transition
+	failoverInfoLabelNames := []string{
+		failoverIDLabelName,
+	}
+	failoverInfoLabelNames = append(failoverInfoLabelNames, m.commonLabelNames...)
+	m.failoverInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: m.metricName("failover_info"),
+			Help: "ID of the current or most recent role transition, always 1 with the failover_id label",
+		},
+		failoverInfoLabelNames,
+	)
+
+	// Client info metric - always 1 with client flavor and version labels
+	clientInfoLabelNames := []string{
+		clientFlavorLabelName,
+		clientVersionLabelName,
+	}
+	clientInfoLabelNames = append(clientInfoLabelNames, m.commonLabelNames...)
+	m.clientInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: m.metricName("client_info"),
+			Help: "Detected validator client flavor and version, always 1 with client labels",
+		},
+		clientInfoLabelNames,
+	)
+
+	// RPC endpoint metrics
+	rpcEndpointLabelNames := []string{
+		rpcEndpointLabelName,
+	}
+	rpcEndpointLabelNames = append(rpcEndpointLabelNames, m.commonLabelNames...)
+	m.rpcEndpointRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: m.metricName("rpc_endpoint_requests_total"),
+			Help: "Number of requests made to a cluster RPC endpoint",
+		},
+		rpcEndpointLabelNames,
+	)
+	m.rpcEndpointErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: m.metricName("rpc_endpoint_errors_total"),
+			Help: "Number of failed requests to a cluster RPC endpoint",
+		},
+		rpcEndpointLabelNames,
+	)
+	m.rpcEndpointTimeouts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: m.metricName("rpc_endpoint_timeouts_total"),
+			Help: "Number of timed out requests to a cluster RPC endpoint",
+		},
+		rpcEndpointLabelNames,
+	)
+	m.rpcEndpointLatencySeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: m.metricName("rpc_endpoint_latency_seconds"),
+			Help: "Moving average request latency of a cluster RPC endpoint in seconds",
+		},
+		rpcEndpointLabelNames,
+	)
+	m.rpcEndpointDemoted = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: m.metricName("rpc_endpoint_demoted"),
+			Help: "Whether a cluster RPC endpoint is demoted to last resort (1 = yes, 0 = no)",
+		},
+		rpcEndpointLabelNames,
+	)
+
 	// Register all metrics
 	m.registry.MustRegister(m.metadata)
 	m.registry.MustRegister(m.peerCount)
 	m.registry.MustRegister(m.selfInGossip)
+	m.registry.MustRegister(m.draining)
+	m.registry.MustRegister(m.voteCredits)
+	m.registry.MustRegister(m.skipRate)
 	m.registry.MustRegister(m.failoverStatus)
+	m.registry.MustRegister(m.failoverInfo)
+	m.registry.MustRegister(m.clientInfo)
+	m.registry.MustRegister(m.rpcEndpointRequests)
+	m.registry.MustRegister(m.rpcEndpointErrors)
+	m.registry.MustRegister(m.rpcEndpointTimeouts)
+	m.registry.MustRegister(m.rpcEndpointLatencySeconds)
+	m.registry.MustRegister(m.rpcEndpointDemoted)
+	m.registry.MustRegister(newCommandCollector(m))
 
 	m.logger.Debug("initialized Prometheus metrics")
 }
 
-// StartServer starts the Prometheus metrics HTTP server
+// StartServer starts the Prometheus metrics HTTP server on prometheus.bind_address, serving HTTPS with
+// prometheus.tls and requiring prometheus.auth when set
 func (m *Metrics) StartServer(port int) error {
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	mux.Handle("/metrics", m.authorize(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})))
+
+	tlsConfig, err := m.config.Prometheus.TLS.ServerConfig()
+	if err != nil {
+		return err
+	}
 
 	m.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: mux,
+		Addr:      net.JoinHostPort(m.config.Prometheus.BindAddress, strconv.Itoa(port)),
+		Handler:   mux,
+		TLSConfig: tlsConfig,
 	}
 
-	m.logger.Debug("starting Prometheus metrics server", "port", port)
+	m.logger.Debug("starting Prometheus metrics server", "address", m.server.Addr, "tls", tlsConfig != nil)
 
-	err := m.server.ListenAndServe()
+	if tlsConfig != nil {
+		// the certificate is already loaded into the TLS config
+		err = m.server.ListenAndServeTLS("", "")
+	} else {
+		err = m.server.ListenAndServe()
+	}
 	if err != nil {
 		m.logger.Error("Prometheus metrics server failed", "error", err)
 	}
 	return err
 }
 
+// authorize rejects requests without the prometheus.auth credentials
+func (m *Metrics) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.config.Prometheus.Auth.Authorized(r) {
+			if m.config.Prometheus.Auth.Username != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="solana-validator-ha"`)
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // StopServer stops the Prometheus metrics HTTP server
 func (m *Metrics) StopServer() error {
 	if m.server != nil {
@@ -158,6 +318,12 @@ func (m *Metrics) StopServer() error {
 	return nil
 }
 
+// WriteTextfile writes the current metrics to path in node_exporter textfile collector format.
+// The file is written to a temporary file and renamed so node_exporter never reads a partial file.
+func (m *Metrics) WriteTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, m.registry)
+}
+
 // GetRegistry returns the Prometheus registry for testing
 func (m *Metrics) GetRegistry() *prometheus.Registry {
 	return m.registry
@@ -171,7 +337,13 @@ func (m *Metrics) RefreshMetrics() {
 	m.exportMetricMetadata(&state)
 	m.exportMetricPeerCount(&state)
 	m.exportMetricSelfInGossip(&state)
+	m.exportMetricDraining(&state)
+	m.exportMetricVoteCredits(&state)
+	m.exportMetricSkipRate(&state)
 	m.exportMetricFailoverStatus(&state)
+	m.exportMetricFailoverInfo(&state)
+	m.exportMetricClientInfo(&state)
+	m.exportMetricRPCEndpoints(&state)
 
 	m.logger.Debug("metrics refreshed",
 		validatorRoleLabelName, state.Role,
@@ -216,6 +388,38 @@ func (m *Metrics) exportMetricSelfInGossip(state *cache.State) {
 		Set(selfInGossipValue)
 }
 
+func (m *Metrics) exportMetricDraining(state *cache.State) {
+	var drainingValue float64
+	if state.Draining {
+		drainingValue = 1
+	}
+	m.draining.
+		With(m.getCommonLabels(state)).
+		Set(drainingValue)
+}
+
+func (m *Metrics) exportMetricVoteCredits(state *cache.State) {
+	// only exported once the cluster average is known
+	m.voteCredits.Reset()
+	if state.VoteCredits == nil || state.VoteCredits.ClusterAverage == 0 {
+		return
+	}
+	m.voteCredits.
+		With(m.getCommonLabels(state)).
+		Set(state.VoteCredits.Ratio())
+}
+
+func (m *Metrics) exportMetricSkipRate(state *cache.State) {
+	// only exported once there have been leader slots this epoch
+	m.skipRate.Reset()
+	if state.BlockProduction == nil || state.BlockProduction.LeaderSlots == 0 {
+		return
+	}
+	m.skipRate.
+		With(m.getCommonLabels(state)).
+		Set(state.BlockProduction.SkipRate())
+}
+
 func (m *Metrics) exportMetricFailoverStatus(state *cache.State) {
 	m.failoverStatus.
 		With(
@@ -229,6 +433,81 @@ func (m *Metrics) exportMetricFailoverStatus(state *cache.State) {
 		Set(1)
 }
 
+func (m *Metrics) exportMetricFailoverInfo(state *cache.State) {
+	// Reset to only export the latest transition
+	m.failoverInfo.Reset()
+
+	if state.FailoverID == "" {
+		return
+	}
+
+	m.failoverInfo.
+		With(
+			m.mergeLabels(
+				prometheus.Labels{
+					failoverIDLabelName: state.FailoverID,
+				},
+				m.getCommonLabels(state),
+			),
+		).
+		Set(1)
+}
+
+func (m *Metrics) exportMetricClientInfo(state *cache.State) {
+	// Reset to remove old flavor/version combinations
+	m.clientInfo.Reset()
+
+	m.clientInfo.
+		With(
+			m.mergeLabels(
+				prometheus.Labels{
+					clientFlavorLabelName:  string(state.Client.Flavor),
+					clientVersionLabelName: state.Client.Version,
+				},
+				m.getCommonLabels(state),
+			),
+		).
+		Set(1)
+}
+
+func (m *Metrics) exportMetricRPCEndpoints(state *cache.State) {
+	if m.rpcEndpointExported == nil {
+		m.rpcEndpointExported = make(map[string]rpc.EndpointStats)
+	}
+
+	for _, endpoint := range state.RPCEndpoints {
+		labels := m.mergeLabels(
+			prometheus.Labels{
+				rpcEndpointLabelName: endpoint.Endpoint,
+			},
+			m.getCommonLabels(state),
+		)
+
+		var demotedValue float64
+		if endpoint.Demoted {
+			demotedValue = 1
+		}
+
+		exported := m.rpcEndpointExported[endpoint.Endpoint]
+		m.rpcEndpointRequests.With(labels).Add(counterIncrease(exported.Requests, endpoint.Requests))
+		m.rpcEndpointErrors.With(labels).Add(counterIncrease(exported.Errors, endpoint.Errors))
+		m.rpcEndpointTimeouts.With(labels).Add(counterIncrease(exported.Timeouts, endpoint.Timeouts))
+		m.rpcEndpointExported[endpoint.Endpoint] = endpoint
+
+		m.rpcEndpointLatencySeconds.With(labels).Set(endpoint.AverageLatency.Seconds())
+		m.rpcEndpointDemoted.With(labels).Set(demotedValue)
+	}
+}
+
+// counterIncrease returns how far a total moved from previous to current - all of current when it went back, as
+// the RPC client counting it was replaced
+func counterIncrease(previous, current uint64) float64 {
+	if current < previous {
+		return float64(current)
+	}
+	return float64(current - previous)
+}
+
 // mergeLabels merges fromLabels into toLabels
 func (m *Metrics) mergeLabels(toLabels prometheus.Labels, fromLabels prometheus.Labels) prometheus.Labels {
 	for labelName, labelValue := range fromLabels {
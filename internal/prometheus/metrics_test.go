@@ -3,6 +3,9 @@ package prometheus
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -14,6 +17,7 @@ import (
 
 	"github.com/sol-strategies/solana-validator-ha/internal/cache"
 	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	"github.com/sol-strategies/solana-validator-ha/internal/rpc"
 )
 
 func createTestConfig() *config.Config {
@@ -448,6 +452,99 @@ func TestExportMetricSelfInGossip_False(t *testing.T) {
 	assert.Equal(t, float64(0), *selfInGossipMetric.Metric[0].Gauge.Value)
 }
 
+func TestExportMetricDraining(t *testing.T) {
+	metrics := New(Options{
+		Config: createTestConfig(),
+		Logger: createTestLogger(),
+		Cache:  createTestCache(),
+	})
+
+	state := cache.State{
+		ValidatorName: "test-validator",
+		PublicIP:      "192.168.1.100",
+		Draining:      true,
+	}
+
+	metrics.exportMetricDraining(&state)
+
+	metricsList, err := metrics.GetRegistry().Gather()
+	require.NoError(t, err)
+
+	var drainingMetric *dto.MetricFamily
+	for _, metricFamily := range metricsList {
+		if *metricFamily.Name == "solana_validator_ha_draining" {
+			drainingMetric = metricFamily
+			break
+		}
+	}
+
+	require.NotNil(t, drainingMetric)
+	assert.Len(t, drainingMetric.Metric, 1)
+	assert.Equal(t, float64(1), *drainingMetric.Metric[0].Gauge.Value)
+}
+
+func TestExportMetricVoteCredits(t *testing.T) {
+	metrics := New(Options{
+		Config: createTestConfig(),
+		Logger: createTestLogger(),
+		Cache:  createTestCache(),
+	})
+
+	gatherVoteCredits := func() *dto.MetricFamily {
+		metricsList, err := metrics.GetRegistry().Gather()
+		require.NoError(t, err)
+		for _, metricFamily := range metricsList {
+			if *metricFamily.Name == "solana_validator_ha_vote_credits_ratio" {
+				return metricFamily
+			}
+		}
+		return nil
+	}
+
+	// not exported until the cluster average is known
+	state := cache.State{ValidatorName: "test-validator", PublicIP: "192.168.1.100"}
+	metrics.exportMetricVoteCredits(&state)
+	assert.Nil(t, gatherVoteCredits())
+
+	state.VoteCredits = &rpc.VoteCredits{Credits: 900, ClusterAverage: 1000}
+	metrics.exportMetricVoteCredits(&state)
+	voteCreditsMetric := gatherVoteCredits()
+	require.NotNil(t, voteCreditsMetric)
+	assert.Len(t, voteCreditsMetric.Metric, 1)
+	assert.InDelta(t, 0.9, *voteCreditsMetric.Metric[0].Gauge.Value, 0.0001)
+}
+
+func TestExportMetricSkipRate(t *testing.T) {
+	metrics := New(Options{
+		Config: createTestConfig(),
+		Logger: createTestLogger(),
+		Cache:  createTestCache(),
+	})
+
+	gatherSkipRate := func() *dto.MetricFamily {
+		metricsList, err := metrics.GetRegistry().Gather()
+		require.NoError(t, err)
+		for _, metricFamily := range metricsList {
+			if *metricFamily.Name == "solana_validator_ha_skip_rate" {
+				return metricFamily
+			}
+		}
+		return nil
+	}
+
+	// not exported until there have been leader slots
+	state := cache.State{ValidatorName: "test-validator", PublicIP: "192.168.1.100", BlockProduction: &rpc.BlockProduction{}}
+	metrics.exportMetricSkipRate(&state)
+	assert.Nil(t, gatherSkipRate())
+
+	state.BlockProduction = &rpc.BlockProduction{LeaderSlots: 40, BlocksProduced: 30}
+	metrics.exportMetricSkipRate(&state)
+	skipRateMetric := gatherSkipRate()
+	require.NotNil(t, skipRateMetric)
+	assert.Len(t, skipRateMetric.Metric, 1)
+	assert.InDelta(t, 0.25, *skipRateMetric.Metric[0].Gauge.Value, 0.0001)
+}
+
 func TestExportMetricFailoverStatus(t *testing.T) {
 	cfg := createTestConfig()
 	cacheInstance := createTestCache()
@@ -487,6 +584,115 @@ func TestExportMetricFailoverStatus(t *testing.T) {
 	assert.Equal(t, float64(1), *failoverStatusMetric.Metric[0].Gauge.Value)
 }
 
+func TestExportMetricFailoverInfo(t *testing.T) {
+	metrics := New(Options{
+		Config: createTestConfig(),
+		Logger: createTestLogger(),
+		Cache:  createTestCache(),
+	})
+
+	findFailoverInfo := func() *dto.MetricFamily {
+		metricsList, err := metrics.GetRegistry().Gather()
+		require.NoError(t, err)
+		for _, metricFamily := range metricsList {
+			if *metricFamily.Name == "solana_validator_ha_failover_info" {
+				return metricFamily
+			}
+		}
+		return nil
+	}
+
+	// nothing exported before the first transition
+	metrics.exportMetricFailoverInfo(&cache.State{ValidatorName: "test-validator", PublicIP: "192.168.1.100"})
+	assert.Nil(t, findFailoverInfo())
+
+	// only the latest transition is exported
+	state := cache.State{ValidatorName: "test-validator", PublicIP: "192.168.1.100", FailoverID: "20250101T000000Z-aaaaaaaa"}
+	metrics.exportMetricFailoverInfo(&state)
+	state.FailoverID = "20250101T010000Z-bbbbbbbb"
+	metrics.exportMetricFailoverInfo(&state)
+
+	failoverInfoMetric := findFailoverInfo()
+	require.NotNil(t, failoverInfoMetric)
+	require.Len(t, failoverInfoMetric.Metric, 1)
+	assert.Equal(t, float64(1), *failoverInfoMetric.Metric[0].Gauge.Value)
+	labels := map[string]string{}
+	for _, label := range failoverInfoMetric.Metric[0].Label {
+		labels[label.GetName()] = label.GetValue()
+	}
+	assert.Equal(t, "20250101T010000Z-bbbbbbbb", labels["failover_id"])
+}
+
+func TestExportMetricRPCEndpoints(t *testing.T) {
+	cfg := createTestConfig()
+	cacheInstance := createTestCache()
+	logger := createTestLogger()
+
+	opts := Options{
+		Config: cfg,
+		Logger: logger,
+		Cache:  cacheInstance,
+	}
+
+	metrics := New(opts)
+
+	state := cache.State{
+		ValidatorName: "test-validator",
+		PublicIP:      "192.168.1.100",
+		RPCEndpoints: []rpc.EndpointStats{
+			{Endpoint: "rpc-1.example.com", Requests: 10, Errors: 2, Timeouts: 1, AverageLatency: 250 * time.Millisecond},
+			{Endpoint: "rpc-2.example.com", Requests: 5, Errors: 5, Demoted: true},
+		},
+	}
+
+	metrics.exportMetricRPCEndpoints(&state)
+
+	gather := func() map[string]map[string]float64 {
+		metricsList, err := metrics.GetRegistry().Gather()
+		require.NoError(t, err)
+
+		values := map[string]map[string]float64{}
+		for _, metricFamily := range metricsList {
+			for _, metric := range metricFamily.Metric {
+				for _, label := range metric.Label {
+					if *label.Name == "rpc_endpoint" {
+						if values[*metricFamily.Name] == nil {
+							values[*metricFamily.Name] = map[string]float64{}
+						}
+						if metric.Counter != nil {
+							values[*metricFamily.Name][*label.Value] = *metric.Counter.Value
+						} else {
+							values[*metricFamily.Name][*label.Value] = *metric.Gauge.Value
+						}
+					}
+				}
+			}
+		}
+		return values
+	}
+
+	values := gather()
+	assert.Equal(t, float64(10), values["solana_validator_ha_rpc_endpoint_requests_total"]["rpc-1.example.com"])
+	assert.Equal(t, float64(2), values["solana_validator_ha_rpc_endpoint_errors_total"]["rpc-1.example.com"])
+	assert.Equal(t, float64(1), values["solana_validator_ha_rpc_endpoint_timeouts_total"]["rpc-1.example.com"])
+	assert.Equal(t, 0.25, values["solana_validator_ha_rpc_endpoint_latency_seconds"]["rpc-1.example.com"])
+	assert.Equal(t, float64(0), values["solana_validator_ha_rpc_endpoint_demoted"]["rpc-1.example.com"])
+	assert.Equal(t, float64(1), values["solana_validator_ha_rpc_endpoint_demoted"]["rpc-2.example.com"])
+
+	// totals only ever go up - by how far they moved, or all of a total that went back as its client was replaced
+	state.RPCEndpoints = []rpc.EndpointStats{
+		{Endpoint: "rpc-1.example.com", Requests: 15, Errors: 2, Timeouts: 1},
+		{Endpoint: "rpc-2.example.com", Requests: 3, Errors: 1},
+	}
+	metrics.exportMetricRPCEndpoints(&state)
+
+	values = gather()
+	assert.Equal(t, float64(15), values["solana_validator_ha_rpc_endpoint_requests_total"]["rpc-1.example.com"])
+	assert.Equal(t, float64(2), values["solana_validator_ha_rpc_endpoint_errors_total"]["rpc-1.example.com"])
+	assert.Equal(t, float64(8), values["solana_validator_ha_rpc_endpoint_requests_total"]["rpc-2.example.com"])
+	assert.Equal(t, float64(6), values["solana_validator_ha_rpc_endpoint_errors_total"]["rpc-2.example.com"])
+}
+
 func TestGetRegistry(t *testing.T) {
 	cfg := createTestConfig()
 	cacheInstance := createTestCache()
@@ -566,6 +772,45 @@ func TestStartServer_WithInvalidPort(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestMetrics_Namespace(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Prometheus.Namespace = "tenant_ha"
+	metrics := New(Options{Config: cfg, Logger: createTestLogger(), Cache: createTestCache()})
+
+	metrics.cache.UpdateState(cache.State{ValidatorName: "test-validator", PublicIP: "192.168.1.100", Role: "active", Status: "healthy", PeerCount: 2})
+	metrics.RefreshMetrics()
+
+	metricFamilies, err := metrics.registry.Gather()
+	require.NoError(t, err)
+	require.NotEmpty(t, metricFamilies)
+	for _, metricFamily := range metricFamilies {
+		assert.Regexp(t, "^tenant_ha_", metricFamily.GetName())
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Prometheus.Auth = config.PrometheusAuth{Username: "prometheus", Password: "hunter2"}
+	metrics := New(Options{Config: cfg, Logger: createTestLogger(), Cache: createTestCache()})
+
+	handler := metrics.authorize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("metrics"))
+	}))
+
+	// unauthenticated scrapes are asked for basic auth
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, `Basic realm="solana-validator-ha"`, recorder.Header().Get("WWW-Authenticate"))
+
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	request.SetBasicAuth("prometheus", "hunter2")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "metrics", recorder.Body.String())
+}
+
 func TestStopServer_WhenNotStarted(t *testing.T) {
 	cfg := createTestConfig()
 	cacheInstance := createTestCache()
@@ -765,3 +1010,32 @@ func TestMetrics_ConcurrentAccess(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotEmpty(t, metricsList)
 }
+
+func TestWriteTextfile(t *testing.T) {
+	metrics := New(Options{
+		Config: createTestConfig(),
+		Logger: createTestLogger(),
+		Cache:  createTestCache(),
+	})
+
+	metrics.cache.UpdateState(cache.State{
+		ValidatorName: "test-validator",
+		PublicIP:      "192.168.1.100",
+		Role:          "active",
+		PeerCount:     2,
+	})
+	metrics.RefreshMetrics()
+
+	path := filepath.Join(t.TempDir(), "solana_validator_ha.prom")
+	require.NoError(t, metrics.WriteTextfile(path))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "# TYPE solana_validator_ha_peer_count gauge")
+	assert.Contains(t, string(contents), `solana_validator_ha_peer_count{environment="test",public_ip="192.168.1.100",region="us-west-1",validator_name="test-validator"} 2`)
+
+	// only the metrics file is left behind in the directory
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
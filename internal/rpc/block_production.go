@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// BlockProduction is how many of an identity's leader slots so far this epoch it produced blocks in
+type BlockProduction struct {
+	FirstSlot      uint64 `json:"first_slot"`
+	LastSlot       uint64 `json:"last_slot"`
+	LeaderSlots    uint64 `json:"leader_slots"`
+	BlocksProduced uint64 `json:"blocks_produced"`
+}
+
+// SkipRate returns the fraction of leader slots without a block produced - zero without leader slots
+func (b BlockProduction) SkipRate() float64 {
+	if b.LeaderSlots == 0 {
+		return 0
+	}
+	return float64(b.LeaderSlots-min(b.BlocksProduced, b.LeaderSlots)) / float64(b.LeaderSlots)
+}
+
+// GetBlockProduction gets identity's block production so far this epoch from the first working RPC client
+func (c *Client) GetBlockProduction(ctx context.Context, identity solana.PublicKey) (BlockProduction, error) {
+	return executeWithRetry(c, ctx, rpcOperation[BlockProduction]{
+		name: "GetBlockProduction",
+		execute: func(client *rpc.Client, ctx context.Context) (BlockProduction, error) {
+			result, err := client.GetBlockProductionWithOpts(ctx, &rpc.GetBlockProductionOpts{
+				Commitment: rpc.CommitmentConfirmed,
+				Identity:   &identity,
+			})
+			if err != nil {
+				return BlockProduction{}, err
+			}
+
+			production := BlockProduction{
+				FirstSlot: result.Value.Range.FirstSlot,
+				LastSlot:  result.Value.Range.LastSlot,
+			}
+			if slots, ok := result.Value.ByIdentity[identity]; ok {
+				production.LeaderSlots = uint64(slots[0])
+				production.BlocksProduced = uint64(slots[1])
+			}
+			return production, nil
+		},
+	})
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -23,20 +25,93 @@ type Client struct {
 	lastSuccessfulURL string
 	timeout           time.Duration
 	logger            *log.Logger
+	// statsMu guards stats and demotion settings which are read from other goroutines
+	statsMu sync.Mutex
+	// stats tracks request statistics keyed by the rpc URL
+	stats                    map[string]*endpointStats
+	demotionFailureThreshold int
+	demotionDuration         time.Duration
+	onEndpointDemoted        func(stats EndpointStats)
 }
 
 // NewClient creates a new RPC client with one or more URLs
 func NewClient(logPrefix string, urls ...string) *Client {
 	clients := make(map[string]*rpc.Client)
+	stats := make(map[string]*endpointStats)
 	for _, url := range urls {
 		clients[url] = rpc.New(url)
+		stats[url] = newEndpointStats(url)
 	}
 	return &Client{
-		logger:            log.WithPrefix(fmt.Sprintf("[%s rpc_client]", logPrefix)),
-		urls:              urls,
-		clients:           clients,
-		lastSuccessfulURL: "",
-		timeout:           5 * time.Second, // Default timeout
+		logger:                   log.WithPrefix(fmt.Sprintf("[%s rpc_client]", logPrefix)),
+		urls:                     urls,
+		clients:                  clients,
+		lastSuccessfulURL:        "",
+		timeout:                  5 * time.Second, // Default timeout
+		stats:                    stats,
+		demotionFailureThreshold: defaultDemotionFailureThreshold,
+		demotionDuration:         defaultDemotionDuration,
+	}
+}
+
+// SetDemotionPolicy sets how many consecutive failures demote an endpoint to last resort and for how long.
+// A failureThreshold of zero disables demotion.
+func (c *Client) SetDemotionPolicy(failureThreshold int, duration time.Duration) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.demotionFailureThreshold = failureThreshold
+	c.demotionDuration = duration
+}
+
+// OnEndpointDemoted sets a callback invoked when an endpoint is demoted to last resort
+func (c *Client) OnEndpointDemoted(fn func(stats EndpointStats)) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.onEndpointDemoted = fn
+}
+
+// EndpointStats returns a snapshot of the request statistics for each endpoint in configured order
+func (c *Client) EndpointStats() []EndpointStats {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	now := time.Now()
+	snapshot := make([]EndpointStats, 0, len(c.urls))
+	for _, url := range c.urls {
+		stats, exists := c.stats[url]
+		if !exists {
+			continue
+		}
+		stats.isDemoted(now)
+		snapshot = append(snapshot, stats.EndpointStats)
+	}
+	return snapshot
+}
+
+// recordRequest records the outcome of a request against url, demoting it if it keeps failing
+func (c *Client) recordRequest(url string, latency time.Duration, err error) {
+	c.statsMu.Lock()
+	stats, exists := c.stats[url]
+	if !exists {
+		c.statsMu.Unlock()
+		return
+	}
+	demoted := stats.record(latency, err, c.demotionFailureThreshold, c.demotionDuration)
+	snapshot := stats.EndpointStats
+	onEndpointDemoted := c.onEndpointDemoted
+	c.statsMu.Unlock()
+
+	if !demoted {
+		return
+	}
+
+	c.logger.Warn("demoting rpc endpoint to last resort after consecutive failures",
+		"endpoint", snapshot.Endpoint,
+		"consecutive_failures", snapshot.ConsecutiveFailures,
+		"demoted_until", snapshot.DemotedUntil.Format(time.RFC3339),
+	)
+	if onEndpointDemoted != nil {
+		onEndpointDemoted(snapshot)
 	}
 }
 
@@ -53,17 +128,31 @@ type rpcOperation[T any] struct {
 	execute func(*rpc.Client, context.Context) (T, error)
 }
 
-// getURLsToTry returns URLs to try with lastSuccessfulURL at the end for throttling protection
+// getURLsToTry returns URLs to try with lastSuccessfulURL at the end for throttling protection,
+// followed by any demoted URLs as a last resort
 func (c *Client) getURLsToTry() []string {
-	if len(c.urls) <= 1 || c.lastSuccessfulURL == "" {
-		return c.urls
+	c.statsMu.Lock()
+	now := time.Now()
+	demotedURLs := []string{}
+	healthyURLs := make([]string, 0, len(c.urls))
+	for _, url := range c.urls {
+		if stats, exists := c.stats[url]; exists && stats.isDemoted(now) {
+			demotedURLs = append(demotedURLs, url)
+			continue
+		}
+		healthyURLs = append(healthyURLs, url)
+	}
+	c.statsMu.Unlock()
+
+	if len(healthyURLs) <= 1 || c.lastSuccessfulURL == "" || !slices.Contains(healthyURLs, c.lastSuccessfulURL) {
+		return append(healthyURLs, demotedURLs...)
 	}
 
 	// Build list with lastSuccessfulURL at the end
 	urlsToTry := make([]string, 0, len(c.urls))
 
 	// Add all URLs except lastSuccessfulURL first
-	for _, url := range c.urls {
+	for _, url := range healthyURLs {
 		if url != c.lastSuccessfulURL {
 			urlsToTry = append(urlsToTry, url)
 		}
@@ -72,7 +161,7 @@ func (c *Client) getURLsToTry() []string {
 	// Add lastSuccessfulURL at the end (as fallback)
 	urlsToTry = append(urlsToTry, c.lastSuccessfulURL)
 
-	return urlsToTry
+	return append(urlsToTry, demotedURLs...)
 }
 
 // executeWithRetry executes an RPC method, trying URLs in throttling-optimized order
@@ -90,11 +179,13 @@ func executeWithRetry[T any](c *Client, ctx context.Context, op rpcOperation[T])
 		attemptedURLs = append(attemptedURLs, url)
 
 		var result T
+		startedAt := time.Now()
 		err := c.withTimeout(ctx, func(timeoutCtx context.Context) error {
 			var err error
 			result, err = op.execute(client, timeoutCtx)
 			return err
 		})
+		c.recordRequest(url, time.Since(startedAt), err)
 
 		if err != nil {
 			c.logger.Debug("method call failed", "method", op.name, "error", err, "rpc_url", url)
@@ -121,6 +212,16 @@ func (c *Client) GetSlot(ctx context.Context) (uint64, error) {
 	})
 }
 
+// GetEpochInfo gets the current epoch info from the first working RPC client
+func (c *Client) GetEpochInfo(ctx context.Context) (*rpc.GetEpochInfoResult, error) {
+	return executeWithRetry(c, ctx, rpcOperation[*rpc.GetEpochInfoResult]{
+		name: "GetEpochInfo",
+		execute: func(client *rpc.Client, ctx context.Context) (*rpc.GetEpochInfoResult, error) {
+			return client.GetEpochInfo(ctx, rpc.CommitmentProcessed)
+		},
+	})
+}
+
 // GetVoteAccounts gets the vote accounts from the first working RPC client
 
 func (c *Client) GetVoteAccounts(ctx context.Context) (*rpc.GetVoteAccountsResult, error) {
@@ -168,6 +269,16 @@ func (c *Client) GetIdentity(ctx context.Context) (*rpc.GetIdentityResult, error
 	})
 }
 
+// GetVersion gets the node software version from the first working RPC client
+func (c *Client) GetVersion(ctx context.Context) (*rpc.GetVersionResult, error) {
+	return executeWithRetry(c, ctx, rpcOperation[*rpc.GetVersionResult]{
+		name: "GetVersion",
+		execute: func(client *rpc.Client, ctx context.Context) (*rpc.GetVersionResult, error) {
+			return client.GetVersion(ctx)
+		},
+	})
+}
+
 // GetHealth gets the health from the first working RPC client
 func (c *Client) GetHealth(ctx context.Context) (string, error) {
 	result, err := executeWithRetry(c, ctx, rpcOperation[string]{
@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// LeaderSlotGap is how far away an identity's next leader slot is
+type LeaderSlotGap struct {
+	// Slots until the next leader slot - the slots left in the epoch when Scheduled is false
+	Slots uint64
+	// Scheduled is false if the identity has no leader slots left this epoch
+	Scheduled bool
+	// AbsoluteSlot is the slot the gap was measured from
+	AbsoluteSlot uint64
+}
+
+// GetLeaderSlotGap gets how far away identity's next leader slot in the current epoch is from the first working
+// RPC client
+func (c *Client) GetLeaderSlotGap(ctx context.Context, identity solana.PublicKey) (LeaderSlotGap, error) {
+	epochInfo, err := c.GetEpochInfo(ctx)
+	if err != nil {
+		return LeaderSlotGap{}, err
+	}
+
+	leaderSlots, err := executeWithRetry(c, ctx, rpcOperation[[]uint64]{
+		name: "GetLeaderSchedule",
+		execute: func(client *rpc.Client, ctx context.Context) ([]uint64, error) {
+			epoch := epochInfo.Epoch
+			schedule, err := client.GetLeaderScheduleWithOpts(ctx, &rpc.GetLeaderScheduleOpts{
+				Commitment: rpc.CommitmentProcessed,
+				Epoch:      &epoch,
+				Identity:   &identity,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return schedule[identity], nil
+		},
+	})
+	if err != nil {
+		return LeaderSlotGap{}, err
+	}
+
+	gap := leaderSlotGap(epochInfo.SlotIndex, epochInfo.SlotsInEpoch, leaderSlots)
+	gap.AbsoluteSlot = epochInfo.AbsoluteSlot
+	return gap, nil
+}
+
+// leaderSlotGap returns how far the first of leaderSlots at or after slotIndex is, as indices into an epoch of
+// slotsInEpoch slots
+func leaderSlotGap(slotIndex uint64, slotsInEpoch uint64, leaderSlots []uint64) LeaderSlotGap {
+	slices.Sort(leaderSlots)
+	for _, leaderSlot := range leaderSlots {
+		if leaderSlot >= slotIndex {
+			return LeaderSlotGap{Slots: leaderSlot - slotIndex, Scheduled: true}
+		}
+	}
+	return LeaderSlotGap{Slots: slotsInEpoch - min(slotIndex, slotsInEpoch)}
+}
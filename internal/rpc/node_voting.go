@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// IsNodeVoting returns true if identity is in gossip and has a current, not delinquent, vote account as seen by the
+// first working RPC client
+func (c *Client) IsNodeVoting(ctx context.Context, identity solana.PublicKey) (bool, error) {
+	clusterNodes, err := c.GetClusterNodes(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	inGossip := false
+	for _, node := range clusterNodes {
+		if node.Pubkey.Equals(identity) {
+			inGossip = true
+			break
+		}
+	}
+	if !inGossip {
+		return false, nil
+	}
+
+	voteAccounts, err := c.GetVoteAccounts(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, voteAccount := range voteAccounts.Current {
+		if voteAccount.NodePubkey.Equals(identity) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
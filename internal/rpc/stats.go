@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDemotionFailureThreshold is the number of consecutive failures before an endpoint is demoted
+	defaultDemotionFailureThreshold = 5
+	// defaultDemotionDuration is how long an endpoint stays demoted before it is tried in normal order again
+	defaultDemotionDuration = 5 * time.Minute
+	// latencyEWMAWeight is the weight given to the latest sample in the latency moving average
+	latencyEWMAWeight = 0.2
+)
+
+// EndpointStats is a snapshot of the request statistics for a single RPC endpoint
+type EndpointStats struct {
+	// Endpoint is the endpoint host:port and path, telling apart endpoints on the same host - credentials and query
+	// strings are omitted as they often carry API keys
+	Endpoint            string        `json:"endpoint"`
+	Requests            uint64        `json:"requests"`
+	Errors              uint64        `json:"errors"`
+	Timeouts            uint64        `json:"timeouts"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	LastLatency         time.Duration `json:"last_latency"`
+	AverageLatency      time.Duration `json:"average_latency"`
+	Demoted             bool          `json:"demoted"`
+	DemotedUntil        time.Time     `json:"demoted_until,omitempty"`
+}
+
+// ErrorRate returns the fraction of requests that failed
+func (s EndpointStats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// endpointStats tracks request statistics for a single RPC endpoint
+type endpointStats struct {
+	EndpointStats
+}
+
+// newEndpointStats creates stats for the given endpoint URL
+func newEndpointStats(rawURL string) *endpointStats {
+	return &endpointStats{EndpointStats: EndpointStats{Endpoint: endpointName(rawURL)}}
+}
+
+// record records the outcome of a request, returning true if the endpoint was demoted by it
+func (s *endpointStats) record(latency time.Duration, err error, failureThreshold int, demotionDuration time.Duration) (demoted bool) {
+	s.Requests++
+	s.LastLatency = latency
+	if s.AverageLatency == 0 {
+		s.AverageLatency = latency
+	} else {
+		s.AverageLatency = time.Duration(latencyEWMAWeight*float64(latency) + (1-latencyEWMAWeight)*float64(s.AverageLatency))
+	}
+
+	if err == nil {
+		s.ConsecutiveFailures = 0
+		return false
+	}
+
+	s.Errors++
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.Timeouts++
+	}
+	s.ConsecutiveFailures++
+
+	if failureThreshold > 0 && s.ConsecutiveFailures >= failureThreshold && !s.Demoted {
+		s.Demoted = true
+		s.DemotedUntil = time.Now().Add(demotionDuration)
+		return true
+	}
+	return false
+}
+
+// isDemoted returns whether the endpoint is currently demoted, reinstating it once the demotion expires
+func (s *endpointStats) isDemoted(now time.Time) bool {
+	if s.Demoted && now.After(s.DemotedUntil) {
+		s.Demoted = false
+		s.DemotedUntil = time.Time{}
+		s.ConsecutiveFailures = 0
+	}
+	return s.Demoted
+}
+
+// endpointName returns the host:port and path of an RPC URL, falling back to a placeholder if it cannot be parsed
+func endpointName(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return "invalid-url"
+	}
+	return parsedURL.Host + strings.TrimSuffix(parsedURL.EscapedPath(), "/")
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// VoteAccountState is the on-chain configuration of a vote account
+type VoteAccountState struct {
+	NodePubkey           string
+	AuthorizedWithdrawer string
+	AuthorizedVoter      string
+	Commission           uint8
+}
+
+// jsonParsedVoteAccount is the jsonParsed encoding of a vote account's data
+type jsonParsedVoteAccount struct {
+	Program string `json:"program"`
+	Parsed  struct {
+		Info struct {
+			NodePubkey           string `json:"nodePubkey"`
+			AuthorizedWithdrawer string `json:"authorizedWithdrawer"`
+			Commission           uint8  `json:"commission"`
+			AuthorizedVoters     []struct {
+				AuthorizedVoter string `json:"authorizedVoter"`
+				Epoch           uint64 `json:"epoch"`
+			} `json:"authorizedVoters"`
+		} `json:"info"`
+	} `json:"parsed"`
+}
+
+// GetVoteAccountState gets the commission, node and authorities of a vote account from the first working RPC client
+func (c *Client) GetVoteAccountState(ctx context.Context, votePubkey solana.PublicKey) (*VoteAccountState, error) {
+	return executeWithRetry(c, ctx, rpcOperation[*VoteAccountState]{
+		name: "GetVoteAccountState",
+		execute: func(client *rpc.Client, ctx context.Context) (*VoteAccountState, error) {
+			result, err := client.GetAccountInfoWithOpts(ctx, votePubkey, &rpc.GetAccountInfoOpts{
+				Encoding:   solana.EncodingJSONParsed,
+				Commitment: rpc.CommitmentConfirmed,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if result == nil || result.Value == nil || result.Value.Data == nil {
+				return nil, fmt.Errorf("vote account %s not found", votePubkey)
+			}
+
+			var account jsonParsedVoteAccount
+			if err := json.Unmarshal(result.Value.Data.GetRawJSON(), &account); err != nil {
+				return nil, fmt.Errorf("failed to parse vote account %s: %w", votePubkey, err)
+			}
+			if account.Program != "vote" {
+				return nil, fmt.Errorf("account %s is not a vote account", votePubkey)
+			}
+
+			state := &VoteAccountState{
+				NodePubkey:           account.Parsed.Info.NodePubkey,
+				AuthorizedWithdrawer: account.Parsed.Info.AuthorizedWithdrawer,
+				Commission:           account.Parsed.Info.Commission,
+			}
+			// the first authorized voter is the one for the current epoch
+			if len(account.Parsed.Info.AuthorizedVoters) > 0 {
+				state.AuthorizedVoter = account.Parsed.Info.AuthorizedVoters[0].AuthorizedVoter
+			}
+
+			return state, nil
+		},
+	})
+}
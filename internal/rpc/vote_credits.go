@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// VoteCredits are a vote account's credits earned so far this epoch and the cluster average
+type VoteCredits struct {
+	VoteAccount string `json:"vote_account"`
+	Epoch       uint64 `json:"epoch"`
+	Credits     uint64 `json:"credits"`
+	// ClusterAverage is the mean credits earned this epoch by the current, staked vote accounts
+	ClusterAverage float64 `json:"cluster_average"`
+}
+
+// Ratio returns the credits as a fraction of the cluster average - zero if the average is unknown
+func (v VoteCredits) Ratio() float64 {
+	if v.ClusterAverage == 0 {
+		return 0
+	}
+	return float64(v.Credits) / v.ClusterAverage
+}
+
+// GetVoteCredits gets the epoch credits of voteAccount, or of the vote account whose node is identity when
+// voteAccount is zero, and the cluster average from the first working RPC client
+func (c *Client) GetVoteCredits(ctx context.Context, identity solana.PublicKey, voteAccount solana.PublicKey) (VoteCredits, error) {
+	voteAccounts, err := c.GetVoteAccounts(ctx)
+	if err != nil {
+		return VoteCredits{}, err
+	}
+	return voteCredits(voteAccounts, identity, voteAccount)
+}
+
+// voteCredits finds the vote account in voteAccounts and averages the credits earned in the latest epoch across the
+// current vote accounts with activated stake
+func voteCredits(voteAccounts *rpc.GetVoteAccountsResult, identity solana.PublicKey, voteAccount solana.PublicKey) (VoteCredits, error) {
+	// epoch credits entries are [epoch, credits, previous credits] - the latest epoch is the current one
+	var epoch uint64
+	for _, account := range voteAccounts.Current {
+		if latest, ok := latestEpochCredits(account); ok {
+			epoch = max(epoch, latest[0])
+		}
+	}
+
+	var total uint64
+	var counted int
+	for _, account := range voteAccounts.Current {
+		if account.ActivatedStake == 0 {
+			continue
+		}
+		total += epochCredits(account, epoch)
+		counted++
+	}
+
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if voteAccount.IsZero() && !account.NodePubkey.Equals(identity) || !voteAccount.IsZero() && !account.VotePubkey.Equals(voteAccount) {
+			continue
+		}
+
+		credits := VoteCredits{
+			VoteAccount: account.VotePubkey.String(),
+			Epoch:       epoch,
+			Credits:     epochCredits(account, epoch),
+		}
+		if counted > 0 {
+			credits.ClusterAverage = float64(total) / float64(counted)
+		}
+		return credits, nil
+	}
+
+	if !voteAccount.IsZero() {
+		return VoteCredits{}, fmt.Errorf("vote account %s not found", voteAccount)
+	}
+	return VoteCredits{}, fmt.Errorf("no vote account found for identity %s", identity)
+}
+
+// latestEpochCredits returns the account's latest [epoch, credits, previous credits] entry
+func latestEpochCredits(account rpc.VoteAccountsResult) ([3]uint64, bool) {
+	if len(account.EpochCredits) == 0 || len(account.EpochCredits[len(account.EpochCredits)-1]) != 3 {
+		return [3]uint64{}, false
+	}
+	latest := account.EpochCredits[len(account.EpochCredits)-1]
+	return [3]uint64{uint64(latest[0]), uint64(latest[1]), uint64(latest[2])}, true
+}
+
+// epochCredits returns the credits the account earned in epoch - zero if it earned none
+func epochCredits(account rpc.VoteAccountsResult, epoch uint64) uint64 {
+	latest, ok := latestEpochCredits(account)
+	if !ok || latest[0] != epoch || latest[1] < latest[2] {
+		return 0
+	}
+	return latest[1] - latest[2]
+}
@@ -0,0 +1,163 @@
+// Package ha embeds the solana-validator-ha failover engine in another Go program.
+//
+// Load a config, create an HA with New and call Run, which blocks running the failover engine until its context
+// is done:
+//
+//	cfg, err := ha.LoadConfig("/etc/solana-validator-ha/config.yaml")
+//	if err != nil {
+//		return err
+//	}
+//
+//	h, err := ha.New(ha.Options{Config: cfg})
+//	if err != nil {
+//		return err
+//	}
+//
+//	events, unsubscribe := h.Subscribe(64)
+//	defer unsubscribe()
+//	go func() {
+//		for event := range events {
+//			fmt.Println(event.Type, event.Message)
+//		}
+//	}()
+//
+//	return h.Run(ctx)
+//
+// Logs are written with the charmbracelet/log default logger.
+package ha
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sol-strategies/solana-validator-ha/internal/config"
+	internalha "github.com/sol-strategies/solana-validator-ha/internal/ha"
+	"github.com/sol-strategies/solana-validator-ha/internal/notify"
+)
+
+// Config is the solana-validator-ha configuration, as documented in the README
+type Config = config.Config
+
+// Event is an event emitted by the failover engine
+type Event = notify.Event
+
+// EventType identifies an event - as named in notifications.events
+type EventType = notify.EventType
+
+// Severity is the severity of an event
+type Severity = notify.Severity
+
+// Event types
+const (
+	EventStartup                   = notify.EventStartup
+	EventShutdown                  = notify.EventShutdown
+	EventBecomingActive            = notify.EventBecomingActive
+	EventBecameActive              = notify.EventBecameActive
+	EventBecomingPassive           = notify.EventBecomingPassive
+	EventBecamePassive             = notify.EventBecamePassive
+	EventHealthUnhealthy           = notify.EventHealthUnhealthy
+	EventHealthRecovered           = notify.EventHealthRecovered
+	EventDelinquent                = notify.EventDelinquent
+	EventGossipLost                = notify.EventGossipLost
+	EventGossipRecovered           = notify.EventGossipRecovered
+	EventPeerDiscovered            = notify.EventPeerDiscovered
+	EventPeerLost                  = notify.EventPeerLost
+	EventRPCDemoted                = notify.EventRPCDemoted
+	EventSnapshotRecoveryStarted   = notify.EventSnapshotRecoveryStarted
+	EventSnapshotRecoveryCompleted = notify.EventSnapshotRecoveryCompleted
+	EventSnapshotRecoveryFailed    = notify.EventSnapshotRecoveryFailed
+	EventQuietPeriodEnded          = notify.EventQuietPeriodEnded
+	EventVoteAccountChanged        = notify.EventVoteAccountChanged
+	EventActiveIdentityOnPassive   = notify.EventActiveIdentityOnPassive
+	EventCircuitBreakerTripped     = notify.EventCircuitBreakerTripped
+	EventCircuitBreakerReset       = notify.EventCircuitBreakerReset
+	EventHeartbeat                 = notify.EventHeartbeat
+	EventAcknowledged              = notify.EventAcknowledged
+	EventManualRoleChange          = notify.EventManualRoleChange
+	EventScheduledFailover         = notify.EventScheduledFailover
+	EventVoteCreditsLagging        = notify.EventVoteCreditsLagging
+	EventSkipRateHigh              = notify.EventSkipRateHigh
+	EventFailback                  = notify.EventFailback
+	EventFlappingDetected          = notify.EventFlappingDetected
+)
+
+// Severities
+const (
+	SeverityCritical = notify.SeverityCritical
+	SeverityError    = notify.SeverityError
+	SeverityWarning  = notify.SeverityWarning
+	SeverityInfo     = notify.SeverityInfo
+)
+
+// LoadConfig loads, defaults and validates a config file
+func LoadConfig(path string) (*Config, error) {
+	return LoadConfigContext(context.Background(), path)
+}
+
+// LoadConfigContext loads, defaults and validates a config file, fetching secrets referenced in AWS with ctx
+func LoadConfigContext(ctx context.Context, path string) (*Config, error) {
+	return config.NewFromConfigFile(ctx, path)
+}
+
+// Options are the options for creating an HA
+type Options struct {
+	// Config is the loaded config - see LoadConfig
+	Config *Config
+	// GetPublicIP overrides how this node's public IP is discovered - defaults to validator.public_ip_service_urls
+	GetPublicIP func() (string, error)
+}
+
+// HA runs the failover engine for a single validator
+type HA struct {
+	manager *internalha.Manager
+}
+
+// New creates an HA from options
+func New(opts Options) (*HA, error) {
+	if opts.Config == nil {
+		return nil, errors.New("config is required")
+	}
+
+	return &HA{
+		manager: internalha.NewManager(internalha.NewManagerOptions{
+			Cfg:             opts.Config,
+			GetPublicIPFunc: opts.GetPublicIP,
+		}),
+	}, nil
+}
+
+// Run runs the failover engine until ctx is done or Stop is called, when the exit report is emitted and shutdown
+// notification sent before returning. An HA can only be run once.
+func (h *HA) Run(ctx context.Context) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			h.manager.Stop()
+		case <-done:
+		}
+	}()
+
+	return h.manager.Run()
+}
+
+// Stop stops a running HA, making Run return
+func (h *HA) Stop() {
+	h.manager.Stop()
+}
+
+// Reload reloads the config file the HA was created with, applying notification settings, failover.peers and
+// thresholds between HA checks without interrupting a failover in progress - other settings take effect on
+// restart. Nothing changes if the file fails to load or validate.
+func (h *HA) Reload() error {
+	return h.manager.ReloadConfigFile()
+}
+
+// Subscribe returns a channel receiving every event emitted from now on, whether or not notifications are enabled
+// for it, and a func to unsubscribe which closes the channel. Events are dropped for a subscriber whose buffer is
+// full rather than holding up failover, so size the buffer for bursts and keep reading.
+func (h *HA) Subscribe(buffer int) (<-chan Event, func()) {
+	return h.manager.Subscribe(buffer)
+}
@@ -0,0 +1,24 @@
+package ha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_RequiresConfig(t *testing.T) {
+	_, err := New(Options{})
+	assert.EqualError(t, err, "config is required")
+}
+
+func TestHA_Subscribe(t *testing.T) {
+	h, err := New(Options{Config: &Config{}})
+	require.NoError(t, err)
+
+	events, unsubscribe := h.Subscribe(1)
+	unsubscribe()
+
+	_, open := <-events
+	assert.False(t, open, "unsubscribe should close the channel")
+}